@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -19,7 +20,11 @@ type Config struct {
 	OpenAIAPIKey  string
 	OpenAIModel   string
 	LocalLLMURL   string
-	AIProvider    string // "openai" or "local"
+	AIProvider    string // "openai", "local" or "mock" (demo mode)
+	TriagePromptVersion string
+	// AutoTriageOnCreate fills in the category, priority and assignee of
+	// new tickets from AI triage when they were not given
+	AutoTriageOnCreate bool
 	CORSOrigin    string
     // Monitoring / AIOps
     MonitoringEnabled    bool
@@ -28,6 +33,255 @@ type Config struct {
     MonitorMinConsecutive int
     AWSRegion            string
     AnomalyCreateTickets bool
+    // Shared secret Alertmanager sends as a bearer token; ingest is
+    // disabled when empty
+    AlertmanagerToken    string
+	// Notifications
+	NotifyWebhookURLs []string
+	SMTPHost          string
+	SMTPPort          int
+	SMTPUsername      string
+	SMTPPassword      string
+	SMTPFrom          string
+	// Weekly operations digest
+	DigestEnabled    bool
+	DigestWeekday    time.Weekday
+	DigestHour       int
+	DigestRecipients []string
+	// Emerging problem detection by ticket clustering
+	ClusteringEnabled     bool
+	ClusterInterval       time.Duration
+	ClusterLookback       time.Duration
+	ClusterGrowthWindow   time.Duration
+	ClusterSimilarity     float64
+	ClusterMinSize        int
+	ClusterGrowthFactor   float64
+	// How often technician skill profiles are inferred from history
+	SkillRecomputeInterval time.Duration
+	// Syslog and SNMP trap listeners for network gear; empty addresses
+	// disable them
+	SyslogListenAddr   string
+	SNMPTrapListenAddr string
+	SNMPCommunity      string
+	EventMinSeverity   int
+	EventRateThreshold int
+	EventRateWindow    time.Duration
+	// External status pages incidents are published to
+	StatuspageAPIKey string
+	StatuspagePageID string
+	InstatusAPIKey   string
+	InstatusPageID   string
+	// War-room channels for major incidents: Slack bot token, or a Teams
+	// team with an Azure AD app allowed to create channels
+	SlackBotToken     string
+	TeamsTenantID     string
+	TeamsClientID     string
+	TeamsClientSecret string
+	TeamsTeamID       string
+	// How often owners of due or overdue postmortem action items are reminded
+	PostmortemReminderInterval time.Duration
+	// Lifetime of public read-only ticket share links
+	ShareLinkDefaultTTL time.Duration
+	ShareLinkMaxTTL     time.Duration
+	// Mobile push: FCM HTTP v1 with a service account JSON file, and APNs
+	// with a .p8 token signing key
+	FCMCredentialsFile string
+	FCMProjectID       string
+	APNsKeyFile        string
+	APNsKeyID          string
+	APNsTeamID         string
+	APNsTopic          string
+	APNsProduction     bool
+	// Externally reachable API URL used in links sent by email, and the
+	// lifetime of one-click ticket action links
+	PublicAPIURL   string
+	QuickActionTTL time.Duration
+	// Network restrictions for /api/admin and /api/monitor: allowed CIDR
+	// ranges, allowed country codes read from a proxy header, and the
+	// proxies trusted to set X-Forwarded-For
+	AdminIPAllowlist      []string
+	AdminAllowedCountries []string
+	GeoIPCountryHeader    string
+	TrustedProxies        []string
+	// Native TLS: either a certificate and key file, or ACME autocert for
+	// the listed domains. HTTPRedirectPort serves HTTP to HTTPS redirects
+	// and ACME HTTP-01 challenges when set
+	TLSCertFile         string
+	TLSKeyFile          string
+	TLSAutocertDomains  []string
+	TLSAutocertCacheDir string
+	TLSAutocertEmail    string
+	HTTPRedirectPort    string
+	// Security headers: HSTS max-age in seconds (0 disables) and an
+	// optional Content-Security-Policy override
+	HSTSMaxAge            int
+	ContentSecurityPolicy string
+	// Password hashing: "bcrypt" or "argon2id", with the bcrypt cost and
+	// Argon2id memory (KiB), iterations and parallelism. Existing hashes are
+	// upgraded when users log in
+	PasswordHashAlgorithm string
+	BcryptCost            int
+	Argon2Memory          int
+	Argon2Iterations      int
+	Argon2Parallelism     int
+	// Demo mode forces mock LLM output, hash embeddings and synthetic
+	// CloudWatch metrics and disables outbound notifications, so the API
+	// runs without external credentials
+	DemoMode bool
+	// Embedded MongoDB runs a mongod (MongodPath) as a child process with
+	// its data in EmbeddedMongoDBDir, a temporary directory when empty,
+	// instead of connecting to MONGODB_URI. On by default in demo mode when
+	// MONGODB_URI is not set.
+	EmbeddedMongoDB    bool
+	MongodPath         string
+	EmbeddedMongoDBDir string
+	// How often replicas check the shared document index for changes when
+	// MongoDB change streams are unavailable (standalone server)
+	DocumentIndexPollInterval time.Duration
+	// Document indexing: chunks per embeddings request, concurrent
+	// requests, and price per 1K tokens used to estimate cost
+	EmbeddingBatchSize       int
+	EmbeddingConcurrency     int
+	EmbeddingCostPer1KTokens float64
+	// Context window of the LLM in tokens, for models whose limit is not
+	// known (local models); 0 uses the built-in table
+	LLMContextTokens int
+	// AI answer tone and the organization profile added to triage and
+	// solution prompts; admin settings override them
+	AITone                string
+	OrgCompanyName        string
+	OrgSupportedOS        []string
+	OrgStandardTools      []string
+	OrgEscalationContacts []string
+	// Runbook automation: whether approved runs execute, how long a run may
+	// take, and where Ansible playbooks and the inventory live
+	AutomationEnabled  bool
+	AutomationTimeout  time.Duration
+	AnsiblePlaybookDir string
+	AnsibleInventory   string
+	// Endpoint agents: how often agents check in, when a silent agent is
+	// shown as stale, and the free disk percentage flagged as low
+	AgentCheckInInterval time.Duration
+	AgentStaleAfter      time.Duration
+	AgentLowDiskPercent  float64
+	// License renewals: how often expiries are checked and how far ahead of
+	// an expiry date the renewal ticket is opened
+	LicenseReminderInterval time.Duration
+	LicenseRenewalLeadTime  time.Duration
+	// Default anomaly severity policy for metrics without their own: the
+	// absolute z-scores at which anomalies become medium, high and critical,
+	// and the ticket priority for each severity
+	AnomalyMediumZScore   float64
+	AnomalyHighZScore     float64
+	AnomalyCriticalZScore float64
+	AnomalyPriorityMap    map[string]string
+	// Anomaly recovery: consecutive points back within baseline that close
+	// an open anomaly, and whether its ticket is resolved too
+	AnomalyRecoveryPoints int
+	AnomalyResolveTickets bool
+	// Cost monitoring: daily AWS spend per service or account is compared
+	// with the previous CostBaselineDays; spikes above CostZScore that add
+	// at least CostMinIncrease USD open tickets in CostAnomalyCategory
+	CostMonitoringEnabled bool
+	CostPollInterval      time.Duration
+	CostGroupBy           string
+	CostBaselineDays      int
+	CostZScore            float64
+	CostMinIncrease       float64
+	CostAnomalyCategory   string
+	// Synthetic checks: how often the backend looks for due checks, and
+	// the token regional workers authenticate with
+	SyntheticChecksEnabled bool
+	SyntheticTickInterval  time.Duration
+	SyntheticWorkerToken   string
+	// Vulnerability advisories: how often the NVD CVE feed and the vendor
+	// feeds are synced, and the lowest severity that opens security tickets
+	AdvisorySyncEnabled  bool
+	AdvisoryPollInterval time.Duration
+	AdvisoryNVDEnabled   bool
+	AdvisoryNVDAPIKey    string
+	AdvisoryFeedURLs     []string
+	AdvisoryMinSeverity  string
+	// Backups: where POST /api/admin/backup writes its exports, either a
+	// local directory or an S3-compatible bucket
+	BackupStorage    string
+	BackupDir        string
+	BackupS3Bucket   string
+	BackupS3Endpoint string
+	BackupS3Region   string
+	BackupS3Prefix   string
+	// Ticket archiving: resolved and closed tickets older than
+	// TicketArchiveAfter move to the archive collection every
+	// TicketArchiveInterval
+	TicketArchiveEnabled  bool
+	TicketArchiveAfter    time.Duration
+	TicketArchiveInterval time.Duration
+	// How often ticket follow-up reminders are checked for delivery
+	ReminderTickInterval time.Duration
+	// Maintenance windows are copied to a shared Google calendar (service
+	// account the calendar is shared with) and/or an Outlook mailbox's
+	// calendar (app registration with Calendars.ReadWrite) every
+	// CalendarSyncInterval
+	CalendarSyncEnabled       bool
+	CalendarSyncInterval      time.Duration
+	CalendarGoogleCredentials string
+	CalendarGoogleCalendarID  string
+	CalendarOutlookTenantID   string
+	CalendarOutlookClientID   string
+	CalendarOutlookSecret     string
+	CalendarOutlookMailbox    string
+	// ServiceNow connector: the instance and Table API credentials tickets
+	// are pushed with, and how often incident states are pulled back (0
+	// disables the pull)
+	ServiceNowInstanceURL  string
+	ServiceNowUsername     string
+	ServiceNowPassword     string
+	ServiceNowSyncInterval time.Duration
+	// Issue trackers software tickets can be linked to: API tokens and
+	// URLs of GitHub and GitLab, and how often the state of linked issues
+	// is pulled (0 disables the pull)
+	GitHubToken       string
+	GitHubAPIURL      string
+	GitLabToken       string
+	GitLabURL         string
+	IssueSyncInterval time.Duration
+	// Deployment events from CI/CD are accepted with this bearer token, and
+	// deployments within the window before a ticket are shown with it
+	DeploymentWebhookToken string
+	DeploymentWindow       time.Duration
+	// Twilio SMS and WhatsApp channel: account credentials and sender
+	// numbers ("whatsapp:+1..." for WhatsApp). Inbound webhooks to
+	// PublicAPIURL/api/messaging/twilio are signed with the auth token
+	TwilioAccountSID   string
+	TwilioAuthToken    string
+	TwilioSMSFrom      string
+	TwilioWhatsAppFrom string
+	// Whether the embeddable support chat takes visitors who are not
+	// signed in, and how many sessions and messages each visitor IP may
+	// send per hour (0 is no limit)
+	ChatAnonymous            bool
+	ChatAnonymousSessionRate int
+	ChatAnonymousMessageRate int
+	// How often buffered per-user API request counts are written
+	UsageFlushInterval time.Duration
+	// Ticket attachments: contents are kept in GridFS ("gridfs") or in
+	// AttachmentDir ("local"); uploads over AttachmentMaxMB are rejected
+	AttachmentStorage string
+	AttachmentDir     string
+	AttachmentMaxMB   int
+	// Document indexing skips chunks this similar (cosine of embeddings) to
+	// an indexed chunk that also shares most of its wording; 0 only drops
+	// exact copies
+	DuplicateSimilarity float64
+	// Document reviews: how often documents are checked, how long after its
+	// last review or change a document is due again, and product names
+	// whose mention flags a document as out of date
+	DocumentReviewInterval  time.Duration
+	DocumentReviewMaxAge    time.Duration
+	DocumentDeprecatedTerms []string
+	// Document search replaces query words missing from the indexed
+	// documents with the closest word they contain
+	QuerySpellCorrection bool
 }
 
 func Load() *Config {
@@ -46,12 +300,165 @@ func Load() *Config {
 		OpenAIModel:  getEnv("OPENAI_MODEL", "gpt-3.5-turbo"),
 		LocalLLMURL:  getEnv("LOCAL_LLM_URL", ""),
 		AIProvider:   getEnv("AI_PROVIDER", "openai"),
+		TriagePromptVersion: getEnv("TRIAGE_PROMPT_VERSION", "v2"),
+		AutoTriageOnCreate:  getEnvAsBool("AUTO_TRIAGE_ON_CREATE", false),
 		CORSOrigin:   getEnv("CORS_ORIGIN", "http://localhost:3000"),
         MonitoringEnabled:    getEnvAsBool("MONITORING_ENABLED", false),
         MonitorDefaultZScore: getEnvAsFloat("MONITOR_DEFAULT_ZSCORE", 3.0),
         MonitorMinConsecutive: getEnvAsInt("MONITOR_MIN_CONSECUTIVE", 3),
         AWSRegion:            getEnv("AWS_REGION", "us-west-2"),
         AnomalyCreateTickets: getEnvAsBool("ANOMALY_CREATE_TICKETS", true),
+        AlertmanagerToken:    getEnv("ALERTMANAGER_TOKEN", ""),
+		NotifyWebhookURLs: getEnvAsList("NOTIFY_WEBHOOK_URLS"),
+		SMTPHost:          getEnv("SMTP_HOST", ""),
+		SMTPPort:          getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername:      getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:      getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:          getEnv("SMTP_FROM", "intelliops@localhost"),
+		DigestEnabled:     getEnvAsBool("DIGEST_ENABLED", false),
+		DigestHour:        getEnvAsInt("DIGEST_HOUR", 8),
+		DigestRecipients:  getEnvAsList("DIGEST_RECIPIENTS"),
+		ClusteringEnabled:   getEnvAsBool("CLUSTERING_ENABLED", false),
+		ClusterInterval:     getEnvAsDuration("CLUSTER_INTERVAL", 15*time.Minute),
+		ClusterLookback:     getEnvAsDuration("CLUSTER_LOOKBACK", 24*time.Hour),
+		ClusterGrowthWindow: getEnvAsDuration("CLUSTER_GROWTH_WINDOW", time.Hour),
+		ClusterSimilarity:   getEnvAsFloat("CLUSTER_SIMILARITY", 0.82),
+		ClusterMinSize:      getEnvAsInt("CLUSTER_MIN_SIZE", 5),
+		ClusterGrowthFactor: getEnvAsFloat("CLUSTER_GROWTH_FACTOR", 3.0),
+		SkillRecomputeInterval: getEnvAsDuration("SKILL_RECOMPUTE_INTERVAL", 24*time.Hour),
+		SyslogListenAddr:   getEnv("SYSLOG_LISTEN_ADDR", ""),
+		SNMPTrapListenAddr: getEnv("SNMP_TRAP_LISTEN_ADDR", ""),
+		SNMPCommunity:      getEnv("SNMP_COMMUNITY", ""),
+		EventMinSeverity:   getEnvAsInt("EVENT_MIN_SEVERITY", 3),
+		EventRateThreshold: getEnvAsInt("EVENT_RATE_THRESHOLD", 20),
+		EventRateWindow:    getEnvAsDuration("EVENT_RATE_WINDOW", 5*time.Minute),
+		StatuspageAPIKey:   getEnv("STATUSPAGE_API_KEY", ""),
+		StatuspagePageID:   getEnv("STATUSPAGE_PAGE_ID", ""),
+		InstatusAPIKey:     getEnv("INSTATUS_API_KEY", ""),
+		InstatusPageID:     getEnv("INSTATUS_PAGE_ID", ""),
+		SlackBotToken:      getEnv("SLACK_BOT_TOKEN", ""),
+		TeamsTenantID:      getEnv("TEAMS_TENANT_ID", ""),
+		TeamsClientID:      getEnv("TEAMS_CLIENT_ID", ""),
+		TeamsClientSecret:  getEnv("TEAMS_CLIENT_SECRET", ""),
+		TeamsTeamID:        getEnv("TEAMS_TEAM_ID", ""),
+		PostmortemReminderInterval: getEnvAsDuration("POSTMORTEM_REMINDER_INTERVAL", time.Hour),
+		ShareLinkDefaultTTL:        getEnvAsDuration("SHARE_LINK_DEFAULT_TTL", 72*time.Hour),
+		ShareLinkMaxTTL:            getEnvAsDuration("SHARE_LINK_MAX_TTL", 30*24*time.Hour),
+		FCMCredentialsFile:         getEnv("FCM_CREDENTIALS_FILE", ""),
+		FCMProjectID:               getEnv("FCM_PROJECT_ID", ""),
+		APNsKeyFile:                getEnv("APNS_KEY_FILE", ""),
+		APNsKeyID:                  getEnv("APNS_KEY_ID", ""),
+		APNsTeamID:                 getEnv("APNS_TEAM_ID", ""),
+		APNsTopic:                  getEnv("APNS_TOPIC", ""),
+		APNsProduction:             getEnvAsBool("APNS_PRODUCTION", false),
+		PublicAPIURL:               getEnv("PUBLIC_API_URL", "http://localhost:8080"),
+		QuickActionTTL:             getEnvAsDuration("QUICK_ACTION_TTL", 48*time.Hour),
+		AdminIPAllowlist:           getEnvAsList("ADMIN_IP_ALLOWLIST"),
+		AdminAllowedCountries:      getEnvAsList("ADMIN_ALLOWED_COUNTRIES"),
+		GeoIPCountryHeader:         getEnv("GEOIP_COUNTRY_HEADER", "CF-IPCountry"),
+		TrustedProxies:             getEnvAsList("TRUSTED_PROXIES"),
+		TLSCertFile:                getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:                 getEnv("TLS_KEY_FILE", ""),
+		TLSAutocertDomains:         getEnvAsList("TLS_AUTOCERT_DOMAINS"),
+		TLSAutocertCacheDir:        getEnv("TLS_AUTOCERT_CACHE_DIR", "certs"),
+		TLSAutocertEmail:           getEnv("TLS_AUTOCERT_EMAIL", ""),
+		HTTPRedirectPort:           getEnv("HTTP_REDIRECT_PORT", ""),
+		HSTSMaxAge:                 getEnvAsInt("HSTS_MAX_AGE", 31536000),
+		ContentSecurityPolicy:      getEnv("CONTENT_SECURITY_POLICY", ""),
+		PasswordHashAlgorithm:      getEnv("PASSWORD_HASH_ALGORITHM", "bcrypt"),
+		BcryptCost:                 getEnvAsInt("BCRYPT_COST", 10),
+		Argon2Memory:               getEnvAsInt("ARGON2_MEMORY", 64*1024),
+		Argon2Iterations:           getEnvAsInt("ARGON2_ITERATIONS", 3),
+		Argon2Parallelism:          getEnvAsInt("ARGON2_PARALLELISM", 2),
+		DemoMode:                   getEnvAsBool("DEMO_MODE", false),
+		MongodPath:                 getEnv("MONGOD_PATH", "mongod"),
+		EmbeddedMongoDBDir:         getEnv("EMBEDDED_MONGODB_DIR", ""),
+		DocumentIndexPollInterval:  getEnvAsDuration("DOCUMENT_INDEX_POLL_INTERVAL", 30*time.Second),
+		EmbeddingBatchSize:         getEnvAsInt("EMBEDDING_BATCH_SIZE", 100),
+		EmbeddingConcurrency:       getEnvAsInt("EMBEDDING_CONCURRENCY", 4),
+		EmbeddingCostPer1KTokens:   getEnvAsFloat("EMBEDDING_COST_PER_1K_TOKENS", 0.00002),
+		LLMContextTokens:           getEnvAsInt("LLM_CONTEXT_TOKENS", 0),
+		AITone:                     getEnv("AI_TONE", ""),
+		OrgCompanyName:             getEnv("ORG_COMPANY_NAME", ""),
+		OrgSupportedOS:             getEnvAsList("ORG_SUPPORTED_OS"),
+		OrgStandardTools:           getEnvAsList("ORG_STANDARD_TOOLS"),
+		OrgEscalationContacts:      getEnvAsList("ORG_ESCALATION_CONTACTS"),
+		AutomationEnabled:          getEnvAsBool("AUTOMATION_ENABLED", true),
+		AutomationTimeout:          getEnvAsDuration("AUTOMATION_TIMEOUT", 10*time.Minute),
+		AnsiblePlaybookDir:         getEnv("ANSIBLE_PLAYBOOK_DIR", "runbooks"),
+		AnsibleInventory:           getEnv("ANSIBLE_INVENTORY", ""),
+		AgentCheckInInterval:       getEnvAsDuration("AGENT_CHECKIN_INTERVAL", 5*time.Minute),
+		AgentStaleAfter:            getEnvAsDuration("AGENT_STALE_AFTER", 30*time.Minute),
+		AgentLowDiskPercent:        getEnvAsFloat("AGENT_LOW_DISK_PERCENT", 10),
+		LicenseReminderInterval:    getEnvAsDuration("LICENSE_REMINDER_INTERVAL", time.Hour),
+		LicenseRenewalLeadTime:     getEnvAsDuration("LICENSE_RENEWAL_LEAD_TIME", 30*24*time.Hour),
+		AnomalyMediumZScore:        getEnvAsFloat("ANOMALY_MEDIUM_ZSCORE", 3),
+		AnomalyHighZScore:          getEnvAsFloat("ANOMALY_HIGH_ZSCORE", 4),
+		AnomalyCriticalZScore:      getEnvAsFloat("ANOMALY_CRITICAL_ZSCORE", 5),
+		AnomalyPriorityMap:         getEnvAsMap("ANOMALY_PRIORITY_MAP"),
+		AnomalyRecoveryPoints:      getEnvAsInt("ANOMALY_RECOVERY_POINTS", 3),
+		AnomalyResolveTickets:      getEnvAsBool("ANOMALY_RESOLVE_TICKETS", false),
+		CostMonitoringEnabled:      getEnvAsBool("COST_MONITORING_ENABLED", false),
+		CostPollInterval:           getEnvAsDuration("COST_POLL_INTERVAL", 6*time.Hour),
+		CostGroupBy:                strings.ToUpper(getEnv("COST_GROUP_BY", "SERVICE")),
+		CostBaselineDays:           getEnvAsInt("COST_BASELINE_DAYS", 28),
+		CostZScore:                 getEnvAsFloat("COST_ZSCORE", 3),
+		CostMinIncrease:            getEnvAsFloat("COST_MIN_INCREASE", 50),
+		CostAnomalyCategory:        getEnv("COST_ANOMALY_CATEGORY", "Finance"),
+		SyntheticChecksEnabled:     getEnvAsBool("SYNTHETIC_CHECKS_ENABLED", true),
+		SyntheticTickInterval:      getEnvAsDuration("SYNTHETIC_TICK_INTERVAL", 10*time.Second),
+		SyntheticWorkerToken:       getEnv("SYNTHETIC_WORKER_TOKEN", ""),
+		AdvisorySyncEnabled:        getEnvAsBool("ADVISORY_SYNC_ENABLED", false),
+		AdvisoryPollInterval:       getEnvAsDuration("ADVISORY_POLL_INTERVAL", 6*time.Hour),
+		AdvisoryNVDEnabled:         getEnvAsBool("ADVISORY_NVD_ENABLED", true),
+		AdvisoryNVDAPIKey:          getEnv("ADVISORY_NVD_API_KEY", ""),
+		AdvisoryFeedURLs:           getEnvAsList("ADVISORY_FEED_URLS"),
+		AdvisoryMinSeverity:        getEnv("ADVISORY_MIN_SEVERITY", "medium"),
+		BackupStorage:              strings.ToLower(getEnv("BACKUP_STORAGE", "local")),
+		BackupDir:                  getEnv("BACKUP_DIR", "./backups"),
+		BackupS3Bucket:             getEnv("BACKUP_S3_BUCKET", ""),
+		BackupS3Endpoint:           getEnv("BACKUP_S3_ENDPOINT", ""),
+		BackupS3Region:             getEnv("BACKUP_S3_REGION", ""),
+		BackupS3Prefix:             getEnv("BACKUP_S3_PREFIX", "intelliops/"),
+		TicketArchiveEnabled:       getEnvAsBool("TICKET_ARCHIVE_ENABLED", false),
+		TicketArchiveAfter:         getEnvAsDuration("TICKET_ARCHIVE_AFTER", 180*24*time.Hour),
+		TicketArchiveInterval:      getEnvAsDuration("TICKET_ARCHIVE_INTERVAL", 24*time.Hour),
+		ReminderTickInterval:       getEnvAsDuration("REMINDER_TICK_INTERVAL", time.Minute),
+		CalendarSyncEnabled:        getEnvAsBool("CALENDAR_SYNC_ENABLED", false),
+		CalendarSyncInterval:       getEnvAsDuration("CALENDAR_SYNC_INTERVAL", 15*time.Minute),
+		CalendarGoogleCredentials:  getEnv("CALENDAR_GOOGLE_CREDENTIALS_FILE", ""),
+		CalendarGoogleCalendarID:   getEnv("CALENDAR_GOOGLE_CALENDAR_ID", ""),
+		CalendarOutlookTenantID:    getEnv("CALENDAR_OUTLOOK_TENANT_ID", ""),
+		CalendarOutlookClientID:    getEnv("CALENDAR_OUTLOOK_CLIENT_ID", ""),
+		CalendarOutlookSecret:      getEnv("CALENDAR_OUTLOOK_CLIENT_SECRET", ""),
+		CalendarOutlookMailbox:     getEnv("CALENDAR_OUTLOOK_MAILBOX", ""),
+		ServiceNowInstanceURL:      strings.TrimRight(getEnv("SERVICENOW_INSTANCE_URL", ""), "/"),
+		ServiceNowUsername:         getEnv("SERVICENOW_USERNAME", ""),
+		ServiceNowPassword:         getEnv("SERVICENOW_PASSWORD", ""),
+		ServiceNowSyncInterval:     getEnvAsDuration("SERVICENOW_SYNC_INTERVAL", 15*time.Minute),
+		GitHubToken:                getEnv("GITHUB_TOKEN", ""),
+		GitHubAPIURL:               strings.TrimRight(getEnv("GITHUB_API_URL", "https://api.github.com"), "/"),
+		GitLabToken:                getEnv("GITLAB_TOKEN", ""),
+		GitLabURL:                  strings.TrimRight(getEnv("GITLAB_URL", "https://gitlab.com"), "/"),
+		IssueSyncInterval:          getEnvAsDuration("ISSUE_SYNC_INTERVAL", 15*time.Minute),
+		DeploymentWebhookToken:     getEnv("DEPLOYMENT_WEBHOOK_TOKEN", ""),
+		DeploymentWindow:           getEnvAsDuration("DEPLOYMENT_CORRELATION_WINDOW", time.Hour),
+		TwilioAccountSID:           getEnv("TWILIO_ACCOUNT_SID", ""),
+		TwilioAuthToken:            getEnv("TWILIO_AUTH_TOKEN", ""),
+		TwilioSMSFrom:              getEnv("TWILIO_SMS_FROM", ""),
+		TwilioWhatsAppFrom:         getEnv("TWILIO_WHATSAPP_FROM", ""),
+		ChatAnonymous:              getEnvAsBool("CHAT_ANONYMOUS_ENABLED", true),
+		ChatAnonymousSessionRate:   getEnvAsInt("CHAT_ANONYMOUS_SESSIONS_PER_HOUR", 10),
+		ChatAnonymousMessageRate:   getEnvAsInt("CHAT_ANONYMOUS_MESSAGES_PER_HOUR", 60),
+		UsageFlushInterval:         getEnvAsDuration("USAGE_FLUSH_INTERVAL", time.Minute),
+		AttachmentStorage:          strings.ToLower(getEnv("ATTACHMENT_STORAGE", "gridfs")),
+		AttachmentDir:              getEnv("ATTACHMENT_DIR", "./attachments"),
+		AttachmentMaxMB:            getEnvAsInt("ATTACHMENT_MAX_MB", 10),
+		DuplicateSimilarity:        getEnvAsFloat("DOCUMENT_DUPLICATE_SIMILARITY", 0.95),
+		DocumentReviewInterval:     getEnvAsDuration("DOCUMENT_REVIEW_INTERVAL", 24*time.Hour),
+		DocumentReviewMaxAge:       getEnvAsDuration("DOCUMENT_REVIEW_MAX_AGE", 365*24*time.Hour),
+		DocumentDeprecatedTerms:    getEnvAsList("DOCUMENT_DEPRECATED_TERMS"),
+		QuerySpellCorrection:       getEnvAsBool("QUERY_SPELL_CORRECTION", true),
 	}
 
 	// Parse JWT expiration duration
@@ -72,9 +479,53 @@ func Load() *Config {
     }
     config.MonitorPollInterval = pollDur
 
+	// Parse digest weekday
+	config.DigestWeekday = time.Monday
+	weekday := strings.ToLower(getEnv("DIGEST_WEEKDAY", "monday"))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.ToLower(d.String()) == weekday {
+			config.DigestWeekday = d
+		}
+	}
+
+	if config.DemoMode {
+		config.applyDemoMode()
+	}
+	config.EmbeddedMongoDB = getEnvAsBool("MONGODB_EMBEDDED", config.DemoMode && os.Getenv("MONGODB_URI") == "")
+
 	return config
 }
 
+// applyDemoMode clears every external credential so services fall back to
+// their built-in mocks. Without MONGODB_URI the data goes to an embedded
+// MongoDB; otherwise to its own database unless DATABASE_NAME is set.
+func (c *Config) applyDemoMode() {
+	log.Println("DEMO_MODE enabled: external services are mocked")
+	c.AIProvider = "mock"
+	c.OpenAIAPIKey = ""
+	c.LocalLLMURL = ""
+	c.NotifyWebhookURLs = nil
+	c.SMTPHost = ""
+	c.StatuspageAPIKey = ""
+	c.InstatusAPIKey = ""
+	c.SlackBotToken = ""
+	c.TeamsTeamID = ""
+	c.FCMCredentialsFile = ""
+	c.APNsKeyFile = ""
+	c.TLSAutocertDomains = nil
+	c.AutomationEnabled = false
+	c.BackupStorage = "local"
+	c.CalendarGoogleCredentials = ""
+	c.CalendarOutlookMailbox = ""
+	c.ServiceNowInstanceURL = ""
+	c.GitHubToken = ""
+	c.GitLabToken = ""
+	c.TwilioAccountSID = ""
+	if os.Getenv("DATABASE_NAME") == "" {
+		c.DatabaseName = "intelliops_demo"
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -108,3 +559,38 @@ func getEnvAsFloat(key string, defaultValue float64) float64 {
     }
     return defaultValue
 }
+
+// getEnvAsList splits a comma-separated variable, dropping empty entries.
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// getEnvAsMap parses a comma-separated list of key=value pairs.
+func getEnvAsMap(key string) map[string]string {
+	values := map[string]string{}
+	for _, pair := range getEnvAsList(key) {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			log.Printf("Ignoring %s entry %q: expected key=value", key, pair)
+			continue
+		}
+		values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return values
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+		log.Printf("Invalid %s, using %s", key, defaultValue)
+	}
+	return defaultValue
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// embeddedStartTimeout is how long an embedded mongod may take to accept
+// connections.
+const embeddedStartTimeout = 30 * time.Second
+
+// EmbeddedMongoDB is a mongod the backend runs as a child process, so demos
+// and tests need no MongoDB server of their own. Data lives in a directory
+// that is removed on Stop unless it was given.
+type EmbeddedMongoDB struct {
+	URI     string
+	cmd     *exec.Cmd
+	dataDir string
+	tempDir bool
+	exited  chan error
+}
+
+// StartEmbedded starts mongodPath (a mongod binary, looked up in PATH) on a
+// free loopback port with its data in dataDir, or a temporary directory
+// when dataDir is empty, and waits until it accepts connections.
+func StartEmbedded(mongodPath, dataDir string) (*EmbeddedMongoDB, error) {
+	binary, err := exec.LookPath(mongodPath)
+	if err != nil {
+		return nil, fmt.Errorf("embedded MongoDB needs a mongod binary (set MONGOD_PATH): %w", err)
+	}
+
+	e := &EmbeddedMongoDB{dataDir: dataDir, exited: make(chan error, 1)}
+	if e.dataDir == "" {
+		if e.dataDir, err = os.MkdirTemp("", "intelliops-mongo-"); err != nil {
+			return nil, err
+		}
+		e.tempDir = true
+	} else if err := os.MkdirAll(e.dataDir, 0o700); err != nil {
+		return nil, err
+	}
+
+	port, err := freePort()
+	if err != nil {
+		e.cleanup()
+		return nil, err
+	}
+	e.URI = fmt.Sprintf("mongodb://127.0.0.1:%d/?directConnection=true", port)
+	e.cmd = exec.Command(binary, "--port", fmt.Sprint(port), "--bind_ip", "127.0.0.1", "--dbpath", e.dataDir, "--quiet")
+	if err := e.cmd.Start(); err != nil {
+		e.cleanup()
+		return nil, err
+	}
+	go func() { e.exited <- e.cmd.Wait() }()
+
+	if err := e.waitReady(); err != nil {
+		e.Stop()
+		return nil, err
+	}
+	log.Printf("Embedded MongoDB started on port %d with data in %s", port, e.dataDir)
+	return e, nil
+}
+
+// waitReady pings the server until it answers, it exits or the start
+// timeout passes.
+func (e *EmbeddedMongoDB) waitReady() error {
+	ctx, cancel := context.WithTimeout(context.Background(), embeddedStartTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(e.URI).SetServerSelectionTimeout(time.Second))
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+
+	for {
+		if err := client.Ping(ctx, nil); err == nil {
+			return nil
+		}
+		select {
+		case err := <-e.exited:
+			e.exited <- err
+			return fmt.Errorf("embedded mongod exited during startup: %v", err)
+		case <-ctx.Done():
+			return fmt.Errorf("embedded mongod did not start within %s", embeddedStartTimeout)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// Stop shuts mongod down, killing it if it does not exit within ten
+// seconds, and removes a temporary data directory.
+func (e *EmbeddedMongoDB) Stop() {
+	if e.cmd != nil && e.cmd.Process != nil {
+		e.cmd.Process.Signal(os.Interrupt)
+		select {
+		case <-e.exited:
+		case <-time.After(10 * time.Second):
+			e.cmd.Process.Kill()
+			<-e.exited
+		}
+	}
+	e.cleanup()
+}
+
+func (e *EmbeddedMongoDB) cleanup() {
+	if e.tempDir {
+		os.RemoveAll(e.dataDir)
+	}
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type AdvisoryHandler struct {
+	advisories *services.AdvisoryService
+}
+
+func NewAdvisoryHandler(advisories *services.AdvisoryService) *AdvisoryHandler {
+	return &AdvisoryHandler{advisories: advisories}
+}
+
+// ListAdvisories returns the latest ?limit (default 100) advisories,
+// filtered by ?severity and, with ?affected=true, to those affecting assets.
+func (h *AdvisoryHandler) ListAdvisories(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+
+	advisories, err := h.advisories.List(context.Background(), c.Query("severity"), c.Query("affected") == "true", limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch advisories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"advisories": advisories})
+}
+
+func (h *AdvisoryHandler) GetAdvisory(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid advisory ID"})
+		return
+	}
+
+	advisory, err := h.advisories.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Advisory not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch advisory"})
+		return
+	}
+
+	c.JSON(http.StatusOK, advisory)
+}
+
+// SyncAdvisories fetches the feeds now instead of waiting for the next poll.
+func (h *AdvisoryHandler) SyncAdvisories(c *gin.Context) {
+	result, err := h.advisories.Sync(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync advisories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ImportAdvisories stores advisories pushed from a vendor feed.
+func (h *AdvisoryHandler) ImportAdvisories(c *gin.Context) {
+	var req models.AdvisoryImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i := range req.Advisories {
+		if err := services.ValidateAdvisory(&req.Advisories[i]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result, err := h.advisories.Import(context.Background(), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import advisories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
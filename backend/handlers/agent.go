@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type AgentHandler struct {
+	db        *database.MongoDB
+	agent     *services.AgentService
+	summaries *services.SummaryService
+}
+
+func NewAgentHandler(db *database.MongoDB, agent *services.AgentService, summaries *services.SummaryService) *AgentHandler {
+	return &AgentHandler{db: db, agent: agent, summaries: summaries}
+}
+
+// GetAgentTools lists the tools the diagnostic agent is allowed to call
+func (h *AgentHandler) GetAgentTools(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"tools": h.agent.Tools()})
+}
+
+// DiagnoseTicket runs the diagnostic agent on a ticket and appends its
+// report to the ticket
+func (h *AgentHandler) DiagnoseTicket(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.AgentRunRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	ctx, ok := generationContext(c)
+	if !ok {
+		return
+	}
+
+	var ticket models.Ticket
+	err = h.db.GetCollection("tickets").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&ticket)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	run, err := h.agent.Diagnose(ctx, ticket, req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store diagnostic run"})
+		return
+	}
+	h.summaries.RefreshIfPresent(ticket.ID)
+
+	c.JSON(http.StatusOK, run)
+}
+
+// GetTicketDiagnostics returns every agent run for a ticket with the full
+// audit of tool calls
+func (h *AgentHandler) GetTicketDiagnostics(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	runs, err := h.agent.ListRuns(context.Background(), objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch diagnostic runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
@@ -1,56 +1,29 @@
 package handlers
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
+	"log"
 	"net/http"
-	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 
 	"intelliops-ai-copilot/database"
 	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
 )
 
 type AIHandler struct {
-	db           *database.MongoDB
-	openAIAPIKey string
-	openAIModel  string
-	localLLMURL  string
-	aiProvider   string
+	db          *database.MongoDB
+	triage      *services.TriageService
+	experiments *services.ExperimentService
 }
 
-type OpenAIRequest struct {
-	Model       string    `json:"model"`
-	Messages    []Message `json:"messages"`
-	Temperature float64   `json:"temperature"`
-	MaxTokens   int       `json:"max_tokens"`
-}
-
-type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type OpenAIResponse struct {
-	Choices []Choice `json:"choices"`
-}
-
-type Choice struct {
-	Message Message `json:"message"`
-}
-
-func NewAIHandler(db *database.MongoDB, openAIAPIKey, openAIModel, localLLMURL, aiProvider string) *AIHandler {
+func NewAIHandler(db *database.MongoDB, triage *services.TriageService, experiments *services.ExperimentService) *AIHandler {
 	return &AIHandler{
-		db:           db,
-		openAIAPIKey: openAIAPIKey,
-		openAIModel:  openAIModel,
-		localLLMURL:  localLLMURL,
-		aiProvider:   aiProvider,
+		db:          db,
+		triage:      triage,
+		experiments: experiments,
 	}
 }
 
@@ -61,245 +34,100 @@ func (h *AIHandler) TriageTicket(c *gin.Context) {
 		return
 	}
 
-	var response *models.TriageResponse
-	var err error
-
-	// Determine which AI provider to use
-	switch h.aiProvider {
-	case "local":
-		if h.localLLMURL == "" {
-			response = h.generateMockTriageResponse(req)
-		} else {
-			response, err = h.callLocalLLM(req)
-			if err != nil {
-				// Fallback to mock if local LLM fails
-				response = h.generateMockTriageResponse(req)
-			}
-		}
-	case "openai":
-		if h.openAIAPIKey == "" {
-			response = h.generateMockTriageResponse(req)
-		} else {
-			response, err = h.callOpenAI(req)
-			if err != nil {
-				// Fallback to mock if OpenAI fails
-				response = h.generateMockTriageResponse(req)
-			}
-		}
-	default:
-		response = h.generateMockTriageResponse(req)
-	}
-
-	c.JSON(http.StatusOK, response)
-}
-
-func (h *AIHandler) callOpenAI(req models.TriageRequest) (*models.TriageResponse, error) {
-	prompt := fmt.Sprintf(`
-Analyze the following IT support ticket and provide triage information:
-
-Title: %s
-Description: %s
-
-Please respond with a JSON object containing:
-- category: One of "Network Issue", "Hardware Issue", "Software Issue", "Security Issue", "Performance Issue", or "Other"
-- summary: A brief 1-2 sentence summary of the issue
-- priority: One of "low", "medium", "high", or "critical"
-- suggestedTechnician: A suggested technician name (use Indian names like "Ravi Kumar", "Priya Sharma", "Amit Patel", "Sneha Singh")
-- confidence: A number between 0.0 and 1.0 indicating confidence in the analysis
-- reasoning: Brief explanation of the categorization
-
-Respond only with valid JSON, no additional text.
-`, req.Title, req.Description)
-
-	openAIReq := OpenAIRequest{
-		Model: h.openAIModel,
-		Messages: []Message{
-			{
-				Role:    "system",
-				Content: "You are an expert IT support triage specialist. Analyze tickets and provide structured triage information.",
-			},
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Temperature: 0.3,
-		MaxTokens:   500,
-	}
-
-	jsonData, err := json.Marshal(openAIReq)
-	if err != nil {
-		return nil, err
-	}
-
-	httpReq, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, err
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+h.openAIAPIKey)
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		return nil, err
+	ctx, ok := generationContext(c)
+	if !ok {
+		return
 	}
-	defer resp.Body.Close()
 
-	var openAIResp OpenAIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&openAIResp); err != nil {
-		return nil, err
+	// When an experiment is running, the request is served by one of its
+	// variants and the run is logged for the results endpoint.
+	opts := models.TriageOptions{}
+	exp, variant := h.experiments.Assign(ctx)
+	if variant != nil {
+		opts = variant.Options
 	}
 
-	if len(openAIResp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
-	}
+	run := h.triage.Triage(ctx, req, opts)
 
-	// Parse the JSON response from OpenAI
-	var triageResp models.TriageResponse
-	if err := json.Unmarshal([]byte(openAIResp.Choices[0].Message.Content), &triageResp); err != nil {
-		// If parsing fails, return mock response
-		return h.generateMockTriageResponse(req), nil
+	if exp != nil {
+		runID, err := h.experiments.RecordRun(ctx, exp, variant.Name, run)
+		if err != nil {
+			log.Printf("Failed to record experiment run: %v", err)
+		} else {
+			run.Result.RunID = runID.Hex()
+		}
 	}
 
-	return &triageResp, nil
+	c.JSON(http.StatusOK, run.Result)
 }
 
-func (h *AIHandler) callLocalLLM(req models.TriageRequest) (*models.TriageResponse, error) {
-	prompt := fmt.Sprintf(`
-Analyze the following IT support ticket and provide triage information:
-
-Title: %s
-Description: %s
-
-Please respond with a JSON object containing:
-- category: One of "Network Issue", "Hardware Issue", "Software Issue", "Security Issue", "Performance Issue", or "Other"
-- summary: A brief 1-2 sentence summary of the issue
-- priority: One of "low", "medium", "high", or "critical"
-- suggestedTechnician: A suggested technician name (use Indian names like "Ravi Kumar", "Priya Sharma", "Amit Patel", "Sneha Singh")
-- confidence: A number between 0.0 and 1.0 indicating confidence in the analysis
-- reasoning: Brief explanation of the categorization
-
-Respond only with valid JSON, no additional text.
-`, req.Title, req.Description)
-
-	// Create request for local LLM (assuming OpenAI-compatible API)
-	localReq := OpenAIRequest{
-		Model: "local-model", // This will be ignored by most local LLMs
-		Messages: []Message{
-			{
-				Role:    "system",
-				Content: "You are an expert IT support triage specialist. Analyze tickets and provide structured triage information.",
-			},
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Temperature: 0.3,
-		MaxTokens:   500,
+// TriageSandbox runs the same ticket through the production configuration and
+// each requested variant without touching any ticket, so admins can compare
+// providers, models and prompt versions side by side.
+func (h *AIHandler) TriageSandbox(c *gin.Context) {
+	var req models.TriageSandboxRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-
-	jsonData, err := json.Marshal(localReq)
-	if err != nil {
-		return nil, err
+	if len(req.Variants) > 5 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At most 5 variants can be compared at once"})
+		return
 	}
 
-	// Call local LLM endpoint
-	httpReq, err := http.NewRequest("POST", h.localLLMURL+"/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, err
+	ctx := context.Background()
+	for _, variant := range req.Variants {
+		if variant.Provider != "" && variant.Provider != "openai" && variant.Provider != "local" && variant.Provider != "mock" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Provider must be 'openai', 'local' or 'mock'"})
+			return
+		}
+		if variant.PromptVersion != "" {
+			if _, err := h.triage.GetPromptTemplate(ctx, "triage", variant.PromptVersion); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 60 * time.Second} // Longer timeout for local LLMs
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		return nil, err
+	triageReq := models.TriageRequest{Title: req.Title, Description: req.Description}
+	response := models.TriageSandboxResponse{
+		Baseline: h.triage.Triage(ctx, triageReq, models.TriageOptions{}),
 	}
-	defer resp.Body.Close()
-
-	var localResp OpenAIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&localResp); err != nil {
-		return nil, err
+	for _, variant := range req.Variants {
+		response.Variants = append(response.Variants, h.triage.Triage(ctx, triageReq, variant))
 	}
 
-	if len(localResp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from local LLM")
-	}
+	c.JSON(http.StatusOK, response)
+}
 
-	// Parse the JSON response from local LLM
-	var triageResp models.TriageResponse
-	if err := json.Unmarshal([]byte(localResp.Choices[0].Message.Content), &triageResp); err != nil {
-		// If parsing fails, return mock response
-		return h.generateMockTriageResponse(req), nil
+// ListPromptTemplates returns built-in and admin-created prompt templates
+func (h *AIHandler) ListPromptTemplates(c *gin.Context) {
+	templates, err := h.triage.ListPromptTemplates(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch prompt templates"})
+		return
 	}
 
-	return &triageResp, nil
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
 }
 
-func (h *AIHandler) generateMockTriageResponse(req models.TriageRequest) *models.TriageResponse {
-	// Simple keyword-based mock triage
-	title := req.Title
-	description := req.Description
-	combined := title + " " + description
-
-	var category models.TicketCategory
-	var priority models.TicketPriority
-	var suggestedTechnician string
-
-	// Determine category based on keywords
-	if contains(combined, []string{"network", "wifi", "internet", "connection", "router", "switch"}) {
-		category = models.CategoryNetwork
-		suggestedTechnician = "Ravi Kumar"
-	} else if contains(combined, []string{"hardware", "computer", "laptop", "desktop", "printer", "monitor"}) {
-		category = models.CategoryHardware
-		suggestedTechnician = "Amit Patel"
-	} else if contains(combined, []string{"software", "application", "program", "install", "update"}) {
-		category = models.CategorySoftware
-		suggestedTechnician = "Priya Sharma"
-	} else if contains(combined, []string{"security", "virus", "malware", "breach", "access"}) {
-		category = models.CategorySecurity
-		suggestedTechnician = "Sneha Singh"
-	} else if contains(combined, []string{"slow", "performance", "lag", "freeze", "crash"}) {
-		category = models.CategoryPerformance
-		suggestedTechnician = "Rajesh Kumar"
-	} else {
-		category = models.CategoryOther
-		suggestedTechnician = "General Support"
+// CreatePromptTemplate stores a new, immutable prompt template version
+func (h *AIHandler) CreatePromptTemplate(c *gin.Context) {
+	var req models.CreatePromptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	// Determine priority based on keywords
-	if contains(combined, []string{"urgent", "critical", "down", "emergency", "outage"}) {
-		priority = models.PriorityCritical
-	} else if contains(combined, []string{"high", "important", "asap", "immediately"}) {
-		priority = models.PriorityHigh
-	} else if contains(combined, []string{"low", "minor", "when possible"}) {
-		priority = models.PriorityLow
-	} else {
-		priority = models.PriorityMedium
-	}
+	user := c.MustGet("user").(models.User)
 
-	return &models.TriageResponse{
-		Category:            category,
-		Summary:             fmt.Sprintf("Issue categorized as %s based on ticket content analysis", category),
-		Priority:            priority,
-		SuggestedTechnician: suggestedTechnician,
-		Confidence:          0.75,
-		Reasoning:           "Analysis based on keyword matching and ticket content patterns",
+	tmpl, err := h.triage.CreatePromptTemplate(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-}
 
-func contains(text string, keywords []string) bool {
-	text = strings.ToLower(text)
-	for _, keyword := range keywords {
-		if strings.Contains(text, strings.ToLower(keyword)) {
-			return true
-		}
-	}
-	return false
+	c.JSON(http.StatusCreated, tmpl)
 }
 
 func (h *AIHandler) GetTechnicians(c *gin.Context) {
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type AlertIngestHandler struct {
+	ingest *services.AlertIngestService
+}
+
+func NewAlertIngestHandler(ingest *services.AlertIngestService) *AlertIngestHandler {
+	return &AlertIngestHandler{ingest: ingest}
+}
+
+// IngestAlertmanager receives Prometheus Alertmanager webhook notifications.
+func (h *AlertIngestHandler) IngestAlertmanager(c *gin.Context) {
+	var payload models.AlertmanagerWebhook
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.ingest.IngestAlertmanager(context.Background(), payload)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to ingest alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type AlertSourceHandler struct {
+	sources *services.AlertSourceService
+}
+
+func NewAlertSourceHandler(sources *services.AlertSourceService) *AlertSourceHandler {
+	return &AlertSourceHandler{sources: sources}
+}
+
+func (h *AlertSourceHandler) CreateAlertSource(c *gin.Context) {
+	var req models.AlertSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateAlertSource(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := h.sources.GetBySlug(context.Background(), req.Slug); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Alert source with this slug already exists"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	source, err := h.sources.Create(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create alert source"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, source)
+}
+
+func (h *AlertSourceHandler) ListAlertSources(c *gin.Context) {
+	sources, err := h.sources.List(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert sources"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sources": sources})
+}
+
+func (h *AlertSourceHandler) UpdateAlertSource(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert source ID"})
+		return
+	}
+
+	var req models.AlertSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateAlertSource(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if existing, err := h.sources.GetBySlug(context.Background(), req.Slug); err == nil && existing.ID != objectID {
+		c.JSON(http.StatusConflict, gin.H{"error": "Alert source with this slug already exists"})
+		return
+	}
+
+	source, err := h.sources.Update(context.Background(), objectID, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert source not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert source"})
+		return
+	}
+
+	c.JSON(http.StatusOK, source)
+}
+
+func (h *AlertSourceHandler) DeleteAlertSource(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert source ID"})
+		return
+	}
+
+	if err := h.sources.Delete(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert source not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete alert source"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert source deleted successfully"})
+}
+
+// RotateAlertSourceToken issues a new webhook token for a source
+func (h *AlertSourceHandler) RotateAlertSourceToken(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert source ID"})
+		return
+	}
+
+	source, err := h.sources.RotateToken(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert source not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, source)
+}
+
+// TestAlertSource shows the alerts a sample payload maps to without
+// ingesting them
+func (h *AlertSourceHandler) TestAlertSource(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert source ID"})
+		return
+	}
+
+	var payload interface{}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	source, err := h.sources.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert source not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert source"})
+		return
+	}
+
+	alerts, err := h.sources.Map(source, payload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"alerts": alerts})
+}
+
+// IngestWebhook receives alerts for the source named by :slug. The token is
+// sent as a bearer token or, for tools that cannot set headers, ?token=.
+func (h *AlertSourceHandler) IngestWebhook(c *gin.Context) {
+	source, err := h.sources.GetBySlug(context.Background(), c.Param("slug"))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alert source not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert source"})
+		return
+	}
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" {
+		token = c.Query("token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(source.Token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook token"})
+		return
+	}
+	if !source.Enabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Alert source is disabled"})
+		return
+	}
+
+	var payload interface{}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.sources.Ingest(context.Background(), source, payload)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
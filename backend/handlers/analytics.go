@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type AnalyticsHandler struct {
+	analytics *services.AnalyticsService
+	questions *services.AnalyticsQuestionService
+}
+
+func NewAnalyticsHandler(analytics *services.AnalyticsService, questions *services.AnalyticsQuestionService) *AnalyticsHandler {
+	return &AnalyticsHandler{analytics: analytics, questions: questions}
+}
+
+// analyticsRange parses ?from and ?to (RFC 3339), by default the last 90
+// days, writing a 400 and returning false when they are invalid.
+func analyticsRange(c *gin.Context) (time.Time, time.Time, bool) {
+	to := time.Now()
+	from := to.Add(-90 * 24 * time.Hour)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 time"})
+			return from, to, false
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 time"})
+			return from, to, false
+		}
+		to = t
+	}
+	if !to.After(from) || to.Sub(from) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from, and at most a year later"})
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// GetTicketHeatmap counts tickets created between ?from and ?to by weekday
+// and hour in ?tz (an IANA zone, default UTC). ?location and ?department
+// ("unknown" for requesters without one) narrow it to some requesters
+// (admin only)
+func (h *AnalyticsHandler) GetTicketHeatmap(c *gin.Context) {
+	from, to, ok := analyticsRange(c)
+	if !ok {
+		return
+	}
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time zone"})
+		return
+	}
+
+	heatmap, err := h.analytics.Heatmap(context.Background(), from, to, loc,
+		strings.TrimSpace(c.Query("location")), strings.TrimSpace(c.Query("department")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute heatmap"})
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+// GetTicketLocations counts tickets created between ?from and ?to by the
+// requester's location and department (admin only)
+func (h *AnalyticsHandler) GetTicketLocations(c *gin.Context) {
+	from, to, ok := analyticsRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.analytics.Locations(context.Background(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute location breakdown"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetTicketDepartments counts tickets created between ?from and ?to per
+// department, rolled up the org hierarchy (admin only)
+func (h *AnalyticsHandler) GetTicketDepartments(c *gin.Context) {
+	from, to, ok := analyticsRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.analytics.Departments(context.Background(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute department breakdown"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RunAnalyticsQuery runs a chart query in the analytics DSL: ticket
+// dimensions and time buckets, count/sum/avg/min/max measures, filters and
+// a date range of at most a year (admin only)
+func (h *AnalyticsHandler) RunAnalyticsQuery(c *gin.Context) {
+	var query models.AnalyticsQuery
+	if err := c.ShouldBindJSON(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.analytics.Query(context.Background(), query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAnalyticsQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run analytics query"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// AskAnalytics answers a natural-language question about ticket data,
+// returning the generated query alongside the answer. Analytics cover every
+// ticket, so like the query endpoint it is for admins only
+func (h *AnalyticsHandler) AskAnalytics(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	if user.Role != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can ask analytics questions"})
+		return
+	}
+	var req models.AskAnalyticsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.questions.Ask(context.Background(), req.Question)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAnalyticsQuery) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "query": response.Query})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to answer analytics question"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
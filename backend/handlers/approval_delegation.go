@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type ApprovalDelegationHandler struct {
+	delegations *services.ApprovalDelegationService
+}
+
+func NewApprovalDelegationHandler(delegations *services.ApprovalDelegationService) *ApprovalDelegationHandler {
+	return &ApprovalDelegationHandler{delegations: delegations}
+}
+
+// ListApprovalDelegations returns the delegations the signed-in user gave
+// and received that have not ended
+func (h *ApprovalDelegationHandler) ListApprovalDelegations(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	delegations, err := h.delegations.List(context.Background(), user.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch approval delegations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, delegations)
+}
+
+// CreateApprovalDelegation hands the signed-in user's approvals to another
+// user for a date range, such as while they are on vacation
+func (h *ApprovalDelegationHandler) CreateApprovalDelegation(c *gin.Context) {
+	var req models.ApprovalDelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	delegation, err := h.delegations.Create(context.Background(), user, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDelegation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create approval delegation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, delegation)
+}
+
+// DeleteApprovalDelegation ends one of the signed-in user's delegations
+func (h *ApprovalDelegationHandler) DeleteApprovalDelegation(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("delegationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delegation ID"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	if err := h.delegations.Delete(context.Background(), id, user.ID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Approval delegation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete approval delegation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Approval delegation deleted successfully"})
+}
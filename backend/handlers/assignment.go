@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type AssignmentHandler struct {
+	assignment *services.AssignmentService
+}
+
+func NewAssignmentHandler(assignment *services.AssignmentService) *AssignmentHandler {
+	return &AssignmentHandler{assignment: assignment}
+}
+
+// ListAssignmentRules returns the auto-assignment rule of each category
+// (admin only)
+func (h *AssignmentHandler) ListAssignmentRules(c *gin.Context) {
+	rules, err := h.assignment.List(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch assignment rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// UpdateAssignmentRule creates or replaces the rule for :category, "*" for
+// categories without their own rule (admin only)
+func (h *AssignmentHandler) UpdateAssignmentRule(c *gin.Context) {
+	var req models.AssignmentRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	rule, err := h.assignment.Save(context.Background(), models.TicketCategory(c.Param("category")), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteAssignmentRule removes the rule for :category so its tickets fall
+// back to the "*" rule (admin only)
+func (h *AssignmentHandler) DeleteAssignmentRule(c *gin.Context) {
+	if err := h.assignment.Delete(context.Background(), models.TicketCategory(c.Param("category"))); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete assignment rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Assignment rule deleted successfully"})
+}
@@ -2,31 +2,41 @@ package handlers
 
 import (
 	"context"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"golang.org/x/crypto/bcrypt"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"intelliops-ai-copilot/database"
 	"intelliops-ai-copilot/middleware"
 	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
 )
 
 type AuthHandler struct {
 	db        *database.MongoDB
 	jwtSecret string
 	jwtExpiry time.Duration
+	passwords *services.PasswordService
+	worklogs  *services.WorklogService
+	taxonomy  *services.TaxonomyService
 }
 
-func NewAuthHandler(db *database.MongoDB, jwtSecret string, jwtExpiry time.Duration) *AuthHandler {
+func NewAuthHandler(db *database.MongoDB, jwtSecret string, jwtExpiry time.Duration, passwords *services.PasswordService, worklogs *services.WorklogService, taxonomy *services.TaxonomyService) *AuthHandler {
 	return &AuthHandler{
 		db:        db,
 		jwtSecret: jwtSecret,
 		jwtExpiry: jwtExpiry,
+		passwords: passwords,
+		worklogs:  worklogs,
+		taxonomy:  taxonomy,
 	}
 }
 
@@ -46,7 +56,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := h.passwords.Hash(req.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
@@ -54,13 +64,15 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	// Create user
 	user := models.User{
-		ID:        primitive.NewObjectID(),
-		Name:      req.Name,
-		Email:     req.Email,
-		Password:  string(hashedPassword),
-		Role:      req.Role,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:         primitive.NewObjectID(),
+		Name:       req.Name,
+		Email:      req.Email,
+		Password:   hashedPassword,
+		Role:       req.Role,
+		Location:   strings.TrimSpace(req.Location),
+		Department: strings.TrimSpace(req.Department),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
 	}
 
 	_, err = h.db.GetCollection("users").InsertOne(context.Background(), user)
@@ -105,12 +117,26 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Check password
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	ok, needsRehash, err := h.passwords.Verify(user.Password, req.Password)
 	if err != nil {
+		log.Printf("Failed to verify password for user %s: %v", user.ID.Hex(), err)
+	}
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
+	// Migrate the stored hash to the configured algorithm and parameters
+	if needsRehash {
+		if hashed, err := h.passwords.Hash(req.Password); err == nil {
+			if _, err := h.db.GetCollection("users").UpdateOne(context.Background(),
+				bson.M{"_id": user.ID, "password": user.Password},
+				bson.M{"$set": bson.M{"password": hashed}}); err != nil {
+				log.Printf("Failed to rehash password for user %s: %v", user.ID.Hex(), err)
+			}
+		}
+	}
+
 	// Generate token
 	token, err := middleware.GenerateToken(user, h.jwtSecret, h.jwtExpiry)
 	if err != nil {
@@ -182,13 +208,13 @@ func (h *AuthHandler) CreateUser(c *gin.Context) {
 	}
 
 	// Validate role
-	if req.Role != models.RoleAdmin && req.Role != models.RoleTechnician {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'admin' or 'technician'"})
+	if !models.ValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'admin', 'technician' or 'requester'"})
 		return
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := h.passwords.Hash(req.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
@@ -196,13 +222,15 @@ func (h *AuthHandler) CreateUser(c *gin.Context) {
 
 	// Create user
 	user := models.User{
-		ID:        primitive.NewObjectID(),
-		Name:      req.Name,
-		Email:     req.Email,
-		Password:  string(hashedPassword),
-		Role:      req.Role,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:         primitive.NewObjectID(),
+		Name:       req.Name,
+		Email:      req.Email,
+		Password:   hashedPassword,
+		Role:       req.Role,
+		Location:   strings.TrimSpace(req.Location),
+		Department: strings.TrimSpace(req.Department),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
 	}
 
 	_, err = h.db.GetCollection("users").InsertOne(context.Background(), user)
@@ -239,19 +267,25 @@ func (h *AuthHandler) UpdateUser(c *gin.Context) {
 		update["$set"].(bson.M)["name"] = name
 	}
 	if role, ok := req["role"].(string); ok && role != "" {
-		if role != string(models.RoleAdmin) && role != string(models.RoleTechnician) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'admin' or 'technician'"})
+		if !models.ValidRole(models.UserRole(role)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'admin', 'technician' or 'requester'"})
 			return
 		}
 		update["$set"].(bson.M)["role"] = models.UserRole(role)
 	}
+	// Location and department are optional, so "" clears them
+	for _, field := range []string{"location", "department"} {
+		if value, ok := req[field].(string); ok {
+			update["$set"].(bson.M)[field] = strings.TrimSpace(value)
+		}
+	}
 	if password, ok := req["password"].(string); ok && password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		hashedPassword, err := h.passwords.Hash(password)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 			return
 		}
-		update["$set"].(bson.M)["password"] = string(hashedPassword)
+		update["$set"].(bson.M)["password"] = hashedPassword
 	}
 
 	result, err := h.db.GetCollection("users").UpdateOne(
@@ -284,7 +318,7 @@ func (h *AuthHandler) DeleteUser(c *gin.Context) {
 	// Get current user to prevent self-deletion
 	currentUser, _ := c.Get("user")
 	currentUserModel := currentUser.(models.User)
-	
+
 	if currentUserModel.ID == objectID {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete your own account"})
 		return
@@ -308,7 +342,8 @@ func (h *AuthHandler) GetSystemStats(c *gin.Context) {
 	// Count users by role
 	adminCount, _ := h.db.GetCollection("users").CountDocuments(context.Background(), bson.M{"role": models.RoleAdmin})
 	technicianCount, _ := h.db.GetCollection("users").CountDocuments(context.Background(), bson.M{"role": models.RoleTechnician})
-	totalUsers := adminCount + technicianCount
+	requesterCount, _ := h.db.GetCollection("users").CountDocuments(context.Background(), bson.M{"role": models.RoleRequester})
+	totalUsers := adminCount + technicianCount + requesterCount
 
 	// Count tickets by status
 	totalTickets, _ := h.db.GetCollection("tickets").CountDocuments(context.Background(), bson.M{})
@@ -316,12 +351,41 @@ func (h *AuthHandler) GetSystemStats(c *gin.Context) {
 	inProgressTickets, _ := h.db.GetCollection("tickets").CountDocuments(context.Background(), bson.M{"status": models.StatusInProgress})
 	resolvedTickets, _ := h.db.GetCollection("tickets").CountDocuments(context.Background(), bson.M{"status": models.StatusResolved})
 	criticalTickets, _ := h.db.GetCollection("tickets").CountDocuments(context.Background(), bson.M{"priority": models.PriorityCritical})
+	overdueTickets, _ := h.db.GetCollection("tickets").CountDocuments(context.Background(), services.OverdueFilter(h.taxonomy.Get(context.Background()), time.Now()))
+
+	// Category breakdown: "primary" counts each ticket once by its primary
+	// category, "anyLabel" counts it under every category it carries.
+	primaryCategories := h.countTicketsBy(bson.A{
+		bson.M{"$group": bson.M{"_id": "$category", "count": bson.M{"$sum": 1}}},
+	})
+	labelCategories := h.countTicketsBy(bson.A{
+		bson.M{"$project": bson.M{"labels": bson.M{"$setUnion": bson.A{
+			bson.A{"$category"},
+			bson.M{"$ifNull": bson.A{"$secondaryCategories", bson.A{}}},
+		}}}},
+		bson.M{"$unwind": "$labels"},
+		bson.M{"$group": bson.M{"_id": "$labels", "count": bson.M{"$sum": 1}}},
+	})
+	subcategories := h.countTicketsBy(bson.A{
+		bson.M{"$match": bson.M{"subcategory": bson.M{"$nin": bson.A{"", nil}}}},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"$concat": bson.A{"$category", " / ", "$subcategory"}},
+			"count": bson.M{"$sum": 1},
+		}},
+	})
+
+	// Time logged per technician and on the longest tickets
+	worklogs, err := h.worklogs.Stats(context.Background())
+	if err != nil {
+		log.Printf("Failed to total worklogs: %v", err)
+	}
 
 	stats := gin.H{
 		"users": gin.H{
 			"total":       totalUsers,
 			"admins":      adminCount,
 			"technicians": technicianCount,
+			"requesters":  requesterCount,
 		},
 		"tickets": gin.H{
 			"total":      totalTickets,
@@ -329,8 +393,63 @@ func (h *AuthHandler) GetSystemStats(c *gin.Context) {
 			"inProgress": inProgressTickets,
 			"resolved":   resolvedTickets,
 			"critical":   criticalTickets,
+			"overdue":    overdueTickets,
 		},
+		"categories": gin.H{
+			"primary":       primaryCategories,
+			"anyLabel":      labelCategories,
+			"subcategories": subcategories,
+		},
+		"worklogs": worklogs,
 	}
 
 	c.JSON(http.StatusOK, stats)
 }
+
+// countTicketsBy runs an aggregation over tickets whose final stage groups by
+// a string key into {_id, count} and returns the counts as a map.
+func (h *AuthHandler) countTicketsBy(pipeline bson.A) map[string]int64 {
+	counts := map[string]int64{}
+	cursor, err := h.db.GetCollection("tickets").Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return counts
+	}
+	defer cursor.Close(context.Background())
+
+	var rows []struct {
+		Key   string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(context.Background(), &rows); err != nil {
+		return counts
+	}
+	for _, row := range rows {
+		counts[row.Key] = row.Count
+	}
+	return counts
+}
+
+// GetAccessDenials returns recent requests to admin and monitor routes that
+// were rejected by the IP allow-list or country restriction
+func (h *AuthHandler) GetAccessDenials(c *gin.Context) {
+	limit := int64(50)
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = int64(l)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit)
+	cursor, err := h.db.GetCollection("access_denials").Find(context.Background(), bson.M{}, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch access denials"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	denials := []models.AccessDenial{}
+	if err := cursor.All(context.Background(), &denials); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode access denials"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"denials": denials})
+}
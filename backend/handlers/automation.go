@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type AutomationHandler struct {
+	db         *database.MongoDB
+	automation *services.AutomationService
+}
+
+func NewAutomationHandler(db *database.MongoDB, automation *services.AutomationService) *AutomationHandler {
+	return &AutomationHandler{db: db, automation: automation}
+}
+
+// ListRunbooks returns the registered runbooks
+func (h *AutomationHandler) ListRunbooks(c *gin.Context) {
+	runbooks, err := h.automation.ListRunbooks(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch runbooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runbooks": runbooks})
+}
+
+// CreateRunbook registers a parameterized SSM, Ansible or HTTP runbook
+// (admin only)
+func (h *AutomationHandler) CreateRunbook(c *gin.Context) {
+	var req models.Runbook
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateRunbook(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	runbook, err := h.automation.CreateRunbook(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create runbook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, runbook)
+}
+
+func (h *AutomationHandler) UpdateRunbook(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid runbook ID"})
+		return
+	}
+
+	var req models.Runbook
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateRunbook(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	runbook, err := h.automation.UpdateRunbook(context.Background(), objectID, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Runbook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update runbook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, runbook)
+}
+
+func (h *AutomationHandler) DeleteRunbook(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid runbook ID"})
+		return
+	}
+
+	if err := h.automation.DeleteRunbook(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Runbook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete runbook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Runbook deleted successfully"})
+}
+
+// RequestAutomation asks for a runbook to be executed for an automatable
+// solution step. The run waits for admin approval.
+func (h *AutomationHandler) RequestAutomation(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.RequestAutomationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	runbookID, err := primitive.ObjectIDFromHex(req.RunbookID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid runbook ID"})
+		return
+	}
+
+	ctx := context.Background()
+	count, err := h.db.GetCollection("tickets").CountDocuments(ctx, bson.M{"_id": ticketID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket"})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		return
+	}
+
+	runbook, err := h.automation.GetRunbook(ctx, runbookID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Runbook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch runbook"})
+		return
+	}
+	if err := services.ValidateRunbookParameters(runbook, req.Parameters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	run, err := h.automation.Request(ctx, ticketID, runbook, req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request automation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, run)
+}
+
+// ListTicketAutomations returns the automation runs of a ticket
+func (h *AutomationHandler) ListTicketAutomations(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	runs, err := h.automation.ListRuns(context.Background(), &ticketID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch automation runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// ListAutomationRuns returns automation runs, optionally filtered by status,
+// e.g. ?status=pending_approval for the approval queue (admin only)
+func (h *AutomationHandler) ListAutomationRuns(c *gin.Context) {
+	runs, err := h.automation.ListRuns(context.Background(), nil, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch automation runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// ApproveAutomation approves a pending run and starts it; output is attached
+// to the ticket when it finishes (admin only)
+func (h *AutomationHandler) ApproveAutomation(c *gin.Context) {
+	runID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid automation run ID"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	run, err := h.automation.Approve(context.Background(), runID, user.ID)
+	if err != nil {
+		switch err {
+		case mongo.ErrNoDocuments:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Automation run not found"})
+		case services.ErrAutomationDisabled:
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		case services.ErrSelfApproval:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// RejectAutomation closes a pending run without executing it (admin only)
+func (h *AutomationHandler) RejectAutomation(c *gin.Context) {
+	runID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid automation run ID"})
+		return
+	}
+
+	var req models.ReviewAutomationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	run, err := h.automation.Reject(context.Background(), runID, user.ID, req.Reason)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Automation run not found"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
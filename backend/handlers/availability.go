@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type AvailabilityHandler struct {
+	availability *services.AvailabilityService
+}
+
+func NewAvailabilityHandler(availability *services.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{availability: availability}
+}
+
+func availabilityError(c *gin.Context, err error, notFound, failed string) {
+	switch {
+	case err == mongo.ErrNoDocuments:
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.Is(err, services.ErrInvalidAvailability):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failed})
+	}
+}
+
+// availabilityUser is the signed-in user, or the user in :id for admins
+func availabilityUser(c *gin.Context) (primitive.ObjectID, bool) {
+	if c.Param("id") == "" {
+		return c.MustGet("user").(models.User).ID, true
+	}
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return primitive.NilObjectID, false
+	}
+	return objectID, true
+}
+
+// GetMyAvailability returns whether the signed-in user takes tickets now,
+// with their working hours and time off
+func (h *AvailabilityHandler) GetMyAvailability(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	availability, err := h.availability.Availability(context.Background(), user.ID, time.Now())
+	if err != nil {
+		availabilityError(c, err, "User not found", "Failed to fetch availability")
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+// GetAvailability returns every technician's availability and who is on
+// call
+func (h *AvailabilityHandler) GetAvailability(c *gin.Context) {
+	overview, err := h.availability.Overview(context.Background(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch availability"})
+		return
+	}
+
+	c.JSON(http.StatusOK, overview)
+}
+
+// SetWorkingHours replaces the working hours of the signed-in user, or of
+// :id (admin only). Sending no shifts clears them, so tickets are assigned
+// at any time.
+func (h *AvailabilityHandler) SetWorkingHours(c *gin.Context) {
+	userID, ok := availabilityUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.WorkingHours
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hours := &req
+	if len(req.Shifts) == 0 {
+		hours = nil
+	}
+
+	user, err := h.availability.SetWorkingHours(context.Background(), userID, hours)
+	if err != nil {
+		availabilityError(c, err, "Technician not found", "Failed to update working hours")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"workingHours": user.WorkingHours})
+}
+
+// AddOutOfOffice records time off for the signed-in user, or for :id
+// (admin only)
+func (h *AvailabilityHandler) AddOutOfOffice(c *gin.Context) {
+	userID, ok := availabilityUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.OutOfOfficeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	period, err := h.availability.AddOutOfOffice(context.Background(), userID, req)
+	if err != nil {
+		availabilityError(c, err, "Technician not found", "Failed to add time off")
+		return
+	}
+
+	c.JSON(http.StatusCreated, period)
+}
+
+// DeleteOutOfOffice removes time off entered by hand; periods read from a
+// calendar are removed there
+func (h *AvailabilityHandler) DeleteOutOfOffice(c *gin.Context) {
+	userID, ok := availabilityUser(c)
+	if !ok {
+		return
+	}
+	periodID, err := primitive.ObjectIDFromHex(c.Param("oooId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time off ID"})
+		return
+	}
+
+	if err := h.availability.DeleteOutOfOffice(context.Background(), userID, periodID); err != nil {
+		availabilityError(c, err, "Time off not found", "Failed to delete time off")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Time off deleted successfully"})
+}
+
+// GetOnCallRotation returns the on-call rotation and who is on call now
+// (admin only)
+func (h *AvailabilityHandler) GetOnCallRotation(c *gin.Context) {
+	ctx := context.Background()
+	rotation, err := h.availability.Rotation(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch on-call rotation"})
+		return
+	}
+	shift, err := h.availability.OnCall(ctx, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch on-call rotation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rotation": rotation, "onCall": shift})
+}
+
+// UpdateOnCallRotation replaces the on-call rotation; no technicians
+// removes it (admin only)
+func (h *AvailabilityHandler) UpdateOnCallRotation(c *gin.Context) {
+	var req models.OnCallRotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ctx := context.Background()
+	rotation, err := h.availability.SetRotation(ctx, req, user.ID)
+	if err != nil {
+		availabilityError(c, err, "On-call rotation not found", "Failed to update on-call rotation")
+		return
+	}
+	shift, err := h.availability.OnCall(ctx, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch on-call rotation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rotation": rotation, "onCall": shift})
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type BackupHandler struct {
+	backups  *services.BackupService
+	settings *services.SettingsService
+}
+
+// NewBackupHandler takes a nil backup service when backup storage could not
+// be set up.
+func NewBackupHandler(backups *services.BackupService, settings *services.SettingsService) *BackupHandler {
+	return &BackupHandler{backups: backups, settings: settings}
+}
+
+// CreateBackup exports users, tickets, document metadata and monitoring
+// configuration to backup storage (admin only)
+func (h *BackupHandler) CreateBackup(c *gin.Context) {
+	if h.backups == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Backup storage is not configured"})
+		return
+	}
+
+	backup, err := h.backups.Backup(context.Background())
+	if err != nil {
+		if errors.Is(err, services.ErrBackupRunning) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Backup failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create backup"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, backup)
+}
+
+// ListBackups returns the backups in storage, newest first (admin only)
+func (h *BackupHandler) ListBackups(c *gin.Context) {
+	if h.backups == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Backup storage is not configured"})
+		return
+	}
+
+	backups, err := h.backups.List(context.Background())
+	if err != nil {
+		log.Printf("Failed to list backups: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch backups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"backups": backups})
+}
+
+// RestoreBackup writes a backup back to the database, skipping or
+// overwriting documents that already exist (admin only)
+func (h *BackupHandler) RestoreBackup(c *gin.Context) {
+	if h.backups == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Backup storage is not configured"})
+		return
+	}
+
+	var req models.RestoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateRestoreRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.backups.Restore(context.Background(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBackupNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Backup not found"})
+		case errors.Is(err, services.ErrBackupRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Restore of %s failed: %v", req.Key, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore backup", "result": result})
+		}
+		return
+	}
+
+	// Restored settings only take effect once they are applied again.
+	if res, ok := result.Collections["settings"]; ok && !req.DryRun && res.Inserted+res.Overwritten > 0 {
+		if err := h.settings.Reload(context.Background()); err != nil {
+			log.Printf("Failed to apply restored settings: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+// ICS feeds cover this much history and this far ahead.
+const (
+	calendarFeedLookback  = 30 * 24 * time.Hour
+	calendarFeedLookahead = 180 * 24 * time.Hour
+)
+
+type CalendarHandler struct {
+	calendar *services.CalendarService
+	sync     *services.CalendarSyncService
+}
+
+func NewCalendarHandler(calendar *services.CalendarService, sync *services.CalendarSyncService) *CalendarHandler {
+	return &CalendarHandler{calendar: calendar, sync: sync}
+}
+
+// GetCalendarEvents returns the current user's calendar between ?from and
+// ?to (RFC 3339), by default the past week and the next 90 days
+func (h *CalendarHandler) GetCalendarEvents(c *gin.Context) {
+	now := time.Now()
+	from, to := now.Add(-7*24*time.Hour), now.Add(90*24*time.Hour)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 time"})
+			return
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 time"})
+			return
+		}
+		to = t
+	}
+	if !to.After(from) || to.Sub(from) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from, and at most a year later"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	events, err := h.calendar.Events(context.Background(), &user, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+func calendarFeedResponse(feed models.CalendarFeed) gin.H {
+	return gin.H{
+		"url":       "/api/public/calendar/" + feed.Token + ".ics",
+		"createdAt": feed.CreatedAt,
+	}
+}
+
+// GetCalendarFeed returns the current user's ICS feed URL, creating the
+// feed on first use
+func (h *CalendarHandler) GetCalendarFeed(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	feed, err := h.calendar.Feed(context.Background(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendarFeedResponse(feed))
+}
+
+// RotateCalendarFeed replaces the current user's feed URL; the old one
+// stops working
+func (h *CalendarHandler) RotateCalendarFeed(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	feed, err := h.calendar.RotateFeed(context.Background(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendarFeedResponse(feed))
+}
+
+// RevokeCalendarFeed turns off the current user's feed
+func (h *CalendarHandler) RevokeCalendarFeed(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	if err := h.calendar.RevokeFeed(context.Background(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed revoked successfully"})
+}
+
+// GetFeedICS serves a user's calendar as iCalendar to calendar apps
+// subscribed to their feed URL
+func (h *CalendarHandler) GetFeedICS(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	ctx := context.Background()
+	user, err := h.calendar.FeedUser(ctx, token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar feed"})
+		return
+	}
+
+	now := time.Now()
+	events, err := h.calendar.Events(ctx, &user, now.Add(-calendarFeedLookback), now.Add(calendarFeedLookahead))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", services.RenderICS("IntelliOps - "+user.Name, events))
+}
+
+// SyncCalendars copies maintenance windows to the external calendars now
+// instead of waiting for the next scheduled sync
+func (h *CalendarHandler) SyncCalendars(c *gin.Context) {
+	if h.sync == nil || !h.sync.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Calendar sync is not configured"})
+		return
+	}
+
+	results, err := h.sync.Sync(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync calendars"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
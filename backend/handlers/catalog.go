@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type CatalogHandler struct {
+	catalog *services.CatalogService
+}
+
+func NewCatalogHandler(catalog *services.CatalogService) *CatalogHandler {
+	return &CatalogHandler{catalog: catalog}
+}
+
+// ListCatalogItems returns the requestable items; staff also see inactive
+// ones with ?all=true
+func (h *CatalogHandler) ListCatalogItems(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	all := c.Query("all") == "true" && user.Role != models.RoleRequester
+
+	items, err := h.catalog.ListItems(context.Background(), all)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch catalog"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *CatalogHandler) GetCatalogItem(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid catalog item ID"})
+		return
+	}
+
+	item, err := h.catalog.GetItem(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Catalog item not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch catalog item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// CreateCatalogItem adds a requestable item with its approval chain (admin
+// only)
+func (h *CatalogHandler) CreateCatalogItem(c *gin.Context) {
+	var req models.CatalogItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.catalog.ValidateCatalogItem(context.Background(), req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	item, err := h.catalog.CreateItem(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create catalog item"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *CatalogHandler) UpdateCatalogItem(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid catalog item ID"})
+		return
+	}
+
+	var req models.CatalogItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.catalog.ValidateCatalogItem(context.Background(), req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := h.catalog.UpdateItem(context.Background(), objectID, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Catalog item not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update catalog item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *CatalogHandler) DeleteCatalogItem(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid catalog item ID"})
+		return
+	}
+
+	if err := h.catalog.DeleteItem(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Catalog item not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete catalog item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Catalog item deleted successfully"})
+}
+
+// RequestCatalogItem raises a service request ticket for an item. It is
+// assigned once its approval chain completes.
+func (h *CatalogHandler) RequestCatalogItem(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid catalog item ID"})
+		return
+	}
+
+	var req models.CatalogRequestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	ctx := context.Background()
+	item, err := h.catalog.GetItem(ctx, objectID)
+	if err != nil || !item.Active {
+		if err == nil || err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Catalog item not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch catalog item"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ticket, err := h.catalog.Request(ctx, item, req, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service request"})
+		return
+	}
+
+	if user.Role == models.RoleRequester {
+		c.JSON(http.StatusCreated, models.NewPortalTicket(ticket))
+		return
+	}
+	c.JSON(http.StatusCreated, ticket)
+}
+
+// ListPendingApprovals returns the service requests waiting for the current
+// user's decision
+func (h *CatalogHandler) ListPendingApprovals(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	tickets, err := h.catalog.PendingApprovals(context.Background(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch approvals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tickets": tickets})
+}
+
+// DecideApproval approves or rejects the current approval step of a service
+// request
+func (h *CatalogHandler) DecideApproval(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.ApprovalDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ticket, err := h.catalog.Decide(context.Background(), ticketID, user, req)
+	if err != nil {
+		switch {
+		case err == mongo.ErrNoDocuments:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		case err == services.ErrNotServiceRequest:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err == services.ErrNotApprover, err == services.ErrRequesterApproval:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err == services.ErrApprovalClosed, errors.Is(err, services.ErrInvalidStatusTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record approval"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+// UpdateFulfillmentStep ticks off a fulfillment step of an approved request
+func (h *CatalogHandler) UpdateFulfillmentStep(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.UpdateFulfillmentStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ticket, err := h.catalog.UpdateFulfillment(context.Background(), ticketID, req, user.ID)
+	if err != nil {
+		switch err {
+		case mongo.ErrNoDocuments:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		case services.ErrNotServiceRequest, services.ErrFulfillmentStepInvalid:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case services.ErrAwaitingApproval:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update fulfillment step"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
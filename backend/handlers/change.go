@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type ChangeHandler struct {
+	changes *services.ChangeService
+}
+
+func NewChangeHandler(changes *services.ChangeService) *ChangeHandler {
+	return &ChangeHandler{changes: changes}
+}
+
+func changeError(c *gin.Context, err error, failed string) {
+	switch {
+	case err == mongo.ErrNoDocuments:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Change not found"})
+	case errors.Is(err, services.ErrNotChange), errors.Is(err, services.ErrChangeInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotApprover), errors.Is(err, services.ErrChangeRequesterApproval):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrChangeApprovalClosed), errors.Is(err, services.ErrInvalidStatusTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failed})
+	}
+}
+
+func changeID(c *gin.Context) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid change ID"})
+		return primitive.NilObjectID, false
+	}
+	return id, true
+}
+
+// CreateChange raises a change ticket, which waits for approval before it
+// can be assigned or worked on
+func (h *ChangeHandler) CreateChange(c *gin.Context) {
+	var req models.CreateChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ticket, err := h.changes.Create(context.Background(), req, user)
+	if err != nil {
+		changeError(c, err, "Failed to create change")
+		return
+	}
+
+	c.JSON(http.StatusCreated, ticket)
+}
+
+// ListChanges returns the change schedule, optionally limited to the
+// changes running between ?from and ?to (RFC 3339)
+func (h *ChangeHandler) ListChanges(c *gin.Context) {
+	var from, to time.Time
+	for param, t := range map[string]*time.Time{"from": &from, "to": &to} {
+		if v := c.Query(param); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 time"})
+				return
+			}
+			*t = parsed
+		}
+	}
+
+	changes, err := h.changes.List(context.Background(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch changes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"changes": changes})
+}
+
+// RescheduleChange moves an open change; an approved change goes back for
+// approval
+func (h *ChangeHandler) RescheduleChange(c *gin.Context) {
+	id, ok := changeID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ScheduleChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ticket, err := h.changes.Reschedule(context.Background(), id, req, user)
+	if err != nil {
+		changeError(c, err, "Failed to reschedule change")
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+// GetChangeConflicts checks a change's schedule against the changes it
+// depends on, maintenance windows and other changes on the same resources
+func (h *ChangeHandler) GetChangeConflicts(c *gin.Context) {
+	id, ok := changeID(c)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	ticket, err := h.changes.Get(ctx, id)
+	if err != nil {
+		changeError(c, err, "Failed to check change schedule")
+		return
+	}
+	conflicts, err := h.changes.Conflicts(ctx, ticket)
+	if err != nil {
+		changeError(c, err, "Failed to check change schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"conflicts": conflicts})
+}
+
+// DecideChange approves or rejects a change. Approval is refused with the
+// schedule conflicts while there are any; a rejection closes the change
+func (h *ChangeHandler) DecideChange(c *gin.Context) {
+	id, ok := changeID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ApprovalDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ticket, conflicts, err := h.changes.Decide(context.Background(), id, user, req)
+	if errors.Is(err, services.ErrChangeConflicts) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": conflicts})
+		return
+	}
+	if err != nil {
+		changeError(c, err, "Failed to record approval")
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
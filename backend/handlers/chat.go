@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+// ChatHandler serves the embeddable support chat, both to signed-in users
+// under /api/portal/chat and to anonymous visitors under /api/public/chat.
+type ChatHandler struct {
+	chat *services.ChatService
+	// anonymous allows sessions without signing in
+	anonymous bool
+}
+
+func NewChatHandler(chat *services.ChatService, anonymous bool) *ChatHandler {
+	return &ChatHandler{chat: chat, anonymous: anonymous}
+}
+
+// chatUser is the signed-in user, or nil on the public routes.
+func chatUser(c *gin.Context) *models.User {
+	if user, ok := c.Get("user"); ok {
+		u := user.(models.User)
+		return &u
+	}
+	return nil
+}
+
+// openChat loads the session in the path for its owner, writing the error
+// response when it cannot.
+func (h *ChatHandler) openChat(c *gin.Context) (models.ChatSession, *models.User, bool) {
+	user := chatUser(c)
+	if user == nil && !h.anonymous {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Anonymous chat is disabled"})
+		return models.ChatSession{}, nil, false
+	}
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return models.ChatSession{}, nil, false
+	}
+	session, err := h.chat.Open(context.Background(), id, user, c.GetHeader("X-Chat-Token"))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chat session not found"})
+			return models.ChatSession{}, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch chat session"})
+		return models.ChatSession{}, nil, false
+	}
+	return session, user, true
+}
+
+func chatError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidChat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err == services.ErrChatClosed:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// StartChat opens a chat session; anonymous visitors get the token to send
+// as X-Chat-Token
+func (h *ChatHandler) StartChat(c *gin.Context) {
+	user := chatUser(c)
+	if user == nil && !h.anonymous {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Anonymous chat is disabled"})
+		return
+	}
+	var req models.StartChatRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	session, token, err := h.chat.Start(context.Background(), user, req)
+	if err != nil {
+		chatError(c, err, "Failed to start chat")
+		return
+	}
+	c.JSON(http.StatusCreated, models.StartChatResponse{Session: session, Token: token})
+}
+
+func (h *ChatHandler) GetChat(c *gin.Context) {
+	session, _, ok := h.openChat(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// SendChatMessage answers a visitor message from the knowledge base
+func (h *ChatHandler) SendChatMessage(c *gin.Context) {
+	session, user, ok := h.openChat(c)
+	if !ok {
+		return
+	}
+	var req models.ChatMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reply, err := h.chat.Send(context.Background(), session, user, req.Body)
+	if err != nil {
+		chatError(c, err, "Failed to send message")
+		return
+	}
+	c.JSON(http.StatusOK, reply)
+}
+
+// EscalateChat hands the conversation over to the service desk as a ticket
+func (h *ChatHandler) EscalateChat(c *gin.Context) {
+	session, user, ok := h.openChat(c)
+	if !ok {
+		return
+	}
+	var req models.EscalateChatRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	session, err := h.chat.Escalate(context.Background(), session, user, req)
+	if err != nil {
+		chatError(c, err, "Failed to escalate chat")
+		return
+	}
+	c.JSON(http.StatusCreated, session)
+}
+
+func (h *ChatHandler) CloseChat(c *gin.Context) {
+	session, _, ok := h.openChat(c)
+	if !ok {
+		return
+	}
+	session, err := h.chat.Close(context.Background(), session)
+	if err != nil {
+		chatError(c, err, "Failed to close chat")
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
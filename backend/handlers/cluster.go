@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/services"
+)
+
+type ClusterHandler struct {
+	clusters *services.ClusterService
+}
+
+func NewClusterHandler(clusters *services.ClusterService) *ClusterHandler {
+	return &ClusterHandler{clusters: clusters}
+}
+
+// ListClusters returns emerging ticket clusters and their problem tickets
+func (h *ClusterHandler) ListClusters(c *gin.Context) {
+	clusters, err := h.clusters.List(context.Background(), 50)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch clusters"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"clusters": clusters})
+}
+
+// RunClustering runs emerging problem detection immediately
+func (h *ClusterHandler) RunClustering(c *gin.Context) {
+	clusters, err := h.clusters.Run(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cluster tickets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"emerging": clusters})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type CommentHandler struct {
+	comments *services.CommentService
+	archive  *services.TicketArchiveService
+}
+
+func NewCommentHandler(comments *services.CommentService, archive *services.TicketArchiveService) *CommentHandler {
+	return &CommentHandler{comments: comments, archive: archive}
+}
+
+// ListComments returns a page of the ticket's conversation thread, oldest
+// first. Requesters do not see internal comments.
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := loadUserTicket(c, h.archive, user)
+	if !ok {
+		return
+	}
+
+	pageInt := 1
+	limitInt := 50
+	if p, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && p > 0 {
+		pageInt = p
+	}
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "50")); err == nil && l > 0 && l <= 200 {
+		limitInt = l
+	}
+
+	includeInternal := user.Role != models.RoleRequester
+	comments, total, err := h.comments.Page(context.Background(), ticket.ID, includeInternal, int64((pageInt-1)*limitInt), int64(limitInt))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments": comments,
+		"total":    total,
+		"page":     pageInt,
+		"limit":    limitInt,
+	})
+}
+
+// AddComment posts a comment on the ticket's conversation thread
+func (h *CommentHandler) AddComment(c *gin.Context) {
+	var req models.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateComment(req.Body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	if req.Internal && user.Role == models.RoleRequester {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only staff can post internal comments"})
+		return
+	}
+
+	ticket, ok := loadUserTicket(c, h.archive, user)
+	if !ok {
+		return
+	}
+
+	comment, err := h.comments.Add(context.Background(), ticket.ID, user, strings.TrimSpace(req.Body), req.Internal)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add comment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/services"
+)
+
+type CostHandler struct {
+	costs *services.CostMonitorService
+}
+
+// NewCostHandler takes a nil service when cost monitoring is disabled.
+func NewCostHandler(costs *services.CostMonitorService) *CostHandler {
+	return &CostHandler{costs: costs}
+}
+
+// GetCosts returns daily AWS spend for the last ?days (default 30) days.
+func (h *CostHandler) GetCosts(c *gin.Context) {
+	if h.costs == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cost monitoring is not enabled"})
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+		return
+	}
+
+	costs, err := h.costs.Costs(context.Background(), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch costs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"costs": costs})
+}
+
+// CheckCosts runs the cost anomaly check now instead of waiting for the
+// next poll.
+func (h *CostHandler) CheckCosts(c *gin.Context) {
+	if h.costs == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cost monitoring is not enabled"})
+		return
+	}
+
+	anomaly, err := h.costs.Check(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check costs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"anomaly": anomaly})
+}
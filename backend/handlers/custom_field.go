@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type CustomFieldHandler struct {
+	fields *services.CustomFieldService
+}
+
+func NewCustomFieldHandler(fields *services.CustomFieldService) *CustomFieldHandler {
+	return &CustomFieldHandler{fields: fields}
+}
+
+// ListCustomFields returns the custom fields tickets may carry
+func (h *CustomFieldHandler) ListCustomFields(c *gin.Context) {
+	fields, err := h.fields.List(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch custom fields"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"fields": fields})
+}
+
+// SaveCustomField creates or replaces the custom field :key (admin only)
+func (h *CustomFieldHandler) SaveCustomField(c *gin.Context) {
+	var req models.CustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user := c.MustGet("user").(models.User)
+
+	field, err := h.fields.Save(context.Background(), c.Param("key"), req, user.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCustomField) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save custom field"})
+		return
+	}
+
+	c.JSON(http.StatusOK, field)
+}
+
+// DeleteCustomField removes the custom field :key; tickets keep their
+// values (admin only)
+func (h *CustomFieldHandler) DeleteCustomField(c *gin.Context) {
+	if err := h.fields.Delete(context.Background(), c.Param("key")); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete custom field"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Custom field deleted successfully"})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type DashboardHandler struct {
+	dashboard *services.DashboardService
+	push      *services.PushService
+}
+
+func NewDashboardHandler(dashboard *services.DashboardService, push *services.PushService) *DashboardHandler {
+	return &DashboardHandler{dashboard: dashboard, push: push}
+}
+
+// GetMyDashboard returns the current user's day for the mobile home screen.
+// ?tz (an IANA zone such as Europe/Berlin, default UTC) sets where today
+// ends for reminders
+func (h *DashboardHandler) GetMyDashboard(c *gin.Context) {
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time zone"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	dashboard, err := h.dashboard.MyDay(context.Background(), user, loc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dashboard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// MarkNotificationsRead marks notifications in the current user's inbox
+// read, all of them when no IDs are given
+func (h *DashboardHandler) MarkNotificationsRead(c *gin.Context) {
+	var req models.MarkNotificationsReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ids := make([]primitive.ObjectID, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+			return
+		}
+		ids = append(ids, objectID)
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	marked, err := h.push.MarkNotificationsRead(context.Background(), user.ID, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
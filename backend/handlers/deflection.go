@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type DeflectionHandler struct {
+	deflection *services.DeflectionService
+}
+
+func NewDeflectionHandler(deflection *services.DeflectionService) *DeflectionHandler {
+	return &DeflectionHandler{deflection: deflection}
+}
+
+// Preflight suggests articles for a ticket draft before it is saved. Send
+// the returned preflightId with the ticket, or mark it resolved.
+func (h *DeflectionHandler) Preflight(c *gin.Context) {
+	var req models.PreflightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	response, err := h.deflection.Preflight(context.Background(), user, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search for suggestions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SelfResolve records that a suggestion solved the issue and no ticket is
+// needed
+func (h *DeflectionHandler) SelfResolve(c *gin.Context) {
+	preflightID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preflight ID"})
+		return
+	}
+
+	var req models.SelfResolveRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	var articleID *primitive.ObjectID
+	if req.ArticleID != "" {
+		id, err := primitive.ObjectIDFromHex(req.ArticleID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+			return
+		}
+		articleID = &id
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	if err := h.deflection.SelfResolved(context.Background(), preflightID, user.ID, articleID); err != nil {
+		switch err {
+		case mongo.ErrNoDocuments:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Preflight not found"})
+		case services.ErrDeflectionClosed:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record resolution"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Glad that helped"})
+}
+
+// GetDeflectionStats reports how many drafts suggestions resolved over the
+// last ?days (default 30)
+func (h *DeflectionHandler) GetDeflectionStats(c *gin.Context) {
+	days := 30
+	if value := c.Query("days"); value != "" {
+		d, err := strconv.Atoi(value)
+		if err != nil || d < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+			return
+		}
+		days = d
+	}
+
+	stats, err := h.deflection.Stats(context.Background(), time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute deflection statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
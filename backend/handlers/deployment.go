@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type DeploymentHandler struct {
+	deployments *services.DeploymentService
+}
+
+func NewDeploymentHandler(deployments *services.DeploymentService) *DeploymentHandler {
+	return &DeploymentHandler{deployments: deployments}
+}
+
+// IngestDeployment records a deployment event posted by a CI/CD pipeline
+func (h *DeploymentHandler) IngestDeployment(c *gin.Context) {
+	var event models.DeploymentEvent
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deployment, err := h.deployments.Record(context.Background(), event)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, deployment)
+}
+
+// ListDeployments returns the latest deployments, filtered by ?service and
+// limited by ?limit
+func (h *DeploymentHandler) ListDeployments(c *gin.Context) {
+	limit := 50
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "50")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+
+	deployments, err := h.deployments.List(context.Background(), c.Query("service"), int64(limit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deployments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deployments": deployments})
+}
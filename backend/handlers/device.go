@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type DeviceHandler struct {
+	push *services.PushService
+}
+
+func NewDeviceHandler(push *services.PushService) *DeviceHandler {
+	return &DeviceHandler{push: push}
+}
+
+// RegisterDevice stores the caller's FCM or APNs token for push
+// notifications
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	var req models.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	device, err := h.push.Register(context.Background(), user.ID, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register device"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, device)
+}
+
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	devices, err := h.push.ListDevices(context.Background(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch devices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"devices": devices, "providers": h.push.Providers()})
+}
+
+func (h *DeviceHandler) UnregisterDevice(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	deleted, err := h.push.Unregister(context.Background(), user.ID, objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unregister device"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Device unregistered successfully"})
+}
+
+// TestPush sends a test notification to the caller's devices
+func (h *DeviceHandler) TestPush(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	delivered, err := h.push.NotifyUsers(context.Background(), []primitive.ObjectID{user.ID}, services.PushMessage{
+		Title: "IntelliOps test notification",
+		Body:  "Push notifications are working on this device.",
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send test notification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"delivered": delivered})
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+// maxDiagnosticUploadBytes caps an upload before it is parsed; text fields
+// are truncated further when stored.
+const maxDiagnosticUploadBytes = 1 << 20
+
+type DiagnosticRequestHandler struct {
+	diagnostics *services.DiagnosticRequestService
+	agents      *services.EndpointAgentService
+	summaries   *services.SummaryService
+}
+
+func NewDiagnosticRequestHandler(diagnostics *services.DiagnosticRequestService, agents *services.EndpointAgentService, summaries *services.SummaryService) *DiagnosticRequestHandler {
+	return &DiagnosticRequestHandler{diagnostics: diagnostics, agents: agents, summaries: summaries}
+}
+
+// CreateDiagnosticRequest issues a single-use link through which the
+// requester or an endpoint agent uploads system information for a ticket
+func (h *DiagnosticRequestHandler) CreateDiagnosticRequest(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.CreateDiagnosticRequestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if !services.ValidDiagnosticItems(req.Items) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Items must be system, network or event_logs"})
+		return
+	}
+	ttl, err := h.diagnostics.ParseTTL(req.ExpiresIn)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Route to a specific endpoint agent; otherwise the ticket's own
+	var agentID *primitive.ObjectID
+	if req.AgentID != "" {
+		id, err := primitive.ObjectIDFromHex(req.AgentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endpoint agent ID"})
+			return
+		}
+		if _, err := h.agents.Get(context.Background(), id); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Endpoint agent not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch endpoint agent"})
+			return
+		}
+		agentID = &id
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	request, token, err := h.diagnostics.Create(context.Background(), ticketID, req, agentID, ttl, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create diagnostic request"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"request": request,
+		"token":   token,
+		"path":    "/api/public/diagnostics/" + token,
+	})
+}
+
+func (h *DiagnosticRequestHandler) ListDiagnosticRequests(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	requests, err := h.diagnostics.List(context.Background(), ticketID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch diagnostic requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requests": requests})
+}
+
+func diagnosticLinkError(c *gin.Context, err error, action string) {
+	switch err {
+	case services.ErrDiagnosticLinkInvalid:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Diagnostic request not found"})
+	case services.ErrDiagnosticLinkExpired:
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action})
+	}
+}
+
+// GetDiagnosticRequest shows what a diagnostic link asks for, without
+// authentication
+func (h *DiagnosticRequestHandler) GetDiagnosticRequest(c *gin.Context) {
+	view, err := h.diagnostics.View(context.Background(), c.Param("token"))
+	if err != nil {
+		diagnosticLinkError(c, err, "fetch diagnostic request")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, view)
+}
+
+// SubmitDiagnostics accepts system information for a diagnostic link, without
+// authentication. Each link accepts one upload.
+func (h *DiagnosticRequestHandler) SubmitDiagnostics(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxDiagnosticUploadBytes)
+
+	var info models.SystemInfo
+	if err := c.ShouldBindJSON(&info); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticketID, err := h.diagnostics.Submit(context.Background(), c.Param("token"), info, c.ClientIP())
+	if err != nil {
+		diagnosticLinkError(c, err, "save diagnostics")
+		return
+	}
+	h.summaries.RefreshIfPresent(ticketID)
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Diagnostics received"})
+}
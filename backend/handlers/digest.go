@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/services"
+)
+
+type DigestHandler struct {
+	digests *services.DigestService
+}
+
+func NewDigestHandler(digests *services.DigestService) *DigestHandler {
+	return &DigestHandler{digests: digests}
+}
+
+func (h *DigestHandler) ListDigests(c *gin.Context) {
+	digests, err := h.digests.List(context.Background(), 20)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch digests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"digests": digests})
+}
+
+func (h *DigestHandler) GetDigest(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid digest ID"})
+		return
+	}
+
+	digest, err := h.digests.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Digest not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch digest"})
+		return
+	}
+
+	c.JSON(http.StatusOK, digest)
+}
+
+// GenerateDigest builds the digest for the last seven days on demand; pass
+// ?send=true to also deliver it
+func (h *DigestHandler) GenerateDigest(c *gin.Context) {
+	digest, err := h.digests.Generate(context.Background(), time.Now(), c.Query("send") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate digest"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, digest)
+}
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -22,15 +24,21 @@ type DocumentHandler struct {
 	docService    *services.DocumentService
 	vectorService *services.VectorService
 	llmService    *services.LLMService
+	reviews       *services.DocumentReviewService
+	searches      *services.SearchAnalyticsService
+	queries       *services.QueryService
 }
 
 func NewDocumentHandler(db *database.MongoDB, docService *services.DocumentService,
-	vectorService *services.VectorService, llmService *services.LLMService) *DocumentHandler {
+	vectorService *services.VectorService, llmService *services.LLMService, reviews *services.DocumentReviewService, searches *services.SearchAnalyticsService, queries *services.QueryService) *DocumentHandler {
 	return &DocumentHandler{
 		db:            db,
 		docService:    docService,
 		vectorService: vectorService,
 		llmService:    llmService,
+		reviews:       reviews,
+		searches:      searches,
+		queries:       queries,
 	}
 }
 
@@ -57,6 +65,8 @@ func (h *DocumentHandler) IndexDocuments(c *gin.Context) {
 	// Walk through directory
 	var documents []models.Document
 	var errors []string
+	var duplicates []models.DocumentDuplicate
+	var embedding models.EmbeddingStats
 
 	err := filepath.Walk(folderPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -70,14 +80,32 @@ func (h *DocumentHandler) IndexDocuments(c *gin.Context) {
 		// Process supported file types
 		ext := strings.ToLower(filepath.Ext(path))
 		if ext == ".pdf" || ext == ".md" || ext == ".txt" {
-			doc, err := h.docService.ProcessDocument(path)
+			doc, stats, err := h.docService.ProcessDocument(path)
 			if err != nil {
 				errors = append(errors, fmt.Sprintf("Error processing %s: %v", path, err))
 				return nil // Continue with other files
 			}
+			embedding.Add(stats)
+
+			// Skip copies of documents already indexed
+			doc, dup, err := h.vectorService.Deduplicate(context.Background(), doc)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("Error checking %s for duplicates: %v", path, err))
+				return nil
+			}
+			if dup != nil {
+				duplicates = append(duplicates, *dup)
+				if dup.Merged {
+					return nil
+				}
+			}
 
 			// Store in vector service
-			h.vectorService.StoreDocument(doc)
+			doc, err = h.vectorService.StoreDocument(context.Background(), doc)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("Error storing %s: %v", path, err))
+				return nil
+			}
 
 			documents = append(documents, doc)
 		}
@@ -91,17 +119,21 @@ func (h *DocumentHandler) IndexDocuments(c *gin.Context) {
 	}
 
 	response := models.IndexResponse{
-		Message:   fmt.Sprintf("Successfully indexed %d documents", len(documents)),
-		Count:     len(documents),
-		Documents: documents,
+		Message:    fmt.Sprintf("Successfully indexed %d documents", len(documents)),
+		Count:      len(documents),
+		Documents:  documents,
+		Embedding:  embedding,
+		Duplicates: duplicates,
 	}
 
 	if len(errors) > 0 {
 		c.JSON(http.StatusOK, gin.H{
-			"message":   response.Message,
-			"count":     response.Count,
-			"documents": response.Documents,
-			"warnings":  errors,
+			"message":    response.Message,
+			"count":      response.Count,
+			"documents":  response.Documents,
+			"embedding":  response.Embedding,
+			"duplicates": response.Duplicates,
+			"warnings":   errors,
 		})
 		return
 	}
@@ -124,8 +156,21 @@ func (h *DocumentHandler) SearchDocuments(c *gin.Context) {
 		req.MinScore = 0.3 // Lower threshold for better results
 	}
 
+	// Correct and expand the query unless asked not to
+	started := time.Now()
+	processed := models.ProcessedQuery{
+		Original:    req.Query,
+		Corrected:   req.Query,
+		Query:       req.Query,
+		Corrections: []models.QueryCorrection{},
+		Expansions:  []models.QueryExpansion{},
+	}
+	if !req.Raw {
+		processed = h.queries.Process(context.Background(), req.Query)
+	}
+
 	// Generate query embedding
-	queryEmbedding, err := h.vectorService.GenerateEmbedding(req.Query)
+	queryEmbedding, err := h.vectorService.GenerateEmbedding(processed.Query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate embedding"})
 		return
@@ -138,13 +183,78 @@ func (h *DocumentHandler) SearchDocuments(c *gin.Context) {
 		return
 	}
 
+	user := c.MustGet("user").(models.User)
+	entry := services.NewSearchLog(models.SearchSourceSearch, req.Query, results, time.Since(started), user.ID)
+	h.searches.Record(context.Background(), entry)
+
 	c.JSON(http.StatusOK, gin.H{
-		"query":   req.Query,
-		"results": results,
-		"count":   len(results),
+		"query":          req.Query,
+		"processedQuery": processed,
+		"results":        results,
+		"count":          len(results),
+		"searchId":       entry.ID,
 	})
 }
 
+// RecordSearchClick records that a result of a search was opened, for
+// click-through analytics
+func (h *DocumentHandler) RecordSearchClick(c *gin.Context) {
+	searchID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search ID"})
+		return
+	}
+
+	var req models.SearchClickRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	documentID, err := primitive.ObjectIDFromHex(req.DocumentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	if err := h.searches.RecordClick(context.Background(), searchID, documentID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Search result not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record click"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Click recorded"})
+}
+
+// GetSearchAnalytics reports document searches between ?from and ?to: the
+// most frequent queries, those that found nothing, and the documents
+// results were used from. ?source narrows it to search or solutions
+func (h *DocumentHandler) GetSearchAnalytics(c *gin.Context) {
+	from, to, ok := analyticsRange(c)
+	if !ok {
+		return
+	}
+	source := c.Query("source")
+	if source != "" && source != models.SearchSourceSearch && source != models.SearchSourceSolutions {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source must be search or solutions"})
+		return
+	}
+	limit := 20
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	report, err := h.searches.Analytics(context.Background(), from, to, source, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute search analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // GetTicketSolutions finds solutions for a specific ticket
 func (h *DocumentHandler) GetTicketSolutions(c *gin.Context) {
 	ticketID := c.Param("id")
@@ -155,6 +265,10 @@ func (h *DocumentHandler) GetTicketSolutions(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
 		return
 	}
+	ctx, ok := generationContext(c)
+	if !ok {
+		return
+	}
 
 	var ticket models.Ticket
 	err = h.db.GetCollection("tickets").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&ticket)
@@ -168,10 +282,11 @@ func (h *DocumentHandler) GetTicketSolutions(c *gin.Context) {
 	}
 
 	// Build search query from ticket
-	query := fmt.Sprintf("%s %s %s", ticket.Title, ticket.Description, string(ticket.Category))
+	query := services.SolutionQuery(ticket)
 
 	// Search relevant documents
-	queryEmbedding, err := h.vectorService.GenerateEmbedding(query)
+	started := time.Now()
+	queryEmbedding, err := h.vectorService.GenerateEmbedding(h.queries.Process(ctx, query).Query)
 	if err != nil {
 		// This should not happen anymore since GenerateEmbedding has fallbacks
 		fmt.Printf("Unexpected embedding error: %v\n", err)
@@ -179,14 +294,15 @@ func (h *DocumentHandler) GetTicketSolutions(c *gin.Context) {
 		return
 	}
 
-	docResults, err := h.vectorService.Search(queryEmbedding, 5, 0.3)
+	docResults, err := h.vectorService.SearchApproved(queryEmbedding, 5, 0.3)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search documents"})
 		return
 	}
+	searchLatency := time.Since(started)
 
 	// Generate solutions using LLM
-	solutions, err := h.llmService.GenerateSolutions(ticket, docResults)
+	solutions, usage, err := h.llmService.GenerateSolutions(ctx, ticket, docResults)
 	fmt.Printf("DEBUG: LLM service returned solutions: %v, error: %v\n", solutions, err)
 	if err != nil {
 		// Log error but don't fail - return mock solutions
@@ -203,8 +319,14 @@ func (h *DocumentHandler) GetTicketSolutions(c *gin.Context) {
 	
 	fmt.Printf("DEBUG: Final solutions before response: %v\n", solutions)
 
+	user := c.MustGet("user").(models.User)
+	entry := services.NewSearchLog(models.SearchSourceSolutions, query, docResults, searchLatency, user.ID)
+	entry.TicketID = &ticket.ID
+	entry.CitedDocumentIDs = services.CitedDocuments(docResults, solutions)
+	h.searches.Record(context.Background(), entry)
+
 	// Calculate confidence based on document relevance
-	confidence := calculateConfidence(docResults)
+	confidence := services.SearchConfidence(docResults)
 
 	ticketSolution := models.TicketSolution{
 		TicketID:        ticketID,
@@ -212,6 +334,7 @@ func (h *DocumentHandler) GetTicketSolutions(c *gin.Context) {
 		DocumentSources: docResults,
 		Confidence:      confidence,
 		GeneratedAt:     ticket.UpdatedAt,
+		ContextUsage:    usage,
 	}
 
 	c.JSON(http.StatusOK, ticketSolution)
@@ -245,18 +368,43 @@ func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 	}
 
 	// Process and index document
-	doc, err := h.docService.ProcessDocument(filePath)
+	doc, stats, err := h.docService.ProcessDocument(filePath)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process document"})
 		return
 	}
+	// Uploads are drafts until approved, and solutions don't cite them
+	user := c.MustGet("user").(models.User)
+	doc.ApprovalStatus = models.DocumentDraft
+	doc.UploadedBy = &user.ID
+
+	// Skip copies of documents already indexed
+	doc, dup, err := h.vectorService.Deduplicate(context.Background(), doc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check document for duplicates"})
+		return
+	}
+	if dup != nil && dup.Merged {
+		c.JSON(http.StatusOK, models.UploadResponse{
+			Message:   fmt.Sprintf("Document is a copy of %s and was merged into it", dup.DuplicateOf),
+			Embedding: stats,
+			Duplicate: dup,
+		})
+		return
+	}
 
 	// Store in vector service
-	h.vectorService.StoreDocument(doc)
+	doc, err = h.vectorService.StoreDocument(context.Background(), doc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store document"})
+		return
+	}
 
 	response := models.UploadResponse{
-		Message:  "Document uploaded and indexed successfully",
-		Document: doc,
+		Message:   "Document uploaded and indexed as a draft; submit it for review so solutions can cite it",
+		Document:  doc,
+		Embedding: stats,
+		Duplicate: dup,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -272,16 +420,306 @@ func (h *DocumentHandler) GetIndexStats(c *gin.Context) {
 	})
 }
 
-func calculateConfidence(results []models.DocumentSearchResult) float32 {
-	if len(results) == 0 {
-		return 0.0
+
+// GetDocumentTree returns the docs folder as a tree with the indexing
+// status and document counts of every folder. ?documents=false leaves out
+// the documents and returns only the counts
+func (h *DocumentHandler) GetDocumentTree(c *gin.Context) {
+	tree, err := services.BuildDocumentTree("./docs", h.vectorService.Documents(), c.Query("documents") != "false")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read documents folder"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// GetStaleDocuments lists the indexed documents due for review
+func (h *DocumentHandler) GetStaleDocuments(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"documents": h.reviews.Stale()})
+}
+
+// document fetches the document named by the :id parameter, writing the
+// error response and returning false when it cannot.
+func (h *DocumentHandler) document(c *gin.Context) (models.Document, bool) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return models.Document{}, false
+	}
+
+	doc, err := h.reviews.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+			return doc, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch document"})
+		return doc, false
+	}
+	return doc, true
+}
+
+// documentUser resolves the user ID given for a document's owner or
+// reviewer, writing a 400 and returning false when it names no user.
+// Reviewers must be staff.
+func (h *DocumentHandler) documentUser(c *gin.Context, id, field string) (primitive.ObjectID, bool) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + field + " ID"})
+		return userID, false
+	}
+	var user models.User
+	if err := h.db.GetCollection("users").FindOne(context.Background(), bson.M{"_id": userID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, gin.H{"error": field + " not found"})
+			return userID, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch " + field})
+		return userID, false
+	}
+	if field == "reviewer" && user.Role == models.RoleRequester {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reviewer must be a technician or admin"})
+		return userID, false
+	}
+	return userID, true
+}
+
+// ownsDocument reports whether user can manage doc: staff, its owner and
+// its uploader can.
+func ownsDocument(user models.User, doc models.Document) bool {
+	return user.Role != models.RoleRequester ||
+		(doc.OwnerID != nil && *doc.OwnerID == user.ID) ||
+		(doc.UploadedBy != nil && *doc.UploadedBy == user.ID)
+}
+
+// documentApprovalError writes the response for a failed approval step.
+func documentApprovalError(c *gin.Context, err error, action string) {
+	switch err {
+	case mongo.ErrNoDocuments:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+	case services.ErrDocumentApprovalState:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action + " document"})
+	}
+}
+
+// ReviewDocument marks a document as reviewed and still accurate. Staff and
+// the document's owner can review it
+func (h *DocumentHandler) ReviewDocument(c *gin.Context) {
+	doc, ok := h.document(c)
+	if !ok {
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	if !ownsDocument(user, doc) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only staff and the document owner can review it"})
+		return
+	}
+
+	doc, err := h.reviews.MarkReviewed(context.Background(), doc.ID, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark document reviewed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+// SetDocumentOwner sets the owner review tickets for a document go to and
+// the reviewer who approves its uploads (admin only)
+func (h *DocumentHandler) SetDocumentOwner(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	var req models.DocumentOwnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// NilObjectID clears the field
+	people := map[string]*primitive.ObjectID{}
+	for field, value := range map[string]*string{"owner": req.OwnerID, "reviewer": req.ReviewerID} {
+		if value == nil {
+			continue
+		}
+		id := primitive.NilObjectID
+		if *value != "" {
+			var ok bool
+			if id, ok = h.documentUser(c, *value, field); !ok {
+				return
+			}
+		}
+		people[field] = &id
+	}
+
+	doc, err := h.reviews.SetPeople(context.Background(), objectID, people["owner"], people["reviewer"])
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update document owner"})
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+// RunDocumentReviews opens review tickets for stale documents now rather
+// than at the next scheduled check (admin only)
+func (h *DocumentHandler) RunDocumentReviews(c *gin.Context) {
+	run, err := h.reviews.OpenReviewTickets(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open review tickets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// ListDocumentApprovals lists uploaded documents, filtered by ?status
+// (draft, pending, approved or rejected)
+func (h *DocumentHandler) ListDocumentApprovals(c *gin.Context) {
+	status := models.DocumentApprovalStatus(c.Query("status"))
+	switch status {
+	case "", models.DocumentDraft, models.DocumentPending, models.DocumentApproved, models.DocumentRejected:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid approval status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"documents": h.reviews.Approvals(status)})
+}
+
+// SubmitDocument submits a draft or rejected upload for approval. Staff and
+// the document's owner can submit it
+func (h *DocumentHandler) SubmitDocument(c *gin.Context) {
+	doc, ok := h.document(c)
+	if !ok {
+		return
+	}
+
+	var req models.DocumentSubmitRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	if !ownsDocument(user, doc) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only staff and the document owner can submit it"})
+		return
+	}
+	var reviewer *primitive.ObjectID
+	if req.ReviewerID != "" {
+		reviewerID, ok := h.documentUser(c, req.ReviewerID, "reviewer")
+		if !ok {
+			return
+		}
+		reviewer = &reviewerID
+	}
+
+	doc, err := h.reviews.Submit(context.Background(), doc.ID, reviewer)
+	if err != nil {
+		documentApprovalError(c, err, "submit")
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+// canDecide reports whether user may approve or reject doc: admins, and
+// the assigned reviewer unless they submitted it themselves.
+func canDecide(user models.User, doc models.Document) bool {
+	if user.Role == models.RoleAdmin {
+		return true
+	}
+	return doc.ReviewerID != nil && *doc.ReviewerID == user.ID &&
+		(doc.UploadedBy == nil || *doc.UploadedBy != user.ID)
+}
+
+// ApproveDocument approves a pending upload so solutions can cite it.
+// Admins and the document's reviewer can approve it
+func (h *DocumentHandler) ApproveDocument(c *gin.Context) {
+	doc, ok := h.document(c)
+	if !ok {
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	if !canDecide(user, doc) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and the document reviewer can approve it"})
+		return
+	}
+
+	doc, err := h.reviews.Approve(context.Background(), doc.ID, user.ID)
+	if err != nil {
+		documentApprovalError(c, err, "approve")
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+// RejectDocument sends a pending upload back to its owner with a note.
+// Admins and the document's reviewer can reject it
+func (h *DocumentHandler) RejectDocument(c *gin.Context) {
+	doc, ok := h.document(c)
+	if !ok {
+		return
+	}
+
+	var req models.DocumentRejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A note explaining the rejection is required"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	if !canDecide(user, doc) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and the document reviewer can reject it"})
+		return
 	}
 
-	var total float32
-	for _, result := range results {
-		total += result.Score
+	doc, err := h.reviews.Reject(context.Background(), doc.ID, user.ID, note)
+	if err != nil {
+		documentApprovalError(c, err, "reject")
+		return
 	}
 
-	return total / float32(len(results))
+	c.JSON(http.StatusOK, doc)
 }
 
+// PreviewSearchQuery shows how a search query would be spell-corrected and
+// expanded, without searching
+func (h *DocumentHandler) PreviewSearchQuery(c *gin.Context) {
+	var req models.QueryPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.queries.Process(context.Background(), req.Query))
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type EndpointAgentHandler struct {
+	agents *services.EndpointAgentService
+}
+
+func NewEndpointAgentHandler(agents *services.EndpointAgentService) *EndpointAgentHandler {
+	return &EndpointAgentHandler{agents: agents}
+}
+
+// RegisterEndpointAgent creates an agent and returns its check-in token
+// (admin only)
+func (h *EndpointAgentHandler) RegisterEndpointAgent(c *gin.Context) {
+	var req models.EndpointAgentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var resource string
+	if req.ResourceID != nil {
+		resource = *req.ResourceID
+	}
+	resourceID, err := h.agents.ResolveResource(context.Background(), resource)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	agent, err := h.agents.Create(context.Background(), req, resourceID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register endpoint agent"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, agent)
+}
+
+func (h *EndpointAgentHandler) UpdateEndpointAgent(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endpoint agent ID"})
+		return
+	}
+
+	var req models.EndpointAgentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// An empty resourceId unlinks the resource; omitting it leaves it as is
+	var resourceID *primitive.ObjectID
+	if req.ResourceID != nil {
+		if resourceID, err = h.agents.ResolveResource(context.Background(), *req.ResourceID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	agent, err := h.agents.Update(context.Background(), objectID, req, resourceID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Endpoint agent not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update endpoint agent"})
+		return
+	}
+
+	c.JSON(http.StatusOK, agent)
+}
+
+func (h *EndpointAgentHandler) DeleteEndpointAgent(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endpoint agent ID"})
+		return
+	}
+
+	if err := h.agents.Delete(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Endpoint agent not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete endpoint agent"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Endpoint agent deleted successfully"})
+}
+
+// RotateEndpointAgentToken issues a new check-in token for an agent
+func (h *EndpointAgentHandler) RotateEndpointAgentToken(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endpoint agent ID"})
+		return
+	}
+
+	agent, err := h.agents.RotateToken(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Endpoint agent not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, agent)
+}
+
+// ListEndpointAgents returns the fleet view, optionally filtered by
+// ?status=healthy|attention|stale and ?os=
+func (h *EndpointAgentHandler) ListEndpointAgents(c *gin.Context) {
+	agents, err := h.agents.List(context.Background(), c.Query("status"), c.Query("os"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch endpoint agents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"agents": agents})
+}
+
+// GetFleetSummary counts agents by status, OS and health issue
+func (h *EndpointAgentHandler) GetFleetSummary(c *gin.Context) {
+	summary, err := h.agents.Summary(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fleet summary"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetEndpointAgent returns an agent with the tickets linked to it
+func (h *EndpointAgentHandler) GetEndpointAgent(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endpoint agent ID"})
+		return
+	}
+
+	ctx := context.Background()
+	agent, err := h.agents.View(ctx, objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Endpoint agent not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch endpoint agent"})
+		return
+	}
+
+	tickets, err := h.agents.Tickets(ctx, objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tickets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"agent": agent, "tickets": tickets})
+}
+
+// LinkTicketEndpointAgent sets the machine a ticket is about, or clears it
+// with an empty agentId
+func (h *EndpointAgentHandler) LinkTicketEndpointAgent(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.LinkEndpointAgentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var agentID *primitive.ObjectID
+	if req.AgentID != "" {
+		id, err := primitive.ObjectIDFromHex(req.AgentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endpoint agent ID"})
+			return
+		}
+		agentID = &id
+	}
+
+	if err := h.agents.LinkTicket(context.Background(), ticketID, agentID); err != nil {
+		switch err {
+		case mongo.ErrNoDocuments:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		case services.ErrEndpointAgentNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Endpoint agent not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link endpoint agent"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Endpoint agent linked successfully"})
+}
+
+// CheckIn records an agent's inventory and health and hands it the
+// diagnostic requests routed to it. The agent authenticates with its token
+// as a bearer token.
+func (h *EndpointAgentHandler) CheckIn(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endpoint agent ID"})
+		return
+	}
+
+	agent, err := h.agents.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid agent token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch endpoint agent"})
+		return
+	}
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(agent.Token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid agent token"})
+		return
+	}
+	if !agent.Enabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Endpoint agent is disabled"})
+		return
+	}
+
+	var req models.AgentCheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.agents.CheckIn(context.Background(), agent, req, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record check-in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type EvaluationHandler struct {
+	db         *database.MongoDB
+	evaluation *services.EvaluationService
+}
+
+func NewEvaluationHandler(db *database.MongoDB, evaluation *services.EvaluationService) *EvaluationHandler {
+	return &EvaluationHandler{db: db, evaluation: evaluation}
+}
+
+// CreateDataset uploads a labeled evaluation dataset
+func (h *EvaluationHandler) CreateDataset(c *gin.Context) {
+	var req models.CreateEvalDatasetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.TriageCases) == 0 && len(req.RetrievalCases) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dataset must contain triage or retrieval cases"})
+		return
+	}
+	for _, tc := range req.TriageCases {
+		if tc.Title == "" || tc.ExpectedCategory == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Every triage case needs a title and expectedCategory"})
+			return
+		}
+	}
+	for _, rc := range req.RetrievalCases {
+		if rc.Query == "" || len(rc.RelevantDocs) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Every retrieval case needs a query and relevantDocs"})
+			return
+		}
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	dataset := models.EvalDataset{
+		ID:             primitive.NewObjectID(),
+		Name:           req.Name,
+		Description:    req.Description,
+		TriageCases:    req.TriageCases,
+		RetrievalCases: req.RetrievalCases,
+		CreatedBy:      user.ID,
+		CreatedAt:      time.Now(),
+	}
+	if _, err := h.db.GetCollection("eval_datasets").InsertOne(context.Background(), dataset); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create dataset"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dataset)
+}
+
+func (h *EvaluationHandler) ListDatasets(c *gin.Context) {
+	opts := options.Find().
+		SetSort(bson.D{{"createdAt", -1}}).
+		SetProjection(bson.M{"triageCases": 0, "retrievalCases": 0})
+	cursor, err := h.db.GetCollection("eval_datasets").Find(context.Background(), bson.M{}, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch datasets"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	datasets := []models.EvalDataset{}
+	if err := cursor.All(context.Background(), &datasets); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode datasets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"datasets": datasets})
+}
+
+func (h *EvaluationHandler) DeleteDataset(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dataset ID"})
+		return
+	}
+
+	result, err := h.db.GetCollection("eval_datasets").DeleteOne(context.Background(), bson.M{"_id": objectID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete dataset"})
+		return
+	}
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dataset not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dataset deleted successfully"})
+}
+
+// RunEvaluation runs a dataset through the current pipeline (or the given
+// triage options) and returns the metrics with the change since the last run
+func (h *EvaluationHandler) RunEvaluation(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dataset ID"})
+		return
+	}
+
+	var req models.RunEvaluationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var dataset models.EvalDataset
+	err = h.db.GetCollection("eval_datasets").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&dataset)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dataset not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dataset"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	run, err := h.evaluation.Run(context.Background(), dataset, req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store evaluation run"})
+		return
+	}
+
+	h.respondWithComparison(c, run)
+}
+
+// ListRuns returns evaluation history, optionally filtered by datasetId
+func (h *EvaluationHandler) ListRuns(c *gin.Context) {
+	var datasetID *primitive.ObjectID
+	if id := c.Query("datasetId"); id != "" {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dataset ID"})
+			return
+		}
+		datasetID = &objectID
+	}
+
+	runs, err := h.evaluation.ListRuns(context.Background(), datasetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch evaluation runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+func (h *EvaluationHandler) GetRun(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+		return
+	}
+
+	var run models.EvalRun
+	err = h.db.GetCollection("eval_runs").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&run)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Evaluation run not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch evaluation run"})
+		return
+	}
+
+	h.respondWithComparison(c, run)
+}
+
+func (h *EvaluationHandler) respondWithComparison(c *gin.Context, run models.EvalRun) {
+	response := gin.H{"run": run}
+	if previous, err := h.evaluation.PreviousRun(context.Background(), run); err == nil {
+		response["previousRunId"] = previous.ID
+		response["delta"] = services.CompareRuns(run, *previous)
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type ExperimentHandler struct {
+	experiments *services.ExperimentService
+	triage      *services.TriageService
+}
+
+func NewExperimentHandler(experiments *services.ExperimentService, triage *services.TriageService) *ExperimentHandler {
+	return &ExperimentHandler{experiments: experiments, triage: triage}
+}
+
+func (h *ExperimentHandler) CreateExperiment(c *gin.Context) {
+	var req models.CreateExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateExperiment(req.Variants); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, variant := range req.Variants {
+		if variant.Options.PromptVersion == "" {
+			continue
+		}
+		if _, err := h.triage.GetPromptTemplate(context.Background(), "triage", variant.Options.PromptVersion); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	exp, err := h.experiments.Create(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create experiment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, exp)
+}
+
+func (h *ExperimentHandler) ListExperiments(c *gin.Context) {
+	experiments, err := h.experiments.List(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch experiments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"experiments": experiments})
+}
+
+// StartExperiment activates an experiment, stopping any other running one
+func (h *ExperimentHandler) StartExperiment(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+func (h *ExperimentHandler) StopExperiment(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+func (h *ExperimentHandler) setActive(c *gin.Context, active bool) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid experiment ID"})
+		return
+	}
+
+	if err := h.experiments.SetActive(context.Background(), objectID, active); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update experiment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Experiment updated successfully", "active": active})
+}
+
+func (h *ExperimentHandler) DeleteExperiment(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid experiment ID"})
+		return
+	}
+
+	if err := h.experiments.Delete(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete experiment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Experiment deleted successfully"})
+}
+
+// GetExperimentResults returns accuracy, latency and cost per variant
+func (h *ExperimentHandler) GetExperimentResults(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid experiment ID"})
+		return
+	}
+
+	exp, err := h.experiments.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch experiment"})
+		return
+	}
+
+	results, err := h.experiments.Results(context.Background(), exp)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute experiment results"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"experiment": exp,
+		"results":    results,
+	})
+}
+
+// SubmitTriageFeedback records the category and priority a technician
+// confirmed for a triage run that was part of an experiment
+func (h *ExperimentHandler) SubmitTriageFeedback(c *gin.Context) {
+	var req models.TriageFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	runID, err := primitive.ObjectIDFromHex(req.RunID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	if err := h.experiments.RecordFeedback(context.Background(), runID, req.Category, req.Priority, user.ID, nil); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Triage run not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record feedback"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Feedback recorded"})
+}
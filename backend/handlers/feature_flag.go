@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type FeatureFlagHandler struct {
+	flags *services.FeatureFlagService
+}
+
+func NewFeatureFlagHandler(flags *services.FeatureFlagService) *FeatureFlagHandler {
+	return &FeatureFlagHandler{flags: flags}
+}
+
+// GetMyFeatureFlags returns which flags are on for the current user, so
+// clients can hide disabled features
+func (h *FeatureFlagHandler) GetMyFeatureFlags(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	c.JSON(http.StatusOK, gin.H{"flags": h.flags.Evaluate(context.Background(), &user)})
+}
+
+// ListFeatureFlags returns every flag with its targeting (admin only)
+func (h *FeatureFlagHandler) ListFeatureFlags(c *gin.Context) {
+	flags, err := h.flags.List(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feature flags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flags": flags})
+}
+
+// UpdateFeatureFlag creates or replaces the flag in :key (admin only)
+func (h *FeatureFlagHandler) UpdateFeatureFlag(c *gin.Context) {
+	var req models.FeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flag, err := services.ValidateFeatureFlag(c.Param("key"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	flag, err = h.flags.Save(context.Background(), flag, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update feature flag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// DeleteFeatureFlag removes a flag; built-in flags return to their default
+// (admin only)
+func (h *FeatureFlagHandler) DeleteFeatureFlag(c *gin.Context) {
+	if err := h.flags.Delete(context.Background(), c.Param("key")); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Feature flag not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete feature flag"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Feature flag deleted successfully"})
+}
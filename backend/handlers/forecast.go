@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type ForecastHandler struct {
+	forecasts *services.ForecastService
+}
+
+func NewForecastHandler(forecasts *services.ForecastService) *ForecastHandler {
+	return &ForecastHandler{forecasts: forecasts}
+}
+
+// GetForecast predicts next week's ticket load and suggested staffing.
+// Query: weeks (history, 2-52, default 8), ticketsPerTechnicianHour
+// (default 2) and an optional category.
+func (h *ForecastHandler) GetForecast(c *gin.Context) {
+	weeks := 8
+	if w := c.Query("weeks"); w != "" {
+		parsed, err := strconv.Atoi(w)
+		if err != nil || parsed < 2 || parsed > 52 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "weeks must be between 2 and 52"})
+			return
+		}
+		weeks = parsed
+	}
+
+	capacity := 2.0
+	if v := c.Query("ticketsPerTechnicianHour"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ticketsPerTechnicianHour must be a positive number"})
+			return
+		}
+		capacity = parsed
+	}
+
+	forecast, err := h.forecasts.Forecast(context.Background(), time.Now(), weeks, capacity, models.TicketCategory(c.Query("category")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute forecast"})
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type GenerationHandler struct {
+	generation *services.GenerationService
+}
+
+func NewGenerationHandler(generation *services.GenerationService) *GenerationHandler {
+	return &GenerationHandler{generation: generation}
+}
+
+// ListGenerationConfig returns the generation parameters of every AI
+// endpoint (admin only)
+func (h *GenerationHandler) ListGenerationConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"endpoints": h.generation.List(context.Background())})
+}
+
+// UpdateGenerationConfig sets the model, temperature, max tokens and top_p
+// of an AI endpoint; omitted fields use the built-in default (admin only)
+func (h *GenerationHandler) UpdateGenerationConfig(c *gin.Context) {
+	endpoint := c.Param("endpoint")
+	if !services.IsGenerationEndpoint(endpoint) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "AI endpoint not found"})
+		return
+	}
+
+	var req models.GenerationParams
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateGenerationParams(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	config, err := h.generation.Update(context.Background(), endpoint, req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update generation config"})
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// ResetGenerationConfig restores the built-in defaults of an AI endpoint
+// (admin only)
+func (h *GenerationHandler) ResetGenerationConfig(c *gin.Context) {
+	endpoint := c.Param("endpoint")
+	if !services.IsGenerationEndpoint(endpoint) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "AI endpoint not found"})
+		return
+	}
+
+	if err := h.generation.Reset(context.Background(), endpoint); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset generation config"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Generation config reset"})
+}
+
+// generationContext returns a context carrying the generation parameter
+// overrides given in the model, temperature, maxTokens and topP query
+// parameters. Only admins may override; on failure the error response has
+// been written and ok is false.
+func generationContext(c *gin.Context) (ctx context.Context, ok bool) {
+	ctx = context.Background()
+
+	var params models.GenerationParams
+	set := false
+	if model := c.Query("model"); model != "" {
+		params.Model = model
+		set = true
+	}
+	for name, target := range map[string]**float64{"temperature": &params.Temperature, "topP": &params.TopP} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+			return nil, false
+		}
+		*target = &f
+		set = true
+	}
+	if value := c.Query("maxTokens"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maxTokens"})
+			return nil, false
+		}
+		params.MaxTokens = n
+		set = true
+	}
+	if !set {
+		return ctx, true
+	}
+
+	user := c.MustGet("user").(models.User)
+	if user.Role != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can override generation parameters"})
+		return nil, false
+	}
+	if err := services.ValidateGenerationParams(params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return services.WithGenerationOverrides(ctx, params), true
+}
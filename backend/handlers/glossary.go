@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type GlossaryHandler struct {
+	glossary *services.GlossaryService
+}
+
+func NewGlossaryHandler(glossary *services.GlossaryService) *GlossaryHandler {
+	return &GlossaryHandler{glossary: glossary}
+}
+
+// ListGlossary returns the organization's acronyms and jargon (admin only)
+func (h *GlossaryHandler) ListGlossary(c *gin.Context) {
+	terms, err := h.glossary.List(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch glossary"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"terms": terms})
+}
+
+// UpdateGlossaryTerm creates or replaces the glossary entry for :term
+// (admin only)
+func (h *GlossaryHandler) UpdateGlossaryTerm(c *gin.Context) {
+	var req models.GlossaryTermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	term, err := services.ValidateGlossaryTerm(c.Param("term"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	term, err = h.glossary.Save(context.Background(), term, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update glossary term"})
+		return
+	}
+
+	c.JSON(http.StatusOK, term)
+}
+
+// DeleteGlossaryTerm removes the glossary entry for :term (admin only)
+func (h *GlossaryHandler) DeleteGlossaryTerm(c *gin.Context) {
+	if err := h.glossary.Delete(context.Background(), c.Param("term")); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Glossary term not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete glossary term"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Glossary term deleted successfully"})
+}
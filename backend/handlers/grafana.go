@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+// GrafanaHandler implements the SimpleJSON datasource API so copilot
+// metrics can be added to existing Grafana dashboards.
+type GrafanaHandler struct {
+	grafana *services.GrafanaService
+}
+
+func NewGrafanaHandler(grafana *services.GrafanaService) *GrafanaHandler {
+	return &GrafanaHandler{grafana: grafana}
+}
+
+// TestConnection answers the datasource "Save & test" check.
+func (h *GrafanaHandler) TestConnection(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Search lists the metrics a panel can query.
+func (h *GrafanaHandler) Search(c *gin.Context) {
+	var req models.GrafanaSearchRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, h.grafana.Search(req.Target))
+}
+
+// Query returns a time series for every requested target.
+func (h *GrafanaHandler) Query(c *gin.Context) {
+	var req models.GrafanaQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series, err := h.grafana.Query(context.Background(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/services"
+)
+
+type GuardrailHandler struct {
+	guardrails *services.GuardrailService
+}
+
+func NewGuardrailHandler(guardrails *services.GuardrailService) *GuardrailHandler {
+	return &GuardrailHandler{guardrails: guardrails}
+}
+
+// ListInjectionEvents returns suspected prompt injection attempts found in
+// ticket text and indexed documents (admin only)
+func (h *GuardrailHandler) ListInjectionEvents(c *gin.Context) {
+	limit := int64(50)
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = int64(l)
+	}
+
+	events, err := h.guardrails.ListInjectionEvents(context.Background(), c.Query("source"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch injection events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
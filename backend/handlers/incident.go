@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type IncidentHandler struct {
+	incidents *services.IncidentService
+}
+
+func NewIncidentHandler(incidents *services.IncidentService) *IncidentHandler {
+	return &IncidentHandler{incidents: incidents}
+}
+
+func parseObjectIDs(ids []string) ([]primitive.ObjectID, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, err
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+	return objectIDs, nil
+}
+
+// GetPublicStatus serves the built-in status page without authentication
+func (h *IncidentHandler) GetPublicStatus(c *gin.Context) {
+	status, err := h.incidents.PublicStatus(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// DeclareIncident opens a major incident from outage tickets or correlated
+// anomalies and publishes it to the status pages
+func (h *IncidentHandler) DeclareIncident(c *gin.Context) {
+	var req models.DeclareIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Impact != "" && !services.ValidIncidentImpact(req.Impact) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "impact must be minor, major or critical"})
+		return
+	}
+	ticketIDs, err := parseObjectIDs(req.TicketIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	anomalyIDs, err := parseObjectIDs(req.AnomalyIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anomaly ID"})
+		return
+	}
+	componentIDs, err := parseObjectIDs(req.ComponentIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid component ID"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	incident, err := h.incidents.Declare(context.Background(), req, ticketIDs, anomalyIDs, componentIDs, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to declare incident"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, incident)
+}
+
+// DeclareTicketIncident declares a major incident from an outage ticket
+func (h *IncidentHandler) DeclareTicketIncident(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.DeclareTicketIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Impact != "" && !services.ValidIncidentImpact(req.Impact) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "impact must be minor, major or critical"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	incident, err := h.incidents.DeclareFromTicket(context.Background(), ticketID, req, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to declare incident"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, incident)
+}
+
+// ListIncidents returns incidents, only unresolved ones with ?active=true
+func (h *IncidentHandler) ListIncidents(c *gin.Context) {
+	incidents, err := h.incidents.List(context.Background(), c.Query("active") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch incidents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"incidents": incidents})
+}
+
+func (h *IncidentHandler) GetIncident(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return
+	}
+
+	incident, err := h.incidents.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch incident"})
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
+
+// UpdateIncident posts a status update, e.g. identified or resolved
+func (h *IncidentHandler) UpdateIncident(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return
+	}
+
+	var req models.UpdateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !services.ValidIncidentStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be investigating, identified, monitoring or resolved"})
+		return
+	}
+	if req.Impact != "" && !services.ValidIncidentImpact(req.Impact) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "impact must be minor, major or critical"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	incident, err := h.incidents.Update(context.Background(), objectID, req, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
+
+// AssignIncidentRole sets the commander, comms, scribe or ops_lead
+func (h *IncidentHandler) AssignIncidentRole(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return
+	}
+
+	var req models.AssignIncidentRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !services.ValidIncidentRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be commander, comms, scribe or ops_lead"})
+		return
+	}
+	userID, err := primitive.ObjectIDFromHex(req.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	incident, err := h.incidents.AssignRole(context.Background(), objectID, req.Role, userID, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
+
+// AddIncidentNote records a war-room note on the incident timeline
+func (h *IncidentHandler) AddIncidentNote(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return
+	}
+
+	var req models.IncidentNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	incident, err := h.incidents.AddNote(context.Background(), objectID, req.Message, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
+
+func (h *IncidentHandler) ListStatusComponents(c *gin.Context) {
+	components, err := h.incidents.ListComponents(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch status components"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"components": components})
+}
+
+func (h *IncidentHandler) CreateStatusComponent(c *gin.Context) {
+	var req models.StatusComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resourceIDs, err := parseObjectIDs(req.ResourceIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+		return
+	}
+
+	component, err := h.incidents.CreateComponent(context.Background(), req, resourceIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create status component"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, component)
+}
+
+func (h *IncidentHandler) UpdateStatusComponent(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid component ID"})
+		return
+	}
+
+	var req models.StatusComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resourceIDs, err := parseObjectIDs(req.ResourceIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+		return
+	}
+
+	component, err := h.incidents.UpdateComponent(context.Background(), objectID, req, resourceIDs)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Status component not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status component"})
+		return
+	}
+
+	c.JSON(http.StatusOK, component)
+}
+
+func (h *IncidentHandler) DeleteStatusComponent(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid component ID"})
+		return
+	}
+
+	if err := h.incidents.DeleteComponent(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Status component not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete status component"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Status component deleted successfully"})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type IssueLinkHandler struct {
+	issues *services.IssueTrackerService
+}
+
+func NewIssueLinkHandler(issues *services.IssueTrackerService) *IssueLinkHandler {
+	return &IssueLinkHandler{issues: issues}
+}
+
+// issueLinkError responds to a failed link or issue creation.
+func issueLinkError(c *gin.Context, err error) {
+	switch {
+	case err == mongo.ErrNoDocuments:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+	case errors.Is(err, services.ErrIssueTrackerDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIssueAlreadyLinked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// LinkIssue links an existing GitHub or GitLab issue to a software ticket
+// and leaves a link to the ticket on the issue
+func (h *IssueLinkHandler) LinkIssue(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	var req models.LinkIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user := c.MustGet("user").(models.User)
+
+	link, err := h.issues.Link(context.Background(), ticketID, req, user.ID)
+	if err != nil {
+		issueLinkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// CreateIssue raises a GitHub or GitLab issue from a software ticket and
+// links it to the ticket
+func (h *IssueLinkHandler) CreateIssue(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	var req models.CreateIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user := c.MustGet("user").(models.User)
+
+	link, err := h.issues.Create(context.Background(), ticketID, req, user.ID)
+	if err != nil {
+		issueLinkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// UnlinkIssue removes the issue given by ?provider, ?repository and
+// ?number from a ticket
+func (h *IssueLinkHandler) UnlinkIssue(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	number, err := strconv.Atoi(c.Query("number"))
+	if err != nil || c.Query("provider") == "" || c.Query("repository") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider, repository and number are required"})
+		return
+	}
+
+	err = h.issues.Unlink(context.Background(), ticketID, models.IssueProvider(c.Query("provider")), c.Query("repository"), number)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Issue link not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Issue unlinked"})
+}
+
+// SyncIssues pulls the state of linked issues now instead of waiting for
+// the next scheduled pull (admin only)
+func (h *IssueLinkHandler) SyncIssues(c *gin.Context) {
+	if !h.issues.Configured() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "No issue tracker is configured"})
+		return
+	}
+
+	result, err := h.issues.Sync(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync issues"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+// kbSuggestionLimit is how many articles are suggested for an issue.
+const kbSuggestionLimit = 3
+
+type KBHandler struct {
+	kb *services.KBService
+}
+
+func NewKBHandler(kb *services.KBService) *KBHandler {
+	return &KBHandler{kb: kb}
+}
+
+// ListArticles returns articles in any state; ?status, ?visibility and
+// ?category narrow the list
+func (h *KBHandler) ListArticles(c *gin.Context) {
+	articles, err := h.kb.List(context.Background(),
+		models.ArticleStatus(c.Query("status")),
+		models.ArticleVisibility(c.Query("visibility")),
+		models.TicketCategory(c.Query("category")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch articles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"articles": articles})
+}
+
+func (h *KBHandler) GetArticle(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	article, err := h.kb.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch article"})
+		return
+	}
+
+	c.JSON(http.StatusOK, article)
+}
+
+// CreateArticle saves a new draft article
+func (h *KBHandler) CreateArticle(c *gin.Context) {
+	var req models.KBArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.kb.Validate(context.Background(), &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	article, err := h.kb.Create(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create article"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, article)
+}
+
+// UpdateArticle saves an edit as a new version. Published articles need to
+// be published again for the edit to reach readers.
+func (h *KBHandler) UpdateArticle(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	var req models.KBArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.kb.Validate(context.Background(), &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	article, err := h.kb.Update(context.Background(), objectID, req, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update article"})
+		return
+	}
+
+	c.JSON(http.StatusOK, article)
+}
+
+func (h *KBHandler) ListArticleVersions(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	versions, err := h.kb.Versions(context.Background(), objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch article versions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+func (h *KBHandler) GetArticleVersion(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+
+	v, err := h.kb.Version(context.Background(), objectID, version)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Article version not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch article version"})
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// PublishArticle publishes the latest version of an article and indexes it
+// (admin only)
+func (h *KBHandler) PublishArticle(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	article, err := h.kb.Publish(context.Background(), objectID, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to publish article"})
+		return
+	}
+
+	c.JSON(http.StatusOK, article)
+}
+
+// ArchiveArticle unpublishes an article and removes it from the index
+// (admin only)
+func (h *KBHandler) ArchiveArticle(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	article, err := h.kb.Archive(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive article"})
+		return
+	}
+
+	c.JSON(http.StatusOK, article)
+}
+
+func (h *KBHandler) DeleteArticle(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	if err := h.kb.Delete(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete article"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Article deleted successfully"})
+}
+
+// SuggestArticles returns published articles that may solve an issue.
+// Requesters only get public articles.
+func (h *KBHandler) SuggestArticles(c *gin.Context) {
+	var req models.SuggestArticlesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	suggestions, err := h.kb.Suggest(context.Background(), req.Title+"\n"+req.Description,
+		kbSuggestionLimit, user.Role == models.RoleRequester)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest articles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"articles": suggestions})
+}
+
+// GetPublicArticle returns the published version of a public article
+func (h *KBHandler) GetPublicArticle(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	article, err := h.kb.PublicArticle(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments || err == services.ErrArticleNotPublished {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch article"})
+		return
+	}
+
+	c.JSON(http.StatusOK, article)
+}
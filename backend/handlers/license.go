@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type LicenseHandler struct {
+	licenses *services.LicenseService
+}
+
+func NewLicenseHandler(licenses *services.LicenseService) *LicenseHandler {
+	return &LicenseHandler{licenses: licenses}
+}
+
+// ListLicenses returns the license inventory; ?expiringWithin=720h limits it
+// to licenses expiring within that window
+func (h *LicenseHandler) ListLicenses(c *gin.Context) {
+	var within time.Duration
+	if value := c.Query("expiringWithin"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expiringWithin must be a positive duration such as 720h"})
+			return
+		}
+		within = d
+	}
+
+	licenses, err := h.licenses.List(context.Background(), within)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch licenses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"licenses": licenses})
+}
+
+func (h *LicenseHandler) GetLicense(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid license ID"})
+		return
+	}
+
+	license, err := h.licenses.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "License not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch license"})
+		return
+	}
+
+	c.JSON(http.StatusOK, license)
+}
+
+// CreateLicense adds a license to the inventory (admin only)
+func (h *LicenseHandler) CreateLicense(c *gin.Context) {
+	var req models.LicenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	license, err := h.licenses.Validate(context.Background(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	view, err := h.licenses.Create(context.Background(), license, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create license"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, view)
+}
+
+func (h *LicenseHandler) UpdateLicense(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid license ID"})
+		return
+	}
+
+	var req models.LicenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	license, err := h.licenses.Validate(context.Background(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	view, err := h.licenses.Update(context.Background(), objectID, license)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "License not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update license"})
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+func (h *LicenseHandler) DeleteLicense(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid license ID"})
+		return
+	}
+
+	if err := h.licenses.Delete(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "License not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete license"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "License deleted successfully"})
+}
+
+// LinkTicketLicense links a ticket to a license record, or unlinks it with
+// an empty licenseId
+func (h *LicenseHandler) LinkTicketLicense(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.LinkLicenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	var licenseID *primitive.ObjectID
+	if req.LicenseID != "" {
+		id, err := primitive.ObjectIDFromHex(req.LicenseID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid license ID"})
+			return
+		}
+		if _, err := h.licenses.Get(ctx, id); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "License not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch license"})
+			return
+		}
+		licenseID = &id
+	}
+
+	if err := h.licenses.SetTicketLicense(ctx, ticketID, licenseID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link license"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "License linked successfully"})
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type MaintenanceHandler struct {
+	maintenance *services.MaintenanceService
+}
+
+func NewMaintenanceHandler(maintenance *services.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{maintenance: maintenance}
+}
+
+func (h *MaintenanceHandler) CreateMaintenanceWindow(c *gin.Context) {
+	var req models.MaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateMaintenanceWindow(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	window, err := h.maintenance.Create(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create maintenance window"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, window)
+}
+
+// ListMaintenanceWindows lists windows, filtered by ?tag=key:value and, with
+// ?active=true, to the ones in effect now.
+func (h *MaintenanceHandler) ListMaintenanceWindows(c *gin.Context) {
+	tags, err := services.ParseTagFilters(c.QueryArray("tag"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var at *time.Time
+	if active, _ := strconv.ParseBool(c.Query("active")); active {
+		now := time.Now()
+		at = &now
+	}
+
+	windows, err := h.maintenance.List(context.Background(), tags, at)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch maintenance windows"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"windows": windows})
+}
+
+func (h *MaintenanceHandler) UpdateMaintenanceWindow(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+		return
+	}
+
+	var req models.MaintenanceWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateMaintenanceWindow(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	window, err := h.maintenance.Update(context.Background(), objectID, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Maintenance window not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update maintenance window"})
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+func (h *MaintenanceHandler) DeleteMaintenanceWindow(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+		return
+	}
+
+	if err := h.maintenance.Delete(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Maintenance window not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete maintenance window"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Maintenance window deleted successfully"})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/xml"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type MessagingHandler struct {
+	messaging *services.MessagingService
+	archive   *services.TicketArchiveService
+	// publicAPIURL is the base of the webhook URL Twilio signs
+	publicAPIURL string
+}
+
+func NewMessagingHandler(messaging *services.MessagingService, archive *services.TicketArchiveService, publicAPIURL string) *MessagingHandler {
+	return &MessagingHandler{messaging: messaging, archive: archive, publicAPIURL: strings.TrimRight(publicAPIURL, "/")}
+}
+
+// TwilioWebhook receives SMS and WhatsApp messages from Twilio and replies
+// with TwiML
+func (h *MessagingHandler) TwilioWebhook(c *gin.Context) {
+	if !h.messaging.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Messaging is not configured"})
+		return
+	}
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form body"})
+		return
+	}
+	webhookURL := h.publicAPIURL + c.Request.URL.RequestURI()
+	if !h.messaging.VerifySignature(webhookURL, c.Request.PostForm, c.GetHeader("X-Twilio-Signature")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid Twilio signature"})
+		return
+	}
+
+	reply, err := h.messaging.HandleInbound(context.Background(), c.PostForm("From"), c.PostForm("Body"), c.PostForm("MessageSid"))
+	if err != nil {
+		log.Printf("Failed to handle message from %s: %v", c.PostForm("From"), err)
+		reply = "Sorry, something went wrong. Please try again later."
+	}
+
+	var twiml strings.Builder
+	twiml.WriteString(xml.Header + "<Response>")
+	if reply != "" {
+		twiml.WriteString("<Message>")
+		xml.EscapeText(&twiml, []byte(reply))
+		twiml.WriteString("</Message>")
+	}
+	twiml.WriteString("</Response>")
+	c.Data(http.StatusOK, "application/xml", []byte(twiml.String()))
+}
+
+// GetMessagingSubscription returns the user's registered phone, the
+// channels it opted in to and the numbers to text
+func (h *MessagingHandler) GetMessagingSubscription(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	sub, err := h.messaging.Subscription(context.Background(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messaging subscription"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessagingSubscriptionView{MessagingSubscription: sub, Numbers: h.messaging.Numbers()})
+}
+
+// SetMessagingSubscription registers the user's phone; they opt in by
+// texting START from it
+func (h *MessagingHandler) SetMessagingSubscription(c *gin.Context) {
+	if !h.messaging.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Messaging is not configured"})
+		return
+	}
+	var req models.MessagingSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user := c.MustGet("user").(models.User)
+
+	sub, err := h.messaging.Subscribe(context.Background(), user.ID, req.Phone)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidPhone:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case services.ErrPhoneTaken:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save messaging subscription"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessagingSubscriptionView{MessagingSubscription: &sub, Numbers: h.messaging.Numbers()})
+}
+
+// DeleteMessagingSubscription forgets the user's phone
+func (h *MessagingHandler) DeleteMessagingSubscription(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	if err := h.messaging.Unsubscribe(context.Background(), user.ID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No phone registered"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete messaging subscription"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Messaging subscription deleted successfully"})
+}
+
+// ListTicketMessages returns the SMS and WhatsApp messages logged with the
+// ticket
+func (h *MessagingHandler) ListTicketMessages(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := loadUserTicket(c, h.archive, user)
+	if !ok {
+		return
+	}
+
+	messages, err := h.messaging.TicketMessages(context.Background(), ticket.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"messages": messages})
+}
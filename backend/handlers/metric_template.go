@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type MetricTemplateHandler struct {
+	templates *services.MetricTemplateService
+	taxonomy  *services.TaxonomyService
+}
+
+func NewMetricTemplateHandler(templates *services.MetricTemplateService, taxonomy *services.TaxonomyService) *MetricTemplateHandler {
+	return &MetricTemplateHandler{templates: templates, taxonomy: taxonomy}
+}
+
+func (h *MetricTemplateHandler) CreateMetricTemplate(c *gin.Context) {
+	var req models.MetricTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateMetricTemplate(h.taxonomy.Get(context.Background()), req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	template, err := h.templates.Create(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create metric template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// ListMetricTemplates lists templates, filtered by ?tag=key:value.
+func (h *MetricTemplateHandler) ListMetricTemplates(c *gin.Context) {
+	tags, err := services.ParseTagFilters(c.QueryArray("tag"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	templates, err := h.templates.List(context.Background(), tags)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch metric templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+func (h *MetricTemplateHandler) UpdateMetricTemplate(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid metric template ID"})
+		return
+	}
+
+	var req models.MetricTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateMetricTemplate(h.taxonomy.Get(context.Background()), req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.templates.Update(context.Background(), objectID, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Metric template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update metric template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (h *MetricTemplateHandler) DeleteMetricTemplate(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid metric template ID"})
+		return
+	}
+
+	if err := h.templates.Delete(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Metric template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete metric template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Metric template deleted successfully"})
+}
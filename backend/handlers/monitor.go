@@ -6,19 +6,23 @@ import (
     "time"
 
     "github.com/gin-gonic/gin"
+    "github.com/gin-gonic/gin/binding"
     "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
 
     "intelliops-ai-copilot/database"
     "intelliops-ai-copilot/models"
+    "intelliops-ai-copilot/services"
 )
 
 type MonitorHandler struct {
-    db *database.MongoDB
+    db       *database.MongoDB
+    taxonomy *services.TaxonomyService
 }
 
-func NewMonitorHandler(db *database.MongoDB) *MonitorHandler {
-    return &MonitorHandler{db: db}
+func NewMonitorHandler(db *database.MongoDB, taxonomy *services.TaxonomyService) *MonitorHandler {
+    return &MonitorHandler{db: db, taxonomy: taxonomy}
 }
 
 // Resources CRUD
@@ -40,7 +44,15 @@ func (h *MonitorHandler) CreateResource(c *gin.Context) {
 }
 
 func (h *MonitorHandler) ListResources(c *gin.Context) {
-    cur, err := h.db.GetCollection("mon_resources").Find(context.Background(), bson.M{})
+    filter := bson.M{}
+    tags, err := services.ParseTagFilters(c.QueryArray("tag"))
+    if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+    if len(tags) > 0 {
+        ids, err := services.ResourceIDsWithTags(context.Background(), h.db, tags)
+        if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "fetch failed"}); return }
+        filter["_id"] = bson.M{"$in": ids}
+    }
+    cur, err := h.db.GetCollection("mon_resources").Find(context.Background(), filter)
     if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "fetch failed"}); return }
     defer cur.Close(context.Background())
     var items []models.MonitoredResource
@@ -73,6 +85,10 @@ func (h *MonitorHandler) DeleteResource(c *gin.Context) {
 func (h *MonitorHandler) CreateMetric(c *gin.Context) {
     var m models.MetricConfig
     if err := c.ShouldBindJSON(&m); err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+    if err := services.ValidateAnomalyPolicy(h.taxonomy.Get(context.Background()), m.SeverityThresholds, m.PriorityMap); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
     m.ID = primitive.NewObjectID()
     m.CreatedAt = time.Now()
     m.UpdatedAt = time.Now()
@@ -101,7 +117,20 @@ func (h *MonitorHandler) UpdateMetric(c *gin.Context) {
     oid, err := primitive.ObjectIDFromHex(id)
     if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"}); return }
     var m bson.M
-    if err := c.ShouldBindJSON(&m); err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+    if err := c.ShouldBindBodyWith(&m, binding.JSON); err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+    // Decode the severity policy fields again typed so they can be validated
+    var policy struct {
+        SeverityThresholds *models.SeverityThresholds        `json:"severityThresholds"`
+        PriorityMap        map[string]models.TicketPriority `json:"priorityMap"`
+    }
+    if err := c.ShouldBindBodyWith(&policy, binding.JSON); err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+    if err := services.ValidateAnomalyPolicy(h.taxonomy.Get(context.Background()), policy.SeverityThresholds, policy.PriorityMap); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    if _, ok := m["severityThresholds"]; ok {
+        m["severityThresholds"] = policy.SeverityThresholds
+    }
     m["updatedAt"] = time.Now()
     _, err = h.db.GetCollection("mon_metrics").UpdateByID(context.Background(), oid, bson.M{"$set": m})
     if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"}); return }
@@ -117,10 +146,34 @@ func (h *MonitorHandler) DeleteMetric(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{"message": "deleted"})
 }
 
+// EffectiveMetrics lists the metrics a resource is monitored with,
+// including those inherited from tag-based templates.
+func (h *MonitorHandler) EffectiveMetrics(c *gin.Context) {
+    oid, err := primitive.ObjectIDFromHex(c.Param("id"))
+    if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"}); return }
+    var r models.MonitoredResource
+    if err := h.db.GetCollection("mon_resources").FindOne(context.Background(), bson.M{"_id": oid}).Decode(&r); err != nil {
+        if err == mongo.ErrNoDocuments { c.JSON(http.StatusNotFound, gin.H{"error": "resource not found"}); return }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "fetch failed"})
+        return
+    }
+    metrics, err := services.NewMetricTemplateService(h.db).Effective(context.Background(), r)
+    if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "fetch failed"}); return }
+    c.JSON(http.StatusOK, metrics)
+}
+
 // List anomalies
 func (h *MonitorHandler) ListAnomalies(c *gin.Context) {
     filter := bson.M{}
     if s := c.Query("status"); s != "" { filter["status"] = s }
+    // ?tag=env:prod keeps anomalies on resources carrying all given tags
+    tags, err := services.ParseTagFilters(c.QueryArray("tag"))
+    if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+    if len(tags) > 0 {
+        ids, err := services.ResourceIDsWithTags(context.Background(), h.db, tags)
+        if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "fetch failed"}); return }
+        filter["resourceId"] = bson.M{"$in": ids}
+    }
     cur, err := h.db.GetCollection("mon_anomalies").Find(context.Background(), filter)
     if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "fetch failed"}); return }
     defer cur.Close(context.Background())
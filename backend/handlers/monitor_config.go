@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+const monitoringExportVersion = 1
+
+// ExportConfig serializes monitored resources and their metric configs.
+// ?format=json (default), yaml or hcl (Terraform-style, export only).
+func (h *MonitorHandler) ExportConfig(c *gin.Context) {
+	ctx := context.Background()
+
+	cur, err := h.db.GetCollection("mon_resources").Find(ctx, bson.M{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "fetch failed"})
+		return
+	}
+	var resources []models.MonitoredResource
+	err = cur.All(ctx, &resources)
+	cur.Close(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "decode failed"})
+		return
+	}
+
+	cur, err = h.db.GetCollection("mon_metrics").Find(ctx, bson.M{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "fetch failed"})
+		return
+	}
+	var metrics []models.MetricConfig
+	err = cur.All(ctx, &metrics)
+	cur.Close(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "decode failed"})
+		return
+	}
+
+	byResource := map[primitive.ObjectID][]models.ExportedMetric{}
+	for _, m := range metrics {
+		byResource[m.ResourceID] = append(byResource[m.ResourceID], models.ExportedMetric{
+			MetricName:         m.MetricName,
+			Statistic:          m.Statistic,
+			PeriodSeconds:      m.PeriodSeconds,
+			WindowSize:         m.WindowSize,
+			ZScore:             m.ZScore,
+			MinConsecutive:     m.MinConsecutive,
+			Direction:          m.Direction,
+			PriorityMap:        m.PriorityMap,
+			SeverityThresholds: m.SeverityThresholds,
+			RecoveryPoints:     m.RecoveryPoints,
+			Enabled:            m.Enabled,
+		})
+	}
+
+	export := models.MonitoringConfigExport{
+		Version:    monitoringExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Resources:  []models.ExportedResource{},
+	}
+	for _, r := range resources {
+		exported := byResource[r.ID]
+		if exported == nil {
+			exported = []models.ExportedMetric{}
+		}
+		sort.Slice(exported, func(i, j int) bool { return exported[i].MetricName < exported[j].MetricName })
+		export.Resources = append(export.Resources, models.ExportedResource{
+			Type:       r.Type,
+			Identifier: r.Identifier,
+			Namespace:  r.Namespace,
+			Dimensions: r.Dimensions,
+			Tags:       r.Tags,
+			Enabled:    r.Enabled,
+			Metrics:    exported,
+		})
+	}
+	// Stable ordering keeps diffs in version control small
+	sort.Slice(export.Resources, func(i, j int) bool {
+		if export.Resources[i].Type != export.Resources[j].Type {
+			return export.Resources[i].Type < export.Resources[j].Type
+		}
+		return export.Resources[i].Identifier < export.Resources[j].Identifier
+	})
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.Header("Content-Disposition", `attachment; filename="monitoring-config.json"`)
+		c.JSON(http.StatusOK, export)
+	case "yaml":
+		c.Header("Content-Disposition", `attachment; filename="monitoring-config.yaml"`)
+		c.YAML(http.StatusOK, export)
+	case "hcl":
+		c.Header("Content-Disposition", `attachment; filename="monitoring.tf"`)
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(monitoringHCL(export)))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, yaml or hcl"})
+	}
+}
+
+// ImportConfig applies an exported configuration (JSON, or YAML with
+// ?format=yaml or a YAML content type). Existing resources and metrics are
+// updated in place; with ?mode=replace anything not in the file is deleted.
+// ?dryRun=true reports the changes without applying them.
+func (h *MonitorHandler) ImportConfig(c *gin.Context) {
+	var config models.MonitoringConfigExport
+	var err error
+	if c.Query("format") == "yaml" || strings.Contains(c.ContentType(), "yaml") {
+		err = c.ShouldBindYAML(&config)
+	} else {
+		err = c.ShouldBindJSON(&config)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if config.Version != monitoringExportVersion {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported export version %d", config.Version)})
+		return
+	}
+	if err := validateMonitoringConfig(config, h.taxonomy.Get(context.Background())); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mode := c.DefaultQuery("mode", "merge")
+	if mode != "merge" && mode != "replace" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be merge or replace"})
+		return
+	}
+
+	result, err := h.applyMonitoringConfig(context.Background(), config, mode == "replace", c.Query("dryRun") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "import failed: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func validateMonitoringConfig(config models.MonitoringConfigExport, taxonomy models.Taxonomy) error {
+	seen := map[string]bool{}
+	for _, r := range config.Resources {
+		if r.Type == "" || r.Identifier == "" || r.Namespace == "" {
+			return fmt.Errorf("every resource needs type, identifier and namespace")
+		}
+		key := string(r.Type) + "/" + r.Identifier
+		if seen[key] {
+			return fmt.Errorf("duplicate resource %s", key)
+		}
+		seen[key] = true
+
+		metrics := map[string]bool{}
+		for _, m := range r.Metrics {
+			if m.MetricName == "" || m.Statistic == "" || m.PeriodSeconds <= 0 || m.WindowSize <= 0 {
+				return fmt.Errorf("resource %s: every metric needs metricName, statistic, periodSeconds and windowSize", key)
+			}
+			if m.Direction != "" && m.Direction != models.DirectionAbove && m.Direction != models.DirectionBelow {
+				return fmt.Errorf("resource %s: direction must be above or below", key)
+			}
+			if m.RecoveryPoints < 0 {
+				return fmt.Errorf("resource %s: recoveryPoints must not be negative", key)
+			}
+			if err := services.ValidateAnomalyPolicy(taxonomy, m.SeverityThresholds, m.PriorityMap); err != nil {
+				return fmt.Errorf("resource %s metric %s: %v", key, m.MetricName, err)
+			}
+			mkey := m.MetricName + "/" + m.Statistic
+			if metrics[mkey] {
+				return fmt.Errorf("resource %s: duplicate metric %s", key, mkey)
+			}
+			metrics[mkey] = true
+		}
+	}
+	return nil
+}
+
+func (h *MonitorHandler) applyMonitoringConfig(ctx context.Context, config models.MonitoringConfigExport, replace, dryRun bool) (models.MonitoringImportResult, error) {
+	result := models.MonitoringImportResult{DryRun: dryRun}
+	resourcesColl := h.db.GetCollection("mon_resources")
+	metricsColl := h.db.GetCollection("mon_metrics")
+	now := time.Now()
+
+	keptResources := []primitive.ObjectID{}
+	for _, r := range config.Resources {
+		var existing models.MonitoredResource
+		err := resourcesColl.FindOne(ctx, bson.M{"type": r.Type, "identifier": r.Identifier}).Decode(&existing)
+		resourceID := existing.ID
+		fields := bson.M{
+			"type":       r.Type,
+			"identifier": r.Identifier,
+			"namespace":  r.Namespace,
+			"dimensions": r.Dimensions,
+			"tags":       r.Tags,
+			"enabled":    r.Enabled,
+			"updatedAt":  now,
+		}
+		if err == nil {
+			result.ResourcesUpdated++
+			if !dryRun {
+				if _, err := resourcesColl.UpdateByID(ctx, resourceID, bson.M{"$set": fields}); err != nil {
+					return result, err
+				}
+			}
+		} else {
+			result.ResourcesCreated++
+			resourceID = primitive.NewObjectID()
+			if !dryRun {
+				fields["_id"] = resourceID
+				fields["createdAt"] = now
+				if _, err := resourcesColl.InsertOne(ctx, fields); err != nil {
+					return result, err
+				}
+			}
+		}
+		keptResources = append(keptResources, resourceID)
+
+		keptMetrics := []primitive.ObjectID{}
+		for _, m := range r.Metrics {
+			direction := m.Direction
+			if direction == "" {
+				direction = models.DirectionAbove
+			}
+			fields := bson.M{
+				"resourceId":         resourceID,
+				"metricName":         m.MetricName,
+				"statistic":          m.Statistic,
+				"periodSeconds":      m.PeriodSeconds,
+				"windowSize":         m.WindowSize,
+				"zScore":             m.ZScore,
+				"minConsecutive":     m.MinConsecutive,
+				"direction":          direction,
+				"priorityMap":        m.PriorityMap,
+				"severityThresholds": m.SeverityThresholds,
+				"recoveryPoints":     m.RecoveryPoints,
+				"enabled":            m.Enabled,
+				"updatedAt":          now,
+			}
+			var existingMetric models.MetricConfig
+			err := metricsColl.FindOne(ctx, bson.M{"resourceId": resourceID, "metricName": m.MetricName, "statistic": m.Statistic}).Decode(&existingMetric)
+			if err == nil {
+				result.MetricsUpdated++
+				keptMetrics = append(keptMetrics, existingMetric.ID)
+				if !dryRun {
+					if _, err := metricsColl.UpdateByID(ctx, existingMetric.ID, bson.M{"$set": fields}); err != nil {
+						return result, err
+					}
+				}
+				continue
+			}
+			result.MetricsCreated++
+			metricID := primitive.NewObjectID()
+			keptMetrics = append(keptMetrics, metricID)
+			if !dryRun {
+				fields["_id"] = metricID
+				fields["createdAt"] = now
+				if _, err := metricsColl.InsertOne(ctx, fields); err != nil {
+					return result, err
+				}
+			}
+		}
+
+		if replace {
+			filter := bson.M{"resourceId": resourceID, "_id": bson.M{"$nin": keptMetrics}}
+			n, err := h.countOrDelete(ctx, "mon_metrics", filter, dryRun)
+			if err != nil {
+				return result, err
+			}
+			result.MetricsDeleted += n
+		}
+	}
+
+	if replace {
+		orphanMetrics := bson.M{"resourceId": bson.M{"$nin": keptResources}}
+		n, err := h.countOrDelete(ctx, "mon_metrics", orphanMetrics, dryRun)
+		if err != nil {
+			return result, err
+		}
+		result.MetricsDeleted += n
+
+		n, err = h.countOrDelete(ctx, "mon_resources", bson.M{"_id": bson.M{"$nin": keptResources}}, dryRun)
+		if err != nil {
+			return result, err
+		}
+		result.ResourcesDeleted = n
+	}
+	return result, nil
+}
+
+func (h *MonitorHandler) countOrDelete(ctx context.Context, collection string, filter bson.M, dryRun bool) (int, error) {
+	if dryRun {
+		n, err := h.db.GetCollection(collection).CountDocuments(ctx, filter)
+		return int(n), err
+	}
+	res, err := h.db.GetCollection(collection).DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return int(res.DeletedCount), nil
+}
+
+var hclNameSanitizer = regexp.MustCompile(`[^a-z0-9_]+`)
+
+func hclName(parts ...string) string {
+	name := hclNameSanitizer.ReplaceAllString(strings.ToLower(strings.Join(parts, "_")), "_")
+	return strings.Trim(name, "_")
+}
+
+// monitoringHCL renders the configuration as Terraform-style resources.
+func monitoringHCL(export models.MonitoringConfigExport) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# IntelliOps monitoring configuration, exported %s\n", export.ExportedAt.Format(time.RFC3339)))
+
+	for _, r := range export.Resources {
+		name := hclName(string(r.Type), r.Identifier)
+		b.WriteString(fmt.Sprintf("\nresource \"intelliops_monitored_resource\" %q {\n", name))
+		b.WriteString(fmt.Sprintf("  type       = %q\n", r.Type))
+		b.WriteString(fmt.Sprintf("  identifier = %q\n", r.Identifier))
+		b.WriteString(fmt.Sprintf("  namespace  = %q\n", r.Namespace))
+		b.WriteString(fmt.Sprintf("  enabled    = %t\n", r.Enabled))
+		writeHCLMap(&b, "dimensions", r.Dimensions)
+		writeHCLMap(&b, "tags", r.Tags)
+		b.WriteString("}\n")
+
+		for _, m := range r.Metrics {
+			b.WriteString(fmt.Sprintf("\nresource \"intelliops_metric_config\" %q {\n", hclName(name, m.MetricName, m.Statistic)))
+			b.WriteString(fmt.Sprintf("  resource_id     = intelliops_monitored_resource.%s.id\n", name))
+			b.WriteString(fmt.Sprintf("  metric_name     = %q\n", m.MetricName))
+			b.WriteString(fmt.Sprintf("  statistic       = %q\n", m.Statistic))
+			b.WriteString(fmt.Sprintf("  period_seconds  = %d\n", m.PeriodSeconds))
+			b.WriteString(fmt.Sprintf("  window_size     = %d\n", m.WindowSize))
+			b.WriteString(fmt.Sprintf("  z_score         = %g\n", m.ZScore))
+			b.WriteString(fmt.Sprintf("  min_consecutive = %d\n", m.MinConsecutive))
+			b.WriteString(fmt.Sprintf("  direction       = %q\n", m.Direction))
+			b.WriteString(fmt.Sprintf("  enabled         = %t\n", m.Enabled))
+			priorities := map[string]string{}
+			for k, v := range m.PriorityMap {
+				priorities[k] = string(v)
+			}
+			writeHCLMap(&b, "priority_map", priorities)
+			if m.RecoveryPoints > 0 {
+				b.WriteString(fmt.Sprintf("  recovery_points = %d\n", m.RecoveryPoints))
+			}
+			if t := m.SeverityThresholds; t != nil {
+				b.WriteString("  severity_thresholds = {\n")
+				b.WriteString(fmt.Sprintf("    medium   = %g\n", t.Medium))
+				b.WriteString(fmt.Sprintf("    high     = %g\n", t.High))
+				b.WriteString(fmt.Sprintf("    critical = %g\n", t.Critical))
+				b.WriteString("  }\n")
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+func writeHCLMap(b *strings.Builder, name string, m map[string]string) {
+	if len(m) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.WriteString(fmt.Sprintf("  %s = {\n", name))
+	for _, k := range keys {
+		b.WriteString(fmt.Sprintf("    %q = %q\n", k, m[k]))
+	}
+	b.WriteString("  }\n")
+}
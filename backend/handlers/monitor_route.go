@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type MonitorRouteHandler struct {
+	db     *database.MongoDB
+	routes *services.MonitorRouteService
+}
+
+func NewMonitorRouteHandler(db *database.MongoDB, routes *services.MonitorRouteService) *MonitorRouteHandler {
+	return &MonitorRouteHandler{db: db, routes: routes}
+}
+
+func (h *MonitorRouteHandler) CreateMonitorRoute(c *gin.Context) {
+	var req models.MonitorRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.routes.Validate(context.Background(), req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	route, err := h.routes.Create(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create monitoring route"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, route)
+}
+
+func (h *MonitorRouteHandler) ListMonitorRoutes(c *gin.Context) {
+	routes, err := h.routes.List(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch monitoring routes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"routes": routes})
+}
+
+func (h *MonitorRouteHandler) UpdateMonitorRoute(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid monitoring route ID"})
+		return
+	}
+
+	var req models.MonitorRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.routes.Validate(context.Background(), req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, err := h.routes.Update(context.Background(), objectID, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Monitoring route not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update monitoring route"})
+		return
+	}
+
+	c.JSON(http.StatusOK, route)
+}
+
+func (h *MonitorRouteHandler) DeleteMonitorRoute(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid monitoring route ID"})
+		return
+	}
+
+	if err := h.routes.Delete(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Monitoring route not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete monitoring route"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Monitoring route deleted successfully"})
+}
+
+// PreviewMonitorRoutes shows which routes an anomaly of the given severity
+// on a resource, or an alert with the given labels, would be sent to.
+func (h *MonitorRouteHandler) PreviewMonitorRoutes(c *gin.Context) {
+	var req struct {
+		ResourceID string            `json:"resourceId"`
+		Severity   string            `json:"severity" binding:"required"`
+		Labels     map[string]string `json:"labels"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var resource *models.MonitoredResource
+	if req.ResourceID != "" {
+		objectID, err := primitive.ObjectIDFromHex(req.ResourceID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+			return
+		}
+		var r models.MonitoredResource
+		if err := h.db.GetCollection("mon_resources").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&r); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch resource"})
+			return
+		}
+		resource = &r
+	}
+
+	routes, err := h.routes.Match(context.Background(), resource, req.Severity, req.Labels)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to match monitoring routes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"routes": routes})
+}
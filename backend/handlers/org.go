@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type OrgHandler struct {
+	org *services.OrgService
+}
+
+func NewOrgHandler(org *services.OrgService) *OrgHandler {
+	return &OrgHandler{org: org}
+}
+
+// ListOrgUnits returns the org hierarchy, or a flat list with ?flat=true
+// (admin only)
+func (h *OrgHandler) ListOrgUnits(c *gin.Context) {
+	if c.Query("flat") == "true" {
+		units, err := h.org.ListUnits(context.Background())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch org units"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"units": units})
+		return
+	}
+
+	tree, err := h.org.Tree(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch org units"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"units": tree})
+}
+
+func (h *OrgHandler) CreateOrgUnit(c *gin.Context) {
+	var req models.OrgUnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.org.ValidateOrgUnit(context.Background(), nil, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unit, err := h.org.CreateUnit(context.Background(), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create org unit"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, unit)
+}
+
+func (h *OrgHandler) UpdateOrgUnit(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid org unit ID"})
+		return
+	}
+
+	var req models.OrgUnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.org.ValidateOrgUnit(context.Background(), &objectID, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unit, err := h.org.UpdateUnit(context.Background(), objectID, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Org unit not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update org unit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, unit)
+}
+
+func (h *OrgHandler) DeleteOrgUnit(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid org unit ID"})
+		return
+	}
+
+	if err := h.org.DeleteUnit(context.Background(), objectID); err != nil {
+		switch err {
+		case mongo.ErrNoDocuments:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Org unit not found"})
+		case services.ErrOrgUnitHasChildren:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete org unit"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Org unit deleted successfully"})
+}
+
+func (h *OrgHandler) ListTicketRoutes(c *gin.Context) {
+	routes, err := h.org.ListRoutes(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket routes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"routes": routes})
+}
+
+func (h *OrgHandler) CreateTicketRoute(c *gin.Context) {
+	var req models.TicketRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.org.ValidateTicketRoute(context.Background(), req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	route, err := h.org.CreateRoute(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create ticket route"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, route)
+}
+
+func (h *OrgHandler) UpdateTicketRoute(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket route ID"})
+		return
+	}
+
+	var req models.TicketRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.org.ValidateTicketRoute(context.Background(), req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, err := h.org.UpdateRoute(context.Background(), objectID, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket route not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update ticket route"})
+		return
+	}
+
+	c.JSON(http.StatusOK, route)
+}
+
+func (h *OrgHandler) DeleteTicketRoute(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket route ID"})
+		return
+	}
+
+	if err := h.org.DeleteRoute(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket route not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete ticket route"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ticket route deleted successfully"})
+}
+
+// PreviewTicketRoute shows which route a ticket from ?department in
+// ?category would take (admin only)
+func (h *OrgHandler) PreviewTicketRoute(c *gin.Context) {
+	route, err := h.org.MatchRoute(context.Background(), c.Query("department"), models.TicketCategory(c.Query("category")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to match ticket routes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"route": route})
+}
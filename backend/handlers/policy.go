@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type PolicyHandler struct {
+	policies *services.PolicyService
+	routes   gin.RoutesInfo
+}
+
+func NewPolicyHandler(policies *services.PolicyService) *PolicyHandler {
+	return &PolicyHandler{policies: policies}
+}
+
+// SetRoutes gives the simulation the registered routes to resolve request
+// paths with. It is called once the router is set up.
+func (h *PolicyHandler) SetRoutes(routes gin.RoutesInfo) {
+	h.routes = routes
+}
+
+// ListPolicyRules returns every authorization rule (admin only)
+func (h *PolicyHandler) ListPolicyRules(c *gin.Context) {
+	rules, err := h.policies.List(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch policy rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// UpdatePolicyRule creates or replaces the rule in :id (admin only)
+func (h *PolicyHandler) UpdatePolicyRule(c *gin.Context) {
+	var req models.PolicyRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := services.ValidatePolicyRule(c.Param("id"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	rule, err = h.policies.Save(context.Background(), rule, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update policy rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeletePolicyRule removes a rule; built-in rules return to their default
+// (admin only)
+func (h *PolicyHandler) DeletePolicyRule(c *gin.Context) {
+	if err := h.policies.Delete(context.Background(), c.Param("id")); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Policy rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete policy rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy rule deleted successfully"})
+}
+
+// SimulatePolicy explains how the rules decide a request by a user or a
+// role, and which rules matched it, without making the request (admin only)
+func (h *PolicyHandler) SimulatePolicy(c *gin.Context) {
+	var req models.PolicySimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, ok := h.resolveRoute(strings.ToUpper(req.Method), req.Path)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No route matches " + strings.ToUpper(req.Method) + " " + req.Path})
+		return
+	}
+
+	simulation, err := h.policies.Simulate(context.Background(), req, route)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, simulation)
+}
+
+// resolveRoute finds the registered route a request path is served by,
+// preferring static segments over parameters as the router does.
+func (h *PolicyHandler) resolveRoute(method, path string) (string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	best, bestStatic := "", -1
+	for _, route := range h.routes {
+		if route.Method != method {
+			continue
+		}
+		if route.Path == path {
+			return route.Path, true
+		}
+		if static, ok := routeMatches(strings.Split(strings.Trim(route.Path, "/"), "/"), segments); ok && static > bestStatic {
+			best, bestStatic = route.Path, static
+		}
+	}
+	return best, bestStatic >= 0
+}
+
+// routeMatches matches path segments against a route's segments and
+// returns how many of them were static.
+func routeMatches(route, path []string) (int, bool) {
+	static := 0
+	for i, seg := range route {
+		if strings.HasPrefix(seg, "*") {
+			return static, true
+		}
+		if i >= len(path) {
+			return 0, false
+		}
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			if path[i] == "" {
+				return 0, false
+			}
+		case seg == path[i]:
+			static++
+		default:
+			return 0, false
+		}
+	}
+	return static, len(route) == len(path)
+}
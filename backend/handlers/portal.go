@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+// PortalHandler serves the requester portal. Every route is scoped to the
+// requester's own tickets and returns the sanitized PortalTicket view.
+type PortalHandler struct {
+	db         *database.MongoDB
+	taxonomy   *services.TaxonomyService
+	affinity   *services.AffinityService
+	licenses   *services.LicenseService
+	comments   *services.CommentService
+	kb         *services.KBService
+	deflection *services.DeflectionService
+	org        *services.OrgService
+	assignment *services.AssignmentService
+}
+
+func NewPortalHandler(db *database.MongoDB, taxonomy *services.TaxonomyService, affinity *services.AffinityService, licenses *services.LicenseService, comments *services.CommentService, kb *services.KBService, deflection *services.DeflectionService, org *services.OrgService, assignment *services.AssignmentService) *PortalHandler {
+	return &PortalHandler{db: db, taxonomy: taxonomy, affinity: affinity, licenses: licenses, comments: comments, kb: kb, deflection: deflection, org: org, assignment: assignment}
+}
+
+func (h *PortalHandler) ListMyTickets(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	filter := bson.M{"createdBy": user.ID}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	cursor, err := h.db.GetCollection("tickets").Find(context.Background(), filter,
+		options.Find().SetSort(bson.D{{"createdAt", -1}}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tickets"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var tickets []models.Ticket
+	if err := cursor.All(context.Background(), &tickets); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode tickets"})
+		return
+	}
+
+	taxonomy := h.taxonomy.Get(context.Background())
+	now := time.Now()
+	views := make([]models.PortalTicket, 0, len(tickets))
+	for _, t := range tickets {
+		services.FillDueDate(taxonomy, &t, now)
+		views = append(views, models.NewPortalTicket(t))
+	}
+	c.JSON(http.StatusOK, gin.H{"tickets": views})
+}
+
+// CreateMyTicket raises a ticket. Requesters may suggest a category but
+// priority is left to triage.
+func (h *PortalHandler) CreateMyTicket(c *gin.Context) {
+	var req models.PortalTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.taxonomy.ValidateTicketFields(context.Background(), req.Category, "", nil, ""); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	taxonomy := h.taxonomy.Get(context.Background())
+	if req.Category == "" {
+		req.Category = services.DefaultCategory(taxonomy)
+	}
+
+	ticket := models.Ticket{
+		ID:          primitive.NewObjectID(),
+		Title:       req.Title,
+		Description: req.Description,
+		Category:    req.Category,
+		Priority:    services.DefaultPriority(taxonomy),
+		Status:      models.StatusOpen,
+		CreatedBy:   user.ID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := h.org.RouteTicket(context.Background(), &ticket, user); err != nil {
+		log.Printf("Failed to route ticket %s: %v", ticket.ID.Hex(), err)
+	}
+	if err := h.assignment.Assign(context.Background(), &ticket); err != nil {
+		log.Printf("Failed to auto-assign ticket %s: %v", ticket.ID.Hex(), err)
+	}
+	if _, err := h.db.GetCollection("tickets").InsertOne(context.Background(), ticket); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create ticket"})
+		return
+	}
+
+	if preflightID, err := primitive.ObjectIDFromHex(req.PreflightID); err == nil {
+		if err := h.deflection.Proceeded(context.Background(), preflightID, user.ID, ticket.ID); err != nil {
+			log.Printf("Failed to record preflight %s outcome: %v", req.PreflightID, err)
+		}
+	}
+
+	if _, err := h.affinity.LinkResources(context.Background(), ticket); err != nil {
+		log.Printf("Failed to link monitored resources to ticket %s: %v", ticket.ID.Hex(), err)
+	}
+	if _, err := h.licenses.LinkTicket(context.Background(), ticket); err != nil {
+		log.Printf("Failed to link license to ticket %s: %v", ticket.ID.Hex(), err)
+	}
+
+	services.FillDueDate(taxonomy, &ticket, time.Now())
+	view := models.NewPortalTicket(ticket)
+	// Public articles that may solve the issue while the requester waits
+	suggestions, err := h.kb.Suggest(context.Background(), ticket.Title+"\n"+ticket.Description, kbSuggestionLimit, true)
+	if err != nil {
+		log.Printf("Failed to suggest articles for ticket %s: %v", ticket.ID.Hex(), err)
+	}
+	view.SuggestedArticles = suggestions
+	c.JSON(http.StatusCreated, view)
+}
+
+func (h *PortalHandler) GetMyTicket(c *gin.Context) {
+	ticket := c.MustGet("ticket").(models.Ticket)
+
+	comments, err := h.comments.List(context.Background(), ticket.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		return
+	}
+
+	services.FillDueDate(h.taxonomy.Get(context.Background()), &ticket, time.Now())
+	view := models.NewPortalTicket(ticket)
+	view.Comments = comments
+	c.JSON(http.StatusOK, view)
+}
+
+func (h *PortalHandler) AddMyComment(c *gin.Context) {
+	var req models.PortalCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateComment(req.Body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticket := c.MustGet("ticket").(models.Ticket)
+	user := c.MustGet("user").(models.User)
+
+	comment, err := h.comments.Add(context.Background(), ticket.ID, user, strings.TrimSpace(req.Body), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add comment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// RateMyTicket records the requester's satisfaction once the ticket is
+// resolved or closed. A later rating replaces the earlier one.
+func (h *PortalHandler) RateMyTicket(c *gin.Context) {
+	var req models.RateTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticket := c.MustGet("ticket").(models.Ticket)
+	if ticket.Status != models.StatusResolved && ticket.Status != models.StatusClosed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only resolved or closed tickets can be rated"})
+		return
+	}
+
+	rating := models.ResolutionRating{Score: req.Score, Comment: req.Comment, RatedAt: time.Now()}
+	if _, err := h.db.GetCollection("tickets").UpdateByID(context.Background(), ticket.ID,
+		bson.M{"$set": bson.M{"rating": rating}}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rate ticket"})
+		return
+	}
+
+	ticket.Rating = &rating
+	c.JSON(http.StatusOK, models.NewPortalTicket(ticket))
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type PostmortemHandler struct {
+	postmortems *services.PostmortemService
+}
+
+func NewPostmortemHandler(postmortems *services.PostmortemService) *PostmortemHandler {
+	return &PostmortemHandler{postmortems: postmortems}
+}
+
+// DraftPostmortem (re)drafts the postmortem of an incident, optionally with
+// a specific template
+func (h *PostmortemHandler) DraftPostmortem(c *gin.Context) {
+	incidentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return
+	}
+
+	var req models.DraftPostmortemRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	var templateID *primitive.ObjectID
+	if req.TemplateID != "" {
+		id, err := primitive.ObjectIDFromHex(req.TemplateID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+			return
+		}
+		templateID = &id
+	}
+	ctx, ok := generationContext(c)
+	if !ok {
+		return
+	}
+
+	pm, err := h.postmortems.Draft(ctx, incidentID, templateID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Incident or template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to draft postmortem"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pm)
+}
+
+func (h *PostmortemHandler) ListPostmortems(c *gin.Context) {
+	postmortems, err := h.postmortems.List(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch postmortems"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"postmortems": postmortems})
+}
+
+func (h *PostmortemHandler) GetPostmortem(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid postmortem ID"})
+		return
+	}
+
+	pm, err := h.postmortems.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Postmortem not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch postmortem"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pm)
+}
+
+func (h *PostmortemHandler) UpdatePostmortem(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid postmortem ID"})
+		return
+	}
+
+	var req models.UpdatePostmortemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	switch req.Status {
+	case "", models.PostmortemDraft, models.PostmortemInReview, models.PostmortemPublished:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid postmortem status"})
+		return
+	}
+
+	pm, err := h.postmortems.Update(context.Background(), objectID, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Postmortem not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update postmortem"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pm)
+}
+
+// AddActionItem opens a tracked ticket for a postmortem follow-up
+func (h *PostmortemHandler) AddActionItem(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid postmortem ID"})
+		return
+	}
+
+	var req models.CreateActionItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ownerID, err := primitive.ObjectIDFromHex(req.OwnerID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid owner ID"})
+		return
+	}
+	if err := h.postmortems.ValidateActionItem(context.Background(), req, ownerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	item, err := h.postmortems.AddActionItem(context.Background(), objectID, req, ownerID, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Postmortem not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create action item"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *PostmortemHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.postmortems.ListTemplates(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch postmortem templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"builtin":   services.DefaultPostmortemTemplate(),
+	})
+}
+
+func (h *PostmortemHandler) CreateTemplate(c *gin.Context) {
+	var req models.PostmortemTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidatePostmortemTemplate(req.Sections); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.postmortems.CreateTemplate(context.Background(), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create postmortem template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+func (h *PostmortemHandler) UpdateTemplate(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	var req models.PostmortemTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidatePostmortemTemplate(req.Sections); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.postmortems.UpdateTemplate(context.Background(), objectID, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update postmortem template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (h *PostmortemHandler) DeleteTemplate(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	if err := h.postmortems.DeleteTemplate(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete postmortem template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
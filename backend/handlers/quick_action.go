@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/services"
+)
+
+// QuickActionHandler serves the one-click links in notification emails.
+// Opening a link only shows a confirmation page; the action runs on POST so
+// mail scanners that prefetch links cannot trigger it.
+type QuickActionHandler struct {
+	actions *services.QuickActionService
+}
+
+func NewQuickActionHandler(actions *services.QuickActionService) *QuickActionHandler {
+	return &QuickActionHandler{actions: actions}
+}
+
+func actionPage(c *gin.Context, status int, title, body string) {
+	page := fmt.Sprintf(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex"><title>%s</title>
+<style>body{font-family:sans-serif;max-width:32rem;margin:3rem auto;padding:0 1rem}button{font-size:1rem;padding:.6rem 1.2rem}</style>
+</head><body><h2>%s</h2>%s</body></html>`, html.EscapeString(title), html.EscapeString(title), body)
+	c.Header("Cache-Control", "no-store")
+	c.Data(status, "text/html; charset=utf-8", []byte(page))
+}
+
+func actionError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrQuickActionInvalid:
+		actionPage(c, http.StatusNotFound, "Link not valid", "<p>This action link is not valid.</p>")
+	case services.ErrQuickActionExpired:
+		actionPage(c, http.StatusGone, "Link expired", "<p>"+html.EscapeString(err.Error())+". Please open the ticket in IntelliOps.</p>")
+	case services.ErrQuickActionUsed:
+		actionPage(c, http.StatusConflict, "Link already used", "<p>"+html.EscapeString(err.Error())+".</p>")
+	case services.ErrQuickActionClosed:
+		actionPage(c, http.StatusConflict, "Nothing to do", "<p>"+html.EscapeString(err.Error())+".</p>")
+	case services.ErrQuickActionClaimed:
+		actionPage(c, http.StatusConflict, "Already claimed", "<p>"+html.EscapeString(err.Error())+".</p>")
+	case mongo.ErrNoDocuments:
+		actionPage(c, http.StatusNotFound, "Ticket not found", "<p>The ticket no longer exists.</p>")
+	default:
+		actionPage(c, http.StatusInternalServerError, "Action failed", "<p>The action could not be completed. Please try again later.</p>")
+	}
+}
+
+// ConfirmQuickAction shows what the link will do with a button to confirm
+func (h *QuickActionHandler) ConfirmQuickAction(c *gin.Context) {
+	action, err := h.actions.Verify(context.Background(), c.Param("token"))
+	if err != nil {
+		actionError(c, err)
+		return
+	}
+
+	form := fmt.Sprintf(`<p>Ticket %s</p><form method="post"><button type="submit">%s</button></form>`,
+		html.EscapeString(action.TicketID.Hex()), html.EscapeString(action.Label))
+	actionPage(c, http.StatusOK, action.Label+"?", form)
+}
+
+// RunQuickAction performs the action the link was signed for
+func (h *QuickActionHandler) RunQuickAction(c *gin.Context) {
+	action, err := h.actions.Verify(context.Background(), c.Param("token"))
+	if err != nil {
+		actionError(c, err)
+		return
+	}
+
+	ticket, err := h.actions.Execute(context.Background(), action)
+	if err != nil {
+		actionError(c, err)
+		return
+	}
+
+	actionPage(c, http.StatusOK, "Done", fmt.Sprintf("<p>%s: <strong>%s</strong> (status %s).</p>",
+		html.EscapeString(action.Label), html.EscapeString(ticket.Title), html.EscapeString(string(ticket.Status))))
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type ReminderHandler struct {
+	reminders *services.ReminderService
+}
+
+func NewReminderHandler(reminders *services.ReminderService) *ReminderHandler {
+	return &ReminderHandler{reminders: reminders}
+}
+
+// CreateReminder sets a follow-up reminder on the ticket for the current
+// user
+func (h *ReminderHandler) CreateReminder(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.CreateReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateReminder(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	reminder, err := h.reminders.Create(context.Background(), ticketID, user, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reminder"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, reminder)
+}
+
+// ListReminders returns every reminder on the ticket, soonest first
+func (h *ReminderHandler) ListReminders(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	reminders, err := h.reminders.List(context.Background(), ticketID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reminders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reminders": reminders})
+}
+
+// UpdateReminder reschedules, edits or completes one of the current user's
+// reminders
+func (h *ReminderHandler) UpdateReminder(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	reminderID, err := primitive.ObjectIDFromHex(c.Param("reminderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reminder ID"})
+		return
+	}
+
+	var req models.UpdateReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateReminderUpdate(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	reminder, err := h.reminders.Update(context.Background(), ticketID, reminderID, user, req)
+	if err != nil {
+		h.reminderError(c, err, "Failed to update reminder")
+		return
+	}
+
+	c.JSON(http.StatusOK, reminder)
+}
+
+// DeleteReminder removes one of the current user's reminders
+func (h *ReminderHandler) DeleteReminder(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	reminderID, err := primitive.ObjectIDFromHex(c.Param("reminderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reminder ID"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	if err := h.reminders.Delete(context.Background(), ticketID, reminderID, user); err != nil {
+		h.reminderError(c, err, "Failed to delete reminder")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reminder deleted successfully"})
+}
+
+func (h *ReminderHandler) reminderError(c *gin.Context, err error, msg string) {
+	switch err {
+	case mongo.ErrNoDocuments:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reminder not found"})
+	case services.ErrReminderNotOwned:
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only change your own reminders"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
+
+// GetAgenda returns the current user's overdue follow-ups and those due in
+// the next ?days (default 7) days
+func (h *ReminderHandler) GetAgenda(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days < 1 || days > 90 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	agenda, err := h.reminders.Agenda(context.Background(), user.ID, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch agenda"})
+		return
+	}
+
+	c.JSON(http.StatusOK, agenda)
+}
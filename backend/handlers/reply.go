@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type ReplyHandler struct {
+	db      *database.MongoDB
+	replies *services.ReplyService
+}
+
+func NewReplyHandler(db *database.MongoDB, replies *services.ReplyService) *ReplyHandler {
+	return &ReplyHandler{db: db, replies: replies}
+}
+
+// DraftReply drafts a reply to the requester from the ticket context, an
+// optional chosen solution and the requested tone
+func (h *ReplyHandler) DraftReply(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.DraftReplyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Tone != "" && !services.ValidTone(req.Tone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tone must be formal or friendly"})
+		return
+	}
+	ctx, ok := generationContext(c)
+	if !ok {
+		return
+	}
+
+	var ticket models.Ticket
+	err = h.db.GetCollection("tickets").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&ticket)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	draft, err := h.replies.Draft(ctx, ticket, req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store reply draft"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, draft)
+}
+
+func (h *ReplyHandler) ListReplyDrafts(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	drafts, err := h.replies.ListDrafts(context.Background(), objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reply drafts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"drafts": drafts})
+}
+
+// AcceptReplyDraft records the (possibly edited) text the technician sends
+func (h *ReplyHandler) AcceptReplyDraft(c *gin.Context) {
+	var req models.AcceptReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.resolve(c, models.ReplyDraftAccepted, req.Text)
+}
+
+func (h *ReplyHandler) DiscardReplyDraft(c *gin.Context) {
+	h.resolve(c, models.ReplyDraftDiscarded, "")
+}
+
+func (h *ReplyHandler) resolve(c *gin.Context, status models.ReplyDraftStatus, text string) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	draftID, err := primitive.ObjectIDFromHex(c.Param("draftId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft ID"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	draft, err := h.replies.Resolve(context.Background(), ticketID, draftID, status, text, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Reply draft not found"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+// GetReplyStats returns acceptance and edit rates of reply drafts per tone
+func (h *ReplyHandler) GetReplyStats(c *gin.Context) {
+	stats, err := h.replies.Stats(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute reply statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tones": stats})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type ServiceNowHandler struct {
+	servicenow *services.ServiceNowService
+}
+
+func NewServiceNowHandler(servicenow *services.ServiceNowService) *ServiceNowHandler {
+	return &ServiceNowHandler{servicenow: servicenow}
+}
+
+// serviceNowTicketIDs binds the tickets a request selects, responding
+// with 400 when there are none, too many or an invalid ID.
+func serviceNowTicketIDs(c *gin.Context) ([]primitive.ObjectID, bool) {
+	var req models.ServiceNowTicketsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if len(req.TicketIDs) == 0 || len(req.TicketIDs) > services.MaxServiceNowTickets {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Select 1-%d tickets", services.MaxServiceNowTickets)})
+		return nil, false
+	}
+	ids := make([]primitive.ObjectID, 0, len(req.TicketIDs))
+	for _, hex := range req.TicketIDs {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID " + hex})
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// ExportServiceNowIncidents returns the selected tickets as ServiceNow
+// incident records, in the JSONv2 format import sets load
+func (h *ServiceNowHandler) ExportServiceNowIncidents(c *gin.Context) {
+	ids, ok := serviceNowTicketIDs(c)
+	if !ok {
+		return
+	}
+
+	tickets, err := h.servicenow.Find(context.Background(), ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tickets"})
+		return
+	}
+
+	records := make([]map[string]string, len(tickets))
+	for i, ticket := range tickets {
+		records[i] = services.ServiceNowIncident(ticket)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"records": records})
+}
+
+// PushServiceNowIncidents creates or updates a ServiceNow incident for each
+// selected ticket and links the ticket to it
+func (h *ServiceNowHandler) PushServiceNowIncidents(c *gin.Context) {
+	if !h.servicenow.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "ServiceNow is not configured"})
+		return
+	}
+
+	ids, ok := serviceNowTicketIDs(c)
+	if !ok {
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	result, err := h.servicenow.Push(context.Background(), ids, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to push tickets to ServiceNow"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SyncServiceNow pulls the state of linked incidents now instead of waiting
+// for the next scheduled pull (admin only)
+func (h *ServiceNowHandler) SyncServiceNow(c *gin.Context) {
+	if !h.servicenow.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "ServiceNow is not configured"})
+		return
+	}
+
+	result, err := h.servicenow.Sync(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync with ServiceNow"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type SettingsHandler struct {
+	settings *services.SettingsService
+}
+
+func NewSettingsHandler(settings *services.SettingsService) *SettingsHandler {
+	return &SettingsHandler{settings: settings}
+}
+
+// GetSettings returns the runtime settings without secrets (admin only)
+func (h *SettingsHandler) GetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, h.settings.Get())
+}
+
+// UpdateSettings replaces the runtime settings and applies them without a
+// restart (admin only)
+func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
+	var req models.UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.settings.Validate(context.Background(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	saved, err := h.settings.Save(context.Background(), settings, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Settings updated successfully",
+		"settings": saved,
+	})
+}
+
+// GetSettingsAudit returns the latest ?limit (default 100) settings changes
+// with who made them (admin only)
+func (h *SettingsHandler) GetSettingsAudit(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+
+	entries, err := h.settings.Audit(context.Background(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch settings audit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
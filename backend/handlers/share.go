@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type ShareHandler struct {
+	shares *services.ShareService
+}
+
+func NewShareHandler(shares *services.ShareService) *ShareHandler {
+	return &ShareHandler{shares: shares}
+}
+
+func shareAccess(c *gin.Context) services.ShareAccess {
+	access := services.ShareAccess{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	if user, ok := c.Get("user"); ok {
+		id := user.(models.User).ID
+		access.UserID = &id
+	}
+	return access
+}
+
+// CreateShareLink issues a signed, time-limited read-only link to a ticket
+func (h *ShareHandler) CreateShareLink(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.CreateShareLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	ttl, err := h.shares.ParseShareTTL(req.ExpiresIn)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, token, err := h.shares.Create(context.Background(), ticketID, ttl, req.Note, shareAccess(c))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"link":  link,
+		"token": token,
+		"path":  "/api/public/tickets/" + token,
+	})
+}
+
+func (h *ShareHandler) ListShareLinks(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	links, err := h.shares.List(context.Background(), ticketID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch share links"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+func (h *ShareHandler) RevokeShareLink(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	linkID, err := primitive.ObjectIDFromHex(c.Param("linkId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share link ID"})
+		return
+	}
+
+	link, err := h.shares.Revoke(context.Background(), ticketID, linkID, shareAccess(c))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke share link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// GetShareLinkAudit lists who created, viewed and revoked a link
+func (h *ShareHandler) GetShareLinkAudit(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	linkID, err := primitive.ObjectIDFromHex(c.Param("linkId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share link ID"})
+		return
+	}
+
+	entries, err := h.shares.Audit(context.Background(), ticketID, linkID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch share link audit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+// GetSharedTicket serves a shared ticket without authentication
+func (h *ShareHandler) GetSharedTicket(c *gin.Context) {
+	ticket, err := h.shares.View(context.Background(), c.Param("token"), shareAccess(c))
+	if err != nil {
+		switch err {
+		case services.ErrShareLinkInvalid:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		case services.ErrShareLinkExpired:
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shared ticket"})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, ticket)
+}
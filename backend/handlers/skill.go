@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type SkillHandler struct {
+	skills *services.SkillService
+}
+
+func NewSkillHandler(skills *services.SkillService) *SkillHandler {
+	return &SkillHandler{skills: skills}
+}
+
+func (h *SkillHandler) ListSkillProfiles(c *gin.Context) {
+	profiles, err := h.skills.List(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill profiles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"profiles": profiles})
+}
+
+func (h *SkillHandler) GetSkillProfile(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid technician ID"})
+		return
+	}
+
+	profile, err := h.skills.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Skill profile not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// RankTechnicians orders technicians by skill for ?category=
+func (h *SkillHandler) RankTechnicians(c *gin.Context) {
+	category := c.Query("category")
+	if category == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category is required"})
+		return
+	}
+
+	ranked, err := h.skills.RankTechnicians(context.Background(), models.TicketCategory(category))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rank technicians"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"category": category, "technicians": ranked})
+}
+
+// RecomputeSkills rebuilds all profiles from resolution history now
+func (h *SkillHandler) RecomputeSkills(c *gin.Context) {
+	if err := h.skills.Recompute(context.Background()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute skill profiles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Skill profiles recomputed"})
+}
+
+// SetSkillOverride pins a score for a category or excludes the technician
+// from it
+func (h *SkillHandler) SetSkillOverride(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid technician ID"})
+		return
+	}
+
+	var req models.SetSkillOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	profile, err := h.skills.SetOverride(context.Background(), objectID, req, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Technician not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// RemoveSkillOverride restores the inferred score for ?category=
+func (h *SkillHandler) RemoveSkillOverride(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid technician ID"})
+		return
+	}
+	category := c.Query("category")
+	if category == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category is required"})
+		return
+	}
+
+	profile, err := h.skills.RemoveOverride(context.Background(), objectID, models.TicketCategory(category))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Technician not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update skill profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
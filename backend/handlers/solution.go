@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type SolutionHandler struct {
+	db        *database.MongoDB
+	solutions *services.SolutionService
+	summaries *services.SummaryService
+}
+
+func NewSolutionHandler(db *database.MongoDB, solutions *services.SolutionService, summaries *services.SummaryService) *SolutionHandler {
+	return &SolutionHandler{db: db, solutions: solutions, summaries: summaries}
+}
+
+// RefineSolutions regenerates a ticket's solutions with the technician's
+// feedback (e.g. "the user is on macOS, not Windows") and stores the result
+// as a new version
+func (h *SolutionHandler) RefineSolutions(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.RefineSolutionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Feedback) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Feedback is required"})
+		return
+	}
+	ctx, ok := generationContext(c)
+	if !ok {
+		return
+	}
+
+	var ticket models.Ticket
+	err = h.db.GetCollection("tickets").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&ticket)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	set, err := h.solutions.Refine(ctx, ticket, req, user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Solution set not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refine solutions"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, set)
+}
+
+// GetSolutionHistory returns every version of a ticket's solutions, oldest
+// first
+func (h *SolutionHandler) GetSolutionHistory(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	sets, err := h.solutions.History(context.Background(), objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch solution history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"versions": sets})
+}
+
+// UpdateSolutionStep marks a suggested solution step as attempted, succeeded
+// or failed on the ticket
+func (h *SolutionHandler) UpdateSolutionStep(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.UpdateSolutionStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !services.ValidStepStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status must be attempted, succeeded or failed"})
+		return
+	}
+	if req.StepIndex < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Step index must not be negative"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	steps, err := h.solutions.RecordStep(context.Background(), objectID, req, user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update solution step"})
+		return
+	}
+	h.summaries.RefreshIfPresent(objectID)
+
+	c.JSON(http.StatusOK, gin.H{"solutionSteps": steps})
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type SyntheticHandler struct {
+	checks *services.SyntheticService
+}
+
+func NewSyntheticHandler(checks *services.SyntheticService) *SyntheticHandler {
+	return &SyntheticHandler{checks: checks}
+}
+
+func (h *SyntheticHandler) CreateCheck(c *gin.Context) {
+	var req models.SyntheticCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateSyntheticCheck(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	check, err := h.checks.Create(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create check"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, check)
+}
+
+// ListChecks lists checks, filtered by ?tag=key:value.
+func (h *SyntheticHandler) ListChecks(c *gin.Context) {
+	tags, err := services.ParseTagFilters(c.QueryArray("tag"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	checks, err := h.checks.List(context.Background(), tags)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch checks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"checks": checks})
+}
+
+func (h *SyntheticHandler) GetCheck(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check ID"})
+		return
+	}
+
+	check, err := h.checks.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Check not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch check"})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+func (h *SyntheticHandler) UpdateCheck(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check ID"})
+		return
+	}
+
+	var req models.SyntheticCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateSyntheticCheck(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	check, err := h.checks.Update(context.Background(), objectID, req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Check not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update check"})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+func (h *SyntheticHandler) DeleteCheck(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check ID"})
+		return
+	}
+
+	if err := h.checks.Delete(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Check not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete check"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Check deleted successfully"})
+}
+
+// RunCheck probes a check from the backend now and records the result.
+func (h *SyntheticHandler) RunCheck(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check ID"})
+		return
+	}
+
+	check, err := h.checks.Get(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Check not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch check"})
+		return
+	}
+
+	check.Region = ""
+	result := services.RunCheck(context.Background(), check)
+	if err := h.checks.Record(context.Background(), check, result); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record check result"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCheckResults returns the latest ?limit (default 100) results of a
+// check with its uptime and response times.
+func (h *SyntheticHandler) GetCheckResults(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check ID"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+
+	history, err := h.checks.History(context.Background(), objectID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch check results"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// WorkerChecks returns the checks due in the worker's ?region.
+func (h *SyntheticHandler) WorkerChecks(c *gin.Context) {
+	region := c.Query("region")
+	if region == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "region is required"})
+		return
+	}
+
+	checks, err := h.checks.Due(context.Background(), region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch checks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"checks": checks})
+}
+
+// ReportResults records the results a regional worker collected.
+func (h *SyntheticHandler) ReportResults(c *gin.Context) {
+	var report models.CheckResultReport
+	if err := c.ShouldBindJSON(&report); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recorded, err := h.checks.Report(context.Background(), report)
+	if err != nil {
+		switch err {
+		case mongo.ErrNoDocuments:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Check not found", "recorded": recorded})
+		case services.ErrCheckRegionMismatch:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "recorded": recorded})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record check results", "recorded": recorded})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recorded": recorded})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type TaxonomyHandler struct {
+	taxonomy *services.TaxonomyService
+}
+
+func NewTaxonomyHandler(taxonomy *services.TaxonomyService) *TaxonomyHandler {
+	return &TaxonomyHandler{taxonomy: taxonomy}
+}
+
+// GetTaxonomy returns the categories and priorities tickets may use
+func (h *TaxonomyHandler) GetTaxonomy(c *gin.Context) {
+	c.JSON(http.StatusOK, h.taxonomy.Get(context.Background()))
+}
+
+// UpdateTaxonomy replaces the organization's taxonomy (admin only)
+func (h *TaxonomyHandler) UpdateTaxonomy(c *gin.Context) {
+	var req models.UpdateTaxonomyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.ValidateTaxonomy(models.Taxonomy{Categories: req.Categories, Priorities: req.Priorities}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	taxonomy, err := h.taxonomy.Save(context.Background(), req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update taxonomy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Taxonomy updated successfully",
+		"taxonomy": taxonomy,
+	})
+}
+
+// ResetTaxonomy restores the built-in categories and priorities (admin only)
+func (h *TaxonomyHandler) ResetTaxonomy(c *gin.Context) {
+	defaults := services.DefaultTaxonomy()
+	user := c.MustGet("user").(models.User)
+
+	taxonomy, err := h.taxonomy.Save(context.Background(), models.UpdateTaxonomyRequest{
+		Categories: defaults.Categories,
+		Priorities: defaults.Priorities,
+	}, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset taxonomy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Taxonomy reset to defaults",
+		"taxonomy": taxonomy,
+	})
+}
@@ -2,8 +2,14 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,38 +20,156 @@ import (
 
 	"intelliops-ai-copilot/database"
 	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
 )
 
+// Comments included in the ticket detail response, the first page of the
+// thread.
+const ticketDetailComments = 20
+
 type TicketHandler struct {
-	db *database.MongoDB
+	db          *database.MongoDB
+	taxonomy    *services.TaxonomyService
+	experiments *services.ExperimentService
+	summaries   *services.SummaryService
+	affinity    *services.AffinityService
+	push        *services.PushService
+	actions     *services.QuickActionService
+	licenses    *services.LicenseService
+	deflection  *services.DeflectionService
+	archive     *services.TicketArchiveService
+	comments    *services.CommentService
+	attachments *services.AttachmentService
+	history     *services.TicketHistoryService
+	org         *services.OrgService
+	assignment  *services.AssignmentService
+	imports     *services.TicketImportService
+	triage      *services.TriageService
+	fields      *services.CustomFieldService
+	agents      *services.EndpointAgentService
+	messaging   *services.MessagingService
+	// autoTriage applies AI triage to tickets as they are created
+	autoTriage bool
+	// Largest attachment upload in bytes
+	maxAttachmentSize int64
 }
 
-func NewTicketHandler(db *database.MongoDB) *TicketHandler {
-	return &TicketHandler{db: db}
+func NewTicketHandler(db *database.MongoDB, taxonomy *services.TaxonomyService, experiments *services.ExperimentService, summaries *services.SummaryService, affinity *services.AffinityService, push *services.PushService, actions *services.QuickActionService, licenses *services.LicenseService, deflection *services.DeflectionService, archive *services.TicketArchiveService, comments *services.CommentService, attachments *services.AttachmentService, history *services.TicketHistoryService, org *services.OrgService, assignment *services.AssignmentService, imports *services.TicketImportService, triage *services.TriageService, fields *services.CustomFieldService, agents *services.EndpointAgentService, messaging *services.MessagingService, autoTriage bool, maxAttachmentSize int64) *TicketHandler {
+	return &TicketHandler{db: db, taxonomy: taxonomy, experiments: experiments, summaries: summaries, affinity: affinity, push: push, actions: actions, licenses: licenses, deflection: deflection, archive: archive, comments: comments, attachments: attachments, history: history, org: org, assignment: assignment, imports: imports, triage: triage, fields: fields, agents: agents, messaging: messaging, autoTriage: autoTriage, maxAttachmentSize: maxAttachmentSize}
 }
 
+// GetTickets lists tickets, newest first. status takes several
+// comma-separated statuses, createdAfter and createdBefore (RFC 3339) bound
+// the creation time and q matches words in the title or description; all
+// filters must hold.
 func (h *TicketHandler) GetTickets(c *gin.Context) {
 	// Get query parameters
 	status := c.Query("status")
 	priority := c.Query("priority")
+	category := c.Query("category")
+	subcategory := c.Query("subcategory")
 	assignedTo := c.Query("assignedTo")
+	createdBy := c.Query("createdBy")
+	q := strings.TrimSpace(c.Query("q"))
 	page := c.DefaultQuery("page", "1")
 	limit := c.DefaultQuery("limit", "10")
 
-	// Build filter
+	// Build filter. Conditions that need their own $or go in and, so they
+	// don't overwrite each other.
 	filter := bson.M{}
-	if status != "" {
-		filter["status"] = status
+	and := bson.A{}
+	if statuses := splitQueryList(status); len(statuses) == 1 {
+		filter["status"] = statuses[0]
+	} else if len(statuses) > 1 {
+		filter["status"] = bson.M{"$in": statuses}
 	}
 	if priority != "" {
 		filter["priority"] = priority
 	}
+	if category != "" {
+		// Match tickets where the category is either the primary or one of the
+		// secondary labels; primaryOnly=true restores single-label matching.
+		if c.Query("primaryOnly") == "true" {
+			filter["category"] = category
+		} else {
+			and = append(and, bson.M{"$or": bson.A{
+				bson.M{"category": category},
+				bson.M{"secondaryCategories": category},
+			}})
+		}
+	}
+	if subcategory != "" {
+		filter["subcategory"] = subcategory
+	}
+	switch ticketType := c.Query("type"); models.TicketType(ticketType) {
+	case "":
+	case models.TicketTypeIncident:
+		filter["type"] = bson.M{"$in": bson.A{nil, models.TicketTypeIncident}}
+	default:
+		filter["type"] = ticketType
+	}
 	if assignedTo != "" {
 		assignedToID, err := primitive.ObjectIDFromHex(assignedTo)
 		if err == nil {
 			filter["assignedTo"] = assignedToID
 		}
 	}
+	if createdBy != "" {
+		createdByID, err := primitive.ObjectIDFromHex(createdBy)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid createdBy ID"})
+			return
+		}
+		filter["createdBy"] = createdByID
+	}
+	created := bson.M{}
+	for param, op := range map[string]string{"createdAfter": "$gte", "createdBefore": "$lt"} {
+		if v := c.Query(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 time"})
+				return
+			}
+			created[op] = t
+		}
+	}
+	if len(created) > 0 {
+		filter["createdAt"] = created
+	}
+	if q != "" {
+		// Every word must appear in the title or the description
+		for _, word := range strings.Fields(q) {
+			pattern := primitive.Regex{Pattern: regexp.QuoteMeta(word), Options: "i"}
+			and = append(and, bson.M{"$or": bson.A{
+				bson.M{"title": pattern},
+				bson.M{"description": pattern},
+			}})
+		}
+	}
+	// Open and in-progress tickets past their due date, or the rest with
+	// overdue=false
+	now := time.Now()
+	taxonomy := h.taxonomy.Get(context.Background())
+	switch c.Query("overdue") {
+	case "":
+	case "true":
+		and = append(and, services.OverdueFilter(taxonomy, now))
+	case "false":
+		and = append(and, bson.M{"$nor": bson.A{services.OverdueFilter(taxonomy, now)}})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "overdue must be true or false"})
+		return
+	}
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+	if !h.orgFilter(c, filter) {
+		return
+	}
+	// Tickets carrying every ?tag
+	if tags := services.ParseTicketTagFilter(c.QueryArray("tag")); len(tags) > 0 {
+		filter["tags"] = bson.M{"$all": tags}
+	}
 
 	// Pagination
 	pageInt := 1
@@ -77,6 +201,9 @@ func (h *TicketHandler) GetTickets(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode tickets"})
 		return
 	}
+	for i := range tickets {
+		services.FillDueDate(taxonomy, &tickets[i], now)
+	}
 
 	// Get total count
 	total, err := h.db.GetCollection("tickets").CountDocuments(context.Background(), filter)
@@ -101,8 +228,8 @@ func (h *TicketHandler) GetTicket(c *gin.Context) {
 		return
 	}
 
-	var ticket models.Ticket
-	err = h.db.GetCollection("tickets").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&ticket)
+	// Archived tickets are read through from the archive
+	ticket, err := h.archive.Get(context.Background(), objectID)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
@@ -112,7 +239,133 @@ func (h *TicketHandler) GetTicket(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, ticket)
+	// The first page of the conversation thread; the rest is paged through
+	// /tickets/:id/comments
+	user := c.MustGet("user").(models.User)
+	comments, total, err := h.comments.Page(context.Background(), ticket.ID, user.Role != models.RoleRequester, 0, ticketDetailComments)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		return
+	}
+
+	services.FillDueDate(h.taxonomy.Get(context.Background()), &ticket, time.Now())
+
+	c.JSON(http.StatusOK, models.TicketDetail{Ticket: ticket, Comments: comments, CommentCount: total})
+}
+
+// splitQueryList splits a comma-separated query parameter, dropping empty
+// values.
+func splitQueryList(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// orgFilter adds the ?department, ?location and ?team filters to filter.
+// A department includes its sub-units unless ?subunits=false. It writes a
+// 500 and returns false when the hierarchy cannot be read.
+func (h *TicketHandler) orgFilter(c *gin.Context, filter bson.M) bool {
+	if department := c.Query("department"); department != "" {
+		departments := []string{department}
+		if c.Query("subunits") != "false" {
+			var err error
+			departments, err = h.org.Subtree(context.Background(), department)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch org units"})
+				return false
+			}
+		}
+		filter["department"] = bson.M{"$in": departments}
+	}
+	for _, field := range []string{"location", "team"} {
+		if v := c.Query(field); v != "" {
+			filter[field] = v
+		}
+	}
+	return true
+}
+
+// GetArchivedTickets lists archived tickets, newest first. It takes the
+// status, priority, category, assignedTo, createdBy, department, location,
+// team and tag filters of GetTickets, plus from and to (RFC 3339) bounds on the
+// creation time.
+func (h *TicketHandler) GetArchivedTickets(c *gin.Context) {
+	filter := bson.M{}
+	for _, field := range []string{"status", "priority", "category"} {
+		if v := c.Query(field); v != "" {
+			filter[field] = v
+		}
+	}
+	for _, field := range []string{"assignedTo", "createdBy"} {
+		if v := c.Query(field); v != "" {
+			id, err := primitive.ObjectIDFromHex(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + field + " ID"})
+				return
+			}
+			filter[field] = id
+		}
+	}
+	created := bson.M{}
+	for param, op := range map[string]string{"from": "$gte", "to": "$lt"} {
+		if v := c.Query(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 time"})
+				return
+			}
+			created[op] = t
+		}
+	}
+	if len(created) > 0 {
+		filter["createdAt"] = created
+	}
+	if !h.orgFilter(c, filter) {
+		return
+	}
+	// Tickets carrying every ?tag
+	if tags := services.ParseTicketTagFilter(c.QueryArray("tag")); len(tags) > 0 {
+		filter["tags"] = bson.M{"$all": tags}
+	}
+
+	pageInt := 1
+	limitInt := 10
+	if p, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && p > 0 {
+		pageInt = p
+	}
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "10")); err == nil && l > 0 && l <= 1000 {
+		limitInt = l
+	}
+
+	tickets, total, err := h.archive.List(context.Background(), filter, int64((pageInt-1)*limitInt), int64(limitInt))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch archived tickets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tickets": tickets,
+		"total":   total,
+		"page":    pageInt,
+		"limit":   limitInt,
+	})
+}
+
+// ArchiveTickets moves old resolved and closed tickets to the archive now
+// instead of waiting for the next run (admin only)
+func (h *TicketHandler) ArchiveTickets(c *gin.Context) {
+	moved, err := h.archive.Archive(context.Background())
+	if err != nil {
+		log.Printf("Ticket archive failed after %d ticket(s): %v", moved, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive tickets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"archived": moved})
 }
 
 func (h *TicketHandler) CreateTicket(c *gin.Context) {
@@ -128,38 +381,180 @@ func (h *TicketHandler) CreateTicket(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
-	
+
 	userObj := user.(models.User)
 
+	if req.Subcategory != "" && req.Category == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category is required when subcategory is set"})
+		return
+	}
+	if err := h.taxonomy.ValidateTicketFields(context.Background(), req.Category, req.Subcategory, req.SecondaryCategories, req.Priority); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.DueAt != nil && !req.DueAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dueAt must be in the future"})
+		return
+	}
+	tags, err := services.MergeTicketTags(nil, req.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Set default values
+	categoryGiven, priorityGiven := req.Category != "", req.Priority != ""
+	taxonomy := h.taxonomy.Get(context.Background())
 	if req.Category == "" {
-		req.Category = models.CategoryOther
+		req.Category = services.DefaultCategory(taxonomy)
 	}
 	if req.Priority == "" {
-		req.Priority = models.PriorityMedium
+		req.Priority = services.DefaultPriority(taxonomy)
+	}
+	customFields, err := h.fields.Apply(context.Background(), req.Category, nil, req.CustomFields, true)
+	if err != nil {
+		customFieldError(c, err)
+		return
+	}
+	var agent *models.EndpointAgent
+	if req.EndpointAgentID != "" {
+		id, err := primitive.ObjectIDFromHex(req.EndpointAgentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endpoint agent ID"})
+			return
+		}
+		found, err := h.agents.Get(context.Background(), id)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Endpoint agent not found"})
+			return
+		}
+		agent = &found
 	}
 
 	ticket := models.Ticket{
-		ID:          primitive.NewObjectID(),
-		Title:       req.Title,
-		Description: req.Description,
-		Category:    req.Category,
-		Priority:    req.Priority,
-		Status:      models.StatusOpen,
-		CreatedBy:   userObj.ID,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:                  primitive.NewObjectID(),
+		Title:               req.Title,
+		Description:         req.Description,
+		Category:            req.Category,
+		Subcategory:         req.Subcategory,
+		SecondaryCategories: services.NormalizeSecondaryCategories(req.Category, req.SecondaryCategories),
+		Priority:            req.Priority,
+		Status:              models.StatusOpen,
+		Tags:                tags,
+		CustomFields:        customFields,
+		CreatedBy:           userObj.ID,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
+		DueAt:               req.DueAt,
+	}
+	// Fields left out are taken from AI triage, before routing and the
+	// assignment rules see the ticket
+	if h.autoTriage {
+		if err := h.triage.ApplyToTicket(context.Background(), &ticket, categoryGiven, priorityGiven); err != nil {
+			log.Printf("Failed to apply triage to ticket %s: %v", ticket.ID.Hex(), err)
+		}
+	}
+	if err := h.org.RouteTicket(context.Background(), &ticket, userObj); err != nil {
+		log.Printf("Failed to route ticket %s: %v", ticket.ID.Hex(), err)
+	}
+	if err := h.assignment.Assign(context.Background(), &ticket); err != nil {
+		log.Printf("Failed to auto-assign ticket %s: %v", ticket.ID.Hex(), err)
 	}
 
-	_, err := h.db.GetCollection("tickets").InsertOne(context.Background(), ticket)
+	_, err = h.db.GetCollection("tickets").InsertOne(context.Background(), ticket)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create ticket"})
 		return
 	}
 
+	if runID, err := primitive.ObjectIDFromHex(req.TriageRunID); err == nil {
+		if err := h.experiments.RecordFeedback(context.Background(), runID, ticket.Category, ticket.Priority, userObj.ID, &ticket.ID); err != nil {
+			log.Printf("Failed to record triage feedback for run %s: %v", req.TriageRunID, err)
+		}
+	}
+	if preflightID, err := primitive.ObjectIDFromHex(req.PreflightID); err == nil {
+		if err := h.deflection.Proceeded(context.Background(), preflightID, userObj.ID, ticket.ID); err != nil {
+			log.Printf("Failed to record preflight %s outcome: %v", req.PreflightID, err)
+		}
+	}
+
+	// Link monitored resources mentioned in the ticket
+	if resources, err := h.affinity.LinkResources(context.Background(), ticket); err != nil {
+		log.Printf("Failed to link monitored resources to ticket %s: %v", ticket.ID.Hex(), err)
+	} else {
+		for _, r := range resources {
+			ticket.LinkedResourceIDs = append(ticket.LinkedResourceIDs, r.ID)
+		}
+	}
+	// After the resources, which the agent's resource is added to
+	if agent != nil {
+		if err := h.agents.LinkTicket(context.Background(), ticket.ID, &agent.ID); err != nil {
+			log.Printf("Failed to link endpoint agent to ticket %s: %v", ticket.ID.Hex(), err)
+		} else {
+			ticket.EndpointAgentID = &agent.ID
+			if agent.ResourceID != nil && !containsObjectID(ticket.LinkedResourceIDs, *agent.ResourceID) {
+				ticket.LinkedResourceIDs = append(ticket.LinkedResourceIDs, *agent.ResourceID)
+			}
+		}
+	}
+
+	// Link license expiry tickets to the license record
+	if license, err := h.licenses.LinkTicket(context.Background(), ticket); err != nil {
+		log.Printf("Failed to link license to ticket %s: %v", ticket.ID.Hex(), err)
+	} else if license != nil {
+		ticket.LicenseID = &license.ID
+	}
+
+	if ticket.Priority == models.PriorityCritical {
+		go h.actions.NotifyCritical(context.Background(), ticket)
+	}
+
+	services.FillDueDate(h.taxonomy.Get(context.Background()), &ticket, time.Now())
 	c.JSON(http.StatusCreated, ticket)
 }
 
+// maxTicketImportSize is the largest CSV file ImportTickets accepts.
+const maxTicketImportSize = 20 << 20
+
+// ImportTickets creates tickets from an uploaded CSV file (form field
+// "file") exported from another helpdesk (admin only). Rows that fail
+// validation are reported and the rest imported; ?dryRun=true only
+// validates
+func (h *TicketHandler) ImportTickets(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTicketImportSize+64<<10)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Import files must be at most %d MB", maxTicketImportSize>>20)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer content.Close()
+
+	user := c.MustGet("user").(models.User)
+
+	result, err := h.imports.Import(context.Background(), content, user, c.Query("dryRun") == "true")
+	if err != nil {
+		if errors.Is(err, services.ErrTicketImportFile) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import tickets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func (h *TicketHandler) UpdateTicket(c *gin.Context) {
 	id := c.Param("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -199,6 +594,54 @@ func (h *TicketHandler) UpdateTicket(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.Status != "" {
+		// Unknown statuses and moves the workflow forbids are both bad
+		// requests
+		if err := services.CheckStatusTransition(ticket.Status, req.Status); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// A parent is resolved and closed after its sub-tasks
+		if err := services.CheckSubtasksDone(context.Background(), h.db, ticket, req.Status); err != nil {
+			if errors.Is(err, services.ErrInvalidStatusTransition) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check sub-tasks"})
+			return
+		}
+	}
+	// Service requests and changes are only assigned and worked on once
+	// approved
+	if req.AssignedTo != nil || (req.Status != "" && req.Status != models.StatusClosed) {
+		if err := services.ServiceRequestGate(ticket); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err := services.ChangeGate(ticket); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var secondary []models.TicketCategory
+	if req.SecondaryCategories != nil {
+		secondary = *req.SecondaryCategories
+	}
+	// A subcategory is validated against the category the ticket will end up
+	// with. Changing the category without naming a subcategory clears it.
+	effectiveCategory := ticket.Category
+	if req.Category != "" {
+		effectiveCategory = req.Category
+	}
+	var subcategory string
+	if req.Subcategory != nil {
+		subcategory = *req.Subcategory
+	}
+	if err := h.taxonomy.ValidateTicketFields(context.Background(), effectiveCategory, subcategory, secondary, req.Priority); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Build update document
 	update := bson.M{"$set": bson.M{"updatedAt": time.Now()}}
@@ -211,39 +654,173 @@ func (h *TicketHandler) UpdateTicket(c *gin.Context) {
 	if req.Category != "" {
 		update["$set"].(bson.M)["category"] = req.Category
 	}
-	if req.Priority != "" {
-		update["$set"].(bson.M)["priority"] = req.Priority
+	if req.Subcategory != nil {
+		update["$set"].(bson.M)["subcategory"] = *req.Subcategory
+	} else if req.Category != "" && req.Category != ticket.Category {
+		update["$set"].(bson.M)["subcategory"] = ""
 	}
-	if req.Status != "" {
-		update["$set"].(bson.M)["status"] = req.Status
-		if req.Status == models.StatusResolved || req.Status == models.StatusClosed {
-			now := time.Now()
-			update["$set"].(bson.M)["resolvedAt"] = &now
+	if req.SecondaryCategories != nil || req.Category != "" {
+		primary := ticket.Category
+		if req.Category != "" {
+			primary = req.Category
+		}
+		secondary := ticket.SecondaryCategories
+		if req.SecondaryCategories != nil {
+			secondary = *req.SecondaryCategories
 		}
+		update["$set"].(bson.M)["secondaryCategories"] = services.NormalizeSecondaryCategories(primary, secondary)
+	}
+	if req.Priority != "" {
+		update["$set"].(bson.M)["priority"] = req.Priority
 	}
 	if req.AssignedTo != nil {
 		update["$set"].(bson.M)["assignedTo"] = req.AssignedTo
 	}
+	if req.Department != nil {
+		update["$set"].(bson.M)["department"] = strings.TrimSpace(*req.Department)
+	}
+	if req.Location != nil {
+		update["$set"].(bson.M)["location"] = strings.TrimSpace(*req.Location)
+	}
+	if req.Team != nil {
+		update["$set"].(bson.M)["team"] = strings.TrimSpace(*req.Team)
+	}
+	if req.CustomFields != nil {
+		values, err := h.fields.Apply(context.Background(), effectiveCategory, ticket.CustomFields, req.CustomFields, false)
+		if err != nil {
+			customFieldError(c, err)
+			return
+		}
+		if values == nil {
+			update["$unset"] = bson.M{"customFields": ""}
+		} else {
+			update["$set"].(bson.M)["customFields"] = values
+		}
+	}
+	if req.ClearDueAt {
+		unset, _ := update["$unset"].(bson.M)
+		if unset == nil {
+			unset = bson.M{}
+			update["$unset"] = unset
+		}
+		unset["dueAt"] = ""
+	} else if req.DueAt != nil {
+		if !req.DueAt.After(ticket.CreatedAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dueAt must be after the ticket was created"})
+			return
+		}
+		update["$set"].(bson.M)["dueAt"] = req.DueAt
+	}
 
-	result, err := h.db.GetCollection("tickets").UpdateOne(
+	filter := bson.M{"_id": objectID}
+	if req.Status != "" && req.Status != ticket.Status {
+		services.ApplyStatusTransition(update, ticket, req.Status, time.Now())
+		// The transition was checked against the status read above
+		filter["status"] = ticket.Status
+	}
+
+	var after models.Ticket
+	err = h.db.GetCollection("tickets").FindOneAndUpdate(
 		context.Background(),
-		bson.M{"_id": objectID},
+		filter,
 		update,
-	)
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&after)
 
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			if _, ok := filter["status"]; ok {
+				c.JSON(http.StatusConflict, gin.H{"error": "Ticket status changed since it was read; reload and try again"})
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update ticket"})
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
-		return
+	if err := h.history.Record(context.Background(), objectID, userObj, services.DiffTicket(ticket, after)); err != nil {
+		log.Printf("Failed to record history of ticket %s: %v", id, err)
+	}
+
+	if req.Title != "" || req.Description != "" {
+		updated := ticket
+		if req.Title != "" {
+			updated.Title = req.Title
+		}
+		if req.Description != "" {
+			updated.Description = req.Description
+		}
+		if _, err := h.affinity.LinkResources(context.Background(), updated); err != nil {
+			log.Printf("Failed to link monitored resources to ticket %s: %v", id, err)
+		}
+	}
+	h.summaries.RefreshIfPresent(objectID)
+
+	// Push and email the assignee when a ticket is (re)assigned or
+	// escalated to critical
+	reassigned := req.AssignedTo != nil && (ticket.AssignedTo == nil || *ticket.AssignedTo != *req.AssignedTo)
+	escalated := req.Priority == models.PriorityCritical && ticket.Priority != models.PriorityCritical
+	if reassigned || escalated {
+		updated := ticket
+		if req.Title != "" {
+			updated.Title = req.Title
+		}
+		if req.AssignedTo != nil {
+			updated.AssignedTo = req.AssignedTo
+		}
+		if req.Priority != "" {
+			updated.Priority = req.Priority
+		}
+		if req.Status != "" {
+			updated.Status = req.Status
+		}
+		go h.push.NotifyTicketAssignee(context.Background(), updated)
+		go h.actions.NotifyAssignee(context.Background(), updated)
+	}
+	// Text the requester when a ticket they raised by SMS or WhatsApp
+	// changes status
+	if after.Status != ticket.Status {
+		go h.messaging.NotifyStatus(context.Background(), after)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Ticket updated successfully"})
 }
 
+// SummarizeTicket condenses the ticket thread into a handover summary and a
+// customer-facing update, and stores both on the ticket
+func (h *TicketHandler) SummarizeTicket(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	ctx, ok := generationContext(c)
+	if !ok {
+		return
+	}
+
+	var ticket models.Ticket
+	err = h.db.GetCollection("tickets").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&ticket)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket"})
+		return
+	}
+
+	summary, err := h.summaries.Summarize(ctx, ticket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store summary"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
 func (h *TicketHandler) DeleteTicket(c *gin.Context) {
 	id := c.Param("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -278,16 +855,444 @@ func (h *TicketHandler) DeleteTicket(c *gin.Context) {
 		return
 	}
 
-	result, err := h.db.GetCollection("tickets").DeleteOne(context.Background(), bson.M{"_id": objectID})
-	if err != nil {
+	// Deleted tickets are kept, with their attachments, so admins can
+	// restore them
+	if err := h.archive.Delete(context.Background(), objectID, userObj.ID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete ticket"})
 		return
 	}
 
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+	c.JSON(http.StatusOK, gin.H{"message": "Ticket deleted successfully"})
+}
+
+// GetDeletedTickets lists deleted tickets, most recently deleted first
+// (admin only)
+func (h *TicketHandler) GetDeletedTickets(c *gin.Context) {
+	pageInt := 1
+	limitInt := 10
+	if p, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && p > 0 {
+		pageInt = p
+	}
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "10")); err == nil && l > 0 && l <= 1000 {
+		limitInt = l
+	}
+
+	tickets, total, err := h.archive.ListDeleted(context.Background(), int64((pageInt-1)*limitInt), int64(limitInt))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted tickets"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Ticket deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"tickets": tickets,
+		"total":   total,
+		"page":    pageInt,
+		"limit":   limitInt,
+	})
+}
+
+// RestoreTicket brings a deleted ticket back (admin only)
+func (h *TicketHandler) RestoreTicket(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	ticket, err := h.archive.Restore(context.Background(), objectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Deleted ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore ticket"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+// PurgeTicket removes a deleted ticket and its attachments for good (admin
+// only)
+func (h *TicketHandler) PurgeTicket(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	if err := h.archive.Purge(context.Background(), objectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Deleted ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge ticket"})
+		return
+	}
+
+	if h.attachments != nil {
+		if err := h.attachments.DeleteForTicket(context.Background(), objectID); err != nil {
+			log.Printf("Failed to delete attachments of ticket %s: %v", objectID.Hex(), err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ticket purged successfully"})
+}
+
+// GetMonitoringContext returns the monitored resources a ticket mentions and
+// their current anomalies
+func (h *TicketHandler) GetMonitoringContext(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var ticket models.Ticket
+	err = h.db.GetCollection("tickets").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&ticket)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket"})
+		return
+	}
+
+	monitoring, err := h.affinity.Context(context.Background(), ticket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load monitoring context"})
+		return
+	}
+
+	c.JSON(http.StatusOK, monitoring)
+}
+
+// loadUserTicket loads the ticket in the :id parameter, reading through to
+// the archive, and writes the error response when it cannot. Requesters
+// only see their own tickets; those of other users are reported as not
+// found.
+func loadUserTicket(c *gin.Context, archive *services.TicketArchiveService, user models.User) (models.Ticket, bool) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return models.Ticket{}, false
+	}
+
+	ticket, err := archive.Get(context.Background(), objectID)
+	if err == nil && user.Role == models.RoleRequester && ticket.CreatedBy != user.ID {
+		err = mongo.ErrNoDocuments
+	}
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return models.Ticket{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket"})
+		return models.Ticket{}, false
+	}
+	return ticket, true
+}
+
+// UploadAttachment stores a file, such as a screenshot or a log, sent as
+// the multipart field "file"
+func (h *TicketHandler) UploadAttachment(c *gin.Context) {
+	if h.attachments == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Attachments are not available"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := loadUserTicket(c, h.archive, user)
+	if !ok {
+		return
+	}
+
+	// Leave room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxAttachmentSize+64<<10)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Attachments must be at most %d MB", h.maxAttachmentSize>>20)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+	if file.Size > h.maxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Attachments must be at most %d MB", h.maxAttachmentSize>>20)})
+		return
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer content.Close()
+
+	attachment, err := h.attachments.Upload(context.Background(), ticket.ID, user, file.Filename, file.Size, content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save attachment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// ListAttachments returns the ticket's attachments with their download URLs
+func (h *TicketHandler) ListAttachments(c *gin.Context) {
+	if h.attachments == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Attachments are not available"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := loadUserTicket(c, h.archive, user)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.attachments.List(context.Background(), ticket.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attachments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+}
+
+// DownloadAttachment sends an attachment's content. It is always sent as a
+// download so uploaded HTML or SVG never renders in the app's origin
+func (h *TicketHandler) DownloadAttachment(c *gin.Context) {
+	if h.attachments == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Attachments are not available"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := loadUserTicket(c, h.archive, user)
+	if !ok {
+		return
+	}
+	attachmentID, err := primitive.ObjectIDFromHex(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	attachment, content, err := h.attachments.Open(context.Background(), ticket.ID, attachmentID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attachment"})
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, no-store")
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, nil)
+}
+
+// DeleteAttachment removes an attachment the current user uploaded, or any
+// attachment for admins
+func (h *TicketHandler) DeleteAttachment(c *gin.Context) {
+	if h.attachments == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Attachments are not available"})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := loadUserTicket(c, h.archive, user)
+	if !ok {
+		return
+	}
+	attachmentID, err := primitive.ObjectIDFromHex(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	if err := h.attachments.Delete(context.Background(), ticket.ID, attachmentID, user); err != nil {
+		switch err {
+		case mongo.ErrNoDocuments:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		case services.ErrAttachmentNotOwned:
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own attachments"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attachment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
+
+// GetTicketHistory returns who changed which fields of the ticket and when,
+// newest first, paged with ?page and ?limit
+func (h *TicketHandler) GetTicketHistory(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := loadUserTicket(c, h.archive, user)
+	if !ok {
+		return
+	}
+
+	pageInt := 1
+	limitInt := 50
+	if p, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && p > 0 {
+		pageInt = p
+	}
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "50")); err == nil && l > 0 && l <= 200 {
+		limitInt = l
+	}
+
+	entries, total, err := h.history.List(context.Background(), ticket.ID, int64((pageInt-1)*limitInt), int64(limitInt))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"history": entries,
+		"total":   total,
+		"page":    pageInt,
+		"limit":   limitInt,
+	})
+}
+
+// ListTicketTags returns the tags in use on live tickets with how many
+// tickets carry each
+func (h *TicketHandler) ListTicketTags(c *gin.Context) {
+	tags, err := services.TicketTagCounts(context.Background(), h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// AddTicketTags adds tags to a ticket, keeping the ones it already has
+func (h *TicketHandler) AddTicketTags(c *gin.Context) {
+	var req models.TicketTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := h.liveTicket(c)
+	if !ok {
+		return
+	}
+	tags, err := services.MergeTicketTags(ticket.Tags, req.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.setTicketTags(c, ticket, tags, user)
+}
+
+// RemoveTicketTag removes :tag from a ticket
+func (h *TicketHandler) RemoveTicketTag(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := h.liveTicket(c)
+	if !ok {
+		return
+	}
+	removed := strings.ToLower(strings.TrimSpace(c.Param("tag")))
+	tags := []string{}
+	for _, tag := range ticket.Tags {
+		if tag != removed {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == len(ticket.Tags) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found on ticket"})
+		return
+	}
+
+	h.setTicketTags(c, ticket, tags, user)
+}
+
+// customFieldError responds to custom field values that were rejected or
+// could not be checked.
+func customFieldError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidCustomField) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check custom fields"})
+}
+
+// liveTicket loads the ticket in :id from the tickets collection; archived
+// tickets are read-only and not found here.
+func (h *TicketHandler) liveTicket(c *gin.Context) (models.Ticket, bool) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return models.Ticket{}, false
+	}
+	var ticket models.Ticket
+	if err := h.db.GetCollection("tickets").FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&ticket); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return models.Ticket{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket"})
+		return models.Ticket{}, false
+	}
+	return ticket, true
+}
+
+// setTicketTags replaces a ticket's tags, records the change and responds
+// with the updated ticket.
+func (h *TicketHandler) setTicketTags(c *gin.Context, ticket models.Ticket, tags []string, user models.User) {
+	var after models.Ticket
+	err := h.db.GetCollection("tickets").FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": ticket.ID},
+		bson.M{"$set": bson.M{"tags": tags, "updatedAt": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&after)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tags"})
+		return
+	}
+
+	if err := h.history.Record(context.Background(), ticket.ID, user, services.DiffTicket(ticket, after)); err != nil {
+		log.Printf("Failed to record history of ticket %s: %v", ticket.ID.Hex(), err)
+	}
+
+	c.JSON(http.StatusOK, after)
+}
+
+func containsObjectID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type TicketLinkHandler struct {
+	links   *services.TicketLinkService
+	archive *services.TicketArchiveService
+	push    *services.PushService
+}
+
+func NewTicketLinkHandler(links *services.TicketLinkService, archive *services.TicketArchiveService, push *services.PushService) *TicketLinkHandler {
+	return &TicketLinkHandler{links: links, archive: archive, push: push}
+}
+
+// ListTicketLinks returns the tickets the ticket blocks, duplicates or is
+// split into, with their titles and statuses
+func (h *TicketLinkHandler) ListTicketLinks(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := loadUserTicket(c, h.archive, user)
+	if !ok {
+		return
+	}
+
+	links, err := h.links.List(context.Background(), ticket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket links"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+// CreateTicketLink links the ticket to another: blocks, blocked_by,
+// duplicates, duplicated_by, parent, child, or after and before between
+// changes
+func (h *TicketLinkHandler) CreateTicketLink(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	var req models.CreateTicketLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user := c.MustGet("user").(models.User)
+
+	link, err := h.links.Link(context.Background(), ticketID, req, user)
+	if err != nil {
+		switch {
+		case err == mongo.ErrNoDocuments:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		case errors.Is(err, services.ErrLinkedTicketNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTicketLinkExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTicketLinkInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link tickets"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// DeleteTicketLink removes the links to :linkedId from both tickets, only
+// those of ?type when it is given
+func (h *TicketLinkHandler) DeleteTicketLink(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	linkedID, err := primitive.ObjectIDFromHex(c.Param("linkedId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid linked ticket ID"})
+		return
+	}
+	linkType := models.TicketLinkType(c.Query("type"))
+	if _, ok := models.TicketLinkInverse[linkType]; linkType != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown link type"})
+		return
+	}
+	user := c.MustGet("user").(models.User)
+
+	if err := h.links.Unlink(context.Background(), ticketID, linkedID, linkType, user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove ticket link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ticket link removed successfully"})
+}
+
+// SplitTicket splits the ticket into sub-tasks, each assigned to the
+// technician given or by the assignment rules (technicians and admins)
+func (h *TicketLinkHandler) SplitTicket(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	user := c.MustGet("user").(models.User)
+	if user.Role == models.RoleRequester {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only technicians and admins can split tickets"})
+		return
+	}
+	var req models.SplitTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subtasks, err := h.links.Split(context.Background(), ticketID, req, user)
+	if err != nil {
+		switch {
+		case err == mongo.ErrNoDocuments:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		case errors.Is(err, services.ErrTicketLinkInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAwaitingApproval), errors.Is(err, services.ErrChangeAwaitingApproval):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to split ticket"})
+		}
+		return
+	}
+
+	for _, subtask := range subtasks {
+		if subtask.AssignedTo != nil {
+			go h.push.NotifyTicketAssignee(context.Background(), subtask)
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"subtasks": subtasks})
+}
+
+// GetSubtasks returns the ticket's sub-tasks as a tree, with how many are
+// done and the status they roll up to
+func (h *TicketLinkHandler) GetSubtasks(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := loadUserTicket(c, h.archive, user)
+	if !ok {
+		return
+	}
+
+	tree, err := h.links.Subtasks(context.Background(), ticket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sub-tasks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type TicketMergeHandler struct {
+	db     *database.MongoDB
+	merges *services.TicketMergeService
+}
+
+func NewTicketMergeHandler(db *database.MongoDB, merges *services.TicketMergeService) *TicketMergeHandler {
+	return &TicketMergeHandler{db: db, merges: merges}
+}
+
+// MergeTicket merges the ticket into the one in targetId, moving its
+// comments and attachments over and closing it as a duplicate
+func (h *TicketMergeHandler) MergeTicket(c *gin.Context) {
+	sourceID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	var req models.MergeTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	targetID, err := primitive.ObjectIDFromHex(req.TargetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target ticket ID"})
+		return
+	}
+	user := c.MustGet("user").(models.User)
+
+	var source models.Ticket
+	if err := h.db.GetCollection("tickets").FindOne(context.Background(), bson.M{"_id": sourceID}).Decode(&source); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket"})
+		return
+	}
+	// Check if user can merge this ticket (creator or admin)
+	if user.Role != models.RoleAdmin && source.CreatedBy != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only merge your own tickets"})
+		return
+	}
+
+	target, err := h.merges.Merge(context.Background(), source, targetID, user)
+	if err != nil {
+		switch {
+		case err == mongo.ErrNoDocuments:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Target ticket not found"})
+		case errors.Is(err, services.ErrMergeInvalid):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge ticket"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ticket merged successfully",
+		"ticket":  target,
+	})
+}
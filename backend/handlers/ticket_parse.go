@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type TicketParseHandler struct {
+	parser *services.TicketParseService
+}
+
+func NewTicketParseHandler(parser *services.TicketParseService) *TicketParseHandler {
+	return &TicketParseHandler{parser: parser}
+}
+
+// ParseTicket drafts a ticket from a free-form message or chat transcript.
+// The draft's ticket can be posted to /api/tickets as it is
+func (h *TicketParseHandler) ParseTicket(c *gin.Context) {
+	var req models.ParseTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, ok := generationContext(c)
+	if !ok {
+		return
+	}
+
+	parsed, err := h.parser.Parse(ctx, req.Text)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse ticket"})
+		return
+	}
+
+	c.JSON(http.StatusOK, parsed)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type UsageHandler struct {
+	usage *services.UsageService
+}
+
+func NewUsageHandler(usage *services.UsageService) *UsageHandler {
+	return &UsageHandler{usage: usage}
+}
+
+// GetUsage returns each user's API requests per day and route group over
+// the last ?days (default 7) UTC days, optionally for one ?userId (admin
+// only)
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days < 1 || days > 90 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+		return
+	}
+
+	var userID *primitive.ObjectID
+	if v := c.Query("userId"); v != "" {
+		id, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		userID = &id
+	}
+
+	ctx := context.Background()
+	// Include the requests still buffered on this replica
+	if err := h.usage.Flush(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record usage"})
+		return
+	}
+
+	now := time.Now().UTC()
+	from := now.AddDate(0, 0, 1-days).Format("2006-01-02")
+	report, err := h.usage.Report(ctx, from, now.Format("2006-01-02"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ListQuotas returns every usage quota (admin only)
+func (h *UsageHandler) ListQuotas(c *gin.Context) {
+	quotas, err := h.usage.ListQuotas(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotas": quotas})
+}
+
+// UpdateQuota creates or replaces the quota in :key (admin only)
+func (h *UsageHandler) UpdateQuota(c *gin.Context) {
+	var req models.UsageQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quota, err := services.ValidateUsageQuota(c.Param("key"), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := c.MustGet("user").(models.User)
+
+	quota, err = h.usage.SaveQuota(context.Background(), quota, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quota"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quota)
+}
+
+// DeleteQuota removes a quota; built-in quotas return to no limit (admin
+// only)
+func (h *UsageHandler) DeleteQuota(c *gin.Context) {
+	if err := h.usage.DeleteQuota(context.Background(), c.Param("key")); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quota not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete quota"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quota deleted successfully"})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/models"
+	"intelliops-ai-copilot/services"
+)
+
+type WorklogHandler struct {
+	worklogs *services.WorklogService
+	archive  *services.TicketArchiveService
+}
+
+func NewWorklogHandler(worklogs *services.WorklogService, archive *services.TicketArchiveService) *WorklogHandler {
+	return &WorklogHandler{worklogs: worklogs, archive: archive}
+}
+
+// ListWorklogs returns the time logged on the ticket, most recent first,
+// with the total and each technician's share
+func (h *WorklogHandler) ListWorklogs(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	ticket, ok := loadUserTicket(c, h.archive, user)
+	if !ok {
+		return
+	}
+
+	worklogs, err := h.worklogs.List(context.Background(), ticket.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch worklogs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, worklogs)
+}
+
+// AddWorklog logs time spent on the ticket
+func (h *WorklogHandler) AddWorklog(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+	var req models.CreateWorklogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user := c.MustGet("user").(models.User)
+
+	entry, err := h.worklogs.Add(context.Background(), ticketID, user, req)
+	if err != nil {
+		worklogError(c, err, "Failed to log time")
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateWorklog changes a worklog entry; only its author or an admin may
+func (h *WorklogHandler) UpdateWorklog(c *gin.Context) {
+	ticketID, id, ok := worklogIDs(c)
+	if !ok {
+		return
+	}
+	var req models.UpdateWorklogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.canEdit(c, ticketID, id) {
+		return
+	}
+
+	entry, err := h.worklogs.Update(context.Background(), ticketID, id, req)
+	if err != nil {
+		worklogError(c, err, "Failed to update worklog entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteWorklog removes a worklog entry; only its author or an admin may
+func (h *WorklogHandler) DeleteWorklog(c *gin.Context) {
+	ticketID, id, ok := worklogIDs(c)
+	if !ok {
+		return
+	}
+	if !h.canEdit(c, ticketID, id) {
+		return
+	}
+
+	if err := h.worklogs.Delete(context.Background(), ticketID, id); err != nil {
+		worklogError(c, err, "Failed to delete worklog entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Worklog entry deleted successfully"})
+}
+
+func worklogIDs(c *gin.Context) (primitive.ObjectID, primitive.ObjectID, bool) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return ticketID, ticketID, false
+	}
+	id, err := primitive.ObjectIDFromHex(c.Param("worklogId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid worklog ID"})
+		return ticketID, id, false
+	}
+	return ticketID, id, true
+}
+
+// canEdit writes an error and returns false unless the user logged the
+// entry or is an admin.
+func (h *WorklogHandler) canEdit(c *gin.Context, ticketID, id primitive.ObjectID) bool {
+	user := c.MustGet("user").(models.User)
+	entry, err := h.worklogs.Get(context.Background(), ticketID, id)
+	if err != nil {
+		worklogError(c, err, "Failed to fetch worklog entry")
+		return false
+	}
+	if user.Role != models.RoleAdmin && entry.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the technician who logged the time or an admin can change it"})
+		return false
+	}
+	return true
+}
+
+func worklogError(c *gin.Context, err error, message string) {
+	switch {
+	case err == mongo.ErrNoDocuments:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket or worklog entry not found"})
+	case errors.Is(err, services.ErrInvalidWorklog):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,412 @@
+//go:build integration
+
+// Integration and contract tests for the backend API.
+//
+// TestMain builds the backend and starts it in DEMO_MODE against a
+// throwaway MongoDB: INTEGRATION_MONGODB_URI when set, else an embedded
+// mongod when one is in PATH, else a mongo:7.0 Docker container.
+// The tests seed users and tickets through the API and exercise the auth,
+// ticket, docs and monitoring routes. The shape of every JSON response
+// (keys and value types, not values) is compared with the golden files in
+// testdata/contracts, so accidental API changes fail the run.
+//
+//	go test -tags integration .          run and compare
+//	go test -tags integration . -update  record new golden files
+//	go test -tags integration -run '^$' -bench .  benchmark the hot paths
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"intelliops-ai-copilot/database"
+)
+
+var updateGolden = flag.Bool("update", false, "record new golden files in testdata/contracts")
+
+const (
+	integrationAlertmanagerToken = "integration-test-token"
+	contractsDir                 = "testdata/contracts"
+)
+
+// apiURL is the base URL of the backend started by TestMain.
+var apiURL string
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	os.Exit(runIntegration(m))
+}
+
+func runIntegration(m *testing.M) int {
+	mongoURI, stopMongo, err := startTestMongoDB()
+	if err != nil {
+		log.Printf("Failed to start MongoDB: %v", err)
+		return 1
+	}
+	defer stopMongo()
+
+	work, err := os.MkdirTemp("", "intelliops-it-")
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	defer os.RemoveAll(work)
+
+	binary := filepath.Join(work, "intelliops")
+	build := exec.Command("go", "build", "-o", binary, ".")
+	build.Stdout, build.Stderr = os.Stdout, os.Stderr
+	if err := build.Run(); err != nil {
+		log.Printf("Failed to build backend: %v", err)
+		return 1
+	}
+
+	port, err := freeTestPort()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	apiURL = fmt.Sprintf("http://127.0.0.1:%d", port)
+
+	var backendLog bytes.Buffer
+	server := exec.Command(binary)
+	server.Dir = work
+	server.Env = append(os.Environ(),
+		"DEMO_MODE=true",
+		"MONGODB_URI="+mongoURI,
+		fmt.Sprintf("DATABASE_NAME=intelliops_it_%d", time.Now().UnixNano()),
+		"JWT_SECRET=integration-test-secret",
+		fmt.Sprintf("PORT=%d", port),
+		"GIN_MODE=release",
+		"ALERTMANAGER_TOKEN="+integrationAlertmanagerToken,
+		"HSTS_MAX_AGE=0",
+	)
+	server.Stdout, server.Stderr = &backendLog, &backendLog
+	if err := server.Start(); err != nil {
+		log.Printf("Failed to start backend: %v", err)
+		return 1
+	}
+	defer server.Process.Kill()
+
+	if !waitHealthy(30 * time.Second) {
+		log.Printf("Backend did not start:\n%s", backendLog.String())
+		return 1
+	}
+
+	code := m.Run()
+	if code != 0 {
+		log.Printf("Backend log:\n%s", tail(backendLog.String(), 50))
+	}
+	return code
+}
+
+// startTestMongoDB returns the URI of the MongoDB the tests use and a
+// function that stops it.
+func startTestMongoDB() (string, func(), error) {
+	if uri := os.Getenv("INTEGRATION_MONGODB_URI"); uri != "" {
+		return uri, func() {}, nil
+	}
+	if _, err := exec.LookPath("mongod"); err == nil {
+		embedded, err := database.StartEmbedded("mongod", "")
+		if err != nil {
+			return "", nil, err
+		}
+		return embedded.URI, embedded.Stop, nil
+	}
+
+	port, err := freeTestPort()
+	if err != nil {
+		return "", nil, err
+	}
+	out, err := exec.Command("docker", "run", "-d", "--rm", "-p", fmt.Sprintf("127.0.0.1:%d:27017", port), "mongo:7.0").Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("no mongod in PATH and docker failed: %w", err)
+	}
+	container := strings.TrimSpace(string(out))
+	stop := func() { exec.Command("docker", "stop", container).Run() }
+	deadline := time.Now().Add(60 * time.Second)
+	for time.Now().Before(deadline) {
+		if exec.Command("docker", "exec", container, "mongosh", "--quiet", "--eval", "db.runCommand({ping: 1})").Run() == nil {
+			return fmt.Sprintf("mongodb://127.0.0.1:%d", port), stop, nil
+		}
+		time.Sleep(time.Second)
+	}
+	stop()
+	return "", nil, fmt.Errorf("MongoDB container did not start")
+}
+
+func freeTestPort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func waitHealthy(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if resp, err := http.Get(apiURL + "/health"); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return true
+			}
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return false
+}
+
+func tail(s string, lines int) string {
+	parts := strings.Split(s, "\n")
+	if len(parts) > lines {
+		parts = parts[len(parts)-lines:]
+	}
+	return strings.Join(parts, "\n")
+}
+
+// request sends a JSON request to the backend and returns the status and
+// decoded body. token is sent as a bearer token when set.
+func request(tb testing.TB, method, path, body, token string) (int, interface{}) {
+	tb.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, apiURL+path, reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var decoded interface{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			tb.Fatalf("%s %s: response is not JSON: %s", method, path, raw)
+		}
+	}
+	return resp.StatusCode, decoded
+}
+
+// call checks the status of a request and compares the response shape with
+// the golden file for name, returning the decoded body.
+func call(t *testing.T, name string, expected int, method, path, body, token string) interface{} {
+	t.Helper()
+	status, decoded := request(t, method, path, body, token)
+	if status != expected {
+		t.Errorf("%s: %s %s returned %d, expected %d: %v", name, method, path, status, expected, decoded)
+		return decoded
+	}
+
+	got, err := json.MarshalIndent(shape(decoded), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+	golden := filepath.Join(contractsDir, name+".json")
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return decoded
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Errorf("%s: no golden file; record it with -update: %v", name, err)
+		return decoded
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("%s: response contract changed\n--- %s\n%s\n--- response\n%s", name, golden, want, got)
+	}
+	return decoded
+}
+
+// shape reduces a JSON document to its shape: objects keep their keys,
+// arrays are represented by their first element, and scalars become their
+// type name.
+func shape(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			out[k] = shape(value)
+		}
+		return out
+	case []interface{}:
+		if len(v) == 0 {
+			return []interface{}{}
+		}
+		return []interface{}{shape(v[0])}
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+// field reads a string at a dotted path of a decoded response.
+func field(tb testing.TB, v interface{}, path string) string {
+	tb.Helper()
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			tb.Fatalf("no %s in response %v", path, v)
+		}
+		v = obj[key]
+	}
+	s, ok := v.(string)
+	if !ok {
+		tb.Fatalf("%s is not a string in response", path)
+	}
+	return s
+}
+
+// fixtures are the users the tests act as, created once.
+type fixtures struct {
+	admin, technician, requester string
+}
+
+var seeded *fixtures
+
+func seed(tb testing.TB) fixtures {
+	tb.Helper()
+	if seeded != nil {
+		return *seeded
+	}
+	login := func(email string) string {
+		status, body := request(tb, http.MethodPost, "/api/auth/login", fmt.Sprintf(`{"email":%q,"password":"password"}`, email), "")
+		if status != http.StatusOK {
+			tb.Fatalf("login as %s returned %d: %v", email, status, body)
+		}
+		return field(tb, body, "token")
+	}
+	f := fixtures{admin: login("admin@intelliops.com")}
+	for _, user := range []struct{ name, email, role string }{
+		{"Test Technician", "tech@example.com", "technician"},
+		{"Test Requester", "requester@example.com", "requester"},
+	} {
+		body := fmt.Sprintf(`{"name":%q,"email":%q,"password":"password","role":%q}`, user.name, user.email, user.role)
+		if status, resp := request(tb, http.MethodPost, "/api/admin/users", body, f.admin); status != http.StatusCreated {
+			tb.Fatalf("creating %s returned %d: %v", user.email, status, resp)
+		}
+	}
+	f.technician = login("tech@example.com")
+	f.requester = login("requester@example.com")
+	seeded = &f
+	return f
+}
+
+func TestAuthContracts(t *testing.T) {
+	call(t, "health", 200, "GET", "/health", "", "")
+	call(t, "auth_login_invalid", 401, "POST", "/api/auth/login", `{"email":"admin@intelliops.com","password":"wrong-password"}`, "")
+	login := call(t, "auth_login", 200, "POST", "/api/auth/login", `{"email":"admin@intelliops.com","password":"password"}`, "")
+	call(t, "auth_profile", 200, "GET", "/api/auth/profile", "", field(t, login, "token"))
+	call(t, "auth_profile_unauthenticated", 401, "GET", "/api/auth/profile", "", "")
+}
+
+func TestTicketContracts(t *testing.T) {
+	f := seed(t)
+
+	ticket := call(t, "ticket_create", 201, "POST", "/api/tickets",
+		`{"title":"VPN disconnects every few minutes","description":"Remote users lose the VPN tunnel several times an hour.","priority":"high"}`, f.technician)
+	id := field(t, ticket, "id")
+	call(t, "ticket_create_invalid", 400, "POST", "/api/tickets", `{"title":"Missing description"}`, f.technician)
+	call(t, "ticket_list", 200, "GET", "/api/tickets", "", f.technician)
+	call(t, "ticket_get", 200, "GET", "/api/tickets/"+id, "", f.technician)
+	call(t, "ticket_get_invalid_id", 400, "GET", "/api/tickets/not-an-id", "", f.technician)
+	call(t, "ticket_update", 200, "PUT", "/api/tickets/"+id, `{"status":"in_progress"}`, f.technician)
+	call(t, "ticket_update_invalid_transition", 400, "PUT", "/api/tickets/"+id, `{"status":"closed"}`, f.technician)
+	call(t, "ticket_summarize", 200, "POST", "/api/tickets/"+id+"/summarize", "", f.technician)
+	call(t, "ticket_requester_forbidden", 403, "GET", "/api/tickets", "", f.requester)
+	call(t, "portal_ticket_create", 201, "POST", "/api/portal/tickets",
+		`{"title":"Printer on floor 3 is jammed","description":"The printer shows a paper jam error."}`, f.requester)
+	call(t, "portal_ticket_list", 200, "GET", "/api/portal/tickets", "", f.requester)
+	call(t, "ai_triage", 200, "POST", "/api/ai/triage",
+		`{"title":"Email not syncing","description":"Outlook stopped syncing this morning."}`, f.technician)
+}
+
+func TestDocsContracts(t *testing.T) {
+	f := seed(t)
+
+	status, ticket := request(t, "POST", "/api/tickets", `{"title":"Wi-Fi drops in meeting rooms","description":"Laptops lose the Wi-Fi connection in the meeting rooms."}`, f.technician)
+	if status != http.StatusCreated {
+		t.Fatalf("creating ticket returned %d: %v", status, ticket)
+	}
+	call(t, "docs_stats", 200, "GET", "/api/docs/stats", "", f.technician)
+	call(t, "docs_search", 200, "POST", "/api/docs/search", `{"query":"vpn disconnects","topK":3}`, f.technician)
+	call(t, "ticket_solutions", 200, "GET", "/api/tickets/"+field(t, ticket, "id")+"/solutions", "", f.technician)
+}
+
+func TestMonitoringContracts(t *testing.T) {
+	f := seed(t)
+
+	call(t, "monitor_alertmanager_unauthorized", 401, "POST", "/api/monitor/ingest/alertmanager", `{"alerts":[]}`, "")
+	call(t, "monitor_alertmanager", 200, "POST", "/api/monitor/ingest/alertmanager",
+		`{"version":"4","status":"firing","alerts":[{"status":"firing","labels":{"alertname":"HighCPU","severity":"critical","instance":"web-1"},"annotations":{"summary":"CPU above 95% on web-1"},"startsAt":"2024-01-01T00:00:00Z","fingerprint":"it-high-cpu"}]}`,
+		integrationAlertmanagerToken)
+	call(t, "monitor_resources", 200, "GET", "/api/admin/monitor/resources", "", f.admin)
+	call(t, "monitor_metrics", 200, "GET", "/api/admin/monitor/metrics", "", f.admin)
+	call(t, "monitor_anomalies", 200, "GET", "/api/admin/monitor/anomalies", "", f.admin)
+	call(t, "monitor_technician_forbidden", 403, "GET", "/api/admin/monitor/resources", "", f.technician)
+	call(t, "admin_stats", 200, "GET", "/api/admin/stats", "", f.admin)
+}
+
+// benchRequest runs one request per iteration and fails on a non-200.
+func benchRequest(b *testing.B, method, path, body, token string) {
+	b.Helper()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if status, resp := request(b, method, path, body, token); status != http.StatusOK {
+			b.Fatalf("%s %s returned %d: %v", method, path, status, resp)
+		}
+	}
+}
+
+func BenchmarkGetTickets(b *testing.B) {
+	f := seed(b)
+	for i := 0; i < 50; i++ {
+		body := fmt.Sprintf(`{"title":"Benchmark ticket %d","description":"Seeded so the ticket list has pages to read."}`, i)
+		if status, resp := request(b, "POST", "/api/tickets", body, f.technician); status != http.StatusCreated {
+			b.Fatalf("seeding tickets returned %d: %v", status, resp)
+		}
+	}
+	benchRequest(b, "GET", "/api/tickets?page=1&limit=10", "", f.technician)
+}
+
+func BenchmarkTriage(b *testing.B) {
+	f := seed(b)
+	benchRequest(b, "POST", "/api/ai/triage", `{"title":"Outlook not syncing","description":"Mailbox stopped syncing after the password change this morning."}`, f.technician)
+}
+
+func BenchmarkVectorSearch(b *testing.B) {
+	f := seed(b)
+	benchRequest(b, "POST", "/api/docs/search", `{"query":"vpn disconnects","topK":5}`, f.technician)
+}
@@ -3,8 +3,16 @@ package main
 import (
 	"log"
 	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/acme/autocert"
 
 	"intelliops-ai-copilot/config"
 	"intelliops-ai-copilot/database"
@@ -21,6 +29,25 @@ func main() {
 	// Set Gin mode
 	gin.SetMode(cfg.GinMode)
 
+	// Start an embedded MongoDB when no server is configured
+	if cfg.EmbeddedMongoDB {
+		embedded, err := database.StartEmbedded(cfg.MongodPath, cfg.EmbeddedMongoDBDir)
+		if err != nil {
+			log.Fatal("Failed to start embedded MongoDB:", err)
+		}
+		defer embedded.Stop()
+		cfg.MongoDBURI = embedded.URI
+
+		// Deferred calls do not run on a signal, so stop mongod here too
+		go func() {
+			stop := make(chan os.Signal, 1)
+			signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+			<-stop
+			embedded.Stop()
+			os.Exit(0)
+		}()
+	}
+
 	// Connect to MongoDB
 	db, err := database.NewMongoDB(cfg.MongoDBURI, cfg.DatabaseName)
 	if err != nil {
@@ -31,33 +58,247 @@ func main() {
 	// Create default admin user if it doesn't exist
 	createDefaultAdmin(db)
 
+	// Apply the admin settings stored in the database over the environment
+	settingsService := services.NewSettingsService(db, cfg)
+	if err := settingsService.Load(context.Background()); err != nil {
+		log.Printf("Failed to load settings, using environment defaults: %v", err)
+	}
+
 	// Initialize services
-	vectorService := services.NewVectorService(cfg.OpenAIAPIKey, cfg.LocalLLMURL, cfg.AIProvider)
+	vectorService := services.NewVectorService(db, cfg)
+	vectorService.Start(context.Background(), cfg.DocumentIndexPollInterval)
 	docService := services.NewDocumentService(vectorService)
-	llmService := services.NewLLMService(cfg.OpenAIAPIKey, cfg.OpenAIModel, cfg.LocalLLMURL, cfg.AIProvider)
+	guardrailService := services.NewGuardrailService(db)
+	generationService := services.NewGenerationService(db)
+	automationService := services.NewAutomationService(db, cfg)
+	glossaryService := services.NewGlossaryService(db)
+	llmService := services.NewLLMService(cfg.OpenAIAPIKey, cfg.OpenAIModel, cfg.LocalLLMURL, cfg.AIProvider, cfg.LLMContextTokens, guardrailService, generationService, automationService, glossaryService)
+	taxonomyService := services.NewTaxonomyService(db)
+	deploymentService := services.NewDeploymentService(db, cfg)
+	availabilityService := services.NewAvailabilityService(db)
+	triageService := services.NewTriageService(db, llmService, taxonomyService, deploymentService, availabilityService, cfg.TriagePromptVersion)
+	experimentService := services.NewExperimentService(db)
+	evaluationService := services.NewEvaluationService(db, triageService, vectorService)
 
 	// Monitoring services
+	notificationService := services.NewNotificationService(cfg)
+	anomalyPolicies := services.NewAnomalyPolicies(cfg)
+	featureFlagService := services.NewFeatureFlagService(db)
+	llmService.ConfigureProfile(cfg)
+	settingsService.OnChange(func(cfg *config.Config) {
+		llmService.Configure(cfg.OpenAIAPIKey, cfg.OpenAIModel, cfg.LocalLLMURL, cfg.AIProvider)
+		llmService.ConfigureProfile(cfg)
+		notificationService.Configure(cfg)
+		anomalyPolicies.Reload(cfg)
+	})
+	monitorRouteService := services.NewMonitorRouteService(db, notificationService)
 	var monitorSvc *services.MonitoringService
+	var cw *services.CloudWatchService
+	if cfg.DemoMode {
+		cw = services.NewDemoCloudWatchService()
+	}
 	if cfg.MonitoringEnabled {
 		ctx := context.Background()
-		cw, err := services.NewCloudWatchService(ctx, cfg.AWSRegion)
+		if cw == nil {
+			cw, err = services.NewCloudWatchService(ctx, cfg.AWSRegion)
+		}
 		if err != nil {
 			log.Printf("Failed to init CloudWatch client: %v", err)
 		} else {
-			monitorSvc = services.NewMonitoringService(db, cw, cfg, llmService)
+			monitorSvc = services.NewMonitoringService(db, cw, cfg, llmService, monitorRouteService, anomalyPolicies)
 			monitorSvc.Start(ctx)
 			log.Println("Monitoring worker started")
 		}
 	}
+	var costMonitorService *services.CostMonitorService
+	if cfg.CostMonitoringEnabled {
+		ce := services.NewDemoCostExplorerService()
+		var ceErr error
+		if !cfg.DemoMode {
+			ce, ceErr = services.NewCostExplorerService(context.Background())
+		}
+		if ceErr != nil {
+			log.Printf("Failed to init Cost Explorer client: %v", ceErr)
+		} else {
+			costMonitorService = services.NewCostMonitorService(db, ce, cfg, taxonomyService, monitorRouteService, anomalyPolicies, featureFlagService)
+			costMonitorService.Start(context.Background(), cfg.CostPollInterval)
+			log.Println("Cost monitoring worker started")
+		}
+	}
+	syntheticService := services.NewSyntheticService(db, cfg, taxonomyService, monitorRouteService, anomalyPolicies, featureFlagService)
+	if cfg.SyntheticChecksEnabled {
+		syntheticService.Start(context.Background(), cfg.SyntheticTickInterval)
+	}
+	agentService := services.NewAgentService(db, llmService, guardrailService, cw)
+	summaryService := services.NewSummaryService(db, llmService, guardrailService)
+	affinityService := services.NewAffinityService(db, deploymentService)
+	replyService := services.NewReplyService(db, llmService, guardrailService)
+	searchAnalyticsService := services.NewSearchAnalyticsService(db, vectorService)
+	queryService := services.NewQueryService(vectorService, glossaryService, cfg)
+	solutionService := services.NewSolutionService(db, llmService, vectorService, searchAnalyticsService, queryService)
+	pushService := services.NewPushService(db, cfg)
+	ticketHistoryService := services.NewTicketHistoryService(db)
+	quickActionService := services.NewQuickActionService(db, notificationService, ticketHistoryService, cfg)
+	digestService := services.NewDigestService(db, llmService, notificationService, cfg)
+	if cfg.DigestEnabled {
+		digestService.Start(context.Background())
+		log.Printf("Weekly digest scheduled for %s %02d:00", cfg.DigestWeekday, cfg.DigestHour)
+	}
+	forecastService := services.NewForecastService(db)
+	orgService := services.NewOrgService(db)
+	analyticsService := services.NewAnalyticsService(db, orgService)
+	skillService := services.NewSkillService(db)
+	skillService.Start(context.Background(), cfg.SkillRecomputeInterval)
+	assignmentService := services.NewAssignmentService(db, taxonomyService, availabilityService)
+	grafanaService := services.NewGrafanaService(db, taxonomyService)
+	alertIngestService := services.NewAlertIngestService(db, taxonomyService, cfg, monitorRouteService)
+	alertSourceService := services.NewAlertSourceService(db, alertIngestService)
+	postmortemService := services.NewPostmortemService(db, llmService, guardrailService, taxonomyService, notificationService)
+	postmortemService.StartReminders(context.Background(), cfg.PostmortemReminderInterval)
+	incidentService := services.NewIncidentService(db, cfg, notificationService, postmortemService)
+	commentService := services.NewCommentService(db)
+	worklogService := services.NewWorklogService(db)
+	ticketArchiveService := services.NewTicketArchiveService(db, cfg)
+	if cfg.TicketArchiveEnabled {
+		ticketArchiveService.Start(context.Background(), cfg.TicketArchiveInterval)
+	}
+	shareService := services.NewShareService(db, commentService, ticketArchiveService, cfg)
+	advisoryService := services.NewAdvisoryService(db, cfg, taxonomyService, commentService)
+	if cfg.AdvisorySyncEnabled {
+		advisoryService.Start(context.Background(), cfg.AdvisoryPollInterval)
+	}
+	diagnosticRequestService := services.NewDiagnosticRequestService(db, ticketArchiveService, cfg)
+	endpointAgentService := services.NewEndpointAgentService(db, diagnosticRequestService, cfg)
+	licenseService := services.NewLicenseService(db, taxonomyService, cfg)
+	licenseService.StartReminders(context.Background(), cfg.LicenseReminderInterval)
+	documentReviewService := services.NewDocumentReviewService(db, vectorService, taxonomyService, cfg)
+	documentReviewService.StartReminders(context.Background(), cfg.DocumentReviewInterval)
+	approvalDelegationService := services.NewApprovalDelegationService(db)
+	catalogService := services.NewCatalogService(db, taxonomyService, notificationService, ticketHistoryService, approvalDelegationService)
+	kbService := services.NewKBService(db, taxonomyService, docService, vectorService, queryService)
+	deflectionService := services.NewDeflectionService(db, kbService, vectorService, queryService)
+	eventListenerService := services.NewEventListenerService(cfg, alertIngestService)
+	if err := eventListenerService.Start(context.Background()); err != nil {
+		log.Printf("Failed to start network event listeners: %v", err)
+	}
+	clusterService := services.NewClusterService(db, vectorService, cfg)
+	if cfg.ClusteringEnabled {
+		clusterService.Start(context.Background())
+		log.Println("Ticket clustering worker started")
+	}
+
+	passwordService, err := services.NewPasswordService(cfg)
+	if err != nil {
+		log.Fatal("Invalid password hashing configuration:", err)
+	}
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(db, cfg.JWTSecret, cfg.JWTExpiresIn)
-	ticketHandler := handlers.NewTicketHandler(db)
-	aiHandler := handlers.NewAIHandler(db, cfg.OpenAIAPIKey, cfg.OpenAIModel, cfg.LocalLLMURL, cfg.AIProvider)
-	docHandler := handlers.NewDocumentHandler(db, docService, vectorService, llmService)
+	authHandler := handlers.NewAuthHandler(db, cfg.JWTSecret, cfg.JWTExpiresIn, passwordService, worklogService, taxonomyService)
+	attachmentService, err := services.NewAttachmentService(db, cfg)
+	if err != nil {
+		log.Printf("Failed to init attachment storage: %v", err)
+	}
+	ticketImportService := services.NewTicketImportService(db, taxonomyService, orgService)
+	customFieldService := services.NewCustomFieldService(db, taxonomyService)
+	ticketParseService := services.NewTicketParseService(db, llmService, guardrailService, taxonomyService)
+	messagingService := services.NewMessagingService(db, cfg, ticketParseService, taxonomyService, orgService, assignmentService, commentService)
+	ticketHandler := handlers.NewTicketHandler(db, taxonomyService, experimentService, summaryService, affinityService, pushService, quickActionService, licenseService, deflectionService, ticketArchiveService, commentService, attachmentService, ticketHistoryService, orgService, assignmentService, ticketImportService, triageService, customFieldService, endpointAgentService, messagingService, cfg.AutoTriageOnCreate, int64(cfg.AttachmentMaxMB)<<20)
+	aiHandler := handlers.NewAIHandler(db, triageService, experimentService)
+	ticketParseHandler := handlers.NewTicketParseHandler(ticketParseService)
+	messagingHandler := handlers.NewMessagingHandler(messagingService, ticketArchiveService, cfg.PublicAPIURL)
+	chatService := services.NewChatService(db, kbService, llmService, guardrailService, ticketParseService, taxonomyService, orgService, assignmentService)
+	chatHandler := handlers.NewChatHandler(chatService, cfg.ChatAnonymous)
+	docHandler := handlers.NewDocumentHandler(db, docService, vectorService, llmService, documentReviewService, searchAnalyticsService, queryService)
+	taxonomyHandler := handlers.NewTaxonomyHandler(taxonomyService)
+	experimentHandler := handlers.NewExperimentHandler(experimentService, triageService)
+	evaluationHandler := handlers.NewEvaluationHandler(db, evaluationService)
+	guardrailHandler := handlers.NewGuardrailHandler(guardrailService)
+	generationHandler := handlers.NewGenerationHandler(generationService)
+	agentHandler := handlers.NewAgentHandler(db, agentService, summaryService)
+	replyHandler := handlers.NewReplyHandler(db, replyService)
+	solutionHandler := handlers.NewSolutionHandler(db, solutionService, summaryService)
+	automationHandler := handlers.NewAutomationHandler(db, automationService)
+	digestHandler := handlers.NewDigestHandler(digestService)
+	clusterHandler := handlers.NewClusterHandler(clusterService)
+	forecastHandler := handlers.NewForecastHandler(forecastService)
+	analyticsQuestionService := services.NewAnalyticsQuestionService(db, analyticsService, llmService, guardrailService, taxonomyService)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService, analyticsQuestionService)
+	orgHandler := handlers.NewOrgHandler(orgService)
+	skillHandler := handlers.NewSkillHandler(skillService)
+	assignmentHandler := handlers.NewAssignmentHandler(assignmentService)
+	availabilityHandler := handlers.NewAvailabilityHandler(availabilityService)
+	grafanaHandler := handlers.NewGrafanaHandler(grafanaService)
+	alertIngestHandler := handlers.NewAlertIngestHandler(alertIngestService)
+	alertSourceHandler := handlers.NewAlertSourceHandler(alertSourceService)
+	monitorRouteHandler := handlers.NewMonitorRouteHandler(db, monitorRouteService)
+	costHandler := handlers.NewCostHandler(costMonitorService)
+	syntheticHandler := handlers.NewSyntheticHandler(syntheticService)
+	incidentHandler := handlers.NewIncidentHandler(incidentService)
+	postmortemHandler := handlers.NewPostmortemHandler(postmortemService)
+	shareHandler := handlers.NewShareHandler(shareService)
+	diagnosticRequestHandler := handlers.NewDiagnosticRequestHandler(diagnosticRequestService, endpointAgentService, summaryService)
+	endpointAgentHandler := handlers.NewEndpointAgentHandler(endpointAgentService)
+	licenseHandler := handlers.NewLicenseHandler(licenseService)
+	settingsHandler := handlers.NewSettingsHandler(settingsService)
+	featureFlagHandler := handlers.NewFeatureFlagHandler(featureFlagService)
+	policyService := services.NewPolicyService(db)
+	policyHandler := handlers.NewPolicyHandler(policyService)
+	glossaryHandler := handlers.NewGlossaryHandler(glossaryService)
+	backupService, err := services.NewBackupService(context.Background(), db, cfg)
+	if err != nil {
+		log.Printf("Failed to init backup storage: %v", err)
+	}
+	backupHandler := handlers.NewBackupHandler(backupService, settingsService)
+	advisoryHandler := handlers.NewAdvisoryHandler(advisoryService)
+	catalogHandler := handlers.NewCatalogHandler(catalogService)
+	kbHandler := handlers.NewKBHandler(kbService)
+	deflectionHandler := handlers.NewDeflectionHandler(deflectionService)
+	portalHandler := handlers.NewPortalHandler(db, taxonomyService, affinityService, licenseService, commentService, kbService, deflectionService, orgService, assignmentService)
+	deviceHandler := handlers.NewDeviceHandler(pushService)
+	quickActionHandler := handlers.NewQuickActionHandler(quickActionService)
+	reminderService := services.NewReminderService(db, notificationService, pushService)
+	reminderService.Start(context.Background(), cfg.ReminderTickInterval)
+	reminderHandler := handlers.NewReminderHandler(reminderService)
+	calendarService := services.NewCalendarService(db)
+	calendarSyncService, err := services.NewCalendarSyncService(db, cfg, calendarService, availabilityService)
+	if err != nil {
+		log.Printf("Failed to init calendar sync: %v", err)
+	} else if cfg.CalendarSyncEnabled && calendarSyncService.Enabled() {
+		calendarSyncService.Start(context.Background(), cfg.CalendarSyncInterval)
+	}
+	calendarHandler := handlers.NewCalendarHandler(calendarService, calendarSyncService)
+	serviceNowService := services.NewServiceNowService(db, cfg)
+	if serviceNowService.Enabled() && cfg.ServiceNowSyncInterval > 0 {
+		serviceNowService.Start(context.Background(), cfg.ServiceNowSyncInterval)
+	}
+	serviceNowHandler := handlers.NewServiceNowHandler(serviceNowService)
+	issueTrackerService := services.NewIssueTrackerService(db, ticketHistoryService, cfg)
+	if issueTrackerService.Configured() && cfg.IssueSyncInterval > 0 {
+		issueTrackerService.Start(context.Background(), cfg.IssueSyncInterval)
+	}
+	issueLinkHandler := handlers.NewIssueLinkHandler(issueTrackerService)
+	deploymentHandler := handlers.NewDeploymentHandler(deploymentService)
+	customFieldHandler := handlers.NewCustomFieldHandler(customFieldService)
+	changeHandler := handlers.NewChangeHandler(services.NewChangeService(db, taxonomyService, ticketHistoryService, approvalDelegationService))
+	approvalDelegationHandler := handlers.NewApprovalDelegationHandler(approvalDelegationService)
+	ticketLinkHandler := handlers.NewTicketLinkHandler(services.NewTicketLinkService(db, ticketHistoryService, assignmentService, taxonomyService), ticketArchiveService, pushService)
+	ticketMergeHandler := handlers.NewTicketMergeHandler(db, services.NewTicketMergeService(db, ticketHistoryService, commentService))
+	commentHandler := handlers.NewCommentHandler(commentService, ticketArchiveService)
+	worklogHandler := handlers.NewWorklogHandler(worklogService, ticketArchiveService)
+	usageService := services.NewUsageService(db)
+	usageService.Start(context.Background(), cfg.UsageFlushInterval)
+	usageHandler := handlers.NewUsageHandler(usageService)
+	dashboardService := services.NewDashboardService(db, taxonomyService, reminderService, pushService)
+	dashboardHandler := handlers.NewDashboardHandler(dashboardService, pushService)
+
+	networkPolicy, err := middleware.NewNetworkPolicy(cfg.AdminIPAllowlist, cfg.AdminAllowedCountries, cfg.GeoIPCountryHeader)
+	if err != nil {
+		log.Fatal("Invalid admin network policy:", err)
+	}
 
 	// Setup routes
-	r := setupRoutes(authHandler, ticketHandler, aiHandler, docHandler, db, cfg.JWTSecret)
+	r := setupRoutes(authHandler, ticketHandler, aiHandler, docHandler, taxonomyHandler, settingsHandler, featureFlagHandler, featureFlagService, policyHandler, policyService, backupHandler, experimentHandler, evaluationHandler, guardrailHandler, generationHandler, agentHandler, replyHandler, solutionHandler, automationHandler, digestHandler, clusterHandler, forecastHandler, skillHandler, assignmentHandler, grafanaHandler, alertIngestHandler, alertSourceHandler, monitorRouteHandler, costHandler, syntheticHandler, incidentHandler, postmortemHandler, shareHandler, diagnosticRequestHandler, endpointAgentHandler, licenseHandler, advisoryHandler, catalogHandler, kbHandler, deflectionHandler, portalHandler, deviceHandler, quickActionHandler, reminderHandler, calendarHandler, commentHandler, usageHandler, usageService, usageService, dashboardHandler, analyticsHandler, orgHandler, glossaryHandler, serviceNowHandler, issueLinkHandler, ticketMergeHandler, deploymentHandler, ticketLinkHandler, customFieldHandler, ticketParseHandler, worklogHandler, messagingHandler, chatHandler, availabilityHandler, changeHandler, approvalDelegationHandler, db, networkPolicy, middleware.SecurityHeadersMiddleware(cfg.HSTSMaxAge, cfg.ContentSecurityPolicy), middleware.NewRateLimiter(cfg.ChatAnonymousSessionRate, time.Hour), middleware.NewRateLimiter(cfg.ChatAnonymousMessageRate, time.Hour), cfg.TrustedProxies, cfg.JWTSecret, cfg.AlertmanagerToken, cfg.SyntheticWorkerToken, cfg.DeploymentWebhookToken)
+	policyHandler.SetRoutes(r.Routes())
 
 	// Start server
 	port := cfg.Port
@@ -65,17 +306,86 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := r.Run(":" + port); err != nil {
+	if err := startServer(r, cfg, port); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
 
-func setupRoutes(authHandler *handlers.AuthHandler, ticketHandler *handlers.TicketHandler, aiHandler *handlers.AIHandler, docHandler *handlers.DocumentHandler, db *database.MongoDB, jwtSecret string) *gin.Engine {
+// startServer listens with TLS when a certificate or autocert domains are
+// configured, and plain HTTP otherwise
+func startServer(r *gin.Engine, cfg *config.Config, port string) error {
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	switch {
+	case len(cfg.TLSAutocertDomains) > 0:
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.TLSAutocertDomains...),
+			Cache:      autocert.DirCache(cfg.TLSAutocertCacheDir),
+			Email:      cfg.TLSAutocertEmail,
+		}
+		srv.TLSConfig = m.TLSConfig()
+		srv.TLSConfig.MinVersion = tls.VersionTLS12
+		startRedirectServer(cfg.HTTPRedirectPort, m.HTTPHandler(nil))
+		log.Printf("Server starting on port %s with TLS for %v", port, cfg.TLSAutocertDomains)
+		return srv.ListenAndServeTLS("", "")
+	case cfg.TLSCertFile != "" || cfg.TLSKeyFile != "":
+		startRedirectServer(cfg.HTTPRedirectPort, redirectToHTTPS(port))
+		log.Printf("Server starting on port %s with TLS", port)
+		return srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+	default:
+		log.Printf("Server starting on port %s", port)
+		return srv.ListenAndServe()
+	}
+}
+
+func startRedirectServer(port string, handler http.Handler) {
+	if port == "" {
+		return
+	}
+	go func() {
+		srv := &http.Server{Addr: ":" + port, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+		if err := srv.ListenAndServe(); err != nil {
+			log.Printf("HTTP redirect server stopped: %v", err)
+		}
+	}()
+}
+
+// redirectToHTTPS sends plain HTTP requests to the same host on the TLS port
+func redirectToHTTPS(tlsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if tlsPort != "443" {
+			host = net.JoinHostPort(host, tlsPort)
+		}
+		http.Redirect(w, req, "https://"+host+req.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+func setupRoutes(authHandler *handlers.AuthHandler, ticketHandler *handlers.TicketHandler, aiHandler *handlers.AIHandler, docHandler *handlers.DocumentHandler, taxonomyHandler *handlers.TaxonomyHandler, settingsHandler *handlers.SettingsHandler, featureFlagHandler *handlers.FeatureFlagHandler, featureFlags middleware.FeatureFlags, policyHandler *handlers.PolicyHandler, policies middleware.Policies, backupHandler *handlers.BackupHandler, experimentHandler *handlers.ExperimentHandler, evaluationHandler *handlers.EvaluationHandler, guardrailHandler *handlers.GuardrailHandler, generationHandler *handlers.GenerationHandler, agentHandler *handlers.AgentHandler, replyHandler *handlers.ReplyHandler, solutionHandler *handlers.SolutionHandler, automationHandler *handlers.AutomationHandler, digestHandler *handlers.DigestHandler, clusterHandler *handlers.ClusterHandler, forecastHandler *handlers.ForecastHandler, skillHandler *handlers.SkillHandler, assignmentHandler *handlers.AssignmentHandler, grafanaHandler *handlers.GrafanaHandler, alertIngestHandler *handlers.AlertIngestHandler, alertSourceHandler *handlers.AlertSourceHandler, monitorRouteHandler *handlers.MonitorRouteHandler, costHandler *handlers.CostHandler, syntheticHandler *handlers.SyntheticHandler, incidentHandler *handlers.IncidentHandler, postmortemHandler *handlers.PostmortemHandler, shareHandler *handlers.ShareHandler, diagnosticRequestHandler *handlers.DiagnosticRequestHandler, endpointAgentHandler *handlers.EndpointAgentHandler, licenseHandler *handlers.LicenseHandler, advisoryHandler *handlers.AdvisoryHandler, catalogHandler *handlers.CatalogHandler, kbHandler *handlers.KBHandler, deflectionHandler *handlers.DeflectionHandler, portalHandler *handlers.PortalHandler, deviceHandler *handlers.DeviceHandler, quickActionHandler *handlers.QuickActionHandler, reminderHandler *handlers.ReminderHandler, calendarHandler *handlers.CalendarHandler, commentHandler *handlers.CommentHandler, usageHandler *handlers.UsageHandler, usage middleware.Usage, quotas middleware.Quotas, dashboardHandler *handlers.DashboardHandler, analyticsHandler *handlers.AnalyticsHandler, orgHandler *handlers.OrgHandler, glossaryHandler *handlers.GlossaryHandler, serviceNowHandler *handlers.ServiceNowHandler, issueLinkHandler *handlers.IssueLinkHandler, ticketMergeHandler *handlers.TicketMergeHandler, deploymentHandler *handlers.DeploymentHandler, ticketLinkHandler *handlers.TicketLinkHandler, customFieldHandler *handlers.CustomFieldHandler, ticketParseHandler *handlers.TicketParseHandler, worklogHandler *handlers.WorklogHandler, messagingHandler *handlers.MessagingHandler, chatHandler *handlers.ChatHandler, availabilityHandler *handlers.AvailabilityHandler, changeHandler *handlers.ChangeHandler, approvalDelegationHandler *handlers.ApprovalDelegationHandler, db *database.MongoDB, networkPolicy *middleware.NetworkPolicy, securityHeaders gin.HandlerFunc, chatSessionLimiter, chatMessageLimiter *middleware.RateLimiter, trustedProxies []string, jwtSecret, alertmanagerToken, syntheticWorkerToken, deploymentWebhookToken string) *gin.Engine {
 	r := gin.Default()
 
+	// Only trust X-Forwarded-For from configured proxies so client IPs used
+	// by the admin allow-list cannot be spoofed
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatal("Invalid trusted proxies:", err)
+	}
+	restrictNetwork := middleware.NetworkPolicyMiddleware(db, networkPolicy)
+	// Role checks of every authenticated route come from the policy rules
+	authorize := middleware.PolicyMiddleware(policies)
+
 	// Middleware
+	r.Use(securityHeaders)
 	r.Use(middleware.CORSMiddleware())
+	r.Use(middleware.UsageMiddleware(usage))
 
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
@@ -85,65 +395,512 @@ func setupRoutes(authHandler *handlers.AuthHandler, ticketHandler *handlers.Tick
 	// API routes
 	api := r.Group("/api")
 	{
+		// Public status page
+		api.GET("/status", incidentHandler.GetPublicStatus)
+
+		// Read-only ticket share links for external vendors
+		api.GET("/public/tickets/:token", shareHandler.GetSharedTicket)
+
+		// ICS calendar feeds, for calendar apps that cannot sign in
+		api.GET("/public/calendar/:token", calendarHandler.GetFeedICS)
+
+		// Diagnostic data uploads from requesters or endpoint agents
+		api.GET("/public/diagnostics/:token", diagnosticRequestHandler.GetDiagnosticRequest)
+		api.POST("/public/diagnostics/:token", diagnosticRequestHandler.SubmitDiagnostics)
+
+		// Embeddable support chat for visitors who are not signed in,
+		// authenticated with the session's X-Chat-Token. New sessions and
+		// messages, which call the LLM, are limited per IP instead of by
+		// user quota
+		publicChat := api.Group("/public/chat/sessions")
+		{
+			publicChat.POST("", middleware.RateLimitMiddleware(chatSessionLimiter), chatHandler.StartChat)
+			publicChat.GET("/:id", chatHandler.GetChat)
+			publicChat.POST("/:id/messages", middleware.RateLimitMiddleware(chatMessageLimiter), chatHandler.SendChatMessage)
+			publicChat.POST("/:id/escalate", chatHandler.EscalateChat)
+			publicChat.POST("/:id/close", chatHandler.CloseChat)
+		}
+
+		// Endpoint agent check-in, authenticated with the agent's token
+		api.POST("/endpoint-agents/:id/checkin", endpointAgentHandler.CheckIn)
+
+		// One-click ticket actions from notification emails
+		api.GET("/actions/:token", quickActionHandler.ConfirmQuickAction)
+		api.POST("/actions/:token", quickActionHandler.RunQuickAction)
+
 		// Auth routes
 		auth := api.Group("/auth")
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
-			auth.GET("/profile", middleware.AuthMiddleware(db, jwtSecret), authHandler.GetProfile)
+			auth.GET("/profile", middleware.AuthMiddleware(db, jwtSecret), authorize, authHandler.GetProfile)
+			auth.GET("/feature-flags", middleware.AuthMiddleware(db, jwtSecret), authorize, featureFlagHandler.GetMyFeatureFlags)
 		}
 
 		// Ticket routes
 		tickets := api.Group("/tickets")
-		tickets.Use(middleware.AuthMiddleware(db, jwtSecret))
+		tickets.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
 		{
 			tickets.GET("", ticketHandler.GetTickets)
 			tickets.GET("/:id", ticketHandler.GetTicket)
+			tickets.GET("/archive", ticketHandler.GetArchivedTickets)
+			tickets.GET("/tags", ticketHandler.ListTicketTags)
 			tickets.POST("", ticketHandler.CreateTicket)
+			tickets.POST("/servicenow/export", serviceNowHandler.ExportServiceNowIncidents)
+			tickets.POST("/servicenow/push", serviceNowHandler.PushServiceNowIncidents)
 			tickets.PUT("/:id", ticketHandler.UpdateTicket)
 			tickets.DELETE("/:id", ticketHandler.DeleteTicket)
-			tickets.GET("/:id/solutions", docHandler.GetTicketSolutions) // New route for solutions
+			tickets.GET("/:id/history", ticketHandler.GetTicketHistory)
+			tickets.POST("/:id/tags", ticketHandler.AddTicketTags)
+			tickets.DELETE("/:id/tags/:tag", ticketHandler.RemoveTicketTag)
+			tickets.GET("/:id/comments", commentHandler.ListComments)
+			tickets.POST("/:id/comments", commentHandler.AddComment)
+			tickets.GET("/:id/worklogs", worklogHandler.ListWorklogs)
+			tickets.POST("/:id/worklogs", worklogHandler.AddWorklog)
+			tickets.PUT("/:id/worklogs/:worklogId", worklogHandler.UpdateWorklog)
+			tickets.DELETE("/:id/worklogs/:worklogId", worklogHandler.DeleteWorklog)
+			tickets.GET("/:id/messages", messagingHandler.ListTicketMessages)
+			tickets.POST("/:id/attachments", ticketHandler.UploadAttachment)
+			tickets.GET("/:id/attachments", ticketHandler.ListAttachments)
+			tickets.GET("/:id/attachments/:attachmentId/download", ticketHandler.DownloadAttachment)
+			tickets.DELETE("/:id/attachments/:attachmentId", ticketHandler.DeleteAttachment)
+			tickets.GET("/:id/solutions", middleware.QuotaMiddleware(quotas, services.QuotaSolutionGeneration), docHandler.GetTicketSolutions) // New route for solutions
+			tickets.POST("/:id/solutions/refine", middleware.QuotaMiddleware(quotas, services.QuotaSolutionGeneration), solutionHandler.RefineSolutions)
+			tickets.GET("/:id/solutions/history", solutionHandler.GetSolutionHistory)
+			tickets.PATCH("/:id/solution-steps", solutionHandler.UpdateSolutionStep)
+			tickets.POST("/:id/automations", automationHandler.RequestAutomation)
+			tickets.GET("/:id/automations", automationHandler.ListTicketAutomations)
+			tickets.POST("/:id/diagnose", middleware.FeatureFlagMiddleware(featureFlags, services.FlagAgentMode), agentHandler.DiagnoseTicket)
+			tickets.POST("/:id/summarize", ticketHandler.SummarizeTicket)
+			tickets.GET("/:id/monitoring-context", ticketHandler.GetMonitoringContext)
+			tickets.POST("/:id/reminders", reminderHandler.CreateReminder)
+			tickets.GET("/:id/reminders", reminderHandler.ListReminders)
+			tickets.PUT("/:id/reminders/:reminderId", reminderHandler.UpdateReminder)
+			tickets.DELETE("/:id/reminders/:reminderId", reminderHandler.DeleteReminder)
+			tickets.POST("/:id/reply-drafts", replyHandler.DraftReply)
+			tickets.GET("/:id/reply-drafts", replyHandler.ListReplyDrafts)
+			tickets.POST("/:id/reply-drafts/:draftId/accept", replyHandler.AcceptReplyDraft)
+			tickets.POST("/:id/reply-drafts/:draftId/discard", replyHandler.DiscardReplyDraft)
+			tickets.GET("/:id/diagnostics", agentHandler.GetTicketDiagnostics)
+			tickets.POST("/:id/declare-incident", incidentHandler.DeclareTicketIncident)
+			tickets.GET("/:id/share-links", shareHandler.ListShareLinks)
+			tickets.POST("/:id/share-links", shareHandler.CreateShareLink)
+			tickets.DELETE("/:id/share-links/:linkId", shareHandler.RevokeShareLink)
+			tickets.GET("/:id/share-links/:linkId/audit", shareHandler.GetShareLinkAudit)
+			tickets.POST("/:id/diagnostic-requests", diagnosticRequestHandler.CreateDiagnosticRequest)
+			tickets.GET("/:id/diagnostic-requests", diagnosticRequestHandler.ListDiagnosticRequests)
+			tickets.PUT("/:id/endpoint-agent", endpointAgentHandler.LinkTicketEndpointAgent)
+			tickets.PUT("/:id/license", licenseHandler.LinkTicketLicense)
+			tickets.POST("/:id/issues", issueLinkHandler.LinkIssue)
+			tickets.POST("/:id/issues/create", issueLinkHandler.CreateIssue)
+			tickets.DELETE("/:id/issues", issueLinkHandler.UnlinkIssue)
+			tickets.POST("/:id/merge", ticketMergeHandler.MergeTicket)
+			tickets.GET("/:id/links", ticketLinkHandler.ListTicketLinks)
+			tickets.POST("/:id/links", ticketLinkHandler.CreateTicketLink)
+			tickets.DELETE("/:id/links/:linkedId", ticketLinkHandler.DeleteTicketLink)
+			tickets.GET("/:id/subtasks", ticketLinkHandler.GetSubtasks)
+			tickets.POST("/:id/subtasks", ticketLinkHandler.SplitTicket)
+			tickets.PATCH("/:id/fulfillment-steps", catalogHandler.UpdateFulfillmentStep)
+			tickets.POST("/preflight", deflectionHandler.Preflight)
+			tickets.POST("/preflight/:id/resolved", deflectionHandler.SelfResolve)
+		}
+
+		// Requester portal: end users raise and follow their own tickets
+		portal := api.Group("/portal")
+		portal.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			portal.GET("/tickets", portalHandler.ListMyTickets)
+			portal.POST("/tickets", portalHandler.CreateMyTicket)
+			portal.POST("/tickets/preflight", deflectionHandler.Preflight)
+			portal.POST("/tickets/preflight/:id/resolved", deflectionHandler.SelfResolve)
+			portal.GET("/tickets/:id", middleware.OwnTicketMiddleware(db), portalHandler.GetMyTicket)
+			portal.POST("/tickets/:id/comments", middleware.OwnTicketMiddleware(db), portalHandler.AddMyComment)
+			portal.POST("/tickets/:id/rating", middleware.OwnTicketMiddleware(db), portalHandler.RateMyTicket)
+			portal.POST("/kb/suggestions", kbHandler.SuggestArticles)
+			portal.GET("/kb/articles/:id", kbHandler.GetPublicArticle)
+			portal.GET("/messaging", messagingHandler.GetMessagingSubscription)
+			portal.PUT("/messaging", messagingHandler.SetMessagingSubscription)
+			portal.DELETE("/messaging", messagingHandler.DeleteMessagingSubscription)
+			portal.POST("/chat/sessions", chatHandler.StartChat)
+			portal.GET("/chat/sessions/:id", chatHandler.GetChat)
+			portal.POST("/chat/sessions/:id/messages", middleware.QuotaMiddleware(quotas, services.QuotaSolutionGeneration), chatHandler.SendChatMessage)
+			portal.POST("/chat/sessions/:id/escalate", chatHandler.EscalateChat)
+			portal.POST("/chat/sessions/:id/close", chatHandler.CloseChat)
+		}
+
+		// Mobile push device registration
+		devices := api.Group("/devices")
+		devices.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			devices.GET("", deviceHandler.ListDevices)
+			devices.POST("", deviceHandler.RegisterDevice)
+			devices.DELETE("/:id", deviceHandler.UnregisterDevice)
+			devices.POST("/test", deviceHandler.TestPush)
+		}
+
+		// Endpoint agent fleet view
+		endpointAgents := api.Group("/endpoint-agents")
+		endpointAgents.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			endpointAgents.GET("", endpointAgentHandler.ListEndpointAgents)
+			endpointAgents.GET("/summary", endpointAgentHandler.GetFleetSummary)
+			endpointAgents.GET("/:id", endpointAgentHandler.GetEndpointAgent)
+		}
+
+		// Service catalog: anyone signed in, requesters included, may request
+		// items; approvers decide on the requests waiting for them
+		catalog := api.Group("/catalog")
+		catalog.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			catalog.GET("", catalogHandler.ListCatalogItems)
+			catalog.GET("/:id", catalogHandler.GetCatalogItem)
+			catalog.POST("/:id/requests", catalogHandler.RequestCatalogItem)
+		}
+		// Approvers delegate their approvals for a date range, such as a
+		// vacation, and their delegates see and decide them meanwhile
+		approvals := api.Group("/approvals")
+		approvals.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			approvals.GET("", catalogHandler.ListPendingApprovals)
+			approvals.POST("/:id", catalogHandler.DecideApproval)
+			approvals.GET("/delegations", approvalDelegationHandler.ListApprovalDelegations)
+			approvals.POST("/delegations", approvalDelegationHandler.CreateApprovalDelegation)
+			approvals.DELETE("/delegations/:delegationId", approvalDelegationHandler.DeleteApprovalDelegation)
+		}
+
+		// Changes are scheduled against maintenance windows and each other,
+		// and approved by admins once their schedule is clear
+		changes := api.Group("/changes")
+		changes.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			changes.GET("", changeHandler.ListChanges)
+			changes.POST("", changeHandler.CreateChange)
+			changes.PUT("/:id/schedule", changeHandler.RescheduleChange)
+			changes.GET("/:id/conflicts", changeHandler.GetChangeConflicts)
+			changes.POST("/:id/approval", changeHandler.DecideChange)
+		}
+
+		// Software license inventory
+		licenses := api.Group("/licenses")
+		licenses.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			licenses.GET("", licenseHandler.ListLicenses)
+			licenses.GET("/:id", licenseHandler.GetLicense)
+		}
+
+		// Vulnerability advisories matched against the inventory
+		advisories := api.Group("/security/advisories")
+		advisories.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			advisories.GET("", advisoryHandler.ListAdvisories)
+			advisories.GET("/:id", advisoryHandler.GetAdvisory)
+		}
+
+		// Major incident command
+		incidents := api.Group("/incidents")
+		incidents.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			incidents.GET("", incidentHandler.ListIncidents)
+			incidents.POST("", incidentHandler.DeclareIncident)
+			incidents.GET("/:id", incidentHandler.GetIncident)
+			incidents.POST("/:id/updates", incidentHandler.UpdateIncident)
+			incidents.POST("/:id/notes", incidentHandler.AddIncidentNote)
+			incidents.PUT("/:id/roles", incidentHandler.AssignIncidentRole)
+			incidents.POST("/:id/postmortem", postmortemHandler.DraftPostmortem)
+		}
+
+		// Postmortems and their tracked action items
+		postmortems := api.Group("/postmortems")
+		postmortems.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			postmortems.GET("", postmortemHandler.ListPostmortems)
+			postmortems.GET("/:id", postmortemHandler.GetPostmortem)
+			postmortems.PUT("/:id", postmortemHandler.UpdatePostmortem)
+			postmortems.POST("/:id/action-items", postmortemHandler.AddActionItem)
 		}
 
 		// AI routes
 		ai := api.Group("/ai")
-		ai.Use(middleware.AuthMiddleware(db, jwtSecret))
+		ai.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
 		{
-			ai.POST("/triage", aiHandler.TriageTicket)
+			ai.POST("/triage", middleware.FeatureFlagMiddleware(featureFlags, services.FlagAutoTriage), middleware.QuotaMiddleware(quotas, services.QuotaAITriage), aiHandler.TriageTicket)
 			ai.GET("/technicians", aiHandler.GetTechnicians)
+			ai.POST("/triage/sandbox", middleware.QuotaMiddleware(quotas, services.QuotaAITriage), aiHandler.TriageSandbox)
+			ai.POST("/triage/feedback", experimentHandler.SubmitTriageFeedback)
+			ai.POST("/ask-analytics", analyticsHandler.AskAnalytics)
+			ai.POST("/parse-ticket", middleware.QuotaMiddleware(quotas, services.QuotaAITriage), ticketParseHandler.ParseTicket)
+			ai.GET("/agent/tools", middleware.FeatureFlagMiddleware(featureFlags, services.FlagAgentMode), agentHandler.GetAgentTools)
+		}
+
+		// Knowledge base: staff write articles, admins publish them
+		kb := api.Group("/kb")
+		kb.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			kb.GET("/articles", kbHandler.ListArticles)
+			kb.POST("/articles", kbHandler.CreateArticle)
+			kb.GET("/articles/:id", kbHandler.GetArticle)
+			kb.PUT("/articles/:id", kbHandler.UpdateArticle)
+			kb.GET("/articles/:id/versions", kbHandler.ListArticleVersions)
+			kb.GET("/articles/:id/versions/:version", kbHandler.GetArticleVersion)
+			kb.POST("/suggestions", kbHandler.SuggestArticles)
 		}
 
 		// Document routes
 		docs := api.Group("/docs")
-		docs.Use(middleware.AuthMiddleware(db, jwtSecret))
+		docs.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
 		{
 			docs.POST("/index", docHandler.IndexDocuments)
 			docs.POST("/search", docHandler.SearchDocuments)
+			docs.POST("/search/:id/click", docHandler.RecordSearchClick)
+			docs.POST("/search/preview", docHandler.PreviewSearchQuery)
+			docs.GET("/search-analytics", docHandler.GetSearchAnalytics)
 			docs.POST("/upload", docHandler.UploadDocument)
 			docs.GET("/stats", docHandler.GetIndexStats)
+			docs.GET("/tree", docHandler.GetDocumentTree)
+			docs.GET("/stale", docHandler.GetStaleDocuments)
+			docs.POST("/:id/review", docHandler.ReviewDocument)
+			docs.GET("/approvals", docHandler.ListDocumentApprovals)
+			docs.POST("/:id/submit", docHandler.SubmitDocument)
+			docs.POST("/:id/approve", docHandler.ApproveDocument)
+			docs.POST("/:id/reject", docHandler.RejectDocument)
+		}
+
+		// Grafana SimpleJSON datasource; configure the datasource to send a
+		// bearer token
+		grafana := api.Group("/grafana")
+		grafana.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			grafana.GET("/", grafanaHandler.TestConnection)
+			grafana.POST("/search", grafanaHandler.Search)
+			grafana.POST("/query", grafanaHandler.Query)
+		}
+
+		// External alert ingestion, authenticated with a shared token or the
+		// alert source's own token
+		api.POST("/monitor/ingest/alertmanager", restrictNetwork, middleware.WebhookTokenMiddleware(alertmanagerToken), alertIngestHandler.IngestAlertmanager)
+		api.POST("/monitor/ingest/webhooks/:slug", restrictNetwork, alertSourceHandler.IngestWebhook)
+
+		// Regional synthetic check workers
+		syntheticWorkers := api.Group("/synthetic/worker")
+		syntheticWorkers.Use(restrictNetwork, middleware.WebhookTokenMiddleware(syntheticWorkerToken))
+		{
+			syntheticWorkers.GET("/checks", syntheticHandler.WorkerChecks)
+			syntheticWorkers.POST("/results", syntheticHandler.ReportResults)
+		}
+
+		// Twilio SMS and WhatsApp messages, checked by their signature
+		api.POST("/messaging/twilio", messagingHandler.TwilioWebhook)
+
+		// Deployment events from CI/CD pipelines, correlated with new tickets
+		api.POST("/deployments/ingest", restrictNetwork, middleware.WebhookTokenMiddleware(deploymentWebhookToken), deploymentHandler.IngestDeployment)
+		api.GET("/deployments", middleware.AuthMiddleware(db, jwtSecret), authorize, deploymentHandler.ListDeployments)
+
+		// Personal agenda of ticket follow-up reminders
+		api.GET("/agenda", middleware.AuthMiddleware(db, jwtSecret), authorize, reminderHandler.GetAgenda)
+
+		// The signed-in technician's day, for the mobile home screen
+		me := api.Group("/me")
+		me.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			me.GET("/dashboard", dashboardHandler.GetMyDashboard)
+			me.POST("/notifications/read", dashboardHandler.MarkNotificationsRead)
+			me.GET("/availability", availabilityHandler.GetMyAvailability)
+			me.PUT("/working-hours", availabilityHandler.SetWorkingHours)
+			me.POST("/out-of-office", availabilityHandler.AddOutOfOffice)
+			me.DELETE("/out-of-office/:oooId", availabilityHandler.DeleteOutOfOffice)
 		}
 
+		// Technicians' working hours, time off and who is on call
+		api.GET("/availability", middleware.AuthMiddleware(db, jwtSecret), authorize, availabilityHandler.GetAvailability)
+
+		// Scheduled work as calendar events and personal ICS feeds
+		calendar := api.Group("/calendar")
+		calendar.Use(middleware.AuthMiddleware(db, jwtSecret), authorize)
+		{
+			calendar.GET("/events", calendarHandler.GetCalendarEvents)
+			calendar.GET("/feed", calendarHandler.GetCalendarFeed)
+			calendar.POST("/feed/rotate", calendarHandler.RotateCalendarFeed)
+			calendar.DELETE("/feed", calendarHandler.RevokeCalendarFeed)
+		}
+
+		// Taxonomy (categories and priorities) is readable by every user
+		api.GET("/taxonomy", middleware.AuthMiddleware(db, jwtSecret), authorize, taxonomyHandler.GetTaxonomy)
+
+		// Custom ticket fields, for the ticket forms
+		api.GET("/custom-fields", middleware.AuthMiddleware(db, jwtSecret), authorize, customFieldHandler.ListCustomFields)
+
 		// Admin routes
 		admin := api.Group("/admin")
-		admin.Use(middleware.AuthMiddleware(db, jwtSecret), middleware.AdminMiddleware())
+		admin.Use(restrictNetwork, middleware.AuthMiddleware(db, jwtSecret), authorize)
 		{
 			admin.GET("/users", authHandler.GetAllUsers)
 			admin.POST("/users", authHandler.CreateUser)
 			admin.PUT("/users/:id", authHandler.UpdateUser)
 			admin.DELETE("/users/:id", authHandler.DeleteUser)
 			admin.GET("/stats", authHandler.GetSystemStats)
+			admin.GET("/access-denials", authHandler.GetAccessDenials)
+			admin.PUT("/taxonomy", taxonomyHandler.UpdateTaxonomy)
+			admin.POST("/taxonomy/reset", taxonomyHandler.ResetTaxonomy)
+			admin.PUT("/custom-fields/:key", customFieldHandler.SaveCustomField)
+			admin.DELETE("/custom-fields/:key", customFieldHandler.DeleteCustomField)
+			admin.GET("/settings", settingsHandler.GetSettings)
+			admin.PUT("/settings", settingsHandler.UpdateSettings)
+			admin.GET("/settings/audit", settingsHandler.GetSettingsAudit)
+			admin.GET("/feature-flags", featureFlagHandler.ListFeatureFlags)
+			admin.PUT("/feature-flags/:key", featureFlagHandler.UpdateFeatureFlag)
+			admin.DELETE("/feature-flags/:key", featureFlagHandler.DeleteFeatureFlag)
+			admin.GET("/policies", policyHandler.ListPolicyRules)
+			admin.PUT("/policies/:id", policyHandler.UpdatePolicyRule)
+			admin.DELETE("/policies/:id", policyHandler.DeletePolicyRule)
+			admin.POST("/policies/simulate", policyHandler.SimulatePolicy)
+			admin.POST("/backup", backupHandler.CreateBackup)
+			admin.GET("/backups", backupHandler.ListBackups)
+			admin.POST("/restore", backupHandler.RestoreBackup)
+			admin.POST("/tickets/archive", ticketHandler.ArchiveTickets)
+			admin.GET("/tickets/deleted", ticketHandler.GetDeletedTickets)
+			admin.POST("/tickets/:id/restore", ticketHandler.RestoreTicket)
+			admin.DELETE("/tickets/deleted/:id", ticketHandler.PurgeTicket)
+			admin.POST("/tickets/import", ticketHandler.ImportTickets)
+			admin.POST("/calendar/sync", calendarHandler.SyncCalendars)
+			admin.POST("/servicenow/sync", serviceNowHandler.SyncServiceNow)
+			admin.POST("/issues/sync", issueLinkHandler.SyncIssues)
+			admin.GET("/usage", usageHandler.GetUsage)
+			admin.GET("/quotas", usageHandler.ListQuotas)
+			admin.PUT("/quotas/:key", usageHandler.UpdateQuota)
+			admin.DELETE("/quotas/:key", usageHandler.DeleteQuota)
+			admin.GET("/prompts", aiHandler.ListPromptTemplates)
+			admin.POST("/prompts", aiHandler.CreatePromptTemplate)
+			admin.GET("/experiments", experimentHandler.ListExperiments)
+			admin.POST("/experiments", experimentHandler.CreateExperiment)
+			admin.POST("/experiments/:id/start", experimentHandler.StartExperiment)
+			admin.POST("/experiments/:id/stop", experimentHandler.StopExperiment)
+			admin.DELETE("/experiments/:id", experimentHandler.DeleteExperiment)
+			admin.GET("/experiments/:id/results", experimentHandler.GetExperimentResults)
+			admin.POST("/evaluations/datasets", evaluationHandler.CreateDataset)
+			admin.GET("/evaluations/datasets", evaluationHandler.ListDatasets)
+			admin.DELETE("/evaluations/datasets/:id", evaluationHandler.DeleteDataset)
+			admin.POST("/evaluations/datasets/:id/run", evaluationHandler.RunEvaluation)
+			admin.GET("/evaluations/runs", evaluationHandler.ListRuns)
+			admin.GET("/evaluations/runs/:id", evaluationHandler.GetRun)
+			admin.GET("/guardrails/injections", guardrailHandler.ListInjectionEvents)
+			admin.GET("/ai/generation", generationHandler.ListGenerationConfig)
+			admin.PUT("/ai/generation/:endpoint", generationHandler.UpdateGenerationConfig)
+			admin.DELETE("/ai/generation/:endpoint", generationHandler.ResetGenerationConfig)
+			admin.GET("/runbooks", automationHandler.ListRunbooks)
+			admin.POST("/runbooks", automationHandler.CreateRunbook)
+			admin.PUT("/runbooks/:id", automationHandler.UpdateRunbook)
+			admin.DELETE("/runbooks/:id", automationHandler.DeleteRunbook)
+			admin.GET("/automations", automationHandler.ListAutomationRuns)
+			admin.POST("/automations/:id/approve", automationHandler.ApproveAutomation)
+			admin.POST("/automations/:id/reject", automationHandler.RejectAutomation)
+			admin.GET("/replies/stats", replyHandler.GetReplyStats)
+			admin.GET("/digests", digestHandler.ListDigests)
+			admin.POST("/digests", digestHandler.GenerateDigest)
+			admin.GET("/digests/:id", digestHandler.GetDigest)
+			admin.GET("/clusters", clusterHandler.ListClusters)
+			admin.POST("/clusters/run", clusterHandler.RunClustering)
+			admin.GET("/forecast", forecastHandler.GetForecast)
+			admin.GET("/analytics/tickets/heatmap", analyticsHandler.GetTicketHeatmap)
+			admin.GET("/analytics/tickets/locations", analyticsHandler.GetTicketLocations)
+			admin.GET("/analytics/tickets/departments", analyticsHandler.GetTicketDepartments)
+			admin.POST("/analytics/query", analyticsHandler.RunAnalyticsQuery)
+			admin.GET("/org/units", orgHandler.ListOrgUnits)
+			admin.POST("/org/units", orgHandler.CreateOrgUnit)
+			admin.PUT("/org/units/:id", orgHandler.UpdateOrgUnit)
+			admin.DELETE("/org/units/:id", orgHandler.DeleteOrgUnit)
+			admin.GET("/ticket-routes", orgHandler.ListTicketRoutes)
+			admin.POST("/ticket-routes", orgHandler.CreateTicketRoute)
+			admin.GET("/ticket-routes/preview", orgHandler.PreviewTicketRoute)
+			admin.PUT("/ticket-routes/:id", orgHandler.UpdateTicketRoute)
+			admin.DELETE("/ticket-routes/:id", orgHandler.DeleteTicketRoute)
+			admin.GET("/skills", skillHandler.ListSkillProfiles)
+			admin.GET("/skills/ranking", skillHandler.RankTechnicians)
+			admin.POST("/skills/recompute", skillHandler.RecomputeSkills)
+			admin.GET("/skills/:id", skillHandler.GetSkillProfile)
+			admin.PUT("/skills/:id/overrides", skillHandler.SetSkillOverride)
+			admin.DELETE("/skills/:id/overrides", skillHandler.RemoveSkillOverride)
+			admin.GET("/assignment-rules", assignmentHandler.ListAssignmentRules)
+			admin.PUT("/assignment-rules/:category", assignmentHandler.UpdateAssignmentRule)
+			admin.DELETE("/assignment-rules/:category", assignmentHandler.DeleteAssignmentRule)
+			admin.PUT("/users/:id/working-hours", availabilityHandler.SetWorkingHours)
+			admin.POST("/users/:id/out-of-office", availabilityHandler.AddOutOfOffice)
+			admin.DELETE("/users/:id/out-of-office/:oooId", availabilityHandler.DeleteOutOfOffice)
+			admin.GET("/oncall", availabilityHandler.GetOnCallRotation)
+			admin.PUT("/oncall", availabilityHandler.UpdateOnCallRotation)
+			admin.GET("/status/components", incidentHandler.ListStatusComponents)
+			admin.POST("/status/components", incidentHandler.CreateStatusComponent)
+			admin.PUT("/status/components/:id", incidentHandler.UpdateStatusComponent)
+			admin.DELETE("/status/components/:id", incidentHandler.DeleteStatusComponent)
+			admin.GET("/postmortem-templates", postmortemHandler.ListTemplates)
+			admin.POST("/postmortem-templates", postmortemHandler.CreateTemplate)
+			admin.PUT("/postmortem-templates/:id", postmortemHandler.UpdateTemplate)
+			admin.DELETE("/postmortem-templates/:id", postmortemHandler.DeleteTemplate)
 
 			// Monitoring admin
-			mon := handlers.NewMonitorHandler(db)
+			mon := handlers.NewMonitorHandler(db, services.NewTaxonomyService(db))
 			admin.POST("/monitor/resources", mon.CreateResource)
 			admin.GET("/monitor/resources", mon.ListResources)
 			admin.PUT("/monitor/resources/:id", mon.UpdateResource)
 			admin.DELETE("/monitor/resources/:id", mon.DeleteResource)
+			admin.GET("/monitor/resources/:id/effective-metrics", mon.EffectiveMetrics)
 			admin.POST("/monitor/metrics", mon.CreateMetric)
 			admin.GET("/monitor/metrics", mon.ListMetrics)
 			admin.PUT("/monitor/metrics/:id", mon.UpdateMetric)
 			admin.DELETE("/monitor/metrics/:id", mon.DeleteMetric)
 			admin.GET("/monitor/anomalies", mon.ListAnomalies)
+			templates := handlers.NewMetricTemplateHandler(services.NewMetricTemplateService(db), services.NewTaxonomyService(db))
+			admin.GET("/monitor/metric-templates", templates.ListMetricTemplates)
+			admin.POST("/monitor/metric-templates", templates.CreateMetricTemplate)
+			admin.PUT("/monitor/metric-templates/:id", templates.UpdateMetricTemplate)
+			admin.DELETE("/monitor/metric-templates/:id", templates.DeleteMetricTemplate)
+			maintenance := handlers.NewMaintenanceHandler(services.NewMaintenanceService(db))
+			admin.GET("/monitor/maintenance-windows", maintenance.ListMaintenanceWindows)
+			admin.POST("/monitor/maintenance-windows", maintenance.CreateMaintenanceWindow)
+			admin.PUT("/monitor/maintenance-windows/:id", maintenance.UpdateMaintenanceWindow)
+			admin.DELETE("/monitor/maintenance-windows/:id", maintenance.DeleteMaintenanceWindow)
+			admin.GET("/monitor/export", mon.ExportConfig)
+			admin.GET("/monitor/alert-sources", alertSourceHandler.ListAlertSources)
+			admin.POST("/monitor/alert-sources", alertSourceHandler.CreateAlertSource)
+			admin.PUT("/monitor/alert-sources/:id", alertSourceHandler.UpdateAlertSource)
+			admin.DELETE("/monitor/alert-sources/:id", alertSourceHandler.DeleteAlertSource)
+			admin.POST("/monitor/alert-sources/:id/rotate-token", alertSourceHandler.RotateAlertSourceToken)
+			admin.POST("/monitor/alert-sources/:id/test", alertSourceHandler.TestAlertSource)
+			admin.GET("/monitor/routes", monitorRouteHandler.ListMonitorRoutes)
+			admin.POST("/monitor/routes", monitorRouteHandler.CreateMonitorRoute)
+			admin.POST("/monitor/routes/preview", monitorRouteHandler.PreviewMonitorRoutes)
+			admin.PUT("/monitor/routes/:id", monitorRouteHandler.UpdateMonitorRoute)
+			admin.DELETE("/monitor/routes/:id", monitorRouteHandler.DeleteMonitorRoute)
+			admin.GET("/monitor/costs", costHandler.GetCosts)
+			admin.POST("/monitor/costs/check", costHandler.CheckCosts)
+			admin.GET("/monitor/synthetic-checks", syntheticHandler.ListChecks)
+			admin.POST("/monitor/synthetic-checks", syntheticHandler.CreateCheck)
+			admin.GET("/monitor/synthetic-checks/:id", syntheticHandler.GetCheck)
+			admin.PUT("/monitor/synthetic-checks/:id", syntheticHandler.UpdateCheck)
+			admin.DELETE("/monitor/synthetic-checks/:id", syntheticHandler.DeleteCheck)
+			admin.POST("/monitor/synthetic-checks/:id/run", syntheticHandler.RunCheck)
+			admin.GET("/monitor/synthetic-checks/:id/results", syntheticHandler.GetCheckResults)
+			admin.POST("/endpoint-agents", endpointAgentHandler.RegisterEndpointAgent)
+			admin.PUT("/endpoint-agents/:id", endpointAgentHandler.UpdateEndpointAgent)
+			admin.DELETE("/endpoint-agents/:id", endpointAgentHandler.DeleteEndpointAgent)
+			admin.POST("/endpoint-agents/:id/rotate-token", endpointAgentHandler.RotateEndpointAgentToken)
+			admin.POST("/licenses", licenseHandler.CreateLicense)
+			admin.PUT("/licenses/:id", licenseHandler.UpdateLicense)
+			admin.DELETE("/licenses/:id", licenseHandler.DeleteLicense)
+			admin.PUT("/documents/:id/owner", docHandler.SetDocumentOwner)
+			admin.POST("/documents/reviews/run", docHandler.RunDocumentReviews)
+			admin.GET("/glossary", glossaryHandler.ListGlossary)
+			admin.PUT("/glossary/:term", glossaryHandler.UpdateGlossaryTerm)
+			admin.DELETE("/glossary/:term", glossaryHandler.DeleteGlossaryTerm)
+			admin.POST("/security/advisories/sync", advisoryHandler.SyncAdvisories)
+			admin.POST("/security/advisories/import", advisoryHandler.ImportAdvisories)
+			admin.POST("/catalog", catalogHandler.CreateCatalogItem)
+			admin.PUT("/catalog/:id", catalogHandler.UpdateCatalogItem)
+			admin.DELETE("/catalog/:id", catalogHandler.DeleteCatalogItem)
+			admin.POST("/kb/articles/:id/publish", kbHandler.PublishArticle)
+			admin.POST("/kb/articles/:id/archive", kbHandler.ArchiveArticle)
+			admin.DELETE("/kb/articles/:id", kbHandler.DeleteArticle)
+			admin.GET("/deflection/stats", deflectionHandler.GetDeflectionStats)
+			admin.POST("/monitor/import", mon.ImportConfig)
 		}
 	}
 
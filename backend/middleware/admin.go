@@ -1,29 +0,0 @@
-package middleware
-
-import (
-	"net/http"
-
-	"github.com/gin-gonic/gin"
-	"intelliops-ai-copilot/models"
-)
-
-// AdminMiddleware checks if the authenticated user has admin role
-func AdminMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		user, exists := c.Get("user")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-			c.Abort()
-			return
-		}
-
-		userModel := user.(models.User)
-		if userModel.Role != models.RoleAdmin {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
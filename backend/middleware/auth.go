@@ -68,26 +68,6 @@ func AuthMiddleware(db *database.MongoDB, jwtSecret string) gin.HandlerFunc {
 	}
 }
 
-func RequireRole(role models.UserRole) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		user, exists := c.Get("user")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-			c.Abort()
-			return
-		}
-
-		userModel := user.(models.User)
-		if userModel.Role != role && userModel.Role != models.RoleAdmin {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
-
 func GenerateToken(user models.User, jwtSecret string, expiresIn time.Duration) (string, error) {
 	claims := &Claims{
 		UserID: user.ID,
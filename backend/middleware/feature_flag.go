@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+)
+
+// FeatureFlags evaluates feature flags for a user, nil when the request has
+// none.
+type FeatureFlags interface {
+	EnabledFor(ctx context.Context, key string, user *models.User) bool
+}
+
+// FeatureFlagMiddleware rejects requests for which the flag is off. It runs
+// after AuthMiddleware so role and percentage rollouts see the user
+func FeatureFlagMiddleware(flags FeatureFlags, key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user *models.User
+		if value, exists := c.Get("user"); exists {
+			u := value.(models.User)
+			user = &u
+		}
+
+		if !flags.EnabledFor(c.Request.Context(), key, user) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This feature is not enabled"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// NetworkPolicy restricts privileged routes to client IPs in an allow-list
+// and, optionally, to countries reported by a trusted proxy or CDN header.
+// An empty policy allows everything.
+type NetworkPolicy struct {
+	networks      []*net.IPNet
+	countries     map[string]bool
+	countryHeader string
+}
+
+// NewNetworkPolicy parses CIDR ranges (bare IPs are treated as single
+// hosts) and ISO 3166 country codes.
+func NewNetworkPolicy(cidrs, countries []string, countryHeader string) (*NetworkPolicy, error) {
+	p := &NetworkPolicy{countries: map[string]bool{}, countryHeader: countryHeader}
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allow-list entry %q: %v", cidr, err)
+		}
+		p.networks = append(p.networks, network)
+	}
+	for _, country := range countries {
+		p.countries[strings.ToUpper(country)] = true
+	}
+	if len(p.countries) > 0 && countryHeader == "" {
+		return nil, fmt.Errorf("a country header is required to restrict by country")
+	}
+	return p, nil
+}
+
+// Enabled reports whether the policy restricts anything.
+func (p *NetworkPolicy) Enabled() bool {
+	return len(p.networks) > 0 || len(p.countries) > 0
+}
+
+// Check returns the reason a request is rejected, or "" when it is allowed.
+// Requests without a country header are rejected when countries are set.
+func (p *NetworkPolicy) Check(ip net.IP, country string) string {
+	if len(p.networks) > 0 {
+		allowed := false
+		for _, network := range p.networks {
+			if ip != nil && network.Contains(ip) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "ip_not_allowed"
+		}
+	}
+	if len(p.countries) > 0 && !p.countries[strings.ToUpper(country)] {
+		return "country_not_allowed"
+	}
+	return ""
+}
+
+// NetworkPolicyMiddleware rejects requests the policy does not allow and
+// records each rejection in the access_denials collection.
+func NetworkPolicyMiddleware(db *database.MongoDB, policy *NetworkPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !policy.Enabled() {
+			c.Next()
+			return
+		}
+
+		clientIP := c.ClientIP()
+		country := ""
+		if policy.countryHeader != "" {
+			country = c.GetHeader(policy.countryHeader)
+		}
+		reason := policy.Check(net.ParseIP(clientIP), country)
+		if reason == "" {
+			c.Next()
+			return
+		}
+
+		denial := models.AccessDenial{
+			IP:        clientIP,
+			Country:   country,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Reason:    reason,
+			UserAgent: c.Request.UserAgent(),
+			CreatedAt: time.Now(),
+		}
+		log.Printf("Rejected %s %s from %s (country %q): %s", denial.Method, denial.Path, denial.IP, denial.Country, reason)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := db.GetCollection("access_denials").InsertOne(ctx, denial); err != nil {
+			log.Printf("Failed to record access denial: %v", err)
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access from this network is not allowed"})
+		c.Abort()
+	}
+}
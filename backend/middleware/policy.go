@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"intelliops-ai-copilot/models"
+)
+
+// Policies decides whether a user may perform an action on a resource.
+type Policies interface {
+	Authorize(ctx context.Context, user models.User, resource, action string) models.PolicyDecision
+}
+
+// PolicyMiddleware authorizes the request's route and method against the
+// policy rules. It runs after AuthMiddleware and replaces per-group role
+// checks, so every route group is governed by the same admin-editable rules
+func PolicyMiddleware(policies Policies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+
+		decision := policies.Authorize(c.Request.Context(), user.(models.User), c.FullPath(), c.Request.Method)
+		if !decision.Allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
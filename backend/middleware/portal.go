@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// OwnTicketMiddleware loads the ticket in the :id parameter and only lets
+// its creator through. The ticket is stored in the context as "ticket".
+// Tickets of other users are reported as not found.
+func OwnTicketMiddleware(db *database.MongoDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+			c.Abort()
+			return
+		}
+
+		user := c.MustGet("user").(models.User)
+
+		var ticket models.Ticket
+		err = db.GetCollection("tickets").FindOne(c.Request.Context(), bson.M{"_id": objectID, "createdBy": user.ID}).Decode(&ticket)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ticket"})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("ticket", ticket)
+		c.Next()
+	}
+}
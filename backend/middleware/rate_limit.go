@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter counts requests per client IP in fixed windows. Counts are
+// kept in memory, so each instance of the API limits on its own.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+	swept   time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// NewRateLimiter allows limit requests per client IP every window; a limit
+// of 0 or less allows everything.
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{limit: limit, window: window, windows: map[string]*rateWindow{}}
+}
+
+// Allow counts a request from key at now and reports whether it is within
+// the limit, with how long until the window resets when it is not.
+func (l *RateLimiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	if l.limit <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop finished windows now and then so the map does not grow with
+	// every address ever seen
+	if now.Sub(l.swept) >= l.window {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.window {
+				delete(l.windows, k)
+			}
+		}
+		l.swept = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// RateLimitMiddleware rejects requests with 429 once the client IP has used
+// up the limiter's allowance for the current window.
+func RateLimitMiddleware(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ok, retry := limiter.Allow(c.ClientIP(), time.Now()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(retry.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
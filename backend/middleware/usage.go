@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"intelliops-ai-copilot/models"
+)
+
+// Usage counts API requests per user.
+type Usage interface {
+	Record(userID primitive.ObjectID, route string)
+}
+
+// UsageMiddleware counts every request made by a signed-in user against
+// its route. It is installed on the engine and looks at the user after the
+// route's handlers ran, so it sees the user AuthMiddleware set
+func UsageMiddleware(usage Usage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		value, exists := c.Get("user")
+		if !exists || c.FullPath() == "" {
+			return
+		}
+		usage.Record(value.(models.User).ID, c.FullPath())
+	}
+}
+
+// Quotas enforces daily per-user limits on expensive endpoints.
+type Quotas interface {
+	Consume(ctx context.Context, key string, user models.User) (bool, error)
+}
+
+// QuotaMiddleware rejects requests with 429 once the user has used up the
+// quota for the day. It runs after AuthMiddleware. If the quota cannot be
+// checked the request goes through, so an outage of the counters does not
+// take the endpoint down with it
+func QuotaMiddleware(quotas Quotas, key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.MustGet("user").(models.User)
+
+		allowed, err := quotas.Consume(c.Request.Context(), key, user)
+		if err != nil {
+			log.Printf("Failed to check quota %s: %v", key, err)
+		} else if !allowed {
+			now := time.Now().UTC()
+			midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+			c.Header("Retry-After", strconv.Itoa(int(midnight.Sub(now).Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Daily quota exceeded for this feature"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookTokenMiddleware authenticates machine callers that send a shared
+// secret as a bearer token. The endpoint is unavailable while no token is
+// configured.
+func WebhookTokenMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook is not configured"})
+			c.Abort()
+			return
+		}
+
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook token"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccessDenial records a request to a privileged route rejected by the IP
+// allow-list or country restriction.
+type AccessDenial struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	IP        string             `json:"ip" bson:"ip"`
+	Country   string             `json:"country,omitempty" bson:"country,omitempty"`
+	Method    string             `json:"method" bson:"method"`
+	Path      string             `json:"path" bson:"path"`
+	Reason    string             `json:"reason" bson:"reason"`
+	UserAgent string             `json:"userAgent,omitempty" bson:"userAgent,omitempty"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Advisory is a vulnerability advisory ingested from the NVD CVE feed or a
+// vendor feed. Advisories are stored once per AdvisoryID; the security
+// ticket opened for the assets running affected software is updated as more
+// assets are found.
+type Advisory struct {
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	// AdvisoryID is the CVE or vendor advisory identifier.
+	AdvisoryID  string `json:"advisoryId" bson:"advisoryId"`
+	Source      string `json:"source" bson:"source"`
+	Title       string `json:"title" bson:"title"`
+	Description string `json:"description,omitempty" bson:"description,omitempty"`
+	// Severity is low, medium, high or critical.
+	Severity    string            `json:"severity" bson:"severity"`
+	CVSSScore   float64           `json:"cvssScore,omitempty" bson:"cvssScore,omitempty"`
+	Affected    []AffectedProduct `json:"affected" bson:"affected"`
+	References  []string          `json:"references,omitempty" bson:"references,omitempty"`
+	PublishedAt *time.Time        `json:"publishedAt,omitempty" bson:"publishedAt,omitempty"`
+	// AffectedAssets are the inventoried machines running affected software
+	// as of the last match.
+	AffectedAssets []AdvisoryAsset     `json:"affectedAssets" bson:"affectedAssets"`
+	TicketID       *primitive.ObjectID `json:"ticketId,omitempty" bson:"ticketId,omitempty"`
+	MatchedAt      *time.Time          `json:"matchedAt,omitempty" bson:"matchedAt,omitempty"`
+	CreatedAt      time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// AffectedProduct is a vendor's product with the versions an advisory
+// applies to: Version exactly, or the range given by the bounds. A product
+// without a version or bounds is affected in every version.
+type AffectedProduct struct {
+	Vendor                string `json:"vendor,omitempty" bson:"vendor,omitempty"`
+	Product               string `json:"product" bson:"product"`
+	Version               string `json:"version,omitempty" bson:"version,omitempty"`
+	VersionStartIncluding string `json:"versionStartIncluding,omitempty" bson:"versionStartIncluding,omitempty"`
+	VersionStartExcluding string `json:"versionStartExcluding,omitempty" bson:"versionStartExcluding,omitempty"`
+	VersionEndIncluding   string `json:"versionEndIncluding,omitempty" bson:"versionEndIncluding,omitempty"`
+	VersionEndExcluding   string `json:"versionEndExcluding,omitempty" bson:"versionEndExcluding,omitempty"`
+}
+
+// AdvisoryAsset is an endpoint agent running software an advisory affects.
+type AdvisoryAsset struct {
+	AgentID    primitive.ObjectID  `json:"agentId" bson:"agentId"`
+	Name       string              `json:"name" bson:"name"`
+	ResourceID *primitive.ObjectID `json:"resourceId,omitempty" bson:"resourceId,omitempty"`
+	Software   string              `json:"software" bson:"software"`
+	Version    string              `json:"version,omitempty" bson:"version,omitempty"`
+}
+
+// AdvisoryImportRequest pushes vendor advisories in the same shape vendor
+// feeds are read in.
+type AdvisoryImportRequest struct {
+	Source     string     `json:"source" binding:"required"`
+	Advisories []Advisory `json:"advisories" binding:"required"`
+}
+
+// AdvisorySyncResult counts what a feed sync or import did.
+type AdvisorySyncResult struct {
+	Fetched       int      `json:"fetched"`
+	Affecting     int      `json:"affecting"`
+	TicketsOpened int      `json:"ticketsOpened"`
+	TicketsNoted  int      `json:"ticketsNoted"`
+	Errors        []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AgentRunStatus string
+
+const (
+	AgentRunCompleted AgentRunStatus = "completed"
+	AgentRunMaxSteps  AgentRunStatus = "max_steps"
+	AgentRunFailed    AgentRunStatus = "failed"
+)
+
+// AgentRun is one diagnostic investigation of a ticket. Every tool call the
+// agent made is kept in Steps as an audit trail.
+type AgentRun struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TicketID primitive.ObjectID `json:"ticketId" bson:"ticketId"`
+	Goal     string             `json:"goal,omitempty" bson:"goal,omitempty"`
+	Status   AgentRunStatus     `json:"status" bson:"status"`
+	Steps    []AgentStep        `json:"steps" bson:"steps"`
+	Report   string             `json:"report" bson:"report"`
+	// Fallback is true when no LLM was available and the agent ran a fixed
+	// diagnostic plan instead of choosing tools itself.
+	Fallback    bool               `json:"fallback" bson:"fallback"`
+	Error       string             `json:"error,omitempty" bson:"error,omitempty"`
+	StartedBy   primitive.ObjectID `json:"startedBy" bson:"startedBy"`
+	StartedAt   time.Time          `json:"startedAt" bson:"startedAt"`
+	CompletedAt time.Time          `json:"completedAt" bson:"completedAt"`
+}
+
+type AgentStep struct {
+	Index      int               `json:"index" bson:"index"`
+	Thought    string            `json:"thought,omitempty" bson:"thought,omitempty"`
+	Tool       string            `json:"tool" bson:"tool"`
+	Input      map[string]string `json:"input" bson:"input"`
+	Output     string            `json:"output,omitempty" bson:"output,omitempty"`
+	Error      string            `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMs int64             `json:"durationMs" bson:"durationMs"`
+	At         time.Time         `json:"at" bson:"at"`
+}
+
+type AgentRunRequest struct {
+	// Goal optionally narrows the investigation, e.g. "check whether the VPN
+	// gateway resolves and is reachable".
+	Goal     string `json:"goal"`
+	MaxSteps int    `json:"maxSteps"`
+}
+
+// AgentTool describes a whitelisted tool the agent may call.
+type AgentTool struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Inputs      []string `json:"inputs"`
+}
+
+// DiagnosticReport is the summary of an agent run appended to a ticket.
+type DiagnosticReport struct {
+	RunID     primitive.ObjectID `json:"runId" bson:"runId"`
+	Report    string             `json:"report" bson:"report"`
+	CreatedBy primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
@@ -1,21 +1,154 @@
 package models
 
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
 type TriageRequest struct {
 	Title       string `json:"title" binding:"required"`
 	Description string `json:"description" binding:"required"`
 }
 
 type TriageResponse struct {
-	Category           TicketCategory `json:"category"`
-	Summary            string         `json:"summary"`
-	Priority           TicketPriority `json:"priority"`
-	SuggestedTechnician string        `json:"suggestedTechnician"`
-	Confidence         float64        `json:"confidence"`
-	Reasoning          string         `json:"reasoning"`
-}
-
-type AITriageConfig struct {
-	Model       string  `json:"model"`
-	Temperature float64 `json:"temperature"`
-	MaxTokens   int     `json:"maxTokens"`
+	Category            TicketCategory   `json:"category"`
+	Subcategory         string           `json:"subcategory,omitempty"`
+	SecondaryCategories []TicketCategory `json:"secondaryCategories,omitempty"`
+	Summary             string           `json:"summary"`
+	Priority            TicketPriority   `json:"priority"`
+	SuggestedTechnician string           `json:"suggestedTechnician"`
+	// TechnicianCandidates are the best technicians for the category by
+	// their history with it, best first; SuggestedTechnician is the first.
+	TechnicianCandidates []TechnicianCandidate `json:"technicianCandidates,omitempty"`
+	Confidence           float64               `json:"confidence"`
+	Reasoning            string                `json:"reasoning"`
+	// RecentDeployments are deployments to services the ticket mentions
+	// shortly before it was triaged; they are also named in Reasoning.
+	RecentDeployments []RecentDeployments `json:"recentDeployments,omitempty"`
+	// RunID identifies the logged run when the request took part in an
+	// experiment; send it back with feedback or when creating the ticket.
+	RunID string `json:"runId,omitempty"`
+}
+
+// TechnicianCandidate is a technician scored for a triaged ticket on how
+// reliably they resolved tickets of its category and how satisfied the
+// requesters were.
+type TechnicianCandidate struct {
+	TechnicianID   primitive.ObjectID `json:"technicianId"`
+	TechnicianName string             `json:"technicianName"`
+	Score          float64            `json:"score"`
+	// Assigned tickets of the category; SuccessRate is the share resolved
+	// without being reopened.
+	Assigned    int64   `json:"assigned"`
+	SuccessRate float64 `json:"successRate"`
+	// AverageRating is the mean satisfaction score (1 to 5) of Ratings
+	// rated tickets.
+	AverageRating *float64 `json:"averageRating,omitempty"`
+	Ratings       int64    `json:"ratings"`
+	// Matched is set when triage named this technician.
+	Matched bool `json:"matched"`
+	// OnCall is set when no technician for the category is available and
+	// the candidate is the one on call.
+	OnCall bool `json:"onCall,omitempty"`
+}
+
+// GenerationParams are the LLM generation parameters of an AI endpoint.
+// Unset fields fall back to the next level: per-request override, admin
+// configuration, then the endpoint's built-in default.
+type GenerationParams struct {
+	Model       string   `json:"model,omitempty" bson:"model,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty" bson:"temperature,omitempty"`
+	MaxTokens   int      `json:"maxTokens,omitempty" bson:"maxTokens,omitempty"`
+	TopP        *float64 `json:"topP,omitempty" bson:"topP,omitempty"`
+}
+
+// GenerationConfig is the admin-configured parameters of one AI endpoint.
+type GenerationConfig struct {
+	Endpoint         string `json:"endpoint" bson:"_id"`
+	GenerationParams `bson:",inline"`
+	UpdatedBy        primitive.ObjectID `json:"updatedBy" bson:"updatedBy"`
+	UpdatedAt        time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// GenerationConfigView shows an endpoint's built-in defaults, admin
+// configuration and the parameters in effect.
+type GenerationConfigView struct {
+	Endpoint   string            `json:"endpoint"`
+	Defaults   GenerationParams  `json:"defaults"`
+	Configured *GenerationConfig `json:"configured,omitempty"`
+	Effective  GenerationParams  `json:"effective"`
+}
+
+// TriageOptions selects the provider, model and prompt template version for a
+// triage run. Empty fields use the configured defaults.
+type TriageOptions struct {
+	Provider      string `json:"provider,omitempty"`
+	Model         string `json:"model,omitempty"`
+	PromptVersion string `json:"promptVersion,omitempty"`
+}
+
+// TriageRun is a triage result together with how it was produced.
+type TriageRun struct {
+	Options   TriageOptions   `json:"options"`
+	Result    *TriageResponse `json:"result"`
+	LatencyMs int64           `json:"latencyMs"`
+	// Fallback is true when the keyword-based mock produced the result because
+	// the LLM was unavailable or returned unusable output.
+	Fallback bool   `json:"fallback"`
+	Error    string `json:"error,omitempty"`
+	// Estimated usage of the LLM call; zero for fallback runs.
+	PromptTokens     int       `json:"promptTokens"`
+	CompletionTokens int       `json:"completionTokens"`
+	CostUSD          float64   `json:"costUsd"`
+	RanAt            time.Time `json:"ranAt"`
+}
+
+// TicketTriage is the AI triage applied to a ticket when it was created,
+// kept so automatic triage can be reviewed later.
+type TicketTriage struct {
+	Category            TicketCategory `json:"category" bson:"category"`
+	Subcategory         string         `json:"subcategory,omitempty" bson:"subcategory,omitempty"`
+	Priority            TicketPriority `json:"priority" bson:"priority"`
+	SuggestedTechnician string         `json:"suggestedTechnician" bson:"suggestedTechnician"`
+	Confidence          float64        `json:"confidence" bson:"confidence"`
+	Reasoning           string         `json:"reasoning" bson:"reasoning"`
+	// Fallback is true when keyword matching produced the result.
+	Fallback  bool      `json:"fallback" bson:"fallback"`
+	TriagedAt time.Time `json:"triagedAt" bson:"triagedAt"`
+}
+
+type TriageSandboxRequest struct {
+	Title       string          `json:"title" binding:"required"`
+	Description string          `json:"description" binding:"required"`
+	Variants    []TriageOptions `json:"variants" binding:"required,min=1"`
+}
+
+type TriageSandboxResponse struct {
+	Baseline TriageRun   `json:"baseline"`
+	Variants []TriageRun `json:"variants"`
+}
+
+type ParseTicketRequest struct {
+	// Text is a free-form description or a pasted chat transcript.
+	Text string `json:"text" binding:"required"`
+}
+
+// ParsedTicket is a ticket drafted from free-form text. Ticket can be sent
+// to POST /api/tickets as it is or after the user edits it.
+type ParsedTicket struct {
+	Ticket CreateTicketRequest `json:"ticket"`
+	// AffectedAsset is the machine, device or system the text names, e.g. a
+	// hostname or asset tag; EndpointAgent is set when it matches an agent.
+	AffectedAsset string             `json:"affectedAsset,omitempty"`
+	EndpointAgent *ParsedTicketAgent `json:"endpointAgent,omitempty"`
+	// Fallback is true when keyword matching produced the draft.
+	Fallback bool `json:"fallback"`
+}
+
+type ParsedTicketAgent struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	AssetTag string `json:"assetTag,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
 }
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AlertSource is an inbound webhook that turns another tool's alert payload
+// into anomalies and tickets using a mapping template. Alerts are posted to
+// /api/monitor/ingest/webhooks/<slug> with the source's token.
+type AlertSource struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name    string             `json:"name" bson:"name"`
+	Slug    string             `json:"slug" bson:"slug"`
+	Token   string             `json:"token,omitempty" bson:"token"`
+	Enabled bool               `json:"enabled" bson:"enabled"`
+	Mapping AlertMapping       `json:"mapping" bson:"mapping"`
+	// LastReceivedAt is updated on every accepted webhook call.
+	LastReceivedAt *time.Time         `json:"lastReceivedAt,omitempty" bson:"lastReceivedAt,omitempty"`
+	CreatedBy      primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// AlertMapping extracts alert fields from a JSON payload. Each field is
+// either a JSONPath such as "$.alert.host" or a template with embedded
+// paths such as "Disk full on {{$.host}}".
+type AlertMapping struct {
+	// AlertsPath points at an array when one payload carries several alerts;
+	// the other fields are then evaluated against each element.
+	AlertsPath  string `json:"alertsPath,omitempty" bson:"alertsPath,omitempty"`
+	Title       string `json:"title" bson:"title"`
+	Description string `json:"description,omitempty" bson:"description,omitempty"`
+	Severity    string `json:"severity,omitempty" bson:"severity,omitempty"`
+	Resource    string `json:"resource,omitempty" bson:"resource,omitempty"`
+	Category    string `json:"category,omitempty" bson:"category,omitempty"`
+	DedupKey    string `json:"dedupKey,omitempty" bson:"dedupKey,omitempty"`
+	Status      string `json:"status,omitempty" bson:"status,omitempty"`
+	// ResolvedValues are Status values that mean the alert has cleared,
+	// e.g. "OK" or "resolved".
+	ResolvedValues []string `json:"resolvedValues,omitempty" bson:"resolvedValues,omitempty"`
+	// SeverityMap translates source severities (e.g. Zabbix "Disaster") to
+	// critical, high, medium or low.
+	SeverityMap map[string]string `json:"severityMap,omitempty" bson:"severityMap,omitempty"`
+}
+
+type AlertSourceRequest struct {
+	Name    string       `json:"name" binding:"required"`
+	Slug    string       `json:"slug" binding:"required"`
+	Enabled *bool        `json:"enabled"`
+	Mapping AlertMapping `json:"mapping"`
+}
+
+// MappedAlert is one alert extracted from a payload, as returned by the
+// mapping test endpoint.
+type MappedAlert struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Severity    string `json:"severity"`
+	Resource    string `json:"resource,omitempty"`
+	Category    string `json:"category,omitempty"`
+	DedupKey    string `json:"dedupKey"`
+	Resolved    bool   `json:"resolved"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// AlertmanagerWebhook is the payload Prometheus Alertmanager posts to a
+// webhook receiver (version 4).
+type AlertmanagerWebhook struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey"`
+	Status            string              `json:"status"`
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []AlertmanagerAlert `json:"alerts" binding:"required"`
+}
+
+type AlertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// AlertIngestResult counts what happened to the alerts in one webhook call.
+type AlertIngestResult struct {
+	Received       int `json:"received"`
+	Created        int `json:"created"`
+	Duplicates     int `json:"duplicates"`
+	Resolved       int `json:"resolved"`
+	TicketsCreated int `json:"ticketsCreated"`
+	// Suppressed counts created anomalies that fell in a maintenance window.
+	Suppressed int `json:"suppressed"`
+}
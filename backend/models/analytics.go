@@ -0,0 +1,114 @@
+package models
+
+import "time"
+
+// UnknownLocation stands for the location or department of requesters
+// who have none set.
+const UnknownLocation = "unknown"
+
+// TicketHeatmap counts tickets created in each hour of the week, in
+// TimeZone, so coverage can be scheduled when issues actually come in.
+type TicketHeatmap struct {
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	TimeZone   string    `json:"timeZone"`
+	Location   string    `json:"location,omitempty"`
+	Department string    `json:"department,omitempty"`
+	Total      int64     `json:"total"`
+	// Cells[weekday][hour]; weekday 0 is Sunday.
+	Cells     [7][24]int64 `json:"cells"`
+	ByWeekday [7]int64     `json:"byWeekday"`
+	ByHour    [24]int64    `json:"byHour"`
+	// PeakWeekday and PeakHour locate the busiest cell.
+	PeakWeekday string `json:"peakWeekday"`
+	PeakHour    int    `json:"peakHour"`
+}
+
+// TicketLocationCount counts tickets raised by requesters at a location in
+// a department.
+type TicketLocationCount struct {
+	Location   string `json:"location"`
+	Department string `json:"department"`
+	Count      int64  `json:"count"`
+	Critical   int64  `json:"critical"`
+}
+
+// TicketLocationReport breaks ticket volume down by the requester's
+// location and department.
+type TicketLocationReport struct {
+	From         time.Time             `json:"from"`
+	To           time.Time             `json:"to"`
+	Total        int64                 `json:"total"`
+	ByLocation   map[string]int64      `json:"byLocation"`
+	ByDepartment map[string]int64      `json:"byDepartment"`
+	Groups       []TicketLocationCount `json:"groups"`
+}
+
+// AnalyticsQuery is a chart definition in the restricted aggregation DSL of
+// the analytics query endpoint. Tickets created in [From, To) are grouped
+// by Dimensions and summarised by Measures; only the listed fields and
+// operators are accepted.
+type AnalyticsQuery struct {
+	// Dimensions are ticket fields (category, subcategory, priority,
+	// status, type, team, department, location, assignedTo, tag) or time
+	// buckets of createdAt (day, week, month).
+	Dimensions []string           `json:"dimensions"`
+	Measures   []AnalyticsMeasure `json:"measures" binding:"required"`
+	Filters    []AnalyticsFilter  `json:"filters"`
+	From       time.Time          `json:"from" binding:"required"`
+	To         time.Time          `json:"to" binding:"required"`
+	// TimeZone is the IANA zone time buckets are cut in, default UTC.
+	TimeZone string         `json:"timeZone"`
+	Sort     *AnalyticsSort `json:"sort"`
+	// Limit caps the rows returned, default 100 and at most 1000.
+	Limit int `json:"limit"`
+}
+
+// AnalyticsMeasure is count, or sum, avg, min or max of a numeric field:
+// resolutionHours or rating. As names the column, by default op or
+// op_field.
+type AnalyticsMeasure struct {
+	Op    string `json:"op" binding:"required"`
+	Field string `json:"field"`
+	As    string `json:"as"`
+}
+
+// AnalyticsFilter keeps tickets whose Field is (eq, in) or is not (ne,
+// nin) the value or one of the values given.
+type AnalyticsFilter struct {
+	Field  string   `json:"field" binding:"required"`
+	Op     string   `json:"op" binding:"required"`
+	Value  string   `json:"value"`
+	Values []string `json:"values"`
+}
+
+// AnalyticsSort orders rows by a dimension or measure column.
+type AnalyticsSort struct {
+	By   string `json:"by" binding:"required"`
+	Desc bool   `json:"desc"`
+}
+
+// AnalyticsQueryResult holds one row per group, keyed by dimension and
+// measure column. Truncated is set when Limit cut rows off.
+type AnalyticsQueryResult struct {
+	Columns   []string                 `json:"columns"`
+	Rows      []map[string]interface{} `json:"rows"`
+	Truncated bool                     `json:"truncated"`
+}
+
+type AskAnalyticsRequest struct {
+	Question string `json:"question" binding:"required"`
+}
+
+// AskAnalyticsResponse answers a question about ticket data with the
+// analytics query it was translated into, so the answer can be checked.
+// Fallback is set when no LLM was available and the question was
+// translated by keyword matching.
+type AskAnalyticsResponse struct {
+	Question string               `json:"question"`
+	Answer   string               `json:"answer"`
+	Title    string               `json:"title"`
+	Query    AnalyticsQuery       `json:"query"`
+	Result   AnalyticsQueryResult `json:"result"`
+	Fallback bool                 `json:"fallback"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ApprovalDelegation lets DelegateID decide, between StartsAt and EndsAt,
+// the approvals DelegatorID could decide, such as while they are on
+// vacation. Delegations do not chain: a delegate acts only for the
+// approvers who delegated to them directly.
+type ApprovalDelegation struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	DelegatorID  primitive.ObjectID `json:"delegatorId" bson:"delegatorId"`
+	DelegateID   primitive.ObjectID `json:"delegateId" bson:"delegateId"`
+	DelegateName string             `json:"delegateName" bson:"delegateName"`
+	StartsAt     time.Time          `json:"startsAt" bson:"startsAt"`
+	EndsAt       time.Time          `json:"endsAt" bson:"endsAt"`
+	Reason       string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+type ApprovalDelegationRequest struct {
+	DelegateID string    `json:"delegateId" binding:"required"`
+	StartsAt   time.Time `json:"startsAt" binding:"required"`
+	EndsAt     time.Time `json:"endsAt" binding:"required"`
+	Reason     string    `json:"reason"`
+}
+
+// ApprovalDelegations are the delegations a user gave and those given to
+// them that have not ended.
+type ApprovalDelegations struct {
+	Given    []ApprovalDelegation `json:"given"`
+	Received []ApprovalDelegation `json:"received"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AssignmentStrategy string
+
+const (
+	// AssignRoundRobin takes turns through the technicians.
+	AssignRoundRobin AssignmentStrategy = "round_robin"
+	// AssignLeastOpen picks the technician with the fewest open tickets,
+	// the more skilled one on a tie.
+	AssignLeastOpen AssignmentStrategy = "least_open"
+)
+
+// AssignmentRuleAnyCategory is the category of the rule for tickets whose
+// category has no rule of its own.
+const AssignmentRuleAnyCategory TicketCategory = "*"
+
+// AssignmentRule assigns new tickets in a category that no ticket route
+// assigned. Technicians excluded from the category in their skill profile
+// are skipped.
+type AssignmentRule struct {
+	Category TicketCategory     `json:"category" bson:"_id"`
+	Strategy AssignmentStrategy `json:"strategy" bson:"strategy"`
+	// Technicians are the pool to assign from; empty means every
+	// technician.
+	Technicians []primitive.ObjectID `json:"technicians" bson:"technicians"`
+	// MaxOpenTickets skips technicians with this many open or in-progress
+	// tickets; 0 means no limit.
+	MaxOpenTickets int  `json:"maxOpenTickets" bson:"maxOpenTickets"`
+	Enabled        bool `json:"enabled" bson:"enabled"`
+	// Turns counts round-robin assignments, to pick the next technician.
+	Turns     int64               `json:"-" bson:"turns"`
+	UpdatedAt time.Time           `json:"updatedAt" bson:"updatedAt"`
+	UpdatedBy *primitive.ObjectID `json:"updatedBy,omitempty" bson:"updatedBy,omitempty"`
+}
+
+type AssignmentRuleRequest struct {
+	Strategy       AssignmentStrategy `json:"strategy" binding:"required"`
+	Technicians    []string           `json:"technicians"`
+	MaxOpenTickets int                `json:"maxOpenTickets"`
+	Enabled        *bool              `json:"enabled"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TicketAttachment is a file, such as a screenshot or a log, uploaded to a
+// ticket. The content is kept in the attachment store under the same ID.
+type TicketAttachment struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	TicketID       primitive.ObjectID `json:"ticketId" bson:"ticketId"`
+	FileName       string             `json:"fileName" bson:"fileName"`
+	ContentType    string             `json:"contentType" bson:"contentType"`
+	Size           int64              `json:"size" bson:"size"`
+	UploadedBy     primitive.ObjectID `json:"uploadedBy" bson:"uploadedBy"`
+	UploadedByName string             `json:"uploadedByName" bson:"uploadedByName"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+	// URL downloads the attachment; it needs the same sign-in as the API.
+	URL string `json:"url" bson:"-"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WorkingHours are the shifts a technician takes tickets in, in their time
+// zone. A shift whose end is not after its start runs past midnight.
+type WorkingHours struct {
+	TimeZone string         `json:"timeZone" bson:"timeZone"`
+	Shifts   []WorkingShift `json:"shifts" bson:"shifts"`
+}
+
+type WorkingShift struct {
+	// Weekday the shift starts on, 0 for Sunday.
+	Weekday time.Weekday `json:"weekday" bson:"weekday"`
+	// Start and End are "15:04" times.
+	Start string `json:"start" bson:"start"`
+	End   string `json:"end" bson:"end"`
+}
+
+type OutOfOfficeSource string
+
+const (
+	OutOfOfficeManual OutOfOfficeSource = "manual"
+	// Periods from calendars are replaced on every calendar sync.
+	OutOfOfficeGoogle  OutOfOfficeSource = "google"
+	OutOfOfficeOutlook OutOfOfficeSource = "outlook"
+)
+
+// OutOfOffice is a period a technician is away, entered by hand or read
+// from their calendar.
+type OutOfOffice struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id"`
+	UserID primitive.ObjectID `json:"userId" bson:"userId"`
+	Start  time.Time          `json:"start" bson:"start"`
+	End    time.Time          `json:"end" bson:"end"`
+	Note   string             `json:"note,omitempty" bson:"note,omitempty"`
+	Source OutOfOfficeSource  `json:"source" bson:"source"`
+	// ExternalID is the calendar event the period was read from.
+	ExternalID string    `json:"externalId,omitempty" bson:"externalId,omitempty"`
+	CreatedAt  time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+type OutOfOfficeRequest struct {
+	Start time.Time `json:"start" binding:"required"`
+	End   time.Time `json:"end" binding:"required"`
+	Note  string    `json:"note"`
+}
+
+// Reasons a technician is not taking tickets.
+const (
+	UnavailableOutOfOffice = "out_of_office"
+	UnavailableOffShift    = "outside_working_hours"
+)
+
+// TechnicianAvailability is whether a technician takes tickets right now,
+// with their working hours and current and upcoming time off.
+type TechnicianAvailability struct {
+	UserID       primitive.ObjectID `json:"userId"`
+	Name         string             `json:"name"`
+	WorkingHours *WorkingHours      `json:"workingHours,omitempty"`
+	OutOfOffice  []OutOfOffice      `json:"outOfOffice"`
+	Available    bool               `json:"available"`
+	Reason       string             `json:"reason,omitempty"`
+	OnCall       bool               `json:"onCall"`
+}
+
+// OnCallRotation hands tickets that arrive while every eligible technician
+// is off to one technician at a time, in turn, each for ShiftHours from
+// StartsAt.
+type OnCallRotation struct {
+	ID          string               `json:"-" bson:"_id"`
+	Technicians []primitive.ObjectID `json:"technicians" bson:"technicians"`
+	ShiftHours  int                  `json:"shiftHours" bson:"shiftHours"`
+	StartsAt    time.Time            `json:"startsAt" bson:"startsAt"`
+	UpdatedBy   primitive.ObjectID   `json:"updatedBy" bson:"updatedBy"`
+	UpdatedAt   time.Time            `json:"updatedAt" bson:"updatedAt"`
+}
+
+// OnCallRotationRequest sets the technicians in turn order; ShiftHours and
+// StartsAt are required unless Technicians is empty.
+type OnCallRotationRequest struct {
+	Technicians []string  `json:"technicians"`
+	ShiftHours  int       `json:"shiftHours"`
+	StartsAt    time.Time `json:"startsAt"`
+}
+
+// OnCallShift is the technician on call and when their shift ends.
+type OnCallShift struct {
+	UserID primitive.ObjectID `json:"userId"`
+	Name   string             `json:"name"`
+	Until  time.Time          `json:"until"`
+}
+
+// AvailabilityOverview is every technician's availability and who is on
+// call.
+type AvailabilityOverview struct {
+	Technicians []TechnicianAvailability `json:"technicians"`
+	OnCall      *OnCallShift             `json:"onCall,omitempty"`
+}
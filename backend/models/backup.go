@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Conflict modes for documents of a restore whose _id already exists.
+const (
+	RestoreSkip      = "skip"
+	RestoreOverwrite = "overwrite"
+)
+
+// Backup describes one export in backup storage.
+type Backup struct {
+	Key       string    `json:"key"`
+	SizeBytes int64     `json:"sizeBytes"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Counts and Consistent are only known for a backup just written.
+	Counts map[string]int `json:"counts,omitempty"`
+	// Consistent is false when the server does not support snapshot reads
+	// (a standalone mongod) and collections were read one after another.
+	Consistent bool `json:"consistent"`
+}
+
+type RestoreRequest struct {
+	Key string `json:"key" binding:"required"`
+	// Conflict is skip (default) or overwrite.
+	Conflict string `json:"conflict"`
+	// Collections limits the restore; empty restores every collection in
+	// the backup.
+	Collections []string `json:"collections"`
+	// DryRun counts what would be inserted, overwritten or skipped without
+	// writing anything.
+	DryRun bool `json:"dryRun"`
+}
+
+type RestoreResult struct {
+	Key         string                              `json:"key"`
+	Conflict    string                              `json:"conflict"`
+	DryRun      bool                                `json:"dryRun"`
+	Collections map[string]*CollectionRestoreResult `json:"collections"`
+}
+
+type CollectionRestoreResult struct {
+	Total       int `json:"total"`
+	Inserted    int `json:"inserted"`
+	Overwritten int `json:"overwritten"`
+	Skipped     int `json:"skipped"`
+	// Failed documents clash with another document on a unique index,
+	// such as a user with the same email under a different _id.
+	Failed int      `json:"failed"`
+	Errors []string `json:"errors,omitempty"`
+}
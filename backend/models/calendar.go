@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of calendar events.
+const (
+	CalendarEventMaintenance = "maintenance"
+	CalendarEventTicketDue   = "ticket_due"
+	CalendarEventReminder    = "reminder"
+)
+
+// CalendarEvent is scheduled work shown in calendar feeds and synced
+// calendars. UID stays the same across updates of its source.
+type CalendarEvent struct {
+	UID         string    `json:"uid"`
+	Kind        string    `json:"kind"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+// CalendarFeed is a user's ICS feed. Calendar apps cannot sign in, so the
+// feed URL carries a secret token; rotating it revokes the old URL.
+type CalendarFeed struct {
+	UserID    primitive.ObjectID `json:"userId" bson:"_id"`
+	Token     string             `json:"token" bson:"token"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// CalendarSyncRecord links a calendar event to the event created for it in
+// an external calendar.
+type CalendarSyncRecord struct {
+	ID       string    `bson:"_id"`
+	Provider string    `bson:"provider"`
+	UID      string    `bson:"uid"`
+	EventID  string    `bson:"eventId"`
+	Hash     string    `bson:"hash"`
+	End      time.Time `bson:"end"`
+	SyncedAt time.Time `bson:"syncedAt"`
+}
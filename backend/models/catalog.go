@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CatalogItem is something users can request from the service catalog,
+// such as a laptop, a monitor or a software seat.
+type CatalogItem struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	// Category is the ticket category of requests for the item.
+	Category TicketCategory `json:"category" bson:"category"`
+	// Cost is the unit cost; a request costs Cost times its quantity.
+	Cost     float64 `json:"cost" bson:"cost"`
+	Currency string  `json:"currency" bson:"currency"`
+	Active   bool    `json:"active" bson:"active"`
+	// Approvals are the steps a request goes through, in order.
+	Approvals []ApprovalRequirement `json:"approvals" bson:"approvals"`
+	// FulfillmentSteps are copied onto approved requests as a checklist.
+	FulfillmentSteps []string `json:"fulfillmentSteps" bson:"fulfillmentSteps"`
+	// AssignTo receives requests once they are approved.
+	AssignTo  *primitive.ObjectID `json:"assignTo,omitempty" bson:"assignTo,omitempty"`
+	CreatedBy primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// ApprovalRequirement is one step of an approval chain.
+type ApprovalRequirement struct {
+	Name string `json:"name" bson:"name"`
+	// ApproverIDs may approve the step; any admin may when empty.
+	ApproverIDs []primitive.ObjectID `json:"approverIds,omitempty" bson:"approverIds,omitempty"`
+	// MinCost skips the step for requests costing less.
+	MinCost float64 `json:"minCost,omitempty" bson:"minCost,omitempty"`
+}
+
+type CatalogItemRequest struct {
+	Name             string                `json:"name" binding:"required"`
+	Description      string                `json:"description"`
+	Category         TicketCategory        `json:"category"`
+	Cost             float64               `json:"cost" binding:"min=0"`
+	Currency         string                `json:"currency"`
+	Active           *bool                 `json:"active"`
+	Approvals        []ApprovalRequirement `json:"approvals"`
+	FulfillmentSteps []string              `json:"fulfillmentSteps"`
+	AssignTo         *primitive.ObjectID   `json:"assignTo"`
+}
+
+type ApprovalStatus string
+
+const (
+	ApprovalPending  ApprovalStatus = "pending"
+	ApprovalApproved ApprovalStatus = "approved"
+	ApprovalRejected ApprovalStatus = "rejected"
+)
+
+// ServiceRequest is the catalog part of a service request ticket.
+type ServiceRequest struct {
+	CatalogItemID primitive.ObjectID `json:"catalogItemId" bson:"catalogItemId"`
+	ItemName      string             `json:"itemName" bson:"itemName"`
+	Quantity      int                `json:"quantity" bson:"quantity"`
+	TotalCost     float64            `json:"totalCost" bson:"totalCost"`
+	Currency      string             `json:"currency" bson:"currency"`
+	Justification string             `json:"justification,omitempty" bson:"justification,omitempty"`
+	// ApprovalStatus is approved once every step is; the ticket can only be
+	// assigned from then on.
+	ApprovalStatus   ApprovalStatus      `json:"approvalStatus" bson:"approvalStatus"`
+	Approvals        []ApprovalStep      `json:"approvals" bson:"approvals"`
+	FulfillmentSteps []FulfillmentStep   `json:"fulfillmentSteps" bson:"fulfillmentSteps"`
+	AssignTo         *primitive.ObjectID `json:"assignTo,omitempty" bson:"assignTo,omitempty"`
+}
+
+// ApprovalStep is the state of one approval step of a request.
+type ApprovalStep struct {
+	Name        string               `json:"name" bson:"name"`
+	ApproverIDs []primitive.ObjectID `json:"approverIds,omitempty" bson:"approverIds,omitempty"`
+	Status      ApprovalStatus       `json:"status" bson:"status"`
+	DecidedBy   *primitive.ObjectID  `json:"decidedBy,omitempty" bson:"decidedBy,omitempty"`
+	DecidedAt   *time.Time           `json:"decidedAt,omitempty" bson:"decidedAt,omitempty"`
+	Comment     string               `json:"comment,omitempty" bson:"comment,omitempty"`
+	// OnBehalfOf is the approver DecidedBy stood in for under a
+	// delegation.
+	OnBehalfOf *primitive.ObjectID `json:"onBehalfOf,omitempty" bson:"onBehalfOf,omitempty"`
+}
+
+type FulfillmentStep struct {
+	Title  string              `json:"title" bson:"title"`
+	Done   bool                `json:"done" bson:"done"`
+	DoneBy *primitive.ObjectID `json:"doneBy,omitempty" bson:"doneBy,omitempty"`
+	DoneAt *time.Time          `json:"doneAt,omitempty" bson:"doneAt,omitempty"`
+}
+
+type CatalogRequestRequest struct {
+	Quantity      int    `json:"quantity" binding:"omitempty,min=1,max=1000"`
+	Justification string `json:"justification"`
+}
+
+type ApprovalDecisionRequest struct {
+	Approve bool   `json:"approve"`
+	Comment string `json:"comment"`
+}
+
+type UpdateFulfillmentStepRequest struct {
+	StepIndex int  `json:"stepIndex" binding:"min=0"`
+	Done      bool `json:"done"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChangeRequest is the change part of a change ticket: when the work is
+// planned, the monitored resources it touches and its approval. Changes
+// are ordered with after and before links.
+type ChangeRequest struct {
+	PlannedStart time.Time            `json:"plannedStart" bson:"plannedStart"`
+	PlannedEnd   time.Time            `json:"plannedEnd" bson:"plannedEnd"`
+	ResourceIDs  []primitive.ObjectID `json:"resourceIds,omitempty" bson:"resourceIds,omitempty"`
+	RollbackPlan string               `json:"rollbackPlan,omitempty" bson:"rollbackPlan,omitempty"`
+	// ApprovalStatus is approved once the approval step is; the change
+	// can only be assigned and worked on from then on. Rescheduling an
+	// approved change sends it back for approval.
+	ApprovalStatus ApprovalStatus `json:"approvalStatus" bson:"approvalStatus"`
+	Approval       ApprovalStep   `json:"approval" bson:"approval"`
+}
+
+type CreateChangeRequest struct {
+	Title        string         `json:"title" binding:"required"`
+	Description  string         `json:"description" binding:"required"`
+	Category     TicketCategory `json:"category,omitempty"`
+	Priority     TicketPriority `json:"priority,omitempty"`
+	PlannedStart time.Time      `json:"plannedStart" binding:"required"`
+	PlannedEnd   time.Time      `json:"plannedEnd" binding:"required"`
+	ResourceIDs  []string       `json:"resourceIds"`
+	RollbackPlan string         `json:"rollbackPlan"`
+}
+
+// ScheduleChangeRequest moves a change; ResourceIDs replaces the resources
+// when non-nil.
+type ScheduleChangeRequest struct {
+	PlannedStart time.Time `json:"plannedStart" binding:"required"`
+	PlannedEnd   time.Time `json:"plannedEnd" binding:"required"`
+	ResourceIDs  *[]string `json:"resourceIds,omitempty"`
+}
+
+type ScheduleConflictKind string
+
+const (
+	// A maintenance window on the same resources overlaps the change.
+	ConflictMaintenanceWindow ScheduleConflictKind = "maintenance_window"
+	// Another change on the same resources overlaps it.
+	ConflictChange ScheduleConflictKind = "change"
+	// A change it must follow, or precede, is scheduled out of order or
+	// not scheduled at all.
+	ConflictDependency ScheduleConflictKind = "dependency"
+)
+
+// ScheduleConflict is a reason a change cannot go ahead as scheduled.
+type ScheduleConflict struct {
+	Kind     ScheduleConflictKind `json:"kind"`
+	TicketID *primitive.ObjectID  `json:"ticketId,omitempty"`
+	WindowID *primitive.ObjectID  `json:"windowId,omitempty"`
+	Title    string               `json:"title"`
+	StartsAt *time.Time           `json:"startsAt,omitempty"`
+	EndsAt   *time.Time           `json:"endsAt,omitempty"`
+	// ResourceIDs are the resources both touch.
+	ResourceIDs []primitive.ObjectID `json:"resourceIds,omitempty"`
+	Message     string               `json:"message"`
+}
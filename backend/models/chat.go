@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ChatSessionStatus string
+
+const (
+	ChatActive ChatSessionStatus = "active"
+	// ChatEscalated sessions were turned into a ticket and take no more
+	// messages.
+	ChatEscalated ChatSessionStatus = "escalated"
+	ChatClosed    ChatSessionStatus = "closed"
+)
+
+type ChatRole string
+
+const (
+	ChatRoleVisitor   ChatRole = "visitor"
+	ChatRoleAssistant ChatRole = "assistant"
+	// ChatRoleSystem messages note events such as the escalation.
+	ChatRoleSystem ChatRole = "system"
+)
+
+// ChatSession is a conversation in the embeddable support chat. Signed-in
+// users own their sessions; anonymous visitors hold the session's token,
+// of which only a hash is stored.
+type ChatSession struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID    *primitive.ObjectID `json:"userId,omitempty" bson:"userId,omitempty"`
+	TokenHash string              `json:"-" bson:"tokenHash,omitempty"`
+	// Contact is who the visitor said they are; for signed-in users it is
+	// taken from their account.
+	Contact  ChatContact       `json:"contact" bson:"contact"`
+	PageURL  string            `json:"pageUrl,omitempty" bson:"pageUrl,omitempty"`
+	Status   ChatSessionStatus `json:"status" bson:"status"`
+	Messages []ChatMessage     `json:"messages" bson:"messages"`
+	// TicketID is the ticket the session was escalated to.
+	TicketID  *primitive.ObjectID `json:"ticketId,omitempty" bson:"ticketId,omitempty"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+type ChatContact struct {
+	Name  string `json:"name,omitempty" bson:"name,omitempty"`
+	Email string `json:"email,omitempty" bson:"email,omitempty"`
+}
+
+type ChatMessage struct {
+	ID   primitive.ObjectID `json:"id" bson:"_id"`
+	Role ChatRole           `json:"role" bson:"role"`
+	Body string             `json:"body" bson:"body"`
+	// Articles are the knowledge base articles an assistant answer is
+	// based on.
+	Articles []ArticleSuggestion `json:"articles,omitempty" bson:"articles,omitempty"`
+	// Fallback is true on assistant answers built without the LLM.
+	Fallback  bool      `json:"fallback,omitempty" bson:"fallback,omitempty"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+type StartChatRequest struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	PageURL string `json:"pageUrl"`
+}
+
+// StartChatResponse carries the token anonymous visitors send as
+// X-Chat-Token; it is only returned here.
+type StartChatResponse struct {
+	Session ChatSession `json:"session"`
+	Token   string      `json:"token,omitempty"`
+}
+
+type ChatMessageRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// ChatReply is the assistant's answer to a visitor message.
+type ChatReply struct {
+	Message ChatMessage `json:"message"`
+	Reply   ChatMessage `json:"reply"`
+	// SuggestEscalation is set when the assistant could not help, so the
+	// widget offers to hand over to a technician.
+	SuggestEscalation bool `json:"suggestEscalation"`
+}
+
+// EscalateChatRequest hands the conversation over to the service desk.
+// Anonymous visitors must give an email address to be reached at.
+type EscalateChatRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Note  string `json:"note"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TicketCluster is a group of similar recent tickets that grew unusually
+// fast. Each detected cluster is tracked by one proactive problem ticket.
+type TicketCluster struct {
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	// Representative is the title of the ticket closest to the centroid.
+	Representative string               `json:"representative" bson:"representative"`
+	Category       TicketCategory       `json:"category" bson:"category"`
+	Centroid       []float32            `json:"-" bson:"centroid"`
+	MemberIDs      []primitive.ObjectID `json:"memberIds" bson:"memberIds"`
+	// RecentCount is the number of members in the growth window; BaselineRate
+	// is the expected count for a window of that length.
+	RecentCount     int                `json:"recentCount" bson:"recentCount"`
+	BaselineRate    float64            `json:"baselineRate" bson:"baselineRate"`
+	GrowthRatio     float64            `json:"growthRatio" bson:"growthRatio"`
+	ProblemTicketID primitive.ObjectID `json:"problemTicketId" bson:"problemTicketId"`
+	DetectedAt      time.Time          `json:"detectedAt" bson:"detectedAt"`
+	UpdatedAt       time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
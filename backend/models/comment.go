@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TicketComment is one message on a ticket's conversation thread. Internal
+// comments are only visible to technicians and admins.
+type TicketComment struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TicketID   primitive.ObjectID `json:"ticketId" bson:"ticketId"`
+	AuthorID   primitive.ObjectID `json:"authorId" bson:"authorId"`
+	AuthorName string             `json:"authorName" bson:"authorName"`
+	AuthorRole UserRole           `json:"authorRole" bson:"authorRole"`
+	Body       string             `json:"body" bson:"body"`
+	Internal   bool               `json:"internal" bson:"internal"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+type CreateCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+	// Internal comments are hidden from the requester. Only staff can post
+	// them.
+	Internal bool `json:"internal"`
+}
+
+// TicketDetail is a ticket with the first page of its conversation thread.
+type TicketDetail struct {
+	Ticket
+	Comments     []TicketComment `json:"comments"`
+	CommentCount int64           `json:"commentCount"`
+}
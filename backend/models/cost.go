@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// DailyCost is one day's unblended AWS spend in USD, in total and per
+// service or account.
+type DailyCost struct {
+	Date   time.Time          `json:"date"`
+	Total  float64            `json:"total"`
+	Groups map[string]float64 `json:"groups"`
+	// Estimated is set while AWS has not finalized the day's figures.
+	Estimated bool `json:"estimated"`
+}
+
+// CostContributor is a service or account's share of a cost spike.
+type CostContributor struct {
+	Key      string  `json:"key"`
+	Cost     float64 `json:"cost"`
+	Baseline float64 `json:"baseline"`
+	Increase float64 `json:"increase"`
+}
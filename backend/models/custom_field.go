@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CustomFieldType string
+
+const (
+	CustomFieldText     CustomFieldType = "text"
+	CustomFieldDropdown CustomFieldType = "dropdown"
+	CustomFieldNumber   CustomFieldType = "number"
+	// Date values are sent as YYYY-MM-DD or RFC 3339 and stored as dates.
+	CustomFieldDate CustomFieldType = "date"
+)
+
+// CustomField is an admin-defined ticket field, such as an asset tag or a
+// building and floor. Values are kept in Ticket.CustomFields under Key.
+type CustomField struct {
+	Key         string          `json:"key" bson:"_id"`
+	Label       string          `json:"label" bson:"label"`
+	Type        CustomFieldType `json:"type" bson:"type"`
+	Description string          `json:"description,omitempty" bson:"description,omitempty"`
+	// Options are the choices of a dropdown.
+	Options  []string `json:"options,omitempty" bson:"options,omitempty"`
+	Required bool     `json:"required" bson:"required"`
+	// Categories limits the field to tickets of these categories; empty
+	// means all tickets.
+	Categories []TicketCategory   `json:"categories,omitempty" bson:"categories,omitempty"`
+	UpdatedAt  time.Time          `json:"updatedAt" bson:"updatedAt"`
+	UpdatedBy  primitive.ObjectID `json:"updatedBy" bson:"updatedBy"`
+}
+
+type CustomFieldRequest struct {
+	Label       string           `json:"label" binding:"required"`
+	Type        CustomFieldType  `json:"type" binding:"required"`
+	Description string           `json:"description"`
+	Options     []string         `json:"options"`
+	Required    bool             `json:"required"`
+	Categories  []TicketCategory `json:"categories"`
+}
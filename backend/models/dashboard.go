@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DashboardTicket is a ticket as listed on the mobile home screen.
+// SLADueAt is when the ticket breaches its response target (while it awaits
+// acceptance) or its resolution target, or its due date if that is sooner.
+type DashboardTicket struct {
+	ID          primitive.ObjectID `json:"id"`
+	Title       string             `json:"title"`
+	Status      TicketStatus       `json:"status"`
+	Priority    TicketPriority     `json:"priority"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	SLADueAt    *time.Time         `json:"slaDueAt,omitempty"`
+	SLABreached bool               `json:"slaBreached"`
+}
+
+// MyDashboard is a technician's day in one payload: open tickets assigned
+// to them, most urgent first, those they have not accepted yet, reminders
+// due by the end of today and unread notifications.
+type MyDashboard struct {
+	AssignedTickets     []DashboardTicket  `json:"assignedTickets"`
+	AwaitingAcceptance  []DashboardTicket  `json:"awaitingAcceptance"`
+	RemindersDueToday   []AgendaItem       `json:"remindersDueToday"`
+	UnreadNotifications []UserNotification `json:"unreadNotifications"`
+	UnreadCount         int64              `json:"unreadCount"`
+	GeneratedAt         time.Time          `json:"generatedAt"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DeflectionOutcome string
+
+const (
+	// DeflectionPending attempts have not been followed by a ticket or a
+	// self-resolution yet; most are abandoned drafts.
+	DeflectionPending      DeflectionOutcome = "pending"
+	DeflectionProceeded    DeflectionOutcome = "proceeded"
+	DeflectionSelfResolved DeflectionOutcome = "self_resolved"
+)
+
+// DeflectionAttempt records the suggestions shown for a ticket draft before
+// it was submitted, and whether the user went on to raise the ticket.
+type DeflectionAttempt struct {
+	ID          primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID   `json:"userId" bson:"userId"`
+	Role        UserRole             `json:"role" bson:"role"`
+	Title       string               `json:"title" bson:"title"`
+	Description string               `json:"description" bson:"description"`
+	ArticleIDs  []primitive.ObjectID `json:"articleIds" bson:"articleIds"`
+	// Documents are the titles of the indexed documents shown to staff.
+	Documents []string          `json:"documents,omitempty" bson:"documents,omitempty"`
+	Outcome   DeflectionOutcome `json:"outcome" bson:"outcome"`
+	// ArticleID is the article that solved the issue, when the user said so.
+	ArticleID  *primitive.ObjectID `json:"articleId,omitempty" bson:"articleId,omitempty"`
+	TicketID   *primitive.ObjectID `json:"ticketId,omitempty" bson:"ticketId,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt" bson:"createdAt"`
+	ResolvedAt *time.Time          `json:"resolvedAt,omitempty" bson:"resolvedAt,omitempty"`
+}
+
+type PreflightRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description" binding:"required"`
+}
+
+// PreflightResponse is returned for a ticket draft. Pass PreflightID when
+// creating the ticket, or mark it self-resolved if a suggestion helped.
+type PreflightResponse struct {
+	PreflightID primitive.ObjectID     `json:"preflightId"`
+	Articles    []ArticleSuggestion    `json:"articles"`
+	Documents   []DocumentSearchResult `json:"documents,omitempty"`
+}
+
+type SelfResolveRequest struct {
+	// ArticleID is the suggested article that solved the issue, if any.
+	ArticleID string `json:"articleId"`
+}
+
+// DeflectionStats summarizes preflight outcomes over a period.
+type DeflectionStats struct {
+	Since        time.Time `json:"since"`
+	Attempts     int64     `json:"attempts"`
+	Proceeded    int64     `json:"proceeded"`
+	SelfResolved int64     `json:"selfResolved"`
+	Pending      int64     `json:"pending"`
+	// WithSuggestions counts attempts that were shown at least one article.
+	WithSuggestions int64 `json:"withSuggestions"`
+	// DeflectionRate is self-resolved attempts over attempts that reached
+	// an outcome.
+	DeflectionRate float64             `json:"deflectionRate"`
+	TopArticles    []ArticleDeflection `json:"topArticles"`
+}
+
+// ArticleDeflection counts the issues an article resolved.
+type ArticleDeflection struct {
+	ArticleID    primitive.ObjectID `json:"articleId"`
+	Title        string             `json:"title"`
+	SelfResolved int64              `json:"selfResolved"`
+}
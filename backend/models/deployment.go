@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Deployment is a release of a service reported by a CI/CD pipeline. Recent
+// deployments are shown next to incidents as likely causes.
+type Deployment struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Service     string             `json:"service" bson:"service"`
+	Environment string             `json:"environment,omitempty" bson:"environment,omitempty"`
+	Version     string             `json:"version,omitempty" bson:"version,omitempty"`
+	// Status is the pipeline's outcome, e.g. succeeded or failed.
+	Status     string    `json:"status,omitempty" bson:"status,omitempty"`
+	URL        string    `json:"url,omitempty" bson:"url,omitempty"`
+	DeployedBy string    `json:"deployedBy,omitempty" bson:"deployedBy,omitempty"`
+	DeployedAt time.Time `json:"deployedAt" bson:"deployedAt"`
+	ReceivedAt time.Time `json:"receivedAt" bson:"receivedAt"`
+}
+
+// DeploymentEvent is the webhook body a pipeline posts after a deployment.
+// DeployedAt defaults to the time the event is received.
+type DeploymentEvent struct {
+	Service     string     `json:"service" binding:"required"`
+	Environment string     `json:"environment"`
+	Version     string     `json:"version"`
+	Status      string     `json:"status"`
+	URL         string     `json:"url"`
+	DeployedBy  string     `json:"deployedBy"`
+	DeployedAt  *time.Time `json:"deployedAt"`
+}
+
+// RecentDeployments groups the deployments of one service shortly before a
+// ticket was raised. Mentioned is set when the ticket names the service.
+type RecentDeployments struct {
+	Service        string       `json:"service"`
+	Count          int          `json:"count"`
+	LastDeployedAt time.Time    `json:"lastDeployedAt"`
+	Mentioned      bool         `json:"mentioned"`
+	Summary        string       `json:"summary"`
+	Deployments    []Deployment `json:"deployments"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PushProviderFCM  = "fcm"
+	PushProviderAPNs = "apns"
+)
+
+// DeviceToken is a mobile device registered for push notifications. A token
+// belongs to one user at a time; registering it again moves it.
+type DeviceToken struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"userId" bson:"userId"`
+	Provider   string             `json:"provider" bson:"provider"`
+	Token      string             `json:"-" bson:"token"`
+	Platform   string             `json:"platform,omitempty" bson:"platform,omitempty"`
+	DeviceName string             `json:"deviceName,omitempty" bson:"deviceName,omitempty"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+	LastSeenAt time.Time          `json:"lastSeenAt" bson:"lastSeenAt"`
+}
+
+type RegisterDeviceRequest struct {
+	Token      string `json:"token" binding:"required"`
+	Provider   string `json:"provider" binding:"required,oneof=fcm apns"`
+	Platform   string `json:"platform"`
+	DeviceName string `json:"deviceName"`
+}
+
+// UserNotification is a notification in a user's in-app inbox. Every push
+// is kept here too, so users without a registered device still see it.
+type UserNotification struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	UserID    primitive.ObjectID `json:"userId" bson:"userId"`
+	Title     string             `json:"title" bson:"title"`
+	Body      string             `json:"body,omitempty" bson:"body,omitempty"`
+	Data      map[string]string  `json:"data,omitempty" bson:"data,omitempty"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	ReadAt    *time.Time         `json:"readAt,omitempty" bson:"readAt,omitempty"`
+}
+
+type MarkNotificationsReadRequest struct {
+	// IDs of the notifications to mark read; all of them when empty.
+	IDs []string `json:"ids"`
+}
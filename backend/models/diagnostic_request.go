@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DiagnosticRequestStatus string
+
+const (
+	DiagnosticRequestPending  DiagnosticRequestStatus = "pending"
+	DiagnosticRequestReceived DiagnosticRequestStatus = "received"
+)
+
+// Items a technician can ask for in a diagnostic data request.
+const (
+	DiagnosticItemSystem    = "system"
+	DiagnosticItemNetwork   = "network"
+	DiagnosticItemEventLogs = "event_logs"
+)
+
+// DiagnosticRequest asks the requester, or an agent on their machine, to
+// upload system information through a signed single-use link. The token
+// itself is never stored.
+type DiagnosticRequest struct {
+	ID          primitive.ObjectID      `json:"id" bson:"_id,omitempty"`
+	TicketID    primitive.ObjectID      `json:"ticketId" bson:"ticketId"`
+	Items       []string                `json:"items" bson:"items"`
+	Message     string                  `json:"message,omitempty" bson:"message,omitempty"`
+	Status      DiagnosticRequestStatus `json:"status" bson:"status"`
+	ExpiresAt   time.Time               `json:"expiresAt" bson:"expiresAt"`
+	RequestedBy primitive.ObjectID      `json:"requestedBy" bson:"requestedBy"`
+	RequestedAt time.Time               `json:"requestedAt" bson:"requestedAt"`
+	ReceivedAt  *time.Time              `json:"receivedAt,omitempty" bson:"receivedAt,omitempty"`
+	ReceivedIP  string                  `json:"receivedIp,omitempty" bson:"receivedIp,omitempty"`
+	// AgentID is set when the request is routed to an endpoint agent.
+	AgentID *primitive.ObjectID `json:"agentId,omitempty" bson:"agentId,omitempty"`
+}
+
+// SystemInfo is the diagnostic data uploaded for a ticket. Large text fields
+// are truncated on upload.
+type SystemInfo struct {
+	RequestID    primitive.ObjectID `json:"requestId" bson:"requestId"`
+	Hostname     string             `json:"hostname,omitempty" bson:"hostname,omitempty"`
+	OS           string             `json:"os,omitempty" bson:"os,omitempty"`
+	OSVersion    string             `json:"osVersion,omitempty" bson:"osVersion,omitempty"`
+	Architecture string             `json:"architecture,omitempty" bson:"architecture,omitempty"`
+	// IPConfig is the output of ipconfig /all, ifconfig or ip addr.
+	IPConfig string `json:"ipConfig,omitempty" bson:"ipConfig,omitempty"`
+	// EventLogs holds recent system or application log entries.
+	EventLogs string            `json:"eventLogs,omitempty" bson:"eventLogs,omitempty"`
+	Extra     map[string]string `json:"extra,omitempty" bson:"extra,omitempty"`
+	// Source is "requester" for manual uploads or the agent's name.
+	Source      string    `json:"source,omitempty" bson:"source,omitempty"`
+	CollectedAt time.Time `json:"collectedAt" bson:"collectedAt"`
+}
+
+type CreateDiagnosticRequestRequest struct {
+	// Items defaults to all of system, network and event_logs.
+	Items   []string `json:"items"`
+	Message string   `json:"message"`
+	// AgentID routes the request to an endpoint agent; it defaults to the
+	// agent linked to the ticket.
+	AgentID string `json:"agentId"`
+	// ExpiresIn is a Go duration such as "48h"; the share link default
+	// applies when empty.
+	ExpiresIn string `json:"expiresIn"`
+}
+
+// DiagnosticRequestView is what the link shows to whoever collects the data.
+type DiagnosticRequestView struct {
+	TicketTitle string    `json:"ticketTitle"`
+	Items       []string  `json:"items"`
+	Message     string    `json:"message,omitempty"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
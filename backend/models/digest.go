@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Digest is a weekly operations summary combining ticket analytics with
+// monitoring anomalies.
+type Digest struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	PeriodStart time.Time          `json:"periodStart" bson:"periodStart"`
+	PeriodEnd   time.Time          `json:"periodEnd" bson:"periodEnd"`
+	Stats       DigestStats        `json:"stats" bson:"stats"`
+	Narrative   string             `json:"narrative" bson:"narrative"`
+	// Fallback is true when the narrative was built from a template because
+	// no LLM was available.
+	Fallback      bool      `json:"fallback" bson:"fallback"`
+	DeliveredVia  []string  `json:"deliveredVia,omitempty" bson:"deliveredVia,omitempty"`
+	DeliveryError string    `json:"deliveryError,omitempty" bson:"deliveryError,omitempty"`
+	CreatedAt     time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+type DigestStats struct {
+	TicketsCreated     int64            `json:"ticketsCreated" bson:"ticketsCreated"`
+	PreviousCreated    int64            `json:"previousCreated" bson:"previousCreated"`
+	TicketsResolved    int64            `json:"ticketsResolved" bson:"ticketsResolved"`
+	OpenBacklog        int64            `json:"openBacklog" bson:"openBacklog"`
+	AvgResolutionHours float64          `json:"avgResolutionHours" bson:"avgResolutionHours"`
+	Categories         []DigestTrend    `json:"categories" bson:"categories"`
+	Subcategories      []DigestTrend    `json:"subcategories" bson:"subcategories"`
+	Anomalies          int64            `json:"anomalies" bson:"anomalies"`
+	AnomalySeverities  map[string]int64 `json:"anomalySeverities" bson:"anomalySeverities"`
+	AnomalyResources   []DigestTrend    `json:"anomalyResources" bson:"anomalyResources"`
+}
+
+// DigestTrend compares a count in the digest period with the week before.
+type DigestTrend struct {
+	Name     string `json:"name" bson:"name"`
+	Count    int64  `json:"count" bson:"count"`
+	Previous int64  `json:"previous" bson:"previous"`
+	// ChangePct is the change against the previous week; 0 when there was no
+	// previous activity.
+	ChangePct float64 `json:"changePct" bson:"changePct"`
+}
@@ -17,6 +17,50 @@ type Document struct {
 	Chunks    []DocumentChunk    `json:"chunks" bson:"chunks"`
 	IndexedAt time.Time          `json:"indexedAt" bson:"indexedAt"`
 	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+	// ContentHash identifies copies of the same text.
+	ContentHash string `json:"contentHash,omitempty" bson:"contentHash,omitempty"`
+	// DuplicatePaths are files found to be copies of this document, which
+	// were merged into it rather than indexed again.
+	DuplicatePaths []string `json:"duplicatePaths,omitempty" bson:"duplicatePaths,omitempty"`
+	// OwnerID is the user who keeps the document current; review tickets
+	// are assigned to them.
+	OwnerID *primitive.ObjectID `json:"ownerId,omitempty" bson:"ownerId,omitempty"`
+	// ContentChangedAt is when the indexed text last changed, unlike
+	// IndexedAt which every re-index moves.
+	ContentChangedAt *time.Time `json:"contentChangedAt,omitempty" bson:"contentChangedAt,omitempty"`
+	// LastReviewedAt and ReviewedBy record when someone last confirmed the
+	// document is still accurate.
+	LastReviewedAt *time.Time          `json:"lastReviewedAt,omitempty" bson:"lastReviewedAt,omitempty"`
+	ReviewedBy     *primitive.ObjectID `json:"reviewedBy,omitempty" bson:"reviewedBy,omitempty"`
+	// ReviewTicketID is the ticket last opened asking for a review.
+	ReviewTicketID *primitive.ObjectID `json:"reviewTicketId,omitempty" bson:"reviewTicketId,omitempty"`
+	// Uploaded documents go through approval before they can be cited in
+	// solutions. Documents indexed from the docs folder have no status and
+	// count as approved.
+	ApprovalStatus DocumentApprovalStatus `json:"approvalStatus,omitempty" bson:"approvalStatus,omitempty"`
+	UploadedBy     *primitive.ObjectID    `json:"uploadedBy,omitempty" bson:"uploadedBy,omitempty"`
+	// ReviewerID is the user asked to approve the document.
+	ReviewerID  *primitive.ObjectID `json:"reviewerId,omitempty" bson:"reviewerId,omitempty"`
+	SubmittedAt *time.Time          `json:"submittedAt,omitempty" bson:"submittedAt,omitempty"`
+	// DecidedBy and DecidedAt record who approved or rejected the document,
+	// and ApprovalNote why it was rejected.
+	DecidedBy    *primitive.ObjectID `json:"decidedBy,omitempty" bson:"decidedBy,omitempty"`
+	DecidedAt    *time.Time          `json:"decidedAt,omitempty" bson:"decidedAt,omitempty"`
+	ApprovalNote string              `json:"approvalNote,omitempty" bson:"approvalNote,omitempty"`
+}
+
+type DocumentApprovalStatus string
+
+const (
+	DocumentDraft    DocumentApprovalStatus = "draft"
+	DocumentPending  DocumentApprovalStatus = "pending"
+	DocumentApproved DocumentApprovalStatus = "approved"
+	DocumentRejected DocumentApprovalStatus = "rejected"
+)
+
+// Approved reports whether the document may be cited in solutions.
+func (d Document) Approved() bool {
+	return d.ApprovalStatus == "" || d.ApprovalStatus == DocumentApproved
 }
 
 type DocumentChunk struct {
@@ -32,6 +76,9 @@ type DocumentSearchRequest struct {
 	TopK      int      `json:"topK"`
 	FileTypes []string `json:"fileTypes"`
 	MinScore  float32  `json:"minScore"`
+	// Raw searches for the query as written, without spell correction or
+	// glossary expansion
+	Raw bool `json:"raw"`
 }
 
 type DocumentSearchResult struct {
@@ -47,6 +94,21 @@ type TicketSolution struct {
 	DocumentSources []DocumentSearchResult  `json:"documentSources"`
 	Confidence      float32                 `json:"confidence"`
 	GeneratedAt     time.Time               `json:"generatedAt"`
+	ContextUsage    ContextUsage            `json:"contextUsage"`
+}
+
+// ContextUsage records how much of the model's context window a solutions
+// prompt used. Token counts are estimates.
+type ContextUsage struct {
+	Model           string `json:"model"`
+	ContextLimit    int    `json:"contextLimit"`
+	ReservedTokens  int    `json:"reservedTokens"`
+	PromptTokens    int    `json:"promptTokens"`
+	BudgetTokens    int    `json:"budgetTokens"`
+	ContextTokens   int    `json:"contextTokens"`
+	ChunksAvailable int    `json:"chunksAvailable"`
+	ChunksUsed      int    `json:"chunksUsed"`
+	ChunksTrimmed   int    `json:"chunksTrimmed"`
 }
 
 type SuggestedSolution struct {
@@ -55,6 +117,8 @@ type SuggestedSolution struct {
 	Steps       []string `json:"steps"`
 	References  []string `json:"references"`
 	Confidence  float32  `json:"confidence"`
+	// Automations marks steps a registered runbook can execute.
+	Automations []StepAutomation `json:"automations,omitempty"`
 }
 
 type IndexRequest struct {
@@ -62,13 +126,165 @@ type IndexRequest struct {
 }
 
 type IndexResponse struct {
-	Message   string     `json:"message"`
-	Count     int        `json:"count"`
-	Documents []Document `json:"documents"`
+	Message    string              `json:"message"`
+	Count      int                 `json:"count"`
+	Documents  []Document          `json:"documents"`
+	Embedding  EmbeddingStats      `json:"embedding"`
+	Duplicates []DocumentDuplicate `json:"duplicates,omitempty"`
+}
+
+// Kinds of duplicate found while indexing.
+const (
+	DuplicateExact  = "exact"  // same text as an indexed document
+	DuplicateNear   = "near"   // every chunk duplicates indexed chunks
+	DuplicateChunks = "chunks" // some chunks duplicate indexed ones
+)
+
+// DocumentDuplicate reports a file that duplicated indexed content. Exact
+// and near duplicates are merged into DuplicateOf and not indexed on their
+// own; for partial duplicates only the repeated chunks are skipped.
+type DocumentDuplicate struct {
+	FilePath      string              `json:"filePath"`
+	Kind          string              `json:"kind"`
+	DuplicateOf   string              `json:"duplicateOf,omitempty"`
+	DuplicateOfID *primitive.ObjectID `json:"duplicateOfId,omitempty"`
+	Similarity    float32             `json:"similarity,omitempty"`
+	SkippedChunks int                 `json:"skippedChunks"`
+	// Merged is true when the file was not indexed as a document of its own.
+	Merged bool `json:"merged"`
+}
+
+// EmbeddingStats reports the embedding work done while indexing.
+type EmbeddingStats struct {
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model"`
+	Chunks           int     `json:"chunks"`
+	Requests         int     `json:"requests"`
+	FailedRequests   int     `json:"failedRequests"`
+	Tokens           int     `json:"tokens"`
+	DurationMs       int64   `json:"durationMs"`
+	ChunksPerSecond  float64 `json:"chunksPerSecond"`
+	EstimatedCostUSD float64 `json:"estimatedCostUsd"`
+}
+
+// Add accumulates the stats of another embedding run.
+func (s *EmbeddingStats) Add(o EmbeddingStats) {
+	if s.Provider == "" {
+		s.Provider, s.Model = o.Provider, o.Model
+	}
+	s.Chunks += o.Chunks
+	s.Requests += o.Requests
+	s.FailedRequests += o.FailedRequests
+	s.Tokens += o.Tokens
+	s.DurationMs += o.DurationMs
+	s.EstimatedCostUSD += o.EstimatedCostUSD
+	if s.DurationMs > 0 {
+		s.ChunksPerSecond = float64(s.Chunks) / (float64(s.DurationMs) / 1000)
+	}
 }
 
 type UploadResponse struct {
-	Message  string   `json:"message"`
-	Document Document `json:"document"`
+	Message   string             `json:"message"`
+	Document  Document           `json:"document"`
+	Embedding EmbeddingStats     `json:"embedding"`
+	Duplicate *DocumentDuplicate `json:"duplicate,omitempty"`
+}
+
+// DocumentTree is the docs folder as stored on disk, with the indexing
+// status of each folder and file. Indexed documents that do not come from
+// the folder, such as knowledge base articles, are grouped into
+// collections by the first segment of their path.
+type DocumentTree struct {
+	Root        DocumentFolder   `json:"root"`
+	Collections []DocumentFolder `json:"collections"`
 }
 
+// Indexing status of a document or folder.
+const (
+	DocumentIndexed    = "indexed"
+	DocumentStale      = "stale"       // changed on disk since it was indexed
+	DocumentNotIndexed = "not_indexed" // on disk, not in the index
+	DocumentMissing    = "missing"     // in the index, no longer on disk
+	DocumentPartial    = "partial"     // folders mixing the above
+	DocumentEmpty      = "empty"
+)
+
+// DocumentFolder counts the documents in a folder and its subfolders.
+type DocumentFolder struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	// Files are supported files on disk; Indexed counts those in the index
+	// and Stale those of them changed since.
+	Files         int              `json:"files"`
+	Indexed       int              `json:"indexed"`
+	Stale         int              `json:"stale"`
+	Missing       int              `json:"missing"`
+	Chunks        int              `json:"chunks"`
+	LastIndexedAt *time.Time       `json:"lastIndexedAt,omitempty"`
+	Documents     []DocumentEntry  `json:"documents,omitempty"`
+	Folders       []DocumentFolder `json:"folders"`
+}
+
+// DocumentEntry is a document directly in a folder.
+type DocumentEntry struct {
+	ID         *primitive.ObjectID `json:"id,omitempty"`
+	Name       string              `json:"name"`
+	Title      string              `json:"title,omitempty"`
+	Path       string              `json:"path"`
+	FileType   string              `json:"fileType"`
+	Status     string              `json:"status"`
+	Size       int64               `json:"size,omitempty"`
+	Chunks     int                 `json:"chunks"`
+	ModifiedAt *time.Time          `json:"modifiedAt,omitempty"`
+	IndexedAt  *time.Time          `json:"indexedAt,omitempty"`
+}
+
+// Reasons a document needs reviewing
+const (
+	StaleReasonAge             = "age"
+	StaleReasonDeprecatedTerms = "deprecated_terms"
+)
+
+// StaleDocument is an indexed document due for review.
+type StaleDocument struct {
+	ID             primitive.ObjectID  `json:"id"`
+	Title          string              `json:"title"`
+	FilePath       string              `json:"filePath"`
+	OwnerID        *primitive.ObjectID `json:"ownerId,omitempty"`
+	LastReviewedAt *time.Time          `json:"lastReviewedAt,omitempty"`
+	// Since is when the document was last reviewed, or failing that last
+	// changed.
+	Since   time.Time `json:"since"`
+	AgeDays int       `json:"ageDays"`
+	Reasons []string  `json:"reasons"`
+	// DeprecatedTerms are the deprecated product names the document
+	// mentions.
+	DeprecatedTerms []string            `json:"deprecatedTerms,omitempty"`
+	ReviewTicketID  *primitive.ObjectID `json:"reviewTicketId,omitempty"`
+}
+
+// DocumentReviewRun is the outcome of a pass over the indexed documents
+// opening review tickets.
+type DocumentReviewRun struct {
+	Stale         int `json:"stale"`
+	TicketsOpened int `json:"ticketsOpened"`
+}
+
+// DocumentOwnerRequest sets a document's owner and reviewer. Omitted
+// fields are left alone and empty ones cleared.
+type DocumentOwnerRequest struct {
+	OwnerID    *string `json:"ownerId"`
+	ReviewerID *string `json:"reviewerId"`
+}
+
+// DocumentSubmitRequest submits a document for approval, optionally by a
+// given reviewer.
+type DocumentSubmitRequest struct {
+	ReviewerID string `json:"reviewerId"`
+}
+
+// DocumentRejectRequest sends a document back to its owner.
+type DocumentRejectRequest struct {
+	Note string `json:"note" binding:"required"`
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EndpointAgent is a lightweight agent installed on an end-user machine or
+// server. It checks in to /api/endpoint-agents/<id>/checkin with its token,
+// reporting inventory and health, and picks up diagnostic requests routed to
+// it.
+type EndpointAgent struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name     string             `json:"name" bson:"name"`
+	Token    string             `json:"token,omitempty" bson:"token"`
+	Enabled  bool               `json:"enabled" bson:"enabled"`
+	AssetTag string             `json:"assetTag,omitempty" bson:"assetTag,omitempty"`
+	// ResourceID links the machine to a monitored resource; tickets linked
+	// to the agent are linked to the resource as well.
+	ResourceID    *primitive.ObjectID `json:"resourceId,omitempty" bson:"resourceId,omitempty"`
+	Inventory     EndpointInventory   `json:"inventory" bson:"inventory"`
+	Health        EndpointHealth      `json:"health" bson:"health"`
+	AgentVersion  string              `json:"agentVersion,omitempty" bson:"agentVersion,omitempty"`
+	LastCheckInAt *time.Time          `json:"lastCheckInAt,omitempty" bson:"lastCheckInAt,omitempty"`
+	LastCheckInIP string              `json:"lastCheckInIp,omitempty" bson:"lastCheckInIp,omitempty"`
+	CreatedBy     primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	CreatedAt     time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt     time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+type EndpointInventory struct {
+	Hostname     string   `json:"hostname,omitempty" bson:"hostname,omitempty"`
+	OS           string   `json:"os,omitempty" bson:"os,omitempty"`
+	OSVersion    string   `json:"osVersion,omitempty" bson:"osVersion,omitempty"`
+	Architecture string   `json:"architecture,omitempty" bson:"architecture,omitempty"`
+	Manufacturer string   `json:"manufacturer,omitempty" bson:"manufacturer,omitempty"`
+	Model        string   `json:"model,omitempty" bson:"model,omitempty"`
+	SerialNumber string   `json:"serialNumber,omitempty" bson:"serialNumber,omitempty"`
+	CPU          string   `json:"cpu,omitempty" bson:"cpu,omitempty"`
+	MemoryMB     int      `json:"memoryMb,omitempty" bson:"memoryMb,omitempty"`
+	IPAddresses  []string `json:"ipAddresses,omitempty" bson:"ipAddresses,omitempty"`
+	LoggedInUser string   `json:"loggedInUser,omitempty" bson:"loggedInUser,omitempty"`
+	// Software is the installed software, matched against vulnerability
+	// advisories.
+	Software []InstalledSoftware `json:"software,omitempty" bson:"software,omitempty"`
+}
+
+type InstalledSoftware struct {
+	Name    string `json:"name" bson:"name"`
+	Vendor  string `json:"vendor,omitempty" bson:"vendor,omitempty"`
+	Version string `json:"version,omitempty" bson:"version,omitempty"`
+}
+
+type EndpointHealth struct {
+	Disks     []DiskUsage     `json:"disks,omitempty" bson:"disks,omitempty"`
+	Antivirus AntivirusStatus `json:"antivirus" bson:"antivirus"`
+	// PendingUpdates is the number of OS updates waiting to be installed.
+	PendingUpdates int  `json:"pendingUpdates" bson:"pendingUpdates"`
+	RebootRequired bool `json:"rebootRequired" bson:"rebootRequired"`
+}
+
+type DiskUsage struct {
+	Mount   string  `json:"mount" bson:"mount"`
+	TotalGB float64 `json:"totalGb" bson:"totalGb"`
+	FreeGB  float64 `json:"freeGb" bson:"freeGb"`
+}
+
+type AntivirusStatus struct {
+	Product string `json:"product,omitempty" bson:"product,omitempty"`
+	Enabled bool   `json:"enabled" bson:"enabled"`
+	// UpToDate reports whether definitions are current.
+	UpToDate bool `json:"upToDate" bson:"upToDate"`
+}
+
+type EndpointAgentRequest struct {
+	Name       string  `json:"name" binding:"required"`
+	AssetTag   string  `json:"assetTag"`
+	ResourceID *string `json:"resourceId"`
+	Enabled    *bool   `json:"enabled"`
+}
+
+type AgentCheckInRequest struct {
+	AgentVersion string            `json:"agentVersion"`
+	Inventory    EndpointInventory `json:"inventory"`
+	Health       EndpointHealth    `json:"health"`
+}
+
+// AgentDiagnosticTask is a diagnostic request routed to an agent. The agent
+// collects the items and posts them to UploadPath.
+type AgentDiagnosticTask struct {
+	RequestID  primitive.ObjectID `json:"requestId"`
+	TicketID   primitive.ObjectID `json:"ticketId"`
+	Items      []string           `json:"items"`
+	UploadPath string             `json:"uploadPath"`
+	ExpiresAt  time.Time          `json:"expiresAt"`
+}
+
+type AgentCheckInResponse struct {
+	// NextCheckIn is how long the agent should wait before checking in again.
+	NextCheckIn string                `json:"nextCheckIn"`
+	Diagnostics []AgentDiagnosticTask `json:"diagnostics"`
+}
+
+// EndpointAgentStatus is the fleet view state derived from the last check-in.
+type EndpointAgentStatus string
+
+const (
+	AgentHealthy   EndpointAgentStatus = "healthy"
+	AgentAttention EndpointAgentStatus = "attention"
+	AgentStale     EndpointAgentStatus = "stale"
+)
+
+// EndpointAgentView is an agent with its derived status and health issues.
+type EndpointAgentView struct {
+	EndpointAgent
+	Status EndpointAgentStatus `json:"status"`
+	Issues []string            `json:"issues,omitempty"`
+}
+
+// FleetSummary counts agents by status and health issue.
+type FleetSummary struct {
+	Total          int            `json:"total"`
+	ByStatus       map[string]int `json:"byStatus"`
+	ByOS           map[string]int `json:"byOs"`
+	LowDisk        int            `json:"lowDisk"`
+	AntivirusIssue int            `json:"antivirusIssue"`
+	PendingUpdates int            `json:"pendingUpdates"`
+	RebootRequired int            `json:"rebootRequired"`
+}
+
+type LinkEndpointAgentRequest struct {
+	// AgentID is empty to unlink the ticket.
+	AgentID string `json:"agentId"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EvalDataset is a labeled set of tickets and search queries used to measure
+// triage and retrieval quality offline.
+type EvalDataset struct {
+	ID             primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Name           string              `json:"name" bson:"name"`
+	Description    string              `json:"description,omitempty" bson:"description,omitempty"`
+	TriageCases    []TriageEvalCase    `json:"triageCases" bson:"triageCases"`
+	RetrievalCases []RetrievalEvalCase `json:"retrievalCases" bson:"retrievalCases"`
+	CreatedBy      primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	CreatedAt      time.Time           `json:"createdAt" bson:"createdAt"`
+}
+
+type TriageEvalCase struct {
+	Title            string         `json:"title" bson:"title"`
+	Description      string         `json:"description" bson:"description"`
+	ExpectedCategory TicketCategory `json:"expectedCategory" bson:"expectedCategory"`
+	ExpectedPriority TicketPriority `json:"expectedPriority,omitempty" bson:"expectedPriority,omitempty"`
+}
+
+// RetrievalEvalCase lists the documents (by title or file path) that a good
+// search for Query should return.
+type RetrievalEvalCase struct {
+	Query        string   `json:"query" bson:"query"`
+	RelevantDocs []string `json:"relevantDocs" bson:"relevantDocs"`
+}
+
+type CreateEvalDatasetRequest struct {
+	Name           string              `json:"name" binding:"required"`
+	Description    string              `json:"description"`
+	TriageCases    []TriageEvalCase    `json:"triageCases"`
+	RetrievalCases []RetrievalEvalCase `json:"retrievalCases"`
+}
+
+type RunEvaluationRequest struct {
+	Options TriageOptions `json:"options"`
+	TopK    int           `json:"topK"`
+}
+
+// EvalRun stores the metrics of one evaluation of a dataset.
+type EvalRun struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	DatasetID      primitive.ObjectID `json:"datasetId" bson:"datasetId"`
+	DatasetName    string             `json:"datasetName" bson:"datasetName"`
+	Options        TriageOptions      `json:"options" bson:"options"`
+	TopK           int                `json:"topK" bson:"topK"`
+	Triage         TriageEvalMetrics  `json:"triage" bson:"triage"`
+	Retrieval      RetrievalMetrics   `json:"retrieval" bson:"retrieval"`
+	TriageFailures []TriageEvalResult `json:"triageFailures,omitempty" bson:"triageFailures,omitempty"`
+	StartedBy      primitive.ObjectID `json:"startedBy" bson:"startedBy"`
+	StartedAt      time.Time          `json:"startedAt" bson:"startedAt"`
+	CompletedAt    time.Time          `json:"completedAt" bson:"completedAt"`
+}
+
+type TriageEvalMetrics struct {
+	Cases            int                        `json:"cases" bson:"cases"`
+	CategoryAccuracy float64                    `json:"categoryAccuracy" bson:"categoryAccuracy"`
+	PriorityAccuracy float64                    `json:"priorityAccuracy" bson:"priorityAccuracy"`
+	MacroPrecision   float64                    `json:"macroPrecision" bson:"macroPrecision"`
+	MacroRecall      float64                    `json:"macroRecall" bson:"macroRecall"`
+	PerCategory      map[string]PrecisionRecall `json:"perCategory" bson:"perCategory"`
+	FallbackRate     float64                    `json:"fallbackRate" bson:"fallbackRate"`
+	AvgLatencyMs     float64                    `json:"avgLatencyMs" bson:"avgLatencyMs"`
+}
+
+type PrecisionRecall struct {
+	Precision float64 `json:"precision" bson:"precision"`
+	Recall    float64 `json:"recall" bson:"recall"`
+	Support   int     `json:"support" bson:"support"`
+}
+
+// TriageEvalResult is kept for cases the pipeline got wrong.
+type TriageEvalResult struct {
+	Title             string         `json:"title" bson:"title"`
+	ExpectedCategory  TicketCategory `json:"expectedCategory" bson:"expectedCategory"`
+	PredictedCategory TicketCategory `json:"predictedCategory" bson:"predictedCategory"`
+	ExpectedPriority  TicketPriority `json:"expectedPriority,omitempty" bson:"expectedPriority,omitempty"`
+	PredictedPriority TicketPriority `json:"predictedPriority" bson:"predictedPriority"`
+}
+
+type RetrievalMetrics struct {
+	Queries      int     `json:"queries" bson:"queries"`
+	PrecisionAtK float64 `json:"precisionAtK" bson:"precisionAtK"`
+	RecallAtK    float64 `json:"recallAtK" bson:"recallAtK"`
+	NDCGAtK      float64 `json:"ndcgAtK" bson:"ndcgAtK"`
+	MRR          float64 `json:"mrr" bson:"mrr"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Experiment splits live triage traffic across variants so prompt and model
+// choices can be compared on real tickets. Only one experiment is active at
+// a time.
+type Experiment struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Name        string              `json:"name" bson:"name"`
+	Description string              `json:"description,omitempty" bson:"description,omitempty"`
+	Active      bool                `json:"active" bson:"active"`
+	Variants    []ExperimentVariant `json:"variants" bson:"variants"`
+	CreatedBy   primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+type ExperimentVariant struct {
+	Name string `json:"name" bson:"name"`
+	// Weight is the relative share of traffic, e.g. 50/50 or 90/10.
+	Weight  int           `json:"weight" bson:"weight"`
+	Options TriageOptions `json:"options" bson:"options"`
+}
+
+// ExperimentRun logs one triage request served by an experiment variant.
+type ExperimentRun struct {
+	ID               primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	ExperimentID     primitive.ObjectID  `json:"experimentId" bson:"experimentId"`
+	Variant          string              `json:"variant" bson:"variant"`
+	Category         TicketCategory      `json:"category" bson:"category"`
+	Priority         TicketPriority      `json:"priority" bson:"priority"`
+	Confidence       float64             `json:"confidence" bson:"confidence"`
+	LatencyMs        int64               `json:"latencyMs" bson:"latencyMs"`
+	Fallback         bool                `json:"fallback" bson:"fallback"`
+	PromptTokens     int                 `json:"promptTokens" bson:"promptTokens"`
+	CompletionTokens int                 `json:"completionTokens" bson:"completionTokens"`
+	CostUSD          float64             `json:"costUsd" bson:"costUsd"`
+	TicketID         *primitive.ObjectID `json:"ticketId,omitempty" bson:"ticketId,omitempty"`
+	Feedback         *TriageFeedback     `json:"feedback,omitempty" bson:"feedback,omitempty"`
+	CreatedAt        time.Time           `json:"createdAt" bson:"createdAt"`
+}
+
+// TriageFeedback records the category and priority a human settled on for a
+// triaged ticket, which is how variant accuracy is measured.
+type TriageFeedback struct {
+	Category        TicketCategory     `json:"category" bson:"category"`
+	Priority        TicketPriority     `json:"priority" bson:"priority"`
+	CategoryCorrect bool               `json:"categoryCorrect" bson:"categoryCorrect"`
+	PriorityCorrect bool               `json:"priorityCorrect" bson:"priorityCorrect"`
+	SubmittedBy     primitive.ObjectID `json:"submittedBy" bson:"submittedBy"`
+	SubmittedAt     time.Time          `json:"submittedAt" bson:"submittedAt"`
+}
+
+type CreateExperimentRequest struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	Variants    []ExperimentVariant `json:"variants" binding:"required,min=2"`
+	Active      bool                `json:"active"`
+}
+
+type TriageFeedbackRequest struct {
+	RunID    string         `json:"runId" binding:"required"`
+	Category TicketCategory `json:"category" binding:"required"`
+	Priority TicketPriority `json:"priority" binding:"required"`
+}
+
+// ExperimentVariantResults aggregates logged runs for one variant.
+type ExperimentVariantResults struct {
+	Variant          string        `json:"variant"`
+	Options          TriageOptions `json:"options"`
+	Runs             int64         `json:"runs"`
+	AvgLatencyMs     float64       `json:"avgLatencyMs"`
+	P95LatencyMs     int64         `json:"p95LatencyMs"`
+	FallbackRate     float64       `json:"fallbackRate"`
+	TotalCostUSD     float64       `json:"totalCostUsd"`
+	AvgCostUSD       float64       `json:"avgCostUsd"`
+	FeedbackCount    int64         `json:"feedbackCount"`
+	CategoryAccuracy float64       `json:"categoryAccuracy"`
+	PriorityAccuracy float64       `json:"priorityAccuracy"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FeatureFlag turns a capability on or off without a deploy. A disabled
+// flag is off for everyone. An enabled flag is on for the listed users, and
+// otherwise for users with one of Roles (any role when empty) who fall in
+// the first Percentage of users. Requests without a user, such as
+// background workers, are bucketed at random.
+type FeatureFlag struct {
+	Key         string               `json:"key" bson:"_id"`
+	Description string               `json:"description,omitempty" bson:"description,omitempty"`
+	Enabled     bool                 `json:"enabled" bson:"enabled"`
+	Roles       []UserRole           `json:"roles,omitempty" bson:"roles,omitempty"`
+	UserIDs     []primitive.ObjectID `json:"userIds,omitempty" bson:"userIds,omitempty"`
+	Percentage  int                  `json:"percentage" bson:"percentage"`
+	// Builtin is set on flags the code defines and that have not been
+	// changed yet; they show their default state.
+	Builtin   bool                `json:"builtin" bson:"-"`
+	UpdatedAt time.Time           `json:"updatedAt,omitempty" bson:"updatedAt"`
+	UpdatedBy *primitive.ObjectID `json:"updatedBy,omitempty" bson:"updatedBy,omitempty"`
+}
+
+type FeatureFlagRequest struct {
+	Description string     `json:"description"`
+	Enabled     bool       `json:"enabled"`
+	Roles       []UserRole `json:"roles"`
+	UserIDs     []string   `json:"userIds"`
+	// Percentage defaults to 100.
+	Percentage *int `json:"percentage"`
+}
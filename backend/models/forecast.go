@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Forecast predicts ticket load for the coming week from historical volume.
+// Lower and Upper bound a ~95% prediction interval.
+type Forecast struct {
+	GeneratedAt              time.Time          `json:"generatedAt"`
+	WeekStart                time.Time          `json:"weekStart"`
+	HistoryWeeks             int                `json:"historyWeeks"`
+	Category                 string             `json:"category,omitempty"`
+	TrendPct                 float64            `json:"trendPct"`
+	TicketsPerTechnicianHour float64            `json:"ticketsPerTechnicianHour"`
+	Expected                 float64            `json:"expected"`
+	Lower                    float64            `json:"lower"`
+	Upper                    float64            `json:"upper"`
+	Days                     []DayForecast      `json:"days"`
+	Hours                    []HourForecast     `json:"hours"`
+	Categories               []CategoryForecast `json:"categories"`
+}
+
+type DayForecast struct {
+	Date     time.Time `json:"date"`
+	Weekday  string    `json:"weekday"`
+	Expected float64   `json:"expected"`
+	Lower    float64   `json:"lower"`
+	Upper    float64   `json:"upper"`
+	// PeakTechnicians is the highest hourly staffing suggestion of the day.
+	PeakTechnicians int `json:"peakTechnicians"`
+}
+
+type HourForecast struct {
+	Start    time.Time `json:"start"`
+	Expected float64   `json:"expected"`
+	Lower    float64   `json:"lower"`
+	Upper    float64   `json:"upper"`
+	// Technicians covers the expected load; TechniciansUpper covers the upper
+	// bound.
+	Technicians      int `json:"technicians"`
+	TechniciansUpper int `json:"techniciansUpper"`
+}
+
+type CategoryForecast struct {
+	Category TicketCategory `json:"category"`
+	Expected float64        `json:"expected"`
+	Lower    float64        `json:"lower"`
+	Upper    float64        `json:"upper"`
+}
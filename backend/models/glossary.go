@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GlossaryTerm is a piece of organization jargon. Document search queries
+// with it get its expansion and synonyms added, and LLM prompts for
+// tickets using it explain it.
+type GlossaryTerm struct {
+	// ID is the term lowercased, so each term has one entry.
+	ID   string `json:"id" bson:"_id"`
+	Term string `json:"term" bson:"term"`
+	// Expansion is what an acronym stands for, such as "multi-factor
+	// authentication" for MFA. A query with either gets the other added.
+	Expansion string `json:"expansion,omitempty" bson:"expansion,omitempty"`
+	// Synonyms are added to queries with the term or any of them.
+	Synonyms []string `json:"synonyms" bson:"synonyms"`
+	// Description explains the term to the AI, such as which Salesforce
+	// instance "CRM" means. It is not added to search queries.
+	Description string              `json:"description,omitempty" bson:"description,omitempty"`
+	UpdatedAt   time.Time           `json:"updatedAt" bson:"updatedAt"`
+	UpdatedBy   *primitive.ObjectID `json:"updatedBy,omitempty" bson:"updatedBy,omitempty"`
+}
+
+type GlossaryTermRequest struct {
+	Expansion   string   `json:"expansion"`
+	Synonyms    []string `json:"synonyms"`
+	Description string   `json:"description"`
+}
+
+// QueryCorrection is a misspelt query word and the corpus word it was
+// replaced with.
+type QueryCorrection struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// QueryExpansion is a glossary term found in a query and the words added
+// for it.
+type QueryExpansion struct {
+	Term  string   `json:"term"`
+	Added []string `json:"added"`
+}
+
+// ProcessedQuery is a search query after spell correction and expansion.
+// Query is what gets embedded.
+type ProcessedQuery struct {
+	Original    string            `json:"original"`
+	Corrected   string            `json:"corrected"`
+	Query       string            `json:"query"`
+	Corrections []QueryCorrection `json:"corrections"`
+	Expansions  []QueryExpansion  `json:"expansions"`
+}
+
+type QueryPreviewRequest struct {
+	Query string `json:"query" binding:"required"`
+}
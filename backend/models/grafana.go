@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Grafana SimpleJSON datasource protocol. The same endpoints also work with
+// the Infinity plugin pointed at /query.
+
+type GrafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+type GrafanaQueryRequest struct {
+	Range         GrafanaRange    `json:"range"`
+	IntervalMs    int64           `json:"intervalMs"`
+	MaxDataPoints int             `json:"maxDataPoints"`
+	Targets       []GrafanaTarget `json:"targets"`
+}
+
+type GrafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type GrafanaTarget struct {
+	Target string `json:"target"`
+	RefID  string `json:"refId"`
+	Type   string `json:"type"`
+}
+
+// GrafanaTimeSeries holds datapoints as [value, unix milliseconds] pairs.
+type GrafanaTimeSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
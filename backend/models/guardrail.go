@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InjectionEvent records untrusted text that looked like an attempt to
+// override the model's instructions before it was placed in a prompt.
+type InjectionEvent struct {
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	// Source is "ticket", "document" or "agent" (tool output).
+	Source   string   `json:"source" bson:"source"`
+	SourceID string   `json:"sourceId,omitempty" bson:"sourceId,omitempty"`
+	Field    string   `json:"field" bson:"field"`
+	Patterns []string `json:"patterns" bson:"patterns"`
+	// Excerpt is the start of the original text, for review.
+	Excerpt   string    `json:"excerpt" bson:"excerpt"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
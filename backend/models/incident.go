@@ -0,0 +1,193 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IncidentStatus string
+type IncidentImpact string
+
+const (
+	IncidentInvestigating IncidentStatus = "investigating"
+	IncidentIdentified    IncidentStatus = "identified"
+	IncidentMonitoring    IncidentStatus = "monitoring"
+	IncidentResolved      IncidentStatus = "resolved"
+
+	ImpactMinor    IncidentImpact = "minor"
+	ImpactMajor    IncidentImpact = "major"
+	ImpactCritical IncidentImpact = "critical"
+)
+
+// Component statuses shown on the status page.
+const (
+	ComponentOperational = "operational"
+	ComponentDegraded    = "degraded_performance"
+	ComponentPartial     = "partial_outage"
+	ComponentMajor       = "major_outage"
+)
+
+// StatusComponent is a service shown on the status page. Monitored
+// resources and ticket categories map incidents onto components; the
+// external IDs link it to the same component on Statuspage or Instatus.
+type StatusComponent struct {
+	ID           primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Name         string               `json:"name" bson:"name"`
+	Description  string               `json:"description,omitempty" bson:"description,omitempty"`
+	Order        int                  `json:"order" bson:"order"`
+	ResourceIDs  []primitive.ObjectID `json:"resourceIds,omitempty" bson:"resourceIds,omitempty"`
+	Categories   []TicketCategory     `json:"categories,omitempty" bson:"categories,omitempty"`
+	StatuspageID string               `json:"statuspageId,omitempty" bson:"statuspageId,omitempty"`
+	InstatusID   string               `json:"instatusId,omitempty" bson:"instatusId,omitempty"`
+	CreatedAt    time.Time            `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time            `json:"updatedAt" bson:"updatedAt"`
+}
+
+type StatusComponentRequest struct {
+	Name         string           `json:"name" binding:"required"`
+	Description  string           `json:"description"`
+	Order        int              `json:"order"`
+	ResourceIDs  []string         `json:"resourceIds"`
+	Categories   []TicketCategory `json:"categories"`
+	StatuspageID string           `json:"statuspageId"`
+	InstatusID   string           `json:"instatusId"`
+}
+
+// Incident is a declared major incident, published to the built-in status
+// page and any configured external status page.
+type Incident struct {
+	ID           primitive.ObjectID    `json:"id" bson:"_id,omitempty"`
+	Title        string                `json:"title" bson:"title"`
+	Status       IncidentStatus        `json:"status" bson:"status"`
+	Impact       IncidentImpact        `json:"impact" bson:"impact"`
+	ComponentIDs []primitive.ObjectID  `json:"componentIds" bson:"componentIds"`
+	TicketIDs    []primitive.ObjectID  `json:"ticketIds,omitempty" bson:"ticketIds,omitempty"`
+	AnomalyIDs   []primitive.ObjectID  `json:"anomalyIds,omitempty" bson:"anomalyIds,omitempty"`
+	Updates      []IncidentUpdate      `json:"updates" bson:"updates"`
+	Publications []IncidentPublication `json:"publications,omitempty" bson:"publications,omitempty"`
+	// Roles maps incident-command roles (commander, comms, ...) to users.
+	Roles map[string]primitive.ObjectID `json:"roles,omitempty" bson:"roles,omitempty"`
+	// Timeline is the internal war-room log; Updates are the public ones.
+	Timeline []IncidentTimelineEntry `json:"timeline" bson:"timeline"`
+	WarRoom  *WarRoom                `json:"warRoom,omitempty" bson:"warRoom,omitempty"`
+	// PostmortemID is set once a postmortem has been drafted.
+	PostmortemID *primitive.ObjectID `json:"postmortemId,omitempty" bson:"postmortemId,omitempty"`
+	DeclaredBy   primitive.ObjectID  `json:"declaredBy" bson:"declaredBy"`
+	CreatedAt    time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time           `json:"updatedAt" bson:"updatedAt"`
+	ResolvedAt   *time.Time          `json:"resolvedAt,omitempty" bson:"resolvedAt,omitempty"`
+}
+
+type IncidentUpdate struct {
+	Status    IncidentStatus     `json:"status" bson:"status"`
+	Message   string             `json:"message" bson:"message"`
+	CreatedBy primitive.ObjectID `json:"createdBy,omitempty" bson:"createdBy"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// Incident-command roles.
+const (
+	RoleCommander = "commander"
+	RoleComms     = "comms"
+	RoleScribe    = "scribe"
+	RoleOpsLead   = "ops_lead"
+)
+
+// Timeline entry types.
+const (
+	TimelineDeclared = "declared"
+	TimelineStatus   = "status"
+	TimelineRole     = "role"
+	TimelineNote     = "note"
+)
+
+type IncidentTimelineEntry struct {
+	Type      string              `json:"type" bson:"type"`
+	Message   string              `json:"message" bson:"message"`
+	CreatedBy *primitive.ObjectID `json:"createdBy,omitempty" bson:"createdBy,omitempty"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+}
+
+// WarRoom is the chat channel created for coordinating an incident.
+type WarRoom struct {
+	Provider  string `json:"provider" bson:"provider"`
+	ChannelID string `json:"channelId,omitempty" bson:"channelId,omitempty"`
+	Name      string `json:"name" bson:"name"`
+	URL       string `json:"url,omitempty" bson:"url,omitempty"`
+	Error     string `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// IncidentPublication tracks the incident on one external status page.
+type IncidentPublication struct {
+	Provider    string    `json:"provider" bson:"provider"`
+	ExternalID  string    `json:"externalId,omitempty" bson:"externalId,omitempty"`
+	Error       string    `json:"error,omitempty" bson:"error,omitempty"`
+	PublishedAt time.Time `json:"publishedAt" bson:"publishedAt"`
+}
+
+// DeclareIncidentRequest declares an incident from correlated anomalies or
+// outage tickets. Components default to those mapped from the anomalies'
+// and tickets' resources and categories.
+type DeclareIncidentRequest struct {
+	Title        string         `json:"title" binding:"required"`
+	Message      string         `json:"message" binding:"required"`
+	Impact       IncidentImpact `json:"impact"`
+	TicketIDs    []string       `json:"ticketIds"`
+	AnomalyIDs   []string       `json:"anomalyIds"`
+	ComponentIDs []string       `json:"componentIds"`
+}
+
+// DeclareTicketIncidentRequest declares an incident from one ticket; the
+// title defaults to the ticket's.
+type DeclareTicketIncidentRequest struct {
+	Title   string         `json:"title"`
+	Message string         `json:"message" binding:"required"`
+	Impact  IncidentImpact `json:"impact"`
+}
+
+type AssignIncidentRoleRequest struct {
+	Role   string `json:"role" binding:"required"`
+	UserID string `json:"userId" binding:"required"`
+}
+
+type IncidentNoteRequest struct {
+	Message string `json:"message" binding:"required"`
+}
+
+type UpdateIncidentRequest struct {
+	Status  IncidentStatus `json:"status" binding:"required"`
+	Message string         `json:"message" binding:"required"`
+	Impact  IncidentImpact `json:"impact"`
+}
+
+// PublicStatus is the unauthenticated status page payload.
+type PublicStatus struct {
+	Status     string            `json:"status"`
+	Components []PublicComponent `json:"components"`
+	Incidents  []PublicIncident  `json:"incidents"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+}
+
+type PublicComponent struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status"`
+}
+
+type PublicIncident struct {
+	ID         primitive.ObjectID `json:"id"`
+	Title      string             `json:"title"`
+	Status     IncidentStatus     `json:"status"`
+	Impact     IncidentImpact     `json:"impact"`
+	Components []string           `json:"components"`
+	Updates    []PublicUpdate     `json:"updates"`
+	CreatedAt  time.Time          `json:"createdAt"`
+	ResolvedAt *time.Time         `json:"resolvedAt,omitempty"`
+}
+
+type PublicUpdate struct {
+	Status    IncidentStatus `json:"status"`
+	Message   string         `json:"message"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
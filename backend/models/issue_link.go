@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IssueProvider string
+
+const (
+	IssueProviderGitHub IssueProvider = "github"
+	IssueProviderGitLab IssueProvider = "gitlab"
+)
+
+// Issue states as last seen in the tracker; GitLab's "opened" is stored as
+// open.
+const (
+	IssueOpen   = "open"
+	IssueClosed = "closed"
+)
+
+// IssueLink ties a software ticket to a GitHub or GitLab issue.
+type IssueLink struct {
+	Provider IssueProvider `json:"provider" bson:"provider"`
+	// Repository is owner/name on GitHub and the project path on GitLab.
+	Repository string             `json:"repository" bson:"repository"`
+	Number     int                `json:"number" bson:"number"`
+	Title      string             `json:"title" bson:"title"`
+	URL        string             `json:"url" bson:"url"`
+	State      string             `json:"state" bson:"state"`
+	LinkedAt   time.Time          `json:"linkedAt" bson:"linkedAt"`
+	LinkedBy   primitive.ObjectID `json:"linkedBy" bson:"linkedBy"`
+	SyncedAt   time.Time          `json:"syncedAt" bson:"syncedAt"`
+}
+
+// LinkIssueRequest links an existing issue to a ticket.
+type LinkIssueRequest struct {
+	Provider   IssueProvider `json:"provider" binding:"required"`
+	Repository string        `json:"repository" binding:"required"`
+	Number     int           `json:"number" binding:"required,min=1"`
+}
+
+// CreateIssueRequest raises an issue from a ticket. The ticket title is
+// used when Title is empty.
+type CreateIssueRequest struct {
+	Provider   IssueProvider `json:"provider" binding:"required"`
+	Repository string        `json:"repository" binding:"required"`
+	Title      string        `json:"title"`
+	Labels     []string      `json:"labels"`
+}
+
+// IssueSyncResult counts the linked issues a pull checked, the tickets it
+// resolved because their issues were closed, and the issues no longer
+// found.
+type IssueSyncResult struct {
+	Checked  int `json:"checked"`
+	Resolved int `json:"resolved"`
+	Missing  int `json:"missing"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ArticleVisibility controls who can read a published knowledge base
+// article.
+type ArticleVisibility string
+
+const (
+	// ArticlePublic articles are suggested to requesters in the portal.
+	ArticlePublic ArticleVisibility = "public"
+	// ArticleInternal articles are only visible to staff.
+	ArticleInternal ArticleVisibility = "internal"
+)
+
+type ArticleStatus string
+
+const (
+	ArticleDraft     ArticleStatus = "draft"
+	ArticlePublished ArticleStatus = "published"
+	ArticleArchived  ArticleStatus = "archived"
+)
+
+// KBArticle is a knowledge base article. Title, Body and the other content
+// fields hold the latest edit; readers outside staff only ever see the
+// PublishedVersion, which is what is indexed for search.
+type KBArticle struct {
+	ID    primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Title string             `json:"title" bson:"title"`
+	// Body is markdown.
+	Body       string            `json:"body" bson:"body"`
+	Category   TicketCategory    `json:"category,omitempty" bson:"category,omitempty"`
+	Tags       []string          `json:"tags,omitempty" bson:"tags,omitempty"`
+	Visibility ArticleVisibility `json:"visibility" bson:"visibility"`
+	Status     ArticleStatus     `json:"status" bson:"status"`
+	// Version is the number of the latest edit, starting at 1.
+	Version          int                 `json:"version" bson:"version"`
+	PublishedVersion int                 `json:"publishedVersion,omitempty" bson:"publishedVersion,omitempty"`
+	PublishedAt      *time.Time          `json:"publishedAt,omitempty" bson:"publishedAt,omitempty"`
+	PublishedBy      *primitive.ObjectID `json:"publishedBy,omitempty" bson:"publishedBy,omitempty"`
+	CreatedBy        primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	UpdatedBy        primitive.ObjectID  `json:"updatedBy" bson:"updatedBy"`
+	CreatedAt        time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt        time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// KBArticleVersion is a snapshot of an article's content at one edit.
+type KBArticleVersion struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ArticleID  primitive.ObjectID `json:"articleId" bson:"articleId"`
+	Version    int                `json:"version" bson:"version"`
+	Title      string             `json:"title" bson:"title"`
+	Body       string             `json:"body" bson:"body"`
+	Category   TicketCategory     `json:"category,omitempty" bson:"category,omitempty"`
+	Tags       []string           `json:"tags,omitempty" bson:"tags,omitempty"`
+	Visibility ArticleVisibility  `json:"visibility" bson:"visibility"`
+	// Note describes the edit, like a commit message.
+	Note     string             `json:"note,omitempty" bson:"note,omitempty"`
+	EditedBy primitive.ObjectID `json:"editedBy" bson:"editedBy"`
+	EditedAt time.Time          `json:"editedAt" bson:"editedAt"`
+}
+
+type KBArticleRequest struct {
+	Title      string            `json:"title" binding:"required"`
+	Body       string            `json:"body" binding:"required"`
+	Category   TicketCategory    `json:"category"`
+	Tags       []string          `json:"tags"`
+	Visibility ArticleVisibility `json:"visibility"`
+	Note       string            `json:"note"`
+}
+
+// PublicArticle is the published version of a public article as requesters
+// see it.
+type PublicArticle struct {
+	ID          primitive.ObjectID `json:"id"`
+	Title       string             `json:"title"`
+	Body        string             `json:"body"`
+	Category    TicketCategory     `json:"category,omitempty"`
+	Tags        []string           `json:"tags,omitempty"`
+	PublishedAt *time.Time         `json:"publishedAt,omitempty"`
+}
+
+// ArticleSuggestion is an article that may solve the issue being reported.
+type ArticleSuggestion struct {
+	ID       primitive.ObjectID `json:"id"`
+	Title    string             `json:"title"`
+	Category TicketCategory     `json:"category,omitempty"`
+	// Excerpt is the part of the article that matched.
+	Excerpt string  `json:"excerpt"`
+	Score   float32 `json:"score"`
+}
+
+type SuggestArticlesRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
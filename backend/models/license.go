@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// License is a software license in the inventory. Seats are consumed by
+// assigned users and assets (endpoint agents).
+type License struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Product string             `json:"product" bson:"product"`
+	Vendor  string             `json:"vendor,omitempty" bson:"vendor,omitempty"`
+	// Reference is the contract or order number, never the license key.
+	Reference      string               `json:"reference,omitempty" bson:"reference,omitempty"`
+	Seats          int                  `json:"seats" bson:"seats"`
+	ExpiresAt      *time.Time           `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	AssignedUsers  []primitive.ObjectID `json:"assignedUsers" bson:"assignedUsers"`
+	AssignedAssets []primitive.ObjectID `json:"assignedAssets" bson:"assignedAssets"`
+	// OwnerID is who renewal tickets are assigned to.
+	OwnerID *primitive.ObjectID `json:"ownerId,omitempty" bson:"ownerId,omitempty"`
+	Notes   string              `json:"notes,omitempty" bson:"notes,omitempty"`
+	// RenewalTicketID is the ticket opened for the upcoming renewal, and
+	// RenewalFor the expiry date it was opened for, so each expiry gets one
+	// ticket.
+	RenewalTicketID *primitive.ObjectID `json:"renewalTicketId,omitempty" bson:"renewalTicketId,omitempty"`
+	RenewalFor      *time.Time          `json:"renewalFor,omitempty" bson:"renewalFor,omitempty"`
+	CreatedBy       primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	CreatedAt       time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt       time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+type LicenseRequest struct {
+	Product        string     `json:"product" binding:"required"`
+	Vendor         string     `json:"vendor"`
+	Reference      string     `json:"reference"`
+	Seats          int        `json:"seats" binding:"min=0"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+	AssignedUsers  []string   `json:"assignedUsers"`
+	AssignedAssets []string   `json:"assignedAssets"`
+	OwnerID        string     `json:"ownerId"`
+	Notes          string     `json:"notes"`
+}
+
+// LicenseView is a license with its seat usage.
+type LicenseView struct {
+	License
+	SeatsUsed int  `json:"seatsUsed"`
+	Expired   bool `json:"expired"`
+}
+
+type LinkLicenseRequest struct {
+	// LicenseID is empty to unlink the ticket.
+	LicenseID string `json:"licenseId"`
+}
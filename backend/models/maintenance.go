@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaintenanceWindow silences monitoring for its resources while it is
+// active: anomalies are still recorded but open no tickets and notify no
+// team. Resources are selected by ID, by tags, or both.
+type MaintenanceWindow struct {
+	ID          primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Name        string               `json:"name" bson:"name"`
+	Description string               `json:"description,omitempty" bson:"description,omitempty"`
+	StartsAt    time.Time            `json:"startsAt" bson:"startsAt"`
+	EndsAt      time.Time            `json:"endsAt" bson:"endsAt"`
+	ResourceIDs []primitive.ObjectID `json:"resourceIds,omitempty" bson:"resourceIds,omitempty"`
+	// Tags select resources, and ingested alerts by their labels; "*"
+	// matches any value.
+	Tags      map[string]string  `json:"tags,omitempty" bson:"tags,omitempty"`
+	CreatedBy primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+type MaintenanceWindowRequest struct {
+	Name        string               `json:"name" binding:"required"`
+	Description string               `json:"description"`
+	StartsAt    time.Time            `json:"startsAt" binding:"required"`
+	EndsAt      time.Time            `json:"endsAt" binding:"required"`
+	ResourceIDs []primitive.ObjectID `json:"resourceIds"`
+	Tags        map[string]string    `json:"tags"`
+}
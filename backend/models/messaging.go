@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MessagingChannel string
+
+const (
+	MessagingSMS      MessagingChannel = "sms"
+	MessagingWhatsApp MessagingChannel = "whatsapp"
+)
+
+// MessagingSubscription is the phone a user registered for SMS and
+// WhatsApp. The user opts in to a channel by texting START from the phone,
+// which shows the number is theirs, and out with STOP.
+type MessagingSubscription struct {
+	UserID primitive.ObjectID `json:"userId" bson:"_id"`
+	// Phone is in E.164 format, e.g. +14155550123.
+	Phone     string             `json:"phone" bson:"phone"`
+	OptedIn   []MessagingChannel `json:"optedIn" bson:"optedIn"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+type MessagingSubscriptionRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+// MessagingSubscriptionView is a user's subscription with the numbers to
+// text START to.
+type MessagingSubscriptionView struct {
+	*MessagingSubscription
+	Numbers map[MessagingChannel]string `json:"numbers"`
+}
+
+// TicketMessage is an SMS or WhatsApp message sent or received. Messages
+// that created or updated a ticket, and status updates sent about it, are
+// logged with the ticket.
+type TicketMessage struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	TicketID  *primitive.ObjectID `json:"ticketId,omitempty" bson:"ticketId,omitempty"`
+	UserID    *primitive.ObjectID `json:"userId,omitempty" bson:"userId,omitempty"`
+	Channel   MessagingChannel    `json:"channel" bson:"channel"`
+	Direction string              `json:"direction" bson:"direction"`
+	Phone     string              `json:"phone" bson:"phone"`
+	Body      string              `json:"body" bson:"body"`
+	// ProviderID is Twilio's message SID.
+	ProviderID string    `json:"providerId,omitempty" bson:"providerId,omitempty"`
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedAt  time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+const (
+	MessageInbound  = "inbound"
+	MessageOutbound = "outbound"
+)
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MetricTemplate gives every monitored resource carrying its tags a default
+// set of metrics, e.g. all resources tagged env=prod get the same five
+// metrics. A resource's own metric with the same name and statistic takes
+// precedence over the template's.
+type MetricTemplate struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	// Tags select the resources; "*" matches any value.
+	Tags      map[string]string  `json:"tags" bson:"tags"`
+	Metrics   []ExportedMetric   `json:"metrics" bson:"metrics"`
+	Enabled   bool               `json:"enabled" bson:"enabled"`
+	CreatedBy primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+type MetricTemplateRequest struct {
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	Tags        map[string]string `json:"tags"`
+	Metrics     []ExportedMetric  `json:"metrics"`
+	Enabled     *bool             `json:"enabled"`
+}
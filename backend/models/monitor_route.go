@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MonitorRoute sends the anomalies of matching resources to a team's own
+// channels, so that e.g. database anomalies page the DBA channel while load
+// balancer anomalies go to the platform team. Routes are tried in Order and
+// the first match wins unless it sets Continue.
+type MonitorRoute struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name    string             `json:"name" bson:"name"`
+	Order   int                `json:"order" bson:"order"`
+	Enabled bool               `json:"enabled" bson:"enabled"`
+	Match   MonitorRouteMatch  `json:"match" bson:"match"`
+	Team    string             `json:"team" bson:"team"`
+	// AssignTo is the technician the anomaly's ticket is assigned to.
+	AssignTo    *primitive.ObjectID `json:"assignTo,omitempty" bson:"assignTo,omitempty"`
+	Emails      []string            `json:"emails,omitempty" bson:"emails,omitempty"`
+	WebhookURLs []string            `json:"webhookUrls,omitempty" bson:"webhookUrls,omitempty"`
+	Continue    bool                `json:"continue" bson:"continue"`
+	CreatedBy   primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// MonitorRouteMatch selects anomalies by their resource. Empty fields match
+// anything; all set fields must match.
+type MonitorRouteMatch struct {
+	// Namespaces are AWS namespaces such as AWS/RDS.
+	Namespaces []string                `json:"namespaces,omitempty" bson:"namespaces,omitempty"`
+	Types      []MonitoredResourceType `json:"types,omitempty" bson:"types,omitempty"`
+	// Tags must all be present on the resource, or among the labels of an
+	// ingested alert; the value "*" matches any value.
+	Tags        map[string]string `json:"tags,omitempty" bson:"tags,omitempty"`
+	MinSeverity string            `json:"minSeverity,omitempty" bson:"minSeverity,omitempty"`
+}
+
+type MonitorRouteRequest struct {
+	Name        string              `json:"name" binding:"required"`
+	Order       int                 `json:"order"`
+	Enabled     *bool               `json:"enabled"`
+	Match       MonitorRouteMatch   `json:"match"`
+	Team        string              `json:"team" binding:"required"`
+	AssignTo    *primitive.ObjectID `json:"assignTo"`
+	Emails      []string            `json:"emails"`
+	WebhookURLs []string            `json:"webhookUrls"`
+	Continue    bool                `json:"continue"`
+}
@@ -19,6 +19,8 @@ type MonitoredResource struct {
     Identifier  string                 `bson:"identifier" json:"identifier"` // e.g., i-123, alb/xyz, service name
     Namespace   string                 `bson:"namespace" json:"namespace"`   // AWS namespace, e.g., AWS/EC2
     Dimensions  map[string]string      `bson:"dimensions" json:"dimensions"`
+    // Tags such as team=dba or env=prod are matched by notification routes.
+    Tags        map[string]string      `bson:"tags,omitempty" json:"tags,omitempty"`
     Enabled     bool                   `bson:"enabled" json:"enabled"`
     CreatedAt   time.Time              `bson:"createdAt" json:"createdAt"`
     UpdatedAt   time.Time              `bson:"updatedAt" json:"updatedAt"`
@@ -31,6 +33,14 @@ const (
     DirectionBelow MetricConfigDirection = "below"
 )
 
+// SeverityThresholds are the absolute z-scores at which an anomaly becomes
+// medium, high or critical; smaller deviations are low.
+type SeverityThresholds struct {
+    Medium   float64 `bson:"medium" json:"medium" yaml:"medium"`
+    High     float64 `bson:"high" json:"high" yaml:"high"`
+    Critical float64 `bson:"critical" json:"critical" yaml:"critical"`
+}
+
 type MetricConfig struct {
     ID             primitive.ObjectID      `bson:"_id,omitempty" json:"id"`
     ResourceID     primitive.ObjectID      `bson:"resourceId" json:"resourceId"`
@@ -41,8 +51,17 @@ type MetricConfig struct {
     ZScore         float64                 `bson:"zScore" json:"zScore"`
     MinConsecutive int                     `bson:"minConsecutive" json:"minConsecutive"`
     Direction      MetricConfigDirection   `bson:"direction" json:"direction"`
+    // PriorityMap maps anomaly severities to ticket priorities; severities
+    // left out use the configured defaults, as do nil SeverityThresholds.
     PriorityMap    map[string]TicketPriority `bson:"priorityMap" json:"priorityMap"`
+    SeverityThresholds *SeverityThresholds `bson:"severityThresholds,omitempty" json:"severityThresholds,omitempty"`
+    // RecoveryPoints is how many consecutive points back within baseline
+    // close an open anomaly; 0 uses the configured default.
+    RecoveryPoints int                     `bson:"recoveryPoints,omitempty" json:"recoveryPoints,omitempty"`
     Enabled        bool                    `bson:"enabled" json:"enabled"`
+    // TemplateID is set on metrics a resource inherits from a tag-based
+    // metric template; those are not stored in mon_metrics.
+    TemplateID     *primitive.ObjectID     `bson:"-" json:"templateId,omitempty"`
     CreatedAt      time.Time               `bson:"createdAt" json:"createdAt"`
     UpdatedAt      time.Time               `bson:"updatedAt" json:"updatedAt"`
 }
@@ -67,7 +86,77 @@ type AnomalyRecord struct {
     DedupKey      string             `bson:"dedupKey" json:"dedupKey"`
     TicketID      *primitive.ObjectID `bson:"ticketId,omitempty" json:"ticketId,omitempty"`
     Status        AnomalyStatus      `bson:"status" json:"status"`
+    // Source is empty for CloudWatch detections and "alertmanager" for
+    // ingested alerts, which also keep their name and labels.
+    Source        string             `bson:"source,omitempty" json:"source,omitempty"`
+    AlertName     string             `bson:"alertName,omitempty" json:"alertName,omitempty"`
+    Labels        map[string]string  `bson:"labels,omitempty" json:"labels,omitempty"`
+    // Teams are the teams of the notification routes the anomaly matched.
+    Teams         []string           `bson:"teams,omitempty" json:"teams,omitempty"`
+    // MaintenanceWindowID is set when the anomaly happened during a
+    // maintenance window, so no ticket was opened and no team notified.
+    MaintenanceWindowID *primitive.ObjectID `bson:"maintenanceWindowId,omitempty" json:"maintenanceWindowId,omitempty"`
     CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
+    ClosedAt      *time.Time         `bson:"closedAt,omitempty" json:"closedAt,omitempty"`
+    // RecoveredAt and Resolution are set when the metric recovered on its
+    // own and the anomaly was closed automatically.
+    RecoveredAt   *time.Time         `bson:"recoveredAt,omitempty" json:"recoveredAt,omitempty"`
+    Resolution    string             `bson:"resolution,omitempty" json:"resolution,omitempty"`
+}
+
+
+
+// MonitoringContext lists the monitored resources a ticket mentions, their
+// recent anomalies and the deployments made shortly before the ticket.
+type MonitoringContext struct {
+    TicketID    primitive.ObjectID  `json:"ticketId"`
+    Resources   []MonitoredResource `json:"resources"`
+    Anomalies   []AnomalyRecord     `json:"anomalies"`
+    Deployments []RecentDeployments `json:"deployments"`
 }
 
+// MonitoringConfigExport is the portable form of the monitoring
+// configuration. Database IDs are left out so it can be version-controlled
+// and imported into another environment; resources are matched by type and
+// identifier, metrics by name and statistic.
+type MonitoringConfigExport struct {
+    Version    int                `json:"version" yaml:"version"`
+    ExportedAt time.Time          `json:"exportedAt" yaml:"exportedAt"`
+    Resources  []ExportedResource `json:"resources" yaml:"resources"`
+}
+
+type ExportedResource struct {
+    Type       MonitoredResourceType `json:"type" yaml:"type"`
+    Identifier string                `json:"identifier" yaml:"identifier"`
+    Namespace  string                `json:"namespace" yaml:"namespace"`
+    Dimensions map[string]string     `json:"dimensions,omitempty" yaml:"dimensions,omitempty"`
+    Tags       map[string]string     `json:"tags,omitempty" yaml:"tags,omitempty"`
+    Enabled    bool                  `json:"enabled" yaml:"enabled"`
+    Metrics    []ExportedMetric      `json:"metrics" yaml:"metrics"`
+}
 
+type ExportedMetric struct {
+    MetricName     string                    `json:"metricName" yaml:"metricName"`
+    Statistic      string                    `json:"statistic" yaml:"statistic"`
+    PeriodSeconds  int                       `json:"periodSeconds" yaml:"periodSeconds"`
+    WindowSize     int                       `json:"windowSize" yaml:"windowSize"`
+    ZScore         float64                   `json:"zScore" yaml:"zScore"`
+    MinConsecutive int                       `json:"minConsecutive" yaml:"minConsecutive"`
+    Direction      MetricConfigDirection     `json:"direction" yaml:"direction"`
+    PriorityMap    map[string]TicketPriority `json:"priorityMap,omitempty" yaml:"priorityMap,omitempty"`
+    SeverityThresholds *SeverityThresholds   `json:"severityThresholds,omitempty" yaml:"severityThresholds,omitempty"`
+    RecoveryPoints int                       `json:"recoveryPoints,omitempty" yaml:"recoveryPoints,omitempty"`
+    Enabled        bool                      `json:"enabled" yaml:"enabled"`
+}
+
+// MonitoringImportResult counts the changes an import made (or would make
+// when run as a dry run).
+type MonitoringImportResult struct {
+    DryRun           bool `json:"dryRun"`
+    ResourcesCreated int  `json:"resourcesCreated"`
+    ResourcesUpdated int  `json:"resourcesUpdated"`
+    ResourcesDeleted int  `json:"resourcesDeleted"`
+    MetricsCreated   int  `json:"metricsCreated"`
+    MetricsUpdated   int  `json:"metricsUpdated"`
+    MetricsDeleted   int  `json:"metricsDeleted"`
+}
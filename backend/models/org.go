@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrgUnit is a department in the organisation hierarchy. Users and tickets
+// name their department, and reports and routes on a unit include its
+// sub-units.
+type OrgUnit struct {
+	ID       primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Name     string              `json:"name" bson:"name"`
+	ParentID *primitive.ObjectID `json:"parentId,omitempty" bson:"parentId,omitempty"`
+	// ManagerID is the user who heads the unit.
+	ManagerID *primitive.ObjectID `json:"managerId,omitempty" bson:"managerId,omitempty"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+type OrgUnitRequest struct {
+	Name      string              `json:"name" binding:"required"`
+	ParentID  *primitive.ObjectID `json:"parentId"`
+	ManagerID *primitive.ObjectID `json:"managerId"`
+}
+
+// OrgUnitNode is a unit with its sub-units, for showing the hierarchy.
+type OrgUnitNode struct {
+	OrgUnit
+	Children []OrgUnitNode `json:"children"`
+}
+
+// TicketRoute sends new tickets from matching departments to a support
+// team, e.g. finance tickets to finance-support. Routes are tried in Order
+// and the first match wins.
+type TicketRoute struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name    string             `json:"name" bson:"name"`
+	Order   int                `json:"order" bson:"order"`
+	Enabled bool               `json:"enabled" bson:"enabled"`
+	// Departments match tickets from these units or their sub-units.
+	Departments []string `json:"departments" bson:"departments"`
+	// Categories narrow the route to some ticket categories; empty matches
+	// any.
+	Categories []TicketCategory `json:"categories,omitempty" bson:"categories,omitempty"`
+	Team       string           `json:"team" bson:"team"`
+	// AssignTo is the technician matching tickets are assigned to.
+	AssignTo  *primitive.ObjectID `json:"assignTo,omitempty" bson:"assignTo,omitempty"`
+	CreatedBy primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+type TicketRouteRequest struct {
+	Name        string              `json:"name" binding:"required"`
+	Order       int                 `json:"order"`
+	Enabled     *bool               `json:"enabled"`
+	Departments []string            `json:"departments" binding:"required"`
+	Categories  []TicketCategory    `json:"categories"`
+	Team        string              `json:"team" binding:"required"`
+	AssignTo    *primitive.ObjectID `json:"assignTo"`
+}
+
+// DepartmentRollup counts a department's tickets, on its own and together
+// with its sub-units.
+type DepartmentRollup struct {
+	Department string `json:"department"`
+	Parent     string `json:"parent,omitempty"`
+	Depth      int    `json:"depth"`
+	Direct     int64  `json:"direct"`
+	Total      int64  `json:"total"`
+	Open       int64  `json:"open"`
+	Critical   int64  `json:"critical"`
+}
+
+// DepartmentReport rolls ticket volume up the org hierarchy. Departments
+// that are not org units are listed at the top level.
+type DepartmentReport struct {
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Total       int64              `json:"total"`
+	Departments []DepartmentRollup `json:"departments"`
+}
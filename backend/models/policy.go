@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PolicyEffect string
+
+const (
+	PolicyAllow PolicyEffect = "allow"
+	PolicyDeny  PolicyEffect = "deny"
+)
+
+// PolicyRule allows or denies a subject an action on a resource. Requests
+// are denied unless an enabled rule allows them, and a matching deny rule
+// wins over any allow.
+type PolicyRule struct {
+	ID          string `json:"id" bson:"_id"`
+	Description string `json:"description" bson:"description"`
+	// Subject is role:<role>, user:<user ID>, department:<name> (the
+	// user's department, any case), or * for every signed-in user.
+	Subject string `json:"subject" bson:"subject"`
+	// Resource is a route path as registered, such as /api/tickets/:id,
+	// where * matches any characters: /api/admin/* covers the admin API.
+	Resource string `json:"resource" bson:"resource"`
+	// Action is an HTTP method, or * for any.
+	Action   string       `json:"action" bson:"action"`
+	Effect   PolicyEffect `json:"effect" bson:"effect"`
+	Disabled bool         `json:"disabled" bson:"disabled"`
+	// Builtin is set on rules the code defines and that have not been
+	// changed yet.
+	Builtin   bool                `json:"builtin" bson:"-"`
+	UpdatedAt time.Time           `json:"updatedAt,omitempty" bson:"updatedAt"`
+	UpdatedBy *primitive.ObjectID `json:"updatedBy,omitempty" bson:"updatedBy,omitempty"`
+}
+
+type PolicyRuleRequest struct {
+	Description string       `json:"description"`
+	Subject     string       `json:"subject" binding:"required"`
+	Resource    string       `json:"resource" binding:"required"`
+	Action      string       `json:"action" binding:"required"`
+	Effect      PolicyEffect `json:"effect" binding:"required"`
+	Disabled    bool         `json:"disabled"`
+}
+
+// PolicyDecision is the outcome of checking a request against the rules.
+type PolicyDecision struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+	// Rule is the rule that decided, nil when no rule matched.
+	Rule *PolicyRule `json:"rule,omitempty"`
+	// Matched lists every enabled rule matching the request.
+	Matched []PolicyRule `json:"matched"`
+}
+
+// PolicySimulationRequest asks how the rules treat a request by a user, or
+// by any user with a role.
+type PolicySimulationRequest struct {
+	UserID string   `json:"userId"`
+	Role   UserRole `json:"role"`
+	Method string   `json:"method" binding:"required"`
+	// Path is a request path such as /api/tickets/65f0..., or a route path.
+	Path string `json:"path" binding:"required"`
+}
+
+type PolicySimulation struct {
+	// Route is the registered route the path resolves to, which rules
+	// match against.
+	Route    string         `json:"route"`
+	Subjects []string       `json:"subjects"`
+	Decision PolicyDecision `json:"decision"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PortalTicketRequest struct {
+	Title       string         `json:"title" binding:"required"`
+	Description string         `json:"description" binding:"required"`
+	Category    TicketCategory `json:"category,omitempty"`
+	// PreflightID is the preflight run for the draft, if any.
+	PreflightID string `json:"preflightId,omitempty"`
+}
+
+type PortalCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+type RateTicketRequest struct {
+	Score   int    `json:"score" binding:"required,min=1,max=5"`
+	Comment string `json:"comment"`
+}
+
+// PortalTicket is what a requester sees of their own ticket: no assignee,
+// diagnostics, linked resources or internal comments.
+type PortalTicket struct {
+	ID          primitive.ObjectID `json:"id"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Category    TicketCategory     `json:"category"`
+	Priority    TicketPriority     `json:"priority"`
+	Status      TicketStatus       `json:"status"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+	ResolvedAt  *time.Time         `json:"resolvedAt,omitempty"`
+	Rating      *ResolutionRating  `json:"rating,omitempty"`
+	Comments    []TicketComment    `json:"comments,omitempty"`
+	Type        TicketType         `json:"type,omitempty"`
+	// ApprovalStatus is set on service requests.
+	ApprovalStatus ApprovalStatus `json:"approvalStatus,omitempty"`
+	// DueAt, DueFromSLA and Overdue are as on Ticket.
+	DueAt      *time.Time `json:"dueAt,omitempty"`
+	DueFromSLA bool       `json:"dueFromSla,omitempty"`
+	Overdue    bool       `json:"overdue,omitempty"`
+	// SuggestedArticles are returned when the ticket is created.
+	SuggestedArticles []ArticleSuggestion `json:"suggestedArticles,omitempty"`
+}
+
+func NewPortalTicket(t Ticket) PortalTicket {
+	p := PortalTicket{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		Category:    t.Category,
+		Priority:    t.Priority,
+		Status:      t.Status,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+		ResolvedAt:  t.ResolvedAt,
+		Rating:      t.Rating,
+		Type:        t.Type,
+		DueAt:       t.DueAt,
+		DueFromSLA:  t.DueFromSLA,
+		Overdue:     t.Overdue,
+	}
+	if t.ServiceRequest != nil {
+		p.ApprovalStatus = t.ServiceRequest.ApprovalStatus
+	}
+	return p
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PostmortemStatus string
+
+const (
+	PostmortemDraft     PostmortemStatus = "draft"
+	PostmortemInReview  PostmortemStatus = "in_review"
+	PostmortemPublished PostmortemStatus = "published"
+)
+
+// PostmortemTemplate lists the sections a postmortem is drafted with. The
+// template marked IsDefault is used when none is chosen.
+type PostmortemTemplate struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Name        string              `json:"name" bson:"name"`
+	Description string              `json:"description,omitempty" bson:"description,omitempty"`
+	Sections    []PostmortemSection `json:"sections" bson:"sections"`
+	IsDefault   bool                `json:"isDefault" bson:"isDefault"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// PostmortemSection is a template section (with Guidance for the drafter)
+// or a drafted section of a postmortem (with Content).
+type PostmortemSection struct {
+	Key      string `json:"key" bson:"key" binding:"required"`
+	Title    string `json:"title" bson:"title" binding:"required"`
+	Guidance string `json:"guidance,omitempty" bson:"guidance,omitempty"`
+	Content  string `json:"content,omitempty" bson:"content,omitempty"`
+}
+
+// Postmortem is the blameless review of a resolved incident. Action items
+// are tracked as tickets so they show up in the normal queue.
+type Postmortem struct {
+	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	IncidentID   primitive.ObjectID  `json:"incidentId" bson:"incidentId"`
+	TemplateID   *primitive.ObjectID `json:"templateId,omitempty" bson:"templateId,omitempty"`
+	TemplateName string              `json:"templateName" bson:"templateName"`
+	Title        string              `json:"title" bson:"title"`
+	Status       PostmortemStatus    `json:"status" bson:"status"`
+	Sections     []PostmortemSection `json:"sections" bson:"sections"`
+	// SuggestedActions are follow-ups proposed by the draft; they become
+	// ActionItems once someone takes ownership.
+	SuggestedActions []string           `json:"suggestedActions,omitempty" bson:"suggestedActions,omitempty"`
+	ActionItems      []PostmortemAction `json:"actionItems" bson:"actionItems"`
+	Fallback         bool               `json:"fallback" bson:"fallback"`
+	GeneratedAt      time.Time          `json:"generatedAt" bson:"generatedAt"`
+	CreatedAt        time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt        time.Time          `json:"updatedAt" bson:"updatedAt"`
+	PublishedAt      *time.Time         `json:"publishedAt,omitempty" bson:"publishedAt,omitempty"`
+}
+
+// PostmortemAction is a follow-up tracked by its own ticket. Status mirrors
+// the ticket's status when the postmortem is read.
+type PostmortemAction struct {
+	ID             primitive.ObjectID `json:"id" bson:"id"`
+	Title          string             `json:"title" bson:"title"`
+	TicketID       primitive.ObjectID `json:"ticketId" bson:"ticketId"`
+	OwnerID        primitive.ObjectID `json:"ownerId" bson:"ownerId"`
+	DueAt          time.Time          `json:"dueAt" bson:"dueAt"`
+	Status         TicketStatus       `json:"status" bson:"status"`
+	LastReminderAt *time.Time         `json:"lastReminderAt,omitempty" bson:"lastReminderAt,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+type PostmortemTemplateRequest struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	Sections    []PostmortemSection `json:"sections" binding:"required,min=1,dive"`
+	IsDefault   bool                `json:"isDefault"`
+}
+
+type DraftPostmortemRequest struct {
+	TemplateID string `json:"templateId"`
+}
+
+type UpdatePostmortemRequest struct {
+	Title    string              `json:"title"`
+	Status   PostmortemStatus    `json:"status"`
+	Sections []PostmortemSection `json:"sections"`
+}
+
+type CreateActionItemRequest struct {
+	Title       string         `json:"title" binding:"required"`
+	Description string         `json:"description"`
+	OwnerID     string         `json:"ownerId" binding:"required"`
+	DueAt       time.Time      `json:"dueAt" binding:"required"`
+	Priority    TicketPriority `json:"priority"`
+}
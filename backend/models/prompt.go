@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PromptTemplate is a versioned prompt stored by admins. Content is a Go
+// text/template; the triage template receives .Title, .Description and
+// .Taxonomy.
+type PromptTemplate struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"` // e.g. "triage"
+	Version     string             `json:"version" bson:"version"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	Content     string             `json:"content" bson:"content"`
+	BuiltIn     bool               `json:"builtIn" bson:"-"`
+	CreatedBy   primitive.ObjectID `json:"createdBy,omitempty" bson:"createdBy,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+type CreatePromptTemplateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Version     string `json:"version" binding:"required"`
+	Description string `json:"description"`
+	Content     string `json:"content" binding:"required"`
+}
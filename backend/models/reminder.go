@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TicketReminder is a follow-up a technician set on a ticket for
+// themselves. It is delivered once at DueAt and stays overdue on their
+// agenda until completed.
+type TicketReminder struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TicketID    primitive.ObjectID `json:"ticketId" bson:"ticketId"`
+	UserID      primitive.ObjectID `json:"userId" bson:"userId"`
+	Note        string             `json:"note" bson:"note"`
+	DueAt       time.Time          `json:"dueAt" bson:"dueAt"`
+	FiredAt     *time.Time         `json:"firedAt,omitempty" bson:"firedAt,omitempty"`
+	CompletedAt *time.Time         `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+type CreateReminderRequest struct {
+	Note  string    `json:"note" binding:"required"`
+	DueAt time.Time `json:"dueAt" binding:"required"`
+}
+
+// UpdateReminderRequest changes the fields that are set. Moving DueAt
+// delivers the reminder again at the new time.
+type UpdateReminderRequest struct {
+	Note      *string    `json:"note"`
+	DueAt     *time.Time `json:"dueAt"`
+	Completed *bool      `json:"completed"`
+}
+
+// AgendaItem is a reminder with the ticket it is about.
+type AgendaItem struct {
+	TicketReminder
+	TicketTitle    string         `json:"ticketTitle"`
+	TicketStatus   TicketStatus   `json:"ticketStatus"`
+	TicketPriority TicketPriority `json:"ticketPriority"`
+}
+
+// Agenda lists a user's open follow-ups.
+type Agenda struct {
+	Overdue  []AgendaItem `json:"overdue"`
+	Upcoming []AgendaItem `json:"upcoming"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReplyTone string
+
+const (
+	ToneFormal   ReplyTone = "formal"
+	ToneFriendly ReplyTone = "friendly"
+)
+
+type ReplyDraftStatus string
+
+const (
+	ReplyDraftPending   ReplyDraftStatus = "draft"
+	ReplyDraftAccepted  ReplyDraftStatus = "accepted"
+	ReplyDraftDiscarded ReplyDraftStatus = "discarded"
+)
+
+// ReplyDraft is an AI-drafted reply to the requester. What the technician
+// finally sent is kept alongside it to track draft quality.
+type ReplyDraft struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TicketID      primitive.ObjectID `json:"ticketId" bson:"ticketId"`
+	Tone          ReplyTone          `json:"tone" bson:"tone"`
+	SolutionTitle string             `json:"solutionTitle,omitempty" bson:"solutionTitle,omitempty"`
+	Draft         string             `json:"draft" bson:"draft"`
+	Fallback      bool               `json:"fallback" bson:"fallback"`
+	Status        ReplyDraftStatus   `json:"status" bson:"status"`
+	FinalText     string             `json:"finalText,omitempty" bson:"finalText,omitempty"`
+	// EditRatio is the share of words changed between draft and final text
+	// (0 means sent unchanged).
+	EditRatio  float64             `json:"editRatio" bson:"editRatio"`
+	CreatedBy  primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	CreatedAt  time.Time           `json:"createdAt" bson:"createdAt"`
+	ResolvedBy *primitive.ObjectID `json:"resolvedBy,omitempty" bson:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time          `json:"resolvedAt,omitempty" bson:"resolvedAt,omitempty"`
+}
+
+type DraftReplyRequest struct {
+	Tone ReplyTone `json:"tone"`
+	// Solution is the suggested solution the technician picked, if any.
+	Solution *SuggestedSolution `json:"solution"`
+	// Instructions are extra notes from the technician, e.g. "ask for the
+	// laptop serial number".
+	Instructions string `json:"instructions"`
+}
+
+type AcceptReplyRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+type ReplyDraftStats struct {
+	Tone           ReplyTone `json:"tone"`
+	Drafts         int64     `json:"drafts"`
+	Accepted       int64     `json:"accepted"`
+	Discarded      int64     `json:"discarded"`
+	AcceptanceRate float64   `json:"acceptanceRate"`
+	UnchangedRate  float64   `json:"unchangedRate"`
+	AvgEditRatio   float64   `json:"avgEditRatio"`
+}
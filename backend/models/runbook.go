@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RunbookType string
+
+const (
+	RunbookSSM     RunbookType = "ssm"
+	RunbookAnsible RunbookType = "ansible"
+	RunbookHTTP    RunbookType = "http"
+)
+
+// Runbook is an admin-registered, parameterized automation that suggested
+// solution steps may reference. The LLM only picks a runbook and fills in
+// its declared parameters; it never supplies the script itself.
+//
+// Templates ({{name}}) in Targets, Playbook, URL, Headers and Body are
+// replaced with parameter values.
+type Runbook struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name" binding:"required"`
+	Description string             `json:"description" bson:"description"`
+	Type        RunbookType        `json:"type" bson:"type" binding:"required"`
+	// SSM: document name (e.g. AWS-RunShellScript) and comma-separated
+	// instance IDs. Parameters are passed to the document.
+	Document string `json:"document,omitempty" bson:"document,omitempty"`
+	Targets  string `json:"targets,omitempty" bson:"targets,omitempty"`
+	// Ansible: playbook path relative to ANSIBLE_PLAYBOOK_DIR. Parameters
+	// are passed as extra vars.
+	Playbook string `json:"playbook,omitempty" bson:"playbook,omitempty"`
+	// HTTP: request sent to an internal API.
+	Method     string             `json:"method,omitempty" bson:"method,omitempty"`
+	URL        string             `json:"url,omitempty" bson:"url,omitempty"`
+	Headers    map[string]string  `json:"headers,omitempty" bson:"headers,omitempty"`
+	Body       string             `json:"body,omitempty" bson:"body,omitempty"`
+	Parameters []RunbookParameter `json:"parameters" bson:"parameters"`
+	CreatedBy  primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt  time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+type RunbookParameter struct {
+	Name        string `json:"name" bson:"name"`
+	Description string `json:"description" bson:"description"`
+	Required    bool   `json:"required" bson:"required"`
+	// Pattern is a regular expression values must match; empty allows
+	// identifier-like values only.
+	Pattern string `json:"pattern,omitempty" bson:"pattern,omitempty"`
+}
+
+// StepAutomation marks a solution step as executable by a runbook.
+type StepAutomation struct {
+	StepIndex  int               `json:"stepIndex" bson:"stepIndex"`
+	RunbookID  string            `json:"runbookId" bson:"runbookId"`
+	Parameters map[string]string `json:"parameters,omitempty" bson:"parameters,omitempty"`
+}
+
+type AutomationStatus string
+
+const (
+	AutomationPendingApproval AutomationStatus = "pending_approval"
+	AutomationRejected        AutomationStatus = "rejected"
+	AutomationRunning         AutomationStatus = "running"
+	AutomationSucceeded       AutomationStatus = "succeeded"
+	AutomationFailed          AutomationStatus = "failed"
+)
+
+// AutomationRun is a request to execute a runbook for a ticket. It only runs
+// once an admin other than the requester approves it.
+type AutomationRun struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	TicketID    primitive.ObjectID  `json:"ticketId" bson:"ticketId"`
+	RunbookID   primitive.ObjectID  `json:"runbookId" bson:"runbookId"`
+	RunbookName string              `json:"runbookName" bson:"runbookName"`
+	Type        RunbookType         `json:"type" bson:"type"`
+	Solution    string              `json:"solution,omitempty" bson:"solution,omitempty"`
+	StepIndex   int                 `json:"stepIndex" bson:"stepIndex"`
+	Step        string              `json:"step,omitempty" bson:"step,omitempty"`
+	Parameters  map[string]string   `json:"parameters" bson:"parameters"`
+	Status      AutomationStatus    `json:"status" bson:"status"`
+	Output      string              `json:"output,omitempty" bson:"output,omitempty"`
+	Error       string              `json:"error,omitempty" bson:"error,omitempty"`
+	RequestedBy primitive.ObjectID  `json:"requestedBy" bson:"requestedBy"`
+	RequestedAt time.Time           `json:"requestedAt" bson:"requestedAt"`
+	ReviewedBy  *primitive.ObjectID `json:"reviewedBy,omitempty" bson:"reviewedBy,omitempty"`
+	ReviewedAt  *time.Time          `json:"reviewedAt,omitempty" bson:"reviewedAt,omitempty"`
+	ReviewNote  string              `json:"reviewNote,omitempty" bson:"reviewNote,omitempty"`
+	FinishedAt  *time.Time          `json:"finishedAt,omitempty" bson:"finishedAt,omitempty"`
+}
+
+// AutomationResult is the outcome of an automation run kept on the ticket.
+type AutomationResult struct {
+	RunID       primitive.ObjectID `json:"runId" bson:"runId"`
+	RunbookName string             `json:"runbookName" bson:"runbookName"`
+	Status      AutomationStatus   `json:"status" bson:"status"`
+	Output      string             `json:"output" bson:"output"`
+	FinishedAt  time.Time          `json:"finishedAt" bson:"finishedAt"`
+}
+
+type RequestAutomationRequest struct {
+	RunbookID  string            `json:"runbookId" binding:"required"`
+	Solution   string            `json:"solution"`
+	StepIndex  int               `json:"stepIndex"`
+	Step       string            `json:"step"`
+	Parameters map[string]string `json:"parameters"`
+}
+
+type ReviewAutomationRequest struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Where a document search came from
+const (
+	SearchSourceSearch    = "search"
+	SearchSourceSolutions = "solutions"
+)
+
+// SearchLog records one document search: what was asked, how long it
+// took, what it returned and which results were then used.
+type SearchLog struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Source string             `json:"source" bson:"source"`
+	Query  string             `json:"query" bson:"query"`
+	// QueryKey is the query lowercased with whitespace collapsed, so
+	// repeats of the same query group together.
+	QueryKey    string               `json:"queryKey" bson:"queryKey"`
+	UserID      primitive.ObjectID   `json:"userId" bson:"userId"`
+	TicketID    *primitive.ObjectID  `json:"ticketId,omitempty" bson:"ticketId,omitempty"`
+	ResultCount int                  `json:"resultCount" bson:"resultCount"`
+	TopScore    float32              `json:"topScore" bson:"topScore"`
+	LatencyMs   int64                `json:"latencyMs" bson:"latencyMs"`
+	DocumentIDs []primitive.ObjectID `json:"documentIds" bson:"documentIds"`
+	// ClickedDocumentIDs are results the user opened.
+	ClickedDocumentIDs []primitive.ObjectID `json:"clickedDocumentIds,omitempty" bson:"clickedDocumentIds,omitempty"`
+	// CitedDocumentIDs are results generated solutions referenced.
+	CitedDocumentIDs []primitive.ObjectID `json:"citedDocumentIds,omitempty" bson:"citedDocumentIds,omitempty"`
+	CreatedAt        time.Time            `json:"createdAt" bson:"createdAt"`
+}
+
+type SearchClickRequest struct {
+	DocumentID string `json:"documentId" binding:"required"`
+}
+
+// SearchQueryStat aggregates the searches for one query.
+type SearchQueryStat struct {
+	Query          string    `json:"query"`
+	Searches       int       `json:"searches"`
+	ZeroResults    int       `json:"zeroResults"`
+	AvgResults     float64   `json:"avgResults"`
+	AvgLatencyMs   float64   `json:"avgLatencyMs"`
+	Clicks         int       `json:"clicks"`
+	Citations      int       `json:"citations"`
+	LastSearchedAt time.Time `json:"lastSearchedAt"`
+}
+
+// SearchDocumentStat counts how often a document was returned and used.
+type SearchDocumentStat struct {
+	DocumentID primitive.ObjectID `json:"documentId"`
+	Title      string             `json:"title"`
+	Returned   int                `json:"returned"`
+	Clicks     int                `json:"clicks"`
+	Citations  int                `json:"citations"`
+}
+
+// SearchAnalytics summarizes document searches over a period.
+type SearchAnalytics struct {
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Searches     int       `json:"searches"`
+	ZeroResults  int       `json:"zeroResults"`
+	AvgLatencyMs float64   `json:"avgLatencyMs"`
+	// ZeroResultRate is zero-result searches over searches.
+	ZeroResultRate float64 `json:"zeroResultRate"`
+	// ClickThroughRate is searches with a click over searches from the
+	// search endpoint; CitationRate is searches with a cited result over
+	// searches for solutions.
+	ClickThroughRate  float64              `json:"clickThroughRate"`
+	CitationRate      float64              `json:"citationRate"`
+	TopQueries        []SearchQueryStat    `json:"topQueries"`
+	ZeroResultQueries []SearchQueryStat    `json:"zeroResultQueries"`
+	TopDocuments      []SearchDocumentStat `json:"topDocuments"`
+}
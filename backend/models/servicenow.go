@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ServiceNowLink ties a ticket to the ServiceNow incident it was pushed as.
+type ServiceNowLink struct {
+	SysID  string `json:"sysId" bson:"sysId"`
+	Number string `json:"number" bson:"number"`
+	// State is the incident state last seen in ServiceNow, such as "2"
+	// for In Progress. Pulls only apply states that changed since.
+	State    string             `json:"state" bson:"state"`
+	PushedAt time.Time          `json:"pushedAt" bson:"pushedAt"`
+	PushedBy primitive.ObjectID `json:"pushedBy" bson:"pushedBy"`
+	SyncedAt time.Time          `json:"syncedAt" bson:"syncedAt"`
+}
+
+// ServiceNowTicketsRequest selects tickets to export or push.
+type ServiceNowTicketsRequest struct {
+	TicketIDs []string `json:"ticketIds" binding:"required"`
+}
+
+type ServiceNowPushError struct {
+	TicketID primitive.ObjectID `json:"ticketId"`
+	Error    string             `json:"error"`
+}
+
+// ServiceNowPushResult counts the incidents a push created and updated.
+// Links maps each pushed ticket ID to its incident number.
+type ServiceNowPushResult struct {
+	Created int                   `json:"created"`
+	Updated int                   `json:"updated"`
+	Failed  int                   `json:"failed"`
+	Links   map[string]string     `json:"links"`
+	Errors  []ServiceNowPushError `json:"errors"`
+}
+
+// ServiceNowSyncResult counts the linked tickets a pull checked, the ones
+// whose status it changed and the ones whose incident no longer exists.
+type ServiceNowSyncResult struct {
+	Checked int `json:"checked"`
+	Updated int `json:"updated"`
+	Missing int `json:"missing"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SettingsID is the _id of the single settings document in the settings
+// collection.
+const SettingsID = "default"
+
+// Settings are the admin-managed settings that override their environment
+// defaults and apply without a restart. Secrets are never returned: the
+// *Set fields report whether one is configured, and an empty secret in an
+// update keeps the current one.
+type Settings struct {
+	ID            string               `json:"-" bson:"_id"`
+	AI            AISettings           `json:"ai" bson:"ai"`
+	Anomaly       AnomalySettings      `json:"anomaly" bson:"anomaly"`
+	Notifications NotificationSettings `json:"notifications" bson:"notifications"`
+	Organization  OrganizationSettings `json:"organization" bson:"organization"`
+	UpdatedAt     time.Time            `json:"updatedAt,omitempty" bson:"updatedAt"`
+	UpdatedBy     primitive.ObjectID   `json:"updatedBy,omitempty" bson:"updatedBy,omitempty"`
+}
+
+// AISettings configure the chat completions provider. Document embeddings
+// keep the provider configured at startup, which the index was built with.
+type AISettings struct {
+	// Provider is openai, local or mock.
+	Provider        string `json:"provider" bson:"provider"`
+	OpenAIModel     string `json:"openAIModel" bson:"openAIModel"`
+	OpenAIAPIKey    string `json:"openAIApiKey,omitempty" bson:"openAIApiKey"`
+	OpenAIAPIKeySet bool   `json:"openAIApiKeySet" bson:"-"`
+	LocalLLMURL     string `json:"localLlmUrl" bson:"localLlmUrl"`
+	// Tone is concise, detailed, friendly or formal; empty leaves the
+	// model's default.
+	Tone string `json:"tone" bson:"tone"`
+}
+
+// AnomalySettings are the defaults for metrics without their own policy.
+type AnomalySettings struct {
+	CreateTickets      bool                      `json:"createTickets" bson:"createTickets"`
+	DefaultZScore      float64                   `json:"defaultZScore" bson:"defaultZScore"`
+	SeverityThresholds SeverityThresholds        `json:"severityThresholds" bson:"severityThresholds"`
+	PriorityMap        map[string]TicketPriority `json:"priorityMap" bson:"priorityMap"`
+	RecoveryPoints     int                       `json:"recoveryPoints" bson:"recoveryPoints"`
+	ResolveTickets     bool                      `json:"resolveTickets" bson:"resolveTickets"`
+}
+
+type NotificationSettings struct {
+	WebhookURLs     []string `json:"webhookUrls" bson:"webhookUrls"`
+	SMTPHost        string   `json:"smtpHost" bson:"smtpHost"`
+	SMTPPort        int      `json:"smtpPort" bson:"smtpPort"`
+	SMTPUsername    string   `json:"smtpUsername" bson:"smtpUsername"`
+	SMTPPassword    string   `json:"smtpPassword,omitempty" bson:"smtpPassword"`
+	SMTPPasswordSet bool     `json:"smtpPasswordSet" bson:"-"`
+	SMTPFrom        string   `json:"smtpFrom" bson:"smtpFrom"`
+	// DigestRecipients receive the weekly operations digest.
+	DigestRecipients []string `json:"digestRecipients" bson:"digestRecipients"`
+}
+
+// OrganizationSettings describe the environment support works in. Triage
+// and solution prompts start with them so answers name the actual systems
+// and contacts instead of giving generic advice.
+type OrganizationSettings struct {
+	CompanyName        string   `json:"companyName" bson:"companyName"`
+	SupportedOS        []string `json:"supportedOs" bson:"supportedOs"`
+	StandardTools      []string `json:"standardTools" bson:"standardTools"`
+	EscalationContacts []string `json:"escalationContacts" bson:"escalationContacts"`
+}
+
+type UpdateSettingsRequest struct {
+	AI            AISettings           `json:"ai"`
+	Anomaly       AnomalySettings      `json:"anomaly"`
+	Notifications NotificationSettings `json:"notifications"`
+	Organization  OrganizationSettings `json:"organization"`
+}
+
+// SettingsChange is one setting changed by an update. Secrets are masked
+// and webhook URLs reduced to their hosts.
+type SettingsChange struct {
+	Setting string `json:"setting" bson:"setting"`
+	Old     string `json:"old" bson:"old"`
+	New     string `json:"new" bson:"new"`
+}
+
+// SettingsAuditEntry records who changed which settings.
+type SettingsAuditEntry struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ChangedBy     primitive.ObjectID `json:"changedBy" bson:"changedBy"`
+	ChangedByName string             `json:"changedByName" bson:"changedByName"`
+	Changes       []SettingsChange   `json:"changes" bson:"changes"`
+	CreatedAt     time.Time          `json:"createdAt" bson:"createdAt"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TicketShareLink grants read-only access to a sanitized view of a ticket
+// without an account, e.g. for an external vendor. The token itself is
+// signed and never stored.
+type TicketShareLink struct {
+	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	TicketID     primitive.ObjectID  `json:"ticketId" bson:"ticketId"`
+	Note         string              `json:"note,omitempty" bson:"note,omitempty"`
+	ExpiresAt    time.Time           `json:"expiresAt" bson:"expiresAt"`
+	CreatedBy    primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	CreatedAt    time.Time           `json:"createdAt" bson:"createdAt"`
+	RevokedAt    *time.Time          `json:"revokedAt,omitempty" bson:"revokedAt,omitempty"`
+	RevokedBy    *primitive.ObjectID `json:"revokedBy,omitempty" bson:"revokedBy,omitempty"`
+	ViewCount    int                 `json:"viewCount" bson:"viewCount"`
+	LastViewedAt *time.Time          `json:"lastViewedAt,omitempty" bson:"lastViewedAt,omitempty"`
+}
+
+type ShareAuditAction string
+
+const (
+	ShareCreated ShareAuditAction = "created"
+	ShareViewed  ShareAuditAction = "viewed"
+	ShareRevoked ShareAuditAction = "revoked"
+	// ShareDenied records an expired or revoked link being opened.
+	ShareDenied ShareAuditAction = "denied"
+)
+
+type ShareAuditEntry struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	LinkID    primitive.ObjectID  `json:"linkId" bson:"linkId"`
+	TicketID  primitive.ObjectID  `json:"ticketId" bson:"ticketId"`
+	Action    ShareAuditAction    `json:"action" bson:"action"`
+	UserID    *primitive.ObjectID `json:"userId,omitempty" bson:"userId,omitempty"`
+	IP        string              `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent string              `json:"userAgent,omitempty" bson:"userAgent,omitempty"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+}
+
+type CreateShareLinkRequest struct {
+	// ExpiresIn is a Go duration such as "72h"; the configured default
+	// applies when empty.
+	ExpiresIn string `json:"expiresIn"`
+	Note      string `json:"note"`
+}
+
+// SharedTicket is the public view of a ticket. It leaves out assignees,
+// diagnostics, internal summaries and other internal fields.
+type SharedTicket struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Category    TicketCategory `json:"category"`
+	Priority    TicketPriority `json:"priority"`
+	Status      TicketStatus   `json:"status"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	ResolvedAt  *time.Time     `json:"resolvedAt,omitempty"`
+	// Comments holds only the non-internal comments.
+	Comments  []SharedComment `json:"comments"`
+	ExpiresAt time.Time       `json:"expiresAt"`
+}
+
+type SharedComment struct {
+	AuthorName string    `json:"authorName"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SkillProfile is a technician's skill per category, inferred from how fast
+// and how reliably they resolve assigned tickets. Admin overrides are kept
+// across recomputation.
+type SkillProfile struct {
+	TechnicianID   primitive.ObjectID `json:"technicianId" bson:"_id"`
+	TechnicianName string             `json:"technicianName" bson:"technicianName"`
+	Skills         []CategorySkill    `json:"skills" bson:"skills"`
+	Overrides      []SkillOverride    `json:"overrides" bson:"overrides"`
+	ComputedAt     time.Time          `json:"computedAt" bson:"computedAt"`
+}
+
+type CategorySkill struct {
+	Category              TicketCategory `json:"category" bson:"category"`
+	Assigned              int64          `json:"assigned" bson:"assigned"`
+	Resolved              int64          `json:"resolved" bson:"resolved"`
+	SuccessRate           float64        `json:"successRate" bson:"successRate"`
+	MedianResolutionHours float64        `json:"medianResolutionHours" bson:"medianResolutionHours"`
+	// InferredScore is computed from history; Score is what assignment uses
+	// after overrides (0 to 1).
+	InferredScore float64 `json:"inferredScore" bson:"inferredScore"`
+	Score         float64 `json:"score" bson:"score"`
+	Overridden    bool    `json:"overridden" bson:"overridden"`
+}
+
+// SkillOverride pins a technician's score for a category or excludes them
+// from it entirely.
+type SkillOverride struct {
+	Category TicketCategory     `json:"category" bson:"category"`
+	Score    *float64           `json:"score,omitempty" bson:"score,omitempty"`
+	Excluded bool               `json:"excluded" bson:"excluded"`
+	Note     string             `json:"note,omitempty" bson:"note,omitempty"`
+	SetBy    primitive.ObjectID `json:"setBy" bson:"setBy"`
+	SetAt    time.Time          `json:"setAt" bson:"setAt"`
+}
+
+type SetSkillOverrideRequest struct {
+	Category TicketCategory `json:"category" binding:"required"`
+	Score    *float64       `json:"score"`
+	Excluded bool           `json:"excluded"`
+	Note     string         `json:"note"`
+}
+
+// RankedTechnician is a technician ordered by skill for a category.
+type RankedTechnician struct {
+	TechnicianID   primitive.ObjectID `json:"technicianId"`
+	TechnicianName string             `json:"technicianName"`
+	Score          float64            `json:"score"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SolutionSet is one version of the solutions suggested for a ticket.
+// Version 1 is the initial suggestion; each refinement adds a version built
+// from the set it refined (Parent) and the technician's feedback.
+type SolutionSet struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	TicketID  primitive.ObjectID  `json:"ticketId" bson:"ticketId"`
+	Version   int                 `json:"version" bson:"version"`
+	Parent    int                 `json:"parent,omitempty" bson:"parent,omitempty"`
+	Feedback  string              `json:"feedback,omitempty" bson:"feedback,omitempty"`
+	Solutions []SuggestedSolution `json:"solutions" bson:"solutions"`
+	// Constraints is all feedback along the refinement chain, oldest first.
+	Constraints  []string           `json:"constraints,omitempty" bson:"constraints,omitempty"`
+	Sources      []string           `json:"sources" bson:"sources"`
+	Confidence   float32            `json:"confidence" bson:"confidence"`
+	ContextUsage ContextUsage       `json:"contextUsage" bson:"contextUsage"`
+	CreatedBy    primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+type RefineSolutionsRequest struct {
+	Feedback string `json:"feedback" binding:"required"`
+	// Version is the solution set to refine; 0 refines the latest.
+	Version int `json:"version"`
+}
+
+type StepStatus string
+
+const (
+	StepAttempted StepStatus = "attempted"
+	StepSucceeded StepStatus = "succeeded"
+	StepFailed    StepStatus = "failed"
+)
+
+// SolutionStepProgress records what a technician did with one step of a
+// suggested solution, so whoever picks up the ticket sees what was tried.
+// Steps are identified by solution title and position.
+type SolutionStepProgress struct {
+	Solution      string             `json:"solution" bson:"solution"`
+	StepIndex     int                `json:"stepIndex" bson:"stepIndex"`
+	Step          string             `json:"step" bson:"step"`
+	Status        StepStatus         `json:"status" bson:"status"`
+	Note          string             `json:"note,omitempty" bson:"note,omitempty"`
+	UpdatedBy     primitive.ObjectID `json:"updatedBy" bson:"updatedBy"`
+	UpdatedByName string             `json:"updatedByName" bson:"updatedByName"`
+	UpdatedAt     time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+type UpdateSolutionStepRequest struct {
+	Solution  string     `json:"solution" binding:"required"`
+	StepIndex int        `json:"stepIndex"`
+	Step      string     `json:"step" binding:"required"`
+	Status    StepStatus `json:"status" binding:"required"`
+	Note      string     `json:"note"`
+}
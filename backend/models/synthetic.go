@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SyntheticCheckType string
+
+const (
+	CheckHTTP SyntheticCheckType = "http"
+	CheckTCP  SyntheticCheckType = "tcp"
+	CheckPing SyntheticCheckType = "ping"
+)
+
+// SyntheticCheck probes an endpoint on a schedule. Checks without a Region
+// run from the backend; the others are picked up by the regional workers
+// polling for that region. FailureThreshold consecutive failures open an
+// anomaly and ticket, which close again on the next success.
+type SyntheticCheck struct {
+	ID   primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name string             `json:"name" bson:"name"`
+	Type SyntheticCheckType `json:"type" bson:"type"`
+	// Target is a URL for HTTP checks, host:port for TCP and a host for ping.
+	Target           string            `json:"target" bson:"target"`
+	Method           string            `json:"method,omitempty" bson:"method,omitempty"`
+	Headers          map[string]string `json:"headers,omitempty" bson:"headers,omitempty"`
+	Body             string            `json:"body,omitempty" bson:"body,omitempty"`
+	Assertions       []CheckAssertion  `json:"assertions,omitempty" bson:"assertions,omitempty"`
+	IntervalSeconds  int               `json:"intervalSeconds" bson:"intervalSeconds"`
+	TimeoutSeconds   int               `json:"timeoutSeconds" bson:"timeoutSeconds"`
+	FailureThreshold int               `json:"failureThreshold" bson:"failureThreshold"`
+	// Severity of the anomaly opened on failure; defaults to high.
+	Severity   string              `json:"severity" bson:"severity"`
+	Region     string              `json:"region,omitempty" bson:"region,omitempty"`
+	ResourceID *primitive.ObjectID `json:"resourceId,omitempty" bson:"resourceId,omitempty"`
+	Tags       map[string]string   `json:"tags,omitempty" bson:"tags,omitempty"`
+	Enabled    bool                `json:"enabled" bson:"enabled"`
+	// State of the latest runs
+	LastRunAt           *time.Time         `json:"lastRunAt,omitempty" bson:"lastRunAt,omitempty"`
+	LastSuccess         *bool              `json:"lastSuccess,omitempty" bson:"lastSuccess,omitempty"`
+	LastResponseTimeMs  float64            `json:"lastResponseTimeMs,omitempty" bson:"lastResponseTimeMs,omitempty"`
+	ConsecutiveFailures int                `json:"consecutiveFailures" bson:"consecutiveFailures"`
+	CreatedBy           primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt           time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt           time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// CheckAssertion is a rule a probe's response must satisfy, e.g.
+// {"source": "statusCode", "comparison": "equals", "target": "200"} or
+// {"source": "header", "property": "Content-Type", "comparison": "contains",
+// "target": "json"}.
+type CheckAssertion struct {
+	// Source is statusCode, responseTime (milliseconds), body or header.
+	Source   string `json:"source" bson:"source"`
+	Property string `json:"property,omitempty" bson:"property,omitempty"`
+	// Comparison is equals, notEquals, lessThan, greaterThan, contains,
+	// notContains or matches (regular expression).
+	Comparison string `json:"comparison" bson:"comparison"`
+	Target     string `json:"target" bson:"target"`
+}
+
+type SyntheticCheckRequest struct {
+	Name             string              `json:"name" binding:"required"`
+	Type             SyntheticCheckType  `json:"type" binding:"required"`
+	Target           string              `json:"target" binding:"required"`
+	Method           string              `json:"method"`
+	Headers          map[string]string   `json:"headers"`
+	Body             string              `json:"body"`
+	Assertions       []CheckAssertion    `json:"assertions"`
+	IntervalSeconds  int                 `json:"intervalSeconds"`
+	TimeoutSeconds   int                 `json:"timeoutSeconds"`
+	FailureThreshold int                 `json:"failureThreshold"`
+	Severity         string              `json:"severity"`
+	Region           string              `json:"region"`
+	ResourceID       *primitive.ObjectID `json:"resourceId"`
+	Tags             map[string]string   `json:"tags"`
+	Enabled          *bool               `json:"enabled"`
+}
+
+// CheckResult is the outcome of one probe.
+type CheckResult struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	CheckID        primitive.ObjectID `json:"checkId" bson:"checkId"`
+	Region         string             `json:"region,omitempty" bson:"region,omitempty"`
+	Success        bool               `json:"success" bson:"success"`
+	ResponseTimeMs float64            `json:"responseTimeMs" bson:"responseTimeMs"`
+	StatusCode     int                `json:"statusCode,omitempty" bson:"statusCode,omitempty"`
+	Error          string             `json:"error,omitempty" bson:"error,omitempty"`
+	// FailedAssertions describes the assertions the response broke.
+	FailedAssertions []string  `json:"failedAssertions,omitempty" bson:"failedAssertions,omitempty"`
+	CheckedAt        time.Time `json:"checkedAt" bson:"checkedAt"`
+}
+
+// CheckResultReport is posted by regional workers with the results of the
+// checks they ran.
+type CheckResultReport struct {
+	Region  string        `json:"region" binding:"required"`
+	Results []CheckResult `json:"results" binding:"required"`
+}
+
+// CheckHistory is a check's recent results, newest first, with their
+// uptime and response times.
+type CheckHistory struct {
+	Results           []CheckResult `json:"results"`
+	UptimePercent     float64       `json:"uptimePercent"`
+	AvgResponseTimeMs float64       `json:"avgResponseTimeMs"`
+	P95ResponseTimeMs float64       `json:"p95ResponseTimeMs"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TaxonomyID is the _id of the single taxonomy document in the taxonomy collection.
+const TaxonomyID = "default"
+
+// Taxonomy is the organization's admin-managed set of ticket categories and
+// priorities. When no taxonomy has been saved, the built-in constants apply.
+type Taxonomy struct {
+	ID         string             `json:"-" bson:"_id"`
+	Categories []TaxonomyCategory `json:"categories" bson:"categories"`
+	Priorities []TaxonomyPriority `json:"priorities" bson:"priorities"`
+	UpdatedAt  time.Time          `json:"updatedAt" bson:"updatedAt"`
+	UpdatedBy  primitive.ObjectID `json:"updatedBy,omitempty" bson:"updatedBy,omitempty"`
+}
+
+type TaxonomyCategory struct {
+	Name          TicketCategory `json:"name" bson:"name"`
+	Description   string         `json:"description,omitempty" bson:"description,omitempty"`
+	Subcategories []string       `json:"subcategories,omitempty" bson:"subcategories,omitempty"`
+}
+
+type TaxonomyPriority struct {
+	Name        TicketPriority `json:"name" bson:"name"`
+	Description string         `json:"description,omitempty" bson:"description,omitempty"`
+	// Service level targets for tickets of this priority, zero means no target.
+	ResponseSLAMinutes int `json:"responseSlaMinutes" bson:"responseSlaMinutes"`
+	ResolutionSLAHours int `json:"resolutionSlaHours" bson:"resolutionSlaHours"`
+}
+
+type UpdateTaxonomyRequest struct {
+	Categories []TaxonomyCategory `json:"categories" binding:"required"`
+	Priorities []TaxonomyPriority `json:"priorities" binding:"required"`
+}
@@ -9,6 +9,7 @@ import (
 type TicketStatus string
 type TicketPriority string
 type TicketCategory string
+type TicketType string
 
 const (
 	StatusOpen       TicketStatus = "open"
@@ -16,9 +17,9 @@ const (
 	StatusResolved   TicketStatus = "resolved"
 	StatusClosed     TicketStatus = "closed"
 
-	PriorityLow    TicketPriority = "low"
-	PriorityMedium TicketPriority = "medium"
-	PriorityHigh   TicketPriority = "high"
+	PriorityLow      TicketPriority = "low"
+	PriorityMedium   TicketPriority = "medium"
+	PriorityHigh     TicketPriority = "high"
 	PriorityCritical TicketPriority = "critical"
 
 	CategoryNetwork     TicketCategory = "Network Issue"
@@ -27,6 +28,11 @@ const (
 	CategorySecurity    TicketCategory = "Security Issue"
 	CategoryPerformance TicketCategory = "Performance Issue"
 	CategoryOther       TicketCategory = "Other"
+
+	// Tickets without a type are incidents.
+	TicketTypeIncident       TicketType = "incident"
+	TicketTypeServiceRequest TicketType = "service_request"
+	TicketTypeChange         TicketType = "change"
 )
 
 type Ticket struct {
@@ -34,29 +40,184 @@ type Ticket struct {
 	Title       string             `json:"title" bson:"title" binding:"required"`
 	Description string             `json:"description" bson:"description" binding:"required"`
 	Category    TicketCategory     `json:"category" bson:"category"`
-	Priority    TicketPriority     `json:"priority" bson:"priority"`
-	Status      TicketStatus       `json:"status" bson:"status"`
-	AssignedTo  *primitive.ObjectID `json:"assignedTo,omitempty" bson:"assignedTo,omitempty"`
-	CreatedBy   primitive.ObjectID `json:"createdBy" bson:"createdBy" binding:"required"`
-	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
-	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
-	ResolvedAt  *time.Time         `json:"resolvedAt,omitempty" bson:"resolvedAt,omitempty"`
+	// Subcategory refines the primary category, e.g. Network Issue → VPN.
+	Subcategory string `json:"subcategory,omitempty" bson:"subcategory,omitempty"`
+	// SecondaryCategories holds additional labels for tickets that span more
+	// than one category. Category always remains the primary label.
+	SecondaryCategories []TicketCategory    `json:"secondaryCategories,omitempty" bson:"secondaryCategories,omitempty"`
+	Priority            TicketPriority      `json:"priority" bson:"priority"`
+	Status              TicketStatus        `json:"status" bson:"status"`
+	AssignedTo          *primitive.ObjectID `json:"assignedTo,omitempty" bson:"assignedTo,omitempty"`
+	AcknowledgedAt      *time.Time          `json:"acknowledgedAt,omitempty" bson:"acknowledgedAt,omitempty"`
+	AcknowledgedBy      *primitive.ObjectID `json:"acknowledgedBy,omitempty" bson:"acknowledgedBy,omitempty"`
+	CreatedBy           primitive.ObjectID  `json:"createdBy" bson:"createdBy" binding:"required"`
+	CreatedAt           time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt           time.Time           `json:"updatedAt" bson:"updatedAt"`
+	ResolvedAt          *time.Time          `json:"resolvedAt,omitempty" bson:"resolvedAt,omitempty"`
+	// Status transitions: when the status last changed, when work first
+	// started, when the ticket was closed and when it was last reopened.
+	StatusChangedAt *time.Time `json:"statusChangedAt,omitempty" bson:"statusChangedAt,omitempty"`
+	StartedAt       *time.Time `json:"startedAt,omitempty" bson:"startedAt,omitempty"`
+	ClosedAt        *time.Time `json:"closedAt,omitempty" bson:"closedAt,omitempty"`
+	ReopenedAt      *time.Time `json:"reopenedAt,omitempty" bson:"reopenedAt,omitempty"`
+	ReopenCount     int        `json:"reopenCount,omitempty" bson:"reopenCount,omitempty"`
+	// ArchivedAt is set on tickets moved to the archive collection, which
+	// are read-only.
+	ArchivedAt *time.Time `json:"archivedAt,omitempty" bson:"archivedAt,omitempty"`
+	// DeletedAt and DeletedBy are set on deleted tickets, which are kept
+	// apart until an admin restores them.
+	DeletedAt *time.Time          `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	DeletedBy *primitive.ObjectID `json:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
+	// DueAt is when the ticket should be resolved, set by hand or on
+	// follow-up tickets such as postmortem action items. Tickets without
+	// one are due by their priority's resolution target; responses fill it
+	// in with DueFromSLA set.
+	DueAt      *time.Time `json:"dueAt,omitempty" bson:"dueAt,omitempty"`
+	DueFromSLA bool       `json:"dueFromSla,omitempty" bson:"-"`
+	// Overdue is set in responses on open and in-progress tickets past due.
+	Overdue bool `json:"overdue,omitempty" bson:"-"`
+	// TimeSpentMinutes is the total of the ticket's worklog entries.
+	TimeSpentMinutes int `json:"timeSpentMinutes,omitempty" bson:"timeSpentMinutes,omitempty"`
+	// Diagnostics are reports appended by the diagnostic agent.
+	Diagnostics []DiagnosticReport `json:"diagnostics,omitempty" bson:"diagnostics,omitempty"`
+	// SolutionSteps tracks which suggested solution steps have been tried.
+	SolutionSteps []SolutionStepProgress `json:"solutionSteps,omitempty" bson:"solutionSteps,omitempty"`
+	// AutomationResults holds the output of runbooks executed for the ticket.
+	AutomationResults []AutomationResult `json:"automationResults,omitempty" bson:"automationResults,omitempty"`
+	// SystemInfo holds diagnostic data uploaded through diagnostic requests.
+	SystemInfo []SystemInfo `json:"systemInfo,omitempty" bson:"systemInfo,omitempty"`
+	// EndpointAgentID is the machine the ticket is about; diagnostic requests
+	// are routed to its agent.
+	EndpointAgentID *primitive.ObjectID `json:"endpointAgentId,omitempty" bson:"endpointAgentId,omitempty"`
+	// LicenseID links renewal and license expiry tickets to the license.
+	LicenseID *primitive.ObjectID `json:"licenseId,omitempty" bson:"licenseId,omitempty"`
+	Type      TicketType          `json:"type,omitempty" bson:"type,omitempty"`
+	// ServiceRequest is set on service request tickets raised from the
+	// catalog.
+	ServiceRequest *ServiceRequest `json:"serviceRequest,omitempty" bson:"serviceRequest,omitempty"`
+	// Change is set on change tickets.
+	Change *ChangeRequest `json:"change,omitempty" bson:"change,omitempty"`
+	// LinkedResourceIDs are monitored resources mentioned in the ticket.
+	LinkedResourceIDs []primitive.ObjectID `json:"linkedResourceIds,omitempty" bson:"linkedResourceIds,omitempty"`
+	// ProblemID links the ticket to the proactive problem ticket raised for
+	// its cluster of similar tickets.
+	ProblemID *primitive.ObjectID `json:"problemId,omitempty" bson:"problemId,omitempty"`
+	// ThreadSummary is generated on demand and refreshed on new activity.
+	ThreadSummary *ThreadSummary `json:"threadSummary,omitempty" bson:"threadSummary,omitempty"`
+	// Rating is the requester's satisfaction with the resolution.
+	Rating *ResolutionRating `json:"rating,omitempty" bson:"rating,omitempty"`
+	// Department and Location are the requester's when the ticket was
+	// raised; Team is the support team a ticket route sent it to.
+	Department string `json:"department,omitempty" bson:"department,omitempty"`
+	Location   string `json:"location,omitempty" bson:"location,omitempty"`
+	Team       string `json:"team,omitempty" bson:"team,omitempty"`
+	// Tags group related work across tickets, e.g. vpn or
+	// exchange-migration.
+	Tags []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	// ExternalID is the ticket's ID in the helpdesk it was imported from.
+	ExternalID string `json:"externalId,omitempty" bson:"externalId,omitempty"`
+	// ServiceNow is set once the ticket is pushed to ServiceNow as an
+	// incident.
+	ServiceNow *ServiceNowLink `json:"serviceNow,omitempty" bson:"serviceNow,omitempty"`
+	// Issues are the GitHub and GitLab issues a software ticket is linked
+	// to.
+	Issues []IssueLink `json:"issues,omitempty" bson:"issues,omitempty"`
+	// AutoTriage is the AI triage applied when the ticket was created.
+	AutoTriage *TicketTriage `json:"autoTriage,omitempty" bson:"autoTriage,omitempty"`
+	// DuplicateOf is the ticket this one was merged into; MergedFrom lists
+	// the duplicates merged into this one.
+	DuplicateOf *primitive.ObjectID  `json:"duplicateOf,omitempty" bson:"duplicateOf,omitempty"`
+	MergedFrom  []primitive.ObjectID `json:"mergedFrom,omitempty" bson:"mergedFrom,omitempty"`
+	// Links relate the ticket to others: blockers, duplicates, and the
+	// parent and child tickets an incident is split into.
+	Links []TicketLink `json:"links,omitempty" bson:"links,omitempty"`
+	// CustomFields holds the values of admin-defined custom fields by key.
+	CustomFields map[string]interface{} `json:"customFields,omitempty" bson:"customFields,omitempty"`
+	// ChatSessionID is the support chat the ticket was escalated from;
+	// ChatContact is how to reach the visitor when they were not signed in.
+	ChatSessionID *primitive.ObjectID `json:"chatSessionId,omitempty" bson:"chatSessionId,omitempty"`
+	ChatContact   *ChatContact        `json:"chatContact,omitempty" bson:"chatContact,omitempty"`
+}
+
+type ResolutionRating struct {
+	Score   int       `json:"score" bson:"score"`
+	Comment string    `json:"comment,omitempty" bson:"comment,omitempty"`
+	RatedAt time.Time `json:"ratedAt" bson:"ratedAt"`
+}
+
+// ThreadSummary condenses a ticket's description and activity for handover
+// between technicians and for updates to the requester.
+type ThreadSummary struct {
+	Handover string `json:"handover" bson:"handover"`
+	Customer string `json:"customer" bson:"customer"`
+	// Fallback is true when no LLM was available and the summary was built
+	// from the ticket fields directly.
+	Fallback    bool      `json:"fallback" bson:"fallback"`
+	GeneratedAt time.Time `json:"generatedAt" bson:"generatedAt"`
 }
 
 type CreateTicketRequest struct {
-	Title       string         `json:"title" binding:"required"`
-	Description string         `json:"description" binding:"required"`
-	Category    TicketCategory `json:"category,omitempty"`
-	Priority    TicketPriority `json:"priority,omitempty"`
+	Title               string           `json:"title" binding:"required"`
+	Description         string           `json:"description" binding:"required"`
+	Category            TicketCategory   `json:"category,omitempty"`
+	Subcategory         string           `json:"subcategory,omitempty"`
+	SecondaryCategories []TicketCategory `json:"secondaryCategories,omitempty"`
+	Priority            TicketPriority   `json:"priority,omitempty"`
+	// TriageRunID links the ticket to the triage run that suggested its
+	// fields; the final values are recorded as feedback for experiments.
+	TriageRunID string `json:"triageRunId,omitempty"`
+	// PreflightID links the ticket to the preflight that suggested articles
+	// for its draft, recording that the suggestions did not help.
+	PreflightID  string                 `json:"preflightId,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+	// EndpointAgentID is the machine the ticket is about.
+	EndpointAgentID string `json:"endpointAgentId,omitempty"`
+	// DueAt overrides the due date derived from the priority's resolution
+	// target.
+	DueAt *time.Time `json:"dueAt,omitempty"`
 }
 
 type UpdateTicketRequest struct {
 	Title       string         `json:"title,omitempty"`
 	Description string         `json:"description,omitempty"`
 	Category    TicketCategory `json:"category,omitempty"`
-	Priority    TicketPriority `json:"priority,omitempty"`
-	Status      TicketStatus   `json:"status,omitempty"`
-	AssignedTo  *primitive.ObjectID `json:"assignedTo,omitempty"`
+	// Subcategory is replaced when non-nil; send "" to clear it.
+	Subcategory *string `json:"subcategory,omitempty"`
+	// SecondaryCategories replaces the stored secondary labels when non-nil;
+	// send an empty array to clear them.
+	SecondaryCategories *[]TicketCategory   `json:"secondaryCategories,omitempty"`
+	Priority            TicketPriority      `json:"priority,omitempty"`
+	Status              TicketStatus        `json:"status,omitempty"`
+	AssignedTo          *primitive.ObjectID `json:"assignedTo,omitempty"`
+	// Department, Location and Team are replaced when non-nil; send "" to
+	// clear them.
+	Department *string `json:"department,omitempty"`
+	Location   *string `json:"location,omitempty"`
+	Team       *string `json:"team,omitempty"`
+	// CustomFields sets the custom fields given, keeping the others; a
+	// null value clears a field.
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+	// DueAt sets the due date by hand; ClearDueAt removes it so the ticket
+	// is due by its priority's resolution target again.
+	DueAt      *time.Time `json:"dueAt,omitempty"`
+	ClearDueAt bool       `json:"clearDueAt,omitempty"`
+}
+
+// MergeTicketRequest names the ticket a duplicate is merged into.
+type MergeTicketRequest struct {
+	TargetID string `json:"targetId" binding:"required"`
+}
+
+type TicketTagsRequest struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+// TicketTagCount is how many live tickets carry a tag.
+type TicketTagCount struct {
+	Tag   string `json:"tag" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+	Open  int64  `json:"open" bson:"open"`
 }
 
 type TicketWithUser struct {
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TicketFieldChange is one ticket field changed by an update. Users are
+// given by ID and long text is cut short.
+type TicketFieldChange struct {
+	Field string `json:"field" bson:"field"`
+	Old   string `json:"old" bson:"old"`
+	New   string `json:"new" bson:"new"`
+}
+
+// TicketHistoryEntry records who changed which fields of a ticket.
+type TicketHistoryEntry struct {
+	ID            primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	TicketID      primitive.ObjectID  `json:"ticketId" bson:"ticketId"`
+	ChangedBy     primitive.ObjectID  `json:"changedBy" bson:"changedBy"`
+	ChangedByName string              `json:"changedByName" bson:"changedByName"`
+	Changes       []TicketFieldChange `json:"changes" bson:"changes"`
+	CreatedAt     time.Time           `json:"createdAt" bson:"createdAt"`
+	// OnBehalfOf is set when ChangedBy acted for someone else, such as a
+	// delegate deciding an approval.
+	OnBehalfOf     *primitive.ObjectID `json:"onBehalfOf,omitempty" bson:"onBehalfOf,omitempty"`
+	OnBehalfOfName string              `json:"onBehalfOfName,omitempty" bson:"onBehalfOfName,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// TicketImportError explains why a CSV row was not imported. Row numbers
+// count the header as row 1, as spreadsheets show them.
+type TicketImportError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// TicketImportResult summarizes a CSV ticket import.
+type TicketImportResult struct {
+	DryRun bool `json:"dryRun"`
+	Rows   int  `json:"rows"`
+	// Created counts the tickets created, or that would be on a dry run.
+	Created int `json:"created"`
+	Failed  int `json:"failed"`
+	// Skipped counts rows whose external ID was already imported.
+	Skipped   int                  `json:"skipped"`
+	TicketIDs []primitive.ObjectID `json:"ticketIds"`
+	Errors    []TicketImportError  `json:"errors"`
+	// Columns maps each recognized CSV column to the ticket field it
+	// fills; IgnoredColumns were not recognized.
+	Columns        map[string]string `json:"columns"`
+	IgnoredColumns []string          `json:"ignoredColumns"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TicketLinkType is how a ticket relates to the linked ticket. Each link is
+// stored on both tickets, with the inverse type on the other one.
+type TicketLinkType string
+
+const (
+	TicketLinkBlocks       TicketLinkType = "blocks"
+	TicketLinkBlockedBy    TicketLinkType = "blocked_by"
+	TicketLinkDuplicates   TicketLinkType = "duplicates"
+	TicketLinkDuplicatedBy TicketLinkType = "duplicated_by"
+	// TicketLinkParent points a sub-task at the ticket it is part of, and
+	// TicketLinkChild the other way.
+	TicketLinkParent TicketLinkType = "parent"
+	TicketLinkChild  TicketLinkType = "child"
+	// TicketLinkAfter schedules a change after the linked change, and
+	// TicketLinkBefore the other way. Both tickets must be changes.
+	TicketLinkAfter  TicketLinkType = "after"
+	TicketLinkBefore TicketLinkType = "before"
+)
+
+// TicketLinkInverse maps each link type to the type stored on the linked
+// ticket.
+var TicketLinkInverse = map[TicketLinkType]TicketLinkType{
+	TicketLinkBlocks:       TicketLinkBlockedBy,
+	TicketLinkBlockedBy:    TicketLinkBlocks,
+	TicketLinkDuplicates:   TicketLinkDuplicatedBy,
+	TicketLinkDuplicatedBy: TicketLinkDuplicates,
+	TicketLinkParent:       TicketLinkChild,
+	TicketLinkChild:        TicketLinkParent,
+	TicketLinkAfter:        TicketLinkBefore,
+	TicketLinkBefore:       TicketLinkAfter,
+}
+
+type TicketLink struct {
+	Type      TicketLinkType     `json:"type" bson:"type"`
+	TicketID  primitive.ObjectID `json:"ticketId" bson:"ticketId"`
+	CreatedBy primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// CreateTicketLinkRequest links the ticket to TicketID, e.g. type parent
+// makes TicketID the ticket's parent.
+type CreateTicketLinkRequest struct {
+	Type     TicketLinkType `json:"type" binding:"required"`
+	TicketID string         `json:"ticketId" binding:"required"`
+}
+
+// TicketLinkView is a link with the linked ticket's title, status and
+// assignee.
+type TicketLinkView struct {
+	TicketLink
+	Title      string              `json:"title"`
+	Status     TicketStatus        `json:"status"`
+	AssignedTo *primitive.ObjectID `json:"assignedTo,omitempty"`
+}
+
+// SplitTicketRequest splits a ticket into sub-tasks, each linked to it as
+// its parent.
+type SplitTicketRequest struct {
+	Subtasks []SubtaskRequest `json:"subtasks" binding:"required,min=1,max=20,dive"`
+}
+
+// SubtaskRequest is one sub-task. It takes the parent's category, priority,
+// tags and org fields; the description defaults to pointing at the parent.
+// Sub-tasks without an assignee go through the assignment rules.
+type SubtaskRequest struct {
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description"`
+	AssignedTo  string     `json:"assignedTo,omitempty"`
+	DueAt       *time.Time `json:"dueAt,omitempty"`
+}
+
+// SubtaskNode is a sub-task with its own sub-tasks.
+type SubtaskNode struct {
+	ID         primitive.ObjectID  `json:"id"`
+	Title      string              `json:"title"`
+	Status     TicketStatus        `json:"status"`
+	Priority   TicketPriority      `json:"priority"`
+	AssignedTo *primitive.ObjectID `json:"assignedTo,omitempty"`
+	DueAt      *time.Time          `json:"dueAt,omitempty"`
+	Subtasks   []SubtaskNode       `json:"subtasks"`
+	// DueFromSLA and Overdue are as on Ticket.
+	DueFromSLA bool `json:"dueFromSla,omitempty"`
+	Overdue    bool `json:"overdue,omitempty"`
+}
+
+// SubtaskTree is a ticket's sub-tasks at every level, with how many are
+// done (resolved or closed) and the status they roll up to: open until one
+// is started, resolved once all are done, and in progress in between.
+type SubtaskTree struct {
+	TicketID primitive.ObjectID `json:"ticketId"`
+	Subtasks []SubtaskNode      `json:"subtasks"`
+	Total    int                `json:"total"`
+	Done     int                `json:"done"`
+	Status   TicketStatus       `json:"status,omitempty"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIUsage counts a user's requests to one route group, such as "tickets"
+// or "ai", on one UTC day.
+type APIUsage struct {
+	ID     string             `json:"-" bson:"_id"`
+	Day    string             `json:"day" bson:"day"`
+	UserID primitive.ObjectID `json:"userId" bson:"userId"`
+	Group  string             `json:"group" bson:"group"`
+	Count  int64              `json:"count" bson:"count"`
+}
+
+// UsageDay is one day of a user's requests by route group.
+type UsageDay struct {
+	Day    string           `json:"day"`
+	Total  int64            `json:"total"`
+	Groups map[string]int64 `json:"groups"`
+}
+
+// UserUsage is a user's requests over a report's days.
+type UserUsage struct {
+	UserID   primitive.ObjectID `json:"userId"`
+	UserName string             `json:"userName,omitempty"`
+	Email    string             `json:"email,omitempty"`
+	Total    int64              `json:"total"`
+	Days     []UsageDay         `json:"days"`
+}
+
+type UsageReport struct {
+	From  string      `json:"from"`
+	To    string      `json:"to"`
+	Users []UserUsage `json:"users"`
+}
+
+// UsageQuota caps how often each user may call an expensive endpoint per
+// UTC day. A DailyLimit of 0 means no limit.
+type UsageQuota struct {
+	Key         string `json:"key" bson:"_id"`
+	Description string `json:"description,omitempty" bson:"description,omitempty"`
+	DailyLimit  int    `json:"dailyLimit" bson:"dailyLimit"`
+	// Builtin is set on quotas the code defines and that have not been
+	// changed yet.
+	Builtin   bool                `json:"builtin" bson:"-"`
+	UpdatedAt time.Time           `json:"updatedAt,omitempty" bson:"updatedAt"`
+	UpdatedBy *primitive.ObjectID `json:"updatedBy,omitempty" bson:"updatedBy,omitempty"`
+}
+
+type UsageQuotaRequest struct {
+	Description string `json:"description"`
+	DailyLimit  *int   `json:"dailyLimit" binding:"required"`
+}
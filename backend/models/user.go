@@ -11,16 +11,31 @@ type UserRole string
 const (
 	RoleAdmin      UserRole = "admin"
 	RoleTechnician UserRole = "technician"
+	// RoleRequester is an end user who can only use the portal API to raise
+	// and follow their own tickets.
+	RoleRequester UserRole = "requester"
 )
 
+// ValidRole reports whether r is a known user role.
+func ValidRole(r UserRole) bool {
+	return r == RoleAdmin || r == RoleTechnician || r == RoleRequester
+}
+
 type User struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name      string             `json:"name" bson:"name" binding:"required"`
-	Email     string             `json:"email" bson:"email" binding:"required,email"`
-	Password  string             `json:"-" bson:"password" binding:"required,min=6"`
-	Role      UserRole           `json:"role" bson:"role" binding:"required"`
-	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
-	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name     string             `json:"name" bson:"name" binding:"required"`
+	Email    string             `json:"email" bson:"email" binding:"required,email"`
+	Password string             `json:"-" bson:"password" binding:"required,min=6"`
+	Role     UserRole           `json:"role" bson:"role" binding:"required"`
+	// Location (site or office) and Department are optional; ticket
+	// analytics group requesters by them.
+	Location   string `json:"location,omitempty" bson:"location,omitempty"`
+	Department string `json:"department,omitempty" bson:"department,omitempty"`
+	// WorkingHours are when a technician takes tickets; without them they
+	// are always on shift.
+	WorkingHours *WorkingHours `json:"workingHours,omitempty" bson:"workingHours,omitempty"`
+	CreatedAt    time.Time     `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt" bson:"updatedAt"`
 }
 
 type LoginRequest struct {
@@ -33,6 +48,9 @@ type RegisterRequest struct {
 	Email    string   `json:"email" binding:"required,email"`
 	Password string   `json:"password" binding:"required,min=6"`
 	Role     UserRole `json:"role" binding:"required"`
+	// Optional
+	Location   string `json:"location"`
+	Department string `json:"department"`
 }
 
 type AuthResponse struct {
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WorklogEntry is time a technician spent on a ticket. The ticket keeps the
+// total in TimeSpentMinutes.
+type WorklogEntry struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TicketID primitive.ObjectID `json:"ticketId" bson:"ticketId"`
+	UserID   primitive.ObjectID `json:"userId" bson:"userId"`
+	UserName string             `json:"userName" bson:"userName"`
+	Minutes  int                `json:"minutes" bson:"minutes"`
+	Note     string             `json:"note,omitempty" bson:"note,omitempty"`
+	// WorkedAt is when the work was done, by default when it was logged.
+	WorkedAt  time.Time `json:"workedAt" bson:"workedAt"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+}
+
+type CreateWorklogRequest struct {
+	Minutes  int        `json:"minutes" binding:"required"`
+	Note     string     `json:"note"`
+	WorkedAt *time.Time `json:"workedAt"`
+}
+
+type UpdateWorklogRequest struct {
+	Minutes  *int       `json:"minutes"`
+	Note     *string    `json:"note"`
+	WorkedAt *time.Time `json:"workedAt"`
+}
+
+// WorklogTotal is the time one technician logged.
+type WorklogTotal struct {
+	UserID   primitive.ObjectID `json:"userId" bson:"_id"`
+	UserName string             `json:"userName" bson:"userName"`
+	Minutes  int                `json:"minutes" bson:"minutes"`
+	Entries  int                `json:"entries" bson:"entries"`
+}
+
+// TicketWorklogs is a ticket's worklog with its totals.
+type TicketWorklogs struct {
+	Entries      []WorklogEntry `json:"entries"`
+	TotalMinutes int            `json:"totalMinutes"`
+	ByTechnician []WorklogTotal `json:"byTechnician"`
+}
+
+// TicketTimeSpent is the time logged on one ticket.
+type TicketTimeSpent struct {
+	TicketID primitive.ObjectID `json:"ticketId" bson:"_id"`
+	Title    string             `json:"title" bson:"title"`
+	Minutes  int                `json:"minutes" bson:"minutes"`
+}
+
+// WorklogStats is the time logged across all tickets.
+type WorklogStats struct {
+	TotalMinutes int               `json:"totalMinutes"`
+	ByTechnician []WorklogTotal    `json:"byTechnician"`
+	TopTickets   []TicketTimeSpent `json:"topTickets"`
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	nvdCVEEndpoint = "https://services.nvd.nist.gov/rest/json/cves/2.0"
+	nvdPageSize    = 2000
+	nvdTimeFormat  = "2006-01-02T15:04:05.000Z"
+)
+
+type nvdResponse struct {
+	TotalResults    int `json:"totalResults"`
+	Vulnerabilities []struct {
+		CVE nvdCVE `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+type nvdCVE struct {
+	ID           string `json:"id"`
+	Published    string `json:"published"`
+	Descriptions []struct {
+		Lang  string `json:"lang"`
+		Value string `json:"value"`
+	} `json:"descriptions"`
+	Metrics struct {
+		CVSSMetricV31 []nvdCVSSMetric `json:"cvssMetricV31"`
+		CVSSMetricV30 []nvdCVSSMetric `json:"cvssMetricV30"`
+		CVSSMetricV2  []nvdCVSSMetric `json:"cvssMetricV2"`
+	} `json:"metrics"`
+	Configurations []struct {
+		Nodes []struct {
+			CPEMatch []struct {
+				Vulnerable            bool   `json:"vulnerable"`
+				Criteria              string `json:"criteria"`
+				VersionStartIncluding string `json:"versionStartIncluding"`
+				VersionStartExcluding string `json:"versionStartExcluding"`
+				VersionEndIncluding   string `json:"versionEndIncluding"`
+				VersionEndExcluding   string `json:"versionEndExcluding"`
+			} `json:"cpeMatch"`
+		} `json:"nodes"`
+	} `json:"configurations"`
+	References []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+}
+
+// nvdCVSSMetric covers CVSS v3, which grades severity in cvssData, and v2,
+// which grades it on the metric itself.
+type nvdCVSSMetric struct {
+	CVSSData struct {
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"`
+	} `json:"cvssData"`
+	BaseSeverity string `json:"baseSeverity"`
+}
+
+// fetchNVD returns the CVEs modified in [since, until) from the NVD CVE API.
+// The API limits a query to 120 days.
+func fetchNVD(ctx context.Context, client *http.Client, apiKey string, since, until time.Time) ([]models.Advisory, error) {
+	advisories := []models.Advisory{}
+	for start := 0; ; start += nvdPageSize {
+		query := url.Values{}
+		query.Set("lastModStartDate", since.UTC().Format(nvdTimeFormat))
+		query.Set("lastModEndDate", until.UTC().Format(nvdTimeFormat))
+		query.Set("resultsPerPage", strconv.Itoa(nvdPageSize))
+		query.Set("startIndex", strconv.Itoa(start))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, nvdCVEEndpoint+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if apiKey != "" {
+			req.Header.Set("apiKey", apiKey)
+		}
+
+		var out nvdResponse
+		if err := getFeedJSON(client, req, &out); err != nil {
+			return nil, fmt.Errorf("nvd: %w", err)
+		}
+		for _, v := range out.Vulnerabilities {
+			advisories = append(advisories, nvdAdvisory(v.CVE))
+		}
+		if start+nvdPageSize >= out.TotalResults {
+			return advisories, nil
+		}
+	}
+}
+
+func nvdAdvisory(cve nvdCVE) models.Advisory {
+	advisory := models.Advisory{AdvisoryID: cve.ID, Source: "nvd", Title: cve.ID, Affected: []models.AffectedProduct{}}
+	for _, d := range cve.Descriptions {
+		if d.Lang == "en" {
+			advisory.Description = d.Value
+			advisory.Title = cve.ID + ": " + truncateWords(d.Value, 100)
+			break
+		}
+	}
+	if published, err := time.Parse("2006-01-02T15:04:05", cve.Published); err == nil {
+		advisory.PublishedAt = &published
+	}
+
+	for _, metrics := range [][]nvdCVSSMetric{cve.Metrics.CVSSMetricV31, cve.Metrics.CVSSMetricV30, cve.Metrics.CVSSMetricV2} {
+		if len(metrics) == 0 {
+			continue
+		}
+		severity := metrics[0].CVSSData.BaseSeverity
+		if severity == "" {
+			severity = metrics[0].BaseSeverity
+		}
+		advisory.Severity = strings.ToLower(severity)
+		advisory.CVSSScore = metrics[0].CVSSData.BaseScore
+		break
+	}
+
+	for _, config := range cve.Configurations {
+		for _, node := range config.Nodes {
+			for _, m := range node.CPEMatch {
+				// cpe:2.3:part:vendor:product:version:...
+				parts := strings.Split(m.Criteria, ":")
+				if !m.Vulnerable || len(parts) < 6 {
+					continue
+				}
+				product := models.AffectedProduct{
+					Vendor:                parts[3],
+					Product:               parts[4],
+					VersionStartIncluding: m.VersionStartIncluding,
+					VersionStartExcluding: m.VersionStartExcluding,
+					VersionEndIncluding:   m.VersionEndIncluding,
+					VersionEndExcluding:   m.VersionEndExcluding,
+				}
+				if parts[5] != "*" && parts[5] != "-" {
+					product.Version = parts[5]
+				}
+				advisory.Affected = append(advisory.Affected, product)
+			}
+		}
+	}
+	for _, r := range cve.References {
+		advisory.References = append(advisory.References, r.URL)
+	}
+	return advisory
+}
+
+// fetchVendorFeed reads a vendor feed: a JSON document with an "advisories"
+// list in the Advisory shape.
+func fetchVendorFeed(ctx context.Context, client *http.Client, feedURL string) ([]models.Advisory, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Advisories []models.Advisory `json:"advisories"`
+	}
+	if err := getFeedJSON(client, req, &out); err != nil {
+		return nil, fmt.Errorf("%s: %w", feedURL, err)
+	}
+	source := feedURL
+	if u, err := url.Parse(feedURL); err == nil && u.Host != "" {
+		source = u.Host
+	}
+	for i := range out.Advisories {
+		if out.Advisories[i].Source == "" {
+			out.Advisories[i].Source = source
+		}
+	}
+	return out.Advisories, nil
+}
+
+func getFeedJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 300))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(data))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// truncateWords cuts s to at most max bytes at a word boundary.
+func truncateWords(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := truncateBytes(s, max)
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
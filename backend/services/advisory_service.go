@@ -0,0 +1,577 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	// nvdFirstSync is how far back the first NVD sync reaches, and
+	// nvdMaxWindow the longest range the API accepts in one query.
+	nvdFirstSync = 7 * 24 * time.Hour
+	nvdMaxWindow = 120 * 24 * time.Hour
+	// advisoryTicketReferences is how many reference links a ticket lists.
+	advisoryTicketReferences = 5
+)
+
+// AdvisoryService ingests vulnerability advisories from the NVD CVE feed
+// and vendor feeds, matches them against the software endpoint agents
+// report, and opens one security ticket per advisory affecting the fleet.
+type AdvisoryService struct {
+	db          *database.MongoDB
+	taxonomy    *TaxonomyService
+	comments    *CommentService
+	client      *http.Client
+	nvdEnabled  bool
+	nvdAPIKey   string
+	feedURLs    []string
+	minSeverity string
+}
+
+func NewAdvisoryService(db *database.MongoDB, cfg *config.Config, taxonomy *TaxonomyService, comments *CommentService) *AdvisoryService {
+	minSeverity := strings.ToLower(cfg.AdvisoryMinSeverity)
+	if !isAnomalySeverity(minSeverity) {
+		log.Printf("Invalid ADVISORY_MIN_SEVERITY %q, using medium", cfg.AdvisoryMinSeverity)
+		minSeverity = "medium"
+	}
+	return &AdvisoryService{
+		db:          db,
+		taxonomy:    taxonomy,
+		comments:    comments,
+		client:      &http.Client{Timeout: 60 * time.Second},
+		nvdEnabled:  cfg.AdvisoryNVDEnabled,
+		nvdAPIKey:   cfg.AdvisoryNVDAPIKey,
+		feedURLs:    cfg.AdvisoryFeedURLs,
+		minSeverity: minSeverity,
+	}
+}
+
+func (s *AdvisoryService) collection() *mongo.Collection {
+	return s.db.GetCollection("advisories")
+}
+
+// Start syncs the feeds every interval until ctx is cancelled.
+func (s *AdvisoryService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if _, err := s.Sync(ctx); err != nil {
+					log.Printf("advisory sync error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// ValidateAdvisory checks an advisory from a vendor feed or import and
+// normalizes its severity.
+func ValidateAdvisory(a *models.Advisory) error {
+	a.AdvisoryID = strings.TrimSpace(a.AdvisoryID)
+	if a.AdvisoryID == "" {
+		return errors.New("advisoryId is required")
+	}
+	a.Severity = strings.ToLower(a.Severity)
+	if a.Severity != "" && !isAnomalySeverity(a.Severity) {
+		return fmt.Errorf("%s: severity must be low, medium, high or critical", a.AdvisoryID)
+	}
+	if a.Title == "" {
+		a.Title = a.AdvisoryID
+	}
+	for i, p := range a.Affected {
+		if strings.TrimSpace(p.Product) == "" {
+			return fmt.Errorf("%s: affected[%d]: product is required", a.AdvisoryID, i)
+		}
+	}
+	if a.Affected == nil {
+		a.Affected = []models.AffectedProduct{}
+	}
+	return nil
+}
+
+// Sync fetches new advisories from NVD and the vendor feeds, then matches
+// the stored advisories against the current inventory. Feed errors are
+// reported in the result without stopping the other feeds.
+func (s *AdvisoryService) Sync(ctx context.Context) (models.AdvisorySyncResult, error) {
+	result := models.AdvisorySyncResult{}
+	advisories := []models.Advisory{}
+
+	if s.nvdEnabled {
+		fetched, err := s.fetchNVD(ctx)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		advisories = append(advisories, fetched...)
+	}
+	for _, feedURL := range s.feedURLs {
+		fetched, err := fetchVendorFeed(ctx, s.client, feedURL)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		for _, a := range fetched {
+			if err := ValidateAdvisory(&a); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+				continue
+			}
+			advisories = append(advisories, a)
+		}
+	}
+
+	if err := s.store(ctx, advisories); err != nil {
+		return result, err
+	}
+	result.Fetched = len(advisories)
+	return result, s.match(ctx, &result)
+}
+
+// Import stores validated advisories pushed by a vendor and matches them
+// like synced ones.
+func (s *AdvisoryService) Import(ctx context.Context, req models.AdvisoryImportRequest) (models.AdvisorySyncResult, error) {
+	for i := range req.Advisories {
+		if req.Advisories[i].Source == "" {
+			req.Advisories[i].Source = req.Source
+		}
+	}
+	if err := s.store(ctx, req.Advisories); err != nil {
+		return models.AdvisorySyncResult{}, err
+	}
+	result := models.AdvisorySyncResult{Fetched: len(req.Advisories)}
+	return result, s.match(ctx, &result)
+}
+
+// fetchNVD returns the CVEs modified since the last successful NVD sync.
+func (s *AdvisoryService) fetchNVD(ctx context.Context) ([]models.Advisory, error) {
+	feeds := s.db.GetCollection("advisory_feeds")
+	until := time.Now()
+	since := until.Add(-nvdFirstSync)
+	var state struct {
+		SyncedAt time.Time `bson:"syncedAt"`
+	}
+	if err := feeds.FindOne(ctx, bson.M{"_id": "nvd"}).Decode(&state); err == nil {
+		since = state.SyncedAt
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if until.Sub(since) > nvdMaxWindow {
+		since = until.Add(-nvdMaxWindow)
+	}
+
+	advisories, err := fetchNVD(ctx, s.client, s.nvdAPIKey, since, until)
+	if err != nil {
+		return nil, err
+	}
+	_, err = feeds.UpdateOne(ctx, bson.M{"_id": "nvd"}, bson.M{"$set": bson.M{"syncedAt": until}}, options.Update().SetUpsert(true))
+	return advisories, err
+}
+
+// store upserts advisories by AdvisoryID, keeping their matches and ticket.
+func (s *AdvisoryService) store(ctx context.Context, advisories []models.Advisory) error {
+	now := time.Now()
+	for _, a := range advisories {
+		_, err := s.collection().UpdateOne(ctx, bson.M{"advisoryId": a.AdvisoryID}, bson.M{
+			"$set": bson.M{
+				"source":      a.Source,
+				"title":       a.Title,
+				"description": a.Description,
+				"severity":    a.Severity,
+				"cvssScore":   a.CVSSScore,
+				"affected":    a.Affected,
+				"references":  a.References,
+				"publishedAt": a.PublishedAt,
+				"updatedAt":   now,
+			},
+			"$setOnInsert": bson.M{
+				"affectedAssets": []models.AdvisoryAsset{},
+				"createdAt":      now,
+			},
+		}, options.Update().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inventoryItem is a piece of software on an endpoint agent.
+type inventoryItem struct {
+	agent    models.EndpointAgent
+	software models.InstalledSoftware
+}
+
+// match matches every stored advisory naming inventoried software against
+// the current inventory, then opens tickets for newly affecting advisories
+// and notes newly affected assets on existing tickets.
+func (s *AdvisoryService) match(ctx context.Context, result *models.AdvisorySyncResult) error {
+	cursor, err := s.db.GetCollection("endpoint_agents").Find(ctx, bson.M{"enabled": true})
+	if err != nil {
+		return err
+	}
+	var agents []models.EndpointAgent
+	if err := cursor.All(ctx, &agents); err != nil {
+		return err
+	}
+	items := []inventoryItem{}
+	products := map[string]bool{}
+	for _, agent := range agents {
+		software := agent.Inventory.Software
+		if agent.Inventory.OS != "" {
+			software = append(software, models.InstalledSoftware{Name: agent.Inventory.OS, Version: agent.Inventory.OSVersion})
+		}
+		for _, sw := range software {
+			items = append(items, inventoryItem{agent: agent, software: sw})
+			for _, key := range productKeys(sw.Name) {
+				products[key] = true
+			}
+		}
+	}
+	if len(products) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(products))
+	for key := range products {
+		keys = append(keys, key)
+	}
+
+	cursor, err = s.collection().Find(ctx, bson.M{"affected.product": bson.M{"$in": keys}})
+	if err != nil {
+		return err
+	}
+	var advisories []models.Advisory
+	if err := cursor.All(ctx, &advisories); err != nil {
+		return err
+	}
+	var admin models.User
+	if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"role": models.RoleAdmin}).Decode(&admin); err != nil {
+		return err
+	}
+
+	for _, a := range advisories {
+		assets := advisoryAssets(a, items)
+		if len(assets) > 0 {
+			result.Affecting++
+		}
+		if err := s.update(ctx, a, assets, admin, result); err != nil {
+			log.Printf("Failed to update advisory %s: %v", a.AdvisoryID, err)
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", a.AdvisoryID, err))
+		}
+	}
+	return nil
+}
+
+func (s *AdvisoryService) update(ctx context.Context, a models.Advisory, assets []models.AdvisoryAsset, admin models.User, result *models.AdvisorySyncResult) error {
+	known := map[string]bool{}
+	for _, asset := range a.AffectedAssets {
+		known[asset.AgentID.Hex()+"/"+asset.Software] = true
+	}
+	added := []models.AdvisoryAsset{}
+	for _, asset := range assets {
+		if !known[asset.AgentID.Hex()+"/"+asset.Software] {
+			added = append(added, asset)
+		}
+	}
+
+	now := time.Now()
+	set := bson.M{"affectedAssets": assets, "matchedAt": now}
+	if len(assets) > 0 && severityRank(a.Severity) >= severityRank(s.minSeverity) {
+		if a.TicketID == nil {
+			ticketID, err := s.openTicket(ctx, a, assets, admin)
+			if err != nil {
+				return err
+			}
+			set["ticketId"] = ticketID
+			result.TicketsOpened++
+		} else if len(added) > 0 {
+			if err := s.noteAssets(ctx, *a.TicketID, added, admin); err != nil {
+				return err
+			}
+			result.TicketsNoted++
+		}
+	}
+	_, err := s.collection().UpdateByID(ctx, a.ID, bson.M{"$set": set})
+	return err
+}
+
+func (s *AdvisoryService) openTicket(ctx context.Context, a models.Advisory, assets []models.AdvisoryAsset, admin models.User) (primitive.ObjectID, error) {
+	taxonomy := s.taxonomy.Get(ctx)
+	category := DefaultCategory(taxonomy)
+	if FindCategory(taxonomy, models.CategorySecurity) != nil {
+		category = models.CategorySecurity
+	}
+	priority := severityPriority(a.Severity)
+	if FindPriority(taxonomy, priority) == nil {
+		priority = DefaultPriority(taxonomy)
+	}
+
+	title := a.Title
+	if !strings.Contains(title, a.AdvisoryID) {
+		title = a.AdvisoryID + ": " + title
+	}
+
+	now := time.Now()
+	ticket := models.Ticket{
+		ID:          primitive.NewObjectID(),
+		Title:       "Security advisory " + title,
+		Description: describeAdvisory(a, assets),
+		Category:    category,
+		Priority:    priority,
+		Status:      models.StatusOpen,
+		CreatedBy:   admin.ID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if len(assets) == 1 {
+		ticket.EndpointAgentID = &assets[0].AgentID
+	}
+	seen := map[primitive.ObjectID]bool{}
+	for _, asset := range assets {
+		if asset.ResourceID != nil && !seen[*asset.ResourceID] {
+			seen[*asset.ResourceID] = true
+			ticket.LinkedResourceIDs = append(ticket.LinkedResourceIDs, *asset.ResourceID)
+		}
+	}
+	if _, err := s.db.GetCollection("tickets").InsertOne(ctx, ticket); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return ticket.ID, nil
+}
+
+// noteAssets comments the newly affected assets on an advisory's ticket,
+// reopening it if it was already resolved. Closed tickets are reopened too,
+// outside the usual workflow, since the advisory needs acting on again.
+func (s *AdvisoryService) noteAssets(ctx context.Context, ticketID primitive.ObjectID, added []models.AdvisoryAsset, admin models.User) error {
+	tickets := s.db.GetCollection("tickets")
+	filter := bson.M{
+		"_id":    ticketID,
+		"status": bson.M{"$in": []models.TicketStatus{models.StatusResolved, models.StatusClosed}},
+	}
+	var ticket models.Ticket
+	err := tickets.FindOne(ctx, filter).Decode(&ticket)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	if err == nil {
+		now := time.Now()
+		update := bson.M{"$set": bson.M{"updatedAt": now}}
+		ApplyStatusTransition(update, ticket, models.StatusOpen, now)
+		// Reopening from closed clears the resolution as from resolved
+		if ticket.Status == models.StatusClosed {
+			update["$unset"] = bson.M{"resolvedAt": "", "closedAt": ""}
+		}
+		filter["status"] = ticket.Status
+		if _, err := tickets.UpdateOne(ctx, filter, update); err != nil {
+			return err
+		}
+	}
+	body := fmt.Sprintf("The advisory now also affects %d more asset(s):\n%s", len(added), listAdvisoryAssets(added))
+	_, err = s.comments.Add(ctx, ticketID, admin, body, true)
+	return err
+}
+
+func describeAdvisory(a models.Advisory, assets []models.AdvisoryAsset) string {
+	var desc strings.Builder
+	severity := a.Severity
+	if severity == "" {
+		severity = "unrated"
+	}
+	desc.WriteString(fmt.Sprintf("Advisory %s from %s, severity %s", a.AdvisoryID, a.Source, severity))
+	if a.CVSSScore > 0 {
+		desc.WriteString(fmt.Sprintf(" (CVSS %.1f)", a.CVSSScore))
+	}
+	desc.WriteString(".\n")
+	if a.Description != "" {
+		desc.WriteString("\n" + a.Description + "\n")
+	}
+	desc.WriteString(fmt.Sprintf("\nAffected assets (%d):\n%s", len(assets), listAdvisoryAssets(assets)))
+	if len(a.References) > 0 {
+		desc.WriteString("\nReferences:\n")
+		for i, ref := range a.References {
+			if i == advisoryTicketReferences {
+				break
+			}
+			desc.WriteString("  " + ref + "\n")
+		}
+	}
+	return desc.String()
+}
+
+func listAdvisoryAssets(assets []models.AdvisoryAsset) string {
+	var list strings.Builder
+	for _, asset := range assets {
+		list.WriteString(fmt.Sprintf("  %s: %s %s\n", asset.Name, asset.Software, asset.Version))
+	}
+	return list.String()
+}
+
+// advisoryAssets returns the inventory items running software the advisory
+// affects, one per agent and software.
+func advisoryAssets(a models.Advisory, items []inventoryItem) []models.AdvisoryAsset {
+	assets := []models.AdvisoryAsset{}
+	seen := map[string]bool{}
+	for _, item := range items {
+		key := item.agent.ID.Hex() + "/" + item.software.Name
+		if seen[key] {
+			continue
+		}
+		for _, p := range a.Affected {
+			if softwareAffected(item.software, p) {
+				seen[key] = true
+				assets = append(assets, models.AdvisoryAsset{
+					AgentID:    item.agent.ID,
+					Name:       item.agent.Name,
+					ResourceID: item.agent.ResourceID,
+					Software:   item.software.Name,
+					Version:    item.software.Version,
+				})
+				break
+			}
+		}
+	}
+	return assets
+}
+
+// softwareAffected reports whether installed software is an affected
+// product in an affected version. Software named either like the product
+// ("OpenSSL" for openssl) or vendor and product ("Google Chrome" for
+// google:chrome) matches. Without a reported version it only matches
+// advisories affecting every version.
+func softwareAffected(sw models.InstalledSoftware, p models.AffectedProduct) bool {
+	name := normalizeProduct(sw.Name)
+	product := normalizeProduct(p.Product)
+	vendor := normalizeProduct(p.Vendor)
+	if name != product && (vendor == "" || name != vendor+"_"+product) {
+		return false
+	}
+	if sw.Vendor != "" && vendor != "" && !strings.Contains(normalizeProduct(sw.Vendor), vendor) {
+		return false
+	}
+
+	if p.Version == "" && p.VersionStartIncluding == "" && p.VersionStartExcluding == "" && p.VersionEndIncluding == "" && p.VersionEndExcluding == "" {
+		return true
+	}
+	if sw.Version == "" {
+		return false
+	}
+	if p.Version != "" && compareVersions(sw.Version, p.Version) != 0 {
+		return false
+	}
+	if p.VersionStartIncluding != "" && compareVersions(sw.Version, p.VersionStartIncluding) < 0 {
+		return false
+	}
+	if p.VersionStartExcluding != "" && compareVersions(sw.Version, p.VersionStartExcluding) <= 0 {
+		return false
+	}
+	if p.VersionEndIncluding != "" && compareVersions(sw.Version, p.VersionEndIncluding) > 0 {
+		return false
+	}
+	if p.VersionEndExcluding != "" && compareVersions(sw.Version, p.VersionEndExcluding) >= 0 {
+		return false
+	}
+	return true
+}
+
+// normalizeProduct lowercases a name and joins its words with underscores,
+// the way CPE names products.
+func normalizeProduct(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "_")
+}
+
+// productKeys are the product names software could be listed under: its
+// whole normalized name and each trailing part, so "Google Chrome" is
+// looked up as google_chrome and chrome.
+func productKeys(name string) []string {
+	normalized := normalizeProduct(name)
+	if normalized == "" {
+		return nil
+	}
+	keys := []string{normalized}
+	for i, r := range normalized {
+		if r == '_' {
+			keys = append(keys, normalized[i+1:])
+		}
+	}
+	return keys
+}
+
+// compareVersions compares dotted versions segment by segment, numerically
+// where both segments are numbers; missing segments count as 0.
+func compareVersions(a, b string) int {
+	split := func(v string) []string {
+		return strings.FieldsFunc(v, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	}
+	as, bs := split(a), split(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, errX := strconv.ParseUint(x, 10, 64)
+		yn, errY := strconv.ParseUint(y, 10, 64)
+		switch {
+		case errX == nil && errY == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
+
+// List returns advisories, most recently updated first, filtered by
+// severity and to those affecting assets.
+func (s *AdvisoryService) List(ctx context.Context, severity string, affectedOnly bool, limit int) ([]models.Advisory, error) {
+	filter := bson.M{}
+	if severity != "" {
+		filter["severity"] = severity
+	}
+	if affectedOnly {
+		filter["affectedAssets.0"] = bson.M{"$exists": true}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := s.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	advisories := []models.Advisory{}
+	if err := cursor.All(ctx, &advisories); err != nil {
+		return nil, err
+	}
+	return advisories, nil
+}
+
+func (s *AdvisoryService) Get(ctx context.Context, id primitive.ObjectID) (models.Advisory, error) {
+	var advisory models.Advisory
+	err := s.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&advisory)
+	return advisory, err
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// minAffinityTermLength avoids linking on short, ambiguous identifiers.
+const minAffinityTermLength = 4
+
+// AffinityService links tickets to the monitored resources they mention.
+type AffinityService struct {
+	db          *database.MongoDB
+	deployments *DeploymentService
+}
+
+func NewAffinityService(db *database.MongoDB, deployments *DeploymentService) *AffinityService {
+	return &AffinityService{db: db, deployments: deployments}
+}
+
+// MatchResources returns the monitored resources whose identifier or
+// dimension values appear as whole words in text.
+func (s *AffinityService) MatchResources(ctx context.Context, text string) ([]models.MonitoredResource, error) {
+	cursor, err := s.db.GetCollection("mon_resources").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var resources []models.MonitoredResource
+	if err := cursor.All(ctx, &resources); err != nil {
+		return nil, err
+	}
+
+	matched := []models.MonitoredResource{}
+	for _, r := range resources {
+		terms := []string{r.Identifier}
+		for _, v := range r.Dimensions {
+			terms = append(terms, v)
+		}
+		for _, term := range terms {
+			if mentions(text, term) {
+				matched = append(matched, r)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+func mentions(text, term string) bool {
+	if len(term) < minAffinityTermLength {
+		return false
+	}
+	pattern := `(?i)(^|[^a-z0-9_.\-/])` + regexp.QuoteMeta(term) + `($|[^a-z0-9_\-/])`
+	return regexp.MustCompile(pattern).MatchString(text)
+}
+
+// LinkResources stores the resources a ticket mentions on the ticket.
+func (s *AffinityService) LinkResources(ctx context.Context, ticket models.Ticket) ([]models.MonitoredResource, error) {
+	resources, err := s.MatchResources(ctx, ticket.Title+"\n"+ticket.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []primitive.ObjectID{}
+	for _, r := range resources {
+		ids = append(ids, r.ID)
+	}
+	_, err = s.db.GetCollection("tickets").UpdateByID(ctx, ticket.ID, bson.M{"$set": bson.M{"linkedResourceIds": ids}})
+	return resources, err
+}
+
+// Context returns the ticket's linked resources with their open anomalies,
+// anomalies from the last 24 hours and any anomaly that raised the ticket,
+// and the deployments made shortly before the ticket.
+func (s *AffinityService) Context(ctx context.Context, ticket models.Ticket) (models.MonitoringContext, error) {
+	result := models.MonitoringContext{TicketID: ticket.ID, Anomalies: []models.AnomalyRecord{}}
+
+	resources, err := s.LinkResources(ctx, ticket)
+	if err != nil {
+		return result, err
+	}
+	result.Resources = resources
+
+	ids := []primitive.ObjectID{}
+	for _, r := range resources {
+		ids = append(ids, r.ID)
+	}
+	filter := bson.M{"$or": bson.A{
+		bson.M{"ticketId": ticket.ID},
+		bson.M{"resourceId": bson.M{"$in": ids}, "$or": bson.A{
+			bson.M{"status": models.AnomalyOpen},
+			bson.M{"createdAt": bson.M{"$gte": time.Now().Add(-24 * time.Hour)}},
+		}},
+	}}
+	opts := options.Find().SetSort(bson.D{{"createdAt", -1}}).SetLimit(50)
+	cursor, err := s.db.GetCollection("mon_anomalies").Find(ctx, filter, opts)
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &result.Anomalies); err != nil {
+		return result, err
+	}
+
+	result.Deployments, err = s.deployments.Recent(ctx, ticket.Title+"\n"+ticket.Description, ticket.CreatedAt)
+	return result, err
+}
@@ -0,0 +1,432 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	defaultAgentSteps = 6
+	maxAgentSteps     = 10
+	agentToolTimeout  = 5 * time.Second
+)
+
+const agentSystemPrompt = `You are an IT diagnostics agent. You investigate support tickets by calling tools one at a time and reasoning about their output.
+On every turn respond with a single JSON object and nothing else, either
+{"thought": "why you are calling the tool", "tool": "<tool name>", "input": {"<input>": "<value>"}}
+to call one of the listed tools, or
+{"thought": "what you concluded", "final": "<diagnostic report for the technician>"}
+when you have enough information. Only call listed tools. Keep the final report under 200 words with findings and recommended next steps.` + untrustedContentPolicy
+
+// agentTool is a whitelisted tool together with its implementation.
+type agentTool struct {
+	models.AgentTool
+	run func(ctx context.Context, ticket models.Ticket, input map[string]string) (string, error)
+}
+
+// AgentService runs a ReAct-style loop in which the LLM investigates a ticket
+// using a fixed set of safe tools.
+type AgentService struct {
+	db         *database.MongoDB
+	llm        *LLMService
+	guardrails *GuardrailService
+	cw         *CloudWatchService // nil when monitoring is disabled
+	tools      []agentTool
+}
+
+func NewAgentService(db *database.MongoDB, llm *LLMService, guardrails *GuardrailService, cw *CloudWatchService) *AgentService {
+	s := &AgentService{db: db, llm: llm, guardrails: guardrails, cw: cw}
+	s.tools = []agentTool{
+		{models.AgentTool{Name: "dns_lookup", Description: "Resolve a hostname to its IP addresses", Inputs: []string{"host"}}, s.dnsLookup},
+		{models.AgentTool{Name: "ping", Description: "Check TCP reachability of a host (three connection attempts)", Inputs: []string{"host", "port (default 443)"}}, s.ping},
+		{models.AgentTool{Name: "cloudwatch_metric", Description: "Summarize a CloudWatch metric of a monitored resource", Inputs: []string{"resource (identifier)", "metric", "stat (default Average)", "minutes (default 60)"}}, s.cloudWatchMetric},
+		{models.AgentTool{Name: "ticket_history", Description: "List earlier tickets from the same requester and resolved tickets in the same category", Inputs: []string{}}, s.ticketHistory},
+	}
+	return s
+}
+
+// Tools lists the tools the agent may call.
+func (s *AgentService) Tools() []models.AgentTool {
+	tools := make([]models.AgentTool, 0, len(s.tools))
+	for _, t := range s.tools {
+		tools = append(tools, t.AgentTool)
+	}
+	return tools
+}
+
+// Diagnose investigates a ticket, stores the run with its audit trail and
+// appends the resulting report to the ticket.
+func (s *AgentService) Diagnose(ctx context.Context, ticket models.Ticket, req models.AgentRunRequest, startedBy primitive.ObjectID) (models.AgentRun, error) {
+	maxSteps := req.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultAgentSteps
+	}
+	if maxSteps > maxAgentSteps {
+		maxSteps = maxAgentSteps
+	}
+
+	run := models.AgentRun{
+		ID:        primitive.NewObjectID(),
+		TicketID:  ticket.ID,
+		Goal:      req.Goal,
+		Steps:     []models.AgentStep{},
+		StartedBy: startedBy,
+		StartedAt: time.Now(),
+	}
+
+	if err := s.react(ctx, ticket, req.Goal, maxSteps, &run); err != nil {
+		if err != ErrLLMUnavailable {
+			run.Error = err.Error()
+		}
+		run.Fallback = true
+		if len(run.Steps) == 0 {
+			s.runFixedPlan(ctx, ticket, &run)
+		}
+		run.Status = models.AgentRunCompleted
+		run.Report = summarizeAgentSteps(run.Steps)
+	}
+	run.CompletedAt = time.Now()
+
+	if _, err := s.db.GetCollection("agent_runs").InsertOne(ctx, run); err != nil {
+		return run, err
+	}
+
+	report := models.DiagnosticReport{
+		RunID:     run.ID,
+		Report:    run.Report,
+		CreatedBy: startedBy,
+		CreatedAt: run.CompletedAt,
+	}
+	_, err := s.db.GetCollection("tickets").UpdateByID(ctx, ticket.ID, bson.M{
+		"$push": bson.M{"diagnostics": report},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	})
+	return run, err
+}
+
+// ListRuns returns the agent runs for a ticket, newest first.
+func (s *AgentService) ListRuns(ctx context.Context, ticketID primitive.ObjectID) ([]models.AgentRun, error) {
+	cursor, err := s.db.GetCollection("agent_runs").Find(ctx, bson.M{"ticketId": ticketID}, options.Find().SetSort(bson.D{{"startedAt", -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runs := []models.AgentRun{}
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// agentAction is one turn of the model: either a tool call or the final report.
+type agentAction struct {
+	Thought string            `json:"thought"`
+	Tool    string            `json:"tool"`
+	Input   map[string]string `json:"input"`
+	Final   string            `json:"final"`
+}
+
+func (s *AgentService) react(ctx context.Context, ticket models.Ticket, goal string, maxSteps int, run *models.AgentRun) error {
+	var base strings.Builder
+	base.WriteString("Available tools:\n")
+	for _, t := range s.tools {
+		base.WriteString(fmt.Sprintf("- %s: %s. Inputs: %s\n", t.Name, t.Description, strings.Join(t.Inputs, ", ")))
+	}
+	base.WriteString("\nTicket:\n")
+	base.WriteString(fmt.Sprintf("Category: %s %s\nPriority: %s\n", ticket.Category, ticket.Subcategory, ticket.Priority))
+	base.WriteString("Title: " + s.guardrails.Wrap(ctx, "ticket", ticket.ID.Hex(), "title", ticket.Title) + "\n")
+	base.WriteString("Description: " + s.guardrails.Wrap(ctx, "ticket", ticket.ID.Hex(), "description", ticket.Description) + "\n")
+	if goal != "" {
+		base.WriteString("\nInvestigation goal: " + goal + "\n")
+	}
+
+	var transcript strings.Builder
+	for i := 0; i < maxSteps; i++ {
+		prompt := base.String()
+		if transcript.Len() > 0 {
+			prompt += "\nSteps so far:\n" + transcript.String()
+		}
+		prompt += "\nRespond with your next JSON action."
+
+		content, err := s.llm.Complete(ctx, CompletionRequest{
+			System:   agentSystemPrompt,
+			Prompt:   prompt,
+			Endpoint: EndpointAgent,
+		})
+		if err != nil {
+			return err
+		}
+
+		var action agentAction
+		if err := json.Unmarshal([]byte(ExtractJSON(content)), &action); err != nil {
+			return fmt.Errorf("failed to parse agent action: %v", err)
+		}
+		if action.Final != "" {
+			run.Status = models.AgentRunCompleted
+			run.Report = strings.TrimSpace(action.Final)
+			return nil
+		}
+
+		step := s.callTool(ctx, ticket, len(run.Steps)+1, action.Thought, action.Tool, action.Input)
+		run.Steps = append(run.Steps, step)
+
+		observation := step.Output
+		if step.Error != "" {
+			observation = "error: " + step.Error
+		}
+		transcript.WriteString(fmt.Sprintf("%d. thought: %s\n   tool: %s %v\n   observation: %s\n",
+			step.Index, step.Thought, step.Tool, step.Input,
+			s.guardrails.Wrap(ctx, "agent", run.ID.Hex(), step.Tool, observation)))
+	}
+
+	run.Status = models.AgentRunMaxSteps
+	run.Report = summarizeAgentSteps(run.Steps)
+	return nil
+}
+
+// callTool runs a whitelisted tool and records the call as an audit step.
+func (s *AgentService) callTool(ctx context.Context, ticket models.Ticket, index int, thought, name string, input map[string]string) models.AgentStep {
+	if input == nil {
+		input = map[string]string{}
+	}
+	step := models.AgentStep{Index: index, Thought: thought, Tool: name, Input: input, At: time.Now()}
+
+	var tool *agentTool
+	for i := range s.tools {
+		if s.tools[i].Name == name {
+			tool = &s.tools[i]
+			break
+		}
+	}
+	if tool == nil {
+		step.Error = fmt.Sprintf("unknown tool %q", name)
+		return step
+	}
+
+	toolCtx, cancel := context.WithTimeout(ctx, agentToolTimeout)
+	defer cancel()
+
+	start := time.Now()
+	output, err := tool.run(toolCtx, ticket, input)
+	step.DurationMs = time.Since(start).Milliseconds()
+	step.Output = output
+	if err != nil {
+		step.Error = err.Error()
+	}
+	log.Printf("Agent tool call ticket=%s tool=%s input=%v error=%q", ticket.ID.Hex(), name, input, step.Error)
+	return step
+}
+
+var agentHostPattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b|\b(?:[a-zA-Z0-9-]+\.)+[a-zA-Z]{2,}\b`)
+
+// runFixedPlan is used without an LLM: look at ticket history, then resolve
+// and probe up to two hosts mentioned in the ticket.
+func (s *AgentService) runFixedPlan(ctx context.Context, ticket models.Ticket, run *models.AgentRun) {
+	run.Steps = append(run.Steps, s.callTool(ctx, ticket, 1, "Check for related tickets", "ticket_history", nil))
+
+	seen := map[string]bool{}
+	for _, host := range agentHostPattern.FindAllString(ticket.Title+" "+ticket.Description, -1) {
+		host = strings.ToLower(host)
+		if seen[host] || len(seen) >= 2 {
+			continue
+		}
+		seen[host] = true
+		input := map[string]string{"host": host}
+		run.Steps = append(run.Steps, s.callTool(ctx, ticket, len(run.Steps)+1, "Resolve host mentioned in ticket", "dns_lookup", input))
+		run.Steps = append(run.Steps, s.callTool(ctx, ticket, len(run.Steps)+1, "Check reachability of host mentioned in ticket", "ping", input))
+	}
+}
+
+func summarizeAgentSteps(steps []models.AgentStep) string {
+	var b strings.Builder
+	b.WriteString("Automated diagnostic checks:\n")
+	for _, step := range steps {
+		result := step.Output
+		if step.Error != "" {
+			result = "failed: " + step.Error
+		}
+		result = strings.ReplaceAll(result, "\n", "\n  ")
+		b.WriteString(fmt.Sprintf("- %s %v: %s\n", step.Tool, step.Input, result))
+	}
+	if len(steps) == 0 {
+		b.WriteString("- no checks were run\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// validateAgentHost resolves host and refuses loopback, link-local (including
+// the cloud metadata endpoint) and other non-routable addresses so the agent
+// cannot be used to probe the server itself.
+func validateAgentHost(ctx context.Context, host string) (net.IP, error) {
+	if host == "" || len(host) > 253 || strings.ContainsAny(host, " /:@") {
+		return nil, fmt.Errorf("invalid host %q", host)
+	}
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+			return nil, fmt.Errorf("host %s resolves to disallowed address %s", host, ip)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("host %s has no addresses", host)
+	}
+	return ips[0], nil
+}
+
+func (s *AgentService) dnsLookup(ctx context.Context, _ models.Ticket, input map[string]string) (string, error) {
+	host := strings.TrimSpace(input["host"])
+	if host == "" || len(host) > 253 || strings.ContainsAny(host, " /:@") {
+		return "", fmt.Errorf("invalid host %q", host)
+	}
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	result := fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", "))
+	if cname, err := net.DefaultResolver.LookupCNAME(ctx, host); err == nil && strings.TrimSuffix(cname, ".") != host {
+		result += fmt.Sprintf(" (CNAME %s)", cname)
+	}
+	return result, nil
+}
+
+// ping is a TCP connect probe. It dials the already validated address rather
+// than the hostname and never shells out.
+func (s *AgentService) ping(ctx context.Context, _ models.Ticket, input map[string]string) (string, error) {
+	host := strings.TrimSpace(input["host"])
+	ip, err := validateAgentHost(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	port := input["port"]
+	if port == "" {
+		port = "443"
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return "", fmt.Errorf("invalid port %q", port)
+	}
+
+	addr := net.JoinHostPort(ip.String(), port)
+	dialer := net.Dialer{Timeout: time.Second}
+	var lines []string
+	succeeded := 0
+	for i := 1; i <= 3; i++ {
+		start := time.Now()
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("attempt %d: %v", i, err))
+			continue
+		}
+		conn.Close()
+		succeeded++
+		lines = append(lines, fmt.Sprintf("attempt %d: connected in %dms", i, time.Since(start).Milliseconds()))
+	}
+	return fmt.Sprintf("TCP probe %s (%s) port %s: %d/3 succeeded\n%s", host, ip, port, succeeded, strings.Join(lines, "\n")), nil
+}
+
+func (s *AgentService) cloudWatchMetric(ctx context.Context, _ models.Ticket, input map[string]string) (string, error) {
+	if s.cw == nil {
+		return "", fmt.Errorf("CloudWatch monitoring is not enabled")
+	}
+	if input["resource"] == "" || input["metric"] == "" {
+		return "", fmt.Errorf("resource and metric are required")
+	}
+
+	var resource models.MonitoredResource
+	if err := s.db.GetCollection("mon_resources").FindOne(ctx, bson.M{"identifier": input["resource"]}).Decode(&resource); err != nil {
+		return "", fmt.Errorf("resource %s is not monitored", input["resource"])
+	}
+
+	stat := input["stat"]
+	if stat == "" {
+		stat = "Average"
+	}
+	minutes := 60
+	if m, err := strconv.Atoi(input["minutes"]); err == nil && m > 0 && m <= 1440 {
+		minutes = m
+	}
+
+	end := time.Now().UTC()
+	series, err := s.cw.GetMetricSeries(ctx, MetricQueryInput{
+		Namespace:  resource.Namespace,
+		MetricName: input["metric"],
+		Dimensions: resource.Dimensions,
+		Stat:       stat,
+		Period:     300,
+		StartTime:  end.Add(-time.Duration(minutes) * time.Minute),
+		EndTime:    end,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(series.Values) == 0 {
+		return fmt.Sprintf("No datapoints for %s %s in the last %d minutes", resource.Identifier, input["metric"], minutes), nil
+	}
+
+	min, max, sum := math.Inf(1), math.Inf(-1), 0.0
+	for _, v := range series.Values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+		sum += v
+	}
+	return fmt.Sprintf("%s %s (%s, last %d minutes): %d points, min %.2f, max %.2f, avg %.2f, latest %.2f",
+		resource.Identifier, input["metric"], stat, minutes, len(series.Values),
+		min, max, sum/float64(len(series.Values)), series.Values[len(series.Values)-1]), nil
+}
+
+func (s *AgentService) ticketHistory(ctx context.Context, ticket models.Ticket, _ map[string]string) (string, error) {
+	coll := s.db.GetCollection("tickets")
+	opts := options.Find().SetSort(bson.D{{"createdAt", -1}}).SetLimit(5)
+
+	var b strings.Builder
+	sections := []struct {
+		title  string
+		filter bson.M
+	}{
+		{"Earlier tickets from the same requester", bson.M{"createdBy": ticket.CreatedBy, "_id": bson.M{"$ne": ticket.ID}}},
+		{"Resolved tickets in the same category", bson.M{
+			"category": ticket.Category,
+			"status":   bson.M{"$in": []models.TicketStatus{models.StatusResolved, models.StatusClosed}},
+			"_id":      bson.M{"$ne": ticket.ID},
+		}},
+	}
+	for _, section := range sections {
+		cursor, err := coll.Find(ctx, section.filter, opts)
+		if err != nil {
+			return "", err
+		}
+		var tickets []models.Ticket
+		err = cursor.All(ctx, &tickets)
+		cursor.Close(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(section.title + ":\n")
+		if len(tickets) == 0 {
+			b.WriteString("- none\n")
+		}
+		for _, t := range tickets {
+			b.WriteString(fmt.Sprintf("- [%s] %s (%s, %s)\n", t.Status, t.Title, t.Category, t.CreatedAt.Format("2006-01-02")))
+		}
+	}
+	return strings.TrimSpace(b.String()), nil
+}
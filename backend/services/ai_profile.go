@@ -0,0 +1,48 @@
+package services
+
+import (
+	"strings"
+
+	"intelliops-ai-copilot/config"
+)
+
+const (
+	// maxProfileValues and maxProfileValueLength keep the organization
+	// profile to a few lines of every prompt.
+	maxProfileValues      = 20
+	maxProfileValueLength = 200
+)
+
+// aiTones are the answer tones admins can choose and the instruction each
+// adds to prompts. The empty tone adds none.
+var aiTones = map[string]string{
+	"":         "",
+	"concise":  "Keep answers short and to the point: brief descriptions and only the essential steps.",
+	"detailed": "Be thorough: explain why each step helps and how to verify it worked.",
+	"friendly": "Use a friendly, reassuring tone in plain language a non-technical user can follow.",
+	"formal":   "Use a formal, professional tone.",
+}
+
+// ProfilePrompt renders the organization profile and answer tone in cfg
+// as the opening of a prompt, or "" when neither is set.
+func ProfilePrompt(cfg *config.Config) string {
+	var b strings.Builder
+	line := func(label, value string) {
+		if value != "" {
+			b.WriteString("- " + label + ": " + value + "\n")
+		}
+	}
+	line("Company", cfg.OrgCompanyName)
+	line("Supported operating systems", strings.Join(cfg.OrgSupportedOS, ", "))
+	line("Standard tools", strings.Join(cfg.OrgStandardTools, ", "))
+	line("Escalation contacts", strings.Join(cfg.OrgEscalationContacts, ", "))
+
+	var prompt string
+	if b.Len() > 0 {
+		prompt = "Organization Profile (refer to these systems and contacts rather than generic alternatives):\n" + b.String() + "\n"
+	}
+	if tone := aiTones[cfg.AITone]; tone != "" {
+		prompt += "Answer Tone: " + tone + "\n\n"
+	}
+	return prompt
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const alertSourceAlertmanager = "alertmanager"
+
+// Labels checked, in order, for the identifier of a monitored resource.
+var alertResourceLabels = []string{"resource", "instance_id", "instance", "service", "load_balancer", "job"}
+
+// Keywords in the alert name or summary that suggest a ticket category when
+// the alert has no category label.
+var alertCategoryKeywords = []struct {
+	category models.TicketCategory
+	keywords []string
+}{
+	{models.CategorySecurity, []string{"security", "auth", "cert", "tls", "intrusion", "malware"}},
+	{models.CategoryNetwork, []string{"network", "dns", "unreachable", "packet", "vpn", "latency"}},
+	{models.CategoryHardware, []string{"disk", "hardware", "temperature", "fan", "power"}},
+	{models.CategoryPerformance, []string{"cpu", "memory", "load", "slow", "saturation", "throttl"}},
+}
+
+// AlertIngestService turns Alertmanager alerts into anomalies and tickets
+// through the same pipeline as CloudWatch detections.
+type AlertIngestService struct {
+	db       *database.MongoDB
+	taxonomy *TaxonomyService
+	cfg      *config.Config
+	routes   *MonitorRouteService
+	// maintenance suppresses tickets and routing for alerts on resources
+	// under maintenance.
+	maintenance *MaintenanceService
+}
+
+func NewAlertIngestService(db *database.MongoDB, taxonomy *TaxonomyService, cfg *config.Config, routes *MonitorRouteService) *AlertIngestService {
+	return &AlertIngestService{db: db, taxonomy: taxonomy, cfg: cfg, routes: routes, maintenance: NewMaintenanceService(db)}
+}
+
+// inboundAlert is an external alert normalized from any source.
+type inboundAlert struct {
+	Name        string
+	Title       string
+	Description string
+	Severity    string
+	Category    string
+	Resources   []string
+	Labels      map[string]string
+	DedupKey    string
+	Resolved    bool
+	StartsAt    time.Time
+}
+
+// IngestAlertmanager records firing alerts as open anomalies, skipping ones
+// already open for the same alert, and closes anomalies of resolved alerts.
+func (s *AlertIngestService) IngestAlertmanager(ctx context.Context, payload models.AlertmanagerWebhook) (models.AlertIngestResult, error) {
+	alerts := make([]inboundAlert, 0, len(payload.Alerts))
+	for _, alert := range payload.Alerts {
+		labels := mergeLabels(payload.CommonLabels, alert.Labels)
+
+		var desc strings.Builder
+		if summary := alert.Annotations["summary"]; summary != "" {
+			desc.WriteString(summary + "\n")
+		}
+		if d := alert.Annotations["description"]; d != "" {
+			desc.WriteString(d + "\n")
+		}
+		if alert.GeneratorURL != "" {
+			desc.WriteString("Source: " + alert.GeneratorURL + "\n")
+		}
+
+		resources := []string{}
+		for _, key := range alertResourceLabels {
+			if value := labels[key]; value != "" {
+				resources = append(resources, value)
+			}
+		}
+
+		title := fmt.Sprintf("Alert firing: %s", labels["alertname"])
+		if labels["instance"] != "" {
+			title += " on " + labels["instance"]
+		}
+
+		alerts = append(alerts, inboundAlert{
+			Name:        labels["alertname"],
+			Title:       title,
+			Description: desc.String(),
+			Severity:    alertSeverity(labels["severity"]),
+			Category:    labels["category"],
+			Resources:   resources,
+			Labels:      labels,
+			DedupKey:    alertDedupKey(alert, labels),
+			Resolved:    alert.Status == "resolved",
+			StartsAt:    alert.StartsAt,
+		})
+	}
+	return s.ingest(ctx, alertSourceAlertmanager, alerts)
+}
+
+func (s *AlertIngestService) ingest(ctx context.Context, source string, alerts []inboundAlert) (models.AlertIngestResult, error) {
+	result := models.AlertIngestResult{Received: len(alerts)}
+	anomalies := s.db.GetCollection("mon_anomalies")
+
+	for _, alert := range alerts {
+		if alert.Resolved {
+			res, err := anomalies.UpdateMany(ctx,
+				bson.M{"dedupKey": alert.DedupKey, "status": models.AnomalyOpen},
+				bson.M{"$set": bson.M{"status": models.AnomalyClosed, "closedAt": time.Now()}})
+			if err != nil {
+				return result, err
+			}
+			result.Resolved += int(res.ModifiedCount)
+			continue
+		}
+
+		count, err := anomalies.CountDocuments(ctx, bson.M{"dedupKey": alert.DedupKey, "status": models.AnomalyOpen})
+		if err != nil {
+			return result, err
+		}
+		if count > 0 {
+			result.Duplicates++
+			continue
+		}
+
+		resource := s.matchResource(ctx, alert.Resources)
+		timestamp := alert.StartsAt
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		anomaly := models.AnomalyRecord{
+			ID:         primitive.NewObjectID(),
+			MetricName: alert.Name,
+			Timestamp:  timestamp,
+			Severity:   alert.Severity,
+			DedupKey:   alert.DedupKey,
+			Status:     models.AnomalyOpen,
+			Source:     source,
+			AlertName:  alert.Name,
+			Labels:     alert.Labels,
+			CreatedAt:  time.Now(),
+		}
+		if resource != nil {
+			anomaly.ResourceID = resource.ID
+		}
+
+		window, err := s.maintenance.Covering(ctx, resource, alert.Labels, timestamp)
+		if err != nil {
+			log.Printf("maintenance window lookup for %s alert %s failed: %v", source, alert.Name, err)
+		} else if window != nil {
+			anomaly.MaintenanceWindowID = &window.ID
+			if _, err := anomalies.InsertOne(ctx, anomaly); err != nil {
+				return result, err
+			}
+			result.Created++
+			result.Suppressed++
+			continue
+		}
+
+		if s.cfg.AnomalyCreateTickets {
+			ticketID, err := s.createTicket(ctx, alert, anomaly, resource)
+			if err != nil {
+				log.Printf("ticket creation for %s alert %s failed: %v", source, alert.Name, err)
+			} else {
+				anomaly.TicketID = ticketID
+				result.TicketsCreated++
+			}
+		}
+		s.routes.Route(ctx, resource, &anomaly, alert.Title)
+
+		if _, err := anomalies.InsertOne(ctx, anomaly); err != nil {
+			return result, err
+		}
+		result.Created++
+	}
+	return result, nil
+}
+
+func (s *AlertIngestService) createTicket(ctx context.Context, alert inboundAlert, anomaly models.AnomalyRecord, resource *models.MonitoredResource) (*primitive.ObjectID, error) {
+	var desc strings.Builder
+	desc.WriteString(alert.Description)
+	desc.WriteString(fmt.Sprintf("\nSeverity: %s\nStarted: %s\n", anomaly.Severity, anomaly.Timestamp.Format(time.RFC3339)))
+	if len(alert.Labels) > 0 {
+		desc.WriteString("Labels:\n")
+		for _, k := range sortedKeys(alert.Labels) {
+			desc.WriteString(fmt.Sprintf("  %s=%s\n", k, alert.Labels[k]))
+		}
+	}
+
+	var resourceID *primitive.ObjectID
+	if resource != nil {
+		resourceID = &resource.ID
+	}
+	category := s.alertCategory(ctx, alert, resource != nil)
+	return insertAnomalyTicket(ctx, s.db, alert.Title, desc.String(), category, severityPriority(anomaly.Severity), resourceID)
+}
+
+// matchResource finds the monitored resource with one of the given
+// identifiers. host:port values are also tried without the port.
+func (s *AlertIngestService) matchResource(ctx context.Context, identifiers []string) *models.MonitoredResource {
+	candidates := []string{}
+	for _, value := range identifiers {
+		candidates = append(candidates, value)
+		if host, _, err := net.SplitHostPort(value); err == nil {
+			candidates = append(candidates, host)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var resource models.MonitoredResource
+	if err := s.db.GetCollection("mon_resources").FindOne(ctx, bson.M{"identifier": bson.M{"$in": candidates}}).Decode(&resource); err != nil {
+		return nil
+	}
+	return &resource
+}
+
+// alertCategory uses the alert's category when it names a taxonomy
+// category, then keywords in the alert name and title. Alerts on a
+// monitored resource default to Performance Issue, others to the taxonomy
+// default.
+func (s *AlertIngestService) alertCategory(ctx context.Context, alert inboundAlert, onResource bool) models.TicketCategory {
+	taxonomy := s.taxonomy.Get(ctx)
+	if c := models.TicketCategory(alert.Category); c != "" && FindCategory(taxonomy, c) != nil {
+		return c
+	}
+
+	text := strings.ToLower(alert.Name + " " + alert.Title)
+	for _, rule := range alertCategoryKeywords {
+		for _, kw := range rule.keywords {
+			if strings.Contains(text, kw) {
+				return rule.category
+			}
+		}
+	}
+	if onResource {
+		return models.CategoryPerformance
+	}
+	return DefaultCategory(taxonomy)
+}
+
+// alertSeverity maps common Alertmanager severity labels onto anomaly
+// severities.
+func alertSeverity(label string) string {
+	switch strings.ToLower(label) {
+	case "critical", "page", "emergency", "fatal":
+		return "critical"
+	case "high", "error", "major":
+		return "high"
+	case "low", "info", "informational", "none":
+		return "low"
+	}
+	return "medium"
+}
+
+// alertDedupKey identifies an alert across repeated notifications, using
+// Alertmanager's fingerprint when present.
+func alertDedupKey(alert models.AlertmanagerAlert, labels map[string]string) string {
+	if alert.Fingerprint != "" {
+		return alertSourceAlertmanager + ":" + alert.Fingerprint
+	}
+	h := sha1.New()
+	for _, k := range sortedKeys(labels) {
+		h.Write([]byte(k + "=" + labels[k] + "\n"))
+	}
+	return alertSourceAlertmanager + ":" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func mergeLabels(common, own map[string]string) map[string]string {
+	merged := map[string]string{}
+	for k, v := range common {
+		merged[k] = v
+	}
+	for k, v := range own {
+		merged[k] = v
+	}
+	return merged
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var alertSourceSlug = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+var alertSeverities = map[string]bool{"critical": true, "high": true, "medium": true, "low": true}
+
+// AlertSourceService manages inbound alert webhooks and maps their payloads
+// onto the alert ingest pipeline.
+type AlertSourceService struct {
+	db     *database.MongoDB
+	ingest *AlertIngestService
+}
+
+func NewAlertSourceService(db *database.MongoDB, ingest *AlertIngestService) *AlertSourceService {
+	return &AlertSourceService{db: db, ingest: ingest}
+}
+
+// ValidateAlertSource checks the slug and every path in the mapping.
+func ValidateAlertSource(req models.AlertSourceRequest) error {
+	if !alertSourceSlug.MatchString(req.Slug) {
+		return fmt.Errorf("slug must be 2-63 lowercase letters, digits or dashes")
+	}
+	m := req.Mapping
+	if strings.TrimSpace(m.Title) == "" {
+		return fmt.Errorf("mapping.title is required")
+	}
+	if m.AlertsPath != "" {
+		if err := ValidateJSONPath(m.AlertsPath); err != nil {
+			return fmt.Errorf("mapping.alertsPath: %v", err)
+		}
+	}
+	fields := map[string]string{
+		"title": m.Title, "description": m.Description, "severity": m.Severity, "resource": m.Resource,
+		"category": m.Category, "dedupKey": m.DedupKey, "status": m.Status,
+	}
+	for name, tmpl := range fields {
+		if err := ValidateTemplate(tmpl); err != nil {
+			return fmt.Errorf("mapping.%s: %v", name, err)
+		}
+	}
+	for from, to := range m.SeverityMap {
+		if !alertSeverities[to] {
+			return fmt.Errorf("mapping.severityMap[%s] must be critical, high, medium or low", from)
+		}
+	}
+	return nil
+}
+
+func newAlertSourceToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Create stores a new source with a generated token, which is only returned
+// here and by RotateToken.
+func (s *AlertSourceService) Create(ctx context.Context, req models.AlertSourceRequest, createdBy primitive.ObjectID) (models.AlertSource, error) {
+	token, err := newAlertSourceToken()
+	if err != nil {
+		return models.AlertSource{}, err
+	}
+	source := models.AlertSource{
+		ID:        primitive.NewObjectID(),
+		Name:      req.Name,
+		Slug:      req.Slug,
+		Token:     token,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		Mapping:   req.Mapping,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if _, err := s.db.GetCollection("alert_sources").InsertOne(ctx, source); err != nil {
+		return models.AlertSource{}, err
+	}
+	return source, nil
+}
+
+func (s *AlertSourceService) List(ctx context.Context) ([]models.AlertSource, error) {
+	cursor, err := s.db.GetCollection("alert_sources").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{"name", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sources := []models.AlertSource{}
+	if err := cursor.All(ctx, &sources); err != nil {
+		return nil, err
+	}
+	for i := range sources {
+		sources[i].Token = ""
+	}
+	return sources, nil
+}
+
+func (s *AlertSourceService) Get(ctx context.Context, id primitive.ObjectID) (models.AlertSource, error) {
+	var source models.AlertSource
+	err := s.db.GetCollection("alert_sources").FindOne(ctx, bson.M{"_id": id}).Decode(&source)
+	return source, err
+}
+
+func (s *AlertSourceService) GetBySlug(ctx context.Context, slug string) (models.AlertSource, error) {
+	var source models.AlertSource
+	err := s.db.GetCollection("alert_sources").FindOne(ctx, bson.M{"slug": slug}).Decode(&source)
+	return source, err
+}
+
+func (s *AlertSourceService) Update(ctx context.Context, id primitive.ObjectID, req models.AlertSourceRequest) (models.AlertSource, error) {
+	set := bson.M{"name": req.Name, "slug": req.Slug, "mapping": req.Mapping, "updatedAt": time.Now()}
+	if req.Enabled != nil {
+		set["enabled"] = *req.Enabled
+	}
+	var source models.AlertSource
+	err := s.db.GetCollection("alert_sources").FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&source)
+	source.Token = ""
+	return source, err
+}
+
+func (s *AlertSourceService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.db.GetCollection("alert_sources").DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// RotateToken replaces a source's token, invalidating the old one.
+func (s *AlertSourceService) RotateToken(ctx context.Context, id primitive.ObjectID) (models.AlertSource, error) {
+	token, err := newAlertSourceToken()
+	if err != nil {
+		return models.AlertSource{}, err
+	}
+	var source models.AlertSource
+	err = s.db.GetCollection("alert_sources").FindOneAndUpdate(ctx, bson.M{"_id": id},
+		bson.M{"$set": bson.M{"token": token, "updatedAt": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&source)
+	return source, err
+}
+
+// Map extracts alerts from a payload without ingesting them.
+func (s *AlertSourceService) Map(source models.AlertSource, payload interface{}) ([]models.MappedAlert, error) {
+	items, err := alertItems(source.Mapping, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]models.MappedAlert, 0, len(items))
+	for _, item := range items {
+		alert, err := mapAlert(source, item)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+// Ingest maps a payload and runs the alerts through the ingest pipeline.
+// The raw event is attached to the ticket description.
+func (s *AlertSourceService) Ingest(ctx context.Context, source models.AlertSource, payload interface{}) (models.AlertIngestResult, error) {
+	items, err := alertItems(source.Mapping, payload)
+	if err != nil {
+		return models.AlertIngestResult{}, err
+	}
+
+	now := time.Now()
+	alerts := make([]inboundAlert, 0, len(items))
+	for _, item := range items {
+		m, err := mapAlert(source, item)
+		if err != nil {
+			return models.AlertIngestResult{}, err
+		}
+
+		description := m.Description
+		if data, err := json.MarshalIndent(item, "", "  "); err == nil {
+			description += "\n\nPayload:\n" + string(data) + "\n"
+		}
+		alert := inboundAlert{
+			Name:        m.Title,
+			Title:       m.Title,
+			Description: description,
+			Severity:    m.Severity,
+			Category:    m.Category,
+			DedupKey:    m.DedupKey,
+			Resolved:    m.Resolved,
+			StartsAt:    now,
+		}
+		if m.Resource != "" {
+			alert.Resources = []string{m.Resource}
+			alert.Labels = map[string]string{"resource": m.Resource}
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if _, err := s.db.GetCollection("alert_sources").UpdateByID(ctx, source.ID, bson.M{"$set": bson.M{"lastReceivedAt": now}}); err != nil {
+		return models.AlertIngestResult{}, err
+	}
+	return s.ingest.ingest(ctx, source.Slug, alerts)
+}
+
+func alertItems(m models.AlertMapping, payload interface{}) ([]interface{}, error) {
+	if m.AlertsPath == "" {
+		return []interface{}{payload}, nil
+	}
+	value, _ := EvalJSONPath(payload, m.AlertsPath)
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("alertsPath %s does not point at an array", m.AlertsPath)
+	}
+	return list, nil
+}
+
+func mapAlert(source models.AlertSource, item interface{}) (models.MappedAlert, error) {
+	m := source.Mapping
+	alert := models.MappedAlert{
+		Title:       strings.TrimSpace(RenderTemplate(m.Title, item)),
+		Description: RenderTemplate(m.Description, item),
+		Resource:    RenderTemplate(m.Resource, item),
+		Category:    RenderTemplate(m.Category, item),
+		DedupKey:    RenderTemplate(m.DedupKey, item),
+	}
+	if alert.Title == "" {
+		return alert, fmt.Errorf("mapping produced an empty title")
+	}
+
+	raw := RenderTemplate(m.Severity, item)
+	if mapped, ok := m.SeverityMap[raw]; ok {
+		alert.Severity = mapped
+	} else {
+		alert.Severity = alertSeverity(raw)
+	}
+
+	if m.Status != "" {
+		status := RenderTemplate(m.Status, item)
+		for _, v := range m.ResolvedValues {
+			if strings.EqualFold(status, v) {
+				alert.Resolved = true
+			}
+		}
+	}
+
+	// Without a dedup template, alerts with the same title and resource are
+	// treated as the same alert
+	if alert.DedupKey == "" {
+		h := sha1.New()
+		h.Write([]byte(alert.Title + "\n" + alert.Resource))
+		alert.DedupKey = hex.EncodeToString(h.Sum(nil))[:16]
+	}
+	alert.DedupKey = source.Slug + ":" + alert.DedupKey
+	return alert, nil
+}
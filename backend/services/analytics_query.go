@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/models"
+)
+
+// Guardrails of the analytics query DSL, keeping ad hoc charts cheap.
+const (
+	maxAnalyticsDimensions   = 3
+	maxAnalyticsMeasures     = 5
+	maxAnalyticsFilters      = 10
+	maxAnalyticsFilterValues = 50
+	defaultAnalyticsRows     = 100
+	maxAnalyticsRows         = 1000
+	maxAnalyticsRange        = 366 * 24 * time.Hour
+	analyticsQueryTimeout    = 10 * time.Second
+)
+
+// ErrInvalidAnalyticsQuery is returned for queries the DSL does not allow.
+var ErrInvalidAnalyticsQuery = errors.New("invalid analytics query")
+
+// analyticsFields are the ticket fields queries may group and filter by.
+// Department and location are the requester's, as in the other reports.
+var analyticsFields = map[string]string{
+	"category":    "category",
+	"subcategory": "subcategory",
+	"priority":    "priority",
+	"status":      "status",
+	"type":        "type",
+	"team":        "team",
+	"department":  "requesterDepartment",
+	"location":    "requesterLocation",
+	"assignedTo":  "assignedTo",
+	"tag":         "tags",
+}
+
+// analyticsTimeBuckets are the createdAt granularities queries may group by.
+var analyticsTimeBuckets = map[string]bool{"day": true, "week": true, "month": true}
+
+// analyticsNumericFields are the values measures other than count
+// aggregate. Open tickets have no resolution time and unrated tickets no
+// rating; the aggregations skip them.
+var analyticsNumericFields = map[string]interface{}{
+	"resolutionHours": bson.M{"$cond": bson.A{
+		bson.M{"$ifNull": bson.A{"$resolvedAt", false}},
+		bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{"$resolvedAt", "$createdAt"}}, 3600000}},
+		nil,
+	}},
+	"rating": "$rating.score",
+}
+
+var analyticsMeasureOps = map[string]string{"sum": "$sum", "avg": "$avg", "min": "$min", "max": "$max"}
+
+var analyticsColumnPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,39}$`)
+
+func invalidAnalyticsQuery(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidAnalyticsQuery, fmt.Sprintf(format, args...))
+}
+
+// CompileAnalyticsQuery checks q against the DSL's guardrails and compiles
+// it to an aggregation pipeline over the live and archived tickets. It
+// returns the pipeline and the result columns.
+func CompileAnalyticsQuery(q models.AnalyticsQuery) (bson.A, []string, error) {
+	if !q.To.After(q.From) || q.To.Sub(q.From) > maxAnalyticsRange {
+		return nil, nil, invalidAnalyticsQuery("to must be after from, and at most a year later")
+	}
+	if len(q.Dimensions) > maxAnalyticsDimensions {
+		return nil, nil, invalidAnalyticsQuery("at most %d dimensions are allowed", maxAnalyticsDimensions)
+	}
+	if len(q.Measures) == 0 || len(q.Measures) > maxAnalyticsMeasures {
+		return nil, nil, invalidAnalyticsQuery("between 1 and %d measures are required", maxAnalyticsMeasures)
+	}
+	if len(q.Filters) > maxAnalyticsFilters {
+		return nil, nil, invalidAnalyticsQuery("at most %d filters are allowed", maxAnalyticsFilters)
+	}
+	timeZone := q.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return nil, nil, invalidAnalyticsQuery("unknown time zone %q", timeZone)
+	}
+
+	columns := []string{}
+	seen := map[string]bool{}
+	requester := false
+	unwindTags := false
+	group := bson.M{}
+	project := bson.M{"_id": 0}
+	for _, dim := range q.Dimensions {
+		if seen[dim] {
+			return nil, nil, invalidAnalyticsQuery("dimension %q is repeated", dim)
+		}
+		seen[dim] = true
+		switch {
+		case analyticsTimeBuckets[dim]:
+			group[dim] = bson.M{"$dateTrunc": bson.M{"date": "$createdAt", "unit": dim, "timezone": timeZone}}
+		case analyticsFields[dim] != "":
+			field := analyticsFields[dim]
+			requester = requester || dim == "department" || dim == "location"
+			unwindTags = unwindTags || dim == "tag"
+			group[dim] = "$" + field
+		default:
+			return nil, nil, invalidAnalyticsQuery("unknown dimension %q", dim)
+		}
+		project[dim] = "$_id." + dim
+		columns = append(columns, dim)
+	}
+
+	accumulators := bson.M{}
+	for _, m := range q.Measures {
+		var accumulator bson.M
+		name := m.Op
+		if m.Op == "count" {
+			if m.Field != "" {
+				return nil, nil, invalidAnalyticsQuery("count takes no field")
+			}
+			accumulator = bson.M{"$sum": 1}
+		} else {
+			op, ok := analyticsMeasureOps[m.Op]
+			if !ok {
+				return nil, nil, invalidAnalyticsQuery("unknown measure op %q", m.Op)
+			}
+			value, ok := analyticsNumericFields[m.Field]
+			if !ok {
+				return nil, nil, invalidAnalyticsQuery("unknown measure field %q", m.Field)
+			}
+			accumulator = bson.M{op: value}
+			name = m.Op + "_" + m.Field
+		}
+		if m.As != "" {
+			name = m.As
+		}
+		if !analyticsColumnPattern.MatchString(name) {
+			return nil, nil, invalidAnalyticsQuery("column name %q must be a letter followed by letters, digits or underscores", name)
+		}
+		if seen[name] {
+			return nil, nil, invalidAnalyticsQuery("column %q is repeated", name)
+		}
+		seen[name] = true
+		accumulators[name] = accumulator
+		project[name] = 1
+		columns = append(columns, name)
+	}
+
+	match := bson.M{"createdAt": bson.M{"$gte": q.From, "$lt": q.To}}
+	requesterMatch := bson.M{}
+	for _, f := range q.Filters {
+		field, ok := analyticsFields[f.Field]
+		if !ok {
+			return nil, nil, invalidAnalyticsQuery("unknown filter field %q", f.Field)
+		}
+		values := f.Values
+		if f.Op == "eq" || f.Op == "ne" {
+			values = []string{f.Value}
+		}
+		if len(values) == 0 || len(values) > maxAnalyticsFilterValues {
+			return nil, nil, invalidAnalyticsQuery("filter on %s needs between 1 and %d values", f.Field, maxAnalyticsFilterValues)
+		}
+		typed := bson.A{}
+		for _, v := range values {
+			if f.Field != "assignedTo" {
+				typed = append(typed, v)
+				continue
+			}
+			id, err := primitive.ObjectIDFromHex(v)
+			if err != nil {
+				return nil, nil, invalidAnalyticsQuery("assignedTo filter values must be user IDs")
+			}
+			typed = append(typed, id)
+		}
+		var condition bson.M
+		switch f.Op {
+		case "eq", "in":
+			condition = bson.M{"$in": typed}
+		case "ne", "nin":
+			condition = bson.M{"$nin": typed}
+		default:
+			return nil, nil, invalidAnalyticsQuery("unknown filter op %q", f.Op)
+		}
+		target := match
+		if f.Field == "department" || f.Field == "location" {
+			requester = true
+			target = requesterMatch
+		}
+		if _, ok := target[field]; ok {
+			return nil, nil, invalidAnalyticsQuery("field %s is filtered more than once", f.Field)
+		}
+		target[field] = condition
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": match},
+		bson.M{"$unionWith": bson.M{"coll": "tickets_archive", "pipeline": bson.A{bson.M{"$match": match}}}},
+	}
+	if requester {
+		pipeline = append(pipeline, requesterStages()...)
+		if len(requesterMatch) > 0 {
+			pipeline = append(pipeline, bson.M{"$match": requesterMatch})
+		}
+	}
+	if unwindTags {
+		pipeline = append(pipeline, bson.M{"$unwind": bson.M{"path": "$tags", "preserveNullAndEmptyArrays": true}})
+	}
+	groupStage := bson.M{"_id": group}
+	for name, accumulator := range accumulators {
+		groupStage[name] = accumulator
+	}
+	pipeline = append(pipeline, bson.M{"$group": groupStage}, bson.M{"$project": project})
+
+	sort := bson.D{}
+	if q.Sort != nil {
+		if !seen[q.Sort.By] {
+			return nil, nil, invalidAnalyticsQuery("sort column %q is not a dimension or measure", q.Sort.By)
+		}
+		direction := 1
+		if q.Sort.Desc {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: q.Sort.By, Value: direction})
+	}
+	for _, dim := range q.Dimensions {
+		if q.Sort == nil || q.Sort.By != dim {
+			sort = append(sort, bson.E{Key: dim, Value: 1})
+		}
+	}
+	if len(sort) > 0 {
+		pipeline = append(pipeline, bson.M{"$sort": sort})
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultAnalyticsRows
+	}
+	if limit > maxAnalyticsRows {
+		return nil, nil, invalidAnalyticsQuery("limit must be at most %d", maxAnalyticsRows)
+	}
+	// One extra row tells whether the limit cut any off
+	pipeline = append(pipeline, bson.M{"$limit": limit + 1})
+	return pipeline, columns, nil
+}
+
+// Query runs an analytics query, giving up after analyticsQueryTimeout.
+func (s *AnalyticsService) Query(ctx context.Context, q models.AnalyticsQuery) (models.AnalyticsQueryResult, error) {
+	pipeline, columns, err := CompileAnalyticsQuery(q)
+	if err != nil {
+		return models.AnalyticsQueryResult{}, err
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultAnalyticsRows
+	}
+
+	cursor, err := s.db.GetCollection("tickets").Aggregate(ctx, pipeline, options.Aggregate().SetMaxTime(analyticsQueryTimeout))
+	if err != nil {
+		return models.AnalyticsQueryResult{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []bson.M
+	if err := cursor.All(ctx, &rows); err != nil {
+		return models.AnalyticsQueryResult{}, err
+	}
+	result := models.AnalyticsQueryResult{Columns: columns, Rows: []map[string]interface{}{}}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		result.Truncated = true
+	}
+	for _, row := range rows {
+		result.Rows = append(result.Rows, map[string]interface{}(row))
+	}
+	return result, nil
+}
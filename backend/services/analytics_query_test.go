@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"intelliops-ai-copilot/models"
+)
+
+func TestCompileAnalyticsQuery(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	count := []models.AnalyticsMeasure{{Op: "count"}}
+
+	tests := []struct {
+		name    string
+		query   models.AnalyticsQuery
+		wantErr string
+		columns []string
+	}{
+		{
+			name: "valid",
+			query: models.AnalyticsQuery{
+				Dimensions: []string{"category", "month"},
+				Measures:   []models.AnalyticsMeasure{{Op: "count"}, {Op: "avg", Field: "resolutionHours"}},
+				Filters:    []models.AnalyticsFilter{{Field: "priority", Op: "in", Values: []string{"high", "critical"}}},
+				From:       from,
+				To:         from.AddDate(0, 3, 0),
+				TimeZone:   "Asia/Kolkata",
+				Limit:      maxAnalyticsRows,
+			},
+			columns: []string{"category", "month", "count", "avg_resolutionHours"},
+		},
+		{
+			name:    "unknown dimension",
+			query:   models.AnalyticsQuery{Dimensions: []string{"password"}, Measures: count, From: from, To: from.AddDate(0, 1, 0)},
+			wantErr: `unknown dimension "password"`,
+		},
+		{
+			name: "unknown filter field",
+			query: models.AnalyticsQuery{Measures: count, From: from, To: from.AddDate(0, 1, 0),
+				Filters: []models.AnalyticsFilter{{Field: "requesterEmail", Op: "eq", Value: "a@example.com"}}},
+			wantErr: `unknown filter field "requesterEmail"`,
+		},
+		{
+			name:    "unknown measure field",
+			query:   models.AnalyticsQuery{Measures: []models.AnalyticsMeasure{{Op: "sum", Field: "cost"}}, From: from, To: from.AddDate(0, 1, 0)},
+			wantErr: `unknown measure field "cost"`,
+		},
+		{
+			name: "repeated filter",
+			query: models.AnalyticsQuery{Measures: count, From: from, To: from.AddDate(0, 1, 0),
+				Filters: []models.AnalyticsFilter{
+					{Field: "status", Op: "eq", Value: "open"},
+					{Field: "status", Op: "nin", Values: []string{"closed"}},
+				}},
+			wantErr: "field status is filtered more than once",
+		},
+		{
+			name: "repeated requester filter",
+			query: models.AnalyticsQuery{Measures: count, From: from, To: from.AddDate(0, 1, 0),
+				Filters: []models.AnalyticsFilter{
+					{Field: "department", Op: "eq", Value: "Finance"},
+					{Field: "department", Op: "ne", Value: "IT"},
+				}},
+			wantErr: "field department is filtered more than once",
+		},
+		{
+			name:    "range over a year",
+			query:   models.AnalyticsQuery{Measures: count, From: from, To: from.Add(maxAnalyticsRange + time.Hour)},
+			wantErr: "at most a year later",
+		},
+		{
+			name:    "to before from",
+			query:   models.AnalyticsQuery{Measures: count, From: from, To: from.AddDate(0, 0, -1)},
+			wantErr: "to must be after from",
+		},
+		{
+			name:    "limit over 1000",
+			query:   models.AnalyticsQuery{Measures: count, From: from, To: from.AddDate(0, 1, 0), Limit: maxAnalyticsRows + 1},
+			wantErr: "limit must be at most 1000",
+		},
+		{
+			name:    "bad time zone",
+			query:   models.AnalyticsQuery{Dimensions: []string{"day"}, Measures: count, From: from, To: from.AddDate(0, 1, 0), TimeZone: "Mars/Olympus_Mons"},
+			wantErr: `unknown time zone "Mars/Olympus_Mons"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline, columns, err := CompileAnalyticsQuery(tt.query)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidAnalyticsQuery) {
+					t.Fatalf("error = %v, want ErrInvalidAnalyticsQuery", err)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %q, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("columns = %v, want %v", columns, tt.columns)
+			}
+			if len(pipeline) == 0 {
+				t.Error("pipeline is empty")
+			}
+		})
+	}
+}
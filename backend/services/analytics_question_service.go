@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const analyticsQuestionSystemPrompt = "You are a data analyst for an IT helpdesk. You translate questions about ticket data into analytics queries. Always respond with valid JSON." + untrustedContentPolicy
+
+// maxAnalyticsQuestion bounds the question sent to the LLM.
+const maxAnalyticsQuestion = 500
+
+// analyticsAnswerRows is how many result rows the answer text names.
+const analyticsAnswerRows = 5
+
+// AnalyticsQuestionService answers natural-language questions about
+// ticket data. The LLM only translates the question into the analytics
+// query DSL, so the guardrails of CompileAnalyticsQuery apply to whatever
+// it generates.
+type AnalyticsQuestionService struct {
+	db         *database.MongoDB
+	analytics  *AnalyticsService
+	llm        *LLMService
+	guardrails *GuardrailService
+	taxonomy   *TaxonomyService
+}
+
+func NewAnalyticsQuestionService(db *database.MongoDB, analytics *AnalyticsService, llm *LLMService, guardrails *GuardrailService, taxonomy *TaxonomyService) *AnalyticsQuestionService {
+	return &AnalyticsQuestionService{db: db, analytics: analytics, llm: llm, guardrails: guardrails, taxonomy: taxonomy}
+}
+
+// Ask translates question into an analytics query, falling back to keyword
+// matching when the LLM is unavailable, runs it and phrases the answer.
+func (s *AnalyticsQuestionService) Ask(ctx context.Context, question string) (models.AskAnalyticsResponse, error) {
+	question = strings.TrimSpace(question)
+	if question == "" || len(question) > maxAnalyticsQuestion {
+		return models.AskAnalyticsResponse{}, invalidAnalyticsQuery("question must be 1 to %d characters", maxAnalyticsQuestion)
+	}
+	response := models.AskAnalyticsResponse{Question: question}
+	taxonomy := s.taxonomy.Get(ctx)
+	now := time.Now()
+
+	title, query, err := s.translate(ctx, question, taxonomy, now)
+	if err != nil {
+		if err != ErrLLMUnavailable {
+			log.Printf("Analytics question translation failed, using keyword matching: %v", err)
+		}
+		title, query = MockAnalyticsQuery(question, taxonomy, now)
+		response.Fallback = true
+	}
+	response.Title, response.Query = title, query
+
+	result, err := s.analytics.Query(ctx, query)
+	if err != nil {
+		return response, err
+	}
+	if err := s.nameTechnicians(ctx, &result); err != nil {
+		return response, err
+	}
+	response.Result = result
+	response.Answer = analyticsAnswer(title, query, result)
+	return response, nil
+}
+
+func (s *AnalyticsQuestionService) translate(ctx context.Context, question string, taxonomy models.Taxonomy, now time.Time) (string, models.AnalyticsQuery, error) {
+	categories := []string{}
+	for _, c := range taxonomy.Categories {
+		categories = append(categories, string(c.Name))
+	}
+	priorities := []string{}
+	for _, p := range taxonomy.Priorities {
+		priorities = append(priorities, string(p.Name))
+	}
+	prompt := fmt.Sprintf(`Translate the question about IT support tickets into an analytics query.
+
+The time now is %s. Weeks start on Sunday.
+Categories: %s
+Priorities: %s
+Statuses: open, in_progress, resolved, closed
+
+%s
+
+Respond with a JSON object containing:
+- title: a short description of what the query computes, e.g. "Critical network tickets created last month by technician"
+- query: an object with
+  - dimensions: up to 3 of category, subcategory, priority, status, type, team, department, location, assignedTo (the technician), tag, day, week, month
+  - measures: 1 to 5 objects {"op", "field", "as"}; op is count (without field) or sum, avg, min or max of the field resolutionHours or rating
+  - filters: objects {"field", "op": "eq" or "ne", "value"} or {"field", "op": "in" or "nin", "values"} on the dimension fields except day, week and month
+  - from, to: RFC 3339 times bounding when the tickets were created, at most a year apart
+  - sort: optionally {"by", "desc"} naming a dimension or measure
+  - limit: optionally the number of rows, at most 1000`,
+		now.Format(time.RFC3339), strings.Join(categories, ", "), strings.Join(priorities, ", "),
+		s.guardrails.Wrap(ctx, "analytics", "", "question", question))
+
+	content, err := s.llm.Complete(ctx, CompletionRequest{
+		System:   analyticsQuestionSystemPrompt,
+		Prompt:   prompt,
+		Endpoint: EndpointAnalytics,
+	})
+	if err != nil {
+		return "", models.AnalyticsQuery{}, err
+	}
+
+	var parsed struct {
+		Title string                `json:"title"`
+		Query models.AnalyticsQuery `json:"query"`
+	}
+	if err := json.Unmarshal([]byte(ExtractJSON(content)), &parsed); err != nil {
+		return "", models.AnalyticsQuery{}, fmt.Errorf("failed to parse analytics query: %v", err)
+	}
+	if _, _, err := CompileAnalyticsQuery(parsed.Query); err != nil {
+		return "", models.AnalyticsQuery{}, err
+	}
+	if strings.TrimSpace(parsed.Title) == "" {
+		parsed.Title = question
+	}
+	return parsed.Title, parsed.Query, nil
+}
+
+// nameTechnicians adds the assignee's name to rows grouped by assignedTo.
+func (s *AnalyticsQuestionService) nameTechnicians(ctx context.Context, result *models.AnalyticsQueryResult) error {
+	ids := []primitive.ObjectID{}
+	for _, row := range result.Rows {
+		if id, ok := row["assignedTo"].(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	cursor, err := s.db.GetCollection("users").Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"name": 1}))
+	if err != nil {
+		return err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return err
+	}
+	names := map[primitive.ObjectID]string{}
+	for _, u := range users {
+		names[u.ID] = u.Name
+	}
+	for _, row := range result.Rows {
+		if _, ok := row["assignedTo"]; !ok {
+			continue
+		}
+		name := "Unassigned"
+		if id, ok := row["assignedTo"].(primitive.ObjectID); ok {
+			name = names[id]
+		}
+		row["assignedToName"] = name
+	}
+	result.Columns = append(result.Columns, "assignedToName")
+	return nil
+}
+
+// analyticsAnswer phrases a query result, e.g. "Critical tickets by
+// technician: Ravi Kumar 5, Priya Sharma 3".
+func analyticsAnswer(title string, query models.AnalyticsQuery, result models.AnalyticsQueryResult) string {
+	if len(result.Rows) == 0 {
+		return title + ": no tickets match."
+	}
+	measures := result.Columns[len(query.Dimensions):]
+	if _, ok := result.Rows[0]["assignedToName"]; ok {
+		measures = measures[:len(measures)-1]
+	}
+	values := func(row map[string]interface{}) string {
+		parts := []string{}
+		for _, m := range measures {
+			text := analyticsValueString(row[m])
+			if len(measures) > 1 {
+				text = m + " " + text
+			}
+			parts = append(parts, text)
+		}
+		return strings.Join(parts, ", ")
+	}
+	if len(query.Dimensions) == 0 {
+		return title + ": " + values(result.Rows[0])
+	}
+
+	parts := []string{}
+	for i, row := range result.Rows {
+		if i == analyticsAnswerRows {
+			parts = append(parts, fmt.Sprintf("and %d more", len(result.Rows)-i))
+			break
+		}
+		labels := []string{}
+		for _, dim := range query.Dimensions {
+			value := row[dim]
+			if dim == "assignedTo" {
+				value = row["assignedToName"]
+			}
+			label := analyticsValueString(value)
+			if label == "" {
+				label = "none"
+			}
+			labels = append(labels, label)
+		}
+		parts = append(parts, strings.Join(labels, " / ")+" "+values(row))
+	}
+	return title + ": " + strings.Join(parts, "; ")
+}
+
+func analyticsValueString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		if v == float64(int64(v)) {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	case primitive.DateTime:
+		return v.Time().UTC().Format("2006-01-02")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+var (
+	lastDaysPattern = regexp.MustCompile(`\b(?:last|past) (\d{1,3}) days\b`)
+	groupByPattern  = regexp.MustCompile(`\b(?:by|per) (technician|assignee|category|subcategory|priority|status|team|department|location|tag|type|day|week|month)\b`)
+)
+
+// analyticsGroupSynonyms maps the words of "by ..." to dimensions.
+var analyticsGroupSynonyms = map[string]string{"technician": "assignedTo", "assignee": "assignedTo"}
+
+// MockAnalyticsQuery translates a question by keyword matching: a time
+// range (today, this or last week or month, the last N days, by default
+// the last 30 days), priorities, categories and statuses it names, "by"
+// groupings, and average resolution time or rating instead of a count.
+func MockAnalyticsQuery(question string, taxonomy models.Taxonomy, now time.Time) (string, models.AnalyticsQuery) {
+	text := strings.ToLower(question)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	query := models.AnalyticsQuery{From: today.AddDate(0, 0, -30), To: now}
+	period := "in the last 30 days"
+	switch {
+	case strings.Contains(text, "today"):
+		query.From, period = today, "today"
+	case strings.Contains(text, "yesterday"):
+		query.From, query.To, period = today.AddDate(0, 0, -1), today, "yesterday"
+	case strings.Contains(text, "this week"):
+		query.From, period = today.AddDate(0, 0, -int(today.Weekday())), "this week"
+	case strings.Contains(text, "last week"):
+		start := today.AddDate(0, 0, -int(today.Weekday()))
+		query.From, query.To, period = start.AddDate(0, 0, -7), start, "last week"
+	case strings.Contains(text, "this month"):
+		query.From, period = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), "this month"
+	case strings.Contains(text, "last month"):
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		query.From, query.To, period = start.AddDate(0, -1, 0), start, "last month"
+	default:
+		if m := lastDaysPattern.FindStringSubmatch(text); m != nil {
+			days, _ := strconv.Atoi(m[1])
+			if days > 0 && days <= 366 {
+				query.From, period = today.AddDate(0, 0, -days), fmt.Sprintf("in the last %d days", days)
+			}
+		}
+	}
+
+	words := []string{}
+	filter := func(field string, values []string) {
+		if len(values) > 0 {
+			query.Filters = append(query.Filters, models.AnalyticsFilter{Field: field, Op: "in", Values: values})
+			words = append(words, strings.Join(values, " or "))
+		}
+	}
+	// Priority names such as "low" are too short for mentions
+	tokens := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	priorities := []string{}
+	for _, p := range taxonomy.Priorities {
+		if containsString(tokens, strings.ToLower(string(p.Name))) {
+			priorities = append(priorities, string(p.Name))
+		}
+	}
+	filter("priority", priorities)
+	categories := []string{}
+	for _, c := range taxonomy.Categories {
+		// "network" names Network Issue
+		name := string(c.Name)
+		if first, _, _ := strings.Cut(name, " "); mentions(text, name) || mentions(text, first) {
+			categories = append(categories, string(c.Name))
+		}
+	}
+	filter("category", categories)
+	statuses := []string{}
+	for _, st := range []models.TicketStatus{models.StatusOpen, models.StatusInProgress, models.StatusResolved, models.StatusClosed} {
+		if mentions(text, string(st)) || mentions(text, strings.ReplaceAll(string(st), "_", " ")) {
+			statuses = append(statuses, string(st))
+		}
+	}
+	filter("status", statuses)
+
+	groups := []string{}
+	for _, m := range groupByPattern.FindAllStringSubmatch(text, -1) {
+		dim := m[1]
+		if synonym, ok := analyticsGroupSynonyms[dim]; ok {
+			dim = synonym
+		}
+		if len(query.Dimensions) < maxAnalyticsDimensions && !containsString(query.Dimensions, dim) {
+			query.Dimensions = append(query.Dimensions, dim)
+			groups = append(groups, m[1])
+		}
+	}
+
+	measure := "Tickets"
+	switch {
+	case strings.Contains(text, "resolution") || strings.Contains(text, "resolve"):
+		query.Measures = []models.AnalyticsMeasure{{Op: "avg", Field: "resolutionHours"}}
+		measure = "Average resolution hours of tickets"
+	case strings.Contains(text, "rating") || strings.Contains(text, "satisfaction"):
+		query.Measures = []models.AnalyticsMeasure{{Op: "avg", Field: "rating"}}
+		measure = "Average rating of tickets"
+	default:
+		query.Measures = []models.AnalyticsMeasure{{Op: "count"}}
+	}
+	if len(query.Dimensions) > 0 {
+		query.Sort = &models.AnalyticsSort{By: query.Measures[0].Op, Desc: true}
+		if query.Measures[0].Op != "count" {
+			query.Sort.By = query.Measures[0].Op + "_" + query.Measures[0].Field
+		}
+	}
+
+	title := measure
+	if len(words) > 0 {
+		title += " (" + strings.Join(words, ", ") + ")"
+	}
+	title += " created " + period
+	if len(groups) > 0 {
+		title += " by " + strings.Join(groups, " and ")
+	}
+	return title, query
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// AnalyticsService aggregates ticket volume by when tickets are raised and
+// where their requesters are. Archived tickets are included, so long
+// ranges are not cut short by the archive.
+type AnalyticsService struct {
+	db  *database.MongoDB
+	org *OrgService
+}
+
+func NewAnalyticsService(db *database.MongoDB, org *OrgService) *AnalyticsService {
+	return &AnalyticsService{db: db, org: org}
+}
+
+// requesterStages sets the location and department a ticket was raised
+// from: those recorded on the ticket, or for older tickets the requester's
+// current ones. Tickets of deleted users, or users without them, get "".
+func requesterStages() bson.A {
+	return bson.A{
+		bson.M{"$lookup": bson.M{
+			"from":         "users",
+			"localField":   "createdBy",
+			"foreignField": "_id",
+			"as":           "requester",
+		}},
+		bson.M{"$addFields": bson.M{
+			"requesterLocation": bson.M{"$ifNull": bson.A{
+				"$location", bson.M{"$arrayElemAt": bson.A{"$requester.location", 0}}, "",
+			}},
+			"requesterDepartment": bson.M{"$ifNull": bson.A{
+				"$department", bson.M{"$arrayElemAt": bson.A{"$requester.department", 0}}, "",
+			}},
+		}},
+	}
+}
+
+// aggregate runs pipeline over the live and then the archived tickets,
+// passing each result cursor to decode.
+func (s *AnalyticsService) aggregate(ctx context.Context, pipeline bson.A, decode func(*mongo.Cursor) error) error {
+	for _, name := range []string{"tickets", "tickets_archive"} {
+		cursor, err := s.db.GetCollection(name).Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+		err = decode(cursor)
+		cursor.Close(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type heatmapRow struct {
+	Key struct {
+		Weekday int `bson:"weekday"`
+		Hour    int `bson:"hour"`
+	} `bson:"_id"`
+	Count int64 `bson:"count"`
+}
+
+type departmentRow struct {
+	Department string `bson:"_id"`
+	Count      int64  `bson:"count"`
+	Open       int64  `bson:"open"`
+	Critical   int64  `bson:"critical"`
+}
+
+type locationRow struct {
+	Key struct {
+		Location   string `bson:"location"`
+		Department string `bson:"department"`
+	} `bson:"_id"`
+	Count    int64 `bson:"count"`
+	Critical int64 `bson:"critical"`
+}
+
+// locationMatch filters requesters by location and department; "unknown"
+// matches requesters without one.
+func locationMatch(field, value string) bson.M {
+	if value == models.UnknownLocation {
+		return bson.M{field: ""}
+	}
+	return bson.M{field: value}
+}
+
+// Heatmap counts tickets created in [from, to) by weekday and hour in loc,
+// optionally only for requesters at location or in department.
+func (s *AnalyticsService) Heatmap(ctx context.Context, from, to time.Time, loc *time.Location, location, department string) (models.TicketHeatmap, error) {
+	heatmap := models.TicketHeatmap{
+		From:       from,
+		To:         to,
+		TimeZone:   loc.String(),
+		Location:   location,
+		Department: department,
+	}
+
+	pipeline := bson.A{bson.M{"$match": bson.M{"createdAt": bson.M{"$gte": from, "$lt": to}}}}
+	if location != "" || department != "" {
+		pipeline = append(pipeline, requesterStages()...)
+		if location != "" {
+			pipeline = append(pipeline, bson.M{"$match": locationMatch("requesterLocation", location)})
+		}
+		if department != "" {
+			pipeline = append(pipeline, bson.M{"$match": locationMatch("requesterDepartment", department)})
+		}
+	}
+	date := bson.M{"date": "$createdAt", "timezone": loc.String()}
+	pipeline = append(pipeline, bson.M{"$group": bson.M{
+		"_id":   bson.M{"weekday": bson.M{"$dayOfWeek": date}, "hour": bson.M{"$hour": date}},
+		"count": bson.M{"$sum": 1},
+	}})
+
+	var rows []heatmapRow
+	err := s.aggregate(ctx, pipeline, func(cursor *mongo.Cursor) error {
+		var page []heatmapRow
+		if err := cursor.All(ctx, &page); err != nil {
+			return err
+		}
+		rows = append(rows, page...)
+		return nil
+	})
+	if err != nil {
+		return heatmap, err
+	}
+
+	for _, row := range rows {
+		// $dayOfWeek numbers Sunday 1 to Saturday 7
+		weekday, hour := row.Key.Weekday-1, row.Key.Hour
+		if weekday < 0 || weekday > 6 || hour < 0 || hour > 23 {
+			continue
+		}
+		heatmap.Cells[weekday][hour] += row.Count
+		heatmap.ByWeekday[weekday] += row.Count
+		heatmap.ByHour[hour] += row.Count
+		heatmap.Total += row.Count
+	}
+
+	peakWeekday, peak := 0, int64(-1)
+	for weekday := range heatmap.Cells {
+		for hour, count := range heatmap.Cells[weekday] {
+			if count > peak {
+				peakWeekday, heatmap.PeakHour, peak = weekday, hour, count
+			}
+		}
+	}
+	heatmap.PeakWeekday = time.Weekday(peakWeekday).String()
+	return heatmap, nil
+}
+
+// Locations counts tickets created in [from, to) by the requester's
+// location and department, busiest first.
+func (s *AnalyticsService) Locations(ctx context.Context, from, to time.Time) (models.TicketLocationReport, error) {
+	report := models.TicketLocationReport{
+		From:         from,
+		To:           to,
+		ByLocation:   map[string]int64{},
+		ByDepartment: map[string]int64{},
+		Groups:       []models.TicketLocationCount{},
+	}
+
+	pipeline := bson.A{bson.M{"$match": bson.M{"createdAt": bson.M{"$gte": from, "$lt": to}}}}
+	pipeline = append(pipeline, requesterStages()...)
+	pipeline = append(pipeline, bson.M{"$group": bson.M{
+		"_id":   bson.M{"location": "$requesterLocation", "department": "$requesterDepartment"},
+		"count": bson.M{"$sum": 1},
+		"critical": bson.M{"$sum": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$priority", models.PriorityCritical}}, 1, 0,
+		}}},
+	}})
+
+	var rows []locationRow
+	err := s.aggregate(ctx, pipeline, func(cursor *mongo.Cursor) error {
+		var page []locationRow
+		if err := cursor.All(ctx, &page); err != nil {
+			return err
+		}
+		rows = append(rows, page...)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	groups := map[[2]string]*models.TicketLocationCount{}
+	for _, row := range rows {
+		location, department := row.Key.Location, row.Key.Department
+		if location == "" {
+			location = models.UnknownLocation
+		}
+		if department == "" {
+			department = models.UnknownLocation
+		}
+		key := [2]string{location, department}
+		group, ok := groups[key]
+		if !ok {
+			group = &models.TicketLocationCount{Location: location, Department: department}
+			groups[key] = group
+		}
+		group.Count += row.Count
+		group.Critical += row.Critical
+		report.ByLocation[location] += row.Count
+		report.ByDepartment[department] += row.Count
+		report.Total += row.Count
+	}
+
+	for _, group := range groups {
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		return a.Department < b.Department
+	})
+	return report, nil
+}
+
+// Departments counts tickets created in [from, to) per department and
+// rolls the counts up the org hierarchy, in hierarchy order.
+func (s *AnalyticsService) Departments(ctx context.Context, from, to time.Time) (models.DepartmentReport, error) {
+	report := models.DepartmentReport{From: from, To: to}
+
+	pipeline := bson.A{bson.M{"$match": bson.M{"createdAt": bson.M{"$gte": from, "$lt": to}}}}
+	pipeline = append(pipeline, requesterStages()...)
+	pipeline = append(pipeline, bson.M{"$group": bson.M{
+		"_id":   "$requesterDepartment",
+		"count": bson.M{"$sum": 1},
+		"open": bson.M{"$sum": bson.M{"$cond": bson.A{
+			bson.M{"$in": bson.A{"$status", bson.A{models.StatusOpen, models.StatusInProgress}}}, 1, 0,
+		}}},
+		"critical": bson.M{"$sum": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$priority", models.PriorityCritical}}, 1, 0,
+		}}},
+	}})
+
+	counts := map[string]*models.DepartmentRollup{}
+	err := s.aggregate(ctx, pipeline, func(cursor *mongo.Cursor) error {
+		var rows []departmentRow
+		if err := cursor.All(ctx, &rows); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			name := row.Department
+			if name == "" {
+				name = models.UnknownLocation
+			}
+			key := strings.ToLower(name)
+			if counts[key] == nil {
+				counts[key] = &models.DepartmentRollup{Department: name}
+			}
+			counts[key].Direct += row.Count
+			counts[key].Open += row.Open
+			counts[key].Critical += row.Critical
+			report.Total += row.Count
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	units, err := s.org.ListUnits(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.Departments = departmentRollups(units, counts)
+	return report, nil
+}
@@ -1,6 +1,9 @@
 package services
 
-import "math"
+import (
+    "math"
+    "time"
+)
 
 type AnomalyResult struct {
     IsAnomaly      bool
@@ -52,6 +55,25 @@ func DetectZScoreAnomaly(values []float64, windowSize int, threshold float64, mi
     }
 }
 
+// DetectRecovery reports whether the last n points after since are all
+// within threshold standard deviations of the baseline, and when the
+// first of them was recorded.
+func DetectRecovery(values []float64, timestamps []time.Time, since time.Time, baselineMean, baselineStd, threshold float64, n int) (time.Time, bool) {
+    if n < 1 || baselineStd <= 0 || len(values) != len(timestamps) || len(values) < n {
+        return time.Time{}, false
+    }
+    start := len(values) - n
+    if !timestamps[start].After(since) {
+        return time.Time{}, false
+    }
+    for i := start; i < len(values); i++ {
+        if math.Abs((values[i]-baselineMean)/baselineStd) >= threshold {
+            return time.Time{}, false
+        }
+    }
+    return timestamps[start], true
+}
+
 func mean(xs []float64) float64 {
     var s float64
     for _, v := range xs {
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/models"
+)
+
+// anomalySeverities are the severities anomalies are graded into, most
+// severe first.
+var anomalySeverities = []string{"critical", "high", "medium", "low"}
+
+// builtinSeverityThresholds apply when the configured defaults are invalid.
+var builtinSeverityThresholds = models.SeverityThresholds{Medium: 3, High: 4, Critical: 5}
+
+// AnomalyPolicy grades anomalies by z-score and picks the priority of the
+// tickets they open.
+type AnomalyPolicy struct {
+	Thresholds models.SeverityThresholds
+	Priorities map[string]models.TicketPriority
+}
+
+// DefaultAnomalyPolicy is the policy configured through ANOMALY_* settings.
+// Invalid settings are logged and replaced with the built-in defaults.
+func DefaultAnomalyPolicy(cfg *config.Config) AnomalyPolicy {
+	policy := AnomalyPolicy{
+		Thresholds: models.SeverityThresholds{
+			Medium:   cfg.AnomalyMediumZScore,
+			High:     cfg.AnomalyHighZScore,
+			Critical: cfg.AnomalyCriticalZScore,
+		},
+		Priorities: map[string]models.TicketPriority{},
+	}
+	if err := validateSeverityThresholds(policy.Thresholds); err != nil {
+		log.Printf("Invalid ANOMALY_*_ZSCORE settings (%v), using %g/%g/%g", err,
+			builtinSeverityThresholds.Medium, builtinSeverityThresholds.High, builtinSeverityThresholds.Critical)
+		policy.Thresholds = builtinSeverityThresholds
+	}
+	for severity, priority := range cfg.AnomalyPriorityMap {
+		if !isAnomalySeverity(severity) {
+			log.Printf("Ignoring ANOMALY_PRIORITY_MAP entry for unknown severity %q", severity)
+			continue
+		}
+		policy.Priorities[severity] = models.TicketPriority(priority)
+	}
+	return policy
+}
+
+// AnomalyPolicies holds the default anomaly policy shared by the monitoring
+// workers, rebuilt when the admin settings change.
+type AnomalyPolicies struct {
+	mu     sync.RWMutex
+	policy AnomalyPolicy
+}
+
+func NewAnomalyPolicies(cfg *config.Config) *AnomalyPolicies {
+	return &AnomalyPolicies{policy: DefaultAnomalyPolicy(cfg)}
+}
+
+// Default returns the current default policy.
+func (p *AnomalyPolicies) Default() AnomalyPolicy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.policy
+}
+
+// Reload rebuilds the default policy from cfg.
+func (p *AnomalyPolicies) Reload(cfg *config.Config) {
+	policy := DefaultAnomalyPolicy(cfg)
+	p.mu.Lock()
+	p.policy = policy
+	p.mu.Unlock()
+}
+
+// For returns the policy of a metric: its own thresholds and priority map
+// where set, the defaults otherwise.
+func (p AnomalyPolicy) For(metric models.MetricConfig) AnomalyPolicy {
+	policy := AnomalyPolicy{Thresholds: p.Thresholds, Priorities: map[string]models.TicketPriority{}}
+	if metric.SeverityThresholds != nil {
+		policy.Thresholds = *metric.SeverityThresholds
+	}
+	for severity, priority := range p.Priorities {
+		policy.Priorities[severity] = priority
+	}
+	for severity, priority := range metric.PriorityMap {
+		policy.Priorities[severity] = priority
+	}
+	return policy
+}
+
+// Severity grades an anomaly by the size of its z-score.
+func (p AnomalyPolicy) Severity(z float64) string {
+	az := math.Abs(z)
+	switch {
+	case az >= p.Thresholds.Critical:
+		return "critical"
+	case az >= p.Thresholds.High:
+		return "high"
+	case az >= p.Thresholds.Medium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// Priority is the ticket priority for an anomaly severity, falling back to
+// the priority of the same name.
+func (p AnomalyPolicy) Priority(severity string) models.TicketPriority {
+	if priority, ok := p.Priorities[severity]; ok {
+		return priority
+	}
+	return severityPriority(severity)
+}
+
+func isAnomalySeverity(severity string) bool {
+	for _, s := range anomalySeverities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+func validateSeverityThresholds(t models.SeverityThresholds) error {
+	if t.Medium <= 0 {
+		return fmt.Errorf("severityThresholds.medium must be positive")
+	}
+	if t.High < t.Medium || t.Critical < t.High {
+		return fmt.Errorf("severityThresholds must satisfy medium <= high <= critical")
+	}
+	return nil
+}
+
+// ValidateAnomalyPolicy checks a metric's severity thresholds and priority
+// map; priorities must exist in the taxonomy.
+func ValidateAnomalyPolicy(taxonomy models.Taxonomy, thresholds *models.SeverityThresholds, priorities map[string]models.TicketPriority) error {
+	if thresholds != nil {
+		if err := validateSeverityThresholds(*thresholds); err != nil {
+			return err
+		}
+	}
+	for severity, priority := range priorities {
+		if !isAnomalySeverity(severity) {
+			return fmt.Errorf("priorityMap: unknown severity %q, expected critical, high, medium or low", severity)
+		}
+		if FindPriority(taxonomy, priority) == nil {
+			return fmt.Errorf("priorityMap[%s]: unknown priority %q", severity, priority)
+		}
+	}
+	return nil
+}
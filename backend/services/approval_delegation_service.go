@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var ErrInvalidDelegation = errors.New("invalid approval delegation")
+
+// ApprovalDelegationService stores who stands in for an approver and when,
+// and routes approvals to them.
+type ApprovalDelegationService struct {
+	db *database.MongoDB
+}
+
+func NewApprovalDelegationService(db *database.MongoDB) *ApprovalDelegationService {
+	return &ApprovalDelegationService{db: db}
+}
+
+func (s *ApprovalDelegationService) collection() *mongo.Collection {
+	return s.db.GetCollection("approval_delegations")
+}
+
+// activeAt matches the delegations in effect at now.
+func activeAt(now time.Time) bson.M {
+	return bson.M{"startsAt": bson.M{"$lte": now}, "endsAt": bson.M{"$gt": now}}
+}
+
+// Create delegates the approvals of delegator to another user for a date
+// range. A delegator's ranges cannot overlap, so each approval has one
+// delegate at a time.
+func (s *ApprovalDelegationService) Create(ctx context.Context, delegator models.User, req models.ApprovalDelegationRequest) (models.ApprovalDelegation, error) {
+	delegateID, err := primitive.ObjectIDFromHex(req.DelegateID)
+	if err != nil {
+		return models.ApprovalDelegation{}, fmt.Errorf("%w: delegateId is not a valid ID", ErrInvalidDelegation)
+	}
+	if delegateID == delegator.ID {
+		return models.ApprovalDelegation{}, fmt.Errorf("%w: approvals cannot be delegated to yourself", ErrInvalidDelegation)
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		return models.ApprovalDelegation{}, fmt.Errorf("%w: endsAt must be after startsAt", ErrInvalidDelegation)
+	}
+	now := time.Now()
+	if !req.EndsAt.After(now) {
+		return models.ApprovalDelegation{}, fmt.Errorf("%w: endsAt must be in the future", ErrInvalidDelegation)
+	}
+
+	var delegate models.User
+	err = s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": delegateID}).Decode(&delegate)
+	if err == mongo.ErrNoDocuments {
+		return models.ApprovalDelegation{}, fmt.Errorf("%w: delegate not found", ErrInvalidDelegation)
+	}
+	if err != nil {
+		return models.ApprovalDelegation{}, err
+	}
+	n, err := s.collection().CountDocuments(ctx, bson.M{
+		"delegatorId": delegator.ID,
+		"startsAt":    bson.M{"$lt": req.EndsAt},
+		"endsAt":      bson.M{"$gt": req.StartsAt},
+	})
+	if err != nil {
+		return models.ApprovalDelegation{}, err
+	}
+	if n > 0 {
+		return models.ApprovalDelegation{}, fmt.Errorf("%w: it overlaps another of your delegations", ErrInvalidDelegation)
+	}
+
+	delegation := models.ApprovalDelegation{
+		ID:           primitive.NewObjectID(),
+		DelegatorID:  delegator.ID,
+		DelegateID:   delegate.ID,
+		DelegateName: delegate.Name,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		Reason:       req.Reason,
+		CreatedAt:    now,
+	}
+	if _, err := s.collection().InsertOne(ctx, delegation); err != nil {
+		return models.ApprovalDelegation{}, err
+	}
+	return delegation, nil
+}
+
+// List returns the delegations userID gave and received that have not
+// ended at now, by start.
+func (s *ApprovalDelegationService) List(ctx context.Context, userID primitive.ObjectID, now time.Time) (models.ApprovalDelegations, error) {
+	result := models.ApprovalDelegations{Given: []models.ApprovalDelegation{}, Received: []models.ApprovalDelegation{}}
+	for field, into := range map[string]*[]models.ApprovalDelegation{"delegatorId": &result.Given, "delegateId": &result.Received} {
+		cursor, err := s.collection().Find(ctx, bson.M{field: userID, "endsAt": bson.M{"$gt": now}},
+			options.Find().SetSort(bson.D{{Key: "startsAt", Value: 1}}))
+		if err != nil {
+			return result, err
+		}
+		if err := cursor.All(ctx, into); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Delete removes one of delegatorID's delegations, ending it early.
+func (s *ApprovalDelegationService) Delete(ctx context.Context, id, delegatorID primitive.ObjectID) error {
+	result, err := s.collection().DeleteOne(ctx, bson.M{"_id": id, "delegatorId": delegatorID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Delegators returns the users delegateID stands in for at now.
+func (s *ApprovalDelegationService) Delegators(ctx context.Context, delegateID primitive.ObjectID, now time.Time) ([]models.User, error) {
+	filter := activeAt(now)
+	filter["delegateId"] = delegateID
+	ids, err := s.collection().Distinct(ctx, "delegatorId", filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	cursor, err := s.db.GetCollection("users").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Delegates returns the users standing in at now for the approvers
+// matching filter.
+func (s *ApprovalDelegationService) Delegates(ctx context.Context, approvers bson.M, now time.Time) ([]primitive.ObjectID, error) {
+	ids, err := s.db.GetCollection("users").Distinct(ctx, "_id", approvers)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	filter := activeAt(now)
+	filter["delegatorId"] = bson.M{"$in": ids}
+	values, err := s.collection().Distinct(ctx, "delegateId", filter)
+	if err != nil {
+		return nil, err
+	}
+	delegates := make([]primitive.ObjectID, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(primitive.ObjectID); ok {
+			delegates = append(delegates, id)
+		}
+	}
+	return delegates, nil
+}
+
+// approverFor reports whether user may decide an approval step on a ticket
+// raised by requester, and for whom: nil when they are an approver
+// themselves, otherwise the delegator they stand in for.
+func approverFor(step models.ApprovalStep, user models.User, delegators []models.User, requester primitive.ObjectID) (bool, *models.User) {
+	if canApprove(step, user) {
+		return true, nil
+	}
+	for i, delegator := range delegators {
+		if delegator.ID != requester && canApprove(step, delegator) {
+			return true, &delegators[i]
+		}
+	}
+	return false, nil
+}
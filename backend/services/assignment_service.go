@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// AssignmentService assigns new tickets to technicians by the assignment
+// rule for their category.
+type AssignmentService struct {
+	db           *database.MongoDB
+	taxonomy     *TaxonomyService
+	availability *AvailabilityService
+}
+
+func NewAssignmentService(db *database.MongoDB, taxonomy *TaxonomyService, availability *AvailabilityService) *AssignmentService {
+	return &AssignmentService{db: db, taxonomy: taxonomy, availability: availability}
+}
+
+func (s *AssignmentService) collection() *mongo.Collection {
+	return s.db.GetCollection("assignment_rules")
+}
+
+func (s *AssignmentService) List(ctx context.Context) ([]models.AssignmentRule, error) {
+	cursor, err := s.collection().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rules := []models.AssignmentRule{}
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Save creates or replaces the rule for category, "*" for the fallback
+// rule. The round-robin position is kept.
+func (s *AssignmentService) Save(ctx context.Context, category models.TicketCategory, req models.AssignmentRuleRequest, updatedBy primitive.ObjectID) (models.AssignmentRule, error) {
+	if category != models.AssignmentRuleAnyCategory && FindCategory(s.taxonomy.Get(ctx), category) == nil {
+		return models.AssignmentRule{}, fmt.Errorf("unknown category %q", category)
+	}
+	if req.Strategy != models.AssignRoundRobin && req.Strategy != models.AssignLeastOpen {
+		return models.AssignmentRule{}, fmt.Errorf("strategy must be %s or %s", models.AssignRoundRobin, models.AssignLeastOpen)
+	}
+	if req.MaxOpenTickets < 0 {
+		return models.AssignmentRule{}, errors.New("maxOpenTickets must not be negative")
+	}
+
+	technicians := []primitive.ObjectID{}
+	seen := map[primitive.ObjectID]bool{}
+	for _, hex := range req.Technicians {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return models.AssignmentRule{}, fmt.Errorf("invalid technician ID %q", hex)
+		}
+		if !seen[id] {
+			seen[id] = true
+			technicians = append(technicians, id)
+		}
+	}
+	if len(technicians) > 0 {
+		n, err := s.db.GetCollection("users").CountDocuments(ctx, bson.M{"_id": bson.M{"$in": technicians}, "role": models.RoleTechnician})
+		if err != nil {
+			return models.AssignmentRule{}, err
+		}
+		if n != int64(len(technicians)) {
+			return models.AssignmentRule{}, errors.New("technicians must be existing technician users")
+		}
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	now := time.Now()
+	var rule models.AssignmentRule
+	err := s.collection().FindOneAndUpdate(ctx, bson.M{"_id": category}, bson.M{
+		"$set": bson.M{
+			"strategy":       req.Strategy,
+			"technicians":    technicians,
+			"maxOpenTickets": req.MaxOpenTickets,
+			"enabled":        enabled,
+			"updatedAt":      now,
+			"updatedBy":      updatedBy,
+		},
+		"$setOnInsert": bson.M{"turns": int64(0)},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&rule)
+	return rule, err
+}
+
+func (s *AssignmentService) Delete(ctx context.Context, category models.TicketCategory) error {
+	res, err := s.collection().DeleteOne(ctx, bson.M{"_id": category})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// rule returns the enabled rule for category, falling back to the "*"
+// rule, or nil when neither applies.
+func (s *AssignmentService) rule(ctx context.Context, category models.TicketCategory) (*models.AssignmentRule, error) {
+	cursor, err := s.collection().Find(ctx, bson.M{
+		"_id":     bson.M{"$in": []models.TicketCategory{category, models.AssignmentRuleAnyCategory}},
+		"enabled": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []models.AssignmentRule
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+	var fallback *models.AssignmentRule
+	for i := range rules {
+		if rules[i].Category == category {
+			return &rules[i], nil
+		}
+		fallback = &rules[i]
+	}
+	return fallback, nil
+}
+
+// assignmentCandidate is a technician a rule may assign to.
+type assignmentCandidate struct {
+	id    primitive.ObjectID
+	open  int
+	score float64
+}
+
+// candidates returns the rule's technicians that may take a ticket in
+// category now, ordered by ID, with their open ticket counts and skill.
+// Technicians out of office or off shift are left out.
+func (s *AssignmentService) candidates(ctx context.Context, rule models.AssignmentRule, category models.TicketCategory) ([]assignmentCandidate, error) {
+	filter := bson.M{"role": models.RoleTechnician}
+	if len(rule.Technicians) > 0 {
+		filter["_id"] = bson.M{"$in": rule.Technicians}
+	}
+	cursor, err := s.db.GetCollection("users").Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}).SetSort(bson.D{{"_id", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	ids := make([]primitive.ObjectID, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+
+	// Skill scores, and technicians excluded from the category
+	cursor, err = s.db.GetCollection("technician_skills").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var profiles []models.SkillProfile
+	if err := cursor.All(ctx, &profiles); err != nil {
+		return nil, err
+	}
+	excluded := map[primitive.ObjectID]bool{}
+	scores := map[primitive.ObjectID]float64{}
+	for _, p := range profiles {
+		for _, o := range p.Overrides {
+			if o.Category == category && o.Excluded {
+				excluded[p.TechnicianID] = true
+			}
+		}
+		for _, skill := range p.Skills {
+			if skill.Category == category {
+				scores[p.TechnicianID] = skill.Score
+			}
+		}
+	}
+
+	cursor, err = s.db.GetCollection("tickets").Aggregate(ctx, mongo.Pipeline{
+		{{"$match", bson.M{
+			"assignedTo": bson.M{"$in": ids},
+			"status":     bson.M{"$in": []models.TicketStatus{models.StatusOpen, models.StatusInProgress}},
+		}}},
+		{{"$group", bson.M{"_id": "$assignedTo", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int                `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	open := map[primitive.ObjectID]int{}
+	for _, c := range counts {
+		open[c.ID] = c.Count
+	}
+
+	unavailable, err := s.availability.Unavailable(ctx, ids, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []assignmentCandidate{}
+	for _, id := range ids {
+		if excluded[id] || unavailable[id] != "" {
+			continue
+		}
+		if rule.MaxOpenTickets > 0 && open[id] >= rule.MaxOpenTickets {
+			continue
+		}
+		candidates = append(candidates, assignmentCandidate{id: id, open: open[id], score: scores[id]})
+	}
+	return candidates, nil
+}
+
+// Assign sets the assignee of a new, unassigned ticket by the rule for its
+// category. It is called before the ticket is stored and leaves the ticket
+// unassigned when no rule applies. When every technician of the rule is
+// excluded, at their limit or unavailable, the ticket goes to whoever is on
+// call, if anyone.
+func (s *AssignmentService) Assign(ctx context.Context, ticket *models.Ticket) error {
+	if ticket.AssignedTo != nil {
+		return nil
+	}
+	rule, err := s.rule(ctx, ticket.Category)
+	if err != nil || rule == nil {
+		return err
+	}
+	candidates, err := s.candidates(ctx, *rule, ticket.Category)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		shift, err := s.availability.OnCall(ctx, time.Now())
+		if err != nil || shift == nil {
+			return err
+		}
+		ticket.AssignedTo = &shift.UserID
+		return nil
+	}
+
+	var assignee primitive.ObjectID
+	switch rule.Strategy {
+	case models.AssignLeastOpen:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].open != candidates[j].open {
+				return candidates[i].open < candidates[j].open
+			}
+			return candidates[i].score > candidates[j].score
+		})
+		assignee = candidates[0].id
+	default:
+		// Take the turn atomically so concurrent tickets go to different
+		// technicians
+		var updated models.AssignmentRule
+		err := s.collection().FindOneAndUpdate(ctx, bson.M{"_id": rule.Category}, bson.M{"$inc": bson.M{"turns": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.Before)).Decode(&updated)
+		if err != nil {
+			return err
+		}
+		assignee = candidates[updated.Turns%int64(len(candidates))].id
+	}
+	ticket.AssignedTo = &assignee
+	return nil
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// ErrAttachmentNotOwned is returned when a user deletes an attachment
+// someone else uploaded.
+var ErrAttachmentNotOwned = errors.New("you can only delete your own attachments")
+
+// attachmentStore keeps attachment contents by attachment ID.
+type attachmentStore interface {
+	Put(ctx context.Context, id primitive.ObjectID, name string, r io.Reader) error
+	Open(ctx context.Context, id primitive.ObjectID) (io.ReadCloser, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+func newAttachmentStore(db *database.MongoDB, cfg *config.Config) (attachmentStore, error) {
+	switch cfg.AttachmentStorage {
+	case "", "gridfs":
+		bucket, err := gridfs.NewBucket(db.Database, options.GridFSBucket().SetName("attachments"))
+		if err != nil {
+			return nil, err
+		}
+		return &gridFSAttachmentStore{bucket: bucket}, nil
+	case "local":
+		return &localAttachmentStore{dir: cfg.AttachmentDir}, nil
+	default:
+		return nil, fmt.Errorf("unknown ATTACHMENT_STORAGE %q", cfg.AttachmentStorage)
+	}
+}
+
+// gridFSAttachmentStore keeps contents in MongoDB GridFS, so they are
+// replicated and backed up with the database.
+type gridFSAttachmentStore struct {
+	bucket *gridfs.Bucket
+}
+
+func (s *gridFSAttachmentStore) Put(ctx context.Context, id primitive.ObjectID, name string, r io.Reader) error {
+	return s.bucket.UploadFromStreamWithID(id, name, r)
+}
+
+func (s *gridFSAttachmentStore) Open(ctx context.Context, id primitive.ObjectID) (io.ReadCloser, error) {
+	stream, err := s.bucket.OpenDownloadStream(id)
+	if err == gridfs.ErrFileNotFound {
+		return nil, mongo.ErrNoDocuments
+	}
+	return stream, err
+}
+
+func (s *gridFSAttachmentStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if err := s.bucket.Delete(id); err != nil && err != gridfs.ErrFileNotFound {
+		return err
+	}
+	return nil
+}
+
+// localAttachmentStore keeps contents as files in a directory.
+type localAttachmentStore struct {
+	dir string
+}
+
+func (s *localAttachmentStore) path(id primitive.ObjectID) string {
+	return filepath.Join(s.dir, id.Hex())
+}
+
+func (s *localAttachmentStore) Put(ctx context.Context, id primitive.ObjectID, name string, r io.Reader) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(s.path(id))
+		return err
+	}
+	return f.Close()
+}
+
+func (s *localAttachmentStore) Open(ctx context.Context, id primitive.ObjectID) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, mongo.ErrNoDocuments
+	}
+	return f, err
+}
+
+func (s *localAttachmentStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// AttachmentService keeps files uploaded to tickets: their metadata in
+// MongoDB and their contents in the configured store.
+type AttachmentService struct {
+	db    *database.MongoDB
+	store attachmentStore
+}
+
+func NewAttachmentService(db *database.MongoDB, cfg *config.Config) (*AttachmentService, error) {
+	store, err := newAttachmentStore(db, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &AttachmentService{db: db, store: store}, nil
+}
+
+func (s *AttachmentService) collection() *mongo.Collection {
+	return s.db.GetCollection("ticket_attachments")
+}
+
+// attachmentURL is where an attachment is downloaded from.
+func attachmentURL(a models.TicketAttachment) string {
+	return "/api/tickets/" + a.TicketID.Hex() + "/attachments/" + a.ID.Hex() + "/download"
+}
+
+// Upload stores a file on a ticket. The content type is detected from the
+// content rather than trusted from the client.
+func (s *AttachmentService) Upload(ctx context.Context, ticketID primitive.ObjectID, user models.User, fileName string, size int64, r io.Reader) (models.TicketAttachment, error) {
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return models.TicketAttachment{}, err
+	}
+
+	attachment := models.TicketAttachment{
+		ID:             primitive.NewObjectID(),
+		TicketID:       ticketID,
+		FileName:       cleanAttachmentName(fileName),
+		ContentType:    http.DetectContentType(head),
+		Size:           size,
+		UploadedBy:     user.ID,
+		UploadedByName: user.Name,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.store.Put(ctx, attachment.ID, attachment.FileName, br); err != nil {
+		return models.TicketAttachment{}, err
+	}
+	if _, err := s.collection().InsertOne(ctx, attachment); err != nil {
+		s.store.Delete(ctx, attachment.ID)
+		return models.TicketAttachment{}, err
+	}
+	attachment.URL = attachmentURL(attachment)
+	return attachment, nil
+}
+
+// cleanAttachmentName keeps the base name of an uploaded file, without
+// any client path or characters that would break a Content-Disposition
+// header.
+func cleanAttachmentName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	return truncateBytes(name, 255)
+}
+
+// List returns a ticket's attachments, oldest first.
+func (s *AttachmentService) List(ctx context.Context, ticketID primitive.ObjectID) ([]models.TicketAttachment, error) {
+	cursor, err := s.collection().Find(ctx, bson.M{"ticketId": ticketID}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	attachments := []models.TicketAttachment{}
+	if err := cursor.All(ctx, &attachments); err != nil {
+		return nil, err
+	}
+	for i := range attachments {
+		attachments[i].URL = attachmentURL(attachments[i])
+	}
+	return attachments, nil
+}
+
+// Open returns an attachment of a ticket with its content, which the
+// caller closes.
+func (s *AttachmentService) Open(ctx context.Context, ticketID, id primitive.ObjectID) (models.TicketAttachment, io.ReadCloser, error) {
+	var attachment models.TicketAttachment
+	if err := s.collection().FindOne(ctx, bson.M{"_id": id, "ticketId": ticketID}).Decode(&attachment); err != nil {
+		return models.TicketAttachment{}, nil, err
+	}
+	content, err := s.store.Open(ctx, id)
+	if err != nil {
+		return models.TicketAttachment{}, nil, err
+	}
+	return attachment, content, nil
+}
+
+// Delete removes an attachment. Users can delete what they uploaded;
+// admins can delete any attachment.
+func (s *AttachmentService) Delete(ctx context.Context, ticketID, id primitive.ObjectID, user models.User) error {
+	var attachment models.TicketAttachment
+	if err := s.collection().FindOne(ctx, bson.M{"_id": id, "ticketId": ticketID}).Decode(&attachment); err != nil {
+		return err
+	}
+	if attachment.UploadedBy != user.ID && user.Role != models.RoleAdmin {
+		return ErrAttachmentNotOwned
+	}
+	if _, err := s.collection().DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, id)
+}
+
+// DeleteForTicket removes every attachment of a deleted ticket.
+func (s *AttachmentService) DeleteForTicket(ctx context.Context, ticketID primitive.ObjectID) error {
+	attachments, err := s.List(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		if err := s.store.Delete(ctx, a.ID); err != nil {
+			return err
+		}
+	}
+	_, err = s.collection().DeleteMany(ctx, bson.M{"ticketId": ticketID})
+	return err
+}
@@ -0,0 +1,685 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awscfg "github.com/aws/aws-sdk-go-v2/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var (
+	ErrAutomationDisabled = errors.New("runbook automation is disabled")
+	ErrSelfApproval       = errors.New("automation runs must be approved by someone other than the requester")
+)
+
+// defaultParameterPattern accepts identifier-like values (instance IDs, host
+// names, service names) when a runbook parameter declares no pattern.
+var defaultParameterPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:/@,-]{0,255}$`)
+
+var templateParameter = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// maxAutomationOutput bounds the output kept on runs and tickets; the end of
+// the output is kept since that is where errors usually are.
+const maxAutomationOutput = 16 * 1024
+
+const ssmPollInterval = 3 * time.Second
+
+// AutomationService manages runbooks that suggested solution steps can
+// reference and executes them once a run is approved. Output is stored on
+// the run and on the ticket.
+type AutomationService struct {
+	db          *database.MongoDB
+	enabled     bool
+	awsRegion   string
+	playbookDir string
+	inventory   string
+	timeout     time.Duration
+}
+
+func NewAutomationService(db *database.MongoDB, cfg *config.Config) *AutomationService {
+	return &AutomationService{
+		db:          db,
+		enabled:     cfg.AutomationEnabled,
+		awsRegion:   cfg.AWSRegion,
+		playbookDir: cfg.AnsiblePlaybookDir,
+		inventory:   cfg.AnsibleInventory,
+		timeout:     cfg.AutomationTimeout,
+	}
+}
+
+// ValidateRunbook checks that a runbook has the fields its type needs.
+func ValidateRunbook(rb models.Runbook) error {
+	switch rb.Type {
+	case models.RunbookSSM:
+		if rb.Document == "" || rb.Targets == "" {
+			return fmt.Errorf("ssm runbooks need a document and targets")
+		}
+	case models.RunbookAnsible:
+		if rb.Playbook == "" {
+			return fmt.Errorf("ansible runbooks need a playbook")
+		}
+	case models.RunbookHTTP:
+		if rb.URL == "" {
+			return fmt.Errorf("http runbooks need a url")
+		}
+		if u, err := url.Parse(templateParameter.ReplaceAllString(rb.URL, "x")); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("http runbooks need an http or https url")
+		}
+	default:
+		return fmt.Errorf("type must be ssm, ansible or http")
+	}
+
+	declared := map[string]bool{}
+	for _, p := range rb.Parameters {
+		if p.Name == "" || declared[p.Name] {
+			return fmt.Errorf("parameter names must be unique and non-empty")
+		}
+		declared[p.Name] = true
+		if p.Pattern != "" {
+			if _, err := regexp.Compile(p.Pattern); err != nil {
+				return fmt.Errorf("invalid pattern for parameter %s: %v", p.Name, err)
+			}
+		}
+	}
+	for _, name := range templateNames(rb) {
+		if !declared[name] {
+			return fmt.Errorf("template references undeclared parameter %s", name)
+		}
+	}
+	return nil
+}
+
+// ValidateRunbookParameters checks values against a runbook's declared
+// parameters.
+func ValidateRunbookParameters(rb models.Runbook, params map[string]string) error {
+	declared := map[string]models.RunbookParameter{}
+	for _, p := range rb.Parameters {
+		declared[p.Name] = p
+	}
+	for name := range params {
+		if _, ok := declared[name]; !ok {
+			return fmt.Errorf("unknown parameter %s", name)
+		}
+	}
+	for _, p := range rb.Parameters {
+		value, ok := params[p.Name]
+		if !ok || value == "" {
+			if p.Required {
+				return fmt.Errorf("parameter %s is required", p.Name)
+			}
+			continue
+		}
+		if !parameterMatches(p, value) {
+			return fmt.Errorf("invalid value for parameter %s", p.Name)
+		}
+	}
+	return nil
+}
+
+func parameterMatches(p models.RunbookParameter, value string) bool {
+	if p.Pattern == "" {
+		return defaultParameterPattern.MatchString(value)
+	}
+	re, err := regexp.Compile(p.Pattern)
+	return err == nil && re.MatchString(value)
+}
+
+func templateNames(rb models.Runbook) []string {
+	templates := []string{rb.Targets, rb.Playbook, rb.URL, rb.Body}
+	for _, v := range rb.Headers {
+		templates = append(templates, v)
+	}
+	var names []string
+	for _, t := range templates {
+		for _, m := range templateParameter.FindAllStringSubmatch(t, -1) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// renderTemplate replaces {{name}} placeholders with escaped parameter
+// values; unset parameters render empty.
+func renderTemplate(tmpl string, params map[string]string, escape func(string) string) string {
+	return templateParameter.ReplaceAllStringFunc(tmpl, func(m string) string {
+		return escape(params[templateParameter.FindStringSubmatch(m)[1]])
+	})
+}
+
+func noEscape(s string) string { return s }
+
+func jsonEscape(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
+
+// RunbookCatalog describes the runbooks for a solutions prompt so the model
+// can mark steps as automatable. It is empty when there are no runbooks.
+func RunbookCatalog(runbooks []models.Runbook) string {
+	if len(runbooks) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Automation runbooks: if one of these runbooks performs a step, add to that solution an \"automations\" array entry ")
+	b.WriteString(`{"stepIndex": <0-based step index>, "runbookId": "<id>", "parameters": {"<name>": "<value>"}}` + ". ")
+	b.WriteString("Only use the runbooks and parameters listed and leave out parameter values you cannot tell from the ticket.\n")
+	for _, rb := range runbooks {
+		b.WriteString(fmt.Sprintf("- %s: %s. %s", rb.ID.Hex(), rb.Name, rb.Description))
+		for i, p := range rb.Parameters {
+			if i == 0 {
+				b.WriteString(" Parameters:")
+			}
+			required := ""
+			if p.Required {
+				required = ", required"
+			}
+			b.WriteString(fmt.Sprintf(" %s (%s%s);", p.Name, p.Description, required))
+		}
+		b.WriteString("\n")
+	}
+	return b.String() + "\n"
+}
+
+// FilterAutomations drops automations the model suggested that reference
+// unknown runbooks, steps or parameters, and parameter values that do not
+// validate. Missing values are left for the technician to fill in.
+func FilterAutomations(solution models.SuggestedSolution, runbooks []models.Runbook) []models.StepAutomation {
+	byID := map[string]models.Runbook{}
+	for _, rb := range runbooks {
+		byID[rb.ID.Hex()] = rb
+	}
+
+	var valid []models.StepAutomation
+	for _, a := range solution.Automations {
+		rb, ok := byID[a.RunbookID]
+		if !ok || a.StepIndex < 0 || a.StepIndex >= len(solution.Steps) {
+			continue
+		}
+		params := map[string]string{}
+		for _, p := range rb.Parameters {
+			if value, ok := a.Parameters[p.Name]; ok && parameterMatches(p, value) {
+				params[p.Name] = value
+			}
+		}
+		a.Parameters = params
+		valid = append(valid, a)
+	}
+	return valid
+}
+
+func (s *AutomationService) runbooks() *mongo.Collection {
+	return s.db.GetCollection("runbooks")
+}
+
+func (s *AutomationService) runs() *mongo.Collection {
+	return s.db.GetCollection("automation_runs")
+}
+
+// ListRunbooks returns every registered runbook by name.
+func (s *AutomationService) ListRunbooks(ctx context.Context) ([]models.Runbook, error) {
+	cursor, err := s.runbooks().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{"name", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runbooks := []models.Runbook{}
+	if err := cursor.All(ctx, &runbooks); err != nil {
+		return nil, err
+	}
+	return runbooks, nil
+}
+
+func (s *AutomationService) GetRunbook(ctx context.Context, id primitive.ObjectID) (models.Runbook, error) {
+	var rb models.Runbook
+	err := s.runbooks().FindOne(ctx, bson.M{"_id": id}).Decode(&rb)
+	return rb, err
+}
+
+func (s *AutomationService) CreateRunbook(ctx context.Context, rb models.Runbook, userID primitive.ObjectID) (models.Runbook, error) {
+	rb.ID = primitive.NewObjectID()
+	rb.CreatedBy = userID
+	rb.CreatedAt = time.Now()
+	rb.UpdatedAt = rb.CreatedAt
+	if rb.Parameters == nil {
+		rb.Parameters = []models.RunbookParameter{}
+	}
+	if _, err := s.runbooks().InsertOne(ctx, rb); err != nil {
+		return models.Runbook{}, err
+	}
+	return rb, nil
+}
+
+// UpdateRunbook replaces a runbook's definition. Pending runs keep the
+// parameters they were requested with and are checked again on approval.
+func (s *AutomationService) UpdateRunbook(ctx context.Context, id primitive.ObjectID, rb models.Runbook) (models.Runbook, error) {
+	existing, err := s.GetRunbook(ctx, id)
+	if err != nil {
+		return models.Runbook{}, err
+	}
+	rb.ID = id
+	rb.CreatedBy = existing.CreatedBy
+	rb.CreatedAt = existing.CreatedAt
+	rb.UpdatedAt = time.Now()
+	if rb.Parameters == nil {
+		rb.Parameters = []models.RunbookParameter{}
+	}
+	if _, err := s.runbooks().ReplaceOne(ctx, bson.M{"_id": id}, rb); err != nil {
+		return models.Runbook{}, err
+	}
+	return rb, nil
+}
+
+func (s *AutomationService) DeleteRunbook(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.runbooks().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Request records a pending automation run for a ticket. Parameters must
+// already be validated against the runbook.
+func (s *AutomationService) Request(ctx context.Context, ticketID primitive.ObjectID, rb models.Runbook, req models.RequestAutomationRequest, userID primitive.ObjectID) (models.AutomationRun, error) {
+	run := models.AutomationRun{
+		ID:          primitive.NewObjectID(),
+		TicketID:    ticketID,
+		RunbookID:   rb.ID,
+		RunbookName: rb.Name,
+		Type:        rb.Type,
+		Solution:    strings.TrimSpace(req.Solution),
+		StepIndex:   req.StepIndex,
+		Step:        req.Step,
+		Parameters:  req.Parameters,
+		Status:      models.AutomationPendingApproval,
+		RequestedBy: userID,
+		RequestedAt: time.Now(),
+	}
+	if run.Parameters == nil {
+		run.Parameters = map[string]string{}
+	}
+	if _, err := s.runs().InsertOne(ctx, run); err != nil {
+		return models.AutomationRun{}, err
+	}
+	return run, nil
+}
+
+// ListRuns returns automation runs, newest first, optionally for one ticket
+// or in one status.
+func (s *AutomationService) ListRuns(ctx context.Context, ticketID *primitive.ObjectID, status string) ([]models.AutomationRun, error) {
+	filter := bson.M{}
+	if ticketID != nil {
+		filter["ticketId"] = *ticketID
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{"requestedAt", -1}}).SetLimit(200)
+	cursor, err := s.runs().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runs := []models.AutomationRun{}
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// review moves a pending run to status. The update is conditional on the run
+// still pending so a run is never approved twice.
+func (s *AutomationService) review(ctx context.Context, runID primitive.ObjectID, status models.AutomationStatus, reviewerID primitive.ObjectID, note string) (models.AutomationRun, error) {
+	var run models.AutomationRun
+	if err := s.runs().FindOne(ctx, bson.M{"_id": runID}).Decode(&run); err != nil {
+		return models.AutomationRun{}, err
+	}
+	if run.Status != models.AutomationPendingApproval {
+		return models.AutomationRun{}, fmt.Errorf("automation run is already %s", run.Status)
+	}
+	if status == models.AutomationRunning && run.RequestedBy == reviewerID {
+		return models.AutomationRun{}, ErrSelfApproval
+	}
+
+	now := time.Now()
+	result, err := s.runs().UpdateOne(ctx, bson.M{"_id": runID, "status": models.AutomationPendingApproval}, bson.M{
+		"$set": bson.M{"status": status, "reviewedBy": reviewerID, "reviewedAt": now, "reviewNote": note},
+	})
+	if err != nil {
+		return models.AutomationRun{}, err
+	}
+	if result.ModifiedCount == 0 {
+		return models.AutomationRun{}, fmt.Errorf("automation run was reviewed concurrently")
+	}
+	run.Status = status
+	run.ReviewedBy = &reviewerID
+	run.ReviewedAt = &now
+	run.ReviewNote = note
+	return run, nil
+}
+
+// Approve starts a pending run in the background. The runbook is loaded and
+// the parameters validated again since either may have changed.
+func (s *AutomationService) Approve(ctx context.Context, runID, reviewerID primitive.ObjectID) (models.AutomationRun, error) {
+	if !s.enabled {
+		return models.AutomationRun{}, ErrAutomationDisabled
+	}
+
+	var pending models.AutomationRun
+	if err := s.runs().FindOne(ctx, bson.M{"_id": runID}).Decode(&pending); err != nil {
+		return models.AutomationRun{}, err
+	}
+	rb, err := s.GetRunbook(ctx, pending.RunbookID)
+	if err != nil {
+		return models.AutomationRun{}, fmt.Errorf("runbook no longer exists")
+	}
+	if err := ValidateRunbookParameters(rb, pending.Parameters); err != nil {
+		return models.AutomationRun{}, err
+	}
+
+	run, err := s.review(ctx, runID, models.AutomationRunning, reviewerID, "")
+	if err != nil {
+		return models.AutomationRun{}, err
+	}
+	go s.execute(run, rb)
+	return run, nil
+}
+
+// Reject closes a pending run without executing it.
+func (s *AutomationService) Reject(ctx context.Context, runID, reviewerID primitive.ObjectID, reason string) (models.AutomationRun, error) {
+	return s.review(ctx, runID, models.AutomationRejected, reviewerID, reason)
+}
+
+func (s *AutomationService) execute(run models.AutomationRun, rb models.Runbook) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var output string
+	var err error
+	switch rb.Type {
+	case models.RunbookSSM:
+		output, err = s.runSSM(ctx, rb, run.Parameters)
+	case models.RunbookAnsible:
+		output, err = s.runAnsible(ctx, rb, run.Parameters)
+	case models.RunbookHTTP:
+		output, err = s.runHTTP(ctx, rb, run.Parameters)
+	default:
+		err = fmt.Errorf("unsupported runbook type %s", rb.Type)
+	}
+	if len(output) > maxAutomationOutput {
+		output = "...\n" + output[len(output)-maxAutomationOutput:]
+	}
+
+	now := time.Now()
+	status := models.AutomationSucceeded
+	set := bson.M{"status": status, "output": output, "finishedAt": now}
+	if err != nil {
+		status = models.AutomationFailed
+		set["status"] = status
+		set["error"] = err.Error()
+		log.Printf("Automation run %s (%s) failed: %v", run.ID.Hex(), rb.Name, err)
+	}
+
+	// Executions outlive the request, so results are written with a fresh
+	// context
+	store := context.Background()
+	if _, err := s.runs().UpdateByID(store, run.ID, bson.M{"$set": set}); err != nil {
+		log.Printf("Failed to store automation run %s: %v", run.ID.Hex(), err)
+	}
+
+	result := models.AutomationResult{
+		RunID:       run.ID,
+		RunbookName: rb.Name,
+		Status:      status,
+		Output:      output,
+		FinishedAt:  now,
+	}
+	if err != nil && result.Output == "" {
+		result.Output = err.Error()
+	}
+	_, updateErr := s.db.GetCollection("tickets").UpdateByID(store, run.TicketID, bson.M{
+		"$push": bson.M{"automationResults": result},
+		"$set":  bson.M{"updatedAt": now},
+	})
+	if updateErr != nil {
+		log.Printf("Failed to attach automation output to ticket %s: %v", run.TicketID.Hex(), updateErr)
+	}
+
+	if run.Solution != "" {
+		stepStatus := models.StepSucceeded
+		if status == models.AutomationFailed {
+			stepStatus = models.StepFailed
+		}
+		_, stepErr := recordStepProgress(store, s.db, run.TicketID, models.SolutionStepProgress{
+			Solution:      run.Solution,
+			StepIndex:     run.StepIndex,
+			Step:          run.Step,
+			Status:        stepStatus,
+			Note:          "Executed by runbook " + rb.Name,
+			UpdatedBy:     run.RequestedBy,
+			UpdatedByName: "Automation",
+			UpdatedAt:     now,
+		})
+		if stepErr != nil {
+			log.Printf("Failed to record step progress for automation run %s: %v", run.ID.Hex(), stepErr)
+		}
+	}
+}
+
+// runAnsible runs the playbook with the parameters as extra vars. Values go
+// through argv as JSON, never through a shell.
+func (s *AutomationService) runAnsible(ctx context.Context, rb models.Runbook, params map[string]string) (string, error) {
+	// Cleaning a rooted path keeps the playbook inside the playbook directory
+	playbook := filepath.Join(s.playbookDir, filepath.Clean("/"+renderTemplate(rb.Playbook, params, noEscape)))
+	extraVars, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{playbook, "--extra-vars", string(extraVars)}
+	if s.inventory != "" {
+		args = append(args, "-i", s.inventory)
+	}
+	out, err := exec.CommandContext(ctx, "ansible-playbook", args...).CombinedOutput()
+	return string(out), err
+}
+
+// runHTTP sends the runbook's request with parameters substituted, escaped
+// for the part of the request they appear in.
+func (s *AutomationService) runHTTP(ctx context.Context, rb models.Runbook, params map[string]string) (string, error) {
+	method := strings.ToUpper(rb.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	body := renderTemplate(rb.Body, params, jsonEscape)
+	req, err := http.NewRequestWithContext(ctx, method, renderTemplate(rb.URL, params, url.PathEscape), strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range rb.Headers {
+		req.Header.Set(name, renderTemplate(value, params, noEscape))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	output := fmt.Sprintf("HTTP %d\n%s", resp.StatusCode, respBody)
+	if resp.StatusCode >= 400 {
+		return output, fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+	return output, nil
+}
+
+// runSSM runs the SSM document on the target instances and waits for every
+// invocation to finish. Parameters used in Targets select instances; the
+// rest are passed to the document.
+func (s *AutomationService) runSSM(ctx context.Context, rb models.Runbook, params map[string]string) (string, error) {
+	var instanceIDs []string
+	for _, id := range strings.Split(renderTemplate(rb.Targets, params, noEscape), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			instanceIDs = append(instanceIDs, id)
+		}
+	}
+	if len(instanceIDs) == 0 {
+		return "", fmt.Errorf("no target instances")
+	}
+
+	targetParams := map[string]bool{}
+	for _, m := range templateParameter.FindAllStringSubmatch(rb.Targets, -1) {
+		targetParams[m[1]] = true
+	}
+	documentParams := map[string][]string{}
+	for name, value := range params {
+		if !targetParams[name] && value != "" {
+			documentParams[name] = []string{value}
+		}
+	}
+
+	var sent struct {
+		Command struct {
+			CommandID string `json:"CommandId"`
+		} `json:"Command"`
+	}
+	err := s.ssmCall(ctx, "SendCommand", map[string]interface{}{
+		"DocumentName": rb.Document,
+		"InstanceIds":  instanceIDs,
+		"Parameters":   documentParams,
+		"Comment":      "IntelliOps runbook " + rb.Name,
+	}, &sent)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("timed out waiting for SSM command %s", sent.Command.CommandID)
+		case <-time.After(ssmPollInterval):
+		}
+
+		var listed struct {
+			CommandInvocations []struct {
+				InstanceID     string `json:"InstanceId"`
+				Status         string `json:"Status"`
+				CommandPlugins []struct {
+					Output string `json:"Output"`
+				} `json:"CommandPlugins"`
+			} `json:"CommandInvocations"`
+		}
+		err := s.ssmCall(ctx, "ListCommandInvocations", map[string]interface{}{
+			"CommandId": sent.Command.CommandID,
+			"Details":   true,
+		}, &listed)
+		if err != nil {
+			return "", err
+		}
+		if len(listed.CommandInvocations) < len(instanceIDs) {
+			continue
+		}
+
+		var b strings.Builder
+		done, failed := true, false
+		sort.Slice(listed.CommandInvocations, func(i, j int) bool {
+			return listed.CommandInvocations[i].InstanceID < listed.CommandInvocations[j].InstanceID
+		})
+		for _, inv := range listed.CommandInvocations {
+			switch inv.Status {
+			case "Pending", "InProgress", "Delayed", "Cancelling":
+				done = false
+			case "Success":
+			default:
+				failed = true
+			}
+			b.WriteString(fmt.Sprintf("== %s: %s\n", inv.InstanceID, inv.Status))
+			for _, plugin := range inv.CommandPlugins {
+				b.WriteString(plugin.Output)
+				if !strings.HasSuffix(plugin.Output, "\n") {
+					b.WriteString("\n")
+				}
+			}
+		}
+		if !done {
+			continue
+		}
+		if failed {
+			return b.String(), fmt.Errorf("SSM command %s failed on one or more instances", sent.Command.CommandID)
+		}
+		return b.String(), nil
+	}
+}
+
+// ssmCall invokes an SSM API action with the JSON protocol, signing the
+// request with the default AWS credential chain.
+func (s *AutomationService) ssmCall(ctx context.Context, action string, input, output interface{}) error {
+	awsConfig, err := awscfg.LoadDefaultConfig(ctx, awscfg.WithRegion(s.awsRegion))
+	if err != nil {
+		return err
+	}
+	creds, err := awsConfig.Credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://ssm."+s.awsRegion+".amazonaws.com/", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AmazonSSM."+action)
+
+	hash := sha256.Sum256(payload)
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "ssm", s.awsRegion, time.Now()); err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("SSM %s failed: status %d, body: %s", action, resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, output)
+}
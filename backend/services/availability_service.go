@@ -0,0 +1,439 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	// maxOutOfOffice bounds one period of time off.
+	maxOutOfOffice     = 366 * 24 * time.Hour
+	maxOutOfOfficeNote = 500
+	maxOnCallShift     = 24 * 14
+	onCallRotationID   = "default"
+)
+
+// ErrInvalidAvailability is returned for working hours, time off and
+// rotations that do not validate.
+var ErrInvalidAvailability = errors.New("invalid availability")
+
+func invalidAvailability(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidAvailability, fmt.Sprintf(format, args...))
+}
+
+// AvailabilityService tracks when technicians take tickets: their working
+// hours, their time off and the on-call rotation that covers tickets when
+// everyone who would take them is off.
+type AvailabilityService struct {
+	db *database.MongoDB
+}
+
+func NewAvailabilityService(db *database.MongoDB) *AvailabilityService {
+	return &AvailabilityService{db: db}
+}
+
+func (s *AvailabilityService) outOfOffice() *mongo.Collection {
+	return s.db.GetCollection("out_of_office")
+}
+
+func (s *AvailabilityService) rotations() *mongo.Collection {
+	return s.db.GetCollection("oncall_rotations")
+}
+
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ValidateWorkingHours checks the time zone and every shift.
+func ValidateWorkingHours(hours models.WorkingHours) error {
+	if _, err := time.LoadLocation(hours.TimeZone); err != nil || hours.TimeZone == "" {
+		return invalidAvailability("unknown time zone %q", hours.TimeZone)
+	}
+	for i, shift := range hours.Shifts {
+		if shift.Weekday < time.Sunday || shift.Weekday > time.Saturday {
+			return invalidAvailability("shifts[%d].weekday must be 0 (Sunday) to 6", i)
+		}
+		start, err := parseClock(shift.Start)
+		if err != nil {
+			return invalidAvailability("shifts[%d].start must be a time such as 09:00", i)
+		}
+		end, err := parseClock(shift.End)
+		if err != nil {
+			return invalidAvailability("shifts[%d].end must be a time such as 17:30", i)
+		}
+		if start == end {
+			return invalidAvailability("shifts[%d] is empty", i)
+		}
+	}
+	return nil
+}
+
+// OnShift reports whether t falls in one of the working hours' shifts.
+// Technicians without working hours are always on shift.
+func OnShift(hours *models.WorkingHours, t time.Time) bool {
+	if hours == nil || len(hours.Shifts) == 0 {
+		return true
+	}
+	loc, err := time.LoadLocation(hours.TimeZone)
+	if err != nil {
+		return true
+	}
+	t = t.In(loc)
+	for _, shift := range hours.Shifts {
+		start, err1 := parseClock(shift.Start)
+		end, err2 := parseClock(shift.End)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if end <= start {
+			end += 24 * time.Hour
+		}
+		// A shift past midnight may have started the day before
+		for _, day := range []time.Time{t, t.AddDate(0, 0, -1)} {
+			if day.Weekday() != shift.Weekday {
+				continue
+			}
+			midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+			if !t.Before(midnight.Add(start)) && t.Before(midnight.Add(end)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetWorkingHours replaces a technician's or admin's working hours; nil
+// clears them.
+func (s *AvailabilityService) SetWorkingHours(ctx context.Context, userID primitive.ObjectID, hours *models.WorkingHours) (models.User, error) {
+	update := bson.M{"$unset": bson.M{"workingHours": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	if hours != nil {
+		if err := ValidateWorkingHours(*hours); err != nil {
+			return models.User{}, err
+		}
+		if hours.Shifts == nil {
+			hours.Shifts = []models.WorkingShift{}
+		}
+		update = bson.M{"$set": bson.M{"workingHours": hours, "updatedAt": time.Now()}}
+	}
+	var user models.User
+	err := s.db.GetCollection("users").FindOneAndUpdate(ctx,
+		bson.M{"_id": userID, "role": bson.M{"$in": bson.A{models.RoleTechnician, models.RoleAdmin}}},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	return user, err
+}
+
+// AddOutOfOffice records time off entered by hand.
+func (s *AvailabilityService) AddOutOfOffice(ctx context.Context, userID primitive.ObjectID, req models.OutOfOfficeRequest) (models.OutOfOffice, error) {
+	if !req.End.After(req.Start) {
+		return models.OutOfOffice{}, invalidAvailability("end must be after start")
+	}
+	if req.End.Sub(req.Start) > maxOutOfOffice {
+		return models.OutOfOffice{}, invalidAvailability("time off can be at most a year; add another period")
+	}
+	if req.End.Before(time.Now()) {
+		return models.OutOfOffice{}, invalidAvailability("end is in the past")
+	}
+	note := strings.TrimSpace(req.Note)
+	if len(note) > maxOutOfOfficeNote {
+		return models.OutOfOffice{}, invalidAvailability("note must be at most %d characters", maxOutOfOfficeNote)
+	}
+	count, err := s.db.GetCollection("users").CountDocuments(ctx, bson.M{"_id": userID, "role": bson.M{"$in": bson.A{models.RoleTechnician, models.RoleAdmin}}})
+	if err != nil {
+		return models.OutOfOffice{}, err
+	}
+	if count == 0 {
+		return models.OutOfOffice{}, mongo.ErrNoDocuments
+	}
+
+	period := models.OutOfOffice{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Start:     req.Start,
+		End:       req.End,
+		Note:      note,
+		Source:    models.OutOfOfficeManual,
+		CreatedAt: time.Now(),
+	}
+	if _, err := s.outOfOffice().InsertOne(ctx, period); err != nil {
+		return models.OutOfOffice{}, err
+	}
+	return period, nil
+}
+
+// DeleteOutOfOffice removes time off entered by hand. Periods read from a
+// calendar are removed there.
+func (s *AvailabilityService) DeleteOutOfOffice(ctx context.Context, userID, id primitive.ObjectID) error {
+	result, err := s.outOfOffice().DeleteOne(ctx, bson.M{"_id": id, "userId": userID, "source": models.OutOfOfficeManual})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// ReplaceCalendarOutOfOffice replaces the periods read from one calendar
+// for a user with the ones it has now.
+func (s *AvailabilityService) ReplaceCalendarOutOfOffice(ctx context.Context, userID primitive.ObjectID, source models.OutOfOfficeSource, periods []models.OutOfOffice) error {
+	if _, err := s.outOfOffice().DeleteMany(ctx, bson.M{"userId": userID, "source": source}); err != nil {
+		return err
+	}
+	if len(periods) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(periods))
+	now := time.Now()
+	for i, p := range periods {
+		p.ID = primitive.NewObjectID()
+		p.UserID = userID
+		p.Source = source
+		p.Note = truncateBytes(p.Note, maxOutOfOfficeNote)
+		p.CreatedAt = now
+		docs[i] = p
+	}
+	_, err := s.outOfOffice().InsertMany(ctx, docs)
+	return err
+}
+
+// upcoming returns the current and future time off of the given users, or
+// of everyone when ids is nil, soonest first.
+func (s *AvailabilityService) upcoming(ctx context.Context, ids []primitive.ObjectID, now time.Time) (map[primitive.ObjectID][]models.OutOfOffice, error) {
+	filter := bson.M{"end": bson.M{"$gt": now}}
+	if ids != nil {
+		filter["userId"] = bson.M{"$in": ids}
+	}
+	cursor, err := s.outOfOffice().Find(ctx, filter, options.Find().SetSort(bson.D{{"start", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var periods []models.OutOfOffice
+	if err := cursor.All(ctx, &periods); err != nil {
+		return nil, err
+	}
+	byUser := map[primitive.ObjectID][]models.OutOfOffice{}
+	for _, p := range periods {
+		byUser[p.UserID] = append(byUser[p.UserID], p)
+	}
+	return byUser, nil
+}
+
+func availability(user models.User, periods []models.OutOfOffice, now time.Time) models.TechnicianAvailability {
+	a := models.TechnicianAvailability{
+		UserID:       user.ID,
+		Name:         user.Name,
+		WorkingHours: user.WorkingHours,
+		OutOfOffice:  periods,
+		Available:    true,
+	}
+	if a.OutOfOffice == nil {
+		a.OutOfOffice = []models.OutOfOffice{}
+	}
+	for _, p := range periods {
+		if !now.Before(p.Start) && now.Before(p.End) {
+			a.Available, a.Reason = false, models.UnavailableOutOfOffice
+			return a
+		}
+	}
+	if !OnShift(user.WorkingHours, now) {
+		a.Available, a.Reason = false, models.UnavailableOffShift
+	}
+	return a
+}
+
+// Availability returns one user's availability at now.
+func (s *AvailabilityService) Availability(ctx context.Context, userID primitive.ObjectID, now time.Time) (models.TechnicianAvailability, error) {
+	var user models.User
+	if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		return models.TechnicianAvailability{}, err
+	}
+	periods, err := s.upcoming(ctx, []primitive.ObjectID{userID}, now)
+	if err != nil {
+		return models.TechnicianAvailability{}, err
+	}
+	a := availability(user, periods[userID], now)
+	if shift, err := s.OnCall(ctx, now); err != nil {
+		return models.TechnicianAvailability{}, err
+	} else if shift != nil && shift.UserID == userID {
+		a.OnCall = true
+	}
+	return a, nil
+}
+
+// Overview returns every technician's availability at now and who is on
+// call.
+func (s *AvailabilityService) Overview(ctx context.Context, now time.Time) (models.AvailabilityOverview, error) {
+	cursor, err := s.db.GetCollection("users").Find(ctx, bson.M{"role": models.RoleTechnician},
+		options.Find().SetProjection(bson.M{"name": 1, "workingHours": 1}).SetSort(bson.D{{"name", 1}}))
+	if err != nil {
+		return models.AvailabilityOverview{}, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return models.AvailabilityOverview{}, err
+	}
+	periods, err := s.upcoming(ctx, nil, now)
+	if err != nil {
+		return models.AvailabilityOverview{}, err
+	}
+	overview := models.AvailabilityOverview{Technicians: []models.TechnicianAvailability{}}
+	if overview.OnCall, err = s.OnCall(ctx, now); err != nil {
+		return models.AvailabilityOverview{}, err
+	}
+	for _, u := range users {
+		a := availability(u, periods[u.ID], now)
+		a.OnCall = overview.OnCall != nil && overview.OnCall.UserID == u.ID
+		overview.Technicians = append(overview.Technicians, a)
+	}
+	return overview, nil
+}
+
+// Unavailable returns which of the given technicians are out of office or
+// off shift at now, with the reason.
+func (s *AvailabilityService) Unavailable(ctx context.Context, ids []primitive.ObjectID, now time.Time) (map[primitive.ObjectID]string, error) {
+	unavailable := map[primitive.ObjectID]string{}
+	if len(ids) == 0 {
+		return unavailable, nil
+	}
+	cursor, err := s.db.GetCollection("users").Find(ctx, bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"workingHours": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	periods, err := s.upcoming(ctx, ids, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if a := availability(u, periods[u.ID], now); !a.Available {
+			unavailable[u.ID] = a.Reason
+		}
+	}
+	return unavailable, nil
+}
+
+// Rotation returns the on-call rotation, or nil when none is set up.
+func (s *AvailabilityService) Rotation(ctx context.Context) (*models.OnCallRotation, error) {
+	var rotation models.OnCallRotation
+	err := s.rotations().FindOne(ctx, bson.M{"_id": onCallRotationID}).Decode(&rotation)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rotation, nil
+}
+
+// SetRotation replaces the on-call rotation; an empty list of technicians
+// removes it.
+func (s *AvailabilityService) SetRotation(ctx context.Context, req models.OnCallRotationRequest, updatedBy primitive.ObjectID) (*models.OnCallRotation, error) {
+	if len(req.Technicians) == 0 {
+		_, err := s.rotations().DeleteOne(ctx, bson.M{"_id": onCallRotationID})
+		return nil, err
+	}
+	if req.ShiftHours < 1 || req.ShiftHours > maxOnCallShift {
+		return nil, invalidAvailability("shiftHours must be between 1 and %d", maxOnCallShift)
+	}
+	if req.StartsAt.IsZero() {
+		return nil, invalidAvailability("startsAt is required")
+	}
+	technicians := []primitive.ObjectID{}
+	seen := map[primitive.ObjectID]bool{}
+	for _, hex := range req.Technicians {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return nil, invalidAvailability("invalid technician ID %q", hex)
+		}
+		if !seen[id] {
+			seen[id] = true
+			technicians = append(technicians, id)
+		}
+	}
+	n, err := s.db.GetCollection("users").CountDocuments(ctx, bson.M{"_id": bson.M{"$in": technicians}, "role": models.RoleTechnician})
+	if err != nil {
+		return nil, err
+	}
+	if n != int64(len(technicians)) {
+		return nil, invalidAvailability("technicians must be existing technician users")
+	}
+
+	rotation := models.OnCallRotation{
+		ID:          onCallRotationID,
+		Technicians: technicians,
+		ShiftHours:  req.ShiftHours,
+		StartsAt:    req.StartsAt,
+		UpdatedBy:   updatedBy,
+		UpdatedAt:   time.Now(),
+	}
+	_, err = s.rotations().ReplaceOne(ctx, bson.M{"_id": onCallRotationID}, rotation, options.Replace().SetUpsert(true))
+	return &rotation, err
+}
+
+// OnCall returns who is on call at now. When the technician whose turn it
+// is is out of office, the next one in the rotation who is not covers the
+// shift. It returns nil without a rotation or when everyone in it is away.
+func (s *AvailabilityService) OnCall(ctx context.Context, now time.Time) (*models.OnCallShift, error) {
+	rotation, err := s.Rotation(ctx)
+	if err != nil || rotation == nil || len(rotation.Technicians) == 0 {
+		return nil, err
+	}
+	shift := time.Duration(rotation.ShiftHours) * time.Hour
+	elapsed := now.Sub(rotation.StartsAt)
+	turn := int64(elapsed / shift)
+	if elapsed < 0 {
+		// Before the rotation starts the first shift runs up to it
+		turn = 0
+	}
+	until := rotation.StartsAt.Add(time.Duration(turn+1) * shift)
+
+	periods, err := s.upcoming(ctx, rotation.Technicians, now)
+	if err != nil {
+		return nil, err
+	}
+	n := int64(len(rotation.Technicians))
+	for i := int64(0); i < n; i++ {
+		id := rotation.Technicians[(turn+i)%n]
+		away := false
+		for _, p := range periods[id] {
+			if !now.Before(p.Start) && now.Before(p.End) {
+				away = true
+			}
+		}
+		if away {
+			continue
+		}
+		var user models.User
+		err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": id, "role": models.RoleTechnician},
+			options.FindOne().SetProjection(bson.M{"name": 1})).Decode(&user)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &models.OnCallShift{UserID: id, Name: user.Name, Until: until}, nil
+	}
+	return nil, nil
+}
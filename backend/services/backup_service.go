@@ -0,0 +1,388 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var ErrBackupRunning = errors.New("another backup or restore is in progress")
+
+const backupFormatVersion = 1
+
+// Restore keeps at most this many error messages per collection.
+const maxRestoreErrors = 10
+
+var backupKeyPattern = regexp.MustCompile(`^backup-\d{8}T\d{6}Z\.json\.gz$`)
+
+func validBackupKey(key string) bool {
+	return backupKeyPattern.MatchString(key)
+}
+
+// backupCollection is a collection included in backups. Fields in exclude
+// are left out, and a restore overwriting an existing document only sets
+// the exported fields so the excluded ones survive.
+type backupCollection struct {
+	name    string
+	exclude []string
+}
+
+// backupCollections are the core data a deployment cannot rebuild, in the
+// order they are restored. Documents keep their metadata only: content and
+// chunk embeddings are rebuilt by indexing the files again.
+var backupCollections = []backupCollection{
+	{name: "users"},
+	{name: "org_units"},
+	{name: "taxonomy"},
+	{name: "settings"},
+	{name: "feature_flags"},
+	{name: "policy_rules"},
+	{name: "custom_fields"},
+	{name: "assignment_rules"},
+	{name: "tickets"},
+	{name: "tickets_archive"},
+	{name: "tickets_deleted"},
+	{name: "ticket_comments"},
+	{name: "ticket_worklogs"},
+	{name: "ticket_attachments"},
+	{name: "ticket_messages"},
+	{name: "messaging_subscriptions"},
+	{name: "chat_sessions"},
+	{name: "out_of_office"},
+	{name: "oncall_rotations"},
+	{name: "approval_delegations"},
+	{name: "documents", exclude: []string{"content", "chunks"}},
+	{name: "mon_resources"},
+	{name: "mon_metrics"},
+	{name: "mon_routes"},
+	{name: "mon_metric_templates"},
+	{name: "maintenance_windows"},
+	{name: "alert_sources"},
+	{name: "synthetic_checks"},
+}
+
+// backupFile is the gzipped JSON written to backup storage. Documents are
+// canonical extended JSON so ObjectIDs and dates round-trip.
+type backupFile struct {
+	Version     int                          `json:"version"`
+	CreatedAt   time.Time                    `json:"createdAt"`
+	Consistent  bool                         `json:"consistent"`
+	Collections map[string][]json.RawMessage `json:"collections"`
+}
+
+// BackupService exports the core collections to backup storage and
+// restores them. Only one backup or restore runs at a time.
+type BackupService struct {
+	db    *database.MongoDB
+	store backupStore
+	mu    sync.Mutex
+}
+
+func NewBackupService(ctx context.Context, db *database.MongoDB, cfg *config.Config) (*BackupService, error) {
+	store, err := newBackupStore(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &BackupService{db: db, store: store}, nil
+}
+
+// Backup exports every backup collection and writes it to storage. On a
+// replica set or sharded cluster all collections are read from one
+// snapshot; a standalone server cannot do that and the backup is marked
+// inconsistent.
+func (s *BackupService) Backup(ctx context.Context) (models.Backup, error) {
+	if !s.mu.TryLock() {
+		return models.Backup{}, ErrBackupRunning
+	}
+	defer s.mu.Unlock()
+
+	file := backupFile{Version: backupFormatVersion, CreatedAt: time.Now().UTC()}
+	exportAll := func(ctx context.Context) error {
+		file.Collections = map[string][]json.RawMessage{}
+		for _, c := range backupCollections {
+			docs, err := s.export(ctx, c)
+			if err != nil {
+				return fmt.Errorf("%s: %w", c.name, err)
+			}
+			file.Collections[c.name] = docs
+		}
+		return nil
+	}
+
+	if s.snapshotSupported(ctx) {
+		err := s.withSnapshot(ctx, exportAll)
+		if err == nil {
+			file.Consistent = true
+		} else {
+			log.Printf("Snapshot backup failed, reading collections one by one: %v", err)
+		}
+	}
+	if !file.Consistent {
+		if err := exportAll(ctx); err != nil {
+			return models.Backup{}, err
+		}
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(file); err != nil {
+		return models.Backup{}, err
+	}
+	if err := gz.Close(); err != nil {
+		return models.Backup{}, err
+	}
+
+	backup := models.Backup{
+		Key:        "backup-" + file.CreatedAt.Format("20060102T150405Z") + ".json.gz",
+		SizeBytes:  int64(buf.Len()),
+		CreatedAt:  file.CreatedAt,
+		Counts:     map[string]int{},
+		Consistent: file.Consistent,
+	}
+	for name, docs := range file.Collections {
+		backup.Counts[name] = len(docs)
+	}
+	if err := s.store.Put(ctx, backup.Key, buf.Bytes()); err != nil {
+		return models.Backup{}, err
+	}
+	log.Printf("Backup %s written (%d bytes, consistent: %t)", backup.Key, backup.SizeBytes, backup.Consistent)
+	return backup, nil
+}
+
+// snapshotSupported reports whether the server is a replica set member or
+// a mongos, which snapshot reads need.
+func (s *BackupService) snapshotSupported(ctx context.Context) bool {
+	var hello bson.M
+	if err := s.db.Database.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false
+	}
+	_, replicaSet := hello["setName"]
+	return replicaSet || hello["msg"] == "isdbgrid"
+}
+
+func (s *BackupService) withSnapshot(ctx context.Context, fn func(context.Context) error) error {
+	sess, err := s.db.Client.StartSession(options.Session().SetSnapshot(true))
+	if err != nil {
+		return err
+	}
+	defer sess.EndSession(ctx)
+	return mongo.WithSession(ctx, sess, func(sc mongo.SessionContext) error {
+		return fn(sc)
+	})
+}
+
+func (s *BackupService) export(ctx context.Context, c backupCollection) ([]json.RawMessage, error) {
+	opts := options.Find()
+	if len(c.exclude) > 0 {
+		projection := bson.M{}
+		for _, field := range c.exclude {
+			projection[field] = 0
+		}
+		opts.SetProjection(projection)
+	}
+	cursor, err := s.db.GetCollection(c.name).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	docs := []json.RawMessage{}
+	for cursor.Next(ctx) {
+		data, err := bson.MarshalExtJSON(cursor.Current, true, false)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, data)
+	}
+	return docs, cursor.Err()
+}
+
+// List returns the backups in storage, newest first.
+func (s *BackupService) List(ctx context.Context) ([]models.Backup, error) {
+	return s.store.List(ctx)
+}
+
+// ValidateRestoreRequest checks the key and collections and defaults the
+// conflict mode to skip.
+func ValidateRestoreRequest(req *models.RestoreRequest) error {
+	if !validBackupKey(req.Key) {
+		return errors.New("invalid backup key")
+	}
+	switch req.Conflict {
+	case "":
+		req.Conflict = models.RestoreSkip
+	case models.RestoreSkip, models.RestoreOverwrite:
+	default:
+		return fmt.Errorf("conflict must be %s or %s", models.RestoreSkip, models.RestoreOverwrite)
+	}
+	for _, name := range req.Collections {
+		if findBackupCollection(name) == nil {
+			return fmt.Errorf("collection %s is not part of backups", name)
+		}
+	}
+	return nil
+}
+
+func findBackupCollection(name string) *backupCollection {
+	for i := range backupCollections {
+		if backupCollections[i].name == name {
+			return &backupCollections[i]
+		}
+	}
+	return nil
+}
+
+// Restore loads a backup and writes its documents back by _id. Documents
+// whose _id exists are skipped or overwritten according to the request;
+// documents clashing on another unique index are counted as failed and
+// the restore goes on. A dry run cannot see unique index clashes.
+func (s *BackupService) Restore(ctx context.Context, req models.RestoreRequest) (models.RestoreResult, error) {
+	if !s.mu.TryLock() {
+		return models.RestoreResult{}, ErrBackupRunning
+	}
+	defer s.mu.Unlock()
+
+	data, err := s.store.Get(ctx, req.Key)
+	if err != nil {
+		return models.RestoreResult{}, err
+	}
+	file, err := decodeBackup(data)
+	if err != nil {
+		return models.RestoreResult{}, fmt.Errorf("backup %s: %w", req.Key, err)
+	}
+
+	selected := map[string]bool{}
+	for _, name := range req.Collections {
+		selected[name] = true
+	}
+
+	result := models.RestoreResult{
+		Key:         req.Key,
+		Conflict:    req.Conflict,
+		DryRun:      req.DryRun,
+		Collections: map[string]*models.CollectionRestoreResult{},
+	}
+	for _, c := range backupCollections {
+		docs, ok := file.Collections[c.name]
+		if !ok || (len(selected) > 0 && !selected[c.name]) {
+			continue
+		}
+		res := &models.CollectionRestoreResult{Total: len(docs)}
+		result.Collections[c.name] = res
+		for _, raw := range docs {
+			var doc bson.D
+			if err := bson.UnmarshalExtJSON(raw, true, &doc); err != nil {
+				return result, fmt.Errorf("%s: %w", c.name, err)
+			}
+			if err := s.restoreDocument(ctx, c, doc, req, res); err != nil {
+				return result, fmt.Errorf("%s: %w", c.name, err)
+			}
+		}
+	}
+	if !req.DryRun {
+		log.Printf("Backup %s restored (conflict: %s)", req.Key, req.Conflict)
+	}
+	return result, nil
+}
+
+func (s *BackupService) restoreDocument(ctx context.Context, c backupCollection, doc bson.D, req models.RestoreRequest, res *models.CollectionRestoreResult) error {
+	var id interface{}
+	fields := bson.D{}
+	for _, e := range doc {
+		if e.Key == "_id" {
+			id = e.Value
+		} else {
+			fields = append(fields, e)
+		}
+	}
+	if id == nil {
+		res.Failed++
+		restoreError(res, "document without _id")
+		return nil
+	}
+
+	coll := s.db.GetCollection(c.name)
+	n, err := coll.CountDocuments(ctx, bson.M{"_id": id}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		if !req.DryRun {
+			if _, err := coll.InsertOne(ctx, doc); err != nil {
+				return restoreFailed(res, id, err)
+			}
+		}
+		res.Inserted++
+		return nil
+	}
+	if req.Conflict != models.RestoreOverwrite {
+		res.Skipped++
+		return nil
+	}
+	if !req.DryRun {
+		if len(c.exclude) > 0 {
+			_, err = coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": fields})
+		} else {
+			_, err = coll.ReplaceOne(ctx, bson.M{"_id": id}, doc)
+		}
+		if err != nil {
+			return restoreFailed(res, id, err)
+		}
+	}
+	res.Overwritten++
+	return nil
+}
+
+// restoreFailed counts a duplicate key error against the document and
+// returns any other error.
+func restoreFailed(res *models.CollectionRestoreResult, id interface{}, err error) error {
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	res.Failed++
+	restoreError(res, fmt.Sprintf("%v: %v", id, err))
+	return nil
+}
+
+func restoreError(res *models.CollectionRestoreResult, msg string) {
+	if len(res.Errors) < maxRestoreErrors {
+		res.Errors = append(res.Errors, msg)
+	}
+}
+
+func decodeBackup(data []byte) (backupFile, error) {
+	var file backupFile
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return file, err
+	}
+	defer gz.Close()
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		return file, err
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return file, err
+	}
+	if file.Version != backupFormatVersion {
+		return file, fmt.Errorf("unsupported backup version %d", file.Version)
+	}
+	return file, nil
+}
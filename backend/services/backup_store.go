@@ -0,0 +1,248 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awscfg "github.com/aws/aws-sdk-go-v2/config"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/models"
+)
+
+var ErrBackupNotFound = errors.New("backup not found")
+
+// backupStore keeps backup files by key.
+type backupStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	List(ctx context.Context) ([]models.Backup, error)
+}
+
+func newBackupStore(ctx context.Context, cfg *config.Config) (backupStore, error) {
+	switch cfg.BackupStorage {
+	case "", "local":
+		return &localBackupStore{dir: cfg.BackupDir}, nil
+	case "s3":
+		return newS3BackupStore(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("unknown BACKUP_STORAGE %q", cfg.BackupStorage)
+	}
+}
+
+type localBackupStore struct {
+	dir string
+}
+
+func (s *localBackupStore) Put(ctx context.Context, key string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	// Write to a temporary file first so a failed backup never leaves a
+	// truncated file under the final key.
+	tmp := filepath.Join(s.dir, "."+key+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(s.dir, key))
+}
+
+func (s *localBackupStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBackupNotFound
+	}
+	return data, err
+}
+
+func (s *localBackupStore) List(ctx context.Context) ([]models.Backup, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []models.Backup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	backups := []models.Backup{}
+	for _, e := range entries {
+		if e.IsDir() || !validBackupKey(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, models.Backup{Key: e.Name(), SizeBytes: info.Size(), CreatedAt: info.ModTime()})
+	}
+	sortBackups(backups)
+	return backups, nil
+}
+
+// s3BackupStore talks to S3, or any S3-compatible store, with path-style
+// requests signed with SigV4.
+type s3BackupStore struct {
+	endpoint    string
+	bucket      string
+	prefix      string
+	region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+}
+
+func newS3BackupStore(ctx context.Context, cfg *config.Config) (*s3BackupStore, error) {
+	if cfg.BackupS3Bucket == "" {
+		return nil, errors.New("BACKUP_S3_BUCKET is required when BACKUP_STORAGE is s3")
+	}
+	region := cfg.BackupS3Region
+	if region == "" {
+		region = cfg.AWSRegion
+	}
+	awsConf, err := awscfg.LoadDefaultConfig(ctx, awscfg.WithRegion(region))
+	if err != nil {
+		return nil, err
+	}
+	endpoint := cfg.BackupS3Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	return &s3BackupStore{
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		bucket:      cfg.BackupS3Bucket,
+		prefix:      cfg.BackupS3Prefix,
+		region:      region,
+		credentials: awsConf.Credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+func (s *s3BackupStore) objectURL(key string) string {
+	segments := strings.Split(s.prefix+key, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return s.endpoint + "/" + url.PathEscape(s.bucket) + "/" + strings.Join(segments, "/")
+}
+
+func (s *s3BackupStore) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, s.objectURL(key), data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return s.check(resp, "put "+key)
+}
+
+func (s *s3BackupStore) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrBackupNotFound
+	}
+	if err := s.check(resp, "get "+key); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key          string
+		Size         int64
+		LastModified time.Time
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+func (s *s3BackupStore) List(ctx context.Context) ([]models.Backup, error) {
+	backups := []models.Backup{}
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {s.prefix}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		resp, err := s.do(ctx, http.MethodGet, s.endpoint+"/"+url.PathEscape(s.bucket)+"?"+q.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		var out s3ListResult
+		err = s.check(resp, "list")
+		if err == nil {
+			err = xml.NewDecoder(resp.Body).Decode(&out)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range out.Contents {
+			key := strings.TrimPrefix(obj.Key, s.prefix)
+			if !validBackupKey(key) {
+				continue
+			}
+			backups = append(backups, models.Backup{Key: key, SizeBytes: obj.Size, CreatedAt: obj.LastModified})
+		}
+		if !out.IsTruncated || out.NextContinuationToken == "" {
+			break
+		}
+		token = out.NextContinuationToken
+	}
+	sortBackups(backups)
+	return backups, nil
+}
+
+func (s *s3BackupStore) do(ctx context.Context, method, target string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(hash[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", s.region, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.client.Do(req)
+}
+
+func (s *s3BackupStore) check(resp *http.Response, operation string) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	data, _ := io.ReadAll(resp.Body)
+	return fmt.Errorf("s3 %s returned status %d: %s", operation, resp.StatusCode, truncateBytes(string(data), 300))
+}
+
+// sortBackups puts the newest backup first.
+func sortBackups(backups []models.Backup) {
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Key > backups[j].Key })
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// Point-in-time events, ticket due dates and reminders, are shown as
+// blocks of this length.
+const calendarPointDuration = 30 * time.Minute
+
+// CalendarService collects scheduled work as calendar events and serves
+// the users' ICS feeds.
+type CalendarService struct {
+	db *database.MongoDB
+}
+
+func NewCalendarService(db *database.MongoDB) *CalendarService {
+	return &CalendarService{db: db}
+}
+
+func (s *CalendarService) feeds() *mongo.Collection {
+	return s.db.GetCollection("calendar_feeds")
+}
+
+// Events returns the events overlapping [from, to), by start. Maintenance
+// windows are shared by everyone; with a user, the open tickets assigned to
+// them with a due date and their open reminders are added.
+func (s *CalendarService) Events(ctx context.Context, user *models.User, from, to time.Time) ([]models.CalendarEvent, error) {
+	events := []models.CalendarEvent{}
+
+	cursor, err := s.db.GetCollection("maintenance_windows").Find(ctx, bson.M{
+		"startsAt": bson.M{"$lt": to},
+		"endsAt":   bson.M{"$gt": from},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var windows []models.MaintenanceWindow
+	if err := cursor.All(ctx, &windows); err != nil {
+		return nil, err
+	}
+	for _, w := range windows {
+		events = append(events, models.CalendarEvent{
+			UID:         "maintenance-" + w.ID.Hex(),
+			Kind:        models.CalendarEventMaintenance,
+			Summary:     "Maintenance: " + w.Name,
+			Description: w.Description,
+			Start:       w.StartsAt,
+			End:         w.EndsAt,
+		})
+	}
+
+	if user != nil {
+		cursor, err := s.db.GetCollection("tickets").Find(ctx, bson.M{
+			"assignedTo": user.ID,
+			"status":     bson.M{"$nin": bson.A{models.StatusResolved, models.StatusClosed}},
+			"dueAt":      bson.M{"$gte": from.Add(-calendarPointDuration), "$lt": to},
+		})
+		if err != nil {
+			return nil, err
+		}
+		var tickets []models.Ticket
+		if err := cursor.All(ctx, &tickets); err != nil {
+			return nil, err
+		}
+		for _, t := range tickets {
+			events = append(events, models.CalendarEvent{
+				UID:         "ticket-due-" + t.ID.Hex(),
+				Kind:        models.CalendarEventTicketDue,
+				Summary:     "Due: " + t.Title,
+				Description: fmt.Sprintf("Ticket %s, %s priority, %s", t.ID.Hex(), t.Priority, t.Status),
+				Start:       *t.DueAt,
+				End:         t.DueAt.Add(calendarPointDuration),
+			})
+		}
+
+		cursor, err = s.db.GetCollection("ticket_reminders").Find(ctx, bson.M{
+			"userId":      user.ID,
+			"completedAt": nil,
+			"dueAt":       bson.M{"$gte": from.Add(-calendarPointDuration), "$lt": to},
+		})
+		if err != nil {
+			return nil, err
+		}
+		var reminders []models.TicketReminder
+		if err := cursor.All(ctx, &reminders); err != nil {
+			return nil, err
+		}
+		for _, r := range reminders {
+			events = append(events, models.CalendarEvent{
+				UID:         "reminder-" + r.ID.Hex(),
+				Kind:        models.CalendarEventReminder,
+				Summary:     "Follow up: " + truncateWords(r.Note, 60),
+				Description: fmt.Sprintf("%s\n\nTicket %s", r.Note, r.TicketID.Hex()),
+				Start:       r.DueAt,
+				End:         r.DueAt.Add(calendarPointDuration),
+			})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events, nil
+}
+
+// Feed returns the user's ICS feed, creating it on first use.
+func (s *CalendarService) Feed(ctx context.Context, userID primitive.ObjectID) (models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := s.feeds().FindOne(ctx, bson.M{"_id": userID}).Decode(&feed)
+	if err == mongo.ErrNoDocuments {
+		return s.RotateFeed(ctx, userID)
+	}
+	return feed, err
+}
+
+// RotateFeed gives the user's feed a new token, revoking the old URL.
+func (s *CalendarService) RotateFeed(ctx context.Context, userID primitive.ObjectID) (models.CalendarFeed, error) {
+	token, err := newAlertSourceToken()
+	if err != nil {
+		return models.CalendarFeed{}, err
+	}
+	feed := models.CalendarFeed{UserID: userID, Token: token, CreatedAt: time.Now()}
+	_, err = s.feeds().ReplaceOne(ctx, bson.M{"_id": userID}, feed, options.Replace().SetUpsert(true))
+	if err != nil {
+		return models.CalendarFeed{}, err
+	}
+	return feed, nil
+}
+
+// RevokeFeed removes the user's feed.
+func (s *CalendarService) RevokeFeed(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := s.feeds().DeleteOne(ctx, bson.M{"_id": userID})
+	return err
+}
+
+// FeedUser returns the user a feed token belongs to, or
+// mongo.ErrNoDocuments.
+func (s *CalendarService) FeedUser(ctx context.Context, token string) (models.User, error) {
+	var feed models.CalendarFeed
+	if err := s.feeds().FindOne(ctx, bson.M{"token": token}).Decode(&feed); err != nil {
+		return models.User{}, err
+	}
+	var user models.User
+	err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": feed.UserID}).Decode(&user)
+	return user, err
+}
+
+const icsTimeFormat = "20060102T150405Z"
+
+// RenderICS writes events as an iCalendar (RFC 5545) document.
+func RenderICS(name string, events []models.CalendarEvent) []byte {
+	var b strings.Builder
+	line := func(content string) {
+		b.WriteString(foldICSLine(content))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//IntelliOps AI Copilot//Calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeICSText(name))
+	stamp := time.Now().UTC().Format(icsTimeFormat)
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID + "@intelliops")
+		line("DTSTAMP:" + stamp)
+		line("DTSTART:" + e.Start.UTC().Format(icsTimeFormat))
+		line("DTEND:" + e.End.UTC().Format(icsTimeFormat))
+		line("SUMMARY:" + escapeICSText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeICSText(e.Description))
+		}
+		line("CATEGORIES:" + strings.ToUpper(e.Kind))
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICSText(s string) string {
+	return icsTextEscaper.Replace(s)
+}
+
+// foldICSLine splits lines longer than 75 octets, continuing them on lines
+// starting with a space, without splitting a UTF-8 character.
+func foldICSLine(s string) string {
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines lose one octet to the leading space.
+		limit = 74
+	}
+	b.WriteString(s)
+	return b.String()
+}
@@ -0,0 +1,524 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// errCalendarEventGone is returned by providers when the remote event no
+// longer exists, e.g. because someone deleted it in the calendar app.
+var errCalendarEventGone = errors.New("calendar event not found")
+
+// calendarProvider writes events to an external calendar and reads the
+// time off in users' calendars.
+type calendarProvider interface {
+	Name() string
+	Create(ctx context.Context, event models.CalendarEvent) (string, error)
+	Update(ctx context.Context, eventID string, event models.CalendarEvent) error
+	Delete(ctx context.Context, eventID string) error
+	// OutOfOffice returns the out-of-office events between from and to in
+	// the calendar of the user with the given email. It returns
+	// errCalendarEventGone when the calendar cannot be found or is not
+	// shared.
+	OutOfOffice(ctx context.Context, email string, from, to time.Time) ([]models.OutOfOffice, error)
+}
+
+// calendarRequest sends a JSON request with a bearer token. A 404 or 410
+// response is returned as errCalendarEventGone.
+func calendarRequest(ctx context.Context, client *http.Client, method, endpoint, token string, body interface{}) ([]byte, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	buf.ReadFrom(resp.Body)
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, errCalendarEventGone
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("calendar API returned %d: %s", resp.StatusCode, truncateBytes(strings.TrimSpace(buf.String()), 200))
+	}
+	return buf.Bytes(), nil
+}
+
+// googleCalendar uses the Google Calendar API with a service account the
+// calendar is shared with.
+type googleCalendar struct {
+	account    *googleServiceAccount
+	calendarID string
+	client     *http.Client
+}
+
+func (g *googleCalendar) Name() string {
+	return "google"
+}
+
+func (g *googleCalendar) eventsURL() string {
+	return "https://www.googleapis.com/calendar/v3/calendars/" + url.PathEscape(g.calendarID) + "/events"
+}
+
+func (g *googleCalendar) body(event models.CalendarEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"summary":     event.Summary,
+		"description": event.Description,
+		"start":       map[string]string{"dateTime": event.Start.UTC().Format(time.RFC3339)},
+		"end":         map[string]string{"dateTime": event.End.UTC().Format(time.RFC3339)},
+	}
+}
+
+func (g *googleCalendar) Create(ctx context.Context, event models.CalendarEvent) (string, error) {
+	token, err := g.account.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	data, err := calendarRequest(ctx, g.client, "POST", g.eventsURL(), token, g.body(event))
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (g *googleCalendar) Update(ctx context.Context, eventID string, event models.CalendarEvent) error {
+	token, err := g.account.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = calendarRequest(ctx, g.client, "PUT", g.eventsURL()+"/"+url.PathEscape(eventID), token, g.body(event))
+	return err
+}
+
+func (g *googleCalendar) Delete(ctx context.Context, eventID string) error {
+	token, err := g.account.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = calendarRequest(ctx, g.client, "DELETE", g.eventsURL()+"/"+url.PathEscape(eventID), token, nil)
+	return err
+}
+
+// OutOfOffice reads the user's primary calendar, which must be shared with
+// the service account.
+func (g *googleCalendar) OutOfOffice(ctx context.Context, email string, from, to time.Time) ([]models.OutOfOffice, error) {
+	token, err := g.account.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{
+		"timeMin":      {from.UTC().Format(time.RFC3339)},
+		"timeMax":      {to.UTC().Format(time.RFC3339)},
+		"singleEvents": {"true"},
+		"eventTypes":   {"outOfOffice"},
+		"maxResults":   {"250"},
+	}
+	endpoint := "https://www.googleapis.com/calendar/v3/calendars/" + url.PathEscape(email) + "/events?" + query.Encode()
+	data, err := calendarRequest(ctx, g.client, "GET", endpoint, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	type eventTime struct {
+		DateTime string `json:"dateTime"`
+		// Date is set instead for all-day events
+		Date string `json:"date"`
+	}
+	var list struct {
+		Items []struct {
+			ID     string    `json:"id"`
+			Status string    `json:"status"`
+			Start  eventTime `json:"start"`
+			End    eventTime `json:"end"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	parse := func(t eventTime) (time.Time, error) {
+		if t.DateTime != "" {
+			return time.Parse(time.RFC3339, t.DateTime)
+		}
+		return time.Parse("2006-01-02", t.Date)
+	}
+	periods := []models.OutOfOffice{}
+	for _, item := range list.Items {
+		if item.Status == "cancelled" {
+			continue
+		}
+		start, err1 := parse(item.Start)
+		end, err2 := parse(item.End)
+		if err1 != nil || err2 != nil || !end.After(start) {
+			continue
+		}
+		periods = append(periods, models.OutOfOffice{Start: start, End: end, ExternalID: item.ID})
+	}
+	return periods, nil
+}
+
+// outlookCalendar writes to a mailbox's default calendar through Microsoft
+// Graph with an app-only token.
+type outlookCalendar struct {
+	graph   *graphCredentials
+	mailbox string
+	client  *http.Client
+}
+
+func (o *outlookCalendar) Name() string {
+	return "outlook"
+}
+
+func (o *outlookCalendar) eventsURL() string {
+	return "https://graph.microsoft.com/v1.0/users/" + url.PathEscape(o.mailbox) + "/events"
+}
+
+func (o *outlookCalendar) body(event models.CalendarEvent) map[string]interface{} {
+	const graphTime = "2006-01-02T15:04:05"
+	return map[string]interface{}{
+		"subject": event.Summary,
+		"body":    map[string]string{"contentType": "text", "content": event.Description},
+		"start":   map[string]string{"dateTime": event.Start.UTC().Format(graphTime), "timeZone": "UTC"},
+		"end":     map[string]string{"dateTime": event.End.UTC().Format(graphTime), "timeZone": "UTC"},
+		"showAs":  "busy",
+	}
+}
+
+func (o *outlookCalendar) Create(ctx context.Context, event models.CalendarEvent) (string, error) {
+	token, err := o.graph.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	data, err := calendarRequest(ctx, o.client, "POST", o.eventsURL(), token, o.body(event))
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (o *outlookCalendar) Update(ctx context.Context, eventID string, event models.CalendarEvent) error {
+	token, err := o.graph.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = calendarRequest(ctx, o.client, "PATCH", o.eventsURL()+"/"+url.PathEscape(eventID), token, o.body(event))
+	return err
+}
+
+func (o *outlookCalendar) Delete(ctx context.Context, eventID string) error {
+	token, err := o.graph.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = calendarRequest(ctx, o.client, "DELETE", o.eventsURL()+"/"+url.PathEscape(eventID), token, nil)
+	return err
+}
+
+// OutOfOffice reads the events the user shows as away in their default
+// calendar; the app needs Calendars.Read for the user's mailbox.
+func (o *outlookCalendar) OutOfOffice(ctx context.Context, email string, from, to time.Time) ([]models.OutOfOffice, error) {
+	token, err := o.graph.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{
+		"startDateTime": {from.UTC().Format(time.RFC3339)},
+		"endDateTime":   {to.UTC().Format(time.RFC3339)},
+		"$select":       {"showAs,start,end,isCancelled"},
+		"$top":          {"250"},
+	}
+	endpoint := "https://graph.microsoft.com/v1.0/users/" + url.PathEscape(email) + "/calendarView?" + query.Encode()
+	data, err := calendarRequest(ctx, o.client, "GET", endpoint, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Without a Prefer header Graph returns times in UTC
+	type eventTime struct {
+		DateTime string `json:"dateTime"`
+	}
+	var list struct {
+		Value []struct {
+			ID          string    `json:"id"`
+			ShowAs      string    `json:"showAs"`
+			IsCancelled bool      `json:"isCancelled"`
+			Start       eventTime `json:"start"`
+			End         eventTime `json:"end"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	const graphTime = "2006-01-02T15:04:05.9999999"
+	periods := []models.OutOfOffice{}
+	for _, item := range list.Value {
+		if item.ShowAs != "oof" || item.IsCancelled {
+			continue
+		}
+		start, err1 := time.Parse(graphTime, item.Start.DateTime)
+		end, err2 := time.Parse(graphTime, item.End.DateTime)
+		if err1 != nil || err2 != nil || !end.After(start) {
+			continue
+		}
+		periods = append(periods, models.OutOfOffice{Start: start, End: end, ExternalID: item.ID})
+	}
+	return periods, nil
+}
+
+// How far back and ahead shared events are kept in external calendars.
+const (
+	calendarSyncLookback  = 7 * 24 * time.Hour
+	calendarSyncLookahead = 180 * 24 * time.Hour
+)
+
+// CalendarSyncResult counts the changes one sync made per provider.
+type CalendarSyncResult struct {
+	Provider string `json:"provider"`
+	Created  int    `json:"created"`
+	Updated  int    `json:"updated"`
+	Deleted  int    `json:"deleted"`
+	Failed   int    `json:"failed"`
+	// OutOfOffice is how many periods of technicians' time off were read.
+	OutOfOffice int `json:"outOfOffice"`
+}
+
+// CalendarSyncService copies shared events, the maintenance windows, to
+// Google and Outlook calendars, and keeps them up to date as windows are
+// moved or cancelled. It also reads technicians' time off from the same
+// calendars.
+type CalendarSyncService struct {
+	db           *database.MongoDB
+	calendar     *CalendarService
+	availability *AvailabilityService
+	providers    []calendarProvider
+}
+
+// NewCalendarSyncService enables each calendar whose settings are
+// complete.
+func NewCalendarSyncService(db *database.MongoDB, cfg *config.Config, calendar *CalendarService, availability *AvailabilityService) (*CalendarSyncService, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	s := &CalendarSyncService{db: db, calendar: calendar, availability: availability}
+	if cfg.CalendarGoogleCredentials != "" && cfg.CalendarGoogleCalendarID != "" {
+		account, err := newGoogleServiceAccount(cfg.CalendarGoogleCredentials, "https://www.googleapis.com/auth/calendar", client)
+		if err != nil {
+			return nil, err
+		}
+		s.providers = append(s.providers, &googleCalendar{account: account, calendarID: cfg.CalendarGoogleCalendarID, client: client})
+	}
+	if cfg.CalendarOutlookTenantID != "" && cfg.CalendarOutlookClientID != "" && cfg.CalendarOutlookSecret != "" && cfg.CalendarOutlookMailbox != "" {
+		graph := &graphCredentials{tenantID: cfg.CalendarOutlookTenantID, clientID: cfg.CalendarOutlookClientID, clientSecret: cfg.CalendarOutlookSecret, client: client}
+		s.providers = append(s.providers, &outlookCalendar{graph: graph, mailbox: cfg.CalendarOutlookMailbox, client: client})
+	}
+	return s, nil
+}
+
+// Enabled reports whether any calendar is configured.
+func (s *CalendarSyncService) Enabled() bool {
+	return len(s.providers) > 0
+}
+
+func (s *CalendarSyncService) records() *mongo.Collection {
+	return s.db.GetCollection("calendar_sync")
+}
+
+// Start syncs the calendars every interval.
+func (s *CalendarSyncService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if _, err := s.Sync(ctx); err != nil {
+					log.Printf("calendar sync error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Sync brings every configured calendar in line with the shared events
+// and reads technicians' upcoming time off from them. Only events whose
+// content changed since the last sync are written.
+func (s *CalendarSyncService) Sync(ctx context.Context) ([]CalendarSyncResult, error) {
+	now := time.Now()
+	events, err := s.calendar.Events(ctx, nil, now.Add(-calendarSyncLookback), now.Add(calendarSyncLookahead))
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := s.db.GetCollection("users").Find(ctx, bson.M{"role": models.RoleTechnician},
+		options.Find().SetProjection(bson.M{"email": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var technicians []models.User
+	if err := cursor.All(ctx, &technicians); err != nil {
+		return nil, err
+	}
+
+	results := []CalendarSyncResult{}
+	for _, p := range s.providers {
+		result, err := s.syncProvider(ctx, p, events, now)
+		if err != nil {
+			return results, err
+		}
+		if err := s.syncOutOfOffice(ctx, p, technicians, now, &result); err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// syncOutOfOffice replaces each technician's time off from the provider
+// with what their calendar shows now. Technicians whose calendar cannot be
+// read keep the periods read before.
+func (s *CalendarSyncService) syncOutOfOffice(ctx context.Context, p calendarProvider, technicians []models.User, now time.Time, result *CalendarSyncResult) error {
+	source := models.OutOfOfficeSource(p.Name())
+	for _, t := range technicians {
+		periods, err := p.OutOfOffice(ctx, t.Email, now, now.Add(calendarSyncLookahead))
+		if err == errCalendarEventGone {
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to read time off of %s from %s calendar: %v", t.Email, p.Name(), err)
+			result.Failed++
+			continue
+		}
+		if err := s.availability.ReplaceCalendarOutOfOffice(ctx, t.ID, source, periods); err != nil {
+			return err
+		}
+		result.OutOfOffice += len(periods)
+	}
+	return nil
+}
+
+func (s *CalendarSyncService) syncProvider(ctx context.Context, p calendarProvider, events []models.CalendarEvent, now time.Time) (CalendarSyncResult, error) {
+	result := CalendarSyncResult{Provider: p.Name()}
+
+	cursor, err := s.records().Find(ctx, bson.M{"provider": p.Name()})
+	if err != nil {
+		return result, err
+	}
+	var records []models.CalendarSyncRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return result, err
+	}
+	synced := make(map[string]models.CalendarSyncRecord, len(records))
+	for _, r := range records {
+		synced[r.UID] = r
+	}
+
+	for _, event := range events {
+		hash := calendarEventHash(event)
+		record, ok := synced[event.UID]
+		delete(synced, event.UID)
+		if ok && record.Hash == hash {
+			continue
+		}
+
+		eventID := record.EventID
+		if ok {
+			err = p.Update(ctx, eventID, event)
+			if err == errCalendarEventGone {
+				eventID, err = p.Create(ctx, event)
+			}
+		} else {
+			eventID, err = p.Create(ctx, event)
+		}
+		if err != nil {
+			log.Printf("Failed to sync %s to %s calendar: %v", event.UID, p.Name(), err)
+			result.Failed++
+			continue
+		}
+		if ok {
+			result.Updated++
+		} else {
+			result.Created++
+		}
+
+		record = models.CalendarSyncRecord{
+			ID:       p.Name() + ":" + event.UID,
+			Provider: p.Name(),
+			UID:      event.UID,
+			EventID:  eventID,
+			Hash:     hash,
+			End:      event.End,
+			SyncedAt: now,
+		}
+		if _, err := s.records().ReplaceOne(ctx, bson.M{"_id": record.ID}, record, options.Replace().SetUpsert(true)); err != nil {
+			return result, err
+		}
+	}
+
+	// What is left was cancelled, or has aged out of the sync range. Aged
+	// out events stay in the calendar as history; only the record goes.
+	for _, record := range synced {
+		if record.End.After(now.Add(-calendarSyncLookback)) {
+			if err := p.Delete(ctx, record.EventID); err != nil && err != errCalendarEventGone {
+				log.Printf("Failed to delete %s from %s calendar: %v", record.UID, p.Name(), err)
+				result.Failed++
+				continue
+			}
+			result.Deleted++
+		}
+		if _, err := s.records().DeleteOne(ctx, bson.M{"_id": record.ID}); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func calendarEventHash(event models.CalendarEvent) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		event.Summary,
+		event.Description,
+		event.Start.UTC().Format(time.RFC3339),
+		event.End.UTC().Format(time.RFC3339),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
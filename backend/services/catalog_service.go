@@ -0,0 +1,511 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var (
+	ErrNotServiceRequest      = errors.New("ticket is not a service request")
+	ErrApprovalClosed         = errors.New("service request is no longer awaiting approval")
+	ErrNotApprover            = errors.New("you are not an approver for the current approval step")
+	ErrRequesterApproval      = errors.New("service requests must be approved by someone other than the requester")
+	ErrAwaitingApproval       = errors.New("service request is awaiting approval")
+	ErrFulfillmentStepInvalid = errors.New("fulfillment step does not exist")
+)
+
+// CatalogService manages the service catalog and moves service requests
+// through their approval chain before they are assigned. Approvers may
+// delegate their approvals for a while.
+type CatalogService struct {
+	db            *database.MongoDB
+	taxonomy      *TaxonomyService
+	notifications *NotificationService
+	history       *TicketHistoryService
+	delegations   *ApprovalDelegationService
+}
+
+func NewCatalogService(db *database.MongoDB, taxonomy *TaxonomyService, notifications *NotificationService, history *TicketHistoryService, delegations *ApprovalDelegationService) *CatalogService {
+	return &CatalogService{db: db, taxonomy: taxonomy, notifications: notifications, history: history, delegations: delegations}
+}
+
+func (s *CatalogService) collection() *mongo.Collection {
+	return s.db.GetCollection("catalog_items")
+}
+
+// ValidateCatalogItem checks the item's category, approval steps and
+// referenced users.
+func (s *CatalogService) ValidateCatalogItem(ctx context.Context, req models.CatalogItemRequest) error {
+	if req.Category != "" && FindCategory(s.taxonomy.Get(ctx), req.Category) == nil {
+		return fmt.Errorf("unknown category %q", req.Category)
+	}
+	users := []primitive.ObjectID{}
+	for i, step := range req.Approvals {
+		if strings.TrimSpace(step.Name) == "" {
+			return fmt.Errorf("approvals[%d].name is required", i)
+		}
+		if step.MinCost < 0 {
+			return fmt.Errorf("approvals[%d].minCost must not be negative", i)
+		}
+		users = append(users, step.ApproverIDs...)
+	}
+	for i, step := range req.FulfillmentSteps {
+		if strings.TrimSpace(step) == "" {
+			return fmt.Errorf("fulfillmentSteps[%d] is empty", i)
+		}
+	}
+	if req.AssignTo != nil {
+		users = append(users, *req.AssignTo)
+	}
+	for _, id := range users {
+		count, err := s.db.GetCollection("users").CountDocuments(ctx, bson.M{"_id": id})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("user %s does not exist", id.Hex())
+		}
+	}
+	return nil
+}
+
+// ListItems returns catalog items by name; inactive items only for staff.
+func (s *CatalogService) ListItems(ctx context.Context, includeInactive bool) ([]models.CatalogItem, error) {
+	filter := bson.M{}
+	if !includeInactive {
+		filter["active"] = true
+	}
+	cursor, err := s.collection().Find(ctx, filter, options.Find().SetSort(bson.D{{"name", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []models.CatalogItem{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *CatalogService) GetItem(ctx context.Context, id primitive.ObjectID) (models.CatalogItem, error) {
+	var item models.CatalogItem
+	err := s.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&item)
+	return item, err
+}
+
+func (s *CatalogService) CreateItem(ctx context.Context, req models.CatalogItemRequest, createdBy primitive.ObjectID) (models.CatalogItem, error) {
+	item := models.CatalogItem{
+		ID:               primitive.NewObjectID(),
+		Name:             req.Name,
+		Description:      req.Description,
+		Category:         req.Category,
+		Cost:             req.Cost,
+		Currency:         req.Currency,
+		Active:           req.Active == nil || *req.Active,
+		Approvals:        req.Approvals,
+		FulfillmentSteps: req.FulfillmentSteps,
+		AssignTo:         req.AssignTo,
+		CreatedBy:        createdBy,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	if item.Approvals == nil {
+		item.Approvals = []models.ApprovalRequirement{}
+	}
+	if item.FulfillmentSteps == nil {
+		item.FulfillmentSteps = []string{}
+	}
+	if _, err := s.collection().InsertOne(ctx, item); err != nil {
+		return models.CatalogItem{}, err
+	}
+	return item, nil
+}
+
+// UpdateItem changes an item; requests already raised keep the approval
+// chain they started with.
+func (s *CatalogService) UpdateItem(ctx context.Context, id primitive.ObjectID, req models.CatalogItemRequest) (models.CatalogItem, error) {
+	approvals := req.Approvals
+	if approvals == nil {
+		approvals = []models.ApprovalRequirement{}
+	}
+	steps := req.FulfillmentSteps
+	if steps == nil {
+		steps = []string{}
+	}
+	set := bson.M{
+		"name":             req.Name,
+		"description":      req.Description,
+		"category":         req.Category,
+		"cost":             req.Cost,
+		"currency":         req.Currency,
+		"approvals":        approvals,
+		"fulfillmentSteps": steps,
+		"assignTo":         req.AssignTo,
+		"updatedAt":        time.Now(),
+	}
+	if req.Active != nil {
+		set["active"] = *req.Active
+	}
+
+	var item models.CatalogItem
+	err := s.collection().FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&item)
+	return item, err
+}
+
+func (s *CatalogService) DeleteItem(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Request raises a service request ticket for a catalog item. Approval
+// steps whose MinCost exceeds the request's cost are skipped; a request
+// without steps is approved and assigned straight away.
+func (s *CatalogService) Request(ctx context.Context, item models.CatalogItem, req models.CatalogRequestRequest, user models.User) (models.Ticket, error) {
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	total := item.Cost * float64(quantity)
+
+	request := &models.ServiceRequest{
+		CatalogItemID:    item.ID,
+		ItemName:         item.Name,
+		Quantity:         quantity,
+		TotalCost:        total,
+		Currency:         item.Currency,
+		Justification:    req.Justification,
+		ApprovalStatus:   models.ApprovalPending,
+		Approvals:        []models.ApprovalStep{},
+		FulfillmentSteps: []models.FulfillmentStep{},
+		AssignTo:         item.AssignTo,
+	}
+	for _, step := range item.Approvals {
+		if total < step.MinCost {
+			continue
+		}
+		request.Approvals = append(request.Approvals, models.ApprovalStep{
+			Name:        step.Name,
+			ApproverIDs: step.ApproverIDs,
+			Status:      models.ApprovalPending,
+		})
+	}
+	for _, title := range item.FulfillmentSteps {
+		request.FulfillmentSteps = append(request.FulfillmentSteps, models.FulfillmentStep{Title: title})
+	}
+
+	taxonomy := s.taxonomy.Get(ctx)
+	category := item.Category
+	if category == "" || FindCategory(taxonomy, category) == nil {
+		category = DefaultCategory(taxonomy)
+	}
+	description := fmt.Sprintf("Service request for %d x %s (%.2f %s).", quantity, item.Name, total, item.Currency)
+	if req.Justification != "" {
+		description += "\n\nJustification: " + req.Justification
+	}
+
+	now := time.Now()
+	ticket := models.Ticket{
+		ID:             primitive.NewObjectID(),
+		Title:          "Request: " + item.Name,
+		Description:    description,
+		Category:       category,
+		Priority:       DefaultPriority(taxonomy),
+		Status:         models.StatusOpen,
+		Type:           models.TicketTypeServiceRequest,
+		ServiceRequest: request,
+		Department:     user.Department,
+		Location:       user.Location,
+		CreatedBy:      user.ID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if len(request.Approvals) == 0 {
+		request.ApprovalStatus = models.ApprovalApproved
+		ticket.AssignedTo = item.AssignTo
+	}
+	if _, err := s.db.GetCollection("tickets").InsertOne(ctx, ticket); err != nil {
+		return models.Ticket{}, err
+	}
+
+	if len(request.Approvals) > 0 {
+		s.notifyApprovers(ctx, ticket, request.Approvals[0])
+	}
+	return ticket, nil
+}
+
+// currentStep returns the index of the first undecided approval step.
+func currentStep(request *models.ServiceRequest) int {
+	for i, step := range request.Approvals {
+		if step.Status == models.ApprovalPending {
+			return i
+		}
+	}
+	return -1
+}
+
+// canApprove reports whether a user may decide an approval step. Steps
+// without named approvers are decided by admins.
+func canApprove(step models.ApprovalStep, user models.User) bool {
+	if len(step.ApproverIDs) == 0 {
+		return user.Role == models.RoleAdmin
+	}
+	for _, id := range step.ApproverIDs {
+		if id == user.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// PendingApprovals returns the service requests whose current approval
+// step the user may decide, themselves or for an approver who delegated to
+// them.
+func (s *CatalogService) PendingApprovals(ctx context.Context, user models.User) ([]models.Ticket, error) {
+	delegators, err := s.delegations.Delegators(ctx, user.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := s.db.GetCollection("tickets").Find(ctx, bson.M{
+		"type":                          models.TicketTypeServiceRequest,
+		"serviceRequest.approvalStatus": models.ApprovalPending,
+	}, options.Find().SetSort(bson.D{{"createdAt", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tickets []models.Ticket
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return nil, err
+	}
+
+	pending := []models.Ticket{}
+	for _, ticket := range tickets {
+		i := currentStep(ticket.ServiceRequest)
+		if i < 0 || ticket.CreatedBy == user.ID {
+			continue
+		}
+		if ok, _ := approverFor(ticket.ServiceRequest.Approvals[i], user, delegators, ticket.CreatedBy); ok {
+			pending = append(pending, ticket)
+		}
+	}
+	return pending, nil
+}
+
+// Decide approves or rejects the current approval step of a request. A
+// rejection closes the ticket; approving the last step assigns it. A
+// delegate's decision records the approver they stood in for too.
+func (s *CatalogService) Decide(ctx context.Context, ticketID primitive.ObjectID, user models.User, req models.ApprovalDecisionRequest) (models.Ticket, error) {
+	tickets := s.db.GetCollection("tickets")
+	var ticket models.Ticket
+	if err := tickets.FindOne(ctx, bson.M{"_id": ticketID}).Decode(&ticket); err != nil {
+		return models.Ticket{}, err
+	}
+	request := ticket.ServiceRequest
+	if request == nil {
+		return models.Ticket{}, ErrNotServiceRequest
+	}
+	i := currentStep(request)
+	if request.ApprovalStatus != models.ApprovalPending || i < 0 {
+		return models.Ticket{}, ErrApprovalClosed
+	}
+	if ticket.CreatedBy == user.ID {
+		return models.Ticket{}, ErrRequesterApproval
+	}
+	now := time.Now()
+	delegators, err := s.delegations.Delegators(ctx, user.ID, now)
+	if err != nil {
+		return models.Ticket{}, err
+	}
+	ok, onBehalfOf := approverFor(request.Approvals[i], user, delegators, ticket.CreatedBy)
+	if !ok {
+		return models.Ticket{}, ErrNotApprover
+	}
+
+	prefix := fmt.Sprintf("serviceRequest.approvals.%d.", i)
+	set := bson.M{
+		prefix + "decidedBy": user.ID,
+		prefix + "decidedAt": now,
+		prefix + "comment":   req.Comment,
+		"updatedAt":          now,
+	}
+	if onBehalfOf != nil {
+		set[prefix+"onBehalfOf"] = onBehalfOf.ID
+	}
+	update := bson.M{"$set": set}
+	last := i == len(request.Approvals)-1
+	switch {
+	case !req.Approve:
+		// Rejection closes the request, which waits for its sub-tasks
+		if err := CheckSubtasksDone(ctx, s.db, ticket, models.StatusClosed); err != nil {
+			return models.Ticket{}, err
+		}
+		set[prefix+"status"] = models.ApprovalRejected
+		set["serviceRequest.approvalStatus"] = models.ApprovalRejected
+		// Requests awaiting approval are still open, since they cannot be
+		// worked on yet, so closing them is a valid move
+		ApplyStatusTransition(update, ticket, models.StatusClosed, now)
+	case last:
+		set[prefix+"status"] = models.ApprovalApproved
+		set["serviceRequest.approvalStatus"] = models.ApprovalApproved
+		if request.AssignTo != nil {
+			set["assignedTo"] = *request.AssignTo
+		}
+	default:
+		set[prefix+"status"] = models.ApprovalApproved
+	}
+
+	// The step must still be undecided so concurrent decisions cannot both
+	// apply
+	err = tickets.FindOneAndUpdate(ctx,
+		bson.M{"_id": ticketID, prefix + "status": models.ApprovalPending},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&ticket)
+	if err == mongo.ErrNoDocuments {
+		return models.Ticket{}, ErrApprovalClosed
+	}
+	if err != nil {
+		return models.Ticket{}, err
+	}
+
+	if err := s.history.RecordOnBehalf(ctx, ticketID, user, onBehalfOf, []models.TicketFieldChange{{
+		Field: prefix + "status",
+		Old:   string(models.ApprovalPending),
+		New:   string(ticket.ServiceRequest.Approvals[i].Status),
+	}}); err != nil {
+		log.Printf("Failed to record history of ticket %s: %v", ticketID.Hex(), err)
+	}
+
+	switch {
+	case !req.Approve || last:
+		s.notifyRequester(ctx, ticket)
+	default:
+		s.notifyApprovers(ctx, ticket, ticket.ServiceRequest.Approvals[i+1])
+	}
+	return ticket, nil
+}
+
+// UpdateFulfillment ticks off a fulfillment step of an approved request.
+func (s *CatalogService) UpdateFulfillment(ctx context.Context, ticketID primitive.ObjectID, req models.UpdateFulfillmentStepRequest, userID primitive.ObjectID) (models.Ticket, error) {
+	tickets := s.db.GetCollection("tickets")
+	var ticket models.Ticket
+	if err := tickets.FindOne(ctx, bson.M{"_id": ticketID}).Decode(&ticket); err != nil {
+		return models.Ticket{}, err
+	}
+	if ticket.ServiceRequest == nil {
+		return models.Ticket{}, ErrNotServiceRequest
+	}
+	if ticket.ServiceRequest.ApprovalStatus != models.ApprovalApproved {
+		return models.Ticket{}, ErrAwaitingApproval
+	}
+	if req.StepIndex >= len(ticket.ServiceRequest.FulfillmentSteps) {
+		return models.Ticket{}, ErrFulfillmentStepInvalid
+	}
+
+	prefix := fmt.Sprintf("serviceRequest.fulfillmentSteps.%d.", req.StepIndex)
+	update := bson.M{"$set": bson.M{prefix + "done": false, "updatedAt": time.Now()},
+		"$unset": bson.M{prefix + "doneBy": "", prefix + "doneAt": ""}}
+	if req.Done {
+		update = bson.M{"$set": bson.M{prefix + "done": true, prefix + "doneBy": userID, prefix + "doneAt": time.Now(), "updatedAt": time.Now()}}
+	}
+	err := tickets.FindOneAndUpdate(ctx, bson.M{"_id": ticketID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&ticket)
+	return ticket, err
+}
+
+// ServiceRequestGate returns ErrAwaitingApproval when a ticket is a service
+// request that has not been approved yet, so it cannot be assigned or
+// worked on.
+func ServiceRequestGate(ticket models.Ticket) error {
+	if ticket.ServiceRequest != nil && ticket.ServiceRequest.ApprovalStatus != models.ApprovalApproved {
+		return ErrAwaitingApproval
+	}
+	return nil
+}
+
+func (s *CatalogService) notifyApprovers(ctx context.Context, ticket models.Ticket, step models.ApprovalStep) {
+	filter := bson.M{"_id": bson.M{"$in": step.ApproverIDs}}
+	if len(step.ApproverIDs) == 0 {
+		filter = bson.M{"role": models.RoleAdmin}
+	}
+	emails, err := s.emails(ctx, filter)
+	if err != nil {
+		log.Printf("Failed to look up approvers for ticket %s: %v", ticket.ID.Hex(), err)
+		return
+	}
+	// Delegates are told as well, since the approvers may be away
+	delegates, err := s.delegations.Delegates(ctx, filter, time.Now())
+	if err != nil {
+		log.Printf("Failed to look up approval delegates for ticket %s: %v", ticket.ID.Hex(), err)
+	} else if len(delegates) > 0 {
+		more, err := s.emails(ctx, bson.M{"_id": bson.M{"$in": delegates}, "email": bson.M{"$nin": emails}})
+		if err != nil {
+			log.Printf("Failed to look up approval delegates for ticket %s: %v", ticket.ID.Hex(), err)
+		}
+		emails = append(emails, more...)
+	}
+
+	request := ticket.ServiceRequest
+	if _, err := s.notifications.Send(ctx, Notification{
+		Subject: "Approval needed: " + ticket.Title,
+		Body: fmt.Sprintf("A service request needs your approval (%s).\n\nItem: %d x %s\nCost: %.2f %s\nJustification: %s\nTicket: %s",
+			step.Name, request.Quantity, request.ItemName, request.TotalCost, request.Currency, request.Justification, ticket.ID.Hex()),
+		Recipients: emails,
+	}); err != nil {
+		log.Printf("Failed to notify approvers for ticket %s: %v", ticket.ID.Hex(), err)
+	}
+}
+
+func (s *CatalogService) notifyRequester(ctx context.Context, ticket models.Ticket) {
+	emails, err := s.emails(ctx, bson.M{"_id": ticket.CreatedBy})
+	if err != nil {
+		log.Printf("Failed to look up requester for ticket %s: %v", ticket.ID.Hex(), err)
+		return
+	}
+
+	if _, err := s.notifications.Send(ctx, Notification{
+		Subject:    fmt.Sprintf("Service request %s: %s", ticket.ServiceRequest.ApprovalStatus, ticket.Title),
+		Body:       fmt.Sprintf("Your request for %s has been %s.\nTicket: %s", ticket.ServiceRequest.ItemName, ticket.ServiceRequest.ApprovalStatus, ticket.ID.Hex()),
+		Recipients: emails,
+	}); err != nil {
+		log.Printf("Failed to notify requester for ticket %s: %v", ticket.ID.Hex(), err)
+	}
+}
+
+func (s *CatalogService) emails(ctx context.Context, filter bson.M) ([]string, error) {
+	cursor, err := s.db.GetCollection("users").Find(ctx, filter, options.Find().SetProjection(bson.M{"email": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	emails := []string{}
+	for _, u := range users {
+		emails = append(emails, u.Email)
+	}
+	return emails, nil
+}
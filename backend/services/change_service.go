@@ -0,0 +1,475 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var (
+	ErrNotChange               = errors.New("ticket is not a change")
+	ErrChangeInvalid           = errors.New("invalid change")
+	ErrChangeApprovalClosed    = errors.New("change is no longer awaiting approval")
+	ErrChangeRequesterApproval = errors.New("changes must be approved by someone other than the requester")
+	ErrChangeAwaitingApproval  = errors.New("change is awaiting approval")
+	ErrChangeConflicts         = errors.New("change conflicts with its schedule")
+)
+
+// changeApprovalStep is the approval a change goes through; it has no named
+// approvers, so admins decide it.
+const changeApprovalStep = "Change approval"
+
+// ChangeService raises and schedules change tickets, and checks their
+// schedule against maintenance windows and other changes before approval.
+type ChangeService struct {
+	db          *database.MongoDB
+	taxonomy    *TaxonomyService
+	history     *TicketHistoryService
+	delegations *ApprovalDelegationService
+}
+
+func NewChangeService(db *database.MongoDB, taxonomy *TaxonomyService, history *TicketHistoryService, delegations *ApprovalDelegationService) *ChangeService {
+	return &ChangeService{db: db, taxonomy: taxonomy, history: history, delegations: delegations}
+}
+
+func (s *ChangeService) tickets() *mongo.Collection {
+	return s.db.GetCollection("tickets")
+}
+
+// Get returns a change ticket, or ErrNotChange for other tickets.
+func (s *ChangeService) Get(ctx context.Context, id primitive.ObjectID) (models.Ticket, error) {
+	var ticket models.Ticket
+	if err := s.tickets().FindOne(ctx, bson.M{"_id": id}).Decode(&ticket); err != nil {
+		return models.Ticket{}, err
+	}
+	if ticket.Change == nil {
+		return models.Ticket{}, ErrNotChange
+	}
+	return ticket, nil
+}
+
+// resources checks the planned times and that every resource ID is a
+// monitored resource.
+func (s *ChangeService) resources(ctx context.Context, start, end time.Time, ids []string) ([]primitive.ObjectID, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("%w: plannedEnd must be after plannedStart", ErrChangeInvalid)
+	}
+	resourceIDs := []primitive.ObjectID{}
+	for i, hex := range ids {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: resourceIds[%d] is not a valid ID", ErrChangeInvalid, i)
+		}
+		resourceIDs = append(resourceIDs, id)
+	}
+	resourceIDs = uniqueObjectIDs(resourceIDs)
+	if len(resourceIDs) == 0 {
+		return resourceIDs, nil
+	}
+	n, err := s.db.GetCollection("mon_resources").CountDocuments(ctx, bson.M{"_id": bson.M{"$in": resourceIDs}})
+	if err != nil {
+		return nil, err
+	}
+	if int(n) != len(resourceIDs) {
+		return nil, fmt.Errorf("%w: resourceIds must be monitored resources", ErrChangeInvalid)
+	}
+	return resourceIDs, nil
+}
+
+func uniqueObjectIDs(ids []primitive.ObjectID) []primitive.ObjectID {
+	seen := map[primitive.ObjectID]bool{}
+	unique := []primitive.ObjectID{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// Create raises a change ticket awaiting approval.
+func (s *ChangeService) Create(ctx context.Context, req models.CreateChangeRequest, user models.User) (models.Ticket, error) {
+	if !req.PlannedStart.After(time.Now()) {
+		return models.Ticket{}, fmt.Errorf("%w: plannedStart must be in the future", ErrChangeInvalid)
+	}
+	resourceIDs, err := s.resources(ctx, req.PlannedStart, req.PlannedEnd, req.ResourceIDs)
+	if err != nil {
+		return models.Ticket{}, err
+	}
+	if err := s.taxonomy.ValidateTicketFields(ctx, req.Category, "", nil, req.Priority); err != nil {
+		return models.Ticket{}, fmt.Errorf("%w: %s", ErrChangeInvalid, err.Error())
+	}
+	taxonomy := s.taxonomy.Get(ctx)
+	category, priority := req.Category, req.Priority
+	if category == "" {
+		category = DefaultCategory(taxonomy)
+	}
+	if priority == "" {
+		priority = DefaultPriority(taxonomy)
+	}
+
+	now := time.Now()
+	ticket := models.Ticket{
+		ID:          primitive.NewObjectID(),
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+		Category:    category,
+		Priority:    priority,
+		Status:      models.StatusOpen,
+		Type:        models.TicketTypeChange,
+		Change: &models.ChangeRequest{
+			PlannedStart:   req.PlannedStart,
+			PlannedEnd:     req.PlannedEnd,
+			ResourceIDs:    resourceIDs,
+			RollbackPlan:   req.RollbackPlan,
+			ApprovalStatus: models.ApprovalPending,
+			Approval:       models.ApprovalStep{Name: changeApprovalStep, Status: models.ApprovalPending},
+		},
+		Department: user.Department,
+		Location:   user.Location,
+		CreatedBy:  user.ID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if _, err := s.tickets().InsertOne(ctx, ticket); err != nil {
+		return models.Ticket{}, err
+	}
+	return ticket, nil
+}
+
+// List returns the changes planned to run at some point between from and
+// to, either of which may be zero, by planned start.
+func (s *ChangeService) List(ctx context.Context, from, to time.Time) ([]models.Ticket, error) {
+	// Sub-tasks of a change are typed as changes but are not scheduled
+	filter := bson.M{"type": models.TicketTypeChange, "change": bson.M{"$ne": nil}}
+	if !from.IsZero() {
+		filter["change.plannedEnd"] = bson.M{"$gt": from}
+	}
+	if !to.IsZero() {
+		filter["change.plannedStart"] = bson.M{"$lt": to}
+	}
+	cursor, err := s.tickets().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "change.plannedStart", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tickets := []models.Ticket{}
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+// Reschedule moves an open change. An approved change goes back for
+// approval, since it was approved for the old schedule.
+func (s *ChangeService) Reschedule(ctx context.Context, id primitive.ObjectID, req models.ScheduleChangeRequest, user models.User) (models.Ticket, error) {
+	ticket, err := s.Get(ctx, id)
+	if err != nil {
+		return models.Ticket{}, err
+	}
+	if ticket.Status != models.StatusOpen {
+		return models.Ticket{}, fmt.Errorf("%w: only open changes can be rescheduled", ErrChangeInvalid)
+	}
+	ids := make([]string, len(ticket.Change.ResourceIDs))
+	for i, id := range ticket.Change.ResourceIDs {
+		ids[i] = id.Hex()
+	}
+	if req.ResourceIDs != nil {
+		ids = *req.ResourceIDs
+	}
+	resourceIDs, err := s.resources(ctx, req.PlannedStart, req.PlannedEnd, ids)
+	if err != nil {
+		return models.Ticket{}, err
+	}
+
+	old := schedule(ticket.Change.PlannedStart, ticket.Change.PlannedEnd)
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"change.plannedStart": req.PlannedStart,
+		"change.plannedEnd":   req.PlannedEnd,
+		"change.resourceIds":  resourceIDs,
+		"updatedAt":           now,
+	}}
+	if ticket.Change.ApprovalStatus == models.ApprovalApproved {
+		set := update["$set"].(bson.M)
+		set["change.approvalStatus"] = models.ApprovalPending
+		set["change.approval.status"] = models.ApprovalPending
+		update["$unset"] = bson.M{"change.approval.decidedBy": "", "change.approval.onBehalfOf": "", "change.approval.decidedAt": "", "change.approval.comment": ""}
+	}
+	if err := s.tickets().FindOneAndUpdate(ctx, bson.M{"_id": id, "status": models.StatusOpen}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&ticket); err != nil {
+		return models.Ticket{}, err
+	}
+
+	s.record(ctx, id, user, models.TicketFieldChange{Field: "change.schedule", Old: old, New: schedule(req.PlannedStart, req.PlannedEnd)})
+	return ticket, nil
+}
+
+func schedule(start, end time.Time) string {
+	return start.UTC().Format(time.RFC3339) + " - " + end.UTC().Format(time.RFC3339)
+}
+
+func sharedResources(ids, others []primitive.ObjectID) []primitive.ObjectID {
+	shared := []primitive.ObjectID{}
+	for _, id := range ids {
+		for _, other := range others {
+			if id == other {
+				shared = append(shared, id)
+				break
+			}
+		}
+	}
+	return shared
+}
+
+// Conflicts checks a change's schedule: the changes it comes after must
+// end before it starts and those it comes before must start after it ends,
+// and no maintenance window or other live change may touch the same
+// resources while it runs.
+func (s *ChangeService) Conflicts(ctx context.Context, ticket models.Ticket) ([]models.ScheduleConflict, error) {
+	change := ticket.Change
+	if change == nil {
+		return nil, ErrNotChange
+	}
+	conflicts := []models.ScheduleConflict{}
+
+	dependencies, err := s.dependencyConflicts(ctx, ticket)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, dependencies...)
+
+	if len(change.ResourceIDs) == 0 {
+		return conflicts, nil
+	}
+
+	// Maintenance windows select resources by ID or by tags
+	cursor, err := s.db.GetCollection("mon_resources").Find(ctx, bson.M{"_id": bson.M{"$in": change.ResourceIDs}},
+		options.Find().SetProjection(bson.M{"tags": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var resources []models.MonitoredResource
+	if err := cursor.All(ctx, &resources); err != nil {
+		return nil, err
+	}
+	tags := map[primitive.ObjectID]map[string]string{}
+	for _, r := range resources {
+		tags[r.ID] = r.Tags
+	}
+	cursor, err = s.db.GetCollection("maintenance_windows").Find(ctx, bson.M{
+		"startsAt": bson.M{"$lt": change.PlannedEnd},
+		"endsAt":   bson.M{"$gt": change.PlannedStart},
+	}, options.Find().SetSort(bson.D{{Key: "startsAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var windows []models.MaintenanceWindow
+	if err := cursor.All(ctx, &windows); err != nil {
+		return nil, err
+	}
+	for _, w := range windows {
+		covered := []primitive.ObjectID{}
+		for _, id := range change.ResourceIDs {
+			if len(sharedResources([]primitive.ObjectID{id}, w.ResourceIDs)) > 0 || (len(w.Tags) > 0 && TagsMatch(w.Tags, tags[id])) {
+				covered = append(covered, id)
+			}
+		}
+		if len(covered) == 0 {
+			continue
+		}
+		id, startsAt, endsAt := w.ID, w.StartsAt, w.EndsAt
+		conflicts = append(conflicts, models.ScheduleConflict{
+			Kind:        models.ConflictMaintenanceWindow,
+			WindowID:    &id,
+			Title:       w.Name,
+			StartsAt:    &startsAt,
+			EndsAt:      &endsAt,
+			ResourceIDs: covered,
+			Message:     fmt.Sprintf("Maintenance window %q covers %d of the change's resources while it runs", w.Name, len(covered)),
+		})
+	}
+
+	cursor, err = s.tickets().Find(ctx, bson.M{
+		"_id":                 bson.M{"$ne": ticket.ID},
+		"type":                models.TicketTypeChange,
+		"status":              bson.M{"$in": liveStatuses()},
+		"change.resourceIds":  bson.M{"$in": change.ResourceIDs},
+		"change.plannedStart": bson.M{"$lt": change.PlannedEnd},
+		"change.plannedEnd":   bson.M{"$gt": change.PlannedStart},
+	}, options.Find().SetSort(bson.D{{Key: "change.plannedStart", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var others []models.Ticket
+	if err := cursor.All(ctx, &others); err != nil {
+		return nil, err
+	}
+	for _, other := range others {
+		id, startsAt, endsAt := other.ID, other.Change.PlannedStart, other.Change.PlannedEnd
+		shared := sharedResources(change.ResourceIDs, other.Change.ResourceIDs)
+		conflicts = append(conflicts, models.ScheduleConflict{
+			Kind:        models.ConflictChange,
+			TicketID:    &id,
+			Title:       other.Title,
+			StartsAt:    &startsAt,
+			EndsAt:      &endsAt,
+			ResourceIDs: shared,
+			Message:     fmt.Sprintf("Change %q touches %d of the same resources at the same time", other.Title, len(shared)),
+		})
+	}
+	return conflicts, nil
+}
+
+func (s *ChangeService) dependencyConflicts(ctx context.Context, ticket models.Ticket) ([]models.ScheduleConflict, error) {
+	var ids []primitive.ObjectID
+	kinds := map[primitive.ObjectID]models.TicketLinkType{}
+	for _, l := range ticket.Links {
+		if l.Type == models.TicketLinkAfter || l.Type == models.TicketLinkBefore {
+			ids = append(ids, l.TicketID)
+			kinds[l.TicketID] = l.Type
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	cursor, err := s.tickets().Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var linked []models.Ticket
+	if err := cursor.All(ctx, &linked); err != nil {
+		return nil, err
+	}
+
+	change := ticket.Change
+	conflicts := []models.ScheduleConflict{}
+	for _, other := range linked {
+		if other.Change == nil {
+			continue
+		}
+		id, startsAt, endsAt := other.ID, other.Change.PlannedStart, other.Change.PlannedEnd
+		conflict := models.ScheduleConflict{Kind: models.ConflictDependency, TicketID: &id, Title: other.Title, StartsAt: &startsAt, EndsAt: &endsAt}
+		switch {
+		case kinds[other.ID] == models.TicketLinkAfter && other.Change.ApprovalStatus == models.ApprovalRejected:
+			conflict.Message = fmt.Sprintf("Change %q it comes after was rejected", other.Title)
+		case kinds[other.ID] == models.TicketLinkAfter && other.Status != models.StatusResolved && other.Status != models.StatusClosed &&
+			endsAt.After(change.PlannedStart):
+			conflict.Message = fmt.Sprintf("Change %q it comes after is planned to end after it starts", other.Title)
+		case kinds[other.ID] == models.TicketLinkBefore && (other.Status == models.StatusOpen || other.Status == models.StatusInProgress) &&
+			startsAt.Before(change.PlannedEnd):
+			conflict.Message = fmt.Sprintf("Change %q it comes before is planned to start before it ends", other.Title)
+		default:
+			continue
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, nil
+}
+
+// Decide approves or rejects a change. Approval is refused with
+// ErrChangeConflicts, along with the conflicts, while its schedule has any;
+// a rejection closes the ticket. Delegates decide for the admins who
+// delegated to them.
+func (s *ChangeService) Decide(ctx context.Context, id primitive.ObjectID, user models.User, req models.ApprovalDecisionRequest) (models.Ticket, []models.ScheduleConflict, error) {
+	ticket, err := s.Get(ctx, id)
+	if err != nil {
+		return models.Ticket{}, nil, err
+	}
+	change := ticket.Change
+	if change.ApprovalStatus != models.ApprovalPending || ticket.Status != models.StatusOpen {
+		return models.Ticket{}, nil, ErrChangeApprovalClosed
+	}
+	if ticket.CreatedBy == user.ID {
+		return models.Ticket{}, nil, ErrChangeRequesterApproval
+	}
+	now := time.Now()
+	delegators, err := s.delegations.Delegators(ctx, user.ID, now)
+	if err != nil {
+		return models.Ticket{}, nil, err
+	}
+	ok, onBehalfOf := approverFor(change.Approval, user, delegators, ticket.CreatedBy)
+	if !ok {
+		return models.Ticket{}, nil, ErrNotApprover
+	}
+	if req.Approve {
+		conflicts, err := s.Conflicts(ctx, ticket)
+		if err != nil {
+			return models.Ticket{}, nil, err
+		}
+		if len(conflicts) > 0 {
+			return models.Ticket{}, conflicts, ErrChangeConflicts
+		}
+	}
+
+	status := models.ApprovalApproved
+	set := bson.M{
+		"change.approval.decidedBy": user.ID,
+		"change.approval.decidedAt": now,
+		"change.approval.comment":   req.Comment,
+		"updatedAt":                 now,
+	}
+	if onBehalfOf != nil {
+		set["change.approval.onBehalfOf"] = onBehalfOf.ID
+	}
+	update := bson.M{"$set": set}
+	if !req.Approve {
+		status = models.ApprovalRejected
+		if err := MoveTicketStatus(ctx, s.db, update, ticket, models.StatusClosed, now); err != nil {
+			return models.Ticket{}, nil, err
+		}
+	}
+	set["change.approval.status"] = status
+	set["change.approvalStatus"] = status
+
+	// The schedule checked above must still be the one approved, so a
+	// concurrent reschedule or decision cannot slip through
+	err = s.tickets().FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "change.approvalStatus": models.ApprovalPending,
+			"change.plannedStart": change.PlannedStart, "change.plannedEnd": change.PlannedEnd},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&ticket)
+	if err == mongo.ErrNoDocuments {
+		return models.Ticket{}, nil, ErrChangeApprovalClosed
+	}
+	if err != nil {
+		return models.Ticket{}, nil, err
+	}
+
+	if err := s.history.RecordOnBehalf(ctx, id, user, onBehalfOf, []models.TicketFieldChange{{
+		Field: "change.approvalStatus",
+		Old:   string(models.ApprovalPending),
+		New:   string(status),
+	}}); err != nil {
+		log.Printf("Failed to record history of ticket %s: %v", id.Hex(), err)
+	}
+	return ticket, nil, nil
+}
+
+// ChangeGate returns ErrChangeAwaitingApproval when a ticket is a change
+// that has not been approved yet, so it cannot be assigned or worked on.
+func ChangeGate(ticket models.Ticket) error {
+	if ticket.Change != nil && ticket.Change.ApprovalStatus != models.ApprovalApproved {
+		return ErrChangeAwaitingApproval
+	}
+	return nil
+}
+
+func (s *ChangeService) record(ctx context.Context, ticketID primitive.ObjectID, user models.User, change models.TicketFieldChange) {
+	if err := s.history.Record(ctx, ticketID, user, []models.TicketFieldChange{change}); err != nil {
+		log.Printf("Failed to record history of ticket %s: %v", ticketID.Hex(), err)
+	}
+}
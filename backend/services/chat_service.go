@@ -0,0 +1,492 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const chatSystemPrompt = "You are the support chat assistant of an IT service desk, talking to an employee. Answer only from the knowledge base articles provided; when they do not cover the question, say so and offer to hand the conversation over to a technician. Always respond with valid JSON." + untrustedContentPolicy
+
+const (
+	// maxChatMessages bounds a session, counting both sides.
+	maxChatMessages      = 40
+	maxChatMessageLength = 2000
+	// chatIdleTimeout is how long an anonymous session can be picked up
+	// again after its last message.
+	chatIdleTimeout = 24 * time.Hour
+	// chatContextMessages is how much of the conversation the LLM sees.
+	chatContextMessages = 8
+	// chatQueryMessages is how many of the visitor's messages make up the
+	// knowledge base search.
+	chatQueryMessages = 3
+	chatArticles      = 3
+	chatArticleBytes  = 3000
+	// chatVisitorEmail is the account tickets from anonymous visitors are
+	// raised by. It has no password, so nobody can sign in as it.
+	chatVisitorEmail = "chat-visitor@system.invalid"
+)
+
+var (
+	// ErrInvalidChat is returned for chat requests that do not validate.
+	ErrInvalidChat = errors.New("invalid chat request")
+	// ErrChatClosed is returned for sessions that were escalated, closed or
+	// left idle.
+	ErrChatClosed = errors.New("chat session is no longer active")
+)
+
+func invalidChat(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidChat, fmt.Sprintf(format, args...))
+}
+
+// ChatService runs the embeddable support chat. Visitor messages are
+// answered from the knowledge base first; when that does not help, the
+// session is escalated into a ticket carrying the transcript.
+type ChatService struct {
+	db         *database.MongoDB
+	kb         *KBService
+	llm        *LLMService
+	guardrails *GuardrailService
+	parser     *TicketParseService
+	taxonomy   *TaxonomyService
+	org        *OrgService
+	assignment *AssignmentService
+}
+
+func NewChatService(db *database.MongoDB, kb *KBService, llm *LLMService, guardrails *GuardrailService, parser *TicketParseService, taxonomy *TaxonomyService, org *OrgService, assignment *AssignmentService) *ChatService {
+	return &ChatService{
+		db:         db,
+		kb:         kb,
+		llm:        llm,
+		guardrails: guardrails,
+		parser:     parser,
+		taxonomy:   taxonomy,
+		org:        org,
+		assignment: assignment,
+	}
+}
+
+func (s *ChatService) collection() *mongo.Collection {
+	return s.db.GetCollection("chat_sessions")
+}
+
+func hashChatToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func validateChatEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return invalidChat("invalid email address")
+	}
+	return nil
+}
+
+// Start opens a session. Sessions of signed-in users belong to them;
+// anonymous sessions get a token, returned only here, that the visitor
+// sends with every later request.
+func (s *ChatService) Start(ctx context.Context, user *models.User, req models.StartChatRequest) (models.ChatSession, string, error) {
+	now := time.Now()
+	session := models.ChatSession{
+		ID:        primitive.NewObjectID(),
+		PageURL:   truncateBytes(strings.TrimSpace(req.PageURL), 500),
+		Status:    models.ChatActive,
+		Messages:  []models.ChatMessage{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	var token string
+	if user != nil {
+		session.UserID = &user.ID
+		session.Contact = models.ChatContact{Name: user.Name, Email: user.Email}
+	} else {
+		session.Contact = models.ChatContact{
+			Name:  truncateBytes(strings.TrimSpace(req.Name), 100),
+			Email: strings.TrimSpace(req.Email),
+		}
+		if err := validateChatEmail(session.Contact.Email); err != nil {
+			return models.ChatSession{}, "", err
+		}
+		var err error
+		if token, err = newAlertSourceToken(); err != nil {
+			return models.ChatSession{}, "", err
+		}
+		session.TokenHash = hashChatToken(token)
+	}
+
+	if _, err := s.collection().InsertOne(ctx, session); err != nil {
+		return models.ChatSession{}, "", err
+	}
+	return session, token, nil
+}
+
+// Open returns a session to its owner: the signed-in user who started it,
+// or the anonymous visitor holding its token. Anyone else gets
+// mongo.ErrNoDocuments.
+func (s *ChatService) Open(ctx context.Context, id primitive.ObjectID, user *models.User, token string) (models.ChatSession, error) {
+	var session models.ChatSession
+	if err := s.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&session); err != nil {
+		return models.ChatSession{}, err
+	}
+	if session.UserID != nil {
+		if user == nil || *session.UserID != user.ID {
+			return models.ChatSession{}, mongo.ErrNoDocuments
+		}
+		return session, nil
+	}
+	if token == "" || subtle.ConstantTimeCompare([]byte(hashChatToken(token)), []byte(session.TokenHash)) != 1 {
+		return models.ChatSession{}, mongo.ErrNoDocuments
+	}
+	return session, nil
+}
+
+func (s *ChatService) active(session models.ChatSession) error {
+	if session.Status != models.ChatActive {
+		return ErrChatClosed
+	}
+	if session.UserID == nil && time.Since(session.UpdatedAt) > chatIdleTimeout {
+		return ErrChatClosed
+	}
+	return nil
+}
+
+// appendMessages adds messages to an active session, returning
+// ErrChatClosed when it was escalated or closed in the meantime.
+func (s *ChatService) appendMessages(ctx context.Context, id primitive.ObjectID, set bson.M, messages ...models.ChatMessage) error {
+	if set == nil {
+		set = bson.M{}
+	}
+	set["updatedAt"] = time.Now()
+	result, err := s.collection().UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.ChatActive},
+		bson.M{"$push": bson.M{"messages": bson.M{"$each": messages}}, "$set": set},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrChatClosed
+	}
+	return nil
+}
+
+// Send records a visitor message and answers it from the knowledge base.
+// Visitors who are not staff are only answered from public articles.
+func (s *ChatService) Send(ctx context.Context, session models.ChatSession, user *models.User, body string) (models.ChatReply, error) {
+	if err := s.active(session); err != nil {
+		return models.ChatReply{}, err
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return models.ChatReply{}, invalidChat("message is empty")
+	}
+	if len(body) > maxChatMessageLength {
+		return models.ChatReply{}, invalidChat("message must be at most %d characters", maxChatMessageLength)
+	}
+	if len(session.Messages)+2 > maxChatMessages {
+		return models.ChatReply{}, invalidChat("the conversation is too long; hand it over to a technician instead")
+	}
+
+	message := models.ChatMessage{ID: primitive.NewObjectID(), Role: models.ChatRoleVisitor, Body: body, CreatedAt: time.Now()}
+	session.Messages = append(session.Messages, message)
+	publicOnly := user == nil || user.Role == models.RoleRequester
+	reply, escalate := s.answer(ctx, session, publicOnly)
+
+	if err := s.appendMessages(ctx, session.ID, nil, message, reply); err != nil {
+		return models.ChatReply{}, err
+	}
+	return models.ChatReply{Message: message, Reply: reply, SuggestEscalation: escalate}, nil
+}
+
+// answer drafts the assistant's reply to the last visitor message and
+// whether to offer a technician. The search covers the visitor's recent
+// messages so follow-ups keep their context.
+func (s *ChatService) answer(ctx context.Context, session models.ChatSession, publicOnly bool) (models.ChatMessage, bool) {
+	visitor := []string{}
+	for i := len(session.Messages) - 1; i >= 0 && len(visitor) < chatQueryMessages; i-- {
+		if session.Messages[i].Role == models.ChatRoleVisitor {
+			visitor = append([]string{session.Messages[i].Body}, visitor...)
+		}
+	}
+	articles, err := s.kb.Suggest(ctx, strings.Join(visitor, " "), chatArticles, publicOnly)
+	if err != nil {
+		log.Printf("Failed to search articles for chat %s: %v", session.ID.Hex(), err)
+		articles = []models.ArticleSuggestion{}
+	}
+
+	reply := models.ChatMessage{ID: primitive.NewObjectID(), Role: models.ChatRoleAssistant, Articles: articles}
+	body, escalate, err := s.generate(ctx, session, articles, publicOnly)
+	if err != nil {
+		if err != ErrLLMUnavailable {
+			log.Printf("Chat answer failed, using fallback: %v", err)
+		}
+		body, escalate = mockChatAnswer(articles)
+		reply.Fallback = true
+	}
+	reply.Body = body
+	reply.CreatedAt = time.Now()
+	return reply, escalate
+}
+
+func (s *ChatService) generate(ctx context.Context, session models.ChatSession, articles []models.ArticleSuggestion, publicOnly bool) (string, bool, error) {
+	var sources strings.Builder
+	for i, a := range articles {
+		_, version, err := s.kb.Published(ctx, a.ID, publicOnly)
+		if err != nil {
+			continue
+		}
+		sources.WriteString(fmt.Sprintf("Article %d: %s\n%s\n\n", i+1, version.Title, truncateBytes(version.Body, chatArticleBytes)))
+	}
+	if sources.Len() == 0 {
+		sources.WriteString("No articles matched.\n")
+	}
+
+	messages := session.Messages
+	if len(messages) > chatContextMessages {
+		messages = messages[len(messages)-chatContextMessages:]
+	}
+	prompt := fmt.Sprintf(`Reply to the last message of this support chat.
+
+Conversation:
+%s
+
+Knowledge base articles:
+%s
+Respond with a JSON object containing:
+- answer: your reply to the employee, a few short paragraphs or steps at most, citing articles by title
+- escalate: true if the articles do not solve the problem or the employee asks for a person`,
+		s.guardrails.Wrap(ctx, "support_chat", session.ID.Hex(), "transcript", chatTranscript(messages)),
+		s.guardrails.Wrap(ctx, "support_chat", session.ID.Hex(), "articles", sources.String()))
+
+	content, err := s.llm.Complete(ctx, CompletionRequest{
+		System:   chatSystemPrompt,
+		Prompt:   prompt,
+		Endpoint: EndpointChat,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	var result struct {
+		Answer   string `json:"answer"`
+		Escalate bool   `json:"escalate"`
+	}
+	if err := json.Unmarshal([]byte(ExtractJSON(content)), &result); err != nil {
+		return "", false, fmt.Errorf("failed to parse chat response: %v", err)
+	}
+	answer := strings.TrimSpace(result.Answer)
+	if answer == "" {
+		return "", false, fmt.Errorf("chat response missing answer")
+	}
+	return answer, result.Escalate, nil
+}
+
+// mockChatAnswer points the visitor at the matching articles, or offers a
+// technician when there are none.
+func mockChatAnswer(articles []models.ArticleSuggestion) (string, bool) {
+	if len(articles) == 0 {
+		return "I couldn't find anything in the knowledge base about this. I can hand the conversation over to a technician, who will follow up with you.", true
+	}
+	var b strings.Builder
+	b.WriteString("These articles may help:\n")
+	for _, a := range articles {
+		b.WriteString(fmt.Sprintf("- %s: %s\n", a.Title, a.Excerpt))
+	}
+	b.WriteString("If they don't solve the problem, I can hand the conversation over to a technician.")
+	return b.String(), false
+}
+
+// chatTranscript renders messages one per line with the speaker.
+func chatTranscript(messages []models.ChatMessage) string {
+	var b strings.Builder
+	for _, m := range messages {
+		speaker := "Visitor"
+		switch m.Role {
+		case models.ChatRoleAssistant:
+			speaker = "Assistant"
+		case models.ChatRoleSystem:
+			continue
+		}
+		b.WriteString(speaker + ": " + strings.ReplaceAll(m.Body, "\n", "\n  ") + "\n")
+	}
+	return b.String()
+}
+
+// visitorUser returns the chat visitor account, creating it the first time
+// an anonymous visitor escalates.
+func (s *ChatService) visitorUser(ctx context.Context) (models.User, error) {
+	now := time.Now()
+	var visitor models.User
+	err := s.db.GetCollection("users").FindOneAndUpdate(ctx,
+		bson.M{"email": chatVisitorEmail},
+		bson.M{"$setOnInsert": bson.M{
+			"name":      "Support chat visitor",
+			"email":     chatVisitorEmail,
+			"password":  "",
+			"role":      models.RoleRequester,
+			"createdAt": now,
+			"updatedAt": now,
+		}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&visitor)
+	return visitor, err
+}
+
+// Escalate turns the session into a ticket. The visitor's messages are
+// parsed into the ticket's title, category and priority, and the whole
+// transcript is kept in its description. Tickets from anonymous visitors
+// are raised by the chat visitor account, with the visitor's own details
+// in the ticket's chat contact.
+func (s *ChatService) Escalate(ctx context.Context, session models.ChatSession, user *models.User, req models.EscalateChatRequest) (models.ChatSession, error) {
+	if err := s.active(session); err != nil {
+		return models.ChatSession{}, err
+	}
+	visitor := []string{}
+	for _, m := range session.Messages {
+		if m.Role == models.ChatRoleVisitor {
+			visitor = append(visitor, m.Body)
+		}
+	}
+	note := strings.TrimSpace(req.Note)
+	if len(note) > maxChatMessageLength {
+		return models.ChatSession{}, invalidChat("note must be at most %d characters", maxChatMessageLength)
+	}
+	if note != "" {
+		visitor = append(visitor, note)
+	}
+	if len(visitor) == 0 {
+		return models.ChatSession{}, invalidChat("describe the problem before handing it over")
+	}
+
+	requester := models.User{}
+	var err error
+	if user != nil {
+		requester = *user
+	} else {
+		if name := strings.TrimSpace(req.Name); name != "" {
+			session.Contact.Name = truncateBytes(name, 100)
+		}
+		if email := strings.TrimSpace(req.Email); email != "" {
+			session.Contact.Email = email
+		}
+		if session.Contact.Email == "" {
+			return models.ChatSession{}, invalidChat("an email address is required so a technician can reach you")
+		}
+		if err := validateChatEmail(session.Contact.Email); err != nil {
+			return models.ChatSession{}, err
+		}
+		if requester, err = s.visitorUser(ctx); err != nil {
+			return models.ChatSession{}, err
+		}
+	}
+
+	parsed, err := s.parser.Parse(ctx, strings.Join(visitor, "\n"))
+	if err != nil {
+		return models.ChatSession{}, err
+	}
+	taxonomy := s.taxonomy.Get(ctx)
+	if parsed.Ticket.Category == "" {
+		parsed.Ticket.Category = DefaultCategory(taxonomy)
+	}
+	if parsed.Ticket.Priority == "" {
+		parsed.Ticket.Priority = DefaultPriority(taxonomy)
+	}
+
+	description := parsed.Ticket.Description
+	if note != "" && !strings.Contains(description, note) {
+		description += "\n\nNote from the visitor: " + note
+	}
+	description += "\n\nSupport chat transcript:\n" + chatTranscript(session.Messages)
+
+	now := time.Now()
+	ticket := models.Ticket{
+		ID:            primitive.NewObjectID(),
+		Title:         parsed.Ticket.Title,
+		Description:   description,
+		Category:      parsed.Ticket.Category,
+		Subcategory:   parsed.Ticket.Subcategory,
+		Priority:      parsed.Ticket.Priority,
+		Status:        models.StatusOpen,
+		CreatedBy:     requester.ID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		ChatSessionID: &session.ID,
+	}
+	if user == nil {
+		contact := session.Contact
+		ticket.ChatContact = &contact
+	} else if err := s.org.RouteTicket(ctx, &ticket, requester); err != nil {
+		log.Printf("Failed to route ticket %s: %v", ticket.ID.Hex(), err)
+	}
+	if err := s.assignment.Assign(ctx, &ticket); err != nil {
+		log.Printf("Failed to auto-assign ticket %s: %v", ticket.ID.Hex(), err)
+	}
+
+	// Claim the session first so it is only escalated once
+	notice := models.ChatMessage{
+		ID:        primitive.NewObjectID(),
+		Role:      models.ChatRoleSystem,
+		Body:      fmt.Sprintf("Handed over to the service desk as ticket #%s. A technician will follow up.", MessagingTicketRef(ticket.ID)),
+		CreatedAt: now,
+	}
+	err = s.appendMessages(ctx, session.ID, bson.M{
+		"status":   models.ChatEscalated,
+		"ticketId": ticket.ID,
+		"contact":  session.Contact,
+	}, notice)
+	if err != nil {
+		return models.ChatSession{}, err
+	}
+	if _, err := s.db.GetCollection("tickets").InsertOne(ctx, ticket); err != nil {
+		s.collection().UpdateByID(ctx, session.ID, bson.M{
+			"$set":   bson.M{"status": models.ChatActive},
+			"$unset": bson.M{"ticketId": ""},
+			"$pull":  bson.M{"messages": bson.M{"_id": notice.ID}},
+		})
+		return models.ChatSession{}, err
+	}
+
+	session.Status = models.ChatEscalated
+	session.TicketID = &ticket.ID
+	session.Messages = append(session.Messages, notice)
+	session.UpdatedAt = now
+	return session, nil
+}
+
+// Close ends an active session without a ticket.
+func (s *ChatService) Close(ctx context.Context, session models.ChatSession) (models.ChatSession, error) {
+	if session.Status != models.ChatActive {
+		return models.ChatSession{}, ErrChatClosed
+	}
+	now := time.Now()
+	result, err := s.collection().UpdateOne(ctx,
+		bson.M{"_id": session.ID, "status": models.ChatActive},
+		bson.M{"$set": bson.M{"status": models.ChatClosed, "updatedAt": now}},
+	)
+	if err != nil {
+		return models.ChatSession{}, err
+	}
+	if result.MatchedCount == 0 {
+		return models.ChatSession{}, ErrChatClosed
+	}
+	session.Status = models.ChatClosed
+	session.UpdatedAt = now
+	return session, nil
+}
@@ -2,6 +2,8 @@ package services
 
 import (
     "context"
+    "hash/fnv"
+    "math"
     "time"
 
     awscfg "github.com/aws/aws-sdk-go-v2/config"
@@ -9,10 +11,18 @@ import (
     cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 )
 
+// CloudWatchService reads metrics from CloudWatch. Without a client (demo
+// mode) it returns synthetic series instead.
 type CloudWatchService struct {
     client *cloudwatch.Client
 }
 
+// NewDemoCloudWatchService returns a stub that needs no AWS credentials and
+// generates deterministic metric series.
+func NewDemoCloudWatchService() *CloudWatchService {
+    return &CloudWatchService{}
+}
+
 func NewCloudWatchService(ctx context.Context, region string) (*CloudWatchService, error) {
     cfg, err := awscfg.LoadDefaultConfig(ctx, awscfg.WithRegion(region))
     if err != nil {
@@ -37,6 +47,10 @@ type MetricSeries struct {
 }
 
 func (s *CloudWatchService) GetMetricSeries(ctx context.Context, in MetricQueryInput) (MetricSeries, error) {
+    if s.client == nil {
+        return syntheticSeries(in), nil
+    }
+
     dims := make([]cwtypes.Dimension, 0, len(in.Dimensions))
     for k, v := range in.Dimensions {
         dims = append(dims, cwtypes.Dimension{Name: &k, Value: &v})
@@ -81,4 +95,24 @@ func (s *CloudWatchService) GetMetricSeries(ctx context.Context, in MetricQueryI
 
 func awsBool(b bool) *bool { return &b }
 
+// syntheticSeries returns a daily wave around a baseline derived from the
+// metric name, so the same query always yields the same values.
+func syntheticSeries(in MetricQueryInput) MetricSeries {
+    h := fnv.New32a()
+    h.Write([]byte(in.Namespace + "/" + in.MetricName))
+    baseline := float64(h.Sum32()%80) + 10
+
+    period := time.Duration(in.Period) * time.Second
+    if period <= 0 {
+        period = time.Minute
+    }
+    series := MetricSeries{}
+    for t := in.StartTime.Truncate(period); !t.After(in.EndTime); t = t.Add(period) {
+        hour := float64(t.Unix()%86400) / 3600
+        series.Timestamps = append(series.Timestamps, t)
+        series.Values = append(series.Values, baseline+baseline*0.2*math.Sin(hour/24*2*math.Pi))
+    }
+    return series
+}
+
 
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// ClusterService groups recent tickets by embedding similarity and raises a
+// problem ticket when a group grows much faster than its usual rate.
+type ClusterService struct {
+	db     *database.MongoDB
+	vector *VectorService
+	cfg    *config.Config
+
+	mu sync.Mutex
+	// embeddings caches ticket embeddings between runs.
+	embeddings map[primitive.ObjectID][]float32
+}
+
+func NewClusterService(db *database.MongoDB, vector *VectorService, cfg *config.Config) *ClusterService {
+	return &ClusterService{
+		db:         db,
+		vector:     vector,
+		cfg:        cfg,
+		embeddings: map[primitive.ObjectID][]float32{},
+	}
+}
+
+func (s *ClusterService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.ClusterInterval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if _, err := s.Run(ctx); err != nil {
+					log.Printf("ticket clustering error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+type ticketGroup struct {
+	centroid []float32
+	members  []models.Ticket
+	vectors  [][]float32
+}
+
+// Run clusters tickets from the lookback window and returns the clusters
+// that were raised or updated as emerging problems.
+func (s *ClusterService) Run(ctx context.Context) ([]models.TicketCluster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	since := now.Add(-s.cfg.ClusterLookback)
+
+	problemIDs, err := s.problemTicketIDs(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{"createdAt", 1}}).
+		SetProjection(bson.M{"title": 1, "description": 1, "category": 1, "subcategory": 1, "createdAt": 1, "problemId": 1})
+	cursor, err := s.db.GetCollection("tickets").Find(ctx, bson.M{
+		"createdAt": bson.M{"$gte": since},
+		"_id":       bson.M{"$nin": problemIDs},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var tickets []models.Ticket
+	err = cursor.All(ctx, &tickets)
+	cursor.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := s.group(tickets)
+
+	var emerging []models.TicketCluster
+	for _, g := range groups {
+		if len(g.members) < s.cfg.ClusterMinSize {
+			continue
+		}
+		recent := 0
+		for _, t := range g.members {
+			if t.CreatedAt.After(now.Add(-s.cfg.ClusterGrowthWindow)) {
+				recent++
+			}
+		}
+		// Expected members per growth window, judged from the rest of the
+		// lookback period.
+		older := float64(len(g.members) - recent)
+		baseline := older / float64(s.cfg.ClusterLookback-s.cfg.ClusterGrowthWindow) * float64(s.cfg.ClusterGrowthWindow)
+		ratio := float64(recent) / math.Max(baseline, 1)
+		if recent < s.cfg.ClusterMinSize || ratio < s.cfg.ClusterGrowthFactor {
+			continue
+		}
+
+		cluster, err := s.raise(ctx, g, recent, baseline, ratio, since)
+		if err != nil {
+			log.Printf("Failed to raise problem for ticket cluster: %v", err)
+			continue
+		}
+		emerging = append(emerging, cluster)
+	}
+
+	// Drop cached embeddings of tickets that left the window
+	seen := make(map[primitive.ObjectID]bool, len(tickets))
+	for _, t := range tickets {
+		seen[t.ID] = true
+	}
+	for id := range s.embeddings {
+		if !seen[id] {
+			delete(s.embeddings, id)
+		}
+	}
+
+	return emerging, nil
+}
+
+// group assigns each ticket, oldest first, to the most similar existing
+// group above the similarity threshold or starts a new group.
+func (s *ClusterService) group(tickets []models.Ticket) []*ticketGroup {
+	var groups []*ticketGroup
+	for _, t := range tickets {
+		vec, ok := s.embeddings[t.ID]
+		if !ok {
+			text := t.Title + "\n" + t.Description
+			if len(text) > 2000 {
+				text = text[:2000]
+			}
+			var err error
+			vec, err = s.vector.GenerateEmbedding(text)
+			if err != nil {
+				continue
+			}
+			s.embeddings[t.ID] = vec
+		}
+
+		var best *ticketGroup
+		bestScore := float32(s.cfg.ClusterSimilarity)
+		for _, g := range groups {
+			if score := CosineSimilarity(vec, g.centroid); score >= bestScore {
+				best, bestScore = g, score
+			}
+		}
+		if best == nil {
+			best = &ticketGroup{centroid: append([]float32{}, vec...)}
+			groups = append(groups, best)
+		} else {
+			n := float32(len(best.members))
+			for i := range best.centroid {
+				best.centroid[i] = (best.centroid[i]*n + vec[i]) / (n + 1)
+			}
+		}
+		best.members = append(best.members, t)
+		best.vectors = append(best.vectors, vec)
+	}
+	return groups
+}
+
+// raise creates a problem ticket for a new emerging cluster, or adds the new
+// members to the problem ticket of a matching cluster found earlier.
+func (s *ClusterService) raise(ctx context.Context, g *ticketGroup, recent int, baseline, ratio float64, since time.Time) (models.TicketCluster, error) {
+	memberIDs := make([]primitive.ObjectID, 0, len(g.members))
+	categories := map[models.TicketCategory]int{}
+	var category models.TicketCategory
+	representative, bestScore := g.members[0].Title, float32(-1)
+	for i, t := range g.members {
+		memberIDs = append(memberIDs, t.ID)
+		categories[t.Category]++
+		if categories[t.Category] > categories[category] {
+			category = t.Category
+		}
+		if score := CosineSimilarity(g.vectors[i], g.centroid); score > bestScore {
+			representative, bestScore = t.Title, score
+		}
+	}
+
+	var existing *models.TicketCluster
+	var previous []models.TicketCluster
+	cursor, err := s.db.GetCollection("ticket_clusters").Find(ctx, bson.M{"updatedAt": bson.M{"$gte": since}})
+	if err != nil {
+		return models.TicketCluster{}, err
+	}
+	err = cursor.All(ctx, &previous)
+	cursor.Close(ctx)
+	if err != nil {
+		return models.TicketCluster{}, err
+	}
+	for i := range previous {
+		if CosineSimilarity(previous[i].Centroid, g.centroid) >= float32(s.cfg.ClusterSimilarity) {
+			existing = &previous[i]
+			break
+		}
+	}
+
+	now := time.Now()
+	cluster := models.TicketCluster{
+		ID:             primitive.NewObjectID(),
+		Representative: representative,
+		Category:       category,
+		Centroid:       g.centroid,
+		MemberIDs:      memberIDs,
+		RecentCount:    recent,
+		BaselineRate:   math.Round(baseline*100) / 100,
+		GrowthRatio:    math.Round(ratio*100) / 100,
+		DetectedAt:     now,
+		UpdatedAt:      now,
+	}
+	description := problemDescription(cluster, g.members, s.cfg.ClusterGrowthWindow)
+
+	if existing != nil {
+		cluster.ID = existing.ID
+		cluster.DetectedAt = existing.DetectedAt
+		cluster.ProblemTicketID = existing.ProblemTicketID
+		_, err := s.db.GetCollection("ticket_clusters").ReplaceOne(ctx, bson.M{"_id": existing.ID}, cluster)
+		if err != nil {
+			return models.TicketCluster{}, err
+		}
+		_, err = s.db.GetCollection("tickets").UpdateByID(ctx, cluster.ProblemTicketID,
+			bson.M{"$set": bson.M{"description": description, "updatedAt": now}})
+		if err != nil {
+			return models.TicketCluster{}, err
+		}
+	} else {
+		var admin models.User
+		if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"role": models.RoleAdmin}).Decode(&admin); err != nil {
+			return models.TicketCluster{}, err
+		}
+		problem := models.Ticket{
+			ID:          primitive.NewObjectID(),
+			Title:       fmt.Sprintf("Emerging problem: %s", representative),
+			Description: description,
+			Category:    category,
+			Priority:    models.PriorityHigh,
+			Status:      models.StatusOpen,
+			CreatedBy:   admin.ID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if _, err := s.db.GetCollection("tickets").InsertOne(ctx, problem); err != nil {
+			return models.TicketCluster{}, err
+		}
+		cluster.ProblemTicketID = problem.ID
+		if _, err := s.db.GetCollection("ticket_clusters").InsertOne(ctx, cluster); err != nil {
+			return models.TicketCluster{}, err
+		}
+		log.Printf("Emerging problem detected: %q (%d tickets, %.1fx usual rate)", representative, recent, ratio)
+	}
+
+	_, err = s.db.GetCollection("tickets").UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": memberIDs}},
+		bson.M{"$set": bson.M{"problemId": cluster.ProblemTicketID}})
+	return cluster, err
+}
+
+// problemTicketIDs returns the problem tickets raised in the window so they
+// are not clustered with their own members.
+func (s *ClusterService) problemTicketIDs(ctx context.Context, since time.Time) ([]primitive.ObjectID, error) {
+	cursor, err := s.db.GetCollection("ticket_clusters").Find(ctx,
+		bson.M{"detectedAt": bson.M{"$gte": since.Add(-s.cfg.ClusterLookback)}},
+		options.Find().SetProjection(bson.M{"problemTicketId": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var clusters []models.TicketCluster
+	if err := cursor.All(ctx, &clusters); err != nil {
+		return nil, err
+	}
+	ids := []primitive.ObjectID{}
+	for _, c := range clusters {
+		ids = append(ids, c.ProblemTicketID)
+	}
+	return ids, nil
+}
+
+// List returns detected clusters, newest first.
+func (s *ClusterService) List(ctx context.Context, limit int64) ([]models.TicketCluster, error) {
+	opts := options.Find().SetSort(bson.D{{"updatedAt", -1}}).SetLimit(limit)
+	cursor, err := s.db.GetCollection("ticket_clusters").Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	clusters := []models.TicketCluster{}
+	if err := cursor.All(ctx, &clusters); err != nil {
+		return nil, err
+	}
+	return clusters, nil
+}
+
+func problemDescription(cluster models.TicketCluster, members []models.Ticket, window time.Duration) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%d similar tickets were raised in the last %s, %.1fx the usual rate (about %.1f expected).\n",
+		cluster.RecentCount, window, cluster.GrowthRatio, cluster.BaselineRate))
+	b.WriteString("This problem ticket was raised automatically; resolving the underlying cause should resolve the related tickets.\n\n")
+	b.WriteString(fmt.Sprintf("Related tickets (%d):\n", len(members)))
+	for _, t := range members {
+		b.WriteString(fmt.Sprintf("- /tickets/%s %s (%s)\n", t.ID.Hex(), t.Title, t.CreatedAt.Format("2006-01-02 15:04")))
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const maxCommentLength = 10000
+
+type CommentService struct {
+	db *database.MongoDB
+}
+
+func NewCommentService(db *database.MongoDB) *CommentService {
+	return &CommentService{db: db}
+}
+
+// ValidateComment checks a comment body before it is added.
+func ValidateComment(body string) error {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return errors.New("body is required")
+	}
+	if len(body) > maxCommentLength {
+		return fmt.Errorf("body must be at most %d characters", maxCommentLength)
+	}
+	return nil
+}
+
+// Add appends a comment to a ticket and bumps the ticket's updatedAt.
+func (s *CommentService) Add(ctx context.Context, ticketID primitive.ObjectID, author models.User, body string, internal bool) (models.TicketComment, error) {
+	comment := models.TicketComment{
+		ID:         primitive.NewObjectID(),
+		TicketID:   ticketID,
+		AuthorID:   author.ID,
+		AuthorName: author.Name,
+		AuthorRole: author.Role,
+		Body:       body,
+		Internal:   internal,
+		CreatedAt:  time.Now(),
+	}
+	if _, err := s.db.GetCollection("ticket_comments").InsertOne(ctx, comment); err != nil {
+		return models.TicketComment{}, err
+	}
+	_, err := s.db.GetCollection("tickets").UpdateByID(ctx, ticketID, bson.M{"$set": bson.M{"updatedAt": comment.CreatedAt}})
+	return comment, err
+}
+
+// List returns a ticket's comments oldest first, leaving out internal ones
+// unless includeInternal is set.
+func (s *CommentService) List(ctx context.Context, ticketID primitive.ObjectID, includeInternal bool) ([]models.TicketComment, error) {
+	filter := bson.M{"ticketId": ticketID}
+	if !includeInternal {
+		filter["internal"] = false
+	}
+	cursor, err := s.db.GetCollection("ticket_comments").Find(ctx, filter, options.Find().SetSort(bson.D{{"createdAt", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := []models.TicketComment{}
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// Page returns one page of a ticket's comments, oldest first, with the
+// number of comments on the thread. Internal comments are left out unless
+// includeInternal is set.
+func (s *CommentService) Page(ctx context.Context, ticketID primitive.ObjectID, includeInternal bool, skip, limit int64) ([]models.TicketComment, int64, error) {
+	filter := bson.M{"ticketId": ticketID}
+	if !includeInternal {
+		filter["internal"] = false
+	}
+	collection := s.db.GetCollection("ticket_comments")
+	opts := options.Find().SetSort(bson.D{{"createdAt", 1}, {"_id", 1}}).SetSkip(skip).SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := []models.TicketComment{}
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, 0, err
+	}
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awscfg "github.com/aws/aws-sdk-go-v2/config"
+
+	"intelliops-ai-copilot/models"
+)
+
+// Cost Explorer is a global service served from us-east-1.
+const (
+	costExplorerEndpoint = "https://ce.us-east-1.amazonaws.com/"
+	costExplorerRegion   = "us-east-1"
+)
+
+// CostExplorerService reads daily spend from AWS Cost Explorer. Requests go
+// through its JSON API signed with the default AWS credentials. Without
+// credentials (demo mode) it returns synthetic costs instead.
+type CostExplorerService struct {
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+}
+
+// NewDemoCostExplorerService returns a stub that needs no AWS credentials
+// and generates deterministic daily costs.
+func NewDemoCostExplorerService() *CostExplorerService {
+	return &CostExplorerService{}
+}
+
+func NewCostExplorerService(ctx context.Context) (*CostExplorerService, error) {
+	cfg, err := awscfg.LoadDefaultConfig(ctx, awscfg.WithRegion(costExplorerRegion))
+	if err != nil {
+		return nil, err
+	}
+	return &CostExplorerService{
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+type costExplorerResponse struct {
+	ResultsByTime []struct {
+		TimePeriod struct {
+			Start string
+		}
+		Groups []struct {
+			Keys    []string
+			Metrics map[string]struct{ Amount string }
+		}
+		Estimated bool
+	}
+	NextPageToken string
+}
+
+// GetDailyCosts returns the spend of every day in [start, end), grouped by
+// the SERVICE or LINKED_ACCOUNT dimension.
+func (s *CostExplorerService) GetDailyCosts(ctx context.Context, start, end time.Time, groupBy string) ([]models.DailyCost, error) {
+	if s.signer == nil {
+		return syntheticCosts(start, end), nil
+	}
+
+	days := []models.DailyCost{}
+	token := ""
+	for {
+		input := map[string]interface{}{
+			"TimePeriod":  map[string]string{"Start": start.Format("2006-01-02"), "End": end.Format("2006-01-02")},
+			"Granularity": "DAILY",
+			"Metrics":     []string{"UnblendedCost"},
+			"GroupBy":     []map[string]string{{"Type": "DIMENSION", "Key": groupBy}},
+		}
+		if token != "" {
+			input["NextPageToken"] = token
+		}
+		var out costExplorerResponse
+		if err := s.call(ctx, "GetCostAndUsage", input, &out); err != nil {
+			return nil, err
+		}
+
+		for _, r := range out.ResultsByTime {
+			date, err := time.Parse("2006-01-02", r.TimePeriod.Start)
+			if err != nil {
+				return nil, err
+			}
+			day := models.DailyCost{Date: date, Groups: map[string]float64{}, Estimated: r.Estimated}
+			for _, g := range r.Groups {
+				if len(g.Keys) == 0 {
+					continue
+				}
+				amount, _ := strconv.ParseFloat(g.Metrics["UnblendedCost"].Amount, 64)
+				day.Groups[g.Keys[0]] += amount
+				day.Total += amount
+			}
+			days = append(days, day)
+		}
+		if out.NextPageToken == "" {
+			break
+		}
+		token = out.NextPageToken
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days, nil
+}
+
+func (s *CostExplorerService) call(ctx context.Context, operation string, input, output interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", costExplorerEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AWSInsightsIndexService."+operation)
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(body)
+	if err := s.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "ce", costExplorerRegion, time.Now()); err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("cost explorer %s returned status %d: %s", operation, resp.StatusCode, truncateBytes(string(data), 300))
+	}
+	return json.Unmarshal(data, output)
+}
+
+// Services the synthetic costs are spread over.
+var syntheticCostServices = []string{
+	"Amazon Elastic Compute Cloud - Compute",
+	"Amazon Relational Database Service",
+	"Amazon Simple Storage Service",
+	"Elastic Load Balancing",
+	"AWS Lambda",
+}
+
+// syntheticCosts returns a weekly wave of spend per service with a baseline
+// derived from the service name, so the same range always yields the same
+// costs.
+func syntheticCosts(start, end time.Time) []models.DailyCost {
+	days := []models.DailyCost{}
+	for t := start.Truncate(24 * time.Hour); t.Before(end); t = t.Add(24 * time.Hour) {
+		day := models.DailyCost{Date: t, Groups: map[string]float64{}}
+		weekday := float64(t.Weekday())
+		for _, service := range syntheticCostServices {
+			h := fnv.New32a()
+			h.Write([]byte(service))
+			baseline := float64(h.Sum32()%200) + 20
+			cost := math.Round((baseline+baseline*0.1*math.Sin(weekday/7*2*math.Pi))*100) / 100
+			day.Groups[service] = cost
+			day.Total += cost
+		}
+		days = append(days, day)
+	}
+	return days
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	anomalySourceCost = "cost_explorer"
+	// costTopContributors is how many services or accounts a cost ticket
+	// lists.
+	costTopContributors = 5
+)
+
+// CostMonitorService runs the anomaly detector on daily AWS spend and opens
+// tickets for spikes, recording them as anomalies alongside metric ones.
+type CostMonitorService struct {
+	db       *database.MongoDB
+	ce       *CostExplorerService
+	cfg      *config.Config
+	taxonomy *TaxonomyService
+	routes   *MonitorRouteService
+	policies *AnomalyPolicies
+	flags    *FeatureFlagService
+	groupBy  string
+}
+
+func NewCostMonitorService(db *database.MongoDB, ce *CostExplorerService, cfg *config.Config, taxonomy *TaxonomyService, routes *MonitorRouteService, policies *AnomalyPolicies, flags *FeatureFlagService) *CostMonitorService {
+	groupBy := cfg.CostGroupBy
+	if groupBy != "SERVICE" && groupBy != "LINKED_ACCOUNT" {
+		log.Printf("Invalid COST_GROUP_BY %q, using SERVICE", groupBy)
+		groupBy = "SERVICE"
+	}
+	return &CostMonitorService{db: db, ce: ce, cfg: cfg, taxonomy: taxonomy, routes: routes, policies: policies, flags: flags, groupBy: groupBy}
+}
+
+// Start checks the last complete day's spend every interval until ctx is
+// cancelled, skipping checks while the cost_anomalies flag is off.
+func (s *CostMonitorService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if !s.flags.Enabled(ctx, FlagCostAnomalies) {
+					continue
+				}
+				if _, err := s.Check(ctx); err != nil {
+					log.Printf("cost anomaly check error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Costs returns the daily spend of the last days complete days.
+func (s *CostMonitorService) Costs(ctx context.Context, days int) ([]models.DailyCost, error) {
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	return s.ce.GetDailyCosts(ctx, end.AddDate(0, 0, -days), end, s.groupBy)
+}
+
+// Check compares yesterday's spend, the last complete day, with the
+// baseline before it. It returns the anomaly it recorded, or nil when spend
+// is normal or the day was already checked.
+func (s *CostMonitorService) Check(ctx context.Context) (*models.AnomalyRecord, error) {
+	baselineDays := s.cfg.CostBaselineDays
+	costs, err := s.Costs(ctx, baselineDays+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(costs) < baselineDays+1 {
+		return nil, nil
+	}
+	latest := costs[len(costs)-1]
+	baseline := costs[:len(costs)-1]
+
+	dedup := fmt.Sprintf("%s:%s:%s", anomalySourceCost, s.groupBy, latest.Date.Format("2006-01-02"))
+	count, err := s.db.GetCollection("mon_anomalies").CountDocuments(ctx, bson.M{"dedupKey": dedup})
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, nil
+	}
+
+	totals := make([]float64, len(costs))
+	for i, day := range costs {
+		totals[i] = day.Total
+	}
+	res := DetectZScoreAnomaly(totals, baselineDays, s.cfg.CostZScore, 1, string(models.DirectionAbove))
+	if !res.IsAnomaly || latest.Total-res.BaselineMean < s.cfg.CostMinIncrease {
+		return nil, nil
+	}
+
+	contributors := costContributors(latest, baseline)
+	policy := s.policies.Default()
+	severity := policy.Severity(res.ZScore)
+	anomaly := models.AnomalyRecord{
+		ID:           primitive.NewObjectID(),
+		MetricName:   "DailyCost",
+		Timestamp:    latest.Date,
+		Value:        latest.Total,
+		BaselineMean: res.BaselineMean,
+		BaselineStd:  res.BaselineStd,
+		ZScore:       res.ZScore,
+		Severity:     severity,
+		DedupKey:     dedup,
+		Status:       models.AnomalyOpen,
+		Source:       anomalySourceCost,
+		Labels:       map[string]string{"groupBy": s.groupBy, "date": latest.Date.Format("2006-01-02")},
+		CreatedAt:    time.Now(),
+	}
+	if len(contributors) > 0 {
+		anomaly.Labels["topContributor"] = contributors[0].Key
+	}
+
+	title := fmt.Sprintf("Cost spike: AWS spend $%.2f on %s", latest.Total, latest.Date.Format("2006-01-02"))
+	if s.cfg.AnomalyCreateTickets {
+		ticketID, err := insertAnomalyTicket(ctx, s.db, title, s.describe(latest, res, contributors), s.category(ctx), policy.Priority(severity), nil)
+		if err != nil {
+			log.Printf("ticket creation for cost anomaly failed: %v", err)
+		} else {
+			anomaly.TicketID = ticketID
+		}
+	}
+	s.routes.Route(ctx, nil, &anomaly, title)
+
+	if _, err := s.db.GetCollection("mon_anomalies").InsertOne(ctx, anomaly); err != nil {
+		return nil, err
+	}
+	return &anomaly, nil
+}
+
+// costContributors ranks the groups of latest by how much they rose above
+// their average over the baseline days, keeping those that rose.
+func costContributors(latest models.DailyCost, baseline []models.DailyCost) []models.CostContributor {
+	contributors := []models.CostContributor{}
+	for key, cost := range latest.Groups {
+		sum := 0.0
+		for _, day := range baseline {
+			sum += day.Groups[key]
+		}
+		mean := sum / float64(len(baseline))
+		if cost > mean {
+			contributors = append(contributors, models.CostContributor{Key: key, Cost: cost, Baseline: mean, Increase: cost - mean})
+		}
+	}
+	sort.Slice(contributors, func(i, j int) bool { return contributors[i].Increase > contributors[j].Increase })
+	if len(contributors) > costTopContributors {
+		contributors = contributors[:costTopContributors]
+	}
+	return contributors
+}
+
+func (s *CostMonitorService) describe(latest models.DailyCost, res AnomalyResult, contributors []models.CostContributor) string {
+	var desc strings.Builder
+	desc.WriteString(fmt.Sprintf("AWS spend on %s was $%.2f, against a %d-day average of $%.2f (std $%.2f, z %.2f), an increase of $%.2f.\n",
+		latest.Date.Format("2006-01-02"), latest.Total, s.cfg.CostBaselineDays, res.BaselineMean, res.BaselineStd, res.ZScore, latest.Total-res.BaselineMean))
+	if latest.Estimated {
+		desc.WriteString("Figures for the day are still estimated by AWS.\n")
+	}
+	if len(contributors) > 0 {
+		desc.WriteString(fmt.Sprintf("\nTop contributors by %s:\n", strings.ToLower(strings.ReplaceAll(s.groupBy, "_", " "))))
+		for _, c := range contributors {
+			desc.WriteString(fmt.Sprintf("  %s: $%.2f (average $%.2f, +$%.2f)\n", c.Key, c.Cost, c.Baseline, c.Increase))
+		}
+	}
+	return desc.String()
+}
+
+// category is the configured cost category when the taxonomy has it, the
+// taxonomy default otherwise.
+func (s *CostMonitorService) category(ctx context.Context) models.TicketCategory {
+	taxonomy := s.taxonomy.Get(ctx)
+	if c := models.TicketCategory(s.cfg.CostAnomalyCategory); FindCategory(taxonomy, c) != nil {
+		return c
+	}
+	return DefaultCategory(taxonomy)
+}
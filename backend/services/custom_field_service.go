@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	maxCustomFieldLabel   = 100
+	maxCustomFieldOptions = 100
+	maxCustomFieldText    = 1000
+)
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-zA-Z0-9_]{0,39}$`)
+
+// ErrInvalidCustomField is returned for custom field definitions and
+// values that do not validate.
+var ErrInvalidCustomField = errors.New("invalid custom field")
+
+func invalidCustomField(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidCustomField, fmt.Sprintf(format, args...))
+}
+
+// CustomFieldService keeps the admin-defined ticket fields and validates
+// the values tickets give them.
+type CustomFieldService struct {
+	db       *database.MongoDB
+	taxonomy *TaxonomyService
+}
+
+func NewCustomFieldService(db *database.MongoDB, taxonomy *TaxonomyService) *CustomFieldService {
+	return &CustomFieldService{db: db, taxonomy: taxonomy}
+}
+
+func (s *CustomFieldService) collection() *mongo.Collection {
+	return s.db.GetCollection("custom_fields")
+}
+
+func (s *CustomFieldService) List(ctx context.Context) ([]models.CustomField, error) {
+	cursor, err := s.collection().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	fields := []models.CustomField{}
+	if err := cursor.All(ctx, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Save creates or replaces the field with key. Values tickets already have
+// are kept when the definition changes.
+func (s *CustomFieldService) Save(ctx context.Context, key string, req models.CustomFieldRequest, updatedBy primitive.ObjectID) (models.CustomField, error) {
+	if !customFieldKeyPattern.MatchString(key) {
+		return models.CustomField{}, invalidCustomField("key must start with a lowercase letter and hold at most 40 letters, digits or underscores")
+	}
+	label := strings.TrimSpace(req.Label)
+	if label == "" || len(label) > maxCustomFieldLabel {
+		return models.CustomField{}, invalidCustomField("label must be 1 to %d characters", maxCustomFieldLabel)
+	}
+	field := models.CustomField{
+		Key:         key,
+		Label:       label,
+		Type:        req.Type,
+		Description: strings.TrimSpace(req.Description),
+		Required:    req.Required,
+		UpdatedAt:   time.Now(),
+		UpdatedBy:   updatedBy,
+	}
+
+	switch req.Type {
+	case models.CustomFieldDropdown:
+		if len(req.Options) == 0 || len(req.Options) > maxCustomFieldOptions {
+			return models.CustomField{}, invalidCustomField("a dropdown needs 1 to %d options", maxCustomFieldOptions)
+		}
+		seen := map[string]bool{}
+		for _, o := range req.Options {
+			o = strings.TrimSpace(o)
+			if o == "" || seen[o] {
+				return models.CustomField{}, invalidCustomField("dropdown options must be non-empty and unique")
+			}
+			seen[o] = true
+			field.Options = append(field.Options, o)
+		}
+	case models.CustomFieldText, models.CustomFieldNumber, models.CustomFieldDate:
+		if len(req.Options) > 0 {
+			return models.CustomField{}, invalidCustomField("only dropdowns have options")
+		}
+	default:
+		return models.CustomField{}, invalidCustomField("type must be text, dropdown, number or date")
+	}
+
+	taxonomy := s.taxonomy.Get(ctx)
+	for _, category := range req.Categories {
+		if FindCategory(taxonomy, category) == nil {
+			return models.CustomField{}, invalidCustomField("unknown category %q", category)
+		}
+	}
+	// Drops repeated categories
+	field.Categories = NormalizeSecondaryCategories("", req.Categories)
+
+	_, err := s.collection().ReplaceOne(ctx, bson.M{"_id": key}, field, options.Replace().SetUpsert(true))
+	return field, err
+}
+
+// Delete removes a field definition. Tickets keep the values they have.
+func (s *CustomFieldService) Delete(ctx context.Context, key string) error {
+	res, err := s.collection().DeleteOne(ctx, bson.M{"_id": key})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func customFieldApplies(field models.CustomField, category models.TicketCategory) bool {
+	return len(field.Categories) == 0 || containsCategory(field.Categories, category)
+}
+
+// Apply validates the custom field values in changes for a ticket of
+// category and returns current with them applied; a nil value clears a
+// field. With requireAll, as on create, every required field that applies
+// to the category must end up set.
+func (s *CustomFieldService) Apply(ctx context.Context, category models.TicketCategory, current, changes map[string]interface{}, requireAll bool) (map[string]interface{}, error) {
+	fields, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string]models.CustomField{}
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+
+	values := map[string]interface{}{}
+	for k, v := range current {
+		values[k] = v
+	}
+	for key, raw := range changes {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, invalidCustomField("unknown custom field %q", key)
+		}
+		if !customFieldApplies(field, category) {
+			return nil, invalidCustomField("%s does not apply to %s tickets", field.Label, category)
+		}
+		if raw == nil {
+			if field.Required {
+				return nil, invalidCustomField("%s is required", field.Label)
+			}
+			delete(values, key)
+			continue
+		}
+		value, err := customFieldValue(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+
+	if requireAll {
+		for _, f := range fields {
+			if _, ok := values[f.Key]; f.Required && !ok && customFieldApplies(f, category) {
+				return nil, invalidCustomField("%s is required", f.Label)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return values, nil
+}
+
+// customFieldValue checks raw, as decoded from JSON, against the field's
+// type and returns the value to store.
+func customFieldValue(field models.CustomField, raw interface{}) (interface{}, error) {
+	switch field.Type {
+	case models.CustomFieldNumber:
+		n, ok := raw.(float64)
+		if !ok || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, invalidCustomField("%s must be a number", field.Label)
+		}
+		return n, nil
+	case models.CustomFieldDate:
+		text, _ := raw.(string)
+		for _, layout := range []string{"2006-01-02", time.RFC3339} {
+			if t, err := time.Parse(layout, strings.TrimSpace(text)); err == nil {
+				return t.UTC(), nil
+			}
+		}
+		return nil, invalidCustomField("%s must be a date (YYYY-MM-DD or RFC 3339)", field.Label)
+	}
+
+	text, ok := raw.(string)
+	text = strings.TrimSpace(text)
+	if !ok || text == "" {
+		return nil, invalidCustomField("%s must be a non-empty string", field.Label)
+	}
+	if field.Type == models.CustomFieldDropdown {
+		for _, o := range field.Options {
+			if o == text {
+				return text, nil
+			}
+		}
+		return nil, invalidCustomField("%s must be one of %s", field.Label, strings.Join(field.Options, ", "))
+	}
+	if len(text) > maxCustomFieldText {
+		return nil, invalidCustomField("%s must be at most %d characters", field.Label, maxCustomFieldText)
+	}
+	return text, nil
+}
+
+// customFieldString formats a stored custom field value for the ticket
+// history.
+func customFieldString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case primitive.DateTime:
+		return v.Time().UTC().Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
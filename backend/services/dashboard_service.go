@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// Longest lists in the dashboard; the full lists are in their own
+// endpoints.
+const (
+	dashboardTicketLimit       = 50
+	dashboardNotificationLimit = 20
+)
+
+// DashboardService builds the technician's "my day" view for the mobile
+// app.
+type DashboardService struct {
+	db        *database.MongoDB
+	taxonomy  *TaxonomyService
+	reminders *ReminderService
+	push      *PushService
+}
+
+func NewDashboardService(db *database.MongoDB, taxonomy *TaxonomyService, reminders *ReminderService, push *PushService) *DashboardService {
+	return &DashboardService{db: db, taxonomy: taxonomy, reminders: reminders, push: push}
+}
+
+// MyDay returns the user's dashboard. Today ends at midnight in loc.
+func (s *DashboardService) MyDay(ctx context.Context, user models.User, loc *time.Location) (models.MyDashboard, error) {
+	now := time.Now()
+	dashboard := models.MyDashboard{GeneratedAt: now}
+
+	cursor, err := s.db.GetCollection("tickets").Find(ctx, bson.M{
+		"assignedTo": user.ID,
+		"status":     bson.M{"$nin": bson.A{models.StatusResolved, models.StatusClosed}},
+	}, options.Find().SetProjection(bson.M{
+		"title": 1, "status": 1, "priority": 1, "createdAt": 1, "acknowledgedAt": 1, "dueAt": 1,
+	}))
+	if err != nil {
+		return models.MyDashboard{}, err
+	}
+	var tickets []models.Ticket
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return models.MyDashboard{}, err
+	}
+
+	targets := map[models.TicketPriority]models.TaxonomyPriority{}
+	for _, p := range s.taxonomy.Get(ctx).Priorities {
+		targets[p.Name] = p
+	}
+	dashboard.AssignedTickets = []models.DashboardTicket{}
+	dashboard.AwaitingAcceptance = []models.DashboardTicket{}
+	for _, t := range tickets {
+		// Open tickets nobody acknowledged still run against the response
+		// target; the rest against the resolution target.
+		awaiting := t.Status == models.StatusOpen && t.AcknowledgedAt == nil
+		item := models.DashboardTicket{ID: t.ID, Title: t.Title, Status: t.Status, Priority: t.Priority, CreatedAt: t.CreatedAt}
+		target := targets[t.Priority]
+		var due time.Time
+		if awaiting && target.ResponseSLAMinutes > 0 {
+			due = t.CreatedAt.Add(time.Duration(target.ResponseSLAMinutes) * time.Minute)
+		} else if !awaiting && target.ResolutionSLAHours > 0 {
+			due = t.CreatedAt.Add(time.Duration(target.ResolutionSLAHours) * time.Hour)
+		}
+		if t.DueAt != nil && (due.IsZero() || t.DueAt.Before(due)) {
+			due = *t.DueAt
+		}
+		if !due.IsZero() {
+			item.SLADueAt = &due
+			item.SLABreached = now.After(due)
+		}
+		if awaiting {
+			dashboard.AwaitingAcceptance = append(dashboard.AwaitingAcceptance, item)
+		} else {
+			dashboard.AssignedTickets = append(dashboard.AssignedTickets, item)
+		}
+	}
+	dashboard.AssignedTickets = sortByUrgency(dashboard.AssignedTickets)
+	dashboard.AwaitingAcceptance = sortByUrgency(dashboard.AwaitingAcceptance)
+
+	local := now.In(loc)
+	endOfDay := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+	agenda, err := s.reminders.Agenda(ctx, user.ID, endOfDay.Sub(now))
+	if err != nil {
+		return models.MyDashboard{}, err
+	}
+	dashboard.RemindersDueToday = append(agenda.Overdue, agenda.Upcoming...)
+
+	dashboard.UnreadNotifications, dashboard.UnreadCount, err = s.push.UnreadNotifications(ctx, user.ID, dashboardNotificationLimit)
+	if err != nil {
+		return models.MyDashboard{}, err
+	}
+	return dashboard, nil
+}
+
+// sortByUrgency orders tickets by SLA deadline, tickets without one last
+// and oldest first, and keeps the first dashboardTicketLimit.
+func sortByUrgency(tickets []models.DashboardTicket) []models.DashboardTicket {
+	sort.SliceStable(tickets, func(i, j int) bool {
+		a, b := tickets[i], tickets[j]
+		if (a.SLADueAt == nil) != (b.SLADueAt == nil) {
+			return a.SLADueAt != nil
+		}
+		if a.SLADueAt != nil && !a.SLADueAt.Equal(*b.SLADueAt) {
+			return a.SLADueAt.Before(*b.SLADueAt)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	if len(tickets) > dashboardTicketLimit {
+		tickets = tickets[:dashboardTicketLimit]
+	}
+	return tickets
+}
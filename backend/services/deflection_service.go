@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// preflightArticles and preflightDocuments cap the suggestions returned for
+// a ticket draft.
+const (
+	preflightArticles  = 3
+	preflightDocuments = 3
+)
+
+var ErrDeflectionClosed = errors.New("preflight already has an outcome")
+
+// DeflectionService suggests articles and documents for a ticket draft
+// before it is saved and tracks whether the suggestions deflected it.
+type DeflectionService struct {
+	db      *database.MongoDB
+	kb      *KBService
+	vectors *VectorService
+	queries *QueryService
+}
+
+func NewDeflectionService(db *database.MongoDB, kb *KBService, vectors *VectorService, queries *QueryService) *DeflectionService {
+	return &DeflectionService{db: db, kb: kb, vectors: vectors, queries: queries}
+}
+
+func (s *DeflectionService) collection() *mongo.Collection {
+	return s.db.GetCollection("deflection_attempts")
+}
+
+// Preflight searches for suggestions for a draft and records the attempt.
+// Requesters only get public articles; staff also get indexed documents.
+func (s *DeflectionService) Preflight(ctx context.Context, user models.User, req models.PreflightRequest) (models.PreflightResponse, error) {
+	query := SolutionQuery(models.Ticket{Title: req.Title, Description: req.Description})
+	embedding, err := s.vectors.GenerateEmbedding(s.queries.Process(ctx, query).Query)
+	if err != nil {
+		return models.PreflightResponse{}, err
+	}
+	results, err := s.vectors.SearchApproved(embedding, preflightArticles*10, kbSuggestionMinScore)
+	if err != nil {
+		return models.PreflightResponse{}, err
+	}
+
+	requester := user.Role == models.RoleRequester
+	response := models.PreflightResponse{
+		PreflightID: primitive.NewObjectID(),
+		Articles:    s.kb.ArticlesFrom(ctx, results, preflightArticles, requester),
+	}
+	attempt := models.DeflectionAttempt{
+		ID:          response.PreflightID,
+		UserID:      user.ID,
+		Role:        user.Role,
+		Title:       req.Title,
+		Description: req.Description,
+		ArticleIDs:  []primitive.ObjectID{},
+		Outcome:     models.DeflectionPending,
+		CreatedAt:   time.Now(),
+	}
+	for _, a := range response.Articles {
+		attempt.ArticleIDs = append(attempt.ArticleIDs, a.ID)
+	}
+	if !requester {
+		for _, result := range results {
+			if len(response.Documents) >= preflightDocuments {
+				break
+			}
+			if IsArticle(result) {
+				continue
+			}
+			response.Documents = append(response.Documents, result)
+			attempt.Documents = append(attempt.Documents, result.Document.Title)
+		}
+	}
+
+	if _, err := s.collection().InsertOne(ctx, attempt); err != nil {
+		return models.PreflightResponse{}, err
+	}
+	return response, nil
+}
+
+// Proceeded records that the user raised the ticket after seeing the
+// suggestions.
+func (s *DeflectionService) Proceeded(ctx context.Context, preflightID, userID, ticketID primitive.ObjectID) error {
+	now := time.Now()
+	result, err := s.collection().UpdateOne(ctx,
+		bson.M{"_id": preflightID, "userId": userID, "outcome": models.DeflectionPending},
+		bson.M{"$set": bson.M{"outcome": models.DeflectionProceeded, "ticketId": ticketID, "resolvedAt": now}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SelfResolved records that a suggestion solved the issue, so no ticket was
+// raised. articleID is the article that helped, if the user said.
+func (s *DeflectionService) SelfResolved(ctx context.Context, preflightID, userID primitive.ObjectID, articleID *primitive.ObjectID) error {
+	var attempt models.DeflectionAttempt
+	if err := s.collection().FindOne(ctx, bson.M{"_id": preflightID, "userId": userID}).Decode(&attempt); err != nil {
+		return err
+	}
+	if attempt.Outcome != models.DeflectionPending {
+		return ErrDeflectionClosed
+	}
+
+	set := bson.M{"outcome": models.DeflectionSelfResolved, "resolvedAt": time.Now()}
+	if articleID != nil {
+		set["articleId"] = *articleID
+	}
+	result, err := s.collection().UpdateOne(ctx,
+		bson.M{"_id": preflightID, "outcome": models.DeflectionPending},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrDeflectionClosed
+	}
+	return nil
+}
+
+// Stats summarizes the outcomes of attempts made since a point in time.
+func (s *DeflectionService) Stats(ctx context.Context, since time.Time) (models.DeflectionStats, error) {
+	cursor, err := s.collection().Find(ctx, bson.M{"createdAt": bson.M{"$gte": since}})
+	if err != nil {
+		return models.DeflectionStats{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var attempts []models.DeflectionAttempt
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return models.DeflectionStats{}, err
+	}
+
+	stats := models.DeflectionStats{Since: since, TopArticles: []models.ArticleDeflection{}}
+	byArticle := map[primitive.ObjectID]int64{}
+	for _, a := range attempts {
+		stats.Attempts++
+		if len(a.ArticleIDs) > 0 {
+			stats.WithSuggestions++
+		}
+		switch a.Outcome {
+		case models.DeflectionProceeded:
+			stats.Proceeded++
+		case models.DeflectionSelfResolved:
+			stats.SelfResolved++
+			if a.ArticleID != nil {
+				byArticle[*a.ArticleID]++
+			}
+		default:
+			stats.Pending++
+		}
+	}
+	if decided := stats.Proceeded + stats.SelfResolved; decided > 0 {
+		stats.DeflectionRate = float64(stats.SelfResolved) / float64(decided)
+	}
+
+	for id, count := range byArticle {
+		entry := models.ArticleDeflection{ArticleID: id, SelfResolved: count}
+		if article, err := s.kb.Get(ctx, id); err == nil {
+			entry.Title = article.Title
+		}
+		stats.TopArticles = append(stats.TopArticles, entry)
+	}
+	sort.Slice(stats.TopArticles, func(i, j int) bool {
+		return stats.TopArticles[i].SelfResolved > stats.TopArticles[j].SelfResolved
+	})
+	if len(stats.TopArticles) > 10 {
+		stats.TopArticles = stats.TopArticles[:10]
+	}
+	return stats, nil
+}
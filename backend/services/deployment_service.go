@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const maxDeploymentServiceLength = 200
+
+// DeploymentService stores deployment events from CI/CD pipelines and finds
+// the ones made shortly before a ticket, the usual suspects for an
+// incident.
+type DeploymentService struct {
+	db *database.MongoDB
+	// window is how far back from a ticket deployments are correlated.
+	window time.Duration
+}
+
+func NewDeploymentService(db *database.MongoDB, cfg *config.Config) *DeploymentService {
+	return &DeploymentService{db: db, window: cfg.DeploymentWindow}
+}
+
+func (s *DeploymentService) collection() *mongo.Collection {
+	return s.db.GetCollection("deployments")
+}
+
+// Record stores a deployment event.
+func (s *DeploymentService) Record(ctx context.Context, event models.DeploymentEvent) (models.Deployment, error) {
+	service := strings.TrimSpace(event.Service)
+	if service == "" {
+		return models.Deployment{}, errors.New("service is required")
+	}
+	if len(service) > maxDeploymentServiceLength {
+		return models.Deployment{}, fmt.Errorf("service must be at most %d characters", maxDeploymentServiceLength)
+	}
+	now := time.Now()
+	deployment := models.Deployment{
+		ID:          primitive.NewObjectID(),
+		Service:     service,
+		Environment: strings.TrimSpace(event.Environment),
+		Version:     strings.TrimSpace(event.Version),
+		Status:      strings.ToLower(strings.TrimSpace(event.Status)),
+		URL:         strings.TrimSpace(event.URL),
+		DeployedBy:  strings.TrimSpace(event.DeployedBy),
+		DeployedAt:  now,
+		ReceivedAt:  now,
+	}
+	if event.DeployedAt != nil {
+		deployment.DeployedAt = *event.DeployedAt
+	}
+	_, err := s.collection().InsertOne(ctx, deployment)
+	return deployment, err
+}
+
+// List returns the latest deployments, of one service when service is set.
+func (s *DeploymentService) List(ctx context.Context, service string, limit int64) ([]models.Deployment, error) {
+	filter := bson.M{}
+	if service != "" {
+		filter["service"] = service
+	}
+	cursor, err := s.collection().Find(ctx, filter, options.Find().SetSort(bson.D{{"deployedAt", -1}}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	deployments := []models.Deployment{}
+	if err := cursor.All(ctx, &deployments); err != nil {
+		return nil, err
+	}
+	return deployments, nil
+}
+
+// Recent groups the deployments in the correlation window before at by
+// service, services mentioned in text first, then the most deployed.
+func (s *DeploymentService) Recent(ctx context.Context, text string, at time.Time) ([]models.RecentDeployments, error) {
+	groups := []models.RecentDeployments{}
+	if s.window <= 0 {
+		return groups, nil
+	}
+	cursor, err := s.collection().Find(ctx, bson.M{
+		"deployedAt": bson.M{"$gte": at.Add(-s.window), "$lte": at},
+	}, options.Find().SetSort(bson.D{{"deployedAt", -1}}).SetLimit(500))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var deployments []models.Deployment
+	if err := cursor.All(ctx, &deployments); err != nil {
+		return nil, err
+	}
+
+	index := map[string]int{}
+	for _, d := range deployments {
+		i, ok := index[d.Service]
+		if !ok {
+			i = len(groups)
+			index[d.Service] = i
+			groups = append(groups, models.RecentDeployments{
+				Service:        d.Service,
+				LastDeployedAt: d.DeployedAt,
+				Mentioned:      mentions(text, d.Service),
+				Deployments:    []models.Deployment{},
+			})
+		}
+		groups[i].Count++
+		groups[i].Deployments = append(groups[i].Deployments, d)
+	}
+
+	period := "in the last " + describeWindow(s.window)
+	if time.Since(at) > time.Minute {
+		period = "in the " + describeWindow(s.window) + " before the ticket was raised"
+	}
+	for i := range groups {
+		noun := "deployments"
+		if groups[i].Count == 1 {
+			noun = "deployment"
+		}
+		groups[i].Summary = fmt.Sprintf("%d %s to %s %s", groups[i].Count, noun, groups[i].Service, period)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Mentioned != groups[j].Mentioned {
+			return groups[i].Mentioned
+		}
+		return groups[i].Count > groups[j].Count
+	})
+	return groups, nil
+}
+
+// Mentioned returns the summaries of the recent deployments to services
+// text names, for triage reasoning.
+func (s *DeploymentService) Mentioned(ctx context.Context, text string) ([]models.RecentDeployments, error) {
+	groups, err := s.Recent(ctx, text, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	mentioned := []models.RecentDeployments{}
+	for _, g := range groups {
+		if g.Mentioned {
+			mentioned = append(mentioned, g)
+		}
+	}
+	return mentioned, nil
+}
+
+// describeWindow phrases a duration as "hour", "3 hours" or "30 minutes".
+func describeWindow(d time.Duration) string {
+	switch {
+	case d == time.Hour:
+		return "hour"
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%d hours", d/time.Hour)
+	default:
+		return fmt.Sprintf("%d minutes", d/time.Minute)
+	}
+}
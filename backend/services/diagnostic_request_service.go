@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var (
+	ErrDiagnosticLinkInvalid = errors.New("invalid diagnostic request link")
+	ErrDiagnosticLinkExpired = errors.New("diagnostic request has expired or was already answered")
+)
+
+var diagnosticItems = []string{models.DiagnosticItemSystem, models.DiagnosticItemNetwork, models.DiagnosticItemEventLogs}
+
+// Upload limits for the free-text fields of SystemInfo.
+const (
+	maxIPConfigBytes  = 16 * 1024
+	maxEventLogBytes  = 64 * 1024
+	maxSystemInfoKeys = 50
+)
+
+// systemInfoPromptTokens bounds how much of the latest system information
+// goes into solution prompts.
+const systemInfoPromptTokens = 800
+
+// DiagnosticRequestService issues signed, single-use links through which a
+// requester or an endpoint agent uploads system information for a ticket.
+// Tokens have the same "<requestID>.<expiry>.<signature>" shape as share
+// links but are signed for a different purpose, so one cannot stand in for
+// the other.
+type DiagnosticRequestService struct {
+	db         *database.MongoDB
+	archive    *TicketArchiveService
+	secret     []byte
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+func NewDiagnosticRequestService(db *database.MongoDB, archive *TicketArchiveService, cfg *config.Config) *DiagnosticRequestService {
+	return &DiagnosticRequestService{
+		db:         db,
+		archive:    archive,
+		secret:     []byte(cfg.JWTSecret),
+		defaultTTL: cfg.ShareLinkDefaultTTL,
+		maxTTL:     cfg.ShareLinkMaxTTL,
+	}
+}
+
+// ParseTTL returns the requested link lifetime, or the default when empty.
+func (s *DiagnosticRequestService) ParseTTL(expiresIn string) (time.Duration, error) {
+	return parseLinkTTL(expiresIn, s.defaultTTL, s.maxTTL)
+}
+
+// ValidDiagnosticItems reports whether every item can be requested.
+func ValidDiagnosticItems(items []string) bool {
+	for _, item := range items {
+		known := false
+		for _, valid := range diagnosticItems {
+			known = known || item == valid
+		}
+		if !known {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *DiagnosticRequestService) collection() *mongo.Collection {
+	return s.db.GetCollection("diagnostic_requests")
+}
+
+// Create stores a diagnostic request for a ticket and returns it with its
+// token. Without an agentID the request goes to the agent linked to the
+// ticket, if any.
+func (s *DiagnosticRequestService) Create(ctx context.Context, ticketID primitive.ObjectID, req models.CreateDiagnosticRequestRequest, agentID *primitive.ObjectID, ttl time.Duration, userID primitive.ObjectID) (models.DiagnosticRequest, string, error) {
+	var ticket models.Ticket
+	err := s.db.GetCollection("tickets").FindOne(ctx, bson.M{"_id": ticketID},
+		options.FindOne().SetProjection(bson.M{"endpointAgentId": 1})).Decode(&ticket)
+	if err != nil {
+		return models.DiagnosticRequest{}, "", err
+	}
+	if agentID == nil {
+		agentID = ticket.EndpointAgentID
+	}
+
+	items := req.Items
+	if len(items) == 0 {
+		items = diagnosticItems
+	}
+	now := time.Now()
+	request := models.DiagnosticRequest{
+		ID:          primitive.NewObjectID(),
+		TicketID:    ticketID,
+		Items:       items,
+		Message:     req.Message,
+		Status:      models.DiagnosticRequestPending,
+		ExpiresAt:   now.Add(ttl).Truncate(time.Second),
+		RequestedBy: userID,
+		RequestedAt: now,
+		AgentID:     agentID,
+	}
+	if _, err := s.collection().InsertOne(ctx, request); err != nil {
+		return models.DiagnosticRequest{}, "", err
+	}
+	return request, s.sign(request.ID, request.ExpiresAt), nil
+}
+
+// List returns the diagnostic requests of a ticket, newest first.
+func (s *DiagnosticRequestService) List(ctx context.Context, ticketID primitive.ObjectID) ([]models.DiagnosticRequest, error) {
+	cursor, err := s.collection().Find(ctx, bson.M{"ticketId": ticketID}, options.Find().SetSort(bson.D{{"requestedAt", -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	requests := []models.DiagnosticRequest{}
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// PendingForAgent returns the unexpired requests routed to an agent with
+// the paths to upload their results to.
+func (s *DiagnosticRequestService) PendingForAgent(ctx context.Context, agentID primitive.ObjectID) ([]models.AgentDiagnosticTask, error) {
+	cursor, err := s.collection().Find(ctx, bson.M{
+		"agentId":   agentID,
+		"status":    models.DiagnosticRequestPending,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}, options.Find().SetSort(bson.D{{"requestedAt", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var requests []models.DiagnosticRequest
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+
+	tasks := []models.AgentDiagnosticTask{}
+	for _, request := range requests {
+		tasks = append(tasks, models.AgentDiagnosticTask{
+			RequestID:  request.ID,
+			TicketID:   request.TicketID,
+			Items:      request.Items,
+			UploadPath: "/api/public/diagnostics/" + s.sign(request.ID, request.ExpiresAt),
+			ExpiresAt:  request.ExpiresAt,
+		})
+	}
+	return tasks, nil
+}
+
+// pending verifies a token and returns its request while it can still be
+// answered.
+func (s *DiagnosticRequestService) pending(ctx context.Context, token string) (models.DiagnosticRequest, error) {
+	requestID, expiresAt, err := s.verify(token)
+	if err != nil {
+		return models.DiagnosticRequest{}, err
+	}
+
+	var request models.DiagnosticRequest
+	if err := s.collection().FindOne(ctx, bson.M{"_id": requestID}).Decode(&request); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.DiagnosticRequest{}, ErrDiagnosticLinkInvalid
+		}
+		return models.DiagnosticRequest{}, err
+	}
+	if request.Status != models.DiagnosticRequestPending || time.Now().After(expiresAt) || !request.ExpiresAt.Equal(expiresAt) {
+		return models.DiagnosticRequest{}, ErrDiagnosticLinkExpired
+	}
+	return request, nil
+}
+
+// View returns what a diagnostic request link asks for.
+func (s *DiagnosticRequestService) View(ctx context.Context, token string) (models.DiagnosticRequestView, error) {
+	request, err := s.pending(ctx, token)
+	if err != nil {
+		return models.DiagnosticRequestView{}, err
+	}
+
+	ticket, err := s.archive.Get(ctx, request.TicketID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.DiagnosticRequestView{}, ErrDiagnosticLinkInvalid
+		}
+		return models.DiagnosticRequestView{}, err
+	}
+
+	return models.DiagnosticRequestView{
+		TicketTitle: ticket.Title,
+		Items:       request.Items,
+		Message:     request.Message,
+		ExpiresAt:   request.ExpiresAt,
+	}, nil
+}
+
+// Submit attaches uploaded system information to the request's ticket and
+// closes the request. It returns the ticket ID.
+func (s *DiagnosticRequestService) Submit(ctx context.Context, token string, info models.SystemInfo, ip string) (primitive.ObjectID, error) {
+	request, err := s.pending(ctx, token)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	// Mark the request answered first so a link is only ever used once
+	now := time.Now()
+	result, err := s.collection().UpdateOne(ctx, bson.M{"_id": request.ID, "status": models.DiagnosticRequestPending}, bson.M{
+		"$set": bson.M{"status": models.DiagnosticRequestReceived, "receivedAt": now, "receivedIp": ip},
+	})
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if result.ModifiedCount == 0 {
+		return primitive.NilObjectID, ErrDiagnosticLinkExpired
+	}
+
+	info = sanitizeSystemInfo(info)
+	info.RequestID = request.ID
+	info.CollectedAt = now
+	if info.Source == "" {
+		info.Source = "requester"
+	}
+	_, err = s.db.GetCollection("tickets").UpdateByID(ctx, request.TicketID, bson.M{
+		"$push": bson.M{"systemInfo": info},
+		"$set":  bson.M{"updatedAt": now},
+	})
+	return request.TicketID, err
+}
+
+func sanitizeSystemInfo(info models.SystemInfo) models.SystemInfo {
+	info.Hostname = truncateBytes(strings.TrimSpace(info.Hostname), 255)
+	info.OS = truncateBytes(strings.TrimSpace(info.OS), 255)
+	info.OSVersion = truncateBytes(strings.TrimSpace(info.OSVersion), 255)
+	info.Architecture = truncateBytes(strings.TrimSpace(info.Architecture), 64)
+	info.Source = truncateBytes(strings.TrimSpace(info.Source), 64)
+	info.IPConfig = truncateBytes(info.IPConfig, maxIPConfigBytes)
+	// Keep the most recent log entries, which come last
+	if len(info.EventLogs) > maxEventLogBytes {
+		info.EventLogs = strings.ToValidUTF8(info.EventLogs[len(info.EventLogs)-maxEventLogBytes:], "")
+	}
+	extra := map[string]string{}
+	for k, v := range info.Extra {
+		if len(extra) == maxSystemInfoKeys {
+			break
+		}
+		extra[truncateBytes(k, 64)] = truncateBytes(v, 1024)
+	}
+	info.Extra = extra
+	return info
+}
+
+func truncateBytes(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "")
+}
+
+// SystemInfoText renders uploaded system information for prompts and the
+// ticket thread.
+func SystemInfoText(info models.SystemInfo) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Collected %s from %s\n", info.CollectedAt.Format(time.RFC822), info.Source))
+	if info.OS != "" {
+		b.WriteString(fmt.Sprintf("OS: %s %s %s\n", info.OS, info.OSVersion, info.Architecture))
+	}
+	if info.Hostname != "" {
+		b.WriteString("Hostname: " + info.Hostname + "\n")
+	}
+	for k, v := range info.Extra {
+		b.WriteString(k + ": " + v + "\n")
+	}
+	if info.IPConfig != "" {
+		b.WriteString("IP configuration:\n" + info.IPConfig + "\n")
+	}
+	if info.EventLogs != "" {
+		b.WriteString("Recent event logs:\n" + info.EventLogs + "\n")
+	}
+	return b.String()
+}
+
+// LatestSystemInfo returns the most recent upload for a ticket, if any.
+func LatestSystemInfo(ticket models.Ticket) *models.SystemInfo {
+	if len(ticket.SystemInfo) == 0 {
+		return nil
+	}
+	return &ticket.SystemInfo[len(ticket.SystemInfo)-1]
+}
+
+// SolutionQuery is the documentation search query for a ticket. The
+// reported OS is included so platform-specific docs rank higher.
+func SolutionQuery(ticket models.Ticket) string {
+	query := fmt.Sprintf("%s %s %s", ticket.Title, ticket.Description, string(ticket.Category))
+	if info := LatestSystemInfo(ticket); info != nil && info.OS != "" {
+		query += " " + info.OS + " " + info.OSVersion
+	}
+	return query
+}
+
+func (s *DiagnosticRequestService) sign(requestID primitive.ObjectID, expiresAt time.Time) string {
+	payload := requestID.Hex() + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("diagnostics:" + payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *DiagnosticRequestService) verify(token string) (primitive.ObjectID, time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return primitive.NilObjectID, time.Time{}, ErrDiagnosticLinkInvalid
+	}
+	requestID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil {
+		return primitive.NilObjectID, time.Time{}, ErrDiagnosticLinkInvalid
+	}
+	unix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return primitive.NilObjectID, time.Time{}, ErrDiagnosticLinkInvalid
+	}
+	expiresAt := time.Unix(unix, 0)
+	if !hmac.Equal([]byte(s.sign(requestID, expiresAt)), []byte(token)) {
+		return primitive.NilObjectID, time.Time{}, ErrDiagnosticLinkInvalid
+	}
+	return requestID, expiresAt, nil
+}
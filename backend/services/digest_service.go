@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const digestSystemPrompt = "You are an IT operations analyst writing a weekly digest for the operations team. Be concise and factual; only use the numbers you are given."
+
+// DigestService builds the weekly operations digest and delivers it through
+// the notification channels.
+type DigestService struct {
+	db            *database.MongoDB
+	llm           *LLMService
+	notifications *NotificationService
+	cfg           *config.Config
+}
+
+func NewDigestService(db *database.MongoDB, llm *LLMService, notifications *NotificationService, cfg *config.Config) *DigestService {
+	return &DigestService{db: db, llm: llm, notifications: notifications, cfg: cfg}
+}
+
+// Start checks hourly whether the configured weekday and hour have come and
+// sends the digest once per week.
+func (s *DigestService) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case now := <-ticker.C:
+				if now.Weekday() != s.cfg.DigestWeekday || now.Hour() != s.cfg.DigestHour {
+					continue
+				}
+				// Guard against restarts sending a second digest the same day.
+				count, err := s.db.GetCollection("digests").CountDocuments(ctx, bson.M{"createdAt": bson.M{"$gte": now.Add(-20 * time.Hour)}})
+				if err != nil || count > 0 {
+					continue
+				}
+				if _, err := s.Generate(ctx, now.Truncate(time.Hour), true); err != nil {
+					log.Printf("weekly digest error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Generate builds the digest for the seven days before end, stores it and
+// optionally sends it.
+func (s *DigestService) Generate(ctx context.Context, end time.Time, send bool) (models.Digest, error) {
+	start := end.AddDate(0, 0, -7)
+	stats, err := s.collectStats(ctx, start, end)
+	if err != nil {
+		return models.Digest{}, err
+	}
+
+	digest := models.Digest{
+		ID:          primitive.NewObjectID(),
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Stats:       stats,
+		CreatedAt:   time.Now(),
+	}
+
+	narrative, err := s.narrate(ctx, stats)
+	if err != nil {
+		if err != ErrLLMUnavailable {
+			log.Printf("Digest narrative failed, using template: %v", err)
+		}
+		narrative = templateDigestNarrative(stats)
+		digest.Fallback = true
+	}
+	digest.Narrative = narrative
+
+	if send {
+		delivered, err := s.notifications.Send(ctx, Notification{
+			Subject:    fmt.Sprintf("Weekly operations digest %s – %s", start.Format("Jan 2"), end.Format("Jan 2")),
+			Body:       digest.Narrative,
+			Recipients: s.cfg.DigestRecipients,
+		})
+		digest.DeliveredVia = delivered
+		if err != nil {
+			digest.DeliveryError = err.Error()
+		}
+	}
+
+	if _, err := s.db.GetCollection("digests").InsertOne(ctx, digest); err != nil {
+		return models.Digest{}, err
+	}
+	return digest, nil
+}
+
+func (s *DigestService) List(ctx context.Context, limit int64) ([]models.Digest, error) {
+	opts := options.Find().SetSort(bson.D{{"createdAt", -1}}).SetLimit(limit)
+	cursor, err := s.db.GetCollection("digests").Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	digests := []models.Digest{}
+	if err := cursor.All(ctx, &digests); err != nil {
+		return nil, err
+	}
+	return digests, nil
+}
+
+func (s *DigestService) Get(ctx context.Context, id primitive.ObjectID) (models.Digest, error) {
+	var digest models.Digest
+	err := s.db.GetCollection("digests").FindOne(ctx, bson.M{"_id": id}).Decode(&digest)
+	return digest, err
+}
+
+func (s *DigestService) collectStats(ctx context.Context, start, end time.Time) (models.DigestStats, error) {
+	prevStart := start.AddDate(0, 0, -7)
+	stats := models.DigestStats{AnomalySeverities: map[string]int64{}}
+	tickets := s.db.GetCollection("tickets")
+
+	// Tickets created this week and the week before, for trends
+	cursor, err := tickets.Find(ctx,
+		bson.M{"createdAt": bson.M{"$gte": prevStart, "$lt": end}},
+		options.Find().SetProjection(bson.M{"category": 1, "subcategory": 1, "createdAt": 1}))
+	if err != nil {
+		return stats, err
+	}
+	var created []models.Ticket
+	err = cursor.All(ctx, &created)
+	cursor.Close(ctx)
+	if err != nil {
+		return stats, err
+	}
+
+	categories := map[string]*models.DigestTrend{}
+	subcategories := map[string]*models.DigestTrend{}
+	count := func(m map[string]*models.DigestTrend, name string, current bool) {
+		if m[name] == nil {
+			m[name] = &models.DigestTrend{Name: name}
+		}
+		if current {
+			m[name].Count++
+		} else {
+			m[name].Previous++
+		}
+	}
+	for _, t := range created {
+		current := !t.CreatedAt.Before(start)
+		if current {
+			stats.TicketsCreated++
+		} else {
+			stats.PreviousCreated++
+		}
+		count(categories, string(t.Category), current)
+		if t.Subcategory != "" {
+			count(subcategories, fmt.Sprintf("%s / %s", t.Category, t.Subcategory), current)
+		}
+	}
+	stats.Categories = sortedTrends(categories, 0)
+	stats.Subcategories = sortedTrends(subcategories, 10)
+
+	// Resolutions in the period
+	cursor, err = tickets.Find(ctx,
+		bson.M{"resolvedAt": bson.M{"$gte": start, "$lt": end}},
+		options.Find().SetProjection(bson.M{"createdAt": 1, "resolvedAt": 1}))
+	if err != nil {
+		return stats, err
+	}
+	var resolved []models.Ticket
+	err = cursor.All(ctx, &resolved)
+	cursor.Close(ctx)
+	if err != nil {
+		return stats, err
+	}
+	var resolutionHours float64
+	for _, t := range resolved {
+		resolutionHours += t.ResolvedAt.Sub(t.CreatedAt).Hours()
+	}
+	stats.TicketsResolved = int64(len(resolved))
+	if len(resolved) > 0 {
+		stats.AvgResolutionHours = math.Round(resolutionHours/float64(len(resolved))*10) / 10
+	}
+
+	stats.OpenBacklog, err = tickets.CountDocuments(ctx, bson.M{"status": bson.M{"$in": []models.TicketStatus{models.StatusOpen, models.StatusInProgress}}})
+	if err != nil {
+		return stats, err
+	}
+
+	// Monitoring anomalies
+	cursor, err = s.db.GetCollection("mon_anomalies").Find(ctx, bson.M{"createdAt": bson.M{"$gte": prevStart, "$lt": end}})
+	if err != nil {
+		return stats, err
+	}
+	var anomalies []models.AnomalyRecord
+	err = cursor.All(ctx, &anomalies)
+	cursor.Close(ctx)
+	if err != nil {
+		return stats, err
+	}
+
+	identifiers := map[primitive.ObjectID]string{}
+	resources := map[string]*models.DigestTrend{}
+	for _, a := range anomalies {
+		current := !a.CreatedAt.Before(start)
+		if current {
+			stats.Anomalies++
+			stats.AnomalySeverities[a.Severity]++
+		}
+		name, ok := identifiers[a.ResourceID]
+		if !ok {
+			var r models.MonitoredResource
+			name = a.ResourceID.Hex()
+			if err := s.db.GetCollection("mon_resources").FindOne(ctx, bson.M{"_id": a.ResourceID}).Decode(&r); err == nil {
+				name = r.Identifier
+			}
+			identifiers[a.ResourceID] = name
+		}
+		count(resources, fmt.Sprintf("%s %s", name, a.MetricName), current)
+	}
+	stats.AnomalyResources = sortedTrends(resources, 5)
+
+	return stats, nil
+}
+
+// sortedTrends orders trends by current count and fills in the change
+// against the previous week. limit <= 0 keeps all entries.
+func sortedTrends(m map[string]*models.DigestTrend, limit int) []models.DigestTrend {
+	trends := make([]models.DigestTrend, 0, len(m))
+	for _, t := range m {
+		if t.Count == 0 && t.Previous == 0 {
+			continue
+		}
+		if t.Previous > 0 {
+			t.ChangePct = math.Round(float64(t.Count-t.Previous) / float64(t.Previous) * 100)
+		}
+		trends = append(trends, *t)
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Count != trends[j].Count {
+			return trends[i].Count > trends[j].Count
+		}
+		return trends[i].Name < trends[j].Name
+	})
+	if limit > 0 && len(trends) > limit {
+		trends = trends[:limit]
+	}
+	return trends
+}
+
+func (s *DigestService) narrate(ctx context.Context, stats models.DigestStats) (string, error) {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	prompt := fmt.Sprintf(`Here are this week's IT operations statistics. "previous" and "changePct" compare with the week before.
+
+%s
+
+Write the weekly digest as 4-8 short bullet points in plain text, most important first. Call out notable increases or decreases (e.g. "VPN issues up 40%%"), recurring problems, monitoring anomalies and the backlog. Finish with one line of suggested focus for next week.`, string(data))
+
+	content, err := s.llm.Complete(ctx, CompletionRequest{
+		System:   digestSystemPrompt,
+		Prompt:   prompt,
+		Endpoint: EndpointDigest,
+	})
+	if err != nil {
+		return "", err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", fmt.Errorf("empty digest narrative")
+	}
+	return content, nil
+}
+
+func templateDigestNarrative(stats models.DigestStats) string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("- %d tickets opened (%s), %d resolved, average resolution %.1fh; %d open in the backlog.",
+		stats.TicketsCreated, describeChange(stats.TicketsCreated, stats.PreviousCreated), stats.TicketsResolved,
+		stats.AvgResolutionHours, stats.OpenBacklog))
+
+	for i, t := range stats.Categories {
+		if i >= 3 {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("- %s: %d tickets (%s).", t.Name, t.Count, describeChange(t.Count, t.Previous)))
+	}
+	for _, t := range stats.Subcategories {
+		if t.Count >= 3 {
+			lines = append(lines, fmt.Sprintf("- Recurring: %d %s tickets.", t.Count, t.Name))
+		}
+	}
+
+	if stats.Anomalies > 0 {
+		line := fmt.Sprintf("- %d monitoring anomalies (%d critical, %d high)", stats.Anomalies,
+			stats.AnomalySeverities["critical"], stats.AnomalySeverities["high"])
+		if len(stats.AnomalyResources) > 0 {
+			line += fmt.Sprintf(", most on %s", stats.AnomalyResources[0].Name)
+		}
+		lines = append(lines, line+".")
+	} else {
+		lines = append(lines, "- No monitoring anomalies this week.")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func describeChange(current, previous int64) string {
+	switch {
+	case previous == 0 && current == 0:
+		return "unchanged"
+	case previous == 0:
+		return "new this week"
+	}
+	pct := math.Round(float64(current-previous) / float64(previous) * 100)
+	switch {
+	case pct > 0:
+		return fmt.Sprintf("up %.0f%%", pct)
+	case pct < 0:
+		return fmt.Sprintf("down %.0f%%", -pct)
+	}
+	return "unchanged"
+}
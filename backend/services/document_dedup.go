@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"intelliops-ai-copilot/models"
+)
+
+// Chunks are near duplicates when their embeddings are similar and at
+// least this share of their word shingles is the same. The wording check
+// keeps unrelated chunks with close embeddings, as the hash-based fallback
+// embeddings can have, from being dropped.
+const duplicateShingleOverlap = 0.6
+
+// Words per shingle when comparing the wording of chunks.
+const shingleWords = 4
+
+// normalizeForHash lowercases text and collapses whitespace, so copies
+// that only differ in formatting hash the same.
+func normalizeForHash(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// ContentHash identifies a document's text regardless of case and
+// whitespace.
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(normalizeForHash(content)))
+	return hex.EncodeToString(sum[:])
+}
+
+// shingles hashes the overlapping word sequences of text.
+func shingles(text string) map[uint64]bool {
+	words := strings.Fields(strings.ToLower(text))
+	set := map[uint64]bool{}
+	if len(words) < shingleWords {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words, " ")))
+		set[h.Sum64()] = true
+		return set
+	}
+	for i := 0; i+shingleWords <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+shingleWords], " ")))
+		set[h.Sum64()] = true
+	}
+	return set
+}
+
+// shingleOverlap is the Jaccard similarity of two shingle sets.
+func shingleOverlap(a, b map[uint64]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for h := range a {
+		if b[h] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// chunkMatch is the indexed chunk most similar to a new chunk.
+type chunkMatch struct {
+	doc        *models.Document
+	similarity float32
+}
+
+// Deduplicate checks a document about to be indexed against the index.
+// An exact copy of another document, or one whose every chunk duplicates
+// indexed chunks, is merged into the document it shares most with: its
+// path is recorded there and the returned duplicate has Merged set, so the
+// caller does not store it. Otherwise repeated chunks are dropped from the
+// returned document. The duplicate is nil when nothing was found.
+func (v *VectorService) Deduplicate(ctx context.Context, doc models.Document) (models.Document, *models.DocumentDuplicate, error) {
+	if doc.ContentHash == "" {
+		doc.ContentHash = ContentHash(doc.Content)
+	}
+
+	v.mu.RLock()
+	others := make([]models.Document, 0, len(v.documents))
+	for _, other := range v.documents {
+		if other.FilePath != doc.FilePath {
+			others = append(others, other)
+		}
+	}
+	v.mu.RUnlock()
+
+	for i := range others {
+		if others[i].ContentHash == doc.ContentHash {
+			dup := &models.DocumentDuplicate{
+				FilePath:      doc.FilePath,
+				Kind:          models.DuplicateExact,
+				DuplicateOf:   others[i].FilePath,
+				DuplicateOfID: &others[i].ID,
+				Similarity:    1,
+				SkippedChunks: len(doc.Chunks),
+				Merged:        true,
+			}
+			return doc, dup, v.merge(ctx, others[i], doc.FilePath)
+		}
+	}
+
+	kept := make([]models.DocumentChunk, 0, len(doc.Chunks))
+	seen := map[string]bool{}
+	matches := map[*models.Document]int{}
+	var best float32
+	for _, chunk := range doc.Chunks {
+		// Repeated within the document itself
+		key := normalizeForHash(chunk.Content)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if match := v.nearestDuplicate(chunk, others); match != nil {
+			matches[match.doc]++
+			if match.similarity > best {
+				best = match.similarity
+			}
+			continue
+		}
+		kept = append(kept, chunk)
+	}
+
+	skipped := len(doc.Chunks) - len(kept)
+	if skipped == 0 {
+		return doc, nil, nil
+	}
+	dup := &models.DocumentDuplicate{
+		FilePath:      doc.FilePath,
+		Kind:          models.DuplicateChunks,
+		Similarity:    best,
+		SkippedChunks: skipped,
+	}
+	var top *models.Document
+	for other, n := range matches {
+		if top == nil || n > matches[top] {
+			top = other
+		}
+	}
+	if top != nil {
+		dup.DuplicateOf, dup.DuplicateOfID = top.FilePath, &top.ID
+	}
+	if len(kept) == 0 {
+		dup.Kind, dup.Merged = models.DuplicateNear, true
+		return doc, dup, v.merge(ctx, *top, doc.FilePath)
+	}
+	doc.Chunks = kept
+	return doc, dup, nil
+}
+
+// nearestDuplicate finds the indexed chunk chunk duplicates, if any.
+func (v *VectorService) nearestDuplicate(chunk models.DocumentChunk, others []models.Document) *chunkMatch {
+	key := normalizeForHash(chunk.Content)
+	var words map[uint64]bool
+	var best *chunkMatch
+	for i := range others {
+		for _, candidate := range others[i].Chunks {
+			if normalizeForHash(candidate.Content) == key {
+				return &chunkMatch{doc: &others[i], similarity: 1}
+			}
+			if v.duplicateSimilarity <= 0 || len(chunk.Embedding) == 0 || len(candidate.Embedding) != len(chunk.Embedding) {
+				continue
+			}
+			similarity := CosineSimilarity(chunk.Embedding, candidate.Embedding)
+			if float64(similarity) < v.duplicateSimilarity || (best != nil && similarity <= best.similarity) {
+				continue
+			}
+			if words == nil {
+				words = shingles(chunk.Content)
+			}
+			if shingleOverlap(words, shingles(candidate.Content)) >= duplicateShingleOverlap {
+				best = &chunkMatch{doc: &others[i], similarity: similarity}
+			}
+		}
+	}
+	return best
+}
+
+// merge records path as a copy of doc and removes any document indexed
+// from path before.
+func (v *VectorService) merge(ctx context.Context, doc models.Document, path string) error {
+	if _, err := v.collection().UpdateOne(ctx, bson.M{"_id": doc.ID}, bson.M{"$addToSet": bson.M{"duplicatePaths": path}}); err != nil {
+		return err
+	}
+	v.mu.Lock()
+	if cached, ok := v.documents[doc.ID]; ok && !containsString(cached.DuplicatePaths, path) {
+		cached.DuplicatePaths = append(append([]string{}, cached.DuplicatePaths...), path)
+		v.documents[doc.ID] = cached
+	}
+	v.mu.Unlock()
+	return v.DeleteDocument(ctx, path)
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// ErrDocumentApprovalState is returned for approval steps that do not
+// apply to the document's current approval status.
+var ErrDocumentApprovalState = errors.New("document is not in a state that allows this")
+
+// deprecatedTerm is a deprecated product name and the pattern finding it as
+// a whole word.
+type deprecatedTerm struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// DocumentReviewService flags indexed documents that are old or mention
+// deprecated products, and opens review tickets for their owners.
+type DocumentReviewService struct {
+	db       *database.MongoDB
+	vector   *VectorService
+	taxonomy *TaxonomyService
+	maxAge   time.Duration
+	terms    []deprecatedTerm
+}
+
+func NewDocumentReviewService(db *database.MongoDB, vector *VectorService, taxonomy *TaxonomyService, cfg *config.Config) *DocumentReviewService {
+	terms := []deprecatedTerm{}
+	for _, name := range cfg.DocumentDeprecatedTerms {
+		terms = append(terms, deprecatedTerm{
+			name:    name,
+			pattern: regexp.MustCompile(`(?i)(^|\W)` + regexp.QuoteMeta(name) + `($|\W)`),
+		})
+	}
+	return &DocumentReviewService{db: db, vector: vector, taxonomy: taxonomy, maxAge: cfg.DocumentReviewMaxAge, terms: terms}
+}
+
+// Check reports why doc is due for review at now, or nil when it is not.
+func (s *DocumentReviewService) Check(doc models.Document, now time.Time) *models.StaleDocument {
+	// Uploads awaiting approval are reviewed through that instead
+	if !doc.Approved() {
+		return nil
+	}
+	since := doc.IndexedAt
+	switch {
+	case doc.LastReviewedAt != nil:
+		since = *doc.LastReviewedAt
+	case doc.ContentChangedAt != nil:
+		since = *doc.ContentChangedAt
+	}
+
+	stale := models.StaleDocument{
+		ID:             doc.ID,
+		Title:          doc.Title,
+		FilePath:       doc.FilePath,
+		OwnerID:        doc.OwnerID,
+		LastReviewedAt: doc.LastReviewedAt,
+		Since:          since,
+		AgeDays:        int(now.Sub(since).Hours() / 24),
+		Reasons:        []string{},
+		ReviewTicketID: doc.ReviewTicketID,
+	}
+	if s.maxAge > 0 && now.Sub(since) > s.maxAge {
+		stale.Reasons = append(stale.Reasons, models.StaleReasonAge)
+	}
+	// Terms mentioned since the last review were not there when the reviewer
+	// looked, or were judged fine; only flag documents changed since
+	if doc.LastReviewedAt == nil || (doc.ContentChangedAt != nil && doc.ContentChangedAt.After(*doc.LastReviewedAt)) {
+		text := doc.Title + "\n" + doc.Content
+		for _, term := range s.terms {
+			if term.pattern.MatchString(text) {
+				stale.DeprecatedTerms = append(stale.DeprecatedTerms, term.name)
+			}
+		}
+		if len(stale.DeprecatedTerms) > 0 {
+			stale.Reasons = append(stale.Reasons, models.StaleReasonDeprecatedTerms)
+		}
+	}
+	if len(stale.Reasons) == 0 {
+		return nil
+	}
+	return &stale
+}
+
+// Stale lists the indexed documents due for review, longest unreviewed
+// first.
+func (s *DocumentReviewService) Stale() []models.StaleDocument {
+	now := time.Now()
+	stale := []models.StaleDocument{}
+	for _, doc := range s.vector.Documents() {
+		if d := s.Check(doc, now); d != nil {
+			stale = append(stale, *d)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Since.Before(stale[j].Since) })
+	return stale
+}
+
+// StartReminders periodically opens review tickets for stale documents.
+func (s *DocumentReviewService) StartReminders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if _, err := s.OpenReviewTickets(ctx); err != nil {
+					log.Printf("document review error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// OpenReviewTickets opens a low-priority review ticket for each stale
+// document that has no open one.
+func (s *DocumentReviewService) OpenReviewTickets(ctx context.Context) (models.DocumentReviewRun, error) {
+	stale := s.Stale()
+	run := models.DocumentReviewRun{Stale: len(stale)}
+	if len(stale) == 0 {
+		return run, nil
+	}
+
+	var admin models.User
+	if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"role": models.RoleAdmin}).Decode(&admin); err != nil {
+		return run, fmt.Errorf("no admin to open review tickets as: %w", err)
+	}
+
+	for _, doc := range stale {
+		if doc.ReviewTicketID != nil {
+			open, err := s.db.GetCollection("tickets").CountDocuments(ctx, bson.M{
+				"_id":    *doc.ReviewTicketID,
+				"status": bson.M{"$in": bson.A{models.StatusOpen, models.StatusInProgress}},
+			})
+			if err != nil {
+				return run, err
+			}
+			if open > 0 {
+				continue
+			}
+		}
+		if err := s.openReviewTicket(ctx, doc, admin); err != nil {
+			log.Printf("Failed to open review ticket for document %s: %v", doc.FilePath, err)
+			continue
+		}
+		run.TicketsOpened++
+	}
+	return run, nil
+}
+
+func (s *DocumentReviewService) openReviewTicket(ctx context.Context, doc models.StaleDocument, admin models.User) error {
+	taxonomy := s.taxonomy.Get(ctx)
+	category := DefaultCategory(taxonomy)
+	if FindCategory(taxonomy, models.CategoryOther) != nil {
+		category = models.CategoryOther
+	}
+	priority := models.PriorityLow
+	if FindPriority(taxonomy, priority) == nil {
+		priority = DefaultPriority(taxonomy)
+	}
+
+	var reasons []string
+	for _, reason := range doc.Reasons {
+		switch reason {
+		case models.StaleReasonAge:
+			if doc.LastReviewedAt != nil {
+				reasons = append(reasons, fmt.Sprintf("- Last reviewed %d days ago", doc.AgeDays))
+			} else {
+				reasons = append(reasons, fmt.Sprintf("- Not reviewed, and unchanged for %d days", doc.AgeDays))
+			}
+		case models.StaleReasonDeprecatedTerms:
+			reasons = append(reasons, "- Mentions deprecated products: "+strings.Join(doc.DeprecatedTerms, ", "))
+		}
+	}
+	description := fmt.Sprintf("The document %s (%s) may be out of date:\n%s\n\nCheck it is still accurate, update it if needed and mark it reviewed.",
+		doc.Title, doc.FilePath, strings.Join(reasons, "\n"))
+
+	now := time.Now()
+	ticket := models.Ticket{
+		ID:          primitive.NewObjectID(),
+		Title:       "Document review: " + doc.Title,
+		Description: description,
+		Category:    category,
+		Priority:    priority,
+		Status:      models.StatusOpen,
+		AssignedTo:  doc.OwnerID,
+		CreatedBy:   admin.ID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := s.db.GetCollection("tickets").InsertOne(ctx, ticket); err != nil {
+		return err
+	}
+
+	_, err := s.update(ctx, doc.ID, bson.M{"$set": bson.M{"reviewTicketId": ticket.ID}})
+	return err
+}
+
+// MarkReviewed records that reviewer confirmed the document is current and
+// resolves its open review ticket.
+func (s *DocumentReviewService) MarkReviewed(ctx context.Context, id, reviewer primitive.ObjectID) (models.Document, error) {
+	now := time.Now()
+	doc, err := s.update(ctx, id, bson.M{"$set": bson.M{"lastReviewedAt": now, "reviewedBy": reviewer}})
+	if err != nil {
+		return doc, err
+	}
+	if doc.ReviewTicketID == nil {
+		return doc, nil
+	}
+	tickets := s.db.GetCollection("tickets")
+	filter := bson.M{"_id": *doc.ReviewTicketID, "status": bson.M{"$in": bson.A{models.StatusOpen, models.StatusInProgress}}}
+	var ticket models.Ticket
+	if err := tickets.FindOne(ctx, filter).Decode(&ticket); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return doc, nil
+		}
+		return doc, err
+	}
+	// A review ticket split into sub-tasks stays open until they are done
+	update := bson.M{"$set": bson.M{"updatedAt": now}}
+	if err := ResolveTicketStatus(ctx, s.db, update, ticket, now); err != nil {
+		if errors.Is(err, ErrInvalidStatusTransition) {
+			return doc, nil
+		}
+		return doc, err
+	}
+	filter["status"] = ticket.Status
+	_, err = tickets.UpdateOne(ctx, filter, update)
+	return doc, err
+}
+
+// Get returns an indexed document.
+func (s *DocumentReviewService) Get(ctx context.Context, id primitive.ObjectID) (models.Document, error) {
+	var doc models.Document
+	err := s.vector.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+	return doc, err
+}
+
+// SetPeople sets the owner and reviewer of a document. Nil leaves a field
+// alone and NilObjectID clears it.
+func (s *DocumentReviewService) SetPeople(ctx context.Context, id primitive.ObjectID, owner, reviewer *primitive.ObjectID) (models.Document, error) {
+	set, unset := bson.M{}, bson.M{}
+	for field, value := range map[string]*primitive.ObjectID{"ownerId": owner, "reviewerId": reviewer} {
+		switch {
+		case value == nil:
+		case value.IsZero():
+			unset[field] = ""
+		default:
+			set[field] = *value
+		}
+	}
+	change := bson.M{}
+	if len(set) > 0 {
+		change["$set"] = set
+	}
+	if len(unset) > 0 {
+		change["$unset"] = unset
+	}
+	if len(change) == 0 {
+		return s.Get(ctx, id)
+	}
+	return s.update(ctx, id, change)
+}
+
+// Approvals lists uploaded documents with the given approval status, or
+// all uploads when status is empty, without their chunks.
+func (s *DocumentReviewService) Approvals(status models.DocumentApprovalStatus) []models.Document {
+	docs := []models.Document{}
+	for _, doc := range s.vector.Documents() {
+		if doc.ApprovalStatus == "" || (status != "" && doc.ApprovalStatus != status) {
+			continue
+		}
+		doc.Chunks = nil
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].UpdatedAt.After(docs[j].UpdatedAt) })
+	return docs
+}
+
+// Submit asks for a draft or rejected document to be approved, by reviewer
+// when given.
+func (s *DocumentReviewService) Submit(ctx context.Context, id primitive.ObjectID, reviewer *primitive.ObjectID) (models.Document, error) {
+	set := bson.M{"approvalStatus": models.DocumentPending, "submittedAt": time.Now()}
+	if reviewer != nil {
+		set["reviewerId"] = *reviewer
+	}
+	return s.transition(ctx, id, []models.DocumentApprovalStatus{models.DocumentDraft, models.DocumentRejected},
+		bson.M{"$set": set, "$unset": bson.M{"approvalNote": ""}})
+}
+
+// Approve publishes a pending document so it can be cited in solutions.
+func (s *DocumentReviewService) Approve(ctx context.Context, id, by primitive.ObjectID) (models.Document, error) {
+	return s.transition(ctx, id, []models.DocumentApprovalStatus{models.DocumentPending}, bson.M{
+		"$set":   bson.M{"approvalStatus": models.DocumentApproved, "decidedBy": by, "decidedAt": time.Now()},
+		"$unset": bson.M{"approvalNote": ""},
+	})
+}
+
+// Reject sends a pending document back to its owner with a note.
+func (s *DocumentReviewService) Reject(ctx context.Context, id, by primitive.ObjectID, note string) (models.Document, error) {
+	return s.transition(ctx, id, []models.DocumentApprovalStatus{models.DocumentPending}, bson.M{
+		"$set": bson.M{"approvalStatus": models.DocumentRejected, "decidedBy": by, "decidedAt": time.Now(), "approvalNote": note},
+	})
+}
+
+// transition applies change to a document whose approval status is one of
+// from.
+func (s *DocumentReviewService) transition(ctx context.Context, id primitive.ObjectID, from []models.DocumentApprovalStatus, change bson.M) (models.Document, error) {
+	var doc models.Document
+	err := s.vector.collection().FindOneAndUpdate(ctx, bson.M{"_id": id, "approvalStatus": bson.M{"$in": from}}, change,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		if _, err := s.Get(ctx, id); err != nil {
+			return doc, err
+		}
+		return doc, ErrDocumentApprovalState
+	}
+	if err != nil {
+		return doc, err
+	}
+	s.vector.cache(doc)
+	return doc, nil
+}
+
+// update applies change to a document and refreshes the index cache.
+func (s *DocumentReviewService) update(ctx context.Context, id primitive.ObjectID, change bson.M) (models.Document, error) {
+	var doc models.Document
+	err := s.vector.collection().FindOneAndUpdate(ctx, bson.M{"_id": id}, change,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+	if err != nil {
+		return doc, err
+	}
+	s.vector.cache(doc)
+	return doc, nil
+}
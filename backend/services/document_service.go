@@ -20,8 +20,9 @@ func NewDocumentService(vectorService *VectorService) *DocumentService {
 	}
 }
 
-// ProcessDocument processes a single document file
-func (s *DocumentService) ProcessDocument(filePath string) (models.Document, error) {
+// ProcessDocument processes a single document file and reports the
+// embedding work it took
+func (s *DocumentService) ProcessDocument(filePath string) (models.Document, models.EmbeddingStats, error) {
 	ext := strings.ToLower(filepath.Ext(filePath))
 
 	var content string
@@ -33,24 +34,28 @@ func (s *DocumentService) ProcessDocument(filePath string) (models.Document, err
 	case ".md", ".txt":
 		content, err = s.extractTextContent(filePath)
 	default:
-		return models.Document{}, fmt.Errorf("unsupported file type: %s", ext)
+		return models.Document{}, models.EmbeddingStats{}, fmt.Errorf("unsupported file type: %s", ext)
 	}
 
 	if err != nil {
-		return models.Document{}, err
+		return models.Document{}, models.EmbeddingStats{}, err
 	}
 
+	doc, stats := s.IndexText(filepath.Base(filePath), filePath, ext, content)
+	return doc, stats, nil
+}
+
+// IndexText chunks and embeds content that does not come from a file, such
+// as a knowledge base article. filePath identifies it in the index.
+func (s *DocumentService) IndexText(title, filePath, fileType, content string) (models.Document, models.EmbeddingStats) {
 	// Chunk the content
 	chunks := s.chunkContent(content, 500) // 500 tokens per chunk
 
-	// Generate embeddings for each chunk
+	// Generate embeddings for all chunks in batches
+	embeddings, stats := s.vectorService.GenerateEmbeddings(chunks)
 	documentChunks := make([]models.DocumentChunk, 0, len(chunks))
 	for i, chunkText := range chunks {
-		embedding, err := s.vectorService.GenerateEmbedding(chunkText)
-		if err != nil {
-			// Continue without embedding if it fails
-			embedding = []float32{}
-		}
+		embedding := embeddings[i]
 
 		documentChunks = append(documentChunks, models.DocumentChunk{
 			ID:        fmt.Sprintf("%s_chunk_%d", filepath.Base(filePath), i),
@@ -65,18 +70,19 @@ func (s *DocumentService) ProcessDocument(filePath string) (models.Document, err
 	summary := s.generateSummary(content)
 
 	doc := models.Document{
-		Title:     filepath.Base(filePath),
-		FilePath:  filePath,
-		FileType:  ext,
-		Content:   content,
-		Summary:   summary,
-		Tags:      s.extractTags(content),
-		Chunks:    documentChunks,
-		IndexedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Title:       title,
+		FilePath:    filePath,
+		FileType:    fileType,
+		Content:     content,
+		Summary:     summary,
+		Tags:        s.extractTags(content),
+		Chunks:      documentChunks,
+		ContentHash: ContentHash(content),
+		IndexedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 
-	return doc, nil
+	return doc, stats
 }
 
 // extractPDFContent extracts text from PDF files
@@ -111,17 +117,20 @@ func (s *DocumentService) chunkContent(content string, maxTokens int) []string {
 			continue
 		}
 
-		paraTokens := len(strings.Fields(para))
+		// Paragraphs longer than a chunk are split at word boundaries
+		for _, piece := range splitByTokens(para, maxTokens) {
+			pieceTokens := EstimateTokens(piece)
 
-		if currentTokens+paraTokens > maxTokens && currentChunk.Len() > 0 {
-			chunks = append(chunks, currentChunk.String())
-			currentChunk.Reset()
-			currentTokens = 0
-		}
+			if currentTokens+pieceTokens > maxTokens && currentChunk.Len() > 0 {
+				chunks = append(chunks, currentChunk.String())
+				currentChunk.Reset()
+				currentTokens = 0
+			}
 
-		currentChunk.WriteString(para)
-		currentChunk.WriteString("\n\n")
-		currentTokens += paraTokens
+			currentChunk.WriteString(piece)
+			currentChunk.WriteString("\n\n")
+			currentTokens += pieceTokens + 1
+		}
 	}
 
 	if currentChunk.Len() > 0 {
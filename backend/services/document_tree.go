@@ -0,0 +1,201 @@
+package services
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"intelliops-ai-copilot/models"
+)
+
+// isIndexableDocument reports whether IndexDocuments picks up path.
+func isIndexableDocument(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pdf", ".md", ".txt":
+		return true
+	}
+	return false
+}
+
+// BuildDocumentTree lays out the documents under root as folders and
+// matches them against the indexed documents. withDocuments lists the
+// documents of each folder as well as their counts.
+func BuildDocumentTree(root string, indexed []models.Document, withDocuments bool) (models.DocumentTree, error) {
+	root = filepath.Clean(root)
+	byPath := map[string]models.Document{}
+	for _, doc := range indexed {
+		byPath[filepath.Clean(doc.FilePath)] = doc
+	}
+
+	rootFolder := &models.DocumentFolder{Name: filepath.Base(root), Path: root}
+	folders := map[string]*models.DocumentFolder{root: rootFolder}
+	// folder returns the folder at dir, creating it and its parents
+	var folder func(dir string) *models.DocumentFolder
+	folder = func(dir string) *models.DocumentFolder {
+		if f, ok := folders[dir]; ok {
+			return f
+		}
+		f := &models.DocumentFolder{Name: filepath.Base(dir), Path: dir}
+		folders[dir] = f
+		folder(filepath.Dir(dir))
+		return f
+	}
+	entries := map[string][]models.DocumentEntry{}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			folder(path)
+			return nil
+		}
+		if !isIndexableDocument(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		modified := info.ModTime()
+		entry := models.DocumentEntry{
+			Name:       d.Name(),
+			Path:       path,
+			FileType:   strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."),
+			Status:     models.DocumentNotIndexed,
+			Size:       info.Size(),
+			ModifiedAt: &modified,
+		}
+		if doc, ok := byPath[path]; ok {
+			indexDocumentEntry(&entry, doc)
+			if modified.After(doc.IndexedAt) {
+				entry.Status = models.DocumentStale
+			}
+			delete(byPath, path)
+		}
+		dir := filepath.Dir(path)
+		folder(dir)
+		entries[dir] = append(entries[dir], entry)
+		return nil
+	})
+	if err != nil {
+		return models.DocumentTree{}, err
+	}
+
+	// What is left was indexed from files since removed under root, or
+	// never came from root at all
+	collections := map[string][]models.DocumentEntry{}
+	for path, doc := range byPath {
+		entry := models.DocumentEntry{Name: filepath.Base(path), Path: path, FileType: doc.FileType}
+		indexDocumentEntry(&entry, doc)
+		if strings.HasPrefix(path, root+string(filepath.Separator)) {
+			entry.Status = models.DocumentMissing
+			dir := filepath.Dir(path)
+			folder(dir)
+			entries[dir] = append(entries[dir], entry)
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimPrefix(filepath.ToSlash(path), "/"), "/")
+		collections[name] = append(collections[name], entry)
+	}
+
+	tree := models.DocumentTree{
+		Root:        fillDocumentFolder(root, folders, entries, withDocuments),
+		Collections: []models.DocumentFolder{},
+	}
+	for name, docs := range collections {
+		c := models.DocumentFolder{Name: name, Path: name, Folders: []models.DocumentFolder{}}
+		tallyDocumentFolder(&c, docs, withDocuments)
+		tree.Collections = append(tree.Collections, c)
+	}
+	sort.Slice(tree.Collections, func(i, j int) bool { return tree.Collections[i].Name < tree.Collections[j].Name })
+	return tree, nil
+}
+
+// fillDocumentFolder builds the folder at dir with its subfolders and
+// counts.
+func fillDocumentFolder(dir string, folders map[string]*models.DocumentFolder, entries map[string][]models.DocumentEntry, withDocuments bool) models.DocumentFolder {
+	f := *folders[dir]
+	f.Folders = []models.DocumentFolder{}
+	var children []string
+	for path := range folders {
+		if path != dir && filepath.Dir(path) == dir {
+			children = append(children, path)
+		}
+	}
+	sort.Strings(children)
+	for _, child := range children {
+		sub := fillDocumentFolder(child, folders, entries, withDocuments)
+		f.Files += sub.Files
+		f.Indexed += sub.Indexed
+		f.Stale += sub.Stale
+		f.Missing += sub.Missing
+		f.Chunks += sub.Chunks
+		f.LastIndexedAt = laterTime(f.LastIndexedAt, sub.LastIndexedAt)
+		f.Folders = append(f.Folders, sub)
+	}
+	tallyDocumentFolder(&f, entries[dir], withDocuments)
+	return f
+}
+
+// tallyDocumentFolder adds a folder's own documents to its counts and sets
+// its status.
+func tallyDocumentFolder(f *models.DocumentFolder, docs []models.DocumentEntry, withDocuments bool) {
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+	for _, doc := range docs {
+		switch doc.Status {
+		case models.DocumentMissing:
+			f.Missing++
+		case models.DocumentNotIndexed:
+			f.Files++
+		case models.DocumentStale:
+			f.Files++
+			f.Indexed++
+			f.Stale++
+		default:
+			// Collections have no files on disk
+			if doc.ModifiedAt != nil {
+				f.Files++
+			}
+			f.Indexed++
+		}
+		f.Chunks += doc.Chunks
+		f.LastIndexedAt = laterTime(f.LastIndexedAt, doc.IndexedAt)
+	}
+	if withDocuments {
+		f.Documents = docs
+	}
+
+	switch {
+	case f.Files == 0 && f.Indexed == 0 && f.Missing == 0:
+		f.Status = models.DocumentEmpty
+	case f.Indexed == 0 && f.Missing == 0:
+		f.Status = models.DocumentNotIndexed
+	case f.Stale == 0 && f.Missing == 0 && f.Indexed >= f.Files:
+		f.Status = models.DocumentIndexed
+	default:
+		f.Status = models.DocumentPartial
+	}
+}
+
+func indexDocumentEntry(entry *models.DocumentEntry, doc models.Document) {
+	id, indexedAt := doc.ID, doc.IndexedAt
+	entry.ID = &id
+	entry.Title = doc.Title
+	entry.Status = models.DocumentIndexed
+	entry.Chunks = len(doc.Chunks)
+	entry.IndexedAt = &indexedAt
+}
+
+func laterTime(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var ErrEndpointAgentNotFound = errors.New("endpoint agent not found")
+
+// EndpointAgentService registers endpoint agents, records their check-ins
+// and builds the fleet view from the last reported inventory and health.
+type EndpointAgentService struct {
+	db              *database.MongoDB
+	diagnostics     *DiagnosticRequestService
+	checkInInterval time.Duration
+	staleAfter      time.Duration
+	lowDiskPercent  float64
+}
+
+func NewEndpointAgentService(db *database.MongoDB, diagnostics *DiagnosticRequestService, cfg *config.Config) *EndpointAgentService {
+	return &EndpointAgentService{
+		db:              db,
+		diagnostics:     diagnostics,
+		checkInInterval: cfg.AgentCheckInInterval,
+		staleAfter:      cfg.AgentStaleAfter,
+		lowDiskPercent:  cfg.AgentLowDiskPercent,
+	}
+}
+
+func (s *EndpointAgentService) collection() *mongo.Collection {
+	return s.db.GetCollection("endpoint_agents")
+}
+
+// Create registers an agent with a generated token, which is only returned
+// here and by RotateToken.
+func (s *EndpointAgentService) Create(ctx context.Context, req models.EndpointAgentRequest, resourceID *primitive.ObjectID, createdBy primitive.ObjectID) (models.EndpointAgent, error) {
+	token, err := newAlertSourceToken()
+	if err != nil {
+		return models.EndpointAgent{}, err
+	}
+	agent := models.EndpointAgent{
+		ID:         primitive.NewObjectID(),
+		Name:       req.Name,
+		Token:      token,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+		AssetTag:   req.AssetTag,
+		ResourceID: resourceID,
+		CreatedBy:  createdBy,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if _, err := s.collection().InsertOne(ctx, agent); err != nil {
+		return models.EndpointAgent{}, err
+	}
+	return agent, nil
+}
+
+// ResolveResource parses a monitored resource ID for linking, returning nil
+// for an empty ID.
+func (s *EndpointAgentService) ResolveResource(ctx context.Context, id string) (*primitive.ObjectID, error) {
+	if id == "" {
+		return nil, nil
+	}
+	resourceID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource ID")
+	}
+	count, err := s.db.GetCollection("mon_resources").CountDocuments(ctx, bson.M{"_id": resourceID})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("monitored resource not found")
+	}
+	return &resourceID, nil
+}
+
+// Get returns an agent including its token, for authenticating check-ins.
+func (s *EndpointAgentService) Get(ctx context.Context, id primitive.ObjectID) (models.EndpointAgent, error) {
+	var agent models.EndpointAgent
+	err := s.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&agent)
+	return agent, err
+}
+
+// View returns an agent with its derived status, without its token.
+func (s *EndpointAgentService) View(ctx context.Context, id primitive.ObjectID) (models.EndpointAgentView, error) {
+	agent, err := s.Get(ctx, id)
+	if err != nil {
+		return models.EndpointAgentView{}, err
+	}
+	return s.view(agent), nil
+}
+
+// List returns the fleet, optionally filtered by derived status and OS.
+func (s *EndpointAgentService) List(ctx context.Context, status, os string) ([]models.EndpointAgentView, error) {
+	filter := bson.M{}
+	if os != "" {
+		filter["inventory.os"] = os
+	}
+	cursor, err := s.collection().Find(ctx, filter, options.Find().SetSort(bson.D{{"name", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var agents []models.EndpointAgent
+	if err := cursor.All(ctx, &agents); err != nil {
+		return nil, err
+	}
+
+	views := []models.EndpointAgentView{}
+	for _, agent := range agents {
+		view := s.view(agent)
+		if status != "" && string(view.Status) != status {
+			continue
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// Summary counts the fleet by status, OS and health issue.
+func (s *EndpointAgentService) Summary(ctx context.Context) (models.FleetSummary, error) {
+	views, err := s.List(ctx, "", "")
+	if err != nil {
+		return models.FleetSummary{}, err
+	}
+
+	summary := models.FleetSummary{ByStatus: map[string]int{}, ByOS: map[string]int{}}
+	for _, view := range views {
+		summary.Total++
+		summary.ByStatus[string(view.Status)]++
+		os := view.Inventory.OS
+		if os == "" {
+			os = "unknown"
+		}
+		summary.ByOS[os]++
+		if s.lowDisk(view.Health) {
+			summary.LowDisk++
+		}
+		if view.LastCheckInAt != nil && antivirusIssue(view.Health.Antivirus) {
+			summary.AntivirusIssue++
+		}
+		if view.Health.PendingUpdates > 0 {
+			summary.PendingUpdates++
+		}
+		if view.Health.RebootRequired {
+			summary.RebootRequired++
+		}
+	}
+	return summary, nil
+}
+
+func (s *EndpointAgentService) Update(ctx context.Context, id primitive.ObjectID, req models.EndpointAgentRequest, resourceID *primitive.ObjectID) (models.EndpointAgentView, error) {
+	update := bson.M{"$set": bson.M{"name": req.Name, "assetTag": req.AssetTag, "updatedAt": time.Now()}}
+	if req.Enabled != nil {
+		update["$set"].(bson.M)["enabled"] = *req.Enabled
+	}
+	if req.ResourceID != nil {
+		if resourceID != nil {
+			update["$set"].(bson.M)["resourceId"] = *resourceID
+		} else {
+			update["$unset"] = bson.M{"resourceId": ""}
+		}
+	}
+
+	var agent models.EndpointAgent
+	err := s.collection().FindOneAndUpdate(ctx, bson.M{"_id": id}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&agent)
+	if err != nil {
+		return models.EndpointAgentView{}, err
+	}
+	return s.view(agent), nil
+}
+
+// Delete removes an agent and unlinks it from its tickets.
+func (s *EndpointAgentService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	_, err = s.db.GetCollection("tickets").UpdateMany(ctx, bson.M{"endpointAgentId": id}, bson.M{"$unset": bson.M{"endpointAgentId": ""}})
+	return err
+}
+
+// RotateToken replaces an agent's token, invalidating the old one.
+func (s *EndpointAgentService) RotateToken(ctx context.Context, id primitive.ObjectID) (models.EndpointAgent, error) {
+	token, err := newAlertSourceToken()
+	if err != nil {
+		return models.EndpointAgent{}, err
+	}
+	var agent models.EndpointAgent
+	err = s.collection().FindOneAndUpdate(ctx, bson.M{"_id": id},
+		bson.M{"$set": bson.M{"token": token, "updatedAt": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&agent)
+	return agent, err
+}
+
+// CheckIn stores the inventory and health an agent reports and returns the
+// diagnostic requests waiting for it.
+func (s *EndpointAgentService) CheckIn(ctx context.Context, agent models.EndpointAgent, req models.AgentCheckInRequest, ip string) (models.AgentCheckInResponse, error) {
+	now := time.Now()
+	_, err := s.collection().UpdateByID(ctx, agent.ID, bson.M{"$set": bson.M{
+		"inventory":     req.Inventory,
+		"health":        req.Health,
+		"agentVersion":  req.AgentVersion,
+		"lastCheckInAt": now,
+		"lastCheckInIp": ip,
+	}})
+	if err != nil {
+		return models.AgentCheckInResponse{}, err
+	}
+
+	tasks, err := s.diagnostics.PendingForAgent(ctx, agent.ID)
+	if err != nil {
+		return models.AgentCheckInResponse{}, err
+	}
+	return models.AgentCheckInResponse{NextCheckIn: s.checkInInterval.String(), Diagnostics: tasks}, nil
+}
+
+// LinkTicket sets the machine a ticket is about, or clears it when agentID
+// is nil. The agent's monitored resource is linked to the ticket too.
+func (s *EndpointAgentService) LinkTicket(ctx context.Context, ticketID primitive.ObjectID, agentID *primitive.ObjectID) error {
+	update := bson.M{"$unset": bson.M{"endpointAgentId": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	if agentID != nil {
+		agent, err := s.Get(ctx, *agentID)
+		if err == mongo.ErrNoDocuments {
+			return ErrEndpointAgentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"endpointAgentId": agent.ID, "updatedAt": time.Now()}}
+		if agent.ResourceID != nil {
+			update["$addToSet"] = bson.M{"linkedResourceIds": *agent.ResourceID}
+		}
+	}
+
+	result, err := s.db.GetCollection("tickets").UpdateByID(ctx, ticketID, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Tickets returns the tickets linked to an agent, newest first.
+func (s *EndpointAgentService) Tickets(ctx context.Context, agentID primitive.ObjectID) ([]models.Ticket, error) {
+	cursor, err := s.db.GetCollection("tickets").Find(ctx, bson.M{"endpointAgentId": agentID},
+		options.Find().SetSort(bson.D{{"createdAt", -1}}).SetLimit(50))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tickets := []models.Ticket{}
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (s *EndpointAgentService) view(agent models.EndpointAgent) models.EndpointAgentView {
+	agent.Token = ""
+	view := models.EndpointAgentView{EndpointAgent: agent, Status: models.AgentHealthy}
+
+	health := agent.Health
+	for _, disk := range health.Disks {
+		if s.lowDiskUsage(disk) {
+			view.Issues = append(view.Issues, fmt.Sprintf("Low disk space on %s (%.1f GB free)", disk.Mount, disk.FreeGB))
+		}
+	}
+	if agent.LastCheckInAt != nil && antivirusIssue(health.Antivirus) {
+		if !health.Antivirus.Enabled {
+			view.Issues = append(view.Issues, "Antivirus disabled")
+		} else if !health.Antivirus.UpToDate {
+			view.Issues = append(view.Issues, "Antivirus definitions out of date")
+		}
+	}
+	if health.PendingUpdates > 0 {
+		view.Issues = append(view.Issues, fmt.Sprintf("%d pending updates", health.PendingUpdates))
+	}
+	if health.RebootRequired {
+		view.Issues = append(view.Issues, "Reboot required")
+	}
+
+	if agent.LastCheckInAt == nil || time.Since(*agent.LastCheckInAt) > s.staleAfter {
+		view.Status = models.AgentStale
+	} else if len(view.Issues) > 0 {
+		view.Status = models.AgentAttention
+	}
+	return view
+}
+
+func (s *EndpointAgentService) lowDiskUsage(disk models.DiskUsage) bool {
+	return disk.TotalGB > 0 && disk.FreeGB/disk.TotalGB*100 < s.lowDiskPercent
+}
+
+func (s *EndpointAgentService) lowDisk(health models.EndpointHealth) bool {
+	for _, disk := range health.Disks {
+		if s.lowDiskUsage(disk) {
+			return true
+		}
+	}
+	return false
+}
+
+func antivirusIssue(av models.AntivirusStatus) bool {
+	return !av.Enabled || !av.UpToDate
+}
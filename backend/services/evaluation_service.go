@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// EvaluationService runs labeled datasets through the live triage and
+// retrieval pipeline and stores the resulting quality metrics.
+type EvaluationService struct {
+	db     *database.MongoDB
+	triage *TriageService
+	vector *VectorService
+}
+
+func NewEvaluationService(db *database.MongoDB, triage *TriageService, vector *VectorService) *EvaluationService {
+	return &EvaluationService{db: db, triage: triage, vector: vector}
+}
+
+// Run evaluates every case of the dataset and stores the run.
+func (s *EvaluationService) Run(ctx context.Context, dataset models.EvalDataset, req models.RunEvaluationRequest, startedBy primitive.ObjectID) (models.EvalRun, error) {
+	if req.TopK <= 0 {
+		req.TopK = 5
+	}
+
+	run := models.EvalRun{
+		ID:          primitive.NewObjectID(),
+		DatasetID:   dataset.ID,
+		DatasetName: dataset.Name,
+		Options:     req.Options,
+		TopK:        req.TopK,
+		StartedBy:   startedBy,
+		StartedAt:   time.Now(),
+	}
+
+	run.Triage, run.TriageFailures = s.evaluateTriage(ctx, dataset.TriageCases, req.Options)
+	run.Retrieval = s.evaluateRetrieval(dataset.RetrievalCases, req.TopK)
+	run.CompletedAt = time.Now()
+
+	if _, err := s.db.GetCollection("eval_runs").InsertOne(ctx, run); err != nil {
+		return models.EvalRun{}, err
+	}
+	return run, nil
+}
+
+func (s *EvaluationService) evaluateTriage(ctx context.Context, cases []models.TriageEvalCase, opts models.TriageOptions) (models.TriageEvalMetrics, []models.TriageEvalResult) {
+	metrics := models.TriageEvalMetrics{Cases: len(cases), PerCategory: map[string]models.PrecisionRecall{}}
+	if len(cases) == 0 {
+		return metrics, nil
+	}
+
+	var failures []models.TriageEvalResult
+	predicted := map[string]int{}
+	expected := map[string]int{}
+	truePositives := map[string]int{}
+	var categoryHits, priorityHits, priorityCases, fallbacks int
+	var latencySum int64
+
+	for _, tc := range cases {
+		run := s.triage.Triage(ctx, models.TriageRequest{Title: tc.Title, Description: tc.Description}, opts)
+		latencySum += run.LatencyMs
+		if run.Fallback {
+			fallbacks++
+		}
+
+		got := run.Result
+		predicted[string(got.Category)]++
+		expected[string(tc.ExpectedCategory)]++
+
+		categoryOK := got.Category == tc.ExpectedCategory
+		if categoryOK {
+			categoryHits++
+			truePositives[string(got.Category)]++
+		}
+		priorityOK := true
+		if tc.ExpectedPriority != "" {
+			priorityCases++
+			priorityOK = got.Priority == tc.ExpectedPriority
+			if priorityOK {
+				priorityHits++
+			}
+		}
+
+		if !categoryOK || !priorityOK {
+			failures = append(failures, models.TriageEvalResult{
+				Title:             tc.Title,
+				ExpectedCategory:  tc.ExpectedCategory,
+				PredictedCategory: got.Category,
+				ExpectedPriority:  tc.ExpectedPriority,
+				PredictedPriority: got.Priority,
+			})
+		}
+	}
+
+	metrics.CategoryAccuracy = float64(categoryHits) / float64(len(cases))
+	if priorityCases > 0 {
+		metrics.PriorityAccuracy = float64(priorityHits) / float64(priorityCases)
+	}
+	metrics.FallbackRate = float64(fallbacks) / float64(len(cases))
+	metrics.AvgLatencyMs = float64(latencySum) / float64(len(cases))
+
+	// Per-category precision/recall over every category that was either
+	// expected or predicted; macro averages weight categories equally.
+	labels := map[string]bool{}
+	for label := range expected {
+		labels[label] = true
+	}
+	for label := range predicted {
+		labels[label] = true
+	}
+	for label := range labels {
+		pr := models.PrecisionRecall{Support: expected[label]}
+		if predicted[label] > 0 {
+			pr.Precision = float64(truePositives[label]) / float64(predicted[label])
+		}
+		if expected[label] > 0 {
+			pr.Recall = float64(truePositives[label]) / float64(expected[label])
+		}
+		metrics.PerCategory[label] = pr
+		metrics.MacroPrecision += pr.Precision
+		metrics.MacroRecall += pr.Recall
+	}
+	metrics.MacroPrecision /= float64(len(labels))
+	metrics.MacroRecall /= float64(len(labels))
+
+	return metrics, failures
+}
+
+func (s *EvaluationService) evaluateRetrieval(cases []models.RetrievalEvalCase, topK int) models.RetrievalMetrics {
+	metrics := models.RetrievalMetrics{}
+	for _, rc := range cases {
+		if len(rc.RelevantDocs) == 0 {
+			continue
+		}
+		embedding, err := s.vector.GenerateEmbedding(rc.Query)
+		if err != nil {
+			continue
+		}
+		// Search per chunk, then keep the best chunk per document so a single
+		// long document cannot fill every slot.
+		results, err := s.vector.Search(embedding, topK*5, 0)
+		if err != nil {
+			continue
+		}
+		var ranked []models.Document
+		seen := map[string]bool{}
+		for _, r := range results {
+			key := r.Document.FilePath + "|" + r.Document.Title
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			ranked = append(ranked, r.Document)
+			if len(ranked) == topK {
+				break
+			}
+		}
+
+		precision, recall, ndcg, rr := RankingMetrics(ranked, rc.RelevantDocs, topK)
+		metrics.Queries++
+		metrics.PrecisionAtK += precision
+		metrics.RecallAtK += recall
+		metrics.NDCGAtK += ndcg
+		metrics.MRR += rr
+	}
+
+	if metrics.Queries > 0 {
+		n := float64(metrics.Queries)
+		metrics.PrecisionAtK /= n
+		metrics.RecallAtK /= n
+		metrics.NDCGAtK /= n
+		metrics.MRR /= n
+	}
+	return metrics
+}
+
+// RankingMetrics computes precision@k, recall@k, nDCG@k (binary relevance)
+// and reciprocal rank for one ranked result list. A document is relevant when
+// its title or file path matches one of the relevant names.
+func RankingMetrics(ranked []models.Document, relevant []string, k int) (precision, recall, ndcg, reciprocalRank float64) {
+	isRelevant := func(doc models.Document) bool {
+		for _, name := range relevant {
+			if strings.EqualFold(doc.Title, name) || strings.EqualFold(doc.FilePath, name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var hits int
+	var dcg float64
+	for i, doc := range ranked {
+		if i >= k {
+			break
+		}
+		if isRelevant(doc) {
+			hits++
+			dcg += 1 / math.Log2(float64(i+2))
+			if reciprocalRank == 0 {
+				reciprocalRank = 1 / float64(i+1)
+			}
+		}
+	}
+
+	var idcg float64
+	for i := 0; i < len(relevant) && i < k; i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+
+	precision = float64(hits) / float64(k)
+	recall = float64(hits) / float64(len(relevant))
+	if idcg > 0 {
+		ndcg = dcg / idcg
+	}
+	return precision, recall, ndcg, reciprocalRank
+}
+
+// ListRuns returns evaluation runs, newest first, optionally for one dataset.
+func (s *EvaluationService) ListRuns(ctx context.Context, datasetID *primitive.ObjectID) ([]models.EvalRun, error) {
+	filter := bson.M{}
+	if datasetID != nil {
+		filter["datasetId"] = *datasetID
+	}
+	cursor, err := s.db.GetCollection("eval_runs").Find(ctx, filter,
+		options.Find().SetSort(bson.D{{"startedAt", -1}}).SetProjection(bson.M{"triageFailures": 0}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runs := []models.EvalRun{}
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// PreviousRun returns the run of the same dataset that preceded run, if any.
+func (s *EvaluationService) PreviousRun(ctx context.Context, run models.EvalRun) (*models.EvalRun, error) {
+	var previous models.EvalRun
+	err := s.db.GetCollection("eval_runs").FindOne(ctx,
+		bson.M{"datasetId": run.DatasetID, "startedAt": bson.M{"$lt": run.StartedAt}},
+		options.FindOne().SetSort(bson.D{{"startedAt", -1}}),
+	).Decode(&previous)
+	if err != nil {
+		return nil, err
+	}
+	return &previous, nil
+}
+
+// CompareRuns reports the change of each headline metric from previous to current.
+func CompareRuns(current, previous models.EvalRun) map[string]float64 {
+	return map[string]float64{
+		"categoryAccuracy": current.Triage.CategoryAccuracy - previous.Triage.CategoryAccuracy,
+		"priorityAccuracy": current.Triage.PriorityAccuracy - previous.Triage.PriorityAccuracy,
+		"macroPrecision":   current.Triage.MacroPrecision - previous.Triage.MacroPrecision,
+		"macroRecall":      current.Triage.MacroRecall - previous.Triage.MacroRecall,
+		"precisionAtK":     current.Retrieval.PrecisionAtK - previous.Retrieval.PrecisionAtK,
+		"recallAtK":        current.Retrieval.RecallAtK - previous.Retrieval.RecallAtK,
+		"ndcgAtK":          current.Retrieval.NDCGAtK - previous.Retrieval.NDCGAtK,
+		"mrr":              current.Retrieval.MRR - previous.Retrieval.MRR,
+	}
+}
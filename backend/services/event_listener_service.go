@@ -0,0 +1,360 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/models"
+)
+
+// Syslog severities (RFC 5424), lower is worse.
+var syslogSeverityNames = []string{"emergency", "alert", "critical", "error", "warning", "notice", "info", "debug"}
+
+var eventDigits = regexp.MustCompile(`\d+`)
+
+// networkEvent is a syslog message or SNMP trap normalized for the rules.
+type networkEvent struct {
+	Source   string // syslog or snmp
+	Host     string
+	Severity int
+	Name     string
+	Message  string
+	// Key groups repeats of the same event for the rate rule and dedup.
+	Key string
+	// Clears is set for events that resolve an earlier one, e.g. linkUp.
+	Clears bool
+	Labels map[string]string
+	Raw    string
+	At     time.Time
+}
+
+// EventListenerService receives syslog messages and SNMP traps from network
+// gear and opens network tickets through the alert ingest pipeline when an
+// event is severe enough or repeats too often.
+type EventListenerService struct {
+	cfg    *config.Config
+	ingest *AlertIngestService
+
+	mu     sync.Mutex
+	recent map[string][]time.Time
+}
+
+func NewEventListenerService(cfg *config.Config, ingest *AlertIngestService) *EventListenerService {
+	return &EventListenerService{cfg: cfg, ingest: ingest, recent: map[string][]time.Time{}}
+}
+
+// Start opens the configured UDP listeners.
+func (s *EventListenerService) Start(ctx context.Context) error {
+	if s.cfg.SyslogListenAddr != "" {
+		if err := s.listen(ctx, s.cfg.SyslogListenAddr, s.handleSyslog); err != nil {
+			return fmt.Errorf("syslog listener: %w", err)
+		}
+		log.Printf("Syslog listener on udp %s", s.cfg.SyslogListenAddr)
+	}
+	if s.cfg.SNMPTrapListenAddr != "" {
+		if err := s.listen(ctx, s.cfg.SNMPTrapListenAddr, s.handleTrap); err != nil {
+			return fmt.Errorf("SNMP trap listener: %w", err)
+		}
+		log.Printf("SNMP trap listener on udp %s", s.cfg.SNMPTrapListenAddr)
+	}
+	return nil
+}
+
+func (s *EventListenerService) listen(ctx context.Context, addr string, handle func(context.Context, []byte, net.Addr)) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("listener %s read error: %v", addr, err)
+				continue
+			}
+			packet := make([]byte, n)
+			copy(packet, buf[:n])
+			handle(ctx, packet, from)
+		}
+	}()
+	return nil
+}
+
+func (s *EventListenerService) handleSyslog(ctx context.Context, packet []byte, from net.Addr) {
+	event := parseSyslog(strings.TrimRight(string(packet), "\r\n\x00"), senderHost(from))
+	s.process(ctx, event)
+}
+
+func (s *EventListenerService) handleTrap(ctx context.Context, packet []byte, from net.Addr) {
+	trap, err := parseSNMPTrap(packet)
+	if err != nil {
+		log.Printf("dropping malformed SNMP trap from %s: %v", from, err)
+		return
+	}
+	if s.cfg.SNMPCommunity != "" && trap.Community != s.cfg.SNMPCommunity {
+		log.Printf("dropping SNMP trap from %s with unexpected community", from)
+		return
+	}
+	s.process(ctx, trapEvent(trap, senderHost(from), packet))
+}
+
+// process applies the threshold and rate rules. Clearing events are always
+// forwarded so open anomalies get closed.
+func (s *EventListenerService) process(ctx context.Context, event networkEvent) {
+	reason := ""
+	switch {
+	case event.Clears:
+	case event.Severity <= s.cfg.EventMinSeverity:
+		reason = fmt.Sprintf("Severity %s meets the %s threshold.", syslogSeverityName(event.Severity), syslogSeverityName(s.cfg.EventMinSeverity))
+	default:
+		if n := s.countRecent(event.Key, event.At); s.cfg.EventRateThreshold > 0 && n >= s.cfg.EventRateThreshold {
+			reason = fmt.Sprintf("Event repeated %d times within %s.", n, s.cfg.EventRateWindow)
+		} else {
+			return
+		}
+	}
+
+	title := fmt.Sprintf("%s %s from %s: %s", strings.ToUpper(event.Source), syslogSeverityName(event.Severity), event.Host, event.Message)
+	if len(title) > 150 {
+		title = title[:147] + "..."
+	}
+	desc := fmt.Sprintf("%s\n\n%s\n\nRaw event:\n%s\n", event.Message, reason, event.Raw)
+
+	labels := map[string]string{"host": event.Host, "source": event.Source}
+	for k, v := range event.Labels {
+		labels[k] = v
+	}
+
+	h := sha1.New()
+	h.Write([]byte(event.Key))
+	alert := inboundAlert{
+		Name:        event.Name,
+		Title:       title,
+		Description: desc,
+		Severity:    eventAlertSeverity(event.Severity),
+		Category:    string(models.CategoryNetwork),
+		Resources:   []string{event.Host},
+		Labels:      labels,
+		DedupKey:    event.Source + ":" + hex.EncodeToString(h.Sum(nil))[:16],
+		Resolved:    event.Clears,
+		StartsAt:    event.At,
+	}
+	if _, err := s.ingest.ingest(ctx, event.Source, []inboundAlert{alert}); err != nil {
+		log.Printf("failed to ingest %s event from %s: %v", event.Source, event.Host, err)
+	}
+}
+
+// countRecent records an occurrence of key and returns how many fell within
+// the rate window. The counter resets once the threshold is reached so a
+// storm raises one alert per window.
+func (s *EventListenerService) countRecent(key string, at time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := at.Add(-s.cfg.EventRateWindow)
+	times := append(s.recent[key], at)
+	for len(times) > 0 && times[0].Before(cutoff) {
+		times = times[1:]
+	}
+	n := len(times)
+	if s.cfg.EventRateThreshold > 0 && n >= s.cfg.EventRateThreshold {
+		times = nil
+	}
+	s.recent[key] = times
+
+	// Keep memory bounded on busy listeners
+	if len(s.recent) > 10000 {
+		for k, ts := range s.recent {
+			if len(ts) == 0 || ts[len(ts)-1].Before(cutoff) {
+				delete(s.recent, k)
+			}
+		}
+	}
+	return n
+}
+
+func eventAlertSeverity(severity int) string {
+	switch {
+	case severity <= 2:
+		return "critical"
+	case severity == 3:
+		return "high"
+	case severity == 4:
+		return "medium"
+	}
+	return "low"
+}
+
+func syslogSeverityName(severity int) string {
+	if severity < 0 || severity >= len(syslogSeverityNames) {
+		return strconv.Itoa(severity)
+	}
+	return syslogSeverityNames[severity]
+}
+
+func senderHost(addr net.Addr) string {
+	if udp, ok := addr.(*net.UDPAddr); ok {
+		return udp.IP.String()
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// parseSyslog reads RFC 5424 and RFC 3164 messages. Anything unparseable is
+// kept whole as a notice from the sender.
+func parseSyslog(line, sender string) networkEvent {
+	event := networkEvent{
+		Source:   "syslog",
+		Host:     sender,
+		Severity: 5,
+		Name:     "syslog",
+		Message:  line,
+		Raw:      line,
+		At:       time.Now(),
+		Labels:   map[string]string{},
+	}
+
+	rest := line
+	if strings.HasPrefix(rest, "<") {
+		if end := strings.IndexByte(rest, '>'); end > 1 && end <= 4 {
+			if pri, err := strconv.Atoi(rest[1:end]); err == nil && pri >= 0 && pri <= 191 {
+				event.Severity = pri % 8
+				event.Labels["facility"] = strconv.Itoa(pri / 8)
+				rest = rest[end+1:]
+			}
+		}
+	}
+
+	if strings.HasPrefix(rest, "1 ") {
+		// RFC 5424: VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+		fields := strings.SplitN(rest, " ", 7)
+		if len(fields) == 7 {
+			if fields[2] != "-" {
+				event.Host = fields[2]
+			}
+			if fields[3] != "-" {
+				event.Name = fields[3]
+			}
+			msg := fields[6]
+			if strings.HasPrefix(msg, "-") {
+				msg = strings.TrimPrefix(msg, "-")
+			} else if strings.HasPrefix(msg, "[") {
+				if end := strings.LastIndex(msg, "] "); end >= 0 {
+					msg = msg[end+1:]
+				}
+			}
+			event.Message = strings.TrimSpace(msg)
+		}
+	} else if len(rest) > 16 {
+		// RFC 3164: "Mmm dd hh:mm:ss HOST TAG: MSG"
+		if _, err := time.Parse(time.Stamp, rest[:15]); err == nil {
+			fields := strings.SplitN(strings.TrimSpace(rest[16:]), " ", 2)
+			if len(fields) == 2 {
+				event.Host = fields[0]
+				msg := fields[1]
+				if colon := strings.Index(msg, ": "); colon > 0 && colon < 48 && !strings.Contains(msg[:colon], " ") {
+					event.Name = strings.SplitN(msg[:colon], "[", 2)[0]
+					msg = msg[colon+2:]
+				}
+				event.Message = msg
+			}
+		} else {
+			event.Message = strings.TrimSpace(rest)
+		}
+	}
+	if event.Name != "syslog" {
+		event.Labels["app"] = event.Name
+	}
+
+	// Numbers usually vary between repeats (ports, counters, PIDs)
+	event.Key = fmt.Sprintf("syslog|%s|%s|%s", event.Host, event.Name, eventDigits.ReplaceAllString(event.Message, "#"))
+	return event
+}
+
+// Generic SNMP traps (RFC 1907 snmpTraps) with their syslog-scale severity.
+var snmpGenericTraps = map[string]struct {
+	name     string
+	severity int
+}{
+	"1.3.6.1.6.3.1.1.5.1": {"coldStart", 4},
+	"1.3.6.1.6.3.1.1.5.2": {"warmStart", 5},
+	"1.3.6.1.6.3.1.1.5.3": {"linkDown", 3},
+	"1.3.6.1.6.3.1.1.5.4": {"linkUp", 5},
+	"1.3.6.1.6.3.1.1.5.5": {"authenticationFailure", 4},
+	"1.3.6.1.6.3.1.1.5.6": {"egpNeighborLoss", 3},
+}
+
+const oidIfIndex = "1.3.6.1.2.1.2.2.1.1."
+
+func trapEvent(trap snmpTrap, sender string, packet []byte) networkEvent {
+	event := networkEvent{
+		Source:   "snmp",
+		Host:     sender,
+		Severity: 4,
+		Name:     trap.TrapOID,
+		At:       time.Now(),
+		Labels:   map[string]string{"trapOid": trap.TrapOID},
+	}
+	if trap.AgentAddr != "" && trap.AgentAddr != "0.0.0.0" {
+		event.Host = trap.AgentAddr
+	}
+	if known, ok := snmpGenericTraps[trap.TrapOID]; ok {
+		event.Name = known.name
+		event.Severity = known.severity
+	}
+
+	oids := make([]string, 0, len(trap.Varbinds))
+	for oid := range trap.Varbinds {
+		oids = append(oids, oid)
+	}
+	sort.Strings(oids)
+
+	var raw strings.Builder
+	raw.WriteString(fmt.Sprintf("SNMP %s trap %s from %s\n", trap.Version, trap.TrapOID, sender))
+	ifIndex := ""
+	for _, oid := range oids {
+		raw.WriteString(fmt.Sprintf("  %s = %s\n", oid, trap.Varbinds[oid]))
+		if strings.HasPrefix(oid, oidIfIndex) {
+			ifIndex = trap.Varbinds[oid]
+		}
+	}
+	raw.WriteString("Packet: " + hex.EncodeToString(packet) + "\n")
+	event.Raw = raw.String()
+
+	event.Message = event.Name
+	if ifIndex != "" {
+		event.Message += " on ifIndex " + ifIndex
+		event.Labels["ifIndex"] = ifIndex
+	}
+
+	// linkUp clears the linkDown of the same interface, so both share a key
+	name := event.Name
+	if name == "linkUp" {
+		name = "linkDown"
+		event.Clears = true
+	}
+	event.Key = fmt.Sprintf("snmp|%s|%s|%s", event.Host, name, ifIndex)
+	return event
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+type ExperimentService struct {
+	db *database.MongoDB
+}
+
+func NewExperimentService(db *database.MongoDB) *ExperimentService {
+	return &ExperimentService{db: db}
+}
+
+// ValidateExperiment checks variant names are unique and weights positive.
+func ValidateExperiment(variants []models.ExperimentVariant) error {
+	if len(variants) < 2 {
+		return fmt.Errorf("an experiment needs at least two variants")
+	}
+	names := map[string]bool{}
+	for _, v := range variants {
+		if v.Name == "" {
+			return fmt.Errorf("variant name is required")
+		}
+		if names[v.Name] {
+			return fmt.Errorf("duplicate variant: %s", v.Name)
+		}
+		if v.Weight <= 0 {
+			return fmt.Errorf("variant %s must have a positive weight", v.Name)
+		}
+		names[v.Name] = true
+	}
+	return nil
+}
+
+func (s *ExperimentService) Create(ctx context.Context, req models.CreateExperimentRequest, createdBy primitive.ObjectID) (models.Experiment, error) {
+	exp := models.Experiment{
+		ID:          primitive.NewObjectID(),
+		Name:        req.Name,
+		Description: req.Description,
+		Variants:    req.Variants,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if _, err := s.db.GetCollection("experiments").InsertOne(ctx, exp); err != nil {
+		return models.Experiment{}, err
+	}
+	if req.Active {
+		if err := s.SetActive(ctx, exp.ID, true); err != nil {
+			return models.Experiment{}, err
+		}
+		exp.Active = true
+	}
+	return exp, nil
+}
+
+func (s *ExperimentService) List(ctx context.Context) ([]models.Experiment, error) {
+	cursor, err := s.db.GetCollection("experiments").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{"createdAt", -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	experiments := []models.Experiment{}
+	if err := cursor.All(ctx, &experiments); err != nil {
+		return nil, err
+	}
+	return experiments, nil
+}
+
+func (s *ExperimentService) Get(ctx context.Context, id primitive.ObjectID) (models.Experiment, error) {
+	var exp models.Experiment
+	err := s.db.GetCollection("experiments").FindOne(ctx, bson.M{"_id": id}).Decode(&exp)
+	return exp, err
+}
+
+// SetActive starts or stops an experiment. Starting one stops any other so
+// traffic is never split across two experiments.
+func (s *ExperimentService) SetActive(ctx context.Context, id primitive.ObjectID, active bool) error {
+	coll := s.db.GetCollection("experiments")
+	if active {
+		if _, err := coll.UpdateMany(ctx, bson.M{"active": true, "_id": bson.M{"$ne": id}},
+			bson.M{"$set": bson.M{"active": false, "updatedAt": time.Now()}}); err != nil {
+			return err
+		}
+	}
+	result, err := coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"active": active, "updatedAt": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (s *ExperimentService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.db.GetCollection("experiments").DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	_, err = s.db.GetCollection("experiment_runs").DeleteMany(ctx, bson.M{"experimentId": id})
+	return err
+}
+
+// Assign picks a variant of the active experiment by weight. It returns nil
+// when no experiment is running.
+func (s *ExperimentService) Assign(ctx context.Context) (*models.Experiment, *models.ExperimentVariant) {
+	var exp models.Experiment
+	if err := s.db.GetCollection("experiments").FindOne(ctx, bson.M{"active": true}).Decode(&exp); err != nil {
+		return nil, nil
+	}
+
+	total := 0
+	for _, v := range exp.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return nil, nil
+	}
+
+	pick := rand.Intn(total)
+	for i := range exp.Variants {
+		pick -= exp.Variants[i].Weight
+		if pick < 0 {
+			return &exp, &exp.Variants[i]
+		}
+	}
+	return nil, nil
+}
+
+// RecordRun logs a triage run served by an experiment variant.
+func (s *ExperimentService) RecordRun(ctx context.Context, exp *models.Experiment, variant string, run models.TriageRun) (primitive.ObjectID, error) {
+	record := models.ExperimentRun{
+		ID:               primitive.NewObjectID(),
+		ExperimentID:     exp.ID,
+		Variant:          variant,
+		LatencyMs:        run.LatencyMs,
+		Fallback:         run.Fallback,
+		PromptTokens:     run.PromptTokens,
+		CompletionTokens: run.CompletionTokens,
+		CostUSD:          run.CostUSD,
+		CreatedAt:        time.Now(),
+	}
+	if run.Result != nil {
+		record.Category = run.Result.Category
+		record.Priority = run.Result.Priority
+		record.Confidence = run.Result.Confidence
+	}
+	_, err := s.db.GetCollection("experiment_runs").InsertOne(ctx, record)
+	return record.ID, err
+}
+
+// RecordFeedback stores the human-confirmed category and priority for a run,
+// optionally linking the ticket that was created from it.
+func (s *ExperimentService) RecordFeedback(ctx context.Context, runID primitive.ObjectID, category models.TicketCategory, priority models.TicketPriority, userID primitive.ObjectID, ticketID *primitive.ObjectID) error {
+	var run models.ExperimentRun
+	if err := s.db.GetCollection("experiment_runs").FindOne(ctx, bson.M{"_id": runID}).Decode(&run); err != nil {
+		return err
+	}
+
+	set := bson.M{"feedback": models.TriageFeedback{
+		Category:        category,
+		Priority:        priority,
+		CategoryCorrect: category == run.Category,
+		PriorityCorrect: priority == run.Priority,
+		SubmittedBy:     userID,
+		SubmittedAt:     time.Now(),
+	}}
+	if ticketID != nil {
+		set["ticketId"] = ticketID
+	}
+	_, err := s.db.GetCollection("experiment_runs").UpdateByID(ctx, runID, bson.M{"$set": set})
+	return err
+}
+
+// Results aggregates logged runs per variant of an experiment.
+func (s *ExperimentService) Results(ctx context.Context, exp models.Experiment) ([]models.ExperimentVariantResults, error) {
+	cursor, err := s.db.GetCollection("experiment_runs").Find(ctx, bson.M{"experimentId": exp.ID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var runs []models.ExperimentRun
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+
+	byVariant := map[string][]models.ExperimentRun{}
+	for _, run := range runs {
+		byVariant[run.Variant] = append(byVariant[run.Variant], run)
+	}
+
+	results := make([]models.ExperimentVariantResults, 0, len(exp.Variants))
+	for _, variant := range exp.Variants {
+		results = append(results, summarizeVariant(variant, byVariant[variant.Name]))
+	}
+	return results, nil
+}
+
+func summarizeVariant(variant models.ExperimentVariant, runs []models.ExperimentRun) models.ExperimentVariantResults {
+	result := models.ExperimentVariantResults{
+		Variant: variant.Name,
+		Options: variant.Options,
+		Runs:    int64(len(runs)),
+	}
+	if len(runs) == 0 {
+		return result
+	}
+
+	latencies := make([]int64, 0, len(runs))
+	var latencySum int64
+	var fallbacks, categoryHits, priorityHits int64
+	for _, run := range runs {
+		latencies = append(latencies, run.LatencyMs)
+		latencySum += run.LatencyMs
+		result.TotalCostUSD += run.CostUSD
+		if run.Fallback {
+			fallbacks++
+		}
+		if run.Feedback != nil {
+			result.FeedbackCount++
+			if run.Feedback.CategoryCorrect {
+				categoryHits++
+			}
+			if run.Feedback.PriorityCorrect {
+				priorityHits++
+			}
+		}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	result.AvgLatencyMs = float64(latencySum) / float64(len(runs))
+	result.P95LatencyMs = latencies[(len(latencies)*95+99)/100-1]
+	result.FallbackRate = float64(fallbacks) / float64(len(runs))
+	result.AvgCostUSD = result.TotalCostUSD / float64(len(runs))
+	if result.FeedbackCount > 0 {
+		result.CategoryAccuracy = float64(categoryHits) / float64(result.FeedbackCount)
+		result.PriorityAccuracy = float64(priorityHits) / float64(result.FeedbackCount)
+	}
+	return result
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math/rand"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// Feature flags checked by the code. Flags not stored yet use the defaults
+// in builtinFeatureFlags.
+const (
+	FlagAutoTriage      = "auto_triage"
+	FlagAgentMode       = "agent_mode"
+	FlagSyntheticChecks = "synthetic_checks"
+	FlagCostAnomalies   = "cost_anomalies"
+)
+
+var builtinFeatureFlags = []models.FeatureFlag{
+	{Key: FlagAutoTriage, Description: "AI triage suggestions for new tickets", Enabled: true, Percentage: 100},
+	{Key: FlagAgentMode, Description: "Diagnostic agent runs on tickets", Enabled: true, Percentage: 100},
+	{Key: FlagSyntheticChecks, Description: "Scheduled synthetic uptime checks", Enabled: true, Percentage: 100},
+	{Key: FlagCostAnomalies, Description: "AWS cost spike detection", Enabled: true, Percentage: 100},
+}
+
+var featureFlagKey = regexp.MustCompile(`^[a-z][a-z0-9_]{1,63}$`)
+
+// featureFlagTTL is how long flags are cached before being read again, so
+// changes made on another replica apply within it.
+const featureFlagTTL = 30 * time.Second
+
+// FeatureFlagService stores feature flags and evaluates them for requests
+// and background workers.
+type FeatureFlagService struct {
+	db *database.MongoDB
+
+	mu       sync.RWMutex
+	flags    map[string]models.FeatureFlag
+	loadedAt time.Time
+}
+
+func NewFeatureFlagService(db *database.MongoDB) *FeatureFlagService {
+	return &FeatureFlagService{db: db}
+}
+
+func (s *FeatureFlagService) collection() *mongo.Collection {
+	return s.db.GetCollection("feature_flags")
+}
+
+// ValidateFeatureFlag checks a flag update and returns the flag it sets.
+func ValidateFeatureFlag(key string, req models.FeatureFlagRequest) (models.FeatureFlag, error) {
+	if !featureFlagKey.MatchString(key) {
+		return models.FeatureFlag{}, errors.New("key must be 2-64 lowercase letters, digits or underscores, starting with a letter")
+	}
+	flag := models.FeatureFlag{Key: key, Description: req.Description, Enabled: req.Enabled, Roles: req.Roles, Percentage: 100}
+	if req.Percentage != nil {
+		flag.Percentage = *req.Percentage
+	}
+	if flag.Percentage < 0 || flag.Percentage > 100 {
+		return models.FeatureFlag{}, errors.New("percentage must be between 0 and 100")
+	}
+	for _, role := range req.Roles {
+		if !models.ValidRole(role) {
+			return models.FeatureFlag{}, fmt.Errorf("unknown role %q", role)
+		}
+	}
+	for _, id := range req.UserIDs {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return models.FeatureFlag{}, fmt.Errorf("invalid user ID %q", id)
+		}
+		flag.UserIDs = append(flag.UserIDs, objectID)
+	}
+	return flag, nil
+}
+
+// Save creates or replaces a flag.
+func (s *FeatureFlagService) Save(ctx context.Context, flag models.FeatureFlag, updatedBy primitive.ObjectID) (models.FeatureFlag, error) {
+	flag.UpdatedAt = time.Now()
+	flag.UpdatedBy = &updatedBy
+	_, err := s.collection().ReplaceOne(ctx, bson.M{"_id": flag.Key}, flag, options.Replace().SetUpsert(true))
+	if err != nil {
+		return models.FeatureFlag{}, err
+	}
+	s.invalidate()
+	return flag, nil
+}
+
+// Delete removes a stored flag; built-in flags return to their default.
+func (s *FeatureFlagService) Delete(ctx context.Context, key string) error {
+	res, err := s.collection().DeleteOne(ctx, bson.M{"_id": key})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	s.invalidate()
+	return nil
+}
+
+// List returns the stored flags and the built-in flags not stored yet, by
+// key.
+func (s *FeatureFlagService) List(ctx context.Context) ([]models.FeatureFlag, error) {
+	flags, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]models.FeatureFlag, 0, len(flags))
+	for _, flag := range flags {
+		list = append(list, flag)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list, nil
+}
+
+// Enabled reports whether a flag is switched on, ignoring its targeting.
+// Background workers use it to check detectors.
+func (s *FeatureFlagService) Enabled(ctx context.Context, key string) bool {
+	flag, ok := s.flag(ctx, key)
+	return ok && flag.Enabled
+}
+
+// EnabledFor reports whether a flag is on for user, which is nil for
+// requests without one. Unknown flags are off.
+func (s *FeatureFlagService) EnabledFor(ctx context.Context, key string, user *models.User) bool {
+	flag, ok := s.flag(ctx, key)
+	return ok && flagOn(flag, user)
+}
+
+// Evaluate returns every flag's state for user.
+func (s *FeatureFlagService) Evaluate(ctx context.Context, user *models.User) map[string]bool {
+	flags, err := s.load(ctx)
+	if err != nil {
+		log.Printf("Failed to load feature flags: %v", err)
+	}
+	states := map[string]bool{}
+	for key, flag := range flags {
+		states[key] = flagOn(flag, user)
+	}
+	return states
+}
+
+func flagOn(flag models.FeatureFlag, user *models.User) bool {
+	if !flag.Enabled {
+		return false
+	}
+	if user == nil {
+		return rand.Intn(100) < flag.Percentage
+	}
+	for _, id := range flag.UserIDs {
+		if id == user.ID {
+			return true
+		}
+	}
+	if len(flag.Roles) > 0 {
+		allowed := false
+		for _, role := range flag.Roles {
+			allowed = allowed || role == user.Role
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return flagBucket(flag.Key, user.ID) < flag.Percentage
+}
+
+// flagBucket places a user in 0-99 for a flag, so a user stays in or out
+// of a rollout and each flag rolls out to a different set of users.
+func flagBucket(key string, userID primitive.ObjectID) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	h.Write(userID[:])
+	return int(h.Sum32() % 100)
+}
+
+func (s *FeatureFlagService) flag(ctx context.Context, key string) (models.FeatureFlag, bool) {
+	flags, err := s.load(ctx)
+	if err != nil {
+		log.Printf("Failed to load feature flags: %v", err)
+	}
+	flag, ok := flags[key]
+	return flag, ok
+}
+
+// load returns the cached flags, reading them again once the cache is
+// older than featureFlagTTL. On a read error the previous flags, or the
+// built-in defaults, are returned with the error.
+func (s *FeatureFlagService) load(ctx context.Context) (map[string]models.FeatureFlag, error) {
+	s.mu.RLock()
+	flags, loadedAt := s.flags, s.loadedAt
+	s.mu.RUnlock()
+	if flags != nil && time.Since(loadedAt) < featureFlagTTL {
+		return flags, nil
+	}
+
+	loaded := map[string]models.FeatureFlag{}
+	for _, flag := range builtinFeatureFlags {
+		flag.Builtin = true
+		loaded[flag.Key] = flag
+	}
+	cursor, err := s.collection().Find(ctx, bson.M{})
+	if err == nil {
+		var stored []models.FeatureFlag
+		if err = cursor.All(ctx, &stored); err == nil {
+			for _, flag := range stored {
+				loaded[flag.Key] = flag
+			}
+		}
+	}
+	if err != nil {
+		if flags != nil {
+			return flags, err
+		}
+		return loaded, err
+	}
+
+	s.mu.Lock()
+	s.flags, s.loadedAt = loaded, time.Now()
+	s.mu.Unlock()
+	return loaded, nil
+}
+
+func (s *FeatureFlagService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const hoursPerWeek = 7 * 24
+
+// z95 is the normal quantile for a two-sided 95% interval.
+const z95 = 1.96
+
+type ForecastService struct {
+	db *database.MongoDB
+}
+
+func NewForecastService(db *database.MongoDB) *ForecastService {
+	return &ForecastService{db: db}
+}
+
+// Forecast predicts next week's ticket volume per hour, day and category
+// from the last historyWeeks full weeks. Each hour of the week is forecast
+// from the same hour in past weeks and scaled by the linear trend in weekly
+// totals. Staffing assumes one technician handles ticketsPerTechHour tickets
+// per hour.
+func (s *ForecastService) Forecast(ctx context.Context, now time.Time, historyWeeks int, ticketsPerTechHour float64, category models.TicketCategory) (models.Forecast, error) {
+	weekStart := startOfWeek(now).AddDate(0, 0, 7)
+	historyStart := weekStart.AddDate(0, 0, -7*(historyWeeks+1))
+	historyEnd := weekStart.AddDate(0, 0, -7)
+
+	filter := bson.M{"createdAt": bson.M{"$gte": historyStart, "$lt": historyEnd}}
+	if category != "" {
+		filter["category"] = category
+	}
+	cursor, err := s.db.GetCollection("tickets").Find(ctx, filter, options.Find().SetProjection(bson.M{"category": 1, "createdAt": 1}))
+	if err != nil {
+		return models.Forecast{}, err
+	}
+	var tickets []models.Ticket
+	err = cursor.All(ctx, &tickets)
+	cursor.Close(ctx)
+	if err != nil {
+		return models.Forecast{}, err
+	}
+
+	// counts[week][hourOfWeek] and per-category weekly totals
+	counts := make([][]float64, historyWeeks)
+	for i := range counts {
+		counts[i] = make([]float64, hoursPerWeek)
+	}
+	weeklyTotals := make([]float64, historyWeeks)
+	categoryTotals := map[models.TicketCategory][]float64{}
+	for _, t := range tickets {
+		created := t.CreatedAt.In(now.Location())
+		week := int(created.Sub(historyStart).Hours() / hoursPerWeek)
+		if week < 0 || week >= historyWeeks {
+			continue
+		}
+		counts[week][hourOfWeek(created)]++
+		weeklyTotals[week]++
+		if categoryTotals[t.Category] == nil {
+			categoryTotals[t.Category] = make([]float64, historyWeeks)
+		}
+		categoryTotals[t.Category][week]++
+	}
+
+	// Scale slot averages by the trend-projected weekly total
+	meanTotal := mean(weeklyTotals)
+	scale := 1.0
+	if meanTotal > 0 {
+		if projected := projectNext(weeklyTotals); projected > 0 {
+			scale = projected / meanTotal
+		} else {
+			scale = 0
+		}
+	}
+
+	forecast := models.Forecast{
+		GeneratedAt:              now,
+		WeekStart:                weekStart,
+		HistoryWeeks:             historyWeeks,
+		Category:                 string(category),
+		TrendPct:                 math.Round((scale - 1) * 100),
+		TicketsPerTechnicianHour: ticketsPerTechHour,
+	}
+
+	var totalVariance float64
+	for d := 0; d < 7; d++ {
+		day := models.DayForecast{Date: weekStart.AddDate(0, 0, d)}
+		day.Weekday = day.Date.Weekday().String()
+		var dayVariance float64
+		for h := 0; h < 24; h++ {
+			slot := make([]float64, historyWeeks)
+			for w := range counts {
+				slot[w] = counts[w][d*24+h]
+			}
+			expected := mean(slot) * scale
+			sd := stddev(slot, mean(slot)) * scale
+			hour := models.HourForecast{
+				Start:    day.Date.Add(time.Duration(h) * time.Hour),
+				Expected: round2(expected),
+				Lower:    round2(math.Max(0, expected-z95*sd)),
+				Upper:    round2(expected + z95*sd),
+			}
+			hour.Technicians = techniciansFor(expected, ticketsPerTechHour)
+			hour.TechniciansUpper = techniciansFor(expected+z95*sd, ticketsPerTechHour)
+			forecast.Hours = append(forecast.Hours, hour)
+
+			day.Expected += expected
+			dayVariance += sd * sd
+			if hour.Technicians > day.PeakTechnicians {
+				day.PeakTechnicians = hour.Technicians
+			}
+		}
+		day.Lower = round2(math.Max(0, day.Expected-z95*math.Sqrt(dayVariance)))
+		day.Upper = round2(day.Expected + z95*math.Sqrt(dayVariance))
+		day.Expected = round2(day.Expected)
+		forecast.Days = append(forecast.Days, day)
+		forecast.Expected += day.Expected
+		totalVariance += dayVariance
+	}
+	forecast.Lower = round2(math.Max(0, forecast.Expected-z95*math.Sqrt(totalVariance)))
+	forecast.Upper = round2(forecast.Expected + z95*math.Sqrt(totalVariance))
+	forecast.Expected = round2(forecast.Expected)
+
+	for cat, totals := range categoryTotals {
+		m := mean(totals)
+		sd := stddev(totals, m)
+		forecast.Categories = append(forecast.Categories, models.CategoryForecast{
+			Category: cat,
+			Expected: round2(m * scale),
+			Lower:    round2(math.Max(0, (m-z95*sd)*scale)),
+			Upper:    round2((m + z95*sd) * scale),
+		})
+	}
+	sort.Slice(forecast.Categories, func(i, j int) bool {
+		return forecast.Categories[i].Expected > forecast.Categories[j].Expected
+	})
+
+	return forecast, nil
+}
+
+// startOfWeek returns Monday 00:00 of the week containing t.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.AddDate(0, 0, -offset).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// hourOfWeek numbers hours from Monday 00:00 (0) to Sunday 23:00 (167).
+func hourOfWeek(t time.Time) int {
+	return ((int(t.Weekday())+6)%7)*24 + t.Hour()
+}
+
+// projectNext fits a least-squares line through the weekly totals and
+// returns its value for the following week.
+func projectNext(ys []float64) float64 {
+	n := float64(len(ys))
+	if n < 2 {
+		return mean(ys)
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range ys {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	intercept := (sumY - slope*sumX) / n
+	return intercept + slope*n
+}
+
+func techniciansFor(load, perTech float64) int {
+	if load <= 0 {
+		return 0
+	}
+	return int(math.Ceil(load / perTech))
+}
+
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// AI endpoints whose generation parameters can be configured.
+const (
+	EndpointTriage     = "triage"
+	EndpointSolutions  = "solutions"
+	EndpointSummary    = "summary"
+	EndpointReplyDraft = "reply_draft"
+	EndpointAgent      = "agent"
+	EndpointPostmortem = "postmortem"
+	EndpointDigest     = "digest"
+	EndpointAnalytics  = "analytics"
+	EndpointParse      = "parse_ticket"
+	EndpointChat       = "support_chat"
+)
+
+func floatPtr(f float64) *float64 { return &f }
+
+// generationDefaults are the built-in parameters of each endpoint. An empty
+// model means the provider's configured model.
+var generationDefaults = map[string]models.GenerationParams{
+	EndpointTriage:     {Temperature: floatPtr(0.3), MaxTokens: 500},
+	EndpointSolutions:  {Temperature: floatPtr(0.7), MaxTokens: 1500},
+	EndpointSummary:    {Temperature: floatPtr(0.3), MaxTokens: 500},
+	EndpointReplyDraft: {Temperature: floatPtr(0.5), MaxTokens: 400},
+	EndpointAgent:      {Temperature: floatPtr(0.2), MaxTokens: 600},
+	EndpointPostmortem: {Temperature: floatPtr(0.3), MaxTokens: 1200},
+	EndpointDigest:     {Temperature: floatPtr(0.4), MaxTokens: 600},
+	EndpointAnalytics:  {Temperature: floatPtr(0.1), MaxTokens: 600},
+	EndpointParse:      {Temperature: floatPtr(0.2), MaxTokens: 600},
+	EndpointChat:       {Temperature: floatPtr(0.4), MaxTokens: 500},
+}
+
+var generationEndpoints = []string{
+	EndpointTriage, EndpointSolutions, EndpointSummary, EndpointReplyDraft,
+	EndpointAgent, EndpointPostmortem, EndpointDigest, EndpointAnalytics,
+	EndpointParse, EndpointChat,
+}
+
+// generationCacheTTL bounds how long a replica uses admin configuration
+// changed through another replica.
+const generationCacheTTL = 30 * time.Second
+
+// GenerationService stores per-endpoint generation parameters and resolves
+// the parameters a completion runs with.
+type GenerationService struct {
+	db *database.MongoDB
+
+	mu       sync.Mutex
+	configs  map[string]models.GenerationConfig
+	loadedAt time.Time
+}
+
+func NewGenerationService(db *database.MongoDB) *GenerationService {
+	return &GenerationService{db: db}
+}
+
+type generationOverridesKey struct{}
+
+// WithGenerationOverrides returns a context whose completions use params in
+// place of the configured values.
+func WithGenerationOverrides(ctx context.Context, params models.GenerationParams) context.Context {
+	return context.WithValue(ctx, generationOverridesKey{}, params)
+}
+
+// ValidateGenerationParams checks parameter ranges accepted by OpenAI
+// compatible APIs.
+func ValidateGenerationParams(p models.GenerationParams) error {
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+	if p.TopP != nil && (*p.TopP <= 0 || *p.TopP > 1) {
+		return fmt.Errorf("topP must be greater than 0 and at most 1")
+	}
+	if p.MaxTokens < 0 || p.MaxTokens > 32000 {
+		return fmt.Errorf("maxTokens must be at most 32000")
+	}
+	return nil
+}
+
+// mergeGenerationParams fills unset fields of p from fallback.
+func mergeGenerationParams(p, fallback models.GenerationParams) models.GenerationParams {
+	if p.Model == "" {
+		p.Model = fallback.Model
+	}
+	if p.Temperature == nil {
+		p.Temperature = fallback.Temperature
+	}
+	if p.MaxTokens == 0 {
+		p.MaxTokens = fallback.MaxTokens
+	}
+	if p.TopP == nil {
+		p.TopP = fallback.TopP
+	}
+	return p
+}
+
+// IsGenerationEndpoint reports whether endpoint has configurable parameters.
+func IsGenerationEndpoint(endpoint string) bool {
+	_, ok := generationDefaults[endpoint]
+	return ok
+}
+
+func (s *GenerationService) collection() *mongo.Collection {
+	return s.db.GetCollection("ai_generation_config")
+}
+
+func (s *GenerationService) load(ctx context.Context) map[string]models.GenerationConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.configs != nil && time.Since(s.loadedAt) < generationCacheTTL {
+		return s.configs
+	}
+
+	configs := map[string]models.GenerationConfig{}
+	cursor, err := s.collection().Find(ctx, bson.M{})
+	if err == nil {
+		var items []models.GenerationConfig
+		if err = cursor.All(ctx, &items); err == nil {
+			for _, item := range items {
+				configs[item.Endpoint] = item
+			}
+		}
+	}
+	if err != nil && s.configs != nil {
+		// Keep serving the last known configuration
+		return s.configs
+	}
+	s.configs, s.loadedAt = configs, time.Now()
+	return configs
+}
+
+func (s *GenerationService) invalidate() {
+	s.mu.Lock()
+	s.configs = nil
+	s.mu.Unlock()
+}
+
+// Resolve returns the parameters for an endpoint: per-request overrides
+// from ctx, then admin configuration, then built-in defaults.
+func (s *GenerationService) Resolve(ctx context.Context, endpoint string) models.GenerationParams {
+	params := models.GenerationParams{}
+	if overrides, ok := ctx.Value(generationOverridesKey{}).(models.GenerationParams); ok {
+		params = overrides
+	}
+	if s != nil {
+		if config, ok := s.load(ctx)[endpoint]; ok {
+			params = mergeGenerationParams(params, config.GenerationParams)
+		}
+	}
+	return mergeGenerationParams(params, generationDefaults[endpoint])
+}
+
+// List returns every configurable endpoint with its defaults, configuration
+// and effective parameters.
+func (s *GenerationService) List(ctx context.Context) []models.GenerationConfigView {
+	s.invalidate()
+	configs := s.load(ctx)
+	views := make([]models.GenerationConfigView, 0, len(generationEndpoints))
+	for _, endpoint := range generationEndpoints {
+		view := models.GenerationConfigView{
+			Endpoint: endpoint,
+			Defaults: generationDefaults[endpoint],
+		}
+		effective := generationDefaults[endpoint]
+		if config, ok := configs[endpoint]; ok {
+			view.Configured = &config
+			effective = mergeGenerationParams(config.GenerationParams, effective)
+		}
+		view.Effective = effective
+		views = append(views, view)
+	}
+	return views
+}
+
+// Update stores the parameters of an endpoint.
+func (s *GenerationService) Update(ctx context.Context, endpoint string, params models.GenerationParams, userID primitive.ObjectID) (models.GenerationConfig, error) {
+	if _, ok := generationDefaults[endpoint]; !ok {
+		return models.GenerationConfig{}, fmt.Errorf("unknown AI endpoint %q", endpoint)
+	}
+	if err := ValidateGenerationParams(params); err != nil {
+		return models.GenerationConfig{}, err
+	}
+
+	config := models.GenerationConfig{
+		Endpoint:         endpoint,
+		GenerationParams: params,
+		UpdatedBy:        userID,
+		UpdatedAt:        time.Now(),
+	}
+	if _, err := s.collection().ReplaceOne(ctx, bson.M{"_id": endpoint}, config, options.Replace().SetUpsert(true)); err != nil {
+		return models.GenerationConfig{}, err
+	}
+	s.invalidate()
+	return config, nil
+}
+
+// Reset removes the configuration of an endpoint so it uses its defaults.
+func (s *GenerationService) Reset(ctx context.Context, endpoint string) error {
+	if _, ok := generationDefaults[endpoint]; !ok {
+		return fmt.Errorf("unknown AI endpoint %q", endpoint)
+	}
+	if _, err := s.collection().DeleteOne(ctx, bson.M{"_id": endpoint}); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
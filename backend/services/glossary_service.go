@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	// glossaryTTL is how long glossary terms are cached, so changes made on
+	// another replica apply within it.
+	glossaryTTL           = 30 * time.Second
+	maxGlossaryTermLength = 64
+	// maxGlossaryDescriptionLength keeps each explanation to a line or
+	// two of prompt.
+	maxGlossaryDescriptionLength = 500
+	// maxGlossaryPromptTerms caps the terms explained in one prompt.
+	maxGlossaryPromptTerms = 20
+)
+
+// GlossaryService stores the organization's jargon: acronyms, internal
+// names and their synonyms. Search adds them to queries and LLM prompts
+// explain the ones a ticket uses.
+type GlossaryService struct {
+	db *database.MongoDB
+
+	mu       sync.RWMutex
+	terms    []models.GlossaryTerm
+	loadedAt time.Time
+}
+
+func NewGlossaryService(db *database.MongoDB) *GlossaryService {
+	return &GlossaryService{db: db}
+}
+
+func (s *GlossaryService) collection() *mongo.Collection {
+	return s.db.GetCollection("glossary_terms")
+}
+
+// ValidateGlossaryTerm checks a glossary update and returns the entry it
+// sets for term.
+func ValidateGlossaryTerm(term string, req models.GlossaryTermRequest) (models.GlossaryTerm, error) {
+	term = strings.Join(strings.Fields(term), " ")
+	if term == "" {
+		return models.GlossaryTerm{}, errors.New("term is required")
+	}
+	if len(term) > maxGlossaryTermLength {
+		return models.GlossaryTerm{}, errors.New("term must be at most 64 characters")
+	}
+	entry := models.GlossaryTerm{
+		ID:          strings.ToLower(term),
+		Term:        term,
+		Expansion:   strings.Join(strings.Fields(req.Expansion), " "),
+		Description: strings.TrimSpace(req.Description),
+		Synonyms:    []string{},
+	}
+	seen := map[string]bool{entry.ID: true, strings.ToLower(entry.Expansion): true}
+	for _, synonym := range req.Synonyms {
+		synonym = strings.Join(strings.Fields(synonym), " ")
+		if synonym == "" || seen[strings.ToLower(synonym)] {
+			continue
+		}
+		if len(synonym) > maxGlossaryTermLength {
+			return models.GlossaryTerm{}, errors.New("synonyms must be at most 64 characters")
+		}
+		seen[strings.ToLower(synonym)] = true
+		entry.Synonyms = append(entry.Synonyms, synonym)
+	}
+	if entry.Expansion == "" && len(entry.Synonyms) == 0 && entry.Description == "" {
+		return models.GlossaryTerm{}, errors.New("an expansion, a description or at least one synonym is required")
+	}
+	if len(entry.Description) > maxGlossaryDescriptionLength {
+		return models.GlossaryTerm{}, errors.New("description must be at most 500 characters")
+	}
+	return entry, nil
+}
+
+// Save creates or replaces a glossary term.
+func (s *GlossaryService) Save(ctx context.Context, term models.GlossaryTerm, updatedBy primitive.ObjectID) (models.GlossaryTerm, error) {
+	term.UpdatedAt = time.Now()
+	term.UpdatedBy = &updatedBy
+	_, err := s.collection().ReplaceOne(ctx, bson.M{"_id": term.ID}, term, options.Replace().SetUpsert(true))
+	if err != nil {
+		return models.GlossaryTerm{}, err
+	}
+	s.invalidate()
+	return term, nil
+}
+
+// Delete removes the glossary term with id, the term lowercased.
+func (s *GlossaryService) Delete(ctx context.Context, id string) error {
+	res, err := s.collection().DeleteOne(ctx, bson.M{"_id": strings.ToLower(id)})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	s.invalidate()
+	return nil
+}
+
+// List returns the glossary terms by term, reading them again when older
+// than glossaryTTL. On a read error the previous terms are returned with
+// the error. The slice is shared and must not be modified.
+func (s *GlossaryService) List(ctx context.Context) ([]models.GlossaryTerm, error) {
+	s.mu.RLock()
+	terms, loadedAt := s.terms, s.loadedAt
+	s.mu.RUnlock()
+	if terms != nil && time.Since(loadedAt) < glossaryTTL {
+		return terms, nil
+	}
+
+	cursor, err := s.collection().Find(ctx, bson.M{})
+	if err != nil {
+		return terms, err
+	}
+	loaded := []models.GlossaryTerm{}
+	if err := cursor.All(ctx, &loaded); err != nil {
+		return terms, err
+	}
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].ID < loaded[j].ID })
+
+	s.mu.Lock()
+	s.terms, s.loadedAt = loaded, time.Now()
+	s.mu.Unlock()
+	return loaded, nil
+}
+
+// Used returns the terms whose term, expansion or a synonym appears in
+// text, at most maxGlossaryPromptTerms.
+func (s *GlossaryService) Used(ctx context.Context, text string) ([]models.GlossaryTerm, error) {
+	terms, err := s.List(ctx)
+	words := queryWords(text)
+	var used []models.GlossaryTerm
+	for _, term := range terms {
+		for _, phrase := range glossaryPhrases(term) {
+			if containsPhrase(words, queryWords(phrase)) {
+				used = append(used, term)
+				break
+			}
+		}
+		if len(used) == maxGlossaryPromptTerms {
+			break
+		}
+	}
+	return used, err
+}
+
+// GlossaryPrompt explains terms to an LLM, one line each, or returns ""
+// without terms.
+func GlossaryPrompt(terms []models.GlossaryTerm) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Organization Glossary (internal terms used in this ticket):\n")
+	for _, term := range terms {
+		b.WriteString("- " + term.Term)
+		if term.Expansion != "" {
+			b.WriteString(" = " + term.Expansion)
+		}
+		if len(term.Synonyms) > 0 {
+			b.WriteString(" (also called " + strings.Join(term.Synonyms, ", ") + ")")
+		}
+		if term.Description != "" {
+			b.WriteString(": " + term.Description)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// glossaryPhrases returns the term, its synonyms and its expansion.
+func glossaryPhrases(term models.GlossaryTerm) []string {
+	phrases := append([]string{term.Term}, term.Synonyms...)
+	if term.Expansion != "" {
+		phrases = append(phrases, term.Expansion)
+	}
+	return phrases
+}
+
+func (s *GlossaryService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// Metrics exposed to Grafana. anomalies.<severity> filters by severity, and
+// anomaly metrics take a resource tag selector such as
+// anomalies.critical{env:prod,team:dba}.
+var grafanaMetrics = []string{
+	"anomalies",
+	"anomalies.critical",
+	"anomalies.high",
+	"anomalies.medium",
+	"anomalies.low",
+	"tickets.created",
+	"tickets.resolved",
+	"tickets.backlog",
+	"sla.compliance",
+}
+
+// GrafanaService answers datasource queries with time series bucketed at
+// the panel's interval.
+type GrafanaService struct {
+	db       *database.MongoDB
+	taxonomy *TaxonomyService
+}
+
+func NewGrafanaService(db *database.MongoDB, taxonomy *TaxonomyService) *GrafanaService {
+	return &GrafanaService{db: db, taxonomy: taxonomy}
+}
+
+// Search lists the metric names containing query.
+func (s *GrafanaService) Search(query string) []string {
+	query = strings.ToLower(query)
+	metrics := []string{}
+	for _, m := range grafanaMetrics {
+		if strings.Contains(m, query) {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics
+}
+
+// Query builds one series per target. The interval is widened when needed
+// so no series has more than maxPoints buckets.
+func (s *GrafanaService) Query(ctx context.Context, req models.GrafanaQueryRequest) ([]models.GrafanaTimeSeries, error) {
+	from, to := req.Range.From, req.Range.To
+	if !to.After(from) {
+		return nil, fmt.Errorf("range.to must be after range.from")
+	}
+	interval := grafanaInterval(to.Sub(from), time.Duration(req.IntervalMs)*time.Millisecond, req.MaxDataPoints)
+
+	series := []models.GrafanaTimeSeries{}
+	for _, target := range req.Targets {
+		if target.Target == "" {
+			continue
+		}
+		points, err := s.series(ctx, target.Target, from, to, interval)
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, models.GrafanaTimeSeries{Target: target.Target, Datapoints: points})
+	}
+	return series, nil
+}
+
+func grafanaInterval(span, requested time.Duration, maxPoints int) time.Duration {
+	interval := requested
+	if interval < time.Minute {
+		interval = time.Minute
+	}
+	if maxPoints <= 0 {
+		maxPoints = 1000
+	}
+	if min := span / time.Duration(maxPoints); interval < min {
+		interval = min
+	}
+	return interval.Truncate(time.Minute)
+}
+
+func (s *GrafanaService) series(ctx context.Context, target string, from, to time.Time, interval time.Duration) ([][2]float64, error) {
+	var tags map[string]string
+	if i := strings.Index(target, "{"); i >= 0 && strings.HasSuffix(target, "}") {
+		var err error
+		if tags, err = ParseTagFilters([]string{target[i+1 : len(target)-1]}); err != nil {
+			return nil, err
+		}
+		target = target[:i]
+		if !strings.HasPrefix(target, "anomalies") {
+			return nil, fmt.Errorf("tag selectors only apply to anomaly metrics: %s", target)
+		}
+	}
+
+	switch {
+	case target == "anomalies":
+		return s.anomalyCounts(ctx, "", tags, from, to, interval)
+	case strings.HasPrefix(target, "anomalies."):
+		return s.anomalyCounts(ctx, strings.TrimPrefix(target, "anomalies."), tags, from, to, interval)
+	case target == "tickets.created":
+		return s.ticketCounts(ctx, "createdAt", from, to, interval)
+	case target == "tickets.resolved":
+		return s.ticketCounts(ctx, "resolvedAt", from, to, interval)
+	case target == "tickets.backlog":
+		return s.backlog(ctx, from, to, interval)
+	case target == "sla.compliance":
+		return s.slaCompliance(ctx, from, to, interval)
+	}
+	return nil, fmt.Errorf("unknown target: %s", target)
+}
+
+// buckets returns the start of every interval in [from, to).
+func buckets(from, to time.Time, interval time.Duration) []time.Time {
+	starts := []time.Time{}
+	for t := from.Truncate(interval); t.Before(to); t = t.Add(interval) {
+		starts = append(starts, t)
+	}
+	return starts
+}
+
+func countSeries(times []time.Time, from, to time.Time, interval time.Duration) [][2]float64 {
+	starts := buckets(from, to, interval)
+	counts := make([]float64, len(starts))
+	for _, t := range times {
+		i := int(t.Sub(starts[0]) / interval)
+		if i >= 0 && i < len(counts) {
+			counts[i]++
+		}
+	}
+	points := make([][2]float64, len(starts))
+	for i, start := range starts {
+		points[i] = [2]float64{counts[i], float64(start.UnixMilli())}
+	}
+	return points
+}
+
+func (s *GrafanaService) anomalyCounts(ctx context.Context, severity string, tags map[string]string, from, to time.Time, interval time.Duration) ([][2]float64, error) {
+	filter := bson.M{"timestamp": bson.M{"$gte": from, "$lt": to}}
+	if severity != "" {
+		filter["severity"] = severity
+	}
+	if len(tags) > 0 {
+		resourceIDs, err := ResourceIDsWithTags(ctx, s.db, tags)
+		if err != nil {
+			return nil, err
+		}
+		filter["resourceId"] = bson.M{"$in": resourceIDs}
+	}
+	cursor, err := s.db.GetCollection("mon_anomalies").Find(ctx, filter, options.Find().SetProjection(bson.M{"timestamp": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var anomalies []models.AnomalyRecord
+	err = cursor.All(ctx, &anomalies)
+	cursor.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	times := make([]time.Time, len(anomalies))
+	for i, a := range anomalies {
+		times[i] = a.Timestamp
+	}
+	return countSeries(times, from, to, interval), nil
+}
+
+func (s *GrafanaService) ticketCounts(ctx context.Context, field string, from, to time.Time, interval time.Duration) ([][2]float64, error) {
+	tickets, err := s.tickets(ctx, bson.M{field: bson.M{"$gte": from, "$lt": to}})
+	if err != nil {
+		return nil, err
+	}
+
+	times := make([]time.Time, 0, len(tickets))
+	for _, t := range tickets {
+		if field == "resolvedAt" {
+			times = append(times, *t.ResolvedAt)
+		} else {
+			times = append(times, t.CreatedAt)
+		}
+	}
+	return countSeries(times, from, to, interval), nil
+}
+
+// backlog counts tickets that were open at the end of each bucket: created
+// before it and not yet resolved.
+func (s *GrafanaService) backlog(ctx context.Context, from, to time.Time, interval time.Duration) ([][2]float64, error) {
+	tickets, err := s.tickets(ctx, bson.M{
+		"createdAt": bson.M{"$lt": to},
+		"$or": bson.A{
+			bson.M{"resolvedAt": bson.M{"$exists": false}},
+			bson.M{"resolvedAt": bson.M{"$gte": from}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	starts := buckets(from, to, interval)
+	points := make([][2]float64, len(starts))
+	for i, start := range starts {
+		end := start.Add(interval)
+		open := 0
+		for _, t := range tickets {
+			if t.CreatedAt.Before(end) && (t.ResolvedAt == nil || !t.ResolvedAt.Before(end)) {
+				open++
+			}
+		}
+		points[i] = [2]float64{float64(open), float64(start.UnixMilli())}
+	}
+	return points, nil
+}
+
+// slaCompliance is the percentage of tickets resolved in each bucket within
+// their priority's resolution SLA. Buckets without resolutions are omitted.
+func (s *GrafanaService) slaCompliance(ctx context.Context, from, to time.Time, interval time.Duration) ([][2]float64, error) {
+	taxonomy := s.taxonomy.Get(ctx)
+	tickets, err := s.tickets(ctx, bson.M{"resolvedAt": bson.M{"$gte": from, "$lt": to}})
+	if err != nil {
+		return nil, err
+	}
+
+	starts := buckets(from, to, interval)
+	met := make([]int, len(starts))
+	total := make([]int, len(starts))
+	for _, t := range tickets {
+		priority := FindPriority(taxonomy, t.Priority)
+		if priority == nil || priority.ResolutionSLAHours <= 0 {
+			continue
+		}
+		i := int(t.ResolvedAt.Sub(starts[0]) / interval)
+		if i < 0 || i >= len(starts) {
+			continue
+		}
+		total[i]++
+		if t.ResolvedAt.Sub(t.CreatedAt) <= time.Duration(priority.ResolutionSLAHours)*time.Hour {
+			met[i]++
+		}
+	}
+
+	points := [][2]float64{}
+	for i, start := range starts {
+		if total[i] == 0 {
+			continue
+		}
+		points = append(points, [2]float64{round2(float64(met[i]) * 100 / float64(total[i])), float64(start.UnixMilli())})
+	}
+	return points, nil
+}
+
+func (s *GrafanaService) tickets(ctx context.Context, filter bson.M) ([]models.Ticket, error) {
+	opts := options.Find().SetProjection(bson.M{"priority": 1, "createdAt": 1, "resolvedAt": 1})
+	cursor, err := s.db.GetCollection("tickets").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tickets []models.Ticket
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
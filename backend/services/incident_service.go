@@ -0,0 +1,557 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// How long resolved incidents stay on the public status page.
+const resolvedIncidentVisibility = 7 * 24 * time.Hour
+
+var componentSeverity = map[string]int{
+	models.ComponentOperational: 0,
+	models.ComponentDegraded:    1,
+	models.ComponentPartial:     2,
+	models.ComponentMajor:       3,
+}
+
+var incidentRoles = map[string]bool{
+	models.RoleCommander: true,
+	models.RoleComms:     true,
+	models.RoleScribe:    true,
+	models.RoleOpsLead:   true,
+}
+
+// IncidentService runs major incidents: declaration, incident-command roles
+// and timeline, a war-room channel, status page publishing and a postmortem
+// draft at resolution.
+type IncidentService struct {
+	db            *database.MongoDB
+	notifications *NotificationService
+	postmortems   *PostmortemService
+	publishers    []StatusPublisher
+	warRoom       WarRoomProvider
+}
+
+func NewIncidentService(db *database.MongoDB, cfg *config.Config, notifications *NotificationService, postmortems *PostmortemService) *IncidentService {
+	s := &IncidentService{db: db, notifications: notifications, postmortems: postmortems}
+	client := &http.Client{Timeout: 10 * time.Second}
+	if cfg.SlackBotToken != "" {
+		s.warRoom = &slackWarRoom{token: cfg.SlackBotToken, client: client}
+	} else if cfg.TeamsTeamID != "" && cfg.TeamsClientID != "" {
+		s.warRoom = &teamsWarRoom{
+			graph: &graphCredentials{
+				tenantID:     cfg.TeamsTenantID,
+				clientID:     cfg.TeamsClientID,
+				clientSecret: cfg.TeamsClientSecret,
+				client:       client,
+			},
+			teamID: cfg.TeamsTeamID,
+			client: client,
+		}
+	}
+	if cfg.StatuspageAPIKey != "" && cfg.StatuspagePageID != "" {
+		s.publishers = append(s.publishers, &statuspagePublisher{apiKey: cfg.StatuspageAPIKey, pageID: cfg.StatuspagePageID, client: client})
+	}
+	if cfg.InstatusAPIKey != "" && cfg.InstatusPageID != "" {
+		s.publishers = append(s.publishers, &instatusPublisher{apiKey: cfg.InstatusAPIKey, pageID: cfg.InstatusPageID, client: client})
+	}
+	return s
+}
+
+func ValidIncidentImpact(impact models.IncidentImpact) bool {
+	return impact == models.ImpactMinor || impact == models.ImpactMajor || impact == models.ImpactCritical
+}
+
+func ValidIncidentRole(role string) bool {
+	return incidentRoles[role]
+}
+
+func ValidIncidentStatus(status models.IncidentStatus) bool {
+	switch status {
+	case models.IncidentInvestigating, models.IncidentIdentified, models.IncidentMonitoring, models.IncidentResolved:
+		return true
+	}
+	return false
+}
+
+func (s *IncidentService) CreateComponent(ctx context.Context, req models.StatusComponentRequest, resourceIDs []primitive.ObjectID) (models.StatusComponent, error) {
+	component := models.StatusComponent{
+		ID:           primitive.NewObjectID(),
+		Name:         req.Name,
+		Description:  req.Description,
+		Order:        req.Order,
+		ResourceIDs:  resourceIDs,
+		Categories:   req.Categories,
+		StatuspageID: req.StatuspageID,
+		InstatusID:   req.InstatusID,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	_, err := s.db.GetCollection("status_components").InsertOne(ctx, component)
+	return component, err
+}
+
+func (s *IncidentService) ListComponents(ctx context.Context) ([]models.StatusComponent, error) {
+	return s.findComponents(ctx, bson.M{})
+}
+
+func (s *IncidentService) findComponents(ctx context.Context, filter bson.M) ([]models.StatusComponent, error) {
+	cursor, err := s.db.GetCollection("status_components").Find(ctx, filter, options.Find().SetSort(bson.D{{"order", 1}, {"name", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	components := []models.StatusComponent{}
+	if err := cursor.All(ctx, &components); err != nil {
+		return nil, err
+	}
+	return components, nil
+}
+
+func (s *IncidentService) UpdateComponent(ctx context.Context, id primitive.ObjectID, req models.StatusComponentRequest, resourceIDs []primitive.ObjectID) (models.StatusComponent, error) {
+	var component models.StatusComponent
+	err := s.db.GetCollection("status_components").FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"name":         req.Name,
+		"description":  req.Description,
+		"order":        req.Order,
+		"resourceIds":  resourceIDs,
+		"categories":   req.Categories,
+		"statuspageId": req.StatuspageID,
+		"instatusId":   req.InstatusID,
+		"updatedAt":    time.Now(),
+	}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&component)
+	return component, err
+}
+
+func (s *IncidentService) DeleteComponent(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.db.GetCollection("status_components").DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// mapComponents finds the components affected by the given tickets and
+// anomalies through their monitored resources and ticket categories.
+func (s *IncidentService) mapComponents(ctx context.Context, ticketIDs, anomalyIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	resourceIDs := []primitive.ObjectID{}
+	categories := []models.TicketCategory{}
+
+	if len(ticketIDs) > 0 {
+		cursor, err := s.db.GetCollection("tickets").Find(ctx, bson.M{"_id": bson.M{"$in": ticketIDs}},
+			options.Find().SetProjection(bson.M{"category": 1, "linkedResourceIds": 1}))
+		if err != nil {
+			return nil, err
+		}
+		var tickets []models.Ticket
+		err = cursor.All(ctx, &tickets)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tickets {
+			categories = append(categories, t.Category)
+			resourceIDs = append(resourceIDs, t.LinkedResourceIDs...)
+		}
+	}
+
+	if len(anomalyIDs) > 0 {
+		cursor, err := s.db.GetCollection("mon_anomalies").Find(ctx, bson.M{"_id": bson.M{"$in": anomalyIDs}},
+			options.Find().SetProjection(bson.M{"resourceId": 1}))
+		if err != nil {
+			return nil, err
+		}
+		var anomalies []models.AnomalyRecord
+		err = cursor.All(ctx, &anomalies)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range anomalies {
+			if !a.ResourceID.IsZero() {
+				resourceIDs = append(resourceIDs, a.ResourceID)
+			}
+		}
+	}
+
+	if len(resourceIDs) == 0 && len(categories) == 0 {
+		return []primitive.ObjectID{}, nil
+	}
+	components, err := s.findComponents(ctx, bson.M{"$or": bson.A{
+		bson.M{"resourceIds": bson.M{"$in": resourceIDs}},
+		bson.M{"categories": bson.M{"$in": categories}},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(components))
+	for _, c := range components {
+		ids = append(ids, c.ID)
+	}
+	return ids, nil
+}
+
+// Declare opens an incident and publishes it. When componentIDs is empty
+// the components are mapped from the tickets and anomalies.
+func (s *IncidentService) Declare(ctx context.Context, req models.DeclareIncidentRequest, ticketIDs, anomalyIDs, componentIDs []primitive.ObjectID, declaredBy primitive.ObjectID) (models.Incident, error) {
+	if len(componentIDs) == 0 {
+		mapped, err := s.mapComponents(ctx, ticketIDs, anomalyIDs)
+		if err != nil {
+			return models.Incident{}, err
+		}
+		componentIDs = mapped
+	}
+
+	impact := req.Impact
+	if impact == "" {
+		impact = models.ImpactMajor
+	}
+	now := time.Now()
+	by := declaredBy
+	incident := models.Incident{
+		ID:           primitive.NewObjectID(),
+		Title:        req.Title,
+		Status:       models.IncidentInvestigating,
+		Impact:       impact,
+		ComponentIDs: componentIDs,
+		TicketIDs:    ticketIDs,
+		AnomalyIDs:   anomalyIDs,
+		Updates: []models.IncidentUpdate{{
+			Status:    models.IncidentInvestigating,
+			Message:   req.Message,
+			CreatedBy: declaredBy,
+			CreatedAt: now,
+		}},
+		Roles: map[string]primitive.ObjectID{models.RoleCommander: declaredBy},
+		Timeline: []models.IncidentTimelineEntry{{
+			Type:      models.TimelineDeclared,
+			Message:   fmt.Sprintf("Incident declared (%s impact): %s", impact, req.Message),
+			CreatedBy: &by,
+			CreatedAt: now,
+		}},
+		DeclaredBy: declaredBy,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	s.openWarRoom(ctx, &incident, req.Message)
+	s.publish(ctx, &incident)
+
+	if _, err := s.db.GetCollection("incidents").InsertOne(ctx, incident); err != nil {
+		return models.Incident{}, err
+	}
+	return incident, nil
+}
+
+// DeclareFromTicket declares an incident for an outage ticket.
+func (s *IncidentService) DeclareFromTicket(ctx context.Context, ticketID primitive.ObjectID, req models.DeclareTicketIncidentRequest, declaredBy primitive.ObjectID) (models.Incident, error) {
+	var ticket models.Ticket
+	if err := s.db.GetCollection("tickets").FindOne(ctx, bson.M{"_id": ticketID}).Decode(&ticket); err != nil {
+		return models.Incident{}, err
+	}
+	title := req.Title
+	if title == "" {
+		title = ticket.Title
+	}
+	return s.Declare(ctx, models.DeclareIncidentRequest{Title: title, Message: req.Message, Impact: req.Impact},
+		[]primitive.ObjectID{ticketID}, nil, nil, declaredBy)
+}
+
+// openWarRoom creates the incident's chat channel and posts the briefing.
+// Failures are kept on the war room so they show up on the incident.
+func (s *IncidentService) openWarRoom(ctx context.Context, incident *models.Incident, message string) {
+	if s.warRoom == nil {
+		s.announce(ctx, *incident, fmt.Sprintf("Major incident declared: %s (%s impact)\n%s", incident.Title, incident.Impact, message))
+		return
+	}
+	topic := fmt.Sprintf("%s | %s impact | status: %s", incident.Title, incident.Impact, incident.Status)
+	room, err := s.warRoom.CreateChannel(ctx, warRoomChannelName(*incident), topic)
+	if err != nil {
+		log.Printf("Failed to create war room for incident %s: %v", incident.ID.Hex(), err)
+		room = models.WarRoom{Provider: s.warRoom.Name(), Name: warRoomChannelName(*incident), Error: err.Error()}
+	}
+	incident.WarRoom = &room
+
+	text := fmt.Sprintf("Major incident declared: %s (%s impact)\n%s", incident.Title, incident.Impact, message)
+	if room.URL != "" {
+		text += "\nWar room: " + room.URL
+	}
+	s.announce(ctx, *incident, text)
+}
+
+// announce posts to the war room, or to the notification webhooks when the
+// provider cannot post (Teams) or no war room exists.
+func (s *IncidentService) announce(ctx context.Context, incident models.Incident, text string) {
+	if incident.WarRoom != nil && incident.WarRoom.ChannelID != "" && incident.WarRoom.Provider == "slack" {
+		if err := s.warRoom.Post(ctx, *incident.WarRoom, text); err != nil {
+			log.Printf("Failed to post to war room of incident %s: %v", incident.ID.Hex(), err)
+		}
+		return
+	}
+	if s.notifications != nil {
+		s.notifications.Send(ctx, Notification{Subject: "[Incident] " + incident.Title, Body: text})
+	}
+}
+
+// AssignRole gives an incident-command role to a user.
+func (s *IncidentService) AssignRole(ctx context.Context, id primitive.ObjectID, role string, userID, assignedBy primitive.ObjectID) (models.Incident, error) {
+	var user models.User
+	if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.Incident{}, fmt.Errorf("user not found")
+		}
+		return models.Incident{}, err
+	}
+
+	entry := models.IncidentTimelineEntry{
+		Type:      models.TimelineRole,
+		Message:   fmt.Sprintf("%s is now %s", user.Name, strings.ReplaceAll(role, "_", " ")),
+		CreatedBy: &assignedBy,
+		CreatedAt: time.Now(),
+	}
+	return s.appendTimeline(ctx, id, entry, bson.M{"roles." + role: userID})
+}
+
+// AddNote records a war-room note on the timeline.
+func (s *IncidentService) AddNote(ctx context.Context, id primitive.ObjectID, message string, by primitive.ObjectID) (models.Incident, error) {
+	entry := models.IncidentTimelineEntry{
+		Type:      models.TimelineNote,
+		Message:   message,
+		CreatedBy: &by,
+		CreatedAt: time.Now(),
+	}
+	return s.appendTimeline(ctx, id, entry, bson.M{})
+}
+
+func (s *IncidentService) appendTimeline(ctx context.Context, id primitive.ObjectID, entry models.IncidentTimelineEntry, set bson.M) (models.Incident, error) {
+	set["updatedAt"] = entry.CreatedAt
+	var incident models.Incident
+	err := s.db.GetCollection("incidents").FindOneAndUpdate(ctx, bson.M{"_id": id},
+		bson.M{"$set": set, "$push": bson.M{"timeline": entry}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&incident)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	s.announce(ctx, incident, entry.Message)
+	return incident, nil
+}
+
+// Update posts a status update and republishes the incident.
+func (s *IncidentService) Update(ctx context.Context, id primitive.ObjectID, req models.UpdateIncidentRequest, updatedBy primitive.ObjectID) (models.Incident, error) {
+	incident, err := s.Get(ctx, id)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	if incident.Status == models.IncidentResolved {
+		return models.Incident{}, fmt.Errorf("incident is already resolved")
+	}
+
+	now := time.Now()
+	incident.Status = req.Status
+	if req.Impact != "" {
+		incident.Impact = req.Impact
+	}
+	incident.Updates = append(incident.Updates, models.IncidentUpdate{
+		Status:    req.Status,
+		Message:   req.Message,
+		CreatedBy: updatedBy,
+		CreatedAt: now,
+	})
+	entry := models.IncidentTimelineEntry{
+		Type:      models.TimelineStatus,
+		Message:   fmt.Sprintf("Status %s: %s", req.Status, req.Message),
+		CreatedBy: &updatedBy,
+		CreatedAt: now,
+	}
+	incident.Timeline = append(incident.Timeline, entry)
+	incident.UpdatedAt = now
+	if req.Status == models.IncidentResolved {
+		incident.ResolvedAt = &now
+	}
+	s.publish(ctx, &incident)
+
+	if _, err := s.db.GetCollection("incidents").ReplaceOne(ctx, bson.M{"_id": id}, incident); err != nil {
+		return models.Incident{}, err
+	}
+	s.announce(ctx, incident, entry.Message)
+
+	if req.Status == models.IncidentResolved {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+			if _, err := s.postmortems.Draft(ctx, id, nil); err != nil {
+				log.Printf("Failed to draft postmortem for incident %s: %v", id.Hex(), err)
+			}
+		}()
+	}
+	return incident, nil
+}
+
+// IncidentTimeline renders an incident and its timeline as plain text.
+func IncidentTimeline(incident models.Incident) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Incident: %s\nImpact: %s\nStatus: %s\nDeclared: %s\n",
+		incident.Title, incident.Impact, incident.Status, incident.CreatedAt.Format(time.RFC822)))
+	if incident.ResolvedAt != nil {
+		b.WriteString(fmt.Sprintf("Resolved: %s (duration %s)\n", incident.ResolvedAt.Format(time.RFC822),
+			incident.ResolvedAt.Sub(incident.CreatedAt).Round(time.Minute)))
+	}
+	b.WriteString("\nTimeline:\n")
+	for _, e := range incident.Timeline {
+		b.WriteString(fmt.Sprintf("[%s] %s\n", e.CreatedAt.Format(time.RFC822), e.Message))
+	}
+	return b.String()
+}
+
+// publish creates or updates the incident on every external status page.
+// Failures are recorded on the publication and retried on the next update.
+func (s *IncidentService) publish(ctx context.Context, incident *models.Incident) {
+	if len(s.publishers) == 0 {
+		return
+	}
+	components, err := s.findComponents(ctx, bson.M{"_id": bson.M{"$in": incident.ComponentIDs}})
+	if err != nil {
+		log.Printf("Failed to load status components for incident %s: %v", incident.ID.Hex(), err)
+		return
+	}
+
+	existing := map[string]models.IncidentPublication{}
+	for _, p := range incident.Publications {
+		existing[p.Provider] = p
+	}
+	publications := []models.IncidentPublication{}
+	for _, publisher := range s.publishers {
+		pub := existing[publisher.Name()]
+		pub.Provider = publisher.Name()
+		pub.PublishedAt = time.Now()
+		if pub.ExternalID == "" {
+			pub.ExternalID, err = publisher.Create(ctx, *incident, components)
+		} else {
+			err = publisher.Update(ctx, pub.ExternalID, *incident, components)
+		}
+		pub.Error = ""
+		if err != nil {
+			log.Printf("Publishing incident %s to %s failed: %v", incident.ID.Hex(), publisher.Name(), err)
+			pub.Error = err.Error()
+		}
+		publications = append(publications, pub)
+	}
+	incident.Publications = publications
+}
+
+func (s *IncidentService) Get(ctx context.Context, id primitive.ObjectID) (models.Incident, error) {
+	var incident models.Incident
+	err := s.db.GetCollection("incidents").FindOne(ctx, bson.M{"_id": id}).Decode(&incident)
+	return incident, err
+}
+
+// List returns incidents newest first, optionally only unresolved ones.
+func (s *IncidentService) List(ctx context.Context, activeOnly bool) ([]models.Incident, error) {
+	filter := bson.M{}
+	if activeOnly {
+		filter["status"] = bson.M{"$ne": models.IncidentResolved}
+	}
+	return s.find(ctx, filter)
+}
+
+func (s *IncidentService) find(ctx context.Context, filter bson.M) ([]models.Incident, error) {
+	cursor, err := s.db.GetCollection("incidents").Find(ctx, filter, options.Find().SetSort(bson.D{{"createdAt", -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	incidents := []models.Incident{}
+	if err := cursor.All(ctx, &incidents); err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
+
+// PublicStatus builds the built-in status page: component status from
+// active incidents, plus incidents resolved in the last week.
+func (s *IncidentService) PublicStatus(ctx context.Context) (models.PublicStatus, error) {
+	components, err := s.ListComponents(ctx)
+	if err != nil {
+		return models.PublicStatus{}, err
+	}
+	incidents, err := s.find(ctx, bson.M{"$or": bson.A{
+		bson.M{"status": bson.M{"$ne": models.IncidentResolved}},
+		bson.M{"resolvedAt": bson.M{"$gte": time.Now().Add(-resolvedIncidentVisibility)}},
+	}})
+	if err != nil {
+		return models.PublicStatus{}, err
+	}
+
+	names := map[primitive.ObjectID]string{}
+	statuses := map[primitive.ObjectID]string{}
+	for _, c := range components {
+		names[c.ID] = c.Name
+		statuses[c.ID] = models.ComponentOperational
+	}
+
+	status := models.PublicStatus{
+		Status:     models.ComponentOperational,
+		Components: []models.PublicComponent{},
+		Incidents:  []models.PublicIncident{},
+		UpdatedAt:  time.Now(),
+	}
+	for _, incident := range incidents {
+		public := models.PublicIncident{
+			ID:         incident.ID,
+			Title:      incident.Title,
+			Status:     incident.Status,
+			Impact:     incident.Impact,
+			Components: []string{},
+			Updates:    []models.PublicUpdate{},
+			CreatedAt:  incident.CreatedAt,
+			ResolvedAt: incident.ResolvedAt,
+		}
+		for i := len(incident.Updates) - 1; i >= 0; i-- {
+			u := incident.Updates[i]
+			public.Updates = append(public.Updates, models.PublicUpdate{Status: u.Status, Message: u.Message, CreatedAt: u.CreatedAt})
+		}
+
+		current := componentStatus(incident)
+		for _, id := range incident.ComponentIDs {
+			name, ok := names[id]
+			if !ok {
+				continue
+			}
+			public.Components = append(public.Components, name)
+			if componentSeverity[current] > componentSeverity[statuses[id]] {
+				statuses[id] = current
+			}
+		}
+		if componentSeverity[current] > componentSeverity[status.Status] {
+			status.Status = current
+		}
+		status.Incidents = append(status.Incidents, public)
+	}
+
+	for _, c := range components {
+		status.Components = append(status.Components, models.PublicComponent{
+			Name:        c.Name,
+			Description: c.Description,
+			Status:      statuses[c.ID],
+		})
+	}
+	return status, nil
+}
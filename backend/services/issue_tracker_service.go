@@ -0,0 +1,437 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var (
+	// ErrIssueTrackerDisabled is returned when the requested tracker has no
+	// token configured.
+	ErrIssueTrackerDisabled = errors.New("the issue tracker is not configured")
+	ErrIssueNotSoftware     = errors.New("only software tickets can be linked to issues")
+	ErrIssueAlreadyLinked   = errors.New("the issue is already linked to this ticket")
+	// errIssueGone is returned when the issue or repository does not exist.
+	errIssueGone = errors.New("issue not found")
+)
+
+// Repository names: owner/name on GitHub, a group path and project on
+// GitLab.
+var (
+	githubRepository = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+	gitlabRepository = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)+$`)
+)
+
+// IssueTrackerService links software tickets to GitHub and GitLab issues,
+// raises issues from tickets and resolves tickets whose issues are closed.
+type IssueTrackerService struct {
+	db          *database.MongoDB
+	history     *TicketHistoryService
+	githubToken string
+	githubURL   string
+	gitlabToken string
+	gitlabURL   string
+	publicURL   string
+	client      *http.Client
+}
+
+func NewIssueTrackerService(db *database.MongoDB, history *TicketHistoryService, cfg *config.Config) *IssueTrackerService {
+	return &IssueTrackerService{
+		db:          db,
+		history:     history,
+		githubToken: cfg.GitHubToken,
+		githubURL:   cfg.GitHubAPIURL,
+		gitlabToken: cfg.GitLabToken,
+		gitlabURL:   cfg.GitLabURL,
+		publicURL:   strings.TrimRight(cfg.PublicAPIURL, "/"),
+		client:      &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Enabled reports whether provider has a token configured.
+func (s *IssueTrackerService) Enabled(provider models.IssueProvider) bool {
+	switch provider {
+	case models.IssueProviderGitHub:
+		return s.githubToken != ""
+	case models.IssueProviderGitLab:
+		return s.gitlabToken != ""
+	}
+	return false
+}
+
+// Configured reports whether any tracker is configured.
+func (s *IssueTrackerService) Configured() bool {
+	return s.Enabled(models.IssueProviderGitHub) || s.Enabled(models.IssueProviderGitLab)
+}
+
+func (s *IssueTrackerService) tickets() *mongo.Collection {
+	return s.db.GetCollection("tickets")
+}
+
+// ticket returns a live ticket that may be linked to issues.
+func (s *IssueTrackerService) ticket(ctx context.Context, ticketID primitive.ObjectID) (models.Ticket, error) {
+	var ticket models.Ticket
+	if err := s.tickets().FindOne(ctx, bson.M{"_id": ticketID}).Decode(&ticket); err != nil {
+		return ticket, err
+	}
+	if ticket.Category != models.CategorySoftware && !containsCategory(ticket.SecondaryCategories, models.CategorySoftware) {
+		return ticket, ErrIssueNotSoftware
+	}
+	return ticket, nil
+}
+
+func (s *IssueTrackerService) validate(provider models.IssueProvider, repository string) error {
+	if provider != models.IssueProviderGitHub && provider != models.IssueProviderGitLab {
+		return fmt.Errorf("provider must be %s or %s", models.IssueProviderGitHub, models.IssueProviderGitLab)
+	}
+	if !s.Enabled(provider) {
+		return ErrIssueTrackerDisabled
+	}
+	pattern := githubRepository
+	if provider == models.IssueProviderGitLab {
+		pattern = gitlabRepository
+	}
+	if !pattern.MatchString(repository) {
+		return fmt.Errorf("invalid %s repository %q", provider, repository)
+	}
+	return nil
+}
+
+// ticketURL is the link to the ticket left on issues.
+func (s *IssueTrackerService) ticketURL(ticket models.Ticket) string {
+	return s.publicURL + "/api/tickets/" + ticket.ID.Hex()
+}
+
+// Link links an existing issue to a ticket and comments on the issue with
+// a link back to the ticket.
+func (s *IssueTrackerService) Link(ctx context.Context, ticketID primitive.ObjectID, req models.LinkIssueRequest, userID primitive.ObjectID) (models.IssueLink, error) {
+	if err := s.validate(req.Provider, req.Repository); err != nil {
+		return models.IssueLink{}, err
+	}
+	ticket, err := s.ticket(ctx, ticketID)
+	if err != nil {
+		return models.IssueLink{}, err
+	}
+	for _, link := range ticket.Issues {
+		if link.Provider == req.Provider && strings.EqualFold(link.Repository, req.Repository) && link.Number == req.Number {
+			return models.IssueLink{}, ErrIssueAlreadyLinked
+		}
+	}
+
+	issue, err := s.fetch(ctx, req.Provider, req.Repository, req.Number)
+	if err == errIssueGone {
+		return models.IssueLink{}, fmt.Errorf("%s issue %s#%d not found", req.Provider, req.Repository, req.Number)
+	}
+	if err != nil {
+		return models.IssueLink{}, err
+	}
+	link, err := s.store(ctx, ticket, req.Provider, req.Repository, issue, userID)
+	if err != nil {
+		return link, err
+	}
+
+	body := fmt.Sprintf("Linked to ticket [%s](%s).", ticket.Title, s.ticketURL(ticket))
+	if err := s.comment(ctx, req.Provider, req.Repository, req.Number, body); err != nil {
+		log.Printf("Failed to comment on %s issue %s#%d: %v", req.Provider, req.Repository, req.Number, err)
+	}
+	return link, nil
+}
+
+// Create raises an issue from a ticket, with the ticket description and a
+// link back to it, and links it to the ticket.
+func (s *IssueTrackerService) Create(ctx context.Context, ticketID primitive.ObjectID, req models.CreateIssueRequest, userID primitive.ObjectID) (models.IssueLink, error) {
+	if err := s.validate(req.Provider, req.Repository); err != nil {
+		return models.IssueLink{}, err
+	}
+	ticket, err := s.ticket(ctx, ticketID)
+	if err != nil {
+		return models.IssueLink{}, err
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = ticket.Title
+	}
+	body := fmt.Sprintf("%s\n\n---\nRaised from ticket [%s](%s) (%s priority).", ticket.Description, ticket.Title, s.ticketURL(ticket), ticket.Priority)
+	issue, err := s.create(ctx, req.Provider, req.Repository, title, body, req.Labels)
+	if err == errIssueGone {
+		return models.IssueLink{}, fmt.Errorf("%s repository %s not found", req.Provider, req.Repository)
+	}
+	if err != nil {
+		return models.IssueLink{}, err
+	}
+	return s.store(ctx, ticket, req.Provider, req.Repository, issue, userID)
+}
+
+// store adds the link to the ticket.
+func (s *IssueTrackerService) store(ctx context.Context, ticket models.Ticket, provider models.IssueProvider, repository string, issue trackerIssue, userID primitive.ObjectID) (models.IssueLink, error) {
+	now := time.Now()
+	link := models.IssueLink{
+		Provider:   provider,
+		Repository: repository,
+		Number:     issue.Number,
+		Title:      issue.Title,
+		URL:        issue.URL,
+		State:      issue.State,
+		LinkedAt:   now,
+		LinkedBy:   userID,
+		SyncedAt:   now,
+	}
+	_, err := s.tickets().UpdateOne(ctx, bson.M{"_id": ticket.ID}, bson.M{"$push": bson.M{"issues": link}})
+	return link, err
+}
+
+// Unlink removes an issue link from a ticket. The issue is left as it is.
+func (s *IssueTrackerService) Unlink(ctx context.Context, ticketID primitive.ObjectID, provider models.IssueProvider, repository string, number int) error {
+	res, err := s.tickets().UpdateOne(ctx, bson.M{"_id": ticketID}, bson.M{"$pull": bson.M{"issues": bson.M{
+		"provider":   provider,
+		"repository": repository,
+		"number":     number,
+	}}})
+	if err != nil {
+		return err
+	}
+	if res.ModifiedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Start pulls the state of linked issues every interval.
+func (s *IssueTrackerService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if _, err := s.Sync(ctx); err != nil {
+					log.Printf("Issue sync error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Sync pulls the state of the issues linked to unresolved tickets. A
+// ticket is resolved once every linked issue that still exists is closed,
+// and the change is recorded in its history. Issues of trackers that are
+// not configured are skipped.
+func (s *IssueTrackerService) Sync(ctx context.Context) (models.IssueSyncResult, error) {
+	var result models.IssueSyncResult
+	cursor, err := s.tickets().Find(ctx, bson.M{
+		"issues.0": bson.M{"$exists": true},
+		"status":   bson.M{"$in": []models.TicketStatus{models.StatusOpen, models.StatusInProgress}},
+	})
+	if err != nil {
+		return result, err
+	}
+	var linked []models.Ticket
+	if err := cursor.All(ctx, &linked); err != nil {
+		return result, err
+	}
+
+	syncUser := models.User{Name: "Issue tracker"}
+	for _, ticket := range linked {
+		now := time.Now()
+		links := make([]models.IssueLink, len(ticket.Issues))
+		copy(links, ticket.Issues)
+		found, closed := 0, 0
+		for i, link := range links {
+			if !s.Enabled(link.Provider) {
+				continue
+			}
+			result.Checked++
+			issue, err := s.fetch(ctx, link.Provider, link.Repository, link.Number)
+			if err == errIssueGone {
+				result.Missing++
+				continue
+			}
+			if err != nil {
+				return result, err
+			}
+			links[i].Title, links[i].State, links[i].SyncedAt = issue.Title, issue.State, now
+			found++
+			if issue.State == models.IssueClosed {
+				closed++
+			}
+		}
+		if found == 0 {
+			continue
+		}
+
+		set := bson.M{"issues": links}
+		update := bson.M{"$set": set}
+		resolve := closed == found
+		if resolve {
+			if err := ResolveTicketStatus(ctx, s.db, update, ticket, now); err != nil {
+				log.Printf("Not resolving ticket %s from its issues: %v", ticket.ID.Hex(), err)
+				resolve = false
+			} else {
+				set["updatedAt"] = now
+			}
+		}
+		if _, err := s.tickets().UpdateOne(ctx, bson.M{"_id": ticket.ID}, update); err != nil {
+			return result, err
+		}
+		if !resolve {
+			continue
+		}
+		result.Resolved++
+		after := ticket
+		after.Status = models.StatusResolved
+		if err := s.history.Record(ctx, ticket.ID, syncUser, DiffTicket(ticket, after)); err != nil {
+			log.Printf("Failed to record issue resolution of ticket %s: %v", ticket.ID.Hex(), err)
+		}
+	}
+	return result, nil
+}
+
+// trackerIssue is the part of a GitHub or GitLab issue the service reads.
+type trackerIssue struct {
+	Number int
+	Title  string
+	URL    string
+	State  string
+}
+
+// githubIssue and gitlabIssue are the tracker's issue representations.
+type githubIssue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+}
+
+type gitlabIssue struct {
+	IID    int    `json:"iid"`
+	Title  string `json:"title"`
+	State  string `json:"state"`
+	WebURL string `json:"web_url"`
+}
+
+func (i githubIssue) issue() trackerIssue {
+	return trackerIssue{Number: i.Number, Title: i.Title, URL: i.HTMLURL, State: i.State}
+}
+
+func (i gitlabIssue) issue() trackerIssue {
+	state := models.IssueOpen
+	if i.State == "closed" {
+		state = models.IssueClosed
+	}
+	return trackerIssue{Number: i.IID, Title: i.Title, URL: i.WebURL, State: state}
+}
+
+// issuesPath is the issues collection of a repository on its tracker.
+func issuesPath(provider models.IssueProvider, repository string) string {
+	if provider == models.IssueProviderGitLab {
+		return "/api/v4/projects/" + url.PathEscape(repository) + "/issues"
+	}
+	return "/repos/" + repository + "/issues"
+}
+
+func (s *IssueTrackerService) fetch(ctx context.Context, provider models.IssueProvider, repository string, number int) (trackerIssue, error) {
+	path := fmt.Sprintf("%s/%d", issuesPath(provider, repository), number)
+	if provider == models.IssueProviderGitLab {
+		var issue gitlabIssue
+		err := s.request(ctx, provider, "GET", path, nil, &issue)
+		return issue.issue(), err
+	}
+	var issue githubIssue
+	err := s.request(ctx, provider, "GET", path, nil, &issue)
+	return issue.issue(), err
+}
+
+func (s *IssueTrackerService) create(ctx context.Context, provider models.IssueProvider, repository, title, body string, labels []string) (trackerIssue, error) {
+	if provider == models.IssueProviderGitLab {
+		var issue gitlabIssue
+		err := s.request(ctx, provider, "POST", issuesPath(provider, repository), map[string]string{
+			"title":       title,
+			"description": body,
+			"labels":      strings.Join(labels, ","),
+		}, &issue)
+		return issue.issue(), err
+	}
+	payload := map[string]interface{}{"title": title, "body": body}
+	if len(labels) > 0 {
+		payload["labels"] = labels
+	}
+	var issue githubIssue
+	err := s.request(ctx, provider, "POST", issuesPath(provider, repository), payload, &issue)
+	return issue.issue(), err
+}
+
+func (s *IssueTrackerService) comment(ctx context.Context, provider models.IssueProvider, repository string, number int, body string) error {
+	path := fmt.Sprintf("%s/%d/comments", issuesPath(provider, repository), number)
+	if provider == models.IssueProviderGitLab {
+		path = fmt.Sprintf("%s/%d/notes", issuesPath(provider, repository), number)
+	}
+	return s.request(ctx, provider, "POST", path, map[string]string{"body": body}, nil)
+}
+
+// request calls the tracker's REST API with its token and decodes the
+// response into out. A 404 is returned as errIssueGone.
+func (s *IssueTrackerService) request(ctx context.Context, provider models.IssueProvider, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	base := s.githubURL
+	if provider == models.IssueProviderGitLab {
+		base = s.gitlabURL
+	}
+	req, err := http.NewRequestWithContext(ctx, method, base+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	if provider == models.IssueProviderGitLab {
+		req.Header.Set("PRIVATE-TOKEN", s.gitlabToken)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+s.githubToken)
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	buf.ReadFrom(resp.Body)
+	if resp.StatusCode == http.StatusNotFound {
+		return errIssueGone
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %d: %s", provider, resp.StatusCode, truncateBytes(strings.TrimSpace(buf.String()), 200))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(buf.Bytes(), out)
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A small JSONPath subset for mapping templates: $ is the document root,
+// followed by .field, ['field'] and [index] steps.
+
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*(\$[^}]*?)\s*\}\}`)
+
+// ValidateJSONPath reports whether path uses the supported syntax.
+func ValidateJSONPath(path string) error {
+	_, err := parseJSONPath(path)
+	return err
+}
+
+// EvalJSONPath returns the value at path, or false when it is missing.
+func EvalJSONPath(doc interface{}, path string) (interface{}, bool) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false
+	}
+
+	current := doc
+	for _, step := range steps {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[step]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			i, err := strconv.Atoi(step)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// RenderTemplate evaluates a mapping field: a bare JSONPath yields the value
+// it points at, otherwise every {{$.path}} placeholder is substituted.
+func RenderTemplate(tmpl string, doc interface{}) string {
+	trimmed := strings.TrimSpace(tmpl)
+	if strings.HasPrefix(trimmed, "$") && !strings.Contains(trimmed, "{{") {
+		value, _ := EvalJSONPath(doc, trimmed)
+		return jsonValueString(value)
+	}
+	return templatePlaceholder.ReplaceAllStringFunc(tmpl, func(match string) string {
+		path := templatePlaceholder.FindStringSubmatch(match)[1]
+		value, _ := EvalJSONPath(doc, path)
+		return jsonValueString(value)
+	})
+}
+
+// ValidateTemplate checks the paths used by a mapping field.
+func ValidateTemplate(tmpl string) error {
+	trimmed := strings.TrimSpace(tmpl)
+	if strings.HasPrefix(trimmed, "$") && !strings.Contains(trimmed, "{{") {
+		return ValidateJSONPath(trimmed)
+	}
+	for _, match := range templatePlaceholder.FindAllStringSubmatch(tmpl, -1) {
+		if err := ValidateJSONPath(match[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseJSONPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSONPath %q must start with $", path)
+	}
+	steps := []string{}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("JSONPath %q has an empty field name", path)
+			}
+			steps = append(steps, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q has an unclosed [", path)
+			}
+			key := rest[1:end]
+			if len(key) >= 2 && (key[0] == '\'' || key[0] == '"') && key[len(key)-1] == key[0] {
+				key = key[1 : len(key)-1]
+			} else if _, err := strconv.Atoi(key); err != nil {
+				return nil, fmt.Errorf("JSONPath %q: %q is not an index or quoted field", path, key)
+			}
+			steps = append(steps, key)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("JSONPath %q: unexpected %q", path, rest[0])
+		}
+	}
+	return steps, nil
+}
+
+func jsonValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
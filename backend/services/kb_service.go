@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// kbPathPrefix marks knowledge base articles in the document index, where
+// each article is stored under kb://<article ID>.
+const kbPathPrefix = "kb://"
+
+// kbSuggestionMinScore is the similarity below which articles are not
+// worth suggesting.
+const kbSuggestionMinScore = 0.3
+
+var ErrArticleNotPublished = errors.New("article is not published")
+
+// KBService manages knowledge base articles. Articles are kept apart from
+// indexed documents but their published version is indexed alongside them,
+// so solution suggestions draw on both.
+type KBService struct {
+	db        *database.MongoDB
+	taxonomy  *TaxonomyService
+	documents *DocumentService
+	vectors   *VectorService
+	queries   *QueryService
+}
+
+func NewKBService(db *database.MongoDB, taxonomy *TaxonomyService, documents *DocumentService, vectors *VectorService, queries *QueryService) *KBService {
+	return &KBService{db: db, taxonomy: taxonomy, documents: documents, vectors: vectors, queries: queries}
+}
+
+func (s *KBService) collection() *mongo.Collection {
+	return s.db.GetCollection("kb_articles")
+}
+
+func (s *KBService) versions() *mongo.Collection {
+	return s.db.GetCollection("kb_article_versions")
+}
+
+func kbPath(id primitive.ObjectID) string {
+	return kbPathPrefix + id.Hex()
+}
+
+// Validate checks an article request and fills in the default visibility.
+func (s *KBService) Validate(ctx context.Context, req *models.KBArticleRequest) error {
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" || strings.TrimSpace(req.Body) == "" {
+		return fmt.Errorf("title and body are required")
+	}
+	switch req.Visibility {
+	case "":
+		req.Visibility = models.ArticleInternal
+	case models.ArticlePublic, models.ArticleInternal:
+	default:
+		return fmt.Errorf("visibility must be %q or %q", models.ArticlePublic, models.ArticleInternal)
+	}
+	return s.taxonomy.ValidateTicketFields(ctx, req.Category, "", nil, "")
+}
+
+// snapshot records the content of an article at its current version.
+func (s *KBService) snapshot(ctx context.Context, article models.KBArticle, note string) error {
+	_, err := s.versions().InsertOne(ctx, models.KBArticleVersion{
+		ID:         primitive.NewObjectID(),
+		ArticleID:  article.ID,
+		Version:    article.Version,
+		Title:      article.Title,
+		Body:       article.Body,
+		Category:   article.Category,
+		Tags:       article.Tags,
+		Visibility: article.Visibility,
+		Note:       note,
+		EditedBy:   article.UpdatedBy,
+		EditedAt:   article.UpdatedAt,
+	})
+	return err
+}
+
+// Create saves a new draft article as version 1.
+func (s *KBService) Create(ctx context.Context, req models.KBArticleRequest, userID primitive.ObjectID) (models.KBArticle, error) {
+	now := time.Now()
+	article := models.KBArticle{
+		ID:         primitive.NewObjectID(),
+		Title:      req.Title,
+		Body:       req.Body,
+		Category:   req.Category,
+		Tags:       req.Tags,
+		Visibility: req.Visibility,
+		Status:     models.ArticleDraft,
+		Version:    1,
+		CreatedBy:  userID,
+		UpdatedBy:  userID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if _, err := s.collection().InsertOne(ctx, article); err != nil {
+		return models.KBArticle{}, err
+	}
+	if err := s.snapshot(ctx, article, req.Note); err != nil {
+		return models.KBArticle{}, err
+	}
+	return article, nil
+}
+
+// Update saves an edit as a new version. A published article keeps serving
+// its published version until it is published again.
+func (s *KBService) Update(ctx context.Context, id primitive.ObjectID, req models.KBArticleRequest, userID primitive.ObjectID) (models.KBArticle, error) {
+	var article models.KBArticle
+	err := s.collection().FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$set": bson.M{
+				"title":      req.Title,
+				"body":       req.Body,
+				"category":   req.Category,
+				"tags":       req.Tags,
+				"visibility": req.Visibility,
+				"updatedBy":  userID,
+				"updatedAt":  time.Now(),
+			},
+			"$inc": bson.M{"version": 1},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&article)
+	if err != nil {
+		return models.KBArticle{}, err
+	}
+	if err := s.snapshot(ctx, article, req.Note); err != nil {
+		return models.KBArticle{}, err
+	}
+	return article, nil
+}
+
+func (s *KBService) Get(ctx context.Context, id primitive.ObjectID) (models.KBArticle, error) {
+	var article models.KBArticle
+	err := s.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&article)
+	return article, err
+}
+
+// List returns articles, most recently updated first; empty filters match
+// everything.
+func (s *KBService) List(ctx context.Context, status models.ArticleStatus, visibility models.ArticleVisibility, category models.TicketCategory) ([]models.KBArticle, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	if visibility != "" {
+		filter["visibility"] = visibility
+	}
+	if category != "" {
+		filter["category"] = category
+	}
+	cursor, err := s.collection().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	articles := []models.KBArticle{}
+	if err := cursor.All(ctx, &articles); err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// Versions returns the edit history of an article, newest first.
+func (s *KBService) Versions(ctx context.Context, id primitive.ObjectID) ([]models.KBArticleVersion, error) {
+	cursor, err := s.versions().Find(ctx, bson.M{"articleId": id}, options.Find().SetSort(bson.D{{Key: "version", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	versions := []models.KBArticleVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (s *KBService) Version(ctx context.Context, id primitive.ObjectID, version int) (models.KBArticleVersion, error) {
+	var v models.KBArticleVersion
+	err := s.versions().FindOne(ctx, bson.M{"articleId": id, "version": version}).Decode(&v)
+	return v, err
+}
+
+// Publish makes the latest version of an article the published one and
+// indexes it for search.
+func (s *KBService) Publish(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID) (models.KBArticle, error) {
+	article, err := s.Get(ctx, id)
+	if err != nil {
+		return models.KBArticle{}, err
+	}
+	version, err := s.Version(ctx, id, article.Version)
+	if err != nil {
+		return models.KBArticle{}, err
+	}
+
+	doc, _ := s.documents.IndexText(version.Title, kbPath(id), "kb", version.Title+"\n\n"+version.Body)
+	if _, err := s.vectors.StoreDocument(ctx, doc); err != nil {
+		return models.KBArticle{}, err
+	}
+
+	now := time.Now()
+	err = s.collection().FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{
+			"status":           models.ArticlePublished,
+			"publishedVersion": version.Version,
+			"publishedAt":      now,
+			"publishedBy":      userID,
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&article)
+	return article, err
+}
+
+// Archive unpublishes an article and drops it from the index. Its history
+// is kept and it can be published again.
+func (s *KBService) Archive(ctx context.Context, id primitive.ObjectID) (models.KBArticle, error) {
+	var article models.KBArticle
+	err := s.collection().FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"status": models.ArticleArchived}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&article)
+	if err != nil {
+		return models.KBArticle{}, err
+	}
+	return article, s.vectors.DeleteDocument(ctx, kbPath(id))
+}
+
+// Delete removes an article with its history and index entry.
+func (s *KBService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	if _, err := s.versions().DeleteMany(ctx, bson.M{"articleId": id}); err != nil {
+		return err
+	}
+	return s.vectors.DeleteDocument(ctx, kbPath(id))
+}
+
+// Published returns the published version of an article. With publicOnly
+// internal articles are treated as missing.
+func (s *KBService) Published(ctx context.Context, id primitive.ObjectID, publicOnly bool) (models.KBArticle, models.KBArticleVersion, error) {
+	article, err := s.Get(ctx, id)
+	if err != nil {
+		return models.KBArticle{}, models.KBArticleVersion{}, err
+	}
+	if article.Status != models.ArticlePublished {
+		return models.KBArticle{}, models.KBArticleVersion{}, ErrArticleNotPublished
+	}
+	version, err := s.Version(ctx, id, article.PublishedVersion)
+	if err != nil {
+		return models.KBArticle{}, models.KBArticleVersion{}, err
+	}
+	if publicOnly && version.Visibility != models.ArticlePublic {
+		return models.KBArticle{}, models.KBArticleVersion{}, mongo.ErrNoDocuments
+	}
+	return article, version, nil
+}
+
+// PublicArticle returns the published version of a public article.
+func (s *KBService) PublicArticle(ctx context.Context, id primitive.ObjectID) (models.PublicArticle, error) {
+	article, version, err := s.Published(ctx, id, true)
+	if err != nil {
+		return models.PublicArticle{}, err
+	}
+	return models.PublicArticle{
+		ID:          article.ID,
+		Title:       version.Title,
+		Body:        version.Body,
+		Category:    version.Category,
+		Tags:        version.Tags,
+		PublishedAt: article.PublishedAt,
+	}, nil
+}
+
+// Suggest finds published articles that may solve the issue described by
+// query, best match first. With publicOnly only public articles are
+// suggested, as for requesters.
+func (s *KBService) Suggest(ctx context.Context, query string, limit int, publicOnly bool) ([]models.ArticleSuggestion, error) {
+	suggestions := []models.ArticleSuggestion{}
+	if strings.TrimSpace(query) == "" {
+		return suggestions, nil
+	}
+	embedding, err := s.vectors.GenerateEmbedding(s.queries.Process(ctx, query).Query)
+	if err != nil {
+		return nil, err
+	}
+	// Documents compete for the top results, so search wide and keep the
+	// articles
+	results, err := s.vectors.SearchApproved(embedding, limit*10, kbSuggestionMinScore)
+	if err != nil {
+		return nil, err
+	}
+	return s.ArticlesFrom(ctx, results, limit, publicOnly), nil
+}
+
+// IsArticle reports whether a search result is a knowledge base article.
+func IsArticle(result models.DocumentSearchResult) bool {
+	return strings.HasPrefix(result.Document.FilePath, kbPathPrefix)
+}
+
+// ArticlesFrom picks up to limit published articles out of search results,
+// skipping documents and articles no longer published.
+func (s *KBService) ArticlesFrom(ctx context.Context, results []models.DocumentSearchResult, limit int, publicOnly bool) []models.ArticleSuggestion {
+	suggestions := []models.ArticleSuggestion{}
+	seen := map[primitive.ObjectID]bool{}
+	for _, result := range results {
+		if len(suggestions) >= limit {
+			break
+		}
+		if !IsArticle(result) {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(strings.TrimPrefix(result.Document.FilePath, kbPathPrefix))
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		_, version, err := s.Published(ctx, id, publicOnly)
+		if err != nil {
+			// Unpublished or hidden since it was indexed
+			continue
+		}
+		suggestions = append(suggestions, models.ArticleSuggestion{
+			ID:       id,
+			Title:    version.Title,
+			Category: version.Category,
+			Excerpt:  truncateBytes(strings.TrimSpace(result.Chunk.Content), 300),
+			Score:    result.Score,
+		})
+	}
+	return suggestions
+}
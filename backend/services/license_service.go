@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// licenseExpiryKeywords mark tickets about an expired or expiring license.
+var licenseExpiryKeywords = []string{"license expired", "licence expired", "license has expired", "license expir", "licence expir", "subscription expired", "activation failed", "unlicensed"}
+
+// LicenseService keeps the software license inventory and opens renewal
+// tickets ahead of expiry dates.
+type LicenseService struct {
+	db       *database.MongoDB
+	taxonomy *TaxonomyService
+	leadTime time.Duration
+}
+
+func NewLicenseService(db *database.MongoDB, taxonomy *TaxonomyService, cfg *config.Config) *LicenseService {
+	return &LicenseService{db: db, taxonomy: taxonomy, leadTime: cfg.LicenseRenewalLeadTime}
+}
+
+func (s *LicenseService) collection() *mongo.Collection {
+	return s.db.GetCollection("licenses")
+}
+
+// resolveIDs parses IDs and checks they all exist in a collection.
+func (s *LicenseService) resolveIDs(ctx context.Context, collection, field string, ids []string) ([]primitive.ObjectID, error) {
+	objectIDs := []primitive.ObjectID{}
+	seen := map[primitive.ObjectID]bool{}
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s ID %q", field, id)
+		}
+		if !seen[objectID] {
+			seen[objectID] = true
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+	if len(objectIDs) == 0 {
+		return objectIDs, nil
+	}
+	count, err := s.db.GetCollection(collection).CountDocuments(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		return nil, err
+	}
+	if int(count) != len(objectIDs) {
+		return nil, fmt.Errorf("unknown %s in assignment", field)
+	}
+	return objectIDs, nil
+}
+
+// Validate checks a license request and resolves its assignments.
+func (s *LicenseService) Validate(ctx context.Context, req models.LicenseRequest) (models.License, error) {
+	users, err := s.resolveIDs(ctx, "users", "user", req.AssignedUsers)
+	if err != nil {
+		return models.License{}, err
+	}
+	assets, err := s.resolveIDs(ctx, "endpoint_agents", "asset", req.AssignedAssets)
+	if err != nil {
+		return models.License{}, err
+	}
+	if used := len(users) + len(assets); req.Seats > 0 && used > req.Seats {
+		return models.License{}, fmt.Errorf("%d assignments exceed %d seats", used, req.Seats)
+	}
+
+	license := models.License{
+		Product:        strings.TrimSpace(req.Product),
+		Vendor:         req.Vendor,
+		Reference:      req.Reference,
+		Seats:          req.Seats,
+		ExpiresAt:      req.ExpiresAt,
+		AssignedUsers:  users,
+		AssignedAssets: assets,
+		Notes:          req.Notes,
+	}
+	if req.OwnerID != "" {
+		owners, err := s.resolveIDs(ctx, "users", "owner", []string{req.OwnerID})
+		if err != nil {
+			return models.License{}, err
+		}
+		license.OwnerID = &owners[0]
+	}
+	return license, nil
+}
+
+func (s *LicenseService) Create(ctx context.Context, license models.License, createdBy primitive.ObjectID) (models.LicenseView, error) {
+	license.ID = primitive.NewObjectID()
+	license.CreatedBy = createdBy
+	license.CreatedAt = time.Now()
+	license.UpdatedAt = time.Now()
+	if _, err := s.collection().InsertOne(ctx, license); err != nil {
+		return models.LicenseView{}, err
+	}
+	return licenseView(license), nil
+}
+
+// List returns the inventory ordered by expiry; expiringWithin limits it to
+// licenses expiring within that window (including expired ones).
+func (s *LicenseService) List(ctx context.Context, expiringWithin time.Duration) ([]models.LicenseView, error) {
+	filter := bson.M{}
+	if expiringWithin > 0 {
+		filter["expiresAt"] = bson.M{"$lte": time.Now().Add(expiringWithin)}
+	}
+	cursor, err := s.collection().Find(ctx, filter, options.Find().SetSort(bson.D{{"expiresAt", 1}, {"product", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var licenses []models.License
+	if err := cursor.All(ctx, &licenses); err != nil {
+		return nil, err
+	}
+	views := []models.LicenseView{}
+	for _, license := range licenses {
+		views = append(views, licenseView(license))
+	}
+	return views, nil
+}
+
+func (s *LicenseService) Get(ctx context.Context, id primitive.ObjectID) (models.LicenseView, error) {
+	var license models.License
+	if err := s.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&license); err != nil {
+		return models.LicenseView{}, err
+	}
+	return licenseView(license), nil
+}
+
+// Update replaces a license's details. Changing the expiry date allows a
+// new renewal ticket for the new date.
+func (s *LicenseService) Update(ctx context.Context, id primitive.ObjectID, license models.License) (models.LicenseView, error) {
+	set := bson.M{
+		"product":        license.Product,
+		"vendor":         license.Vendor,
+		"reference":      license.Reference,
+		"seats":          license.Seats,
+		"assignedUsers":  license.AssignedUsers,
+		"assignedAssets": license.AssignedAssets,
+		"notes":          license.Notes,
+		"updatedAt":      time.Now(),
+	}
+	unset := bson.M{}
+	if license.ExpiresAt != nil {
+		set["expiresAt"] = *license.ExpiresAt
+	} else {
+		unset["expiresAt"] = ""
+	}
+	if license.OwnerID != nil {
+		set["ownerId"] = *license.OwnerID
+	} else {
+		unset["ownerId"] = ""
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	var updated models.License
+	err := s.collection().FindOneAndUpdate(ctx, bson.M{"_id": id}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+	if err != nil {
+		return models.LicenseView{}, err
+	}
+	return licenseView(updated), nil
+}
+
+// Delete removes a license and unlinks it from its tickets.
+func (s *LicenseService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.collection().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	_, err = s.db.GetCollection("tickets").UpdateMany(ctx, bson.M{"licenseId": id}, bson.M{"$unset": bson.M{"licenseId": ""}})
+	return err
+}
+
+func licenseView(license models.License) models.LicenseView {
+	return models.LicenseView{
+		License:   license,
+		SeatsUsed: len(license.AssignedUsers) + len(license.AssignedAssets),
+		Expired:   license.ExpiresAt != nil && license.ExpiresAt.Before(time.Now()),
+	}
+}
+
+// MatchTicket returns the license a ticket about an expired license refers
+// to: the product named in the ticket, preferring the longest name. Tickets
+// that are not about license expiry match nothing.
+func (s *LicenseService) MatchTicket(ctx context.Context, ticket models.Ticket) (*models.License, error) {
+	text := ticket.Title + " " + ticket.Description
+	if ticket.Subcategory != "Licensing" && !containsAny(text, licenseExpiryKeywords) {
+		return nil, nil
+	}
+
+	cursor, err := s.collection().Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var licenses []models.License
+	if err := cursor.All(ctx, &licenses); err != nil {
+		return nil, err
+	}
+
+	var best *models.License
+	for i, license := range licenses {
+		if mentions(text, license.Product) && (best == nil || len(license.Product) > len(best.Product)) {
+			best = &licenses[i]
+		}
+	}
+	return best, nil
+}
+
+// LinkTicket links a new ticket to the license it reports as expired.
+func (s *LicenseService) LinkTicket(ctx context.Context, ticket models.Ticket) (*models.License, error) {
+	license, err := s.MatchTicket(ctx, ticket)
+	if err != nil || license == nil {
+		return nil, err
+	}
+	_, err = s.db.GetCollection("tickets").UpdateByID(ctx, ticket.ID, bson.M{"$set": bson.M{"licenseId": license.ID}})
+	return license, err
+}
+
+// SetTicketLicense links a ticket to a license, or unlinks it when
+// licenseID is nil.
+func (s *LicenseService) SetTicketLicense(ctx context.Context, ticketID primitive.ObjectID, licenseID *primitive.ObjectID) error {
+	update := bson.M{"$unset": bson.M{"licenseId": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	if licenseID != nil {
+		update = bson.M{"$set": bson.M{"licenseId": *licenseID, "updatedAt": time.Now()}}
+	}
+	result, err := s.db.GetCollection("tickets").UpdateByID(ctx, ticketID, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// StartReminders periodically opens renewal tickets for licenses nearing
+// expiry.
+func (s *LicenseService) StartReminders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if err := s.OpenRenewalTickets(ctx); err != nil {
+					log.Printf("license reminder error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// OpenRenewalTickets opens one ticket per expiry date for licenses expiring
+// within the renewal lead time.
+func (s *LicenseService) OpenRenewalTickets(ctx context.Context) error {
+	cursor, err := s.collection().Find(ctx, bson.M{
+		"expiresAt": bson.M{"$lte": time.Now().Add(s.leadTime)},
+	})
+	if err != nil {
+		return err
+	}
+	var licenses []models.License
+	if err := cursor.All(ctx, &licenses); err != nil {
+		return err
+	}
+
+	for _, license := range licenses {
+		if license.RenewalFor != nil && license.RenewalFor.Equal(*license.ExpiresAt) {
+			continue
+		}
+		if err := s.openRenewalTicket(ctx, license); err != nil {
+			log.Printf("Failed to open renewal ticket for license %s: %v", license.ID.Hex(), err)
+		}
+	}
+	return nil
+}
+
+func (s *LicenseService) openRenewalTicket(ctx context.Context, license models.License) error {
+	now := time.Now()
+	expiresAt := *license.ExpiresAt
+
+	taxonomy := s.taxonomy.Get(ctx)
+	category := DefaultCategory(taxonomy)
+	subcategory := ""
+	if FindCategory(taxonomy, models.CategorySoftware) != nil {
+		category = models.CategorySoftware
+		if HasSubcategory(taxonomy, category, "Licensing") {
+			subcategory = "Licensing"
+		}
+	}
+	priority := models.PriorityMedium
+	state := "expires on " + expiresAt.Format("Mon Jan 2 2006")
+	if expiresAt.Before(now) {
+		priority = models.PriorityHigh
+		state = "expired on " + expiresAt.Format("Mon Jan 2 2006")
+	}
+	if FindPriority(taxonomy, priority) == nil {
+		priority = DefaultPriority(taxonomy)
+	}
+
+	description := fmt.Sprintf("The %s license %s and needs to be renewed.\n\nSeats: %d (%d assigned)",
+		license.Product, state, license.Seats, len(license.AssignedUsers)+len(license.AssignedAssets))
+	if license.Vendor != "" {
+		description += "\nVendor: " + license.Vendor
+	}
+	if license.Reference != "" {
+		description += "\nReference: " + license.Reference
+	}
+
+	ticket := models.Ticket{
+		ID:          primitive.NewObjectID(),
+		Title:       "License renewal: " + license.Product,
+		Description: description,
+		Category:    category,
+		Subcategory: subcategory,
+		Priority:    priority,
+		Status:      models.StatusOpen,
+		AssignedTo:  license.OwnerID,
+		CreatedBy:   license.CreatedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		DueAt:       &expiresAt,
+		LicenseID:   &license.ID,
+	}
+	if _, err := s.db.GetCollection("tickets").InsertOne(ctx, ticket); err != nil {
+		return err
+	}
+
+	_, err := s.collection().UpdateByID(ctx, license.ID, bson.M{"$set": bson.M{
+		"renewalTicketId": ticket.ID,
+		"renewalFor":      expiresAt,
+	}})
+	return err
+}
@@ -2,45 +2,183 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
+	"intelliops-ai-copilot/config"
 	"intelliops-ai-copilot/models"
 )
 
 type LLMService struct {
+	// mu guards conf and profile, which the admin settings replace at
+	// runtime
+	mu         sync.RWMutex
+	conf       llmProviderConfig
+	guardrails *GuardrailService
+	// Context window override in tokens; 0 uses the known model limit
+	contextTokens int
+	generation    *GenerationService
+	automation    *AutomationService
+	glossary      *GlossaryService
+	// Organization profile and answer tone prompts start with
+	profile string
+}
+
+// llmProviderConfig is the provider and its credentials.
+type llmProviderConfig struct {
+	provider     string
 	openAIAPIKey string
 	openAIModel  string
 	localLLMURL  string
-	provider     string
 }
 
-func NewLLMService(openAIAPIKey, openAIModel, localLLMURL, provider string) *LLMService {
+func NewLLMService(openAIAPIKey, openAIModel, localLLMURL, provider string, contextTokens int, guardrails *GuardrailService, generation *GenerationService, automation *AutomationService, glossary *GlossaryService) *LLMService {
 	return &LLMService{
-		openAIAPIKey: openAIAPIKey,
-		openAIModel:  openAIModel,
-		localLLMURL:  localLLMURL,
-		provider:     provider,
+		conf:          llmProviderConfig{provider: provider, openAIAPIKey: openAIAPIKey, openAIModel: openAIModel, localLLMURL: localLLMURL},
+		guardrails:    guardrails,
+		contextTokens: contextTokens,
+		generation:    generation,
+		automation:    automation,
+		glossary:      glossary,
 	}
 }
 
-// GenerateSolutions generates solution suggestions based on ticket and documents
-func (l *LLMService) GenerateSolutions(ticket models.Ticket, docResults []models.DocumentSearchResult) ([]models.SuggestedSolution, error) {
-	fmt.Printf("DEBUG: GenerateSolutions called with provider: %s\n", l.provider)
-	// Build context from document results
-	var contextBuilder strings.Builder
-	contextBuilder.WriteString("Relevant Documentation:\n\n")
+// Configure switches the provider, e.g. after the admin settings change.
+func (l *LLMService) Configure(openAIAPIKey, openAIModel, localLLMURL, provider string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.conf = llmProviderConfig{provider: provider, openAIAPIKey: openAIAPIKey, openAIModel: openAIModel, localLLMURL: localLLMURL}
+}
+
+// ConfigureProfile sets the organization profile and answer tone triage
+// and solution prompts start with, e.g. after the admin settings change.
+func (l *LLMService) ConfigureProfile(cfg *config.Config) {
+	profile := ProfilePrompt(cfg)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.profile = profile
+}
+
+// Profile returns the prompt opening set by ConfigureProfile.
+func (l *LLMService) Profile() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.profile
+}
+
+func (l *LLMService) providerConfig() llmProviderConfig {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.conf
+}
+
+const solutionSystemPrompt = "You are an IT support expert that provides detailed technical solutions. Always respond with valid JSON." + untrustedContentPolicy
+
+// renderSolutionSource is the text a search result adds to a solutions
+// prompt, with the chunk already wrapped.
+func renderSolutionSource(i int, result models.DocumentSearchResult, content string) string {
+	return fmt.Sprintf("Document %d: %s\nContent: %s\nRelevance Score: %.2f\n\n", i+1, result.Document.Title, content, result.Score)
+}
+
+// GenerateSolutions generates solution suggestions based on ticket and
+// documents. Document chunks are selected by score to fit the model's
+// context window; the returned usage records how much of it was used.
+func (l *LLMService) GenerateSolutions(ctx context.Context, ticket models.Ticket, docResults []models.DocumentSearchResult) ([]models.SuggestedSolution, models.ContextUsage, error) {
+	return l.generateSolutions(ctx, ticket, docResults, "")
+}
+
+// RefineSolutions regenerates solutions for a ticket so they satisfy the
+// technician's feedback on a previous set. feedback holds every constraint
+// given so far, oldest first.
+func (l *LLMService) RefineSolutions(ctx context.Context, ticket models.Ticket, docResults []models.DocumentSearchResult, previous []models.SuggestedSolution, feedback []string) ([]models.SuggestedSolution, models.ContextUsage, error) {
+	var previousText strings.Builder
+	for i, solution := range previous {
+		previousText.WriteString(fmt.Sprintf("Solution %d: %s\n", i+1, formatSolution(solution)))
+	}
+
+	var b strings.Builder
+	b.WriteString("Previously suggested solutions:\n")
+	b.WriteString(l.guardrails.Wrap(ctx, "ticket", ticket.ID.Hex(), "previous solutions", previousText.String()) + "\n\n")
+	b.WriteString("Technician feedback on the suggestions, all of which still applies:\n")
+	for _, f := range feedback {
+		b.WriteString("- " + f + "\n")
+	}
+	b.WriteString("\nRevise the solutions so every step is consistent with this feedback. Rewrite or drop steps that conflict with it.\n")
+	return l.generateSolutions(ctx, ticket, docResults, b.String())
+}
+
+// generateSolutions prompts for solutions to a ticket; refinement is added
+// after the documentation when revising an earlier set.
+func (l *LLMService) generateSolutions(ctx context.Context, ticket models.Ticket, docResults []models.DocumentSearchResult, refinement string) ([]models.SuggestedSolution, models.ContextUsage, error) {
+	conf := l.providerConfig()
+	fmt.Printf("DEBUG: GenerateSolutions called with provider: %s\n", conf.provider)
+	params := l.GenerationParams(ctx, EndpointSolutions, conf.provider)
+
+	// Steps may be marked automatable with a registered runbook
+	runbooks, err := l.automation.ListRunbooks(ctx)
+	if err != nil {
+		fmt.Printf("Failed to load runbooks: %v\n", err)
+	}
+	refinement += RunbookCatalog(runbooks)
+
+	// Ticket text and document chunks are untrusted, so they are sanitized
+	// and delimited before use.
+	title := l.guardrails.Wrap(ctx, "ticket", ticket.ID.Hex(), "title", ticket.Title)
+	description := l.guardrails.Wrap(ctx, "ticket", ticket.ID.Hex(), "description", ticket.Description)
+
+	// Uploaded system information is requester-supplied and kept short
+	header := "Relevant Documentation:\n\n"
+	if glossary := l.GlossaryPrompt(ctx, ticket.Title+"\n"+ticket.Description); glossary != "" {
+		header = glossary + "\n" + header
+	}
+	if info := LatestSystemInfo(ticket); info != nil {
+		text := TruncateToTokens(SystemInfoText(*info), systemInfoPromptTokens)
+		header = "Requester System Information:\n" + l.guardrails.Wrap(ctx, "ticket", ticket.ID.Hex(), "system info", text) + "\n\n" + header
+	}
+
+	// Budget what is left of the context window after the instructions,
+	// ticket and room for the answer
+	profile := l.Profile()
+	limit := ContextLimit(params.Model, l.contextTokens)
+	fixed := EstimateTokens(solutionSystemPrompt) + EstimateTokens(profile+solutionPrompt(ticket, title, description, header+refinement))
+	budget := limit - params.MaxTokens - fixed
+	if budget < 0 {
+		budget = 0
+	}
+	selected, usage := SelectContext(docResults, budget, func(result models.DocumentSearchResult) string {
+		wrapped := fmt.Sprintf("<untrusted source=%q>\n%s\n</untrusted>", "chunk "+result.Chunk.ID, result.Chunk.Content)
+		return renderSolutionSource(0, result, wrapped)
+	})
 
-	for i, result := range docResults {
-		contextBuilder.WriteString(fmt.Sprintf("Document %d: %s\n", i+1, result.Document.Title))
-		contextBuilder.WriteString(fmt.Sprintf("Content: %s\n", result.Chunk.Content))
-		contextBuilder.WriteString(fmt.Sprintf("Relevance Score: %.2f\n\n", result.Score))
+	var contextBuilder strings.Builder
+	contextBuilder.WriteString(header)
+	for i, result := range selected {
+		wrapped := l.guardrails.Wrap(ctx, "document", result.Document.ID.Hex(), "chunk "+result.Chunk.ID, result.Chunk.Content)
+		contextBuilder.WriteString(renderSolutionSource(i, result, wrapped))
+	}
+	contextBuilder.WriteString(refinement)
+	prompt := profile + solutionPrompt(ticket, title, description, contextBuilder.String())
+
+	usage.Model = params.Model
+	usage.ContextLimit = limit
+	usage.ReservedTokens = params.MaxTokens
+	usage.PromptTokens = EstimateTokens(solutionSystemPrompt) + EstimateTokens(prompt)
+	solutions, err := l.completeSolutions(ticket, selected, prompt, params)
+	for i := range solutions {
+		solutions[i].Automations = FilterAutomations(solutions[i], runbooks)
 	}
+	return solutions, usage, err
+}
 
-	prompt := fmt.Sprintf(`You are an IT support expert. Based on the following ticket and relevant documentation, provide detailed solution suggestions.
+func solutionPrompt(ticket models.Ticket, title, description, documentation string) string {
+	return fmt.Sprintf(`You are an IT support expert. Based on the following ticket and relevant documentation, provide detailed solution suggestions.
 
 Ticket Information:
 - Title: %s
@@ -67,11 +205,17 @@ Format your response as JSON with the following structure:
             "confidence": 0.9
         }
     ]
-}`, ticket.Title, ticket.Description, ticket.Category, ticket.Priority, contextBuilder.String())
+}`, title, description, ticket.Category, ticket.Priority, documentation)
+}
 
-	if l.provider == "openai" && l.openAIAPIKey != "" {
+func (l *LLMService) completeSolutions(ticket models.Ticket, docResults []models.DocumentSearchResult, prompt string, params models.GenerationParams) ([]models.SuggestedSolution, error) {
+	conf := l.providerConfig()
+	if conf.provider == "openai" && conf.openAIAPIKey != "" {
 		fmt.Printf("DEBUG: Calling OpenAI with API key present\n")
-		solutions, err := l.callOpenAI(prompt)
+		solutions, err := l.callOpenAI(prompt, params)
+		if err == nil {
+			solutions, err = ValidateSolutions(solutions)
+		}
 		if err != nil {
 			fmt.Printf("OpenAI LLM failed, falling back to mock solutions: %v\n", err)
 			mockSolutions := l.generateMockSolutions(ticket, docResults)
@@ -80,9 +224,12 @@ Format your response as JSON with the following structure:
 		}
 		fmt.Printf("DEBUG: OpenAI returned %d solutions\n", len(solutions))
 		return solutions, nil
-	} else if l.provider == "local" && l.localLLMURL != "" {
+	} else if conf.provider == "local" && conf.localLLMURL != "" {
 		fmt.Printf("DEBUG: Calling local LLM\n")
-		solutions, err := l.callLocalLLM(prompt)
+		solutions, err := l.callLocalLLM(prompt, params)
+		if err == nil {
+			solutions, err = ValidateSolutions(solutions)
+		}
 		if err != nil {
 			fmt.Printf("Local LLM failed, falling back to mock solutions: %v\n", err)
 			mockSolutions := l.generateMockSolutions(ticket, docResults)
@@ -100,16 +247,15 @@ Format your response as JSON with the following structure:
 	return mockSolutions, nil
 }
 
-func (l *LLMService) callOpenAI(prompt string) ([]models.SuggestedSolution, error) {
+func (l *LLMService) callOpenAI(prompt string, params models.GenerationParams) ([]models.SuggestedSolution, error) {
+	conf := l.providerConfig()
 	url := "https://api.openai.com/v1/chat/completions"
 
-	payload := map[string]interface{}{
-		"model": l.openAIModel,
-		"messages": []map[string]string{
-			{"role": "system", "content": "You are an IT support expert that provides detailed technical solutions. Always respond with valid JSON."},
-			{"role": "user", "content": prompt},
-		},
-		"temperature": 0.7,
+	payload := generationPayload(params)
+	payload["model"] = params.Model
+	payload["messages"] = []map[string]string{
+		{"role": "system", "content": solutionSystemPrompt},
+		{"role": "user", "content": prompt},
 	}
 
 	jsonData, _ := json.Marshal(payload)
@@ -120,7 +266,7 @@ func (l *LLMService) callOpenAI(prompt string) ([]models.SuggestedSolution, erro
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+l.openAIAPIKey)
+	req.Header.Set("Authorization", "Bearer "+conf.openAIAPIKey)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -151,25 +297,13 @@ func (l *LLMService) callOpenAI(prompt string) ([]models.SuggestedSolution, erro
 	content := result.Choices[0].Message.Content
 	
 	// Try to extract JSON from markdown code blocks if present
-	if strings.Contains(content, "```json") {
-		start := strings.Index(content, "```json") + 7
-		end := strings.Index(content[start:], "```")
-		if end > 0 {
-			content = content[start : start+end]
-		}
-	} else if strings.Contains(content, "```") {
-		start := strings.Index(content, "```") + 3
-		end := strings.Index(content[start:], "```")
-		if end > 0 {
-			content = content[start : start+end]
-		}
-	}
+	content = ExtractJSON(content)
 
 	var solutionResponse struct {
 		Solutions []models.SuggestedSolution `json:"solutions"`
 	}
 
-	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &solutionResponse); err != nil {
+	if err := json.Unmarshal([]byte(content), &solutionResponse); err != nil {
 		// If parsing fails, return empty slice
 		return []models.SuggestedSolution{}, fmt.Errorf("failed to parse OpenAI response: %v", err)
 	}
@@ -177,16 +311,15 @@ func (l *LLMService) callOpenAI(prompt string) ([]models.SuggestedSolution, erro
 	return solutionResponse.Solutions, nil
 }
 
-func (l *LLMService) callLocalLLM(prompt string) ([]models.SuggestedSolution, error) {
-	url := l.localLLMURL + "/v1/chat/completions"
+func (l *LLMService) callLocalLLM(prompt string, params models.GenerationParams) ([]models.SuggestedSolution, error) {
+	conf := l.providerConfig()
+	url := conf.localLLMURL + "/v1/chat/completions"
 
-	payload := map[string]interface{}{
-		"model": "local-model",
-		"messages": []map[string]string{
-			{"role": "system", "content": "You are an IT support expert. Always respond with valid JSON."},
-			{"role": "user", "content": prompt},
-		},
-		"temperature": 0.7,
+	payload := generationPayload(params)
+	payload["model"] = params.Model
+	payload["messages"] = []map[string]string{
+		{"role": "system", "content": "You are an IT support expert. Always respond with valid JSON." + untrustedContentPolicy},
+		{"role": "user", "content": prompt},
 	}
 
 	jsonData, _ := json.Marshal(payload)
@@ -226,6 +359,189 @@ func (l *LLMService) callLocalLLM(prompt string) ([]models.SuggestedSolution, er
 	return solutionResponse.Solutions, nil
 }
 
+// ErrLLMUnavailable is returned by Complete when the selected provider has no
+// API key or URL configured, so callers can fall back to mock output.
+var ErrLLMUnavailable = errors.New("no LLM provider configured")
+
+// CompletionRequest describes a single chat completion. Endpoint selects the
+// generation parameters; empty Provider and Model fall back to those and the
+// service configuration.
+type CompletionRequest struct {
+	System   string
+	Prompt   string
+	Endpoint string
+	Provider string
+	Model    string
+}
+
+// GlossaryPrompt explains the organization's terms that text uses, so
+// internal names and acronyms don't confuse the model. It returns "" when
+// text uses none.
+func (l *LLMService) GlossaryPrompt(ctx context.Context, text string) string {
+	terms, err := l.glossary.Used(ctx, text)
+	if err != nil {
+		fmt.Printf("Failed to load glossary: %v\n", err)
+	}
+	return GlossaryPrompt(terms)
+}
+
+// Provider returns the configured default provider name.
+func (l *LLMService) Provider() string {
+	return l.providerConfig().provider
+}
+
+// Model returns the model used for a provider when none is requested.
+func (l *LLMService) Model(provider string) string {
+	if provider == "local" {
+		return "local-model"
+	}
+	return l.providerConfig().openAIModel
+}
+
+// GenerationParams resolves the parameters of an AI endpoint for ctx, using
+// the provider's model when none is configured.
+func (l *LLMService) GenerationParams(ctx context.Context, endpoint, provider string) models.GenerationParams {
+	params := l.generation.Resolve(ctx, endpoint)
+	if params.Model == "" {
+		params.Model = l.Model(provider)
+	}
+	return params
+}
+
+// generationPayload returns the sampling fields of a chat completion payload.
+func generationPayload(params models.GenerationParams) map[string]interface{} {
+	payload := map[string]interface{}{}
+	if params.Temperature != nil {
+		payload["temperature"] = *params.Temperature
+	}
+	if params.MaxTokens > 0 {
+		payload["max_tokens"] = params.MaxTokens
+	}
+	if params.TopP != nil {
+		payload["top_p"] = *params.TopP
+	}
+	return payload
+}
+
+// Complete sends a chat completion to the OpenAI or local (OpenAI-compatible)
+// endpoint and returns the raw message content.
+func (l *LLMService) Complete(ctx context.Context, req CompletionRequest) (string, error) {
+	conf := l.providerConfig()
+	provider := req.Provider
+	if provider == "" {
+		provider = conf.provider
+	}
+	params := l.GenerationParams(ctx, req.Endpoint, provider)
+	if req.Model != "" {
+		params.Model = req.Model
+	}
+
+	var url, apiKey string
+	timeout := 30 * time.Second
+	switch provider {
+	case "openai":
+		if conf.openAIAPIKey == "" {
+			return "", ErrLLMUnavailable
+		}
+		url = "https://api.openai.com/v1/chat/completions"
+		apiKey = conf.openAIAPIKey
+	case "local":
+		if conf.localLLMURL == "" {
+			return "", ErrLLMUnavailable
+		}
+		url = conf.localLLMURL + "/v1/chat/completions"
+		timeout = 60 * time.Second // Longer timeout for local LLMs
+	default:
+		return "", ErrLLMUnavailable
+	}
+
+	payload := generationPayload(params)
+	payload["model"] = params.Model
+	payload["messages"] = []map[string]string{
+		{"role": "system", "content": req.System},
+		{"role": "user", "content": req.Prompt},
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s completion failed: status %d, body: %s", provider, resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", err
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("no response from %s", provider)
+	}
+
+	return result.Choices[0].Message.Content, nil
+}
+
+// modelPricing is the USD price per 1K prompt and completion tokens used for
+// cost estimates. Unknown and local models are treated as free.
+var modelPricing = map[string][2]float64{
+	"gpt-3.5-turbo": {0.0005, 0.0015},
+	"gpt-4o-mini":   {0.00015, 0.0006},
+	"gpt-4o":        {0.0025, 0.01},
+	"gpt-4-turbo":   {0.01, 0.03},
+	"gpt-4":         {0.03, 0.06},
+}
+
+// EstimateCostUSD estimates the price of a completion for the given model.
+func EstimateCostUSD(model string, promptTokens, completionTokens int) float64 {
+	price, ok := modelPricing[model]
+	if !ok {
+		return 0
+	}
+	return float64(promptTokens)/1000*price[0] + float64(completionTokens)/1000*price[1]
+}
+
+// ExtractJSON strips markdown code fences that models often wrap around JSON.
+func ExtractJSON(content string) string {
+	if strings.Contains(content, "```json") {
+		start := strings.Index(content, "```json") + 7
+		end := strings.Index(content[start:], "```")
+		if end > 0 {
+			content = content[start : start+end]
+		}
+	} else if strings.Contains(content, "```") {
+		start := strings.Index(content, "```") + 3
+		end := strings.Index(content[start:], "```")
+		if end > 0 {
+			content = content[start : start+end]
+		}
+	}
+	return strings.TrimSpace(content)
+}
+
 func (l *LLMService) generateMockSolutions(ticket models.Ticket, docResults []models.DocumentSearchResult) []models.SuggestedSolution {
 	// Generate contextual solutions based on ticket category and available documents
 	solutions := []models.SuggestedSolution{}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// MaintenanceService stores maintenance windows and finds the one covering
+// an anomaly.
+type MaintenanceService struct {
+	db *database.MongoDB
+}
+
+func NewMaintenanceService(db *database.MongoDB) *MaintenanceService {
+	return &MaintenanceService{db: db}
+}
+
+func ValidateMaintenanceWindow(req models.MaintenanceWindowRequest) error {
+	if !req.EndsAt.After(req.StartsAt) {
+		return fmt.Errorf("endsAt must be after startsAt")
+	}
+	if len(req.ResourceIDs) == 0 && len(req.Tags) == 0 {
+		return fmt.Errorf("resourceIds or tags are required")
+	}
+	return nil
+}
+
+func (s *MaintenanceService) Create(ctx context.Context, req models.MaintenanceWindowRequest, createdBy primitive.ObjectID) (models.MaintenanceWindow, error) {
+	window := models.MaintenanceWindow{
+		ID:          primitive.NewObjectID(),
+		Name:        req.Name,
+		Description: req.Description,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		ResourceIDs: req.ResourceIDs,
+		Tags:        req.Tags,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if _, err := s.db.GetCollection("maintenance_windows").InsertOne(ctx, window); err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	return window, nil
+}
+
+// List returns windows by start time. With tags, only windows covering a
+// resource carrying those tags, or selecting those tags themselves, are
+// returned; with at, only windows active at that time.
+func (s *MaintenanceService) List(ctx context.Context, tags map[string]string, at *time.Time) ([]models.MaintenanceWindow, error) {
+	filter := bson.M{}
+	if len(tags) > 0 {
+		resourceIDs, err := ResourceIDsWithTags(ctx, s.db, tags)
+		if err != nil {
+			return nil, err
+		}
+		filter["$or"] = []bson.M{tagQuery("tags", tags), {"resourceIds": bson.M{"$in": resourceIDs}}}
+	}
+	if at != nil {
+		filter["startsAt"] = bson.M{"$lte": *at}
+		filter["endsAt"] = bson.M{"$gt": *at}
+	}
+	cursor, err := s.db.GetCollection("maintenance_windows").Find(ctx, filter, options.Find().SetSort(bson.D{{"startsAt", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	windows := []models.MaintenanceWindow{}
+	if err := cursor.All(ctx, &windows); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+func (s *MaintenanceService) Update(ctx context.Context, id primitive.ObjectID, req models.MaintenanceWindowRequest) (models.MaintenanceWindow, error) {
+	set := bson.M{
+		"name":        req.Name,
+		"description": req.Description,
+		"startsAt":    req.StartsAt,
+		"endsAt":      req.EndsAt,
+		"resourceIds": req.ResourceIDs,
+		"tags":        req.Tags,
+		"updatedAt":   time.Now(),
+	}
+	var window models.MaintenanceWindow
+	err := s.db.GetCollection("maintenance_windows").FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&window)
+	return window, err
+}
+
+func (s *MaintenanceService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.db.GetCollection("maintenance_windows").DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Covering returns the window active at `at` that covers resource, which
+// may be nil for ingested alerts on unknown resources, or whose tags match
+// the resource's tags or the alert's labels. It returns nil when none does.
+func (s *MaintenanceService) Covering(ctx context.Context, resource *models.MonitoredResource, labels map[string]string, at time.Time) (*models.MaintenanceWindow, error) {
+	windows, err := s.List(ctx, nil, &at)
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	for k, v := range labels {
+		tags[k] = v
+	}
+	if resource != nil {
+		for k, v := range resource.Tags {
+			tags[k] = v
+		}
+	}
+
+	for i, w := range windows {
+		if resource != nil {
+			for _, id := range w.ResourceIDs {
+				if id == resource.ID {
+					return &windows[i], nil
+				}
+			}
+		}
+		if len(w.Tags) > 0 && TagsMatch(w.Tags, tags) {
+			return &windows[i], nil
+		}
+	}
+	return nil, nil
+}
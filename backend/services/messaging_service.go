@@ -0,0 +1,518 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	// messagingThreadWindow is how long after their last message a
+	// requester's texts keep updating the same ticket.
+	messagingThreadWindow = 72 * time.Hour
+	// maxMessageLength keeps replies within a few SMS segments.
+	maxMessageLength = 480
+	twilioAPIURL     = "https://api.twilio.com/2010-04-01"
+)
+
+var (
+	// ErrInvalidPhone is returned for phone numbers that are not E.164.
+	ErrInvalidPhone = errors.New("phone must be in international format, e.g. +14155550123")
+	// ErrPhoneTaken is returned when another user registered the phone.
+	ErrPhoneTaken = errors.New("phone is registered to another user")
+)
+
+var (
+	e164Pattern      = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	phoneSeparators  = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+	ticketRefMessage = regexp.MustCompile(`^#([0-9a-fA-F]{6})\b\s*`)
+)
+
+// Keywords texted to manage the subscription, as carriers expect.
+var (
+	optOutKeywords = []string{"STOP", "STOPALL", "UNSUBSCRIBE", "CANCEL", "END", "QUIT"}
+	optInKeywords  = []string{"START", "YES", "UNSTOP", "SUBSCRIBE"}
+)
+
+// NormalizePhone strips separators from phone and checks it is E.164.
+func NormalizePhone(phone string) (string, error) {
+	phone = phoneSeparators.Replace(strings.TrimSpace(phone))
+	if !e164Pattern.MatchString(phone) {
+		return "", ErrInvalidPhone
+	}
+	return phone, nil
+}
+
+// MessagingTicketRef is the short ticket reference used in text messages:
+// the last six characters of its ID.
+func MessagingTicketRef(id primitive.ObjectID) string {
+	return strings.ToUpper(id.Hex()[18:])
+}
+
+// MessagingService is the SMS and WhatsApp channel through Twilio. Texts
+// from a requester's registered phone create tickets or add comments to
+// them, and the requester is texted when those tickets change status.
+type MessagingService struct {
+	db           *database.MongoDB
+	parser       *TicketParseService
+	taxonomy     *TaxonomyService
+	org          *OrgService
+	assignment   *AssignmentService
+	comments     *CommentService
+	accountSID   string
+	authToken    string
+	smsFrom      string
+	whatsAppFrom string
+	client       *http.Client
+}
+
+func NewMessagingService(db *database.MongoDB, cfg *config.Config, parser *TicketParseService, taxonomy *TaxonomyService, org *OrgService, assignment *AssignmentService, comments *CommentService) *MessagingService {
+	return &MessagingService{
+		db:           db,
+		parser:       parser,
+		taxonomy:     taxonomy,
+		org:          org,
+		assignment:   assignment,
+		comments:     comments,
+		accountSID:   cfg.TwilioAccountSID,
+		authToken:    cfg.TwilioAuthToken,
+		smsFrom:      cfg.TwilioSMSFrom,
+		whatsAppFrom: cfg.TwilioWhatsAppFrom,
+		client:       &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Enabled reports whether inbound messages are accepted: webhooks can only
+// be verified with the auth token.
+func (s *MessagingService) Enabled() bool {
+	return s.authToken != "" && len(s.Numbers()) > 0
+}
+
+// canSend reports whether messages can be sent outside webhook replies.
+func (s *MessagingService) canSend() bool {
+	return s.Enabled() && s.accountSID != ""
+}
+
+// Numbers returns the configured sender of each channel.
+func (s *MessagingService) Numbers() map[models.MessagingChannel]string {
+	numbers := map[models.MessagingChannel]string{}
+	if s.smsFrom != "" {
+		numbers[models.MessagingSMS] = s.smsFrom
+	}
+	if s.whatsAppFrom != "" {
+		numbers[models.MessagingWhatsApp] = strings.TrimPrefix(s.whatsAppFrom, "whatsapp:")
+	}
+	return numbers
+}
+
+func (s *MessagingService) subscriptions() *mongo.Collection {
+	return s.db.GetCollection("messaging_subscriptions")
+}
+
+func (s *MessagingService) messages() *mongo.Collection {
+	return s.db.GetCollection("ticket_messages")
+}
+
+// Subscription returns the user's subscription, or nil when they have not
+// registered a phone.
+func (s *MessagingService) Subscription(ctx context.Context, userID primitive.ObjectID) (*models.MessagingSubscription, error) {
+	var sub models.MessagingSubscription
+	err := s.subscriptions().FindOne(ctx, bson.M{"_id": userID}).Decode(&sub)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Subscribe registers the user's phone. Changing the phone clears the
+// opt-ins, which the new phone has to send again.
+func (s *MessagingService) Subscribe(ctx context.Context, userID primitive.ObjectID, phone string) (models.MessagingSubscription, error) {
+	phone, err := NormalizePhone(phone)
+	if err != nil {
+		return models.MessagingSubscription{}, err
+	}
+	count, err := s.subscriptions().CountDocuments(ctx, bson.M{"phone": phone, "_id": bson.M{"$ne": userID}})
+	if err != nil {
+		return models.MessagingSubscription{}, err
+	}
+	if count > 0 {
+		return models.MessagingSubscription{}, ErrPhoneTaken
+	}
+
+	now := time.Now()
+	current, err := s.Subscription(ctx, userID)
+	if err != nil {
+		return models.MessagingSubscription{}, err
+	}
+	sub := models.MessagingSubscription{UserID: userID, Phone: phone, OptedIn: []models.MessagingChannel{}, CreatedAt: now, UpdatedAt: now}
+	if current != nil {
+		sub.CreatedAt = current.CreatedAt
+		if current.Phone == phone {
+			sub.OptedIn = current.OptedIn
+		}
+	}
+	_, err = s.subscriptions().ReplaceOne(ctx, bson.M{"_id": userID}, sub, options.Replace().SetUpsert(true))
+	return sub, err
+}
+
+func (s *MessagingService) Unsubscribe(ctx context.Context, userID primitive.ObjectID) error {
+	result, err := s.subscriptions().DeleteOne(ctx, bson.M{"_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// VerifySignature checks the X-Twilio-Signature of a webhook: the base64
+// HMAC-SHA1, keyed with the auth token, of the URL Twilio called followed
+// by the POST parameters sorted by name.
+func (s *MessagingService) VerifySignature(webhookURL string, params url.Values, signature string) bool {
+	if s.authToken == "" || signature == "" {
+		return false
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(webhookURL)
+	for _, k := range keys {
+		for _, v := range params[k] {
+			b.WriteString(k + v)
+		}
+	}
+	mac := hmac.New(sha1.New, []byte(s.authToken))
+	mac.Write([]byte(b.String()))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// HandleInbound processes a message Twilio received from the address from
+// ("+1..." or "whatsapp:+1...") and returns the reply to send back.
+func (s *MessagingService) HandleInbound(ctx context.Context, from, body, messageSID string) (string, error) {
+	channel := models.MessagingSMS
+	if strings.HasPrefix(from, "whatsapp:") {
+		channel = models.MessagingWhatsApp
+	}
+	phone := strings.TrimPrefix(from, "whatsapp:")
+	body = strings.TrimSpace(body)
+
+	inbound := models.TicketMessage{
+		ID:         primitive.NewObjectID(),
+		Channel:    channel,
+		Direction:  models.MessageInbound,
+		Phone:      phone,
+		Body:       body,
+		ProviderID: messageSID,
+		CreatedAt:  time.Now(),
+	}
+	reply, err := s.handleInbound(ctx, &inbound)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.messages().InsertOne(ctx, inbound); err != nil {
+		return "", err
+	}
+	if reply != "" {
+		s.log(ctx, models.TicketMessage{
+			TicketID:  inbound.TicketID,
+			UserID:    inbound.UserID,
+			Channel:   channel,
+			Direction: models.MessageOutbound,
+			Phone:     phone,
+			Body:      reply,
+		})
+	}
+	return reply, nil
+}
+
+// handleInbound acts on the message and links it to its user and ticket.
+func (s *MessagingService) handleInbound(ctx context.Context, msg *models.TicketMessage) (string, error) {
+	var sub models.MessagingSubscription
+	err := s.subscriptions().FindOne(ctx, bson.M{"phone": msg.Phone}).Decode(&sub)
+	if err == mongo.ErrNoDocuments {
+		return "This number is not registered for IT support. Add it under Messaging in the support portal, then text START.", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	msg.UserID = &sub.UserID
+	keyword := strings.ToUpper(msg.Body)
+
+	switch {
+	case containsString(optOutKeywords, keyword):
+		_, err := s.subscriptions().UpdateByID(ctx, sub.UserID, bson.M{
+			"$pull": bson.M{"optedIn": msg.Channel},
+			"$set":  bson.M{"updatedAt": time.Now()},
+		})
+		return "You will no longer get IT support messages here. Text START to opt back in.", err
+	case containsString(optInKeywords, keyword):
+		_, err := s.subscriptions().UpdateByID(ctx, sub.UserID, bson.M{
+			"$addToSet": bson.M{"optedIn": msg.Channel},
+			"$set":      bson.M{"updatedAt": time.Now()},
+		})
+		return "You're subscribed to IT support messages. " + messagingUsage, err
+	case keyword == "HELP":
+		return messagingUsage + " Text STOP to opt out.", nil
+	}
+	if !containsMessagingChannel(sub.OptedIn, msg.Channel) {
+		return "Text START to use IT support over this channel.", nil
+	}
+
+	var user models.User
+	if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": sub.UserID}).Decode(&user); err != nil {
+		return "", err
+	}
+	ticket, text, missing, err := s.threadTicket(ctx, user, msg.Body)
+	if err != nil {
+		return "", err
+	}
+	if missing != "" {
+		return fmt.Sprintf("No ticket #%s found. %s", missing, messagingUsage), nil
+	}
+	if text == "" {
+		return messagingUsage, nil
+	}
+	if ticket != nil {
+		msg.TicketID = &ticket.ID
+		if _, err := s.comments.Add(ctx, ticket.ID, user, text, false); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Added your message to ticket #%s.", MessagingTicketRef(ticket.ID)), nil
+	}
+
+	created, err := s.createTicket(ctx, user, text)
+	if err != nil {
+		return "", err
+	}
+	msg.TicketID = &created.ID
+	return truncateWords(fmt.Sprintf("Created ticket #%s: %s. We'll text you when its status changes. Reply to add details.",
+		MessagingTicketRef(created.ID), created.Title), maxMessageLength), nil
+}
+
+const messagingUsage = "Text your IT issue to open a ticket, NEW <issue> to open another, or #<ticket> <message> to update one."
+
+// threadTicket picks the ticket a message is about, nil for a new one,
+// and returns the text without its prefix. missing is the reference of a
+// ticket the message names that the user does not have.
+func (s *MessagingService) threadTicket(ctx context.Context, user models.User, body string) (ticket *models.Ticket, text, missing string, err error) {
+	if strings.HasPrefix(strings.ToUpper(body), "NEW ") {
+		return nil, strings.TrimSpace(body[4:]), "", nil
+	}
+
+	if m := ticketRefMessage.FindStringSubmatch(body); m != nil {
+		ref, text := strings.ToUpper(m[1]), strings.TrimSpace(body[len(m[0]):])
+		cursor, err := s.db.GetCollection("tickets").Find(ctx, bson.M{"createdBy": user.ID},
+			options.Find().SetProjection(bson.M{"title": 1}).SetSort(bson.D{{"createdAt", -1}}))
+		if err != nil {
+			return nil, "", "", err
+		}
+		var tickets []models.Ticket
+		if err := cursor.All(ctx, &tickets); err != nil {
+			return nil, "", "", err
+		}
+		for i, t := range tickets {
+			if MessagingTicketRef(t.ID) == ref {
+				return &tickets[i], text, "", nil
+			}
+		}
+		return nil, text, ref, nil
+	}
+
+	// Otherwise the open ticket the requester last texted about
+	var last models.TicketMessage
+	err = s.messages().FindOne(ctx, bson.M{
+		"userId":    user.ID,
+		"direction": models.MessageInbound,
+		"ticketId":  bson.M{"$exists": true},
+		"createdAt": bson.M{"$gte": time.Now().Add(-messagingThreadWindow)},
+	}, options.FindOne().SetSort(bson.D{{"createdAt", -1}})).Decode(&last)
+	if err == mongo.ErrNoDocuments {
+		return nil, body, "", nil
+	}
+	if err != nil {
+		return nil, "", "", err
+	}
+	var open models.Ticket
+	err = s.db.GetCollection("tickets").FindOne(ctx, bson.M{
+		"_id":    last.TicketID,
+		"status": bson.M{"$in": bson.A{models.StatusOpen, models.StatusInProgress}},
+	}).Decode(&open)
+	if err == mongo.ErrNoDocuments {
+		return nil, body, "", nil
+	}
+	if err != nil {
+		return nil, "", "", err
+	}
+	return &open, body, "", nil
+}
+
+// createTicket drafts a ticket from the message and routes and assigns it
+// like a portal ticket.
+func (s *MessagingService) createTicket(ctx context.Context, user models.User, text string) (models.Ticket, error) {
+	parsed, err := s.parser.Parse(ctx, text)
+	if err != nil {
+		return models.Ticket{}, err
+	}
+	taxonomy := s.taxonomy.Get(ctx)
+	if parsed.Ticket.Category == "" {
+		parsed.Ticket.Category = DefaultCategory(taxonomy)
+	}
+	if parsed.Ticket.Priority == "" {
+		parsed.Ticket.Priority = DefaultPriority(taxonomy)
+	}
+
+	now := time.Now()
+	ticket := models.Ticket{
+		ID:          primitive.NewObjectID(),
+		Title:       parsed.Ticket.Title,
+		Description: parsed.Ticket.Description,
+		Category:    parsed.Ticket.Category,
+		Subcategory: parsed.Ticket.Subcategory,
+		Priority:    parsed.Ticket.Priority,
+		Status:      models.StatusOpen,
+		CreatedBy:   user.ID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.org.RouteTicket(ctx, &ticket, user); err != nil {
+		log.Printf("Failed to route ticket %s: %v", ticket.ID.Hex(), err)
+	}
+	if err := s.assignment.Assign(ctx, &ticket); err != nil {
+		log.Printf("Failed to auto-assign ticket %s: %v", ticket.ID.Hex(), err)
+	}
+	_, err = s.db.GetCollection("tickets").InsertOne(ctx, ticket)
+	return ticket, err
+}
+
+// NotifyStatus texts the requester the ticket's new status, on the channel
+// they last texted about it, if they are still opted in to it.
+func (s *MessagingService) NotifyStatus(ctx context.Context, ticket models.Ticket) {
+	if !s.canSend() {
+		return
+	}
+	var last models.TicketMessage
+	err := s.messages().FindOne(ctx, bson.M{"ticketId": ticket.ID, "direction": models.MessageInbound},
+		options.FindOne().SetSort(bson.D{{"createdAt", -1}})).Decode(&last)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Failed to look up messages of ticket %s: %v", ticket.ID.Hex(), err)
+		}
+		return
+	}
+	sub, err := s.Subscription(ctx, ticket.CreatedBy)
+	if err != nil || sub == nil || sub.Phone != last.Phone || !containsMessagingChannel(sub.OptedIn, last.Channel) {
+		return
+	}
+
+	body := truncateWords(fmt.Sprintf("Ticket #%s (%s) is now %s.", MessagingTicketRef(ticket.ID), ticket.Title,
+		strings.ReplaceAll(string(ticket.Status), "_", " ")), maxMessageLength)
+	msg := models.TicketMessage{
+		TicketID:  &ticket.ID,
+		UserID:    &ticket.CreatedBy,
+		Channel:   last.Channel,
+		Direction: models.MessageOutbound,
+		Phone:     last.Phone,
+		Body:      body,
+	}
+	msg.ProviderID, err = s.send(ctx, last.Channel, last.Phone, body)
+	if err != nil {
+		log.Printf("Failed to text status of ticket %s: %v", ticket.ID.Hex(), err)
+		msg.Error = err.Error()
+	}
+	s.log(ctx, msg)
+}
+
+// send sends a message through the Twilio Messages API and returns its SID.
+func (s *MessagingService) send(ctx context.Context, channel models.MessagingChannel, phone, body string) (string, error) {
+	from, to := s.smsFrom, phone
+	if channel == models.MessagingWhatsApp {
+		from, to = s.whatsAppFrom, "whatsapp:"+phone
+	}
+	if from == "" {
+		return "", fmt.Errorf("no %s number configured", channel)
+	}
+	form := url.Values{"From": {from}, "To": {to}, "Body": {body}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/Accounts/%s/Messages.json", twilioAPIURL, s.accountSID), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(s.accountSID, s.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 300))
+		return "", fmt.Errorf("Twilio returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	var result struct {
+		SID string `json:"sid"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result.SID, err
+}
+
+func (s *MessagingService) log(ctx context.Context, msg models.TicketMessage) {
+	msg.ID = primitive.NewObjectID()
+	msg.CreatedAt = time.Now()
+	if _, err := s.messages().InsertOne(ctx, msg); err != nil {
+		log.Printf("Failed to log %s message to %s: %v", msg.Channel, msg.Phone, err)
+	}
+}
+
+// TicketMessages returns the messages logged with a ticket, oldest first.
+func (s *MessagingService) TicketMessages(ctx context.Context, ticketID primitive.ObjectID) ([]models.TicketMessage, error) {
+	cursor, err := s.messages().Find(ctx, bson.M{"ticketId": ticketID}, options.Find().SetSort(bson.D{{"createdAt", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	messages := []models.TicketMessage{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func containsMessagingChannel(channels []models.MessagingChannel, channel models.MessagingChannel) bool {
+	for _, c := range channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// MetricTemplateService stores tag-based metric templates and resolves the
+// metrics a resource is monitored with.
+type MetricTemplateService struct {
+	db *database.MongoDB
+}
+
+func NewMetricTemplateService(db *database.MongoDB) *MetricTemplateService {
+	return &MetricTemplateService{db: db}
+}
+
+// ValidateMetricTemplate checks the tag selector and every metric, including
+// its severity policy.
+func ValidateMetricTemplate(taxonomy models.Taxonomy, req models.MetricTemplateRequest) error {
+	if len(req.Tags) == 0 {
+		return fmt.Errorf("tags are required to select resources")
+	}
+	if len(req.Metrics) == 0 {
+		return fmt.Errorf("metrics are required")
+	}
+	seen := map[string]bool{}
+	for i, m := range req.Metrics {
+		if m.MetricName == "" || m.Statistic == "" {
+			return fmt.Errorf("metrics[%d]: metricName and statistic are required", i)
+		}
+		key := m.MetricName + "/" + m.Statistic
+		if seen[key] {
+			return fmt.Errorf("metrics[%d]: %s is listed twice", i, key)
+		}
+		seen[key] = true
+		if m.PeriodSeconds <= 0 || m.WindowSize <= 0 {
+			return fmt.Errorf("metrics[%d]: periodSeconds and windowSize must be positive", i)
+		}
+		if m.Direction != "" && m.Direction != models.DirectionAbove && m.Direction != models.DirectionBelow {
+			return fmt.Errorf("metrics[%d]: direction must be above or below", i)
+		}
+		if err := ValidateAnomalyPolicy(taxonomy, m.SeverityThresholds, m.PriorityMap); err != nil {
+			return fmt.Errorf("metrics[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (s *MetricTemplateService) Create(ctx context.Context, req models.MetricTemplateRequest, createdBy primitive.ObjectID) (models.MetricTemplate, error) {
+	template := models.MetricTemplate{
+		ID:          primitive.NewObjectID(),
+		Name:        req.Name,
+		Description: req.Description,
+		Tags:        req.Tags,
+		Metrics:     templateMetrics(req.Metrics),
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if _, err := s.db.GetCollection("mon_metric_templates").InsertOne(ctx, template); err != nil {
+		return models.MetricTemplate{}, err
+	}
+	return template, nil
+}
+
+// List returns the templates carrying every tag in the filter.
+func (s *MetricTemplateService) List(ctx context.Context, tags map[string]string) ([]models.MetricTemplate, error) {
+	cursor, err := s.db.GetCollection("mon_metric_templates").Find(ctx, tagQuery("tags", tags), options.Find().SetSort(bson.D{{"name", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	templates := []models.MetricTemplate{}
+	if err := cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (s *MetricTemplateService) Update(ctx context.Context, id primitive.ObjectID, req models.MetricTemplateRequest) (models.MetricTemplate, error) {
+	set := bson.M{
+		"name":        req.Name,
+		"description": req.Description,
+		"tags":        req.Tags,
+		"metrics":     templateMetrics(req.Metrics),
+		"updatedAt":   time.Now(),
+	}
+	if req.Enabled != nil {
+		set["enabled"] = *req.Enabled
+	}
+	var template models.MetricTemplate
+	err := s.db.GetCollection("mon_metric_templates").FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&template)
+	return template, err
+}
+
+// templateMetrics enables every metric of a template; templates are switched
+// on and off as a whole.
+func templateMetrics(metrics []models.ExportedMetric) []models.ExportedMetric {
+	for i := range metrics {
+		metrics[i].Enabled = true
+	}
+	return metrics
+}
+
+func (s *MetricTemplateService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.db.GetCollection("mon_metric_templates").DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Effective returns the metrics resource is monitored with: its own metrics
+// followed by those of the enabled templates matching its tags. Own metrics,
+// even disabled ones, override template metrics with the same name and
+// statistic; between templates the first by name wins.
+func (s *MetricTemplateService) Effective(ctx context.Context, resource models.MonitoredResource) ([]models.MetricConfig, error) {
+	cursor, err := s.db.GetCollection("mon_metrics").Find(ctx, bson.M{"resourceId": resource.ID})
+	if err != nil {
+		return nil, err
+	}
+	metrics := []models.MetricConfig{}
+	err = cursor.All(ctx, &metrics)
+	cursor.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, m := range metrics {
+		seen[m.MetricName+"/"+m.Statistic] = true
+	}
+	if len(resource.Tags) == 0 {
+		return metrics, nil
+	}
+
+	templates, err := s.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		if !t.Enabled || !TagsMatch(t.Tags, resource.Tags) {
+			continue
+		}
+		templateID := t.ID
+		for _, m := range t.Metrics {
+			key := m.MetricName + "/" + m.Statistic
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			metrics = append(metrics, models.MetricConfig{
+				ResourceID:         resource.ID,
+				MetricName:         m.MetricName,
+				Statistic:          m.Statistic,
+				PeriodSeconds:      m.PeriodSeconds,
+				WindowSize:         m.WindowSize,
+				ZScore:             m.ZScore,
+				MinConsecutive:     m.MinConsecutive,
+				Direction:          m.Direction,
+				PriorityMap:        m.PriorityMap,
+				SeverityThresholds: m.SeverityThresholds,
+				RecoveryPoints:     m.RecoveryPoints,
+				Enabled:            m.Enabled,
+				TemplateID:         &templateID,
+				CreatedAt:          t.CreatedAt,
+				UpdatedAt:          t.UpdatedAt,
+			})
+		}
+	}
+	return metrics, nil
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// MonitorRouteService matches anomalies to team notification routes.
+type MonitorRouteService struct {
+	db            *database.MongoDB
+	notifications *NotificationService
+}
+
+func NewMonitorRouteService(db *database.MongoDB, notifications *NotificationService) *MonitorRouteService {
+	return &MonitorRouteService{db: db, notifications: notifications}
+}
+
+// Validate checks a route's match, channels and assignee, who must be an
+// admin or technician.
+func (s *MonitorRouteService) Validate(ctx context.Context, req models.MonitorRouteRequest) error {
+	if strings.TrimSpace(req.Team) == "" {
+		return fmt.Errorf("team is required")
+	}
+	if req.Match.MinSeverity != "" && !isAnomalySeverity(req.Match.MinSeverity) {
+		return fmt.Errorf("match.minSeverity must be critical, high, medium or low")
+	}
+	for _, t := range req.Match.Types {
+		if t != models.ResourceEC2 && t != models.ResourceALB && t != models.ResourceECS {
+			return fmt.Errorf("match.types: unknown resource type %q", t)
+		}
+	}
+	if len(req.Emails) == 0 && len(req.WebhookURLs) == 0 && req.AssignTo == nil {
+		return fmt.Errorf("a route needs emails, webhookUrls or assignTo")
+	}
+	for _, email := range req.Emails {
+		if !strings.Contains(email, "@") {
+			return fmt.Errorf("emails: invalid address %q", email)
+		}
+	}
+	for _, raw := range req.WebhookURLs {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhookUrls: %q is not an http(s) URL", raw)
+		}
+	}
+	if req.AssignTo != nil {
+		var user models.User
+		err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": *req.AssignTo}).Decode(&user)
+		if err != nil || (user.Role != models.RoleAdmin && user.Role != models.RoleTechnician) {
+			return fmt.Errorf("assignTo must be an admin or technician")
+		}
+	}
+	return nil
+}
+
+func (s *MonitorRouteService) Create(ctx context.Context, req models.MonitorRouteRequest, createdBy primitive.ObjectID) (models.MonitorRoute, error) {
+	route := models.MonitorRoute{
+		ID:          primitive.NewObjectID(),
+		Name:        req.Name,
+		Order:       req.Order,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		Match:       req.Match,
+		Team:        req.Team,
+		AssignTo:    req.AssignTo,
+		Emails:      req.Emails,
+		WebhookURLs: req.WebhookURLs,
+		Continue:    req.Continue,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if _, err := s.db.GetCollection("mon_routes").InsertOne(ctx, route); err != nil {
+		return models.MonitorRoute{}, err
+	}
+	return route, nil
+}
+
+// List returns routes in evaluation order.
+func (s *MonitorRouteService) List(ctx context.Context) ([]models.MonitorRoute, error) {
+	cursor, err := s.db.GetCollection("mon_routes").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{"order", 1}, {"createdAt", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	routes := []models.MonitorRoute{}
+	if err := cursor.All(ctx, &routes); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+func (s *MonitorRouteService) Update(ctx context.Context, id primitive.ObjectID, req models.MonitorRouteRequest) (models.MonitorRoute, error) {
+	set := bson.M{
+		"name":        req.Name,
+		"order":       req.Order,
+		"match":       req.Match,
+		"team":        req.Team,
+		"assignTo":    req.AssignTo,
+		"emails":      req.Emails,
+		"webhookUrls": req.WebhookURLs,
+		"continue":    req.Continue,
+		"updatedAt":   time.Now(),
+	}
+	if req.Enabled != nil {
+		set["enabled"] = *req.Enabled
+	}
+	var route models.MonitorRoute
+	err := s.db.GetCollection("mon_routes").FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&route)
+	return route, err
+}
+
+func (s *MonitorRouteService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.db.GetCollection("mon_routes").DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Match returns the enabled routes for an anomaly of the given severity on
+// resource, which may be nil for ingested alerts on unknown resources. The
+// labels of ingested alerts are matched as tags.
+func (s *MonitorRouteService) Match(ctx context.Context, resource *models.MonitoredResource, severity string, labels map[string]string) ([]models.MonitorRoute, error) {
+	routes, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	for k, v := range labels {
+		tags[k] = v
+	}
+	if resource != nil {
+		for k, v := range resource.Tags {
+			tags[k] = v
+		}
+	}
+
+	matched := []models.MonitorRoute{}
+	for _, route := range routes {
+		if !route.Enabled || !routeMatches(route.Match, resource, severity, tags) {
+			continue
+		}
+		matched = append(matched, route)
+		if !route.Continue {
+			break
+		}
+	}
+	return matched, nil
+}
+
+func routeMatches(m models.MonitorRouteMatch, resource *models.MonitoredResource, severity string, tags map[string]string) bool {
+	if m.MinSeverity != "" && severityRank(severity) < severityRank(m.MinSeverity) {
+		return false
+	}
+	if len(m.Namespaces) > 0 || len(m.Types) > 0 {
+		if resource == nil {
+			return false
+		}
+		if len(m.Namespaces) > 0 && !containsFold(m.Namespaces, resource.Namespace) {
+			return false
+		}
+		if len(m.Types) > 0 && !containsType(m.Types, resource.Type) {
+			return false
+		}
+	}
+	return TagsMatch(m.Tags, tags)
+}
+
+// severityRank orders anomaly severities from low (0) to critical (3).
+func severityRank(severity string) int {
+	for i, s := range anomalySeverities {
+		if s == severity {
+			return len(anomalySeverities) - 1 - i
+		}
+	}
+	return 0
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsType(types []models.MonitoredResourceType, t models.MonitoredResourceType) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Route records the teams of the routes matching anomaly on the record,
+// assigns its ticket to the first route assignee and notifies the routes'
+// channels in the background. Anomalies no route matches are left as before.
+func (s *MonitorRouteService) Route(ctx context.Context, resource *models.MonitoredResource, anomaly *models.AnomalyRecord, title string) {
+	routes, err := s.Match(ctx, resource, anomaly.Severity, anomaly.Labels)
+	if err != nil {
+		log.Printf("monitor route lookup failed: %v", err)
+		return
+	}
+	if len(routes) == 0 {
+		return
+	}
+
+	assigned := false
+	for _, route := range routes {
+		anomaly.Teams = append(anomaly.Teams, route.Team)
+		if assigned || route.AssignTo == nil || anomaly.TicketID == nil {
+			continue
+		}
+		_, err := s.db.GetCollection("tickets").UpdateOne(ctx,
+			bson.M{"_id": *anomaly.TicketID, "assignedTo": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"assignedTo": *route.AssignTo, "updatedAt": time.Now()}})
+		if err != nil {
+			log.Printf("failed to assign anomaly ticket %s for route %s: %v", anomaly.TicketID.Hex(), route.Name, err)
+			continue
+		}
+		assigned = true
+	}
+
+	n := s.notification(resource, *anomaly, title)
+	go func() {
+		for _, route := range routes {
+			if len(route.Emails) == 0 && len(route.WebhookURLs) == 0 {
+				continue
+			}
+			msg := n
+			msg.Subject = fmt.Sprintf("[%s] %s", route.Team, n.Subject)
+			msg.Recipients = route.Emails
+			if _, err := s.notifications.SendTo(context.Background(), msg, route.WebhookURLs); err != nil {
+				log.Printf("monitor route %s notification failed: %v", route.Name, err)
+			}
+		}
+	}()
+}
+
+func (s *MonitorRouteService) notification(resource *models.MonitoredResource, a models.AnomalyRecord, title string) Notification {
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("Severity: %s\nMetric: %s\n", a.Severity, a.MetricName))
+	if resource != nil {
+		body.WriteString(fmt.Sprintf("Resource: %s (%s)\n", resource.Identifier, resource.Namespace))
+	}
+	if a.Source == "" {
+		body.WriteString(fmt.Sprintf("Value: %.2f, baseline mean: %.2f, z: %.2f\n", a.Value, a.BaselineMean, a.ZScore))
+	}
+	body.WriteString(fmt.Sprintf("Detected: %s\n", a.Timestamp.Format(time.RFC3339)))
+	if a.TicketID != nil {
+		body.WriteString(fmt.Sprintf("Ticket: %s\n", a.TicketID.Hex()))
+	}
+	return Notification{Subject: title, Body: body.String()}
+}
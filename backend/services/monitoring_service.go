@@ -2,6 +2,7 @@ package services
 
 import (
     "context"
+    "errors"
     "fmt"
     "log"
     "time"
@@ -19,10 +20,16 @@ type MonitoringService struct {
     cw           *CloudWatchService
     cfg          *config.Config
     llm          *LLMService
+    policies     *AnomalyPolicies
+    comments     *CommentService
+    routes       *MonitorRouteService
+    templates    *MetricTemplateService
+    maintenance  *MaintenanceService
 }
 
-func NewMonitoringService(db *database.MongoDB, cw *CloudWatchService, cfg *config.Config, llm *LLMService) *MonitoringService {
-    return &MonitoringService{db: db, cw: cw, cfg: cfg, llm: llm}
+func NewMonitoringService(db *database.MongoDB, cw *CloudWatchService, cfg *config.Config, llm *LLMService, routes *MonitorRouteService, policies *AnomalyPolicies) *MonitoringService {
+    return &MonitoringService{db: db, cw: cw, cfg: cfg, llm: llm, policies: policies, comments: NewCommentService(db), routes: routes,
+        templates: NewMetricTemplateService(db), maintenance: NewMaintenanceService(db)}
 }
 
 func (m *MonitoringService) Start(ctx context.Context) {
@@ -51,14 +58,13 @@ func (m *MonitoringService) pollOnce(ctx context.Context) error {
     var resources []models.MonitoredResource
     if err := cur.All(ctx, &resources); err != nil { return err }
 
-    // For each resource, load metrics
+    // For each resource, load its own metrics and those of matching templates
     for _, r := range resources {
-        var metrics []models.MetricConfig
-        mc, err := m.db.GetCollection("mon_metrics").Find(ctx, bson.M{"resourceId": r.ID, "enabled": true})
+        metrics, err := m.templates.Effective(ctx, r)
         if err != nil { return err }
-        if err := mc.All(ctx, &metrics); err != nil { return err }
 
         for _, mcg := range metrics {
+            if !mcg.Enabled { continue }
             if err := m.evaluateMetric(ctx, r, mcg); err != nil {
                 log.Printf("evaluate metric error: %v", err)
             }
@@ -70,7 +76,15 @@ func (m *MonitoringService) pollOnce(ctx context.Context) error {
 func (m *MonitoringService) evaluateMetric(ctx context.Context, r models.MonitoredResource, mcg models.MetricConfig) error {
     end := time.Now().UTC()
     totalPoints := mcg.WindowSize + mcg.MinConsecutive
-    start := end.Add(-time.Duration(totalPoints*mcg.PeriodSeconds) * time.Second)
+    recoveryPoints := mcg.RecoveryPoints
+    if recoveryPoints <= 0 {
+        recoveryPoints = m.cfg.AnomalyRecoveryPoints
+    }
+    fetchPoints := totalPoints
+    if recoveryPoints > fetchPoints {
+        fetchPoints = recoveryPoints
+    }
+    start := end.Add(-time.Duration(fetchPoints*mcg.PeriodSeconds) * time.Second)
 
     series, err := m.cw.GetMetricSeries(ctx, MetricQueryInput{
         Namespace:  r.Namespace,
@@ -82,18 +96,24 @@ func (m *MonitoringService) evaluateMetric(ctx context.Context, r models.Monitor
         EndTime:    end,
     })
     if err != nil { return err }
+
+    // dedup key: resource+metric within 30m
+    dedup := fmt.Sprintf("%s:%s:%s", r.ID.Hex(), r.Namespace, mcg.MetricName)
+    if err := m.closeRecovered(ctx, mcg, dedup, series, recoveryPoints); err != nil {
+        log.Printf("anomaly recovery check error: %v", err)
+    }
+
     if len(series.Values) < totalPoints { return nil }
 
     res := DetectZScoreAnomaly(series.Values, mcg.WindowSize, mcg.ZScore, mcg.MinConsecutive, string(mcg.Direction))
     if !res.IsAnomaly { return nil }
 
-    // dedup key: resource+metric within 30m
-    dedup := fmt.Sprintf("%s:%s:%s", r.ID.Hex(), r.Namespace, mcg.MetricName)
     since := time.Now().Add(-30 * time.Minute)
     count, err := m.db.GetCollection("mon_anomalies").CountDocuments(ctx, bson.M{"dedupKey": dedup, "createdAt": bson.M{"$gte": since}})
     if err == nil && count > 0 { return nil }
 
-    severity := mapSeverity(res.ZScore)
+    policy := m.policies.Default().For(mcg)
+    severity := policy.Severity(res.ZScore)
 
     anomaly := models.AnomalyRecord{
         ID:           primitive.NewObjectID(),
@@ -110,9 +130,18 @@ func (m *MonitoringService) evaluateMetric(ctx context.Context, r models.Monitor
         CreatedAt:    time.Now(),
     }
 
+    window, err := m.maintenance.Covering(ctx, &r, nil, anomaly.Timestamp)
+    if err != nil {
+        log.Printf("maintenance window lookup failed: %v", err)
+    } else if window != nil {
+        anomaly.MaintenanceWindowID = &window.ID
+        _, err = m.db.GetCollection("mon_anomalies").InsertOne(ctx, anomaly)
+        return err
+    }
+
     var ticketID *primitive.ObjectID
     if m.cfg.AnomalyCreateTickets {
-        tID, err := m.createTicketForAnomaly(ctx, r, mcg, series, anomaly)
+        tID, err := m.createTicketForAnomaly(ctx, r, mcg, series, anomaly, policy.Priority(severity))
         if err != nil {
             log.Printf("ticket creation failed: %v", err)
         } else if tID != nil {
@@ -120,61 +149,117 @@ func (m *MonitoringService) evaluateMetric(ctx context.Context, r models.Monitor
             anomaly.TicketID = ticketID
         }
     }
+    m.routes.Route(ctx, &r, &anomaly, fmt.Sprintf("Anomaly detected: %s on %s", mcg.MetricName, r.Identifier))
 
     _, err = m.db.GetCollection("mon_anomalies").InsertOne(ctx, anomaly)
     return err
 }
 
-func mapSeverity(z float64) string {
-    az := z
-    if az < 0 { az = -az }
-    switch {
-    case az >= 5:
-        return "critical"
-    case az >= 4:
-        return "high"
-    case az >= 3:
-        return "medium"
-    default:
-        return "low"
+// closeRecovered closes the metric's open anomalies once the last n points
+// are back within the detection threshold of the baseline recorded when
+// each anomaly was detected.
+func (m *MonitoringService) closeRecovered(ctx context.Context, mcg models.MetricConfig, dedup string, series MetricSeries, n int) error {
+    cur, err := m.db.GetCollection("mon_anomalies").Find(ctx, bson.M{"dedupKey": dedup, "status": models.AnomalyOpen})
+    if err != nil { return err }
+    var open []models.AnomalyRecord
+    if err := cur.All(ctx, &open); err != nil { return err }
+
+    threshold := mcg.ZScore
+    if threshold <= 0 {
+        threshold = m.cfg.MonitorDefaultZScore
+    }
+    for _, a := range open {
+        recoveredAt, ok := DetectRecovery(series.Values, series.Timestamps, a.Timestamp, a.BaselineMean, a.BaselineStd, threshold, n)
+        if !ok { continue }
+
+        note := fmt.Sprintf("Recovered at %s: the last %d points of %s were within %g standard deviations of the baseline mean %.2f (latest value %.2f).",
+            recoveredAt.Format(time.RFC3339), n, mcg.MetricName, threshold, a.BaselineMean, series.Values[len(series.Values)-1])
+        now := time.Now()
+        res, err := m.db.GetCollection("mon_anomalies").UpdateOne(ctx,
+            bson.M{"_id": a.ID, "status": models.AnomalyOpen},
+            bson.M{"$set": bson.M{"status": models.AnomalyClosed, "closedAt": now, "recoveredAt": recoveredAt, "resolution": note}})
+        if err != nil { return err }
+        if res.ModifiedCount == 0 || a.TicketID == nil { continue }
+
+        if err := noteRecoveryOnTicket(ctx, m.db, m.comments, m.cfg.AnomalyResolveTickets, *a.TicketID, note); err != nil {
+            log.Printf("failed to update ticket %s for recovered anomaly: %v", a.TicketID.Hex(), err)
+        }
     }
+    return nil
 }
 
-func (m *MonitoringService) createTicketForAnomaly(ctx context.Context, r models.MonitoredResource, mcg models.MetricConfig, series MetricSeries, a models.AnomalyRecord) (*primitive.ObjectID, error) {
-    // Build ticket directly into DB using existing schema. Assign to admin if exists.
-    // Find admin user
+// noteRecoveryOnTicket adds the recovery note to an anomaly's open ticket
+// and, when resolve is set, resolves it.
+func noteRecoveryOnTicket(ctx context.Context, db *database.MongoDB, comments *CommentService, resolve bool, ticketID primitive.ObjectID, note string) error {
+    var ticket models.Ticket
+    if err := db.GetCollection("tickets").FindOne(ctx, bson.M{"_id": ticketID}).Decode(&ticket); err != nil { return err }
+    if ticket.Status == models.StatusResolved || ticket.Status == models.StatusClosed { return nil }
+
     var admin models.User
-    err := m.db.GetCollection("users").FindOne(ctx, bson.M{"role": models.RoleAdmin}).Decode(&admin)
-    if err != nil { return nil, err }
+    if err := db.GetCollection("users").FindOne(ctx, bson.M{"role": models.RoleAdmin}).Decode(&admin); err != nil { return err }
 
+    body := "Anomaly closed automatically. " + note
+    if resolve {
+        now := time.Now()
+        update := bson.M{"$set": bson.M{"updatedAt": now}}
+        err := ResolveTicketStatus(ctx, db, update, ticket, now)
+        switch {
+        case errors.Is(err, ErrInvalidStatusTransition):
+            body += " The ticket was left open: " + err.Error() + "."
+        case err != nil:
+            return err
+        default:
+            if _, err := db.GetCollection("tickets").UpdateByID(ctx, ticketID, update); err != nil { return err }
+            body += " The ticket was resolved."
+        }
+    }
+    _, err := comments.Add(ctx, ticketID, admin, body, true)
+    return err
+}
+
+func (m *MonitoringService) createTicketForAnomaly(ctx context.Context, r models.MonitoredResource, mcg models.MetricConfig, series MetricSeries, a models.AnomalyRecord, priority models.TicketPriority) (*primitive.ObjectID, error) {
     title := fmt.Sprintf("Anomaly detected: %s on %s", mcg.MetricName, r.Identifier)
     desc := fmt.Sprintf("Metric %s in %s for %s breached z-score threshold.\nCurrent: %.2f, Baseline mean: %.2f, std: %.2f, z: %.2f\nWindow: last %d x %ds\n",
         mcg.MetricName, r.Namespace, r.Identifier, a.Value, a.BaselineMean, a.BaselineStd, a.ZScore, mcg.WindowSize, mcg.PeriodSeconds)
 
-    priority := models.PriorityMedium
-    switch a.Severity {
+    return insertAnomalyTicket(ctx, m.db, title, desc, models.CategoryPerformance, priority, &r.ID)
+}
+
+// severityPriority maps an anomaly severity to a ticket priority.
+func severityPriority(severity string) models.TicketPriority {
+    switch severity {
     case "critical":
-        priority = models.PriorityCritical
+        return models.PriorityCritical
     case "high":
-        priority = models.PriorityHigh
+        return models.PriorityHigh
     case "low":
-        priority = models.PriorityLow
+        return models.PriorityLow
     }
+    return models.PriorityMedium
+}
+
+// insertAnomalyTicket builds a ticket directly into the DB using the existing
+// schema, created by the first admin user.
+func insertAnomalyTicket(ctx context.Context, db *database.MongoDB, title, desc string, category models.TicketCategory, priority models.TicketPriority, resourceID *primitive.ObjectID) (*primitive.ObjectID, error) {
+    var admin models.User
+    err := db.GetCollection("users").FindOne(ctx, bson.M{"role": models.RoleAdmin}).Decode(&admin)
+    if err != nil { return nil, err }
 
     ticket := models.Ticket{
         ID:          primitive.NewObjectID(),
         Title:       title,
         Description: desc,
-        Category:    models.CategoryPerformance,
+        Category:    category,
         Priority:    priority,
         Status:      models.StatusOpen,
         CreatedBy:   admin.ID,
         CreatedAt:   time.Now(),
         UpdatedAt:   time.Now(),
     }
-    _, err = m.db.GetCollection("tickets").InsertOne(ctx, ticket)
+    if resourceID != nil {
+        ticket.LinkedResourceIDs = []primitive.ObjectID{*resourceID}
+    }
+    _, err = db.GetCollection("tickets").InsertOne(ctx, ticket)
     if err != nil { return nil, err }
     return &ticket.ID, nil
 }
-
-
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"intelliops-ai-copilot/config"
+)
+
+// ErrEmailNotConfigured is returned by SendEmail when SMTP is not set up.
+var ErrEmailNotConfigured = errors.New("email notifications are not configured")
+
+// Notification is a message delivered over every configured channel.
+// Recipients are email addresses; webhook channels ignore them.
+type Notification struct {
+	Subject    string
+	Body       string
+	Recipients []string
+}
+
+// NotificationChannel delivers notifications to one destination type.
+type NotificationChannel interface {
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// NotificationService fans notifications out to the configured channels.
+type NotificationService struct {
+	// mu guards channels, which are rebuilt when the admin settings change
+	mu       sync.RWMutex
+	channels []NotificationChannel
+}
+
+func NewNotificationService(cfg *config.Config) *NotificationService {
+	return &NotificationService{channels: notificationChannels(cfg)}
+}
+
+// Configure rebuilds the channels from cfg.
+func (s *NotificationService) Configure(cfg *config.Config) {
+	channels := notificationChannels(cfg)
+	s.mu.Lock()
+	s.channels = channels
+	s.mu.Unlock()
+}
+
+func (s *NotificationService) current() []NotificationChannel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.channels
+}
+
+func notificationChannels(cfg *config.Config) []NotificationChannel {
+	var channels []NotificationChannel
+	for _, url := range cfg.NotifyWebhookURLs {
+		channels = append(channels, &webhookChannel{url: url, client: &http.Client{Timeout: 10 * time.Second}})
+	}
+	if cfg.SMTPHost != "" {
+		channels = append(channels, &emailChannel{
+			addr:     fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort),
+			host:     cfg.SMTPHost,
+			username: cfg.SMTPUsername,
+			password: cfg.SMTPPassword,
+			from:     cfg.SMTPFrom,
+		})
+	}
+	return channels
+}
+
+// Channels returns the names of the configured channels.
+func (s *NotificationService) Channels() []string {
+	channels := s.current()
+	names := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		names = append(names, ch.Name())
+	}
+	return names
+}
+
+// Send delivers n on every channel and returns the channels that accepted it.
+// A failing channel does not stop delivery on the others.
+func (s *NotificationService) Send(ctx context.Context, n Notification) ([]string, error) {
+	var delivered, failed []string
+	for _, ch := range s.current() {
+		if err := ch.Send(ctx, n); err != nil {
+			log.Printf("Notification via %s failed: %v", ch.Name(), err)
+			failed = append(failed, ch.Name())
+			continue
+		}
+		delivered = append(delivered, ch.Name())
+	}
+	if len(failed) > 0 {
+		return delivered, fmt.Errorf("delivery failed on %s", strings.Join(failed, ", "))
+	}
+	return delivered, nil
+}
+
+// SendEmail delivers n on the email channel only. It is used for messages
+// addressed to one person, such as ones carrying personal action links,
+// which must not be broadcast to chat webhooks.
+func (s *NotificationService) SendEmail(ctx context.Context, n Notification) error {
+	for _, ch := range s.current() {
+		if ch.Name() == "email" {
+			return ch.Send(ctx, n)
+		}
+	}
+	return ErrEmailNotConfigured
+}
+
+// SendTo delivers n to the given webhooks and, by email, to its recipients,
+// instead of the configured webhooks. It is used for team channels chosen
+// per message, such as monitoring routes.
+func (s *NotificationService) SendTo(ctx context.Context, n Notification, webhookURLs []string) ([]string, error) {
+	var channels []NotificationChannel
+	for _, url := range webhookURLs {
+		channels = append(channels, &webhookChannel{url: url, client: &http.Client{Timeout: 10 * time.Second}})
+	}
+	if len(n.Recipients) > 0 {
+		for _, ch := range s.current() {
+			if ch.Name() == "email" {
+				channels = append(channels, ch)
+			}
+		}
+	}
+	return (&NotificationService{channels: channels}).Send(ctx, n)
+}
+
+// webhookChannel posts Slack-compatible {"text": ...} payloads, which Slack,
+// Mattermost, Teams workflows and most chat tools accept.
+type webhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+func (w *webhookChannel) Name() string {
+	return "webhook"
+}
+
+func (w *webhookChannel) Send(ctx context.Context, n Notification) error {
+	payload, err := json.Marshal(map[string]string{"text": fmt.Sprintf("*%s*\n%s", n.Subject, n.Body)})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", w.url, bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type emailChannel struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func (e *emailChannel) Name() string {
+	return "email"
+}
+
+func (e *emailChannel) Send(_ context.Context, n Notification) error {
+	if len(n.Recipients) == 0 {
+		return nil
+	}
+	var auth smtp.Auth
+	if e.username != "" {
+		auth = smtp.PlainAuth("", e.username, e.password, e.host)
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		e.from, strings.Join(n.Recipients, ", "), n.Subject, n.Body)
+	return smtp.SendMail(e.addr, auth, e.from, n.Recipients, []byte(msg))
+}
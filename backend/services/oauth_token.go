@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// googleServiceAccount gets OAuth access tokens for a Google service
+// account with the JWT bearer grant, and caches them until shortly before
+// they expire.
+type googleServiceAccount struct {
+	projectID   string
+	clientEmail string
+	tokenURI    string
+	scope       string
+	key         *rsa.PrivateKey
+	client      *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// newGoogleServiceAccount reads a service account JSON key file.
+func newGoogleServiceAccount(credentialsFile, scope string, client *http.Client) (*googleServiceAccount, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	var creds struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("invalid service account file: %v", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(creds.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account key: %v", err)
+	}
+	if creds.TokenURI == "" {
+		creds.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &googleServiceAccount{
+		projectID:   creds.ProjectID,
+		clientEmail: creds.ClientEmail,
+		tokenURI:    creds.TokenURI,
+		scope:       scope,
+		key:         key,
+		client:      client,
+	}, nil
+}
+
+func (g *googleServiceAccount) accessToken(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token != "" && time.Now().Before(g.expires) {
+		return g.token, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   g.clientEmail,
+		"scope": g.scope,
+		"aud":   g.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(g.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	g.token, g.expires, err = requestAccessToken(ctx, g.client, g.tokenURI, form)
+	return g.token, err
+}
+
+// graphCredentials gets app-only Microsoft Graph tokens with the client
+// credentials grant, and caches them until shortly before they expire.
+type graphCredentials struct {
+	tenantID     string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (g *graphCredentials) accessToken(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token != "" && time.Now().Before(g.expires) {
+		return g.token, nil
+	}
+
+	form := url.Values{
+		"client_id":     {g.clientID},
+		"client_secret": {g.clientSecret},
+		"scope":         {"https://graph.microsoft.com/.default"},
+		"grant_type":    {"client_credentials"},
+	}
+	endpoint := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", g.tenantID)
+	var err error
+	g.token, g.expires, err = requestAccessToken(ctx, g.client, endpoint, form)
+	return g.token, err
+}
+
+// requestAccessToken posts an OAuth token request and returns the access
+// token with the time to renew it at.
+func requestAccessToken(ctx context.Context, client *http.Client, endpoint string, form url.Values) (string, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", time.Time{}, fmt.Errorf("token request returned %d", resp.StatusCode)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", time.Time{}, err
+	}
+	return token.AccessToken, time.Now().Add(time.Duration(token.ExpiresIn-60) * time.Second), nil
+}
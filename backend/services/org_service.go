@@ -0,0 +1,500 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// ErrOrgUnitHasChildren is returned when deleting a unit that still has
+// sub-units.
+var ErrOrgUnitHasChildren = errors.New("move or delete the unit's sub-units first")
+
+// OrgService keeps the organisation hierarchy of departments and the
+// routes that send tickets to support teams by department.
+type OrgService struct {
+	db *database.MongoDB
+}
+
+func NewOrgService(db *database.MongoDB) *OrgService {
+	return &OrgService{db: db}
+}
+
+func (s *OrgService) units() *mongo.Collection {
+	return s.db.GetCollection("org_units")
+}
+
+func (s *OrgService) routes() *mongo.Collection {
+	return s.db.GetCollection("ticket_routes")
+}
+
+// ListUnits returns every org unit by name.
+func (s *OrgService) ListUnits(ctx context.Context) ([]models.OrgUnit, error) {
+	cursor, err := s.units().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	units := []models.OrgUnit{}
+	if err := cursor.All(ctx, &units); err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// Tree returns the org units as a hierarchy of top-level units.
+func (s *OrgService) Tree(ctx context.Context) ([]models.OrgUnitNode, error) {
+	units, err := s.ListUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	children := map[primitive.ObjectID][]models.OrgUnit{}
+	var roots []models.OrgUnit
+	for _, u := range units {
+		if u.ParentID == nil {
+			roots = append(roots, u)
+		} else {
+			children[*u.ParentID] = append(children[*u.ParentID], u)
+		}
+	}
+	var build func(units []models.OrgUnit) []models.OrgUnitNode
+	build = func(units []models.OrgUnit) []models.OrgUnitNode {
+		nodes := []models.OrgUnitNode{}
+		for _, u := range units {
+			nodes = append(nodes, models.OrgUnitNode{OrgUnit: u, Children: build(children[u.ID])})
+		}
+		return nodes
+	}
+	return build(roots), nil
+}
+
+// ValidateOrgUnit checks a unit's name is unique and that its parent exists
+// without making the unit its own ancestor. id is nil for new units.
+func (s *OrgService) ValidateOrgUnit(ctx context.Context, id *primitive.ObjectID, req models.OrgUnitRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.EqualFold(name, models.UnknownLocation) {
+		return fmt.Errorf("%q is reserved", models.UnknownLocation)
+	}
+	units, err := s.ListUnits(ctx)
+	if err != nil {
+		return err
+	}
+	byID := map[primitive.ObjectID]models.OrgUnit{}
+	for _, u := range units {
+		byID[u.ID] = u
+		if strings.EqualFold(u.Name, name) && (id == nil || u.ID != *id) {
+			return fmt.Errorf("a unit named %q already exists", u.Name)
+		}
+	}
+	if req.ParentID != nil {
+		for parentID := req.ParentID; parentID != nil; {
+			if id != nil && *parentID == *id {
+				return fmt.Errorf("parentId would make the unit its own ancestor")
+			}
+			parent, ok := byID[*parentID]
+			if !ok {
+				return fmt.Errorf("parentId: unit not found")
+			}
+			parentID = parent.ParentID
+		}
+	}
+	if req.ManagerID != nil {
+		count, err := s.db.GetCollection("users").CountDocuments(ctx, bson.M{"_id": *req.ManagerID})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("managerId: user not found")
+		}
+	}
+	return nil
+}
+
+func (s *OrgService) CreateUnit(ctx context.Context, req models.OrgUnitRequest) (models.OrgUnit, error) {
+	unit := models.OrgUnit{
+		ID:        primitive.NewObjectID(),
+		Name:      strings.TrimSpace(req.Name),
+		ParentID:  req.ParentID,
+		ManagerID: req.ManagerID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if _, err := s.units().InsertOne(ctx, unit); err != nil {
+		return models.OrgUnit{}, err
+	}
+	return unit, nil
+}
+
+// UpdateUnit replaces a unit's fields. Renaming a unit renames the
+// department of its users and its ticket routes; tickets keep the name
+// they were raised under.
+func (s *OrgService) UpdateUnit(ctx context.Context, id primitive.ObjectID, req models.OrgUnitRequest) (models.OrgUnit, error) {
+	var before models.OrgUnit
+	if err := s.units().FindOne(ctx, bson.M{"_id": id}).Decode(&before); err != nil {
+		return models.OrgUnit{}, err
+	}
+	var unit models.OrgUnit
+	err := s.units().FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"name":      strings.TrimSpace(req.Name),
+		"parentId":  req.ParentID,
+		"managerId": req.ManagerID,
+		"updatedAt": time.Now(),
+	}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&unit)
+	if err != nil {
+		return models.OrgUnit{}, err
+	}
+	if unit.Name != before.Name {
+		if _, err := s.db.GetCollection("users").UpdateMany(ctx, bson.M{"department": before.Name},
+			bson.M{"$set": bson.M{"department": unit.Name, "updatedAt": time.Now()}}); err != nil {
+			return unit, err
+		}
+		if _, err := s.routes().UpdateMany(ctx, bson.M{"departments": before.Name},
+			bson.M{"$set": bson.M{"departments.$": unit.Name}}); err != nil {
+			return unit, err
+		}
+	}
+	return unit, nil
+}
+
+// DeleteUnit removes a unit without sub-units. Its users keep the
+// department name, which is then reported at the top level.
+func (s *OrgService) DeleteUnit(ctx context.Context, id primitive.ObjectID) error {
+	count, err := s.units().CountDocuments(ctx, bson.M{"parentId": id})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrOrgUnitHasChildren
+	}
+	result, err := s.units().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// orgIndex looks up units by name, case-insensitively.
+type orgIndex struct {
+	byName   map[string]models.OrgUnit
+	byID     map[primitive.ObjectID]models.OrgUnit
+	children map[primitive.ObjectID][]models.OrgUnit
+}
+
+func newOrgIndex(units []models.OrgUnit) orgIndex {
+	idx := orgIndex{
+		byName:   map[string]models.OrgUnit{},
+		byID:     map[primitive.ObjectID]models.OrgUnit{},
+		children: map[primitive.ObjectID][]models.OrgUnit{},
+	}
+	for _, u := range units {
+		idx.byName[strings.ToLower(u.Name)] = u
+		idx.byID[u.ID] = u
+		if u.ParentID != nil {
+			idx.children[*u.ParentID] = append(idx.children[*u.ParentID], u)
+		}
+	}
+	return idx
+}
+
+// ancestors returns department and the names of the units above it,
+// nearest first.
+func (idx orgIndex) ancestors(department string) []string {
+	names := []string{department}
+	unit, ok := idx.byName[strings.ToLower(department)]
+	for ok && unit.ParentID != nil {
+		unit, ok = idx.byID[*unit.ParentID]
+		if ok {
+			names = append(names, unit.Name)
+		}
+	}
+	return names
+}
+
+// subtree returns department and the names of every unit below it.
+func (idx orgIndex) subtree(department string) []string {
+	names := []string{department}
+	unit, ok := idx.byName[strings.ToLower(department)]
+	if !ok {
+		return names
+	}
+	queue := idx.children[unit.ID]
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		names = append(names, u.Name)
+		queue = append(queue, idx.children[u.ID]...)
+	}
+	return names
+}
+
+func (s *OrgService) index(ctx context.Context) (orgIndex, error) {
+	units, err := s.ListUnits(ctx)
+	if err != nil {
+		return orgIndex{}, err
+	}
+	return newOrgIndex(units), nil
+}
+
+// Subtree returns the department and every department below it, for
+// filters that roll up sub-units.
+func (s *OrgService) Subtree(ctx context.Context, department string) ([]string, error) {
+	idx, err := s.index(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return idx.subtree(department), nil
+}
+
+// ValidateTicketRoute checks a route names existing departments and an
+// admin or technician assignee.
+func (s *OrgService) ValidateTicketRoute(ctx context.Context, req models.TicketRouteRequest) error {
+	if strings.TrimSpace(req.Team) == "" {
+		return fmt.Errorf("team is required")
+	}
+	if len(req.Departments) == 0 {
+		return fmt.Errorf("departments is required")
+	}
+	idx, err := s.index(ctx)
+	if err != nil {
+		return err
+	}
+	for _, d := range req.Departments {
+		if _, ok := idx.byName[strings.ToLower(d)]; !ok {
+			return fmt.Errorf("departments: unknown org unit %q", d)
+		}
+	}
+	if req.AssignTo != nil {
+		var user models.User
+		err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": *req.AssignTo}).Decode(&user)
+		if err != nil || (user.Role != models.RoleAdmin && user.Role != models.RoleTechnician) {
+			return fmt.Errorf("assignTo must be an admin or technician")
+		}
+	}
+	return nil
+}
+
+func (s *OrgService) CreateRoute(ctx context.Context, req models.TicketRouteRequest, createdBy primitive.ObjectID) (models.TicketRoute, error) {
+	route := models.TicketRoute{
+		ID:          primitive.NewObjectID(),
+		Name:        req.Name,
+		Order:       req.Order,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		Departments: req.Departments,
+		Categories:  req.Categories,
+		Team:        strings.TrimSpace(req.Team),
+		AssignTo:    req.AssignTo,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if _, err := s.routes().InsertOne(ctx, route); err != nil {
+		return models.TicketRoute{}, err
+	}
+	return route, nil
+}
+
+// ListRoutes returns ticket routes in evaluation order.
+func (s *OrgService) ListRoutes(ctx context.Context) ([]models.TicketRoute, error) {
+	cursor, err := s.routes().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "order", Value: 1}, {Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	routes := []models.TicketRoute{}
+	if err := cursor.All(ctx, &routes); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+func (s *OrgService) UpdateRoute(ctx context.Context, id primitive.ObjectID, req models.TicketRouteRequest) (models.TicketRoute, error) {
+	set := bson.M{
+		"name":        req.Name,
+		"order":       req.Order,
+		"departments": req.Departments,
+		"categories":  req.Categories,
+		"team":        strings.TrimSpace(req.Team),
+		"assignTo":    req.AssignTo,
+		"updatedAt":   time.Now(),
+	}
+	if req.Enabled != nil {
+		set["enabled"] = *req.Enabled
+	}
+	var route models.TicketRoute
+	err := s.routes().FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&route)
+	return route, err
+}
+
+func (s *OrgService) DeleteRoute(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.routes().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// MatchRoute returns the first enabled route for a ticket from department
+// in category, or nil.
+func (s *OrgService) MatchRoute(ctx context.Context, department string, category models.TicketCategory) (*models.TicketRoute, error) {
+	if department == "" {
+		return nil, nil
+	}
+	router, err := s.Router(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return router.match(department, category), nil
+}
+
+// TicketRouter routes tickets against one read of the routes and the
+// hierarchy, so bulk imports do not read them per ticket.
+type TicketRouter struct {
+	routes []models.TicketRoute
+	idx    orgIndex
+}
+
+func (s *OrgService) Router(ctx context.Context) (*TicketRouter, error) {
+	routes, err := s.ListRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := s.index(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &TicketRouter{routes: routes, idx: idx}, nil
+}
+
+func (r *TicketRouter) match(department string, category models.TicketCategory) *models.TicketRoute {
+	if department == "" {
+		return nil
+	}
+	ancestors := r.idx.ancestors(department)
+	for i, route := range r.routes {
+		if !route.Enabled {
+			continue
+		}
+		if len(route.Categories) > 0 && !containsCategory(route.Categories, category) {
+			continue
+		}
+		for _, d := range route.Departments {
+			if containsFold(ancestors, d) {
+				return &r.routes[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Route fills in the ticket's department and location from its requester
+// and, when a ticket route matches, its team and assignee.
+func (r *TicketRouter) Route(ticket *models.Ticket, requester models.User) {
+	if ticket.Department == "" {
+		ticket.Department = requester.Department
+	}
+	if ticket.Location == "" {
+		ticket.Location = requester.Location
+	}
+	route := r.match(ticket.Department, ticket.Category)
+	if route == nil {
+		return
+	}
+	ticket.Team = route.Team
+	if ticket.AssignedTo == nil && route.AssignTo != nil {
+		assignee := *route.AssignTo
+		ticket.AssignedTo = &assignee
+	}
+}
+
+func containsCategory(categories []models.TicketCategory, category models.TicketCategory) bool {
+	for _, c := range categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteTicket fills in a new ticket's department and location from its
+// requester and, when a ticket route matches, its team and assignee. It is
+// called before the ticket is stored; a routing failure leaves the ticket
+// unrouted.
+func (s *OrgService) RouteTicket(ctx context.Context, ticket *models.Ticket, requester models.User) error {
+	if ticket.Department == "" {
+		ticket.Department = requester.Department
+	}
+	if ticket.Location == "" {
+		ticket.Location = requester.Location
+	}
+	if ticket.Department == "" {
+		return nil
+	}
+	router, err := s.Router(ctx)
+	if err != nil {
+		return err
+	}
+	router.Route(ticket, requester)
+	return nil
+}
+
+// departmentRollups counts tickets per department up the hierarchy.
+// counts are the direct counts by department name.
+func departmentRollups(units []models.OrgUnit, counts map[string]*models.DepartmentRollup) []models.DepartmentRollup {
+	idx := newOrgIndex(units)
+	rollups := []models.DepartmentRollup{}
+
+	var walk func(unit models.OrgUnit, parent string, depth int) models.DepartmentRollup
+	walk = func(unit models.OrgUnit, parent string, depth int) models.DepartmentRollup {
+		rollup := models.DepartmentRollup{Department: unit.Name, Parent: parent, Depth: depth}
+		if direct, ok := counts[strings.ToLower(unit.Name)]; ok {
+			rollup.Direct = direct.Direct
+			rollup.Total, rollup.Open, rollup.Critical = direct.Direct, direct.Open, direct.Critical
+			delete(counts, strings.ToLower(unit.Name))
+		}
+		at := len(rollups)
+		rollups = append(rollups, rollup)
+		children := idx.children[unit.ID]
+		sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+		for _, child := range children {
+			sub := walk(child, unit.Name, depth+1)
+			rollup.Total += sub.Total
+			rollup.Open += sub.Open
+			rollup.Critical += sub.Critical
+		}
+		rollups[at] = rollup
+		return rollup
+	}
+	for _, unit := range units {
+		if unit.ParentID == nil {
+			walk(unit, "", 0)
+		}
+	}
+
+	// Departments that are not org units
+	var rest []models.DepartmentRollup
+	for _, r := range counts {
+		r.Total = r.Direct
+		rest = append(rest, *r)
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].Department < rest[j].Department })
+	return append(rollups, rest...)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"intelliops-ai-copilot/config"
+)
+
+// Password hashing algorithms.
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var ErrPasswordHashFormat = errors.New("unrecognized password hash format")
+
+// PasswordService hashes passwords with the configured algorithm and
+// verifies both bcrypt and Argon2id hashes, reporting when a stored hash
+// should be replaced so users migrate as they log in.
+type PasswordService struct {
+	algorithm   string
+	bcryptCost  int
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+func NewPasswordService(cfg *config.Config) (*PasswordService, error) {
+	s := &PasswordService{
+		algorithm:   strings.ToLower(cfg.PasswordHashAlgorithm),
+		bcryptCost:  cfg.BcryptCost,
+		memory:      uint32(cfg.Argon2Memory),
+		iterations:  uint32(cfg.Argon2Iterations),
+		parallelism: uint8(cfg.Argon2Parallelism),
+	}
+	switch s.algorithm {
+	case PasswordAlgorithmBcrypt:
+		if s.bcryptCost < bcrypt.MinCost || s.bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case PasswordAlgorithmArgon2id:
+		if cfg.Argon2Memory < 8*cfg.Argon2Parallelism || cfg.Argon2Iterations < 1 || cfg.Argon2Parallelism < 1 || cfg.Argon2Parallelism > 255 {
+			return nil, fmt.Errorf("invalid argon2id parameters")
+		}
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q", cfg.PasswordHashAlgorithm)
+	}
+	return s, nil
+}
+
+// Hash returns an encoded hash of password using the configured algorithm.
+// Argon2id hashes use the PHC string format.
+func (s *PasswordService) Hash(password string) (string, error) {
+	if s.algorithm == PasswordAlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), s.bcryptCost)
+		return string(hash), err
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, s.iterations, s.memory, s.parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, s.memory, s.iterations, s.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify reports whether password matches hash, and whether the hash was
+// made with a different algorithm or parameters than currently configured.
+func (s *PasswordService) Verify(hash, password string) (ok, needsRehash bool, err error) {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		return s.verifyArgon2id(hash, password)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return true, true, nil
+	}
+	return true, s.algorithm != PasswordAlgorithmBcrypt || cost != s.bcryptCost, nil
+}
+
+func (s *PasswordService) verifyArgon2id(hash, password string) (bool, bool, error) {
+	// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false, ErrPasswordHashFormat
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, ErrPasswordHashFormat
+	}
+	var memory, iterations uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return false, false, ErrPasswordHashFormat
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrPasswordHashFormat
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, ErrPasswordHashFormat
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(candidate, key) != 1 {
+		return false, false, nil
+	}
+	needsRehash := s.algorithm != PasswordAlgorithmArgon2id ||
+		memory != s.memory || iterations != s.iterations || parallelism != s.parallelism || len(key) != argon2KeyLength
+	return true, needsRehash, nil
+}
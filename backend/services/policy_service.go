@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// builtinPolicyRules reproduce the role checks the routes had before
+// policies: admins use everything, technicians the staff API, and
+// requesters the portal plus the routes every signed-in user shares.
+var builtinPolicyRules = []models.PolicyRule{
+	{ID: "admin-all", Description: "Admins can use every route", Subject: "role:admin", Resource: "*", Action: "*", Effect: models.PolicyAllow},
+	{ID: "technician-api", Description: "Technicians can use the staff API", Subject: "role:technician", Resource: "/api/*", Action: "*", Effect: models.PolicyAllow},
+	{ID: "technician-no-admin", Description: "The admin API is for admins only", Subject: "role:technician", Resource: "/api/admin/*", Action: "*", Effect: models.PolicyDeny},
+	{ID: "technician-no-triage-sandbox", Description: "The triage sandbox is for admins only", Subject: "role:technician", Resource: "/api/ai/triage/sandbox", Action: "*", Effect: models.PolicyDeny},
+	{ID: "technician-no-portal", Description: "The portal API is for requesters", Subject: "role:technician", Resource: "/api/portal/*", Action: "*", Effect: models.PolicyDeny},
+	{ID: "requester-portal", Description: "Requesters use the portal API", Subject: "role:requester", Resource: "/api/portal/*", Action: "*", Effect: models.PolicyAllow},
+	{ID: "requester-ticket-comments", Description: "Requesters discuss their own tickets", Subject: "role:requester", Resource: "/api/tickets/:id/comments", Action: "*", Effect: models.PolicyAllow},
+	{ID: "requester-ticket-attachments", Description: "Requesters share files on their own tickets", Subject: "role:requester", Resource: "/api/tickets/:id/attachments*", Action: "*", Effect: models.PolicyAllow},
+	{ID: "everyone-auth", Description: "Profile and feature flags of the signed-in user", Subject: "*", Resource: "/api/auth/*", Action: "*", Effect: models.PolicyAllow},
+	{ID: "everyone-taxonomy", Description: "Categories and priorities", Subject: "*", Resource: "/api/taxonomy", Action: "GET", Effect: models.PolicyAllow},
+	{ID: "everyone-devices", Description: "Mobile push device registration", Subject: "*", Resource: "/api/devices*", Action: "*", Effect: models.PolicyAllow},
+	{ID: "everyone-catalog", Description: "Requesting service catalog items", Subject: "*", Resource: "/api/catalog*", Action: "*", Effect: models.PolicyAllow},
+	{ID: "everyone-approvals", Description: "Deciding catalog approvals", Subject: "*", Resource: "/api/approvals*", Action: "*", Effect: models.PolicyAllow},
+}
+
+// policyAdminResource is always open to admins, so no rule change can lock
+// them out of fixing the rules.
+const policyAdminResource = "/api/admin/policies*"
+
+var policyRuleID = regexp.MustCompile(`^[a-z][a-z0-9-]{1,63}$`)
+
+var policyActions = map[string]bool{"*": true, "GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+
+// policyTTL is how long rules are cached before being read again, so
+// changes made on another replica apply within it.
+const policyTTL = 30 * time.Second
+
+// PolicyService stores the authorization rules and decides requests
+// against them.
+type PolicyService struct {
+	db *database.MongoDB
+
+	mu       sync.RWMutex
+	rules    map[string]models.PolicyRule
+	loadedAt time.Time
+}
+
+func NewPolicyService(db *database.MongoDB) *PolicyService {
+	return &PolicyService{db: db}
+}
+
+func (s *PolicyService) collection() *mongo.Collection {
+	return s.db.GetCollection("policy_rules")
+}
+
+// ValidatePolicyRule checks a rule update and returns the rule it sets.
+func ValidatePolicyRule(id string, req models.PolicyRuleRequest) (models.PolicyRule, error) {
+	if !policyRuleID.MatchString(id) {
+		return models.PolicyRule{}, errors.New("id must be 2-64 lowercase letters, digits or dashes, starting with a letter")
+	}
+	rule := models.PolicyRule{
+		ID:          id,
+		Description: req.Description,
+		Subject:     req.Subject,
+		Resource:    req.Resource,
+		Action:      strings.ToUpper(req.Action),
+		Effect:      req.Effect,
+		Disabled:    req.Disabled,
+	}
+
+	switch kind, value, _ := strings.Cut(rule.Subject, ":"); {
+	case rule.Subject == "*":
+	case kind == "role":
+		if !models.ValidRole(models.UserRole(value)) {
+			return models.PolicyRule{}, fmt.Errorf("unknown role %q", value)
+		}
+	case kind == "user":
+		if _, err := primitive.ObjectIDFromHex(value); err != nil {
+			return models.PolicyRule{}, fmt.Errorf("invalid user ID %q", value)
+		}
+	case kind == "department":
+		department := policyDepartment(value)
+		if department == "" {
+			return models.PolicyRule{}, errors.New("department subject needs a department name")
+		}
+		rule.Subject = "department:" + department
+	default:
+		return models.PolicyRule{}, errors.New("subject must be role:<role>, user:<user ID>, department:<name> or *")
+	}
+	if rule.Resource != "*" && !strings.HasPrefix(rule.Resource, "/") {
+		return models.PolicyRule{}, errors.New("resource must be a route path starting with / or *")
+	}
+	if !policyActions[rule.Action] {
+		return models.PolicyRule{}, errors.New("action must be an HTTP method or *")
+	}
+	if rule.Effect != models.PolicyAllow && rule.Effect != models.PolicyDeny {
+		return models.PolicyRule{}, fmt.Errorf("effect must be %s or %s", models.PolicyAllow, models.PolicyDeny)
+	}
+	return rule, nil
+}
+
+// Save creates or replaces a rule.
+func (s *PolicyService) Save(ctx context.Context, rule models.PolicyRule, updatedBy primitive.ObjectID) (models.PolicyRule, error) {
+	rule.UpdatedAt = time.Now()
+	rule.UpdatedBy = &updatedBy
+	_, err := s.collection().ReplaceOne(ctx, bson.M{"_id": rule.ID}, rule, options.Replace().SetUpsert(true))
+	if err != nil {
+		return models.PolicyRule{}, err
+	}
+	s.invalidate()
+	log.Printf("Policy rule %s saved: %s %s %s %s", rule.ID, rule.Effect, rule.Subject, rule.Action, rule.Resource)
+	return rule, nil
+}
+
+// Delete removes a stored rule; built-in rules return to their default.
+func (s *PolicyService) Delete(ctx context.Context, id string) error {
+	res, err := s.collection().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	s.invalidate()
+	return nil
+}
+
+// List returns the stored rules and the built-in rules not stored yet, by
+// id.
+func (s *PolicyService) List(ctx context.Context) ([]models.PolicyRule, error) {
+	rules, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]models.PolicyRule, 0, len(rules))
+	for _, rule := range rules {
+		list = append(list, rule)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+// Authorize decides whether user may perform action (an HTTP method) on
+// resource (a route path). Deny rules win over allow rules, and requests
+// no rule allows are denied.
+func (s *PolicyService) Authorize(ctx context.Context, user models.User, resource, action string) models.PolicyDecision {
+	rules, err := s.load(ctx)
+	if err != nil {
+		log.Printf("Failed to load policy rules: %v", err)
+	}
+
+	decision := models.PolicyDecision{Matched: []models.PolicyRule{}}
+	subjects := policySubjects(user)
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		rule := rules[id]
+		if !rule.Disabled && policyRuleMatches(rule, subjects, resource, action) {
+			decision.Matched = append(decision.Matched, rule)
+		}
+	}
+
+	if user.Role == models.RoleAdmin && policyGlobMatch(policyAdminResource, resource) {
+		decision.Allowed = true
+		decision.Reason = "admins can always manage policy rules"
+		return decision
+	}
+	for _, effect := range []models.PolicyEffect{models.PolicyDeny, models.PolicyAllow} {
+		for i := range decision.Matched {
+			if rule := decision.Matched[i]; rule.Effect == effect {
+				decision.Allowed = effect == models.PolicyAllow
+				decision.Reason = "denied by rule " + rule.ID
+				if decision.Allowed {
+					decision.Reason = "allowed by rule " + rule.ID
+				}
+				decision.Rule = &rule
+				return decision
+			}
+		}
+	}
+	decision.Reason = "no rule allows " + action + " " + resource + " for " + strings.Join(subjects, ", ")
+	return decision
+}
+
+// Simulate decides a request as Authorize would for the user in req, or a
+// user with the role in req, without making it. route is the registered
+// route the request path resolves to.
+func (s *PolicyService) Simulate(ctx context.Context, req models.PolicySimulationRequest, route string) (models.PolicySimulation, error) {
+	var user models.User
+	switch {
+	case req.UserID != "":
+		id, err := primitive.ObjectIDFromHex(req.UserID)
+		if err != nil {
+			return models.PolicySimulation{}, errors.New("invalid user ID")
+		}
+		if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": id}).Decode(&user); err != nil {
+			return models.PolicySimulation{}, err
+		}
+	case models.ValidRole(req.Role):
+		user.Role = req.Role
+	default:
+		return models.PolicySimulation{}, errors.New("userId or a valid role is required")
+	}
+
+	return models.PolicySimulation{
+		Route:    route,
+		Subjects: policySubjects(user),
+		Decision: s.Authorize(ctx, user, route, strings.ToUpper(req.Method)),
+	}, nil
+}
+
+// policySubjects are the subjects a rule can name to match user.
+func policySubjects(user models.User) []string {
+	subjects := []string{"*", "role:" + string(user.Role)}
+	if !user.ID.IsZero() {
+		subjects = append(subjects, "user:"+user.ID.Hex())
+	}
+	if department := policyDepartment(user.Department); department != "" {
+		subjects = append(subjects, "department:"+department)
+	}
+	return subjects
+}
+
+// policyDepartment normalizes a department name for department subjects,
+// which match regardless of case and spacing.
+func policyDepartment(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func policyRuleMatches(rule models.PolicyRule, subjects []string, resource, action string) bool {
+	if rule.Action != "*" && rule.Action != action {
+		return false
+	}
+	if !policyGlobMatch(rule.Resource, resource) {
+		return false
+	}
+	for _, subject := range subjects {
+		if rule.Subject == subject {
+			return true
+		}
+	}
+	return false
+}
+
+// policyGlobMatch matches s against pattern, where * matches any
+// characters, slashes included.
+func policyGlobMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// load returns the cached rules, reading them again once the cache is older
+// than policyTTL. On a read error the previous rules, or the built-in
+// defaults, are returned with the error.
+func (s *PolicyService) load(ctx context.Context) (map[string]models.PolicyRule, error) {
+	s.mu.RLock()
+	rules, loadedAt := s.rules, s.loadedAt
+	s.mu.RUnlock()
+	if rules != nil && time.Since(loadedAt) < policyTTL {
+		return rules, nil
+	}
+
+	loaded := map[string]models.PolicyRule{}
+	for _, rule := range builtinPolicyRules {
+		rule.Builtin = true
+		loaded[rule.ID] = rule
+	}
+	cursor, err := s.collection().Find(ctx, bson.M{})
+	if err == nil {
+		var stored []models.PolicyRule
+		if err = cursor.All(ctx, &stored); err == nil {
+			for _, rule := range stored {
+				loaded[rule.ID] = rule
+			}
+		}
+	}
+	if err != nil {
+		if rules != nil {
+			return rules, err
+		}
+		return loaded, err
+	}
+
+	s.mu.Lock()
+	s.rules, s.loadedAt = loaded, time.Now()
+	s.mu.Unlock()
+	return loaded, nil
+}
+
+func (s *PolicyService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
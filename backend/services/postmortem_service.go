@@ -0,0 +1,572 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const postmortemSystemPrompt = "You are an SRE writing blameless incident postmortems. Always respond with valid JSON." + untrustedContentPolicy
+
+// timelineSectionKey is filled from the incident timeline rather than drafted.
+const timelineSectionKey = "timeline"
+
+// DefaultPostmortemTemplate is used until an admin marks a stored template
+// as the default.
+func DefaultPostmortemTemplate() models.PostmortemTemplate {
+	return models.PostmortemTemplate{
+		Name: "Standard",
+		Sections: []models.PostmortemSection{
+			{Key: "summary", Title: "Summary", Guidance: "2-4 sentences on what happened"},
+			{Key: "impact", Title: "Impact", Guidance: "who and what was affected and for how long"},
+			{Key: timelineSectionKey, Title: "Timeline"},
+			{Key: "root_cause", Title: "Root cause", Guidance: "the most likely root cause based on the evidence, or what remains unknown"},
+			{Key: "resolution", Title: "Resolution", Guidance: "what resolved the incident"},
+			{Key: "lessons", Title: "Lessons learned", Guidance: "what went well and what did not"},
+		},
+		IsDefault: true,
+	}
+}
+
+type PostmortemService struct {
+	db            *database.MongoDB
+	llm           *LLMService
+	guardrails    *GuardrailService
+	taxonomy      *TaxonomyService
+	notifications *NotificationService
+}
+
+func NewPostmortemService(db *database.MongoDB, llm *LLMService, guardrails *GuardrailService, taxonomy *TaxonomyService, notifications *NotificationService) *PostmortemService {
+	return &PostmortemService{db: db, llm: llm, guardrails: guardrails, taxonomy: taxonomy, notifications: notifications}
+}
+
+// ValidatePostmortemTemplate checks section keys are present and unique.
+func ValidatePostmortemTemplate(sections []models.PostmortemSection) error {
+	keys := map[string]bool{}
+	for _, section := range sections {
+		key := strings.TrimSpace(section.Key)
+		if key == "" {
+			return fmt.Errorf("section key is required")
+		}
+		if keys[key] {
+			return fmt.Errorf("duplicate section key: %s", key)
+		}
+		keys[key] = true
+	}
+	return nil
+}
+
+func (s *PostmortemService) ListTemplates(ctx context.Context) ([]models.PostmortemTemplate, error) {
+	cursor, err := s.db.GetCollection("postmortem_templates").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{"name", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	templates := []models.PostmortemTemplate{}
+	if err := cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (s *PostmortemService) CreateTemplate(ctx context.Context, req models.PostmortemTemplateRequest) (models.PostmortemTemplate, error) {
+	template := models.PostmortemTemplate{
+		ID:          primitive.NewObjectID(),
+		Name:        req.Name,
+		Description: req.Description,
+		Sections:    req.Sections,
+		IsDefault:   req.IsDefault,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := s.clearDefault(ctx, template.ID, req.IsDefault); err != nil {
+		return models.PostmortemTemplate{}, err
+	}
+	_, err := s.db.GetCollection("postmortem_templates").InsertOne(ctx, template)
+	return template, err
+}
+
+func (s *PostmortemService) UpdateTemplate(ctx context.Context, id primitive.ObjectID, req models.PostmortemTemplateRequest) (models.PostmortemTemplate, error) {
+	if err := s.clearDefault(ctx, id, req.IsDefault); err != nil {
+		return models.PostmortemTemplate{}, err
+	}
+	var template models.PostmortemTemplate
+	err := s.db.GetCollection("postmortem_templates").FindOneAndUpdate(ctx, bson.M{"_id": id},
+		bson.M{"$set": bson.M{
+			"name":        req.Name,
+			"description": req.Description,
+			"sections":    req.Sections,
+			"isDefault":   req.IsDefault,
+			"updatedAt":   time.Now(),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&template)
+	return template, err
+}
+
+func (s *PostmortemService) DeleteTemplate(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.db.GetCollection("postmortem_templates").DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// clearDefault unmarks every other template when id becomes the default.
+func (s *PostmortemService) clearDefault(ctx context.Context, id primitive.ObjectID, isDefault bool) error {
+	if !isDefault {
+		return nil
+	}
+	_, err := s.db.GetCollection("postmortem_templates").UpdateMany(ctx,
+		bson.M{"isDefault": true, "_id": bson.M{"$ne": id}},
+		bson.M{"$set": bson.M{"isDefault": false, "updatedAt": time.Now()}})
+	return err
+}
+
+// resolveTemplate returns the requested template, the stored default, or
+// the built-in template, in that order.
+func (s *PostmortemService) resolveTemplate(ctx context.Context, id *primitive.ObjectID) (models.PostmortemTemplate, error) {
+	coll := s.db.GetCollection("postmortem_templates")
+	var template models.PostmortemTemplate
+	if id != nil {
+		err := coll.FindOne(ctx, bson.M{"_id": *id}).Decode(&template)
+		return template, err
+	}
+	err := coll.FindOne(ctx, bson.M{"isDefault": true}).Decode(&template)
+	if err == mongo.ErrNoDocuments {
+		return DefaultPostmortemTemplate(), nil
+	}
+	return template, err
+}
+
+// Draft generates the postmortem for an incident from its timeline and
+// linked tickets. An existing postmortem is redrafted in place, keeping its
+// action items.
+func (s *PostmortemService) Draft(ctx context.Context, incidentID primitive.ObjectID, templateID *primitive.ObjectID) (models.Postmortem, error) {
+	var incident models.Incident
+	if err := s.db.GetCollection("incidents").FindOne(ctx, bson.M{"_id": incidentID}).Decode(&incident); err != nil {
+		return models.Postmortem{}, err
+	}
+	template, err := s.resolveTemplate(ctx, templateID)
+	if err != nil {
+		return models.Postmortem{}, err
+	}
+
+	sections, actions, err := s.draftSections(ctx, incident, template)
+	fallback := false
+	if err != nil {
+		if err != ErrLLMUnavailable {
+			log.Printf("Postmortem draft failed for incident %s, using fallback: %v", incidentID.Hex(), err)
+		}
+		sections, actions = mockPostmortem(incident, template)
+		fallback = true
+	}
+
+	now := time.Now()
+	pm := models.Postmortem{
+		ID:               primitive.NewObjectID(),
+		IncidentID:       incident.ID,
+		TemplateName:     template.Name,
+		Title:            "Postmortem: " + incident.Title,
+		Status:           models.PostmortemDraft,
+		Sections:         sections,
+		SuggestedActions: actions,
+		ActionItems:      []models.PostmortemAction{},
+		Fallback:         fallback,
+		GeneratedAt:      now,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if !template.ID.IsZero() {
+		pm.TemplateID = &template.ID
+	}
+
+	coll := s.db.GetCollection("postmortems")
+	if incident.PostmortemID != nil {
+		var existing models.Postmortem
+		err := coll.FindOneAndUpdate(ctx, bson.M{"_id": *incident.PostmortemID},
+			bson.M{"$set": bson.M{
+				"templateId":       pm.TemplateID,
+				"templateName":     pm.TemplateName,
+				"sections":         pm.Sections,
+				"suggestedActions": pm.SuggestedActions,
+				"fallback":         pm.Fallback,
+				"generatedAt":      now,
+				"updatedAt":        now,
+			}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&existing)
+		if err != mongo.ErrNoDocuments {
+			return existing, err
+		}
+	}
+
+	if _, err := coll.InsertOne(ctx, pm); err != nil {
+		return models.Postmortem{}, err
+	}
+	_, err = s.db.GetCollection("incidents").UpdateByID(ctx, incident.ID, bson.M{"$set": bson.M{"postmortemId": pm.ID}})
+	return pm, err
+}
+
+func (s *PostmortemService) draftSections(ctx context.Context, incident models.Incident, template models.PostmortemTemplate) ([]models.PostmortemSection, []string, error) {
+	var b strings.Builder
+	b.WriteString(IncidentTimeline(incident))
+	if len(incident.TicketIDs) > 0 {
+		cursor, err := s.db.GetCollection("tickets").Find(ctx, bson.M{"_id": bson.M{"$in": incident.TicketIDs}})
+		if err != nil {
+			return nil, nil, err
+		}
+		var tickets []models.Ticket
+		err = cursor.All(ctx, &tickets)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, t := range tickets {
+			thread := TicketThread(t)
+			if len(thread) > 2000 {
+				thread = thread[:2000] + "..."
+			}
+			b.WriteString("\nLinked ticket:\n" + thread)
+		}
+	}
+
+	var fields strings.Builder
+	for _, section := range template.Sections {
+		if section.Key == timelineSectionKey {
+			continue
+		}
+		guidance := section.Guidance
+		if guidance == "" {
+			guidance = section.Title
+		}
+		fields.WriteString(fmt.Sprintf("- %s: %s\n", section.Key, guidance))
+	}
+
+	prompt := fmt.Sprintf(`Draft a blameless postmortem for the following resolved incident.
+
+%s
+
+Respond with a JSON object containing:
+- sections: an object with these keys
+%s- actionItems: 2-6 short, concrete follow-up actions`, s.guardrails.Wrap(ctx, "incident", incident.ID.Hex(), "timeline", b.String()), fields.String())
+
+	content, err := s.llm.Complete(ctx, CompletionRequest{
+		System:   postmortemSystemPrompt,
+		Prompt:   prompt,
+		Endpoint: EndpointPostmortem,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var draft struct {
+		Sections    map[string]string `json:"sections"`
+		ActionItems []string          `json:"actionItems"`
+	}
+	if err := json.Unmarshal([]byte(ExtractJSON(content)), &draft); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse postmortem response: %v", err)
+	}
+	if len(draft.Sections) == 0 {
+		return nil, nil, fmt.Errorf("postmortem response missing sections")
+	}
+
+	sections := make([]models.PostmortemSection, 0, len(template.Sections))
+	for _, section := range template.Sections {
+		content := strings.TrimSpace(draft.Sections[section.Key])
+		if section.Key == timelineSectionKey {
+			content = renderTimeline(incident)
+		}
+		sections = append(sections, models.PostmortemSection{Key: section.Key, Title: section.Title, Content: content})
+	}
+	return sections, draft.ActionItems, nil
+}
+
+func renderTimeline(incident models.Incident) string {
+	var b strings.Builder
+	for _, e := range incident.Timeline {
+		b.WriteString(fmt.Sprintf("%s — %s\n", e.CreatedAt.Format("2006-01-02 15:04"), e.Message))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func mockPostmortem(incident models.Incident, template models.PostmortemTemplate) ([]models.PostmortemSection, []string) {
+	duration := "still ongoing"
+	if incident.ResolvedAt != nil {
+		duration = incident.ResolvedAt.Sub(incident.CreatedAt).Round(time.Minute).String()
+	}
+	resolution := "See the incident timeline."
+	if n := len(incident.Updates); n > 0 {
+		resolution = incident.Updates[n-1].Message
+	}
+
+	known := map[string]string{
+		"summary": fmt.Sprintf("\"%s\" was declared a %s-impact incident on %s and lasted %s.",
+			incident.Title, incident.Impact, incident.CreatedAt.Format("2006-01-02 15:04"), duration),
+		"impact": fmt.Sprintf("%d status page component(s) and %d linked ticket(s) were affected.",
+			len(incident.ComponentIDs), len(incident.TicketIDs)),
+		timelineSectionKey: renderTimeline(incident),
+		"root_cause":       "To be determined. Review the timeline and linked tickets.",
+		"resolution":       resolution,
+	}
+
+	sections := make([]models.PostmortemSection, 0, len(template.Sections))
+	for _, section := range template.Sections {
+		content, ok := known[section.Key]
+		if !ok {
+			content = "To be completed in the postmortem review."
+		}
+		sections = append(sections, models.PostmortemSection{Key: section.Key, Title: section.Title, Content: content})
+	}
+	return sections, []string{
+		"Confirm the root cause and document it",
+		"Add monitoring or alerting that would have detected this earlier",
+		"Review the runbook used during the incident",
+	}
+}
+
+func (s *PostmortemService) List(ctx context.Context) ([]models.Postmortem, error) {
+	cursor, err := s.db.GetCollection("postmortems").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{"createdAt", -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	postmortems := []models.Postmortem{}
+	if err := cursor.All(ctx, &postmortems); err != nil {
+		return nil, err
+	}
+	return postmortems, nil
+}
+
+// Get returns a postmortem with action item statuses refreshed from their
+// tickets.
+func (s *PostmortemService) Get(ctx context.Context, id primitive.ObjectID) (models.Postmortem, error) {
+	var pm models.Postmortem
+	if err := s.db.GetCollection("postmortems").FindOne(ctx, bson.M{"_id": id}).Decode(&pm); err != nil {
+		return pm, err
+	}
+	if len(pm.ActionItems) == 0 {
+		return pm, nil
+	}
+
+	ticketIDs := make([]primitive.ObjectID, 0, len(pm.ActionItems))
+	for _, item := range pm.ActionItems {
+		ticketIDs = append(ticketIDs, item.TicketID)
+	}
+	cursor, err := s.db.GetCollection("tickets").Find(ctx, bson.M{"_id": bson.M{"$in": ticketIDs}},
+		options.Find().SetProjection(bson.M{"status": 1}))
+	if err != nil {
+		return pm, err
+	}
+	var tickets []models.Ticket
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return pm, err
+	}
+	status := map[primitive.ObjectID]models.TicketStatus{}
+	for _, t := range tickets {
+		status[t.ID] = t.Status
+	}
+	for i := range pm.ActionItems {
+		if st, ok := status[pm.ActionItems[i].TicketID]; ok {
+			pm.ActionItems[i].Status = st
+		}
+	}
+	return pm, nil
+}
+
+func (s *PostmortemService) Update(ctx context.Context, id primitive.ObjectID, req models.UpdatePostmortemRequest) (models.Postmortem, error) {
+	set := bson.M{"updatedAt": time.Now()}
+	if req.Title != "" {
+		set["title"] = req.Title
+	}
+	if req.Sections != nil {
+		set["sections"] = req.Sections
+	}
+	if req.Status != "" {
+		set["status"] = req.Status
+		if req.Status == models.PostmortemPublished {
+			set["publishedAt"] = time.Now()
+		}
+	}
+	result, err := s.db.GetCollection("postmortems").UpdateByID(ctx, id, bson.M{"$set": set})
+	if err != nil {
+		return models.Postmortem{}, err
+	}
+	if result.MatchedCount == 0 {
+		return models.Postmortem{}, mongo.ErrNoDocuments
+	}
+	return s.Get(ctx, id)
+}
+
+// ValidateActionItem checks the priority against the taxonomy and that the
+// owner is an existing user.
+func (s *PostmortemService) ValidateActionItem(ctx context.Context, req models.CreateActionItemRequest, ownerID primitive.ObjectID) error {
+	if err := s.taxonomy.ValidateTicketFields(ctx, "", "", nil, req.Priority); err != nil {
+		return err
+	}
+	count, err := s.db.GetCollection("users").CountDocuments(ctx, bson.M{"_id": ownerID})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("owner not found")
+	}
+	return nil
+}
+
+// AddActionItem opens a ticket assigned to the owner for a follow-up and
+// tracks it on the postmortem.
+func (s *PostmortemService) AddActionItem(ctx context.Context, id primitive.ObjectID, req models.CreateActionItemRequest, ownerID, createdBy primitive.ObjectID) (models.PostmortemAction, error) {
+	var pm models.Postmortem
+	if err := s.db.GetCollection("postmortems").FindOne(ctx, bson.M{"_id": id}).Decode(&pm); err != nil {
+		return models.PostmortemAction{}, err
+	}
+
+	taxonomy := s.taxonomy.Get(ctx)
+	category := DefaultCategory(taxonomy)
+	var incident models.Incident
+	if err := s.db.GetCollection("incidents").FindOne(ctx, bson.M{"_id": pm.IncidentID}).Decode(&incident); err == nil && len(incident.TicketIDs) > 0 {
+		var linked models.Ticket
+		if err := s.db.GetCollection("tickets").FindOne(ctx, bson.M{"_id": incident.TicketIDs[0]}).Decode(&linked); err == nil {
+			category = linked.Category
+		}
+	}
+	priority := req.Priority
+	if priority == "" {
+		priority = DefaultPriority(taxonomy)
+	}
+
+	description := req.Description
+	if description == "" {
+		description = req.Title
+	}
+	description += fmt.Sprintf("\n\nAction item from %s.", pm.Title)
+
+	now := time.Now()
+	dueAt := req.DueAt
+	ticket := models.Ticket{
+		ID:          primitive.NewObjectID(),
+		Title:       req.Title,
+		Description: description,
+		Category:    category,
+		Priority:    priority,
+		Status:      models.StatusOpen,
+		AssignedTo:  &ownerID,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		DueAt:       &dueAt,
+	}
+	if _, err := s.db.GetCollection("tickets").InsertOne(ctx, ticket); err != nil {
+		return models.PostmortemAction{}, err
+	}
+
+	item := models.PostmortemAction{
+		ID:        primitive.NewObjectID(),
+		Title:     req.Title,
+		TicketID:  ticket.ID,
+		OwnerID:   ownerID,
+		DueAt:     dueAt,
+		Status:    ticket.Status,
+		CreatedAt: now,
+	}
+	_, err := s.db.GetCollection("postmortems").UpdateByID(ctx, id, bson.M{
+		"$push": bson.M{"actionItems": item},
+		"$set":  bson.M{"updatedAt": now},
+	})
+	return item, err
+}
+
+// StartReminders emails owners of open action items that are due within a
+// day or overdue, at most once a day per item.
+func (s *PostmortemService) StartReminders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if err := s.SendReminders(ctx); err != nil {
+					log.Printf("postmortem reminder error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (s *PostmortemService) SendReminders(ctx context.Context) error {
+	now := time.Now()
+	cursor, err := s.db.GetCollection("postmortems").Find(ctx, bson.M{
+		"actionItems.dueAt": bson.M{"$lte": now.Add(24 * time.Hour)},
+	})
+	if err != nil {
+		return err
+	}
+	var postmortems []models.Postmortem
+	if err := cursor.All(ctx, &postmortems); err != nil {
+		return err
+	}
+
+	for _, stored := range postmortems {
+		pm, err := s.Get(ctx, stored.ID)
+		if err != nil {
+			log.Printf("Failed to load postmortem %s for reminders: %v", stored.ID.Hex(), err)
+			continue
+		}
+		for _, item := range pm.ActionItems {
+			if item.Status == models.StatusResolved || item.Status == models.StatusClosed {
+				continue
+			}
+			if item.DueAt.After(now.Add(24 * time.Hour)) {
+				continue
+			}
+			if item.LastReminderAt != nil && now.Sub(*item.LastReminderAt) < 24*time.Hour {
+				continue
+			}
+			if err := s.remind(ctx, pm, item, now); err != nil {
+				log.Printf("Failed to send reminder for action item %s: %v", item.ID.Hex(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *PostmortemService) remind(ctx context.Context, pm models.Postmortem, item models.PostmortemAction, now time.Time) error {
+	var owner models.User
+	if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": item.OwnerID}).Decode(&owner); err != nil {
+		return err
+	}
+
+	state := "is due " + item.DueAt.Format("Mon Jan 2 15:04")
+	if item.DueAt.Before(now) {
+		state = "was due " + item.DueAt.Format("Mon Jan 2 15:04") + " and is overdue"
+	}
+	if _, err := s.notifications.Send(ctx, Notification{
+		Subject:    "Action item due: " + item.Title,
+		Body:       fmt.Sprintf("Hi %s,\n\nThe action item \"%s\" from %s %s.\nTicket: %s", owner.Name, item.Title, pm.Title, state, item.TicketID.Hex()),
+		Recipients: []string{owner.Email},
+	}); err != nil {
+		return err
+	}
+
+	_, err := s.db.GetCollection("postmortems").UpdateOne(ctx,
+		bson.M{"_id": pm.ID, "actionItems.id": item.ID},
+		bson.M{"$set": bson.M{"actionItems.$.lastReminderAt": now}})
+	return err
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// ErrDeviceUnregistered means the provider no longer accepts the token, so
+// it is removed.
+var ErrDeviceUnregistered = errors.New("device token is no longer registered")
+
+// PushMessage is a notification for a mobile device. Data is delivered to
+// the app alongside the alert, e.g. {"ticketId": "..."}.
+type PushMessage struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// PushProvider delivers a message to one device token.
+type PushProvider interface {
+	Name() string
+	Send(ctx context.Context, token string, msg PushMessage) error
+}
+
+// PushService keeps device tokens and pushes to the devices of users.
+type PushService struct {
+	db        *database.MongoDB
+	providers map[string]PushProvider
+}
+
+func NewPushService(db *database.MongoDB, cfg *config.Config) *PushService {
+	s := &PushService{db: db, providers: map[string]PushProvider{}}
+	client := &http.Client{Timeout: 10 * time.Second}
+	if cfg.FCMCredentialsFile != "" {
+		fcm, err := newFCMProvider(cfg.FCMCredentialsFile, cfg.FCMProjectID, client)
+		if err != nil {
+			log.Printf("FCM push disabled: %v", err)
+		} else {
+			s.providers[fcm.Name()] = fcm
+		}
+	}
+	if cfg.APNsKeyFile != "" {
+		apns, err := newAPNsProvider(cfg, client)
+		if err != nil {
+			log.Printf("APNs push disabled: %v", err)
+		} else {
+			s.providers[apns.Name()] = apns
+		}
+	}
+	return s
+}
+
+// Providers returns the names of the configured push providers.
+func (s *PushService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Register stores a device token for a user, taking it over from any other
+// user it was registered to.
+func (s *PushService) Register(ctx context.Context, userID primitive.ObjectID, req models.RegisterDeviceRequest) (models.DeviceToken, error) {
+	now := time.Now()
+	var device models.DeviceToken
+	err := s.db.GetCollection("device_tokens").FindOneAndUpdate(ctx,
+		bson.M{"token": req.Token, "provider": req.Provider},
+		bson.M{
+			"$set": bson.M{
+				"userId":     userID,
+				"platform":   req.Platform,
+				"deviceName": req.DeviceName,
+				"lastSeenAt": now,
+			},
+			"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "createdAt": now},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&device)
+	return device, err
+}
+
+func (s *PushService) ListDevices(ctx context.Context, userID primitive.ObjectID) ([]models.DeviceToken, error) {
+	cursor, err := s.db.GetCollection("device_tokens").Find(ctx, bson.M{"userId": userID},
+		options.Find().SetSort(bson.D{{"lastSeenAt", -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	devices := []models.DeviceToken{}
+	if err := cursor.All(ctx, &devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Unregister removes one of the user's devices.
+func (s *PushService) Unregister(ctx context.Context, userID, id primitive.ObjectID) (bool, error) {
+	result, err := s.db.GetCollection("device_tokens").DeleteOne(ctx, bson.M{"_id": id, "userId": userID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// NotifyUsers adds msg to the inbox of the given users, pushes it to every
+// registered device of theirs and returns how many devices accepted it.
+// Unregistered tokens are removed.
+func (s *PushService) NotifyUsers(ctx context.Context, userIDs []primitive.ObjectID, msg PushMessage) (int, error) {
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+	now := time.Now()
+	inbox := make([]interface{}, 0, len(userIDs))
+	for _, userID := range userIDs {
+		inbox = append(inbox, models.UserNotification{
+			ID:        primitive.NewObjectID(),
+			UserID:    userID,
+			Title:     msg.Title,
+			Body:      msg.Body,
+			Data:      msg.Data,
+			CreatedAt: now,
+		})
+	}
+	if _, err := s.db.GetCollection("user_notifications").InsertMany(ctx, inbox); err != nil {
+		log.Printf("Failed to add notification %q to inboxes: %v", msg.Title, err)
+	}
+
+	if len(s.providers) == 0 {
+		return 0, nil
+	}
+	cursor, err := s.db.GetCollection("device_tokens").Find(ctx, bson.M{"userId": bson.M{"$in": userIDs}})
+	if err != nil {
+		return 0, err
+	}
+	var devices []models.DeviceToken
+	if err := cursor.All(ctx, &devices); err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, device := range devices {
+		provider, ok := s.providers[device.Provider]
+		if !ok {
+			continue
+		}
+		if err := provider.Send(ctx, device.Token, msg); err != nil {
+			if err == ErrDeviceUnregistered {
+				s.db.GetCollection("device_tokens").DeleteOne(ctx, bson.M{"_id": device.ID})
+				continue
+			}
+			log.Printf("Push via %s to device %s failed: %v", provider.Name(), device.ID.Hex(), err)
+			continue
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// UnreadNotifications returns the user's newest unread notifications, at
+// most limit, and how many are unread in all.
+func (s *PushService) UnreadNotifications(ctx context.Context, userID primitive.ObjectID, limit int64) ([]models.UserNotification, int64, error) {
+	filter := bson.M{"userId": userID, "readAt": nil}
+	collection := s.db.GetCollection("user_notifications")
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+	notifications := []models.UserNotification{}
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, 0, err
+	}
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+// MarkNotificationsRead marks the given notifications of the user read, or
+// all of them when ids is empty, and returns how many were unread.
+func (s *PushService) MarkNotificationsRead(ctx context.Context, userID primitive.ObjectID, ids []primitive.ObjectID) (int64, error) {
+	filter := bson.M{"userId": userID, "readAt": nil}
+	if len(ids) > 0 {
+		filter["_id"] = bson.M{"$in": ids}
+	}
+	res, err := s.db.GetCollection("user_notifications").UpdateMany(ctx, filter, bson.M{"$set": bson.M{"readAt": time.Now()}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// NotifyTicketAssignee pushes to the assignee of a critical ticket or of a
+// ticket opened for an anomaly. Other tickets are left to email.
+func (s *PushService) NotifyTicketAssignee(ctx context.Context, ticket models.Ticket) {
+	if ticket.AssignedTo == nil || ticket.Status == models.StatusResolved || ticket.Status == models.StatusClosed {
+		return
+	}
+
+	var title string
+	if ticket.Priority == models.PriorityCritical {
+		title = "Critical ticket assigned to you"
+	} else {
+		var anomaly models.AnomalyRecord
+		err := s.db.GetCollection("mon_anomalies").FindOne(ctx, bson.M{"ticketId": ticket.ID}).Decode(&anomaly)
+		if err != nil {
+			return
+		}
+		title = fmt.Sprintf("Anomaly (%s) assigned to you", anomaly.Severity)
+	}
+
+	msg := PushMessage{
+		Title: title,
+		Body:  ticket.Title,
+		Data:  map[string]string{"ticketId": ticket.ID.Hex(), "priority": string(ticket.Priority)},
+	}
+	if _, err := s.NotifyUsers(ctx, []primitive.ObjectID{*ticket.AssignedTo}, msg); err != nil {
+		log.Printf("Failed to push ticket %s to assignee: %v", ticket.ID.Hex(), err)
+	}
+}
+
+// sendPush posts a JSON payload and maps provider responses to errors.
+// gone lists the status codes that mean the token is no longer valid.
+func sendPush(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, body interface{}, gone ...int) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	for _, code := range gone {
+		if resp.StatusCode == code {
+			return ErrDeviceUnregistered
+		}
+	}
+	var buf bytes.Buffer
+	buf.ReadFrom(resp.Body)
+	return fmt.Errorf("push API returned %d: %s", resp.StatusCode, strings.TrimSpace(buf.String()))
+}
+
+// fcmProvider uses the FCM HTTP v1 API with a service account.
+type fcmProvider struct {
+	projectID string
+	account   *googleServiceAccount
+	client    *http.Client
+}
+
+func newFCMProvider(credentialsFile, projectID string, client *http.Client) (*fcmProvider, error) {
+	account, err := newGoogleServiceAccount(credentialsFile, "https://www.googleapis.com/auth/firebase.messaging", client)
+	if err != nil {
+		return nil, err
+	}
+	if projectID == "" {
+		projectID = account.projectID
+	}
+	return &fcmProvider{projectID: projectID, account: account, client: client}, nil
+}
+
+func (f *fcmProvider) Name() string {
+	return models.PushProviderFCM
+}
+
+func (f *fcmProvider) Send(ctx context.Context, token string, msg PushMessage) error {
+	access, err := f.account.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("https://fcm.googleapis.com/v1/projects/%s/messages:send", f.projectID)
+	body := map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        token,
+			"notification": map[string]string{"title": msg.Title, "body": msg.Body},
+			"data":         msg.Data,
+			"android":      map[string]string{"priority": "high"},
+			"apns": map[string]interface{}{
+				"headers": map[string]string{"apns-priority": "10"},
+			},
+		},
+	}
+	// FCM answers 404 UNREGISTERED for tokens of uninstalled apps.
+	return sendPush(ctx, f.client, endpoint, map[string]string{"Authorization": "Bearer " + access}, body, http.StatusNotFound)
+}
+
+// apnsProvider uses token-based authentication with a .p8 signing key.
+type apnsProvider struct {
+	keyID  string
+	teamID string
+	topic  string
+	host   string
+	key    *ecdsa.PrivateKey
+	client *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newAPNsProvider(cfg *config.Config, client *http.Client) (*apnsProvider, error) {
+	data, err := os.ReadFile(cfg.APNsKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs key: %v", err)
+	}
+	host := "https://api.sandbox.push.apple.com"
+	if cfg.APNsProduction {
+		host = "https://api.push.apple.com"
+	}
+	return &apnsProvider{
+		keyID:  cfg.APNsKeyID,
+		teamID: cfg.APNsTeamID,
+		topic:  cfg.APNsTopic,
+		host:   host,
+		key:    key,
+		client: client,
+	}, nil
+}
+
+func (a *apnsProvider) Name() string {
+	return models.PushProviderAPNs
+}
+
+// providerToken is reused for up to 50 minutes; Apple rejects tokens older
+// than an hour and throttles ones refreshed more than every 20 minutes.
+func (a *apnsProvider) providerToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token != "" && time.Now().Before(a.expires) {
+		return a.token, nil
+	}
+	t := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": a.teamID,
+		"iat": time.Now().Unix(),
+	})
+	t.Header["kid"] = a.keyID
+	signed, err := t.SignedString(a.key)
+	if err != nil {
+		return "", err
+	}
+	a.token = signed
+	a.expires = time.Now().Add(50 * time.Minute)
+	return a.token, nil
+}
+
+func (a *apnsProvider) Send(ctx context.Context, token string, msg PushMessage) error {
+	auth, err := a.providerToken()
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": msg.Title, "body": msg.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range msg.Data {
+		body[k] = v
+	}
+	headers := map[string]string{
+		"Authorization":  "bearer " + auth,
+		"apns-topic":     a.topic,
+		"apns-push-type": "alert",
+		"apns-priority":  "10",
+	}
+	// 410 Unregistered and 400 BadDeviceToken both mean the token is dead;
+	// other 400s are payload errors, but tokens are the only variable input.
+	return sendPush(ctx, a.client, a.host+"/3/device/"+token, headers, body, http.StatusGone, http.StatusBadRequest)
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	// vocabularyTTL is how long the words of the indexed documents are
+	// cached before being counted again, so newly indexed documents are
+	// picked up within it.
+	vocabularyTTL = 5 * time.Minute
+	// minCorrectedWordLength is the shortest word spell correction touches;
+	// shorter words are too often codes or abbreviations.
+	minCorrectedWordLength = 4
+)
+
+// queryWordPattern finds the words of a query: letters and digits,
+// keeping apostrophes inside words such as "can't".
+var queryWordPattern = regexp.MustCompile(`[\p{L}\p{N}]+(?:'[\p{L}\p{N}]+)*`)
+
+// QueryService rewrites document search queries before they are embedded:
+// misspelt words are corrected against the words of the indexed
+// documents, and glossary acronyms and synonyms are added.
+type QueryService struct {
+	vectors       *VectorService
+	glossary      *GlossaryService
+	spellCorrects bool
+
+	mu         sync.RWMutex
+	vocabulary map[string]int
+	loadedAt   time.Time
+}
+
+func NewQueryService(vectors *VectorService, glossary *GlossaryService, cfg *config.Config) *QueryService {
+	return &QueryService{vectors: vectors, glossary: glossary, spellCorrects: cfg.QuerySpellCorrection}
+}
+
+// Process spell-corrects query and expands its glossary terms. Failing to
+// read the glossary only skips the expansion, so search keeps working.
+func (s *QueryService) Process(ctx context.Context, query string) models.ProcessedQuery {
+	glossary, err := s.glossary.List(ctx)
+	if err != nil {
+		log.Printf("Failed to load glossary: %v", err)
+	}
+
+	processed := models.ProcessedQuery{
+		Original:    query,
+		Corrected:   query,
+		Query:       query,
+		Corrections: []models.QueryCorrection{},
+		Expansions:  []models.QueryExpansion{},
+	}
+	if s.spellCorrects {
+		processed.Corrected, processed.Corrections = correctQuery(query, s.loadVocabulary(), glossaryWords(glossary))
+	}
+	processed.Expansions = expandQuery(processed.Corrected, glossary)
+	processed.Query = processed.Corrected
+	for _, expansion := range processed.Expansions {
+		processed.Query += " " + strings.Join(expansion.Added, " ")
+	}
+	return processed
+}
+
+// correctQuery replaces the words of query that vocabulary lacks with the
+// closest word it has, keeping the rest of the query as written. Words
+// known from the glossary, short words, words with digits and acronyms
+// written in capitals are left alone.
+func correctQuery(query string, vocabulary map[string]int, known map[string]bool) (string, []models.QueryCorrection) {
+	corrections := []models.QueryCorrection{}
+	if len(vocabulary) == 0 {
+		return query, corrections
+	}
+	var b strings.Builder
+	last := 0
+	for _, span := range queryWordPattern.FindAllStringIndex(query, -1) {
+		word := query[span[0]:span[1]]
+		lower := strings.ToLower(word)
+		if len([]rune(lower)) < minCorrectedWordLength || vocabulary[lower] > 0 || known[lower] ||
+			strings.IndexFunc(word, unicode.IsDigit) >= 0 || word == strings.ToUpper(word) {
+			continue
+		}
+		replacement := closestWord(lower, vocabulary)
+		if replacement == "" {
+			continue
+		}
+		replacement = matchCase(word, replacement)
+		b.WriteString(query[last:span[0]])
+		b.WriteString(replacement)
+		last = span[1]
+		corrections = append(corrections, models.QueryCorrection{From: word, To: replacement})
+	}
+	b.WriteString(query[last:])
+	return b.String(), corrections
+}
+
+// closestWord returns the vocabulary word nearest to word: one edit away
+// for words up to six letters and two for longer ones, preferring fewer
+// edits, then the more frequent word. It returns "" when none is close.
+func closestWord(word string, vocabulary map[string]int) string {
+	runes := []rune(word)
+	maxDistance := 1
+	if len(runes) > 6 {
+		maxDistance = 2
+	}
+	best, bestDistance, bestCount := "", maxDistance+1, 0
+	for candidate, count := range vocabulary {
+		c := []rune(candidate)
+		if diff := len(c) - len(runes); diff > maxDistance || -diff > maxDistance {
+			continue
+		}
+		d := editDistance(runes, c, maxDistance)
+		if d < bestDistance || (d == bestDistance && (count > bestCount || (count == bestCount && candidate < best))) {
+			best, bestDistance, bestCount = candidate, d, count
+		}
+	}
+	if bestDistance > maxDistance {
+		return ""
+	}
+	return best
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent letters turning a into b, giving up with max+1 once it exceeds
+// max.
+func editDistance(a, b []rune, max int) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = minInt(cur[j], prev2[j-2]+1)
+			}
+			rowMin = minInt(rowMin, cur[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// matchCase writes replacement capitalized like word.
+func matchCase(word, replacement string) string {
+	first := []rune(word)[0]
+	if unicode.IsUpper(first) {
+		r := []rune(replacement)
+		r[0] = unicode.ToUpper(r[0])
+		return string(r)
+	}
+	return replacement
+}
+
+// expandQuery returns, for each glossary term whose term, expansion or a
+// synonym appears in query, the others to add to it. Words added for one
+// term are not added again for another.
+func expandQuery(query string, glossary []models.GlossaryTerm) []models.QueryExpansion {
+	expansions := []models.QueryExpansion{}
+	words := queryWords(query)
+	added := map[string]bool{}
+	for _, term := range glossary {
+		phrases := glossaryPhrases(term)
+		var present bool
+		var missing []string
+		for _, phrase := range phrases {
+			if containsPhrase(words, queryWords(phrase)) {
+				present = true
+			} else {
+				missing = append(missing, phrase)
+			}
+		}
+		if !present {
+			continue
+		}
+		expansion := models.QueryExpansion{Term: term.Term, Added: []string{}}
+		for _, phrase := range missing {
+			if key := strings.ToLower(phrase); !added[key] {
+				added[key] = true
+				expansion.Added = append(expansion.Added, phrase)
+			}
+		}
+		if len(expansion.Added) > 0 {
+			expansions = append(expansions, expansion)
+		}
+	}
+	return expansions
+}
+
+// queryWords returns the words of text lowercased.
+func queryWords(text string) []string {
+	words := queryWordPattern.FindAllString(strings.ToLower(text), -1)
+	if words == nil {
+		return []string{}
+	}
+	return words
+}
+
+// containsPhrase reports whether phrase appears in words as consecutive
+// words.
+func containsPhrase(words, phrase []string) bool {
+	if len(phrase) == 0 {
+		return false
+	}
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j := range phrase {
+			if words[i+j] != phrase[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// glossaryWords returns the words of every glossary term, expansion and
+// synonym, which spell correction leaves alone.
+func glossaryWords(glossary []models.GlossaryTerm) map[string]bool {
+	known := map[string]bool{}
+	for _, term := range glossary {
+		for _, phrase := range append([]string{term.Term, term.Expansion}, term.Synonyms...) {
+			for _, word := range queryWords(phrase) {
+				known[word] = true
+			}
+		}
+	}
+	return known
+}
+
+// loadVocabulary returns how often each word appears in the indexed
+// documents, counting them again when older than vocabularyTTL.
+func (s *QueryService) loadVocabulary() map[string]int {
+	s.mu.RLock()
+	vocabulary, loadedAt := s.vocabulary, s.loadedAt
+	s.mu.RUnlock()
+	if vocabulary != nil && time.Since(loadedAt) < vocabularyTTL {
+		return vocabulary
+	}
+
+	vocabulary = map[string]int{}
+	for _, doc := range s.vectors.Documents() {
+		for _, word := range queryWords(doc.Title + " " + doc.Content) {
+			if strings.IndexFunc(word, unicode.IsDigit) < 0 {
+				vocabulary[word]++
+			}
+		}
+	}
+	s.mu.Lock()
+	s.vocabulary, s.loadedAt = vocabulary, time.Now()
+	s.mu.Unlock()
+	return vocabulary
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// Quick actions a technician can take from an email link.
+const (
+	QuickActionAcknowledge = "acknowledge"
+	QuickActionInProgress  = "in_progress"
+	QuickActionAssignToMe  = "assign_to_me"
+)
+
+var quickActionLabels = map[string]string{
+	QuickActionAcknowledge: "Acknowledge",
+	QuickActionInProgress:  "Set in progress",
+	QuickActionAssignToMe:  "Assign to me",
+}
+
+var (
+	ErrQuickActionInvalid = errors.New("invalid action link")
+	ErrQuickActionExpired = errors.New("this action link has expired")
+	ErrQuickActionUsed    = errors.New("this action link has already been used")
+	ErrQuickActionClosed  = errors.New("the ticket is already resolved or closed")
+	ErrQuickActionClaimed = errors.New("the ticket has already been claimed by another technician")
+)
+
+// QuickAction is a verified action link.
+type QuickAction struct {
+	TicketID primitive.ObjectID
+	UserID   primitive.ObjectID
+	Action   string
+	Label    string
+	nonce    string
+}
+
+// QuickActionService signs one-click ticket action links for notification
+// emails. A link is bound to a ticket, a technician and an action, expires,
+// and can be used once.
+type QuickActionService struct {
+	db            *database.MongoDB
+	notifications *NotificationService
+	history       *TicketHistoryService
+	secret        []byte
+	baseURL       string
+	ttl           time.Duration
+}
+
+func NewQuickActionService(db *database.MongoDB, notifications *NotificationService, history *TicketHistoryService, cfg *config.Config) *QuickActionService {
+	return &QuickActionService{
+		db:            db,
+		notifications: notifications,
+		history:       history,
+		secret:        []byte(cfg.JWTSecret),
+		baseURL:       strings.TrimRight(cfg.PublicAPIURL, "/"),
+		ttl:           cfg.QuickActionTTL,
+	}
+}
+
+// Link returns the URL of a signed action link.
+func (s *QuickActionService) Link(ticketID, userID primitive.ObjectID, action string) string {
+	nonce := make([]byte, 8)
+	rand.Read(nonce)
+	payload := strings.Join([]string{
+		ticketID.Hex(),
+		userID.Hex(),
+		action,
+		strconv.FormatInt(time.Now().Add(s.ttl).Unix(), 10),
+		hex.EncodeToString(nonce),
+	}, ".")
+	return s.baseURL + "/api/actions/" + payload + "." + s.signature(payload)
+}
+
+func (s *QuickActionService) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a token's signature, expiry and that it has not been used.
+func (s *QuickActionService) Verify(ctx context.Context, token string) (QuickAction, error) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return QuickAction{}, ErrQuickActionInvalid
+	}
+	payload, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(s.signature(payload)), []byte(sig)) {
+		return QuickAction{}, ErrQuickActionInvalid
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 5 {
+		return QuickAction{}, ErrQuickActionInvalid
+	}
+	ticketID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil {
+		return QuickAction{}, ErrQuickActionInvalid
+	}
+	userID, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return QuickAction{}, ErrQuickActionInvalid
+	}
+	label, ok := quickActionLabels[parts[2]]
+	if !ok {
+		return QuickAction{}, ErrQuickActionInvalid
+	}
+	expires, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return QuickAction{}, ErrQuickActionInvalid
+	}
+	if time.Now().Unix() > expires {
+		return QuickAction{}, ErrQuickActionExpired
+	}
+
+	count, err := s.db.GetCollection("quick_action_uses").CountDocuments(ctx, bson.M{"_id": parts[4]})
+	if err != nil {
+		return QuickAction{}, err
+	}
+	if count > 0 {
+		return QuickAction{}, ErrQuickActionUsed
+	}
+	return QuickAction{TicketID: ticketID, UserID: userID, Action: parts[2], Label: label, nonce: parts[4]}, nil
+}
+
+// Execute performs a verified action on behalf of the technician the link
+// was issued to, marks the link used and records the change in the ticket
+// history. Assign to me only takes tickets nobody has claimed yet.
+func (s *QuickActionService) Execute(ctx context.Context, action QuickAction) (models.Ticket, error) {
+	var user models.User
+	if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": action.UserID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.Ticket{}, ErrQuickActionInvalid
+		}
+		return models.Ticket{}, err
+	}
+	if user.Role == models.RoleRequester {
+		return models.Ticket{}, ErrQuickActionInvalid
+	}
+
+	var ticket models.Ticket
+	if err := s.db.GetCollection("tickets").FindOne(ctx, bson.M{"_id": action.TicketID}).Decode(&ticket); err != nil {
+		return models.Ticket{}, err
+	}
+	if ticket.Status == models.StatusResolved || ticket.Status == models.StatusClosed {
+		return ticket, ErrQuickActionClosed
+	}
+	if action.Action == QuickActionAssignToMe && ticket.AssignedTo != nil && *ticket.AssignedTo != user.ID {
+		return ticket, ErrQuickActionClaimed
+	}
+
+	// Claim the link before acting so a double click runs it once.
+	if _, err := s.db.GetCollection("quick_action_uses").InsertOne(ctx, bson.M{
+		"_id":      action.nonce,
+		"ticketId": action.TicketID,
+		"userId":   action.UserID,
+		"action":   action.Action,
+		"usedAt":   time.Now(),
+	}); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ticket, ErrQuickActionUsed
+		}
+		return ticket, err
+	}
+
+	before := ticket
+	now := time.Now()
+	filter := bson.M{"_id": ticket.ID}
+	set := bson.M{"updatedAt": now}
+	update := bson.M{"$set": set}
+	switch action.Action {
+	case QuickActionAcknowledge:
+		set["acknowledgedAt"] = now
+		set["acknowledgedBy"] = user.ID
+		ticket.AcknowledgedAt, ticket.AcknowledgedBy = &now, &user.ID
+	case QuickActionInProgress:
+		ApplyStatusTransition(update, ticket, models.StatusInProgress, now)
+		ticket.Status = models.StatusInProgress
+		if ticket.AssignedTo == nil {
+			set["assignedTo"] = user.ID
+			ticket.AssignedTo = &user.ID
+		}
+	case QuickActionAssignToMe:
+		// Another technician may have claimed it since it was read
+		filter["assignedTo"] = bson.M{"$in": bson.A{nil, user.ID}}
+		set["assignedTo"] = user.ID
+		ticket.AssignedTo = &user.ID
+	}
+	ticket.UpdatedAt = now
+	res, err := s.db.GetCollection("tickets").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return ticket, err
+	}
+	if res.MatchedCount == 0 {
+		return before, ErrQuickActionClaimed
+	}
+	if err := s.history.Record(ctx, ticket.ID, user, DiffTicket(before, ticket)); err != nil {
+		log.Printf("Failed to record history of ticket %s: %v", ticket.ID.Hex(), err)
+	}
+	return ticket, nil
+}
+
+// EmailTicket sends each recipient an email about a ticket with their own
+// action links.
+func (s *QuickActionService) EmailTicket(ctx context.Context, ticket models.Ticket, recipients []models.User, subject, intro string, actions ...string) {
+	for _, user := range recipients {
+		if user.Email == "" {
+			continue
+		}
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("Hi %s,\n\n%s\n\n%s\nPriority: %s | Category: %s | Status: %s\n\n%s\n\n",
+			user.Name, intro, ticket.Title, ticket.Priority, ticket.Category, ticket.Status, ticket.Description))
+		b.WriteString("Quick actions (no login needed):\n")
+		for _, action := range actions {
+			b.WriteString(fmt.Sprintf("- %s: %s\n", quickActionLabels[action], s.Link(ticket.ID, user.ID, action)))
+		}
+		b.WriteString(fmt.Sprintf("\nLinks are personal, single-use and expire in %s.", s.ttl))
+
+		err := s.notifications.SendEmail(ctx, Notification{Subject: subject, Body: b.String(), Recipients: []string{user.Email}})
+		if err == ErrEmailNotConfigured {
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to email ticket %s to %s: %v", ticket.ID.Hex(), user.Email, err)
+		}
+	}
+}
+
+// NotifyAssignee emails the assignee of a ticket with acknowledge and
+// in-progress links.
+func (s *QuickActionService) NotifyAssignee(ctx context.Context, ticket models.Ticket) {
+	if ticket.AssignedTo == nil {
+		return
+	}
+	var assignee models.User
+	if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": *ticket.AssignedTo}).Decode(&assignee); err != nil {
+		return
+	}
+	s.EmailTicket(ctx, ticket, []models.User{assignee}, "[Assigned] "+ticket.Title,
+		"A ticket has been assigned to you.", QuickActionAcknowledge, QuickActionInProgress)
+}
+
+// NotifyCritical emails every technician about a new unassigned critical
+// ticket so one of them can claim it.
+func (s *QuickActionService) NotifyCritical(ctx context.Context, ticket models.Ticket) {
+	cursor, err := s.db.GetCollection("users").Find(ctx, bson.M{"role": models.RoleTechnician})
+	if err != nil {
+		log.Printf("Failed to load technicians for ticket %s: %v", ticket.ID.Hex(), err)
+		return
+	}
+	var technicians []models.User
+	if err := cursor.All(ctx, &technicians); err != nil {
+		return
+	}
+	s.EmailTicket(ctx, ticket, technicians, "[Critical] "+ticket.Title,
+		"A critical ticket has been raised and is not assigned yet.", QuickActionAssignToMe, QuickActionAcknowledge)
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// ErrReminderNotOwned is returned when a user changes another user's
+// reminder.
+var ErrReminderNotOwned = errors.New("you can only change your own reminders")
+
+const maxReminderNoteLength = 500
+
+// ReminderService keeps technicians' follow-up reminders on tickets and
+// delivers them when they are due.
+type ReminderService struct {
+	db            *database.MongoDB
+	notifications *NotificationService
+	push          *PushService
+}
+
+func NewReminderService(db *database.MongoDB, notifications *NotificationService, push *PushService) *ReminderService {
+	return &ReminderService{db: db, notifications: notifications, push: push}
+}
+
+func (s *ReminderService) collection() *mongo.Collection {
+	return s.db.GetCollection("ticket_reminders")
+}
+
+// ValidateReminder checks a new reminder's note and due time.
+func ValidateReminder(req models.CreateReminderRequest) error {
+	if err := validateReminderNote(req.Note); err != nil {
+		return err
+	}
+	return validateReminderDue(req.DueAt)
+}
+
+// ValidateReminderUpdate checks the fields a reminder update sets.
+func ValidateReminderUpdate(req models.UpdateReminderRequest) error {
+	if req.Note != nil {
+		if err := validateReminderNote(*req.Note); err != nil {
+			return err
+		}
+	}
+	if req.DueAt != nil {
+		return validateReminderDue(*req.DueAt)
+	}
+	return nil
+}
+
+func validateReminderNote(note string) error {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return errors.New("note is required")
+	}
+	if len(note) > maxReminderNoteLength {
+		return fmt.Errorf("note must be at most %d characters", maxReminderNoteLength)
+	}
+	return nil
+}
+
+func validateReminderDue(dueAt time.Time) error {
+	if dueAt.Before(time.Now().Add(-time.Minute)) {
+		return errors.New("dueAt must be in the future")
+	}
+	return nil
+}
+
+// Create sets a reminder on a ticket for user. It returns
+// mongo.ErrNoDocuments when the ticket does not exist.
+func (s *ReminderService) Create(ctx context.Context, ticketID primitive.ObjectID, user models.User, req models.CreateReminderRequest) (models.TicketReminder, error) {
+	n, err := s.db.GetCollection("tickets").CountDocuments(ctx, bson.M{"_id": ticketID}, options.Count().SetLimit(1))
+	if err != nil {
+		return models.TicketReminder{}, err
+	}
+	if n == 0 {
+		return models.TicketReminder{}, mongo.ErrNoDocuments
+	}
+
+	reminder := models.TicketReminder{
+		ID:        primitive.NewObjectID(),
+		TicketID:  ticketID,
+		UserID:    user.ID,
+		Note:      strings.TrimSpace(req.Note),
+		DueAt:     req.DueAt,
+		CreatedAt: time.Now(),
+	}
+	if _, err := s.collection().InsertOne(ctx, reminder); err != nil {
+		return models.TicketReminder{}, err
+	}
+	return reminder, nil
+}
+
+// List returns every reminder on a ticket, soonest first.
+func (s *ReminderService) List(ctx context.Context, ticketID primitive.ObjectID) ([]models.TicketReminder, error) {
+	cursor, err := s.collection().Find(ctx, bson.M{"ticketId": ticketID}, options.Find().SetSort(bson.D{{Key: "dueAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	reminders := []models.TicketReminder{}
+	if err := cursor.All(ctx, &reminders); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// get loads a reminder of a ticket that user may change: their own, or
+// any reminder for admins.
+func (s *ReminderService) get(ctx context.Context, ticketID, id primitive.ObjectID, user models.User) (models.TicketReminder, error) {
+	var reminder models.TicketReminder
+	if err := s.collection().FindOne(ctx, bson.M{"_id": id, "ticketId": ticketID}).Decode(&reminder); err != nil {
+		return models.TicketReminder{}, err
+	}
+	if reminder.UserID != user.ID && user.Role != models.RoleAdmin {
+		return models.TicketReminder{}, ErrReminderNotOwned
+	}
+	return reminder, nil
+}
+
+// Update changes a reminder. A new due time delivers it again, and
+// completing it takes it off the agenda.
+func (s *ReminderService) Update(ctx context.Context, ticketID, id primitive.ObjectID, user models.User, req models.UpdateReminderRequest) (models.TicketReminder, error) {
+	reminder, err := s.get(ctx, ticketID, id, user)
+	if err != nil {
+		return models.TicketReminder{}, err
+	}
+
+	set, unset := bson.M{}, bson.M{}
+	if req.Note != nil {
+		reminder.Note = strings.TrimSpace(*req.Note)
+		set["note"] = reminder.Note
+	}
+	if req.DueAt != nil && !req.DueAt.Equal(reminder.DueAt) {
+		reminder.DueAt, reminder.FiredAt = *req.DueAt, nil
+		set["dueAt"] = reminder.DueAt
+		unset["firedAt"] = ""
+	}
+	if req.Completed != nil {
+		if *req.Completed && reminder.CompletedAt == nil {
+			now := time.Now()
+			reminder.CompletedAt = &now
+			set["completedAt"] = now
+		} else if !*req.Completed {
+			reminder.CompletedAt = nil
+			unset["completedAt"] = ""
+		}
+	}
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if len(update) > 0 {
+		if _, err := s.collection().UpdateByID(ctx, id, update); err != nil {
+			return models.TicketReminder{}, err
+		}
+	}
+	return reminder, nil
+}
+
+func (s *ReminderService) Delete(ctx context.Context, ticketID, id primitive.ObjectID, user models.User) error {
+	if _, err := s.get(ctx, ticketID, id, user); err != nil {
+		return err
+	}
+	_, err := s.collection().DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// Agenda returns the user's open reminders: overdue ones, and those due
+// within horizon.
+func (s *ReminderService) Agenda(ctx context.Context, userID primitive.ObjectID, horizon time.Duration) (models.Agenda, error) {
+	now := time.Now()
+	filter := bson.M{
+		"userId":      userID,
+		"completedAt": nil,
+		"dueAt":       bson.M{"$lte": now.Add(horizon)},
+	}
+	cursor, err := s.collection().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "dueAt", Value: 1}}))
+	if err != nil {
+		return models.Agenda{}, err
+	}
+	var reminders []models.TicketReminder
+	if err := cursor.All(ctx, &reminders); err != nil {
+		return models.Agenda{}, err
+	}
+
+	ticketIDs := make([]primitive.ObjectID, 0, len(reminders))
+	for _, r := range reminders {
+		ticketIDs = append(ticketIDs, r.TicketID)
+	}
+	tickets := map[primitive.ObjectID]models.Ticket{}
+	if len(ticketIDs) > 0 {
+		cursor, err := s.db.GetCollection("tickets").Find(ctx, bson.M{"_id": bson.M{"$in": ticketIDs}},
+			options.Find().SetProjection(bson.M{"title": 1, "status": 1, "priority": 1}))
+		if err != nil {
+			return models.Agenda{}, err
+		}
+		var found []models.Ticket
+		if err := cursor.All(ctx, &found); err != nil {
+			return models.Agenda{}, err
+		}
+		for _, t := range found {
+			tickets[t.ID] = t
+		}
+	}
+
+	agenda := models.Agenda{Overdue: []models.AgendaItem{}, Upcoming: []models.AgendaItem{}}
+	for _, r := range reminders {
+		t := tickets[r.TicketID]
+		item := models.AgendaItem{TicketReminder: r, TicketTitle: t.Title, TicketStatus: t.Status, TicketPriority: t.Priority}
+		if r.DueAt.After(now) {
+			agenda.Upcoming = append(agenda.Upcoming, item)
+		} else {
+			agenda.Overdue = append(agenda.Overdue, item)
+		}
+	}
+	return agenda, nil
+}
+
+// Start delivers due reminders every interval.
+func (s *ReminderService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if _, err := s.FireDue(ctx); err != nil {
+					log.Printf("reminder error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// FireDue delivers every due reminder not delivered yet and returns how
+// many were delivered. Each reminder is claimed before it is delivered, so
+// replicas running the scheduler side by side never deliver one twice.
+func (s *ReminderService) FireDue(ctx context.Context) (int, error) {
+	fired := 0
+	for {
+		now := time.Now()
+		var reminder models.TicketReminder
+		err := s.collection().FindOneAndUpdate(ctx,
+			bson.M{"firedAt": nil, "completedAt": nil, "dueAt": bson.M{"$lte": now}},
+			bson.M{"$set": bson.M{"firedAt": now}},
+			options.FindOneAndUpdate().SetSort(bson.D{{Key: "dueAt", Value: 1}}).SetReturnDocument(options.After),
+		).Decode(&reminder)
+		if err == mongo.ErrNoDocuments {
+			return fired, nil
+		}
+		if err != nil {
+			return fired, err
+		}
+		s.deliver(ctx, reminder)
+		fired++
+	}
+}
+
+// deliver pushes the reminder to the user's devices and emails it to them.
+func (s *ReminderService) deliver(ctx context.Context, reminder models.TicketReminder) {
+	var user models.User
+	if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": reminder.UserID}).Decode(&user); err != nil {
+		log.Printf("Failed to load user of reminder %s: %v", reminder.ID.Hex(), err)
+		return
+	}
+	var ticket models.Ticket
+	if err := s.db.GetCollection("tickets").FindOne(ctx, bson.M{"_id": reminder.TicketID}).Decode(&ticket); err != nil {
+		log.Printf("Failed to load ticket of reminder %s: %v", reminder.ID.Hex(), err)
+		return
+	}
+
+	msg := PushMessage{
+		Title: "Reminder: " + ticket.Title,
+		Body:  reminder.Note,
+		Data:  map[string]string{"ticketId": ticket.ID.Hex(), "reminderId": reminder.ID.Hex()},
+	}
+	if _, err := s.push.NotifyUsers(ctx, []primitive.ObjectID{user.ID}, msg); err != nil {
+		log.Printf("Failed to push reminder %s: %v", reminder.ID.Hex(), err)
+	}
+
+	if user.Email == "" {
+		return
+	}
+	body := fmt.Sprintf("Hi %s,\n\nYou asked to be reminded about this ticket:\n\n%s\n\n%s\nPriority: %s | Status: %s\n",
+		user.Name, reminder.Note, ticket.Title, ticket.Priority, ticket.Status)
+	err := s.notifications.SendEmail(ctx, Notification{Subject: "[Reminder] " + ticket.Title, Body: body, Recipients: []string{user.Email}})
+	if err != nil && err != ErrEmailNotConfigured {
+		log.Printf("Failed to email reminder %s to %s: %v", reminder.ID.Hex(), user.Email, err)
+	}
+}
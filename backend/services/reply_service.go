@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var replyToneInstructions = map[models.ReplyTone]string{
+	models.ToneFormal:   "Use a formal, professional tone. Address the requester politely and avoid contractions and emoji.",
+	models.ToneFriendly: "Use a warm, friendly and reassuring tone in plain language.",
+}
+
+// ReplyService drafts replies to requesters and tracks how technicians edit
+// them before sending.
+type ReplyService struct {
+	db         *database.MongoDB
+	llm        *LLMService
+	guardrails *GuardrailService
+}
+
+func NewReplyService(db *database.MongoDB, llm *LLMService, guardrails *GuardrailService) *ReplyService {
+	return &ReplyService{db: db, llm: llm, guardrails: guardrails}
+}
+
+// ValidTone reports whether tone is a supported reply tone.
+func ValidTone(tone models.ReplyTone) bool {
+	_, ok := replyToneInstructions[tone]
+	return ok
+}
+
+// Draft generates a reply for the ticket and stores it as a pending draft.
+func (s *ReplyService) Draft(ctx context.Context, ticket models.Ticket, req models.DraftReplyRequest, createdBy primitive.ObjectID) (models.ReplyDraft, error) {
+	if req.Tone == "" {
+		req.Tone = models.ToneFriendly
+	}
+
+	draft := models.ReplyDraft{
+		ID:        primitive.NewObjectID(),
+		TicketID:  ticket.ID,
+		Tone:      req.Tone,
+		Status:    models.ReplyDraftPending,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	if req.Solution != nil {
+		draft.SolutionTitle = req.Solution.Title
+	}
+
+	text, err := s.generate(ctx, ticket, req)
+	if err != nil {
+		if err != ErrLLMUnavailable {
+			log.Printf("Reply draft failed for ticket %s, using template: %v", ticket.ID.Hex(), err)
+		}
+		text = mockReplyDraft(ticket, req)
+		draft.Fallback = true
+	}
+	draft.Draft = text
+
+	if _, err := s.db.GetCollection("reply_drafts").InsertOne(ctx, draft); err != nil {
+		return models.ReplyDraft{}, err
+	}
+	return draft, nil
+}
+
+func (s *ReplyService) generate(ctx context.Context, ticket models.Ticket, req models.DraftReplyRequest) (string, error) {
+	var b strings.Builder
+	b.WriteString("Draft a reply from the IT support technician to the person who raised this ticket.\n\n")
+	b.WriteString(s.guardrails.Wrap(ctx, "ticket", ticket.ID.Hex(), "thread", TicketThread(ticket)) + "\n\n")
+	if req.Solution != nil {
+		b.WriteString("The technician chose this solution; explain it as clear steps the requester can follow:\n")
+		b.WriteString(s.guardrails.Wrap(ctx, "ticket", ticket.ID.Hex(), "solution", formatSolution(*req.Solution)) + "\n\n")
+	}
+	if req.Instructions != "" {
+		b.WriteString("Technician notes for this reply: " + req.Instructions + "\n\n")
+	}
+	b.WriteString(replyToneInstructions[req.Tone] + "\n")
+	b.WriteString("Keep it under 180 words. Respond with the reply text only, no subject line and no JSON.")
+
+	content, err := s.llm.Complete(ctx, CompletionRequest{
+		System:   "You are an IT support technician writing replies to colleagues who raised support tickets." + untrustedContentPolicy,
+		Prompt:   b.String(),
+		Endpoint: EndpointReplyDraft,
+	})
+	if err != nil {
+		return "", err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", fmt.Errorf("empty reply draft")
+	}
+	return content, nil
+}
+
+// Resolve records the text the technician sent (accepted) or that the draft
+// was thrown away (discarded).
+func (s *ReplyService) Resolve(ctx context.Context, ticketID, draftID primitive.ObjectID, status models.ReplyDraftStatus, finalText string, userID primitive.ObjectID) (models.ReplyDraft, error) {
+	var draft models.ReplyDraft
+	err := s.db.GetCollection("reply_drafts").FindOne(ctx, bson.M{"_id": draftID, "ticketId": ticketID}).Decode(&draft)
+	if err != nil {
+		return models.ReplyDraft{}, err
+	}
+	if draft.Status != models.ReplyDraftPending {
+		return models.ReplyDraft{}, fmt.Errorf("draft is already %s", draft.Status)
+	}
+
+	now := time.Now()
+	draft.Status = status
+	draft.ResolvedBy = &userID
+	draft.ResolvedAt = &now
+	set := bson.M{"status": status, "resolvedBy": userID, "resolvedAt": now}
+	if status == models.ReplyDraftAccepted {
+		draft.FinalText = finalText
+		draft.EditRatio = EditRatio(draft.Draft, finalText)
+		set["finalText"] = draft.FinalText
+		set["editRatio"] = draft.EditRatio
+	}
+
+	if _, err := s.db.GetCollection("reply_drafts").UpdateByID(ctx, draftID, bson.M{"$set": set}); err != nil {
+		return models.ReplyDraft{}, err
+	}
+	return draft, nil
+}
+
+// ListDrafts returns reply drafts for a ticket, newest first.
+func (s *ReplyService) ListDrafts(ctx context.Context, ticketID primitive.ObjectID) ([]models.ReplyDraft, error) {
+	cursor, err := s.db.GetCollection("reply_drafts").Find(ctx, bson.M{"ticketId": ticketID}, options.Find().SetSort(bson.D{{"createdAt", -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	drafts := []models.ReplyDraft{}
+	if err := cursor.All(ctx, &drafts); err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
+
+// Stats summarizes acceptance and edit rates per tone.
+func (s *ReplyService) Stats(ctx context.Context) ([]models.ReplyDraftStats, error) {
+	opts := options.Find().SetProjection(bson.M{"tone": 1, "status": 1, "editRatio": 1})
+	cursor, err := s.db.GetCollection("reply_drafts").Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var drafts []models.ReplyDraft
+	if err := cursor.All(ctx, &drafts); err != nil {
+		return nil, err
+	}
+
+	byTone := map[models.ReplyTone]*models.ReplyDraftStats{}
+	unchanged := map[models.ReplyTone]int64{}
+	editSum := map[models.ReplyTone]float64{}
+	for _, tone := range []models.ReplyTone{models.ToneFormal, models.ToneFriendly} {
+		byTone[tone] = &models.ReplyDraftStats{Tone: tone}
+	}
+	for _, d := range drafts {
+		stats, ok := byTone[d.Tone]
+		if !ok {
+			continue
+		}
+		stats.Drafts++
+		switch d.Status {
+		case models.ReplyDraftAccepted:
+			stats.Accepted++
+			editSum[d.Tone] += d.EditRatio
+			if d.EditRatio == 0 {
+				unchanged[d.Tone]++
+			}
+		case models.ReplyDraftDiscarded:
+			stats.Discarded++
+		}
+	}
+
+	result := []models.ReplyDraftStats{}
+	for _, tone := range []models.ReplyTone{models.ToneFormal, models.ToneFriendly} {
+		stats := byTone[tone]
+		if resolved := stats.Accepted + stats.Discarded; resolved > 0 {
+			stats.AcceptanceRate = float64(stats.Accepted) / float64(resolved)
+		}
+		if stats.Accepted > 0 {
+			stats.UnchangedRate = float64(unchanged[tone]) / float64(stats.Accepted)
+			stats.AvgEditRatio = editSum[tone] / float64(stats.Accepted)
+		}
+		result = append(result, *stats)
+	}
+	return result, nil
+}
+
+// EditRatio is the word-level edit distance between two texts divided by the
+// length of the longer one.
+func EditRatio(original, final string) float64 {
+	a, b := strings.Fields(original), strings.Fields(final)
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	return float64(prev[len(b)]) / float64(longest)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func formatSolution(solution models.SuggestedSolution) string {
+	var b strings.Builder
+	b.WriteString(solution.Title + "\n")
+	if solution.Description != "" {
+		b.WriteString(solution.Description + "\n")
+	}
+	for i, step := range solution.Steps {
+		b.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
+	}
+	return b.String()
+}
+
+func mockReplyDraft(ticket models.Ticket, req models.DraftReplyRequest) string {
+	var b strings.Builder
+	if req.Tone == models.ToneFormal {
+		b.WriteString("Dear colleague,\n\n")
+		b.WriteString(fmt.Sprintf("Thank you for raising the ticket \"%s\". ", ticket.Title))
+	} else {
+		b.WriteString("Hi there,\n\n")
+		b.WriteString(fmt.Sprintf("Thanks for letting us know about \"%s\". ", ticket.Title))
+	}
+
+	if req.Solution != nil && len(req.Solution.Steps) > 0 {
+		b.WriteString("Please try the following steps:\n\n")
+		for i, step := range req.Solution.Steps {
+			b.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString("We are looking into the issue and will update you shortly.\n\n")
+	}
+
+	if req.Tone == models.ToneFormal {
+		b.WriteString("Should the issue persist, please reply to this message and we will investigate further.\n\nKind regards,\nIT Support")
+	} else {
+		b.WriteString("If that doesn't sort it out, just reply here and we'll dig in further.\n\nCheers,\nIT Support")
+	}
+	return b.String()
+}
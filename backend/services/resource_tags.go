@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// ParseTagFilters parses key:value tag filters such as the values of
+// repeated ?tag= query parameters. A value of "*" matches any value.
+func ParseTagFilters(values []string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(part, ":")
+			k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+			if !ok || k == "" || v == "" {
+				return nil, fmt.Errorf("invalid tag filter %q, expected key:value", part)
+			}
+			tags[k] = v
+		}
+	}
+	return tags, nil
+}
+
+// TagsMatch reports whether tags carry every key of selector with the
+// selected value, compared case-insensitively, or any value for "*".
+func TagsMatch(selector, tags map[string]string) bool {
+	for k, want := range selector {
+		got, ok := tags[k]
+		if !ok || (want != "*" && !strings.EqualFold(got, want)) {
+			return false
+		}
+	}
+	return true
+}
+
+// tagQuery is the filter for documents whose field (e.g. "tags") carries
+// every selected tag.
+func tagQuery(field string, selector map[string]string) bson.M {
+	filter := bson.M{}
+	for k, v := range selector {
+		if v == "*" {
+			filter[field+"."+k] = bson.M{"$exists": true}
+			continue
+		}
+		filter[field+"."+k] = v
+	}
+	return filter
+}
+
+// ResourceIDsWithTags returns the IDs of the monitored resources carrying
+// every selected tag.
+func ResourceIDsWithTags(ctx context.Context, db *database.MongoDB, selector map[string]string) ([]primitive.ObjectID, error) {
+	cursor, err := db.GetCollection("mon_resources").Find(ctx, tagQuery("tags", selector), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var resources []models.MonitoredResource
+	err = cursor.All(ctx, &resources)
+	cursor.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(resources))
+	for i, r := range resources {
+		ids[i] = r.ID
+	}
+	return ids, nil
+}
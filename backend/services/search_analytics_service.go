@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// SearchAnalyticsService logs document searches and how their results were
+// used, to show which queries the index answers poorly.
+type SearchAnalyticsService struct {
+	db      *database.MongoDB
+	vectors *VectorService
+}
+
+func NewSearchAnalyticsService(db *database.MongoDB, vectors *VectorService) *SearchAnalyticsService {
+	return &SearchAnalyticsService{db: db, vectors: vectors}
+}
+
+func (s *SearchAnalyticsService) collection() *mongo.Collection {
+	return s.db.GetCollection("search_logs")
+}
+
+// NewSearchLog describes a search that returned results after latency.
+func NewSearchLog(source, query string, results []models.DocumentSearchResult, latency time.Duration, userID primitive.ObjectID) models.SearchLog {
+	entry := models.SearchLog{
+		ID:          primitive.NewObjectID(),
+		Source:      source,
+		Query:       strings.TrimSpace(query),
+		QueryKey:    normalizeForHash(query),
+		UserID:      userID,
+		ResultCount: len(results),
+		LatencyMs:   latency.Milliseconds(),
+		DocumentIDs: []primitive.ObjectID{},
+		CreatedAt:   time.Now(),
+	}
+	seen := map[primitive.ObjectID]bool{}
+	for _, result := range results {
+		if result.Score > entry.TopScore {
+			entry.TopScore = result.Score
+		}
+		if !seen[result.Document.ID] {
+			seen[result.Document.ID] = true
+			entry.DocumentIDs = append(entry.DocumentIDs, result.Document.ID)
+		}
+	}
+	return entry
+}
+
+// CitedDocuments returns the results whose document the solutions name in
+// their references.
+func CitedDocuments(results []models.DocumentSearchResult, solutions []models.SuggestedSolution) []primitive.ObjectID {
+	var references []string
+	for _, solution := range solutions {
+		for _, ref := range solution.References {
+			references = append(references, strings.ToLower(ref))
+		}
+	}
+	cited := []primitive.ObjectID{}
+	seen := map[primitive.ObjectID]bool{}
+	for _, result := range results {
+		title := strings.ToLower(strings.TrimSpace(result.Document.Title))
+		if title == "" || seen[result.Document.ID] {
+			continue
+		}
+		for _, ref := range references {
+			if strings.Contains(ref, title) {
+				seen[result.Document.ID] = true
+				cited = append(cited, result.Document.ID)
+				break
+			}
+		}
+	}
+	return cited
+}
+
+// Record stores a search. Failures are logged rather than returned, so
+// analytics never break a search.
+func (s *SearchAnalyticsService) Record(ctx context.Context, entry models.SearchLog) {
+	if _, err := s.collection().InsertOne(ctx, entry); err != nil {
+		log.Printf("Failed to record search: %v", err)
+	}
+}
+
+// RecordClick notes that a result of a search was opened. It returns
+// mongo.ErrNoDocuments when the search did not return the document.
+func (s *SearchAnalyticsService) RecordClick(ctx context.Context, searchID, documentID primitive.ObjectID) error {
+	res, err := s.collection().UpdateOne(ctx,
+		bson.M{"_id": searchID, "documentIds": documentID},
+		bson.M{"$addToSet": bson.M{"clickedDocumentIds": documentID}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Analytics summarizes searches between from and to, optionally from one
+// source, listing up to limit queries and documents.
+func (s *SearchAnalyticsService) Analytics(ctx context.Context, from, to time.Time, source string, limit int) (models.SearchAnalytics, error) {
+	filter := bson.M{"createdAt": bson.M{"$gte": from, "$lt": to}}
+	if source != "" {
+		filter["source"] = source
+	}
+	cursor, err := s.collection().Find(ctx, filter)
+	if err != nil {
+		return models.SearchAnalytics{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.SearchLog
+	if err := cursor.All(ctx, &entries); err != nil {
+		return models.SearchAnalytics{}, err
+	}
+
+	titles := map[primitive.ObjectID]string{}
+	for _, doc := range s.vectors.Documents() {
+		titles[doc.ID] = doc.Title
+	}
+
+	report := models.SearchAnalytics{From: from, To: to}
+	queries := map[string]*models.SearchQueryStat{}
+	documents := map[primitive.ObjectID]*models.SearchDocumentStat{}
+	document := func(id primitive.ObjectID) *models.SearchDocumentStat {
+		if documents[id] == nil {
+			documents[id] = &models.SearchDocumentStat{DocumentID: id, Title: titles[id]}
+		}
+		return documents[id]
+	}
+	var latency int64
+	var searches, clicked, solutions, cited int
+	for _, e := range entries {
+		report.Searches++
+		latency += e.LatencyMs
+
+		q := queries[e.QueryKey]
+		if q == nil {
+			q = &models.SearchQueryStat{Query: e.Query}
+			queries[e.QueryKey] = q
+		}
+		q.Searches++
+		q.AvgResults += float64(e.ResultCount)
+		q.AvgLatencyMs += float64(e.LatencyMs)
+		q.Clicks += len(e.ClickedDocumentIDs)
+		q.Citations += len(e.CitedDocumentIDs)
+		if e.CreatedAt.After(q.LastSearchedAt) {
+			q.LastSearchedAt = e.CreatedAt
+		}
+		if e.ResultCount == 0 {
+			report.ZeroResults++
+			q.ZeroResults++
+		}
+
+		switch e.Source {
+		case models.SearchSourceSearch:
+			searches++
+			if len(e.ClickedDocumentIDs) > 0 {
+				clicked++
+			}
+		case models.SearchSourceSolutions:
+			solutions++
+			if len(e.CitedDocumentIDs) > 0 {
+				cited++
+			}
+		}
+		for _, id := range e.DocumentIDs {
+			document(id).Returned++
+		}
+		for _, id := range e.ClickedDocumentIDs {
+			document(id).Clicks++
+		}
+		for _, id := range e.CitedDocumentIDs {
+			document(id).Citations++
+		}
+	}
+	if report.Searches > 0 {
+		report.AvgLatencyMs = float64(latency) / float64(report.Searches)
+		report.ZeroResultRate = float64(report.ZeroResults) / float64(report.Searches)
+	}
+	if searches > 0 {
+		report.ClickThroughRate = float64(clicked) / float64(searches)
+	}
+	if solutions > 0 {
+		report.CitationRate = float64(cited) / float64(solutions)
+	}
+
+	report.TopQueries = []models.SearchQueryStat{}
+	report.ZeroResultQueries = []models.SearchQueryStat{}
+	for _, q := range queries {
+		q.AvgResults /= float64(q.Searches)
+		q.AvgLatencyMs /= float64(q.Searches)
+		report.TopQueries = append(report.TopQueries, *q)
+		if q.ZeroResults > 0 {
+			report.ZeroResultQueries = append(report.ZeroResultQueries, *q)
+		}
+	}
+	sort.Slice(report.TopQueries, func(i, j int) bool {
+		a, b := report.TopQueries[i], report.TopQueries[j]
+		return a.Searches > b.Searches || (a.Searches == b.Searches && a.Query < b.Query)
+	})
+	sort.Slice(report.ZeroResultQueries, func(i, j int) bool {
+		a, b := report.ZeroResultQueries[i], report.ZeroResultQueries[j]
+		return a.ZeroResults > b.ZeroResults || (a.ZeroResults == b.ZeroResults && a.Query < b.Query)
+	})
+	if len(report.TopQueries) > limit {
+		report.TopQueries = report.TopQueries[:limit]
+	}
+	if len(report.ZeroResultQueries) > limit {
+		report.ZeroResultQueries = report.ZeroResultQueries[:limit]
+	}
+
+	report.TopDocuments = []models.SearchDocumentStat{}
+	for _, d := range documents {
+		report.TopDocuments = append(report.TopDocuments, *d)
+	}
+	sort.Slice(report.TopDocuments, func(i, j int) bool {
+		a, b := report.TopDocuments[i], report.TopDocuments[j]
+		if a.Citations+a.Clicks != b.Citations+b.Clicks {
+			return a.Citations+a.Clicks > b.Citations+b.Clicks
+		}
+		return a.Returned > b.Returned
+	})
+	if len(report.TopDocuments) > limit {
+		report.TopDocuments = report.TopDocuments[:limit]
+	}
+	return report, nil
+}
@@ -0,0 +1,388 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	// MaxServiceNowTickets caps the tickets one export or push may select.
+	MaxServiceNowTickets = 100
+	// serviceNowPullBatch is how many incidents one pull request reads.
+	serviceNowPullBatch = 100
+	// serviceNowCorrelation names this system in the incidents it creates.
+	serviceNowCorrelation = "IntelliOps"
+)
+
+// ErrServiceNowDisabled is returned when no ServiceNow instance is
+// configured.
+var ErrServiceNowDisabled = errors.New("ServiceNow is not configured")
+
+// errServiceNowGone is returned when the incident no longer exists.
+var errServiceNowGone = errors.New("ServiceNow incident not found")
+
+// Incident states of the ServiceNow incident table
+const (
+	serviceNowNew        = "1"
+	serviceNowInProgress = "2"
+	serviceNowOnHold     = "3"
+	serviceNowResolved   = "6"
+	serviceNowClosed     = "7"
+	serviceNowCanceled   = "8"
+)
+
+// serviceNowCategories maps ticket categories to the default incident
+// categories; others are sent as inquiry.
+var serviceNowCategories = map[models.TicketCategory]string{
+	models.CategoryNetwork:  "network",
+	models.CategoryHardware: "hardware",
+	models.CategorySoftware: "software",
+}
+
+// serviceNowImpactUrgency maps ticket priorities to the impact and urgency
+// ServiceNow derives its priority from: critical is P1, high P2, medium
+// P3 and low P4.
+var serviceNowImpactUrgency = map[models.TicketPriority][2]string{
+	models.PriorityCritical: {"1", "1"},
+	models.PriorityHigh:     {"1", "2"},
+	models.PriorityMedium:   {"2", "2"},
+	models.PriorityLow:      {"2", "3"},
+}
+
+// ServiceNowService pushes tickets to a ServiceNow instance as incidents
+// through the Table API and pulls their state changes back, for teams
+// running both systems during a migration.
+type ServiceNowService struct {
+	db          *database.MongoDB
+	instanceURL string
+	username    string
+	password    string
+	client      *http.Client
+}
+
+func NewServiceNowService(db *database.MongoDB, cfg *config.Config) *ServiceNowService {
+	return &ServiceNowService{
+		db:          db,
+		instanceURL: cfg.ServiceNowInstanceURL,
+		username:    cfg.ServiceNowUsername,
+		password:    cfg.ServiceNowPassword,
+		client:      &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Enabled reports whether an instance and credentials are configured.
+func (s *ServiceNowService) Enabled() bool {
+	return s.instanceURL != "" && s.username != "" && s.password != ""
+}
+
+func (s *ServiceNowService) tickets() *mongo.Collection {
+	return s.db.GetCollection("tickets")
+}
+
+// ServiceNowIncident renders a ticket as incident table fields, as pushed
+// and as exported for an import set.
+func ServiceNowIncident(ticket models.Ticket) map[string]string {
+	category, ok := serviceNowCategories[ticket.Category]
+	if !ok {
+		category = "inquiry"
+	}
+	impactUrgency, ok := serviceNowImpactUrgency[ticket.Priority]
+	if !ok {
+		impactUrgency = serviceNowImpactUrgency[models.PriorityMedium]
+	}
+	incident := map[string]string{
+		"short_description":   truncateBytes(ticket.Title, 160),
+		"description":         ticket.Description,
+		"category":            category,
+		"impact":              impactUrgency[0],
+		"urgency":             impactUrgency[1],
+		"state":               serviceNowState(ticket.Status),
+		"correlation_id":      ticket.ID.Hex(),
+		"correlation_display": serviceNowCorrelation,
+	}
+	if ticket.Status == models.StatusResolved || ticket.Status == models.StatusClosed {
+		incident["close_code"] = "Solved (Permanently)"
+		incident["close_notes"] = "Resolved in " + serviceNowCorrelation + " ticket " + ticket.ID.Hex()
+	}
+	return incident
+}
+
+func serviceNowState(status models.TicketStatus) string {
+	switch status {
+	case models.StatusInProgress:
+		return serviceNowInProgress
+	case models.StatusResolved:
+		return serviceNowResolved
+	case models.StatusClosed:
+		return serviceNowClosed
+	default:
+		return serviceNowNew
+	}
+}
+
+// ticketStatusFromServiceNow maps an incident state to a ticket status;
+// false for states it does not know.
+func ticketStatusFromServiceNow(state string) (models.TicketStatus, bool) {
+	switch state {
+	case serviceNowNew:
+		return models.StatusOpen, true
+	case serviceNowInProgress, serviceNowOnHold:
+		return models.StatusInProgress, true
+	case serviceNowResolved:
+		return models.StatusResolved, true
+	case serviceNowClosed, serviceNowCanceled:
+		return models.StatusClosed, true
+	}
+	return "", false
+}
+
+// Find returns the selected tickets that exist, in the order given.
+func (s *ServiceNowService) Find(ctx context.Context, ids []primitive.ObjectID) ([]models.Ticket, error) {
+	cursor, err := s.tickets().Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var found []models.Ticket
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]models.Ticket, len(found))
+	for _, ticket := range found {
+		byID[ticket.ID] = ticket
+	}
+	tickets := []models.Ticket{}
+	for _, id := range ids {
+		if ticket, ok := byID[id]; ok {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets, nil
+}
+
+// Push creates an incident for each selected ticket not pushed yet and
+// updates the incident of those that were. A ticket whose incident was
+// deleted in ServiceNow gets a new one. Failures are reported per ticket.
+func (s *ServiceNowService) Push(ctx context.Context, ids []primitive.ObjectID, userID primitive.ObjectID) (models.ServiceNowPushResult, error) {
+	result := models.ServiceNowPushResult{Links: map[string]string{}, Errors: []models.ServiceNowPushError{}}
+	if !s.Enabled() {
+		return result, ErrServiceNowDisabled
+	}
+	tickets, err := s.Find(ctx, ids)
+	if err != nil {
+		return result, err
+	}
+
+	for _, ticket := range tickets {
+		link, created, err := s.push(ctx, ticket)
+		if err != nil {
+			log.Printf("Failed to push ticket %s to ServiceNow: %v", ticket.ID.Hex(), err)
+			result.Failed++
+			result.Errors = append(result.Errors, models.ServiceNowPushError{TicketID: ticket.ID, Error: err.Error()})
+			continue
+		}
+		now := time.Now()
+		link.PushedAt, link.PushedBy, link.SyncedAt = now, userID, now
+		if _, err := s.tickets().UpdateOne(ctx, bson.M{"_id": ticket.ID}, bson.M{"$set": bson.M{"serviceNow": link}}); err != nil {
+			return result, err
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		result.Links[ticket.ID.Hex()] = link.Number
+	}
+	return result, nil
+}
+
+// push writes one ticket and returns its link and whether the incident was
+// created.
+func (s *ServiceNowService) push(ctx context.Context, ticket models.Ticket) (models.ServiceNowLink, bool, error) {
+	incident := ServiceNowIncident(ticket)
+	if ticket.ServiceNow != nil {
+		var record serviceNowRecord
+		err := s.request(ctx, "PATCH", "/api/now/table/incident/"+url.PathEscape(ticket.ServiceNow.SysID), nil, incident, &record)
+		if err == nil {
+			return record.link(), false, nil
+		}
+		if err != errServiceNowGone {
+			return models.ServiceNowLink{}, false, err
+		}
+	}
+	var record serviceNowRecord
+	if err := s.request(ctx, "POST", "/api/now/table/incident", nil, incident, &record); err != nil {
+		return models.ServiceNowLink{}, false, err
+	}
+	return record.link(), true, nil
+}
+
+// Start pulls incident states every interval.
+func (s *ServiceNowService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if _, err := s.Sync(ctx); err != nil {
+					log.Printf("ServiceNow sync error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Sync pulls the state of every linked incident and applies the ones that
+// changed in ServiceNow since they were last seen to their tickets, so
+// local changes are not overwritten by stale remote states. Status changes
+// are recorded in the ticket history.
+func (s *ServiceNowService) Sync(ctx context.Context) (models.ServiceNowSyncResult, error) {
+	var result models.ServiceNowSyncResult
+	if !s.Enabled() {
+		return result, ErrServiceNowDisabled
+	}
+	cursor, err := s.tickets().Find(ctx, bson.M{"serviceNow.sysId": bson.M{"$exists": true}})
+	if err != nil {
+		return result, err
+	}
+	var linked []models.Ticket
+	if err := cursor.All(ctx, &linked); err != nil {
+		return result, err
+	}
+
+	history := NewTicketHistoryService(s.db)
+	syncUser := models.User{Name: "ServiceNow"}
+	for start := 0; start < len(linked); start += serviceNowPullBatch {
+		batch := linked[start:]
+		if len(batch) > serviceNowPullBatch {
+			batch = batch[:serviceNowPullBatch]
+		}
+		sysIDs := make([]string, len(batch))
+		for i, ticket := range batch {
+			sysIDs[i] = ticket.ServiceNow.SysID
+		}
+		query := url.Values{
+			"sysparm_query":  {"sys_idIN" + strings.Join(sysIDs, ",")},
+			"sysparm_fields": {"sys_id,number,state"},
+			"sysparm_limit":  {fmt.Sprint(len(sysIDs))},
+		}
+		var records []serviceNowRecord
+		if err := s.request(ctx, "GET", "/api/now/table/incident", query, nil, &records); err != nil {
+			return result, err
+		}
+		states := make(map[string]serviceNowRecord, len(records))
+		for _, record := range records {
+			states[record.SysID] = record
+		}
+
+		now := time.Now()
+		for _, ticket := range batch {
+			result.Checked++
+			record, ok := states[ticket.ServiceNow.SysID]
+			if !ok {
+				result.Missing++
+				continue
+			}
+			set := bson.M{"serviceNow.state": record.State, "serviceNow.number": record.Number, "serviceNow.syncedAt": now}
+			update := bson.M{"$set": set}
+			status, known := ticketStatusFromServiceNow(record.State)
+			changed := known && record.State != ticket.ServiceNow.State && status != ticket.Status
+			if changed {
+				// ServiceNow owns the incident, so its state is mirrored
+				// even where the workflow would not allow the move
+				ApplyStatusTransition(update, ticket, status, now)
+				set["updatedAt"] = now
+			}
+			if _, err := s.tickets().UpdateOne(ctx, bson.M{"_id": ticket.ID}, update); err != nil {
+				return result, err
+			}
+			if !changed {
+				continue
+			}
+			result.Updated++
+			after := ticket
+			after.Status = status
+			if err := history.Record(ctx, ticket.ID, syncUser, DiffTicket(ticket, after)); err != nil {
+				log.Printf("Failed to record ServiceNow status change of ticket %s: %v", ticket.ID.Hex(), err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// serviceNowRecord is the part of an incident record the connector reads.
+type serviceNowRecord struct {
+	SysID  string `json:"sys_id"`
+	Number string `json:"number"`
+	State  string `json:"state"`
+}
+
+func (r serviceNowRecord) link() models.ServiceNowLink {
+	return models.ServiceNowLink{SysID: r.SysID, Number: r.Number, State: r.State}
+}
+
+// request calls the Table API with basic authentication and decodes the
+// "result" member of the response into out. A 404 is returned as
+// errServiceNowGone.
+func (s *ServiceNowService) request(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	endpoint := s.instanceURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.username, s.password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	buf.ReadFrom(resp.Body)
+	if resp.StatusCode == http.StatusNotFound {
+		return errServiceNowGone
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("ServiceNow returned %d: %s", resp.StatusCode, truncateBytes(strings.TrimSpace(buf.String()), 200))
+	}
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &envelope); err != nil {
+		return err
+	}
+	return json.Unmarshal(envelope.Result, out)
+}
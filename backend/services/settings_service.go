@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// SettingsService stores the admin settings and applies them to the shared
+// configuration. Services reading the configuration per use pick changes up
+// directly; the others register to be reconfigured.
+type SettingsService struct {
+	db       *database.MongoDB
+	cfg      *config.Config
+	taxonomy *TaxonomyService
+
+	mu        sync.Mutex
+	listeners []func(*config.Config)
+}
+
+func NewSettingsService(db *database.MongoDB, cfg *config.Config) *SettingsService {
+	return &SettingsService{db: db, cfg: cfg, taxonomy: NewTaxonomyService(db)}
+}
+
+// Load applies the stored settings over the environment defaults. It runs
+// at startup, before services read the configuration. In demo mode the AI
+// settings other than the tone and the notification settings are ignored
+// so external services stay mocked.
+func (s *SettingsService) Load(ctx context.Context) error {
+	var stored models.Settings
+	err := s.db.GetCollection("settings").FindOne(ctx, bson.M{"_id": models.SettingsID}).Decode(&stored)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cfg.DemoMode {
+		current := settingsFromConfig(s.cfg)
+		tone := stored.AI.Tone
+		stored.AI = current.AI
+		stored.AI.Tone = tone
+		stored.Notifications = current.Notifications
+	}
+	applySettings(s.cfg, stored)
+	return nil
+}
+
+// OnChange registers fn to be called with the configuration after every
+// update.
+func (s *SettingsService) OnChange(fn func(*config.Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Reload applies the stored settings again, after they were changed outside
+// Save such as by a restore, and reconfigures the registered services.
+func (s *SettingsService) Reload(ctx context.Context) error {
+	if err := s.Load(ctx); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, fn := range s.listeners {
+		fn(s.cfg)
+	}
+	return nil
+}
+
+// Get returns the current settings with secrets removed.
+func (s *SettingsService) Get() models.Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maskSettings(settingsFromConfig(s.cfg))
+}
+
+// Validate merges an update with the current secrets and checks it.
+func (s *SettingsService) Validate(ctx context.Context, req models.UpdateSettingsRequest) (models.Settings, error) {
+	s.mu.Lock()
+	current := settingsFromConfig(s.cfg)
+	s.mu.Unlock()
+
+	settings := models.Settings{AI: req.AI, Anomaly: req.Anomaly, Notifications: req.Notifications, Organization: req.Organization}
+	if settings.AI.OpenAIAPIKey == "" {
+		settings.AI.OpenAIAPIKey = current.AI.OpenAIAPIKey
+	}
+	if settings.Notifications.SMTPPassword == "" {
+		settings.Notifications.SMTPPassword = current.Notifications.SMTPPassword
+	}
+	if settings.Anomaly.PriorityMap == nil {
+		settings.Anomaly.PriorityMap = map[string]models.TicketPriority{}
+	}
+	return settings, validateSettings(s.taxonomy.Get(ctx), settings, s.cfg.DemoMode)
+}
+
+func validateSettings(taxonomy models.Taxonomy, st models.Settings, demoMode bool) error {
+	switch st.AI.Provider {
+	case "openai":
+		if st.AI.OpenAIAPIKey == "" {
+			return errors.New("ai.openAIApiKey is required for the openai provider")
+		}
+		if st.AI.OpenAIModel == "" {
+			return errors.New("ai.openAIModel is required for the openai provider")
+		}
+	case "local":
+		if err := validateHTTPURL(st.AI.LocalLLMURL); err != nil {
+			return fmt.Errorf("ai.localLlmUrl: %v", err)
+		}
+	case "mock":
+	default:
+		return errors.New("ai.provider must be openai, local or mock")
+	}
+	if _, ok := aiTones[st.AI.Tone]; !ok {
+		return errors.New("ai.tone must be concise, detailed, friendly, formal or empty")
+	}
+
+	if st.Anomaly.DefaultZScore <= 0 {
+		return errors.New("anomaly.defaultZScore must be positive")
+	}
+	if st.Anomaly.RecoveryPoints < 1 {
+		return errors.New("anomaly.recoveryPoints must be at least 1")
+	}
+	if err := ValidateAnomalyPolicy(taxonomy, &st.Anomaly.SeverityThresholds, st.Anomaly.PriorityMap); err != nil {
+		return fmt.Errorf("anomaly: %v", err)
+	}
+
+	n := st.Notifications
+	for i, u := range n.WebhookURLs {
+		if err := validateHTTPURL(u); err != nil {
+			return fmt.Errorf("notifications.webhookUrls[%d]: %v", i, err)
+		}
+	}
+	if n.SMTPHost != "" {
+		if n.SMTPPort < 1 || n.SMTPPort > 65535 {
+			return errors.New("notifications.smtpPort must be between 1 and 65535")
+		}
+		if _, err := mail.ParseAddress(n.SMTPFrom); err != nil {
+			return errors.New("notifications.smtpFrom must be an email address")
+		}
+	}
+	for i, r := range n.DigestRecipients {
+		if _, err := mail.ParseAddress(r); err != nil {
+			return fmt.Errorf("notifications.digestRecipients[%d] must be an email address", i)
+		}
+	}
+
+	org := st.Organization
+	if len(org.CompanyName) > maxProfileValueLength {
+		return fmt.Errorf("organization.companyName must be at most %d characters", maxProfileValueLength)
+	}
+	for name, values := range map[string][]string{
+		"supportedOs":        org.SupportedOS,
+		"standardTools":      org.StandardTools,
+		"escalationContacts": org.EscalationContacts,
+	} {
+		if len(values) > maxProfileValues {
+			return fmt.Errorf("organization.%s can have at most %d entries", name, maxProfileValues)
+		}
+		for i, value := range values {
+			if strings.TrimSpace(value) == "" || len(value) > maxProfileValueLength {
+				return fmt.Errorf("organization.%s[%d] must be 1-%d characters", name, i, maxProfileValueLength)
+			}
+		}
+	}
+
+	if demoMode && (st.AI.Provider != "mock" || len(n.WebhookURLs) > 0 || n.SMTPHost != "") {
+		return errors.New("external AI providers and notifications cannot be enabled in demo mode")
+	}
+	return nil
+}
+
+func validateHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an http or https URL")
+	}
+	return nil
+}
+
+// Save stores validated settings, records the changes in the audit trail,
+// applies them and notifies the registered services. Saving unchanged
+// settings does nothing.
+func (s *SettingsService) Save(ctx context.Context, settings models.Settings, user models.User) (models.Settings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes := diffSettings(settingsFromConfig(s.cfg), settings)
+	if len(changes) == 0 {
+		return maskSettings(settingsFromConfig(s.cfg)), nil
+	}
+
+	now := time.Now()
+	settings.ID = models.SettingsID
+	settings.UpdatedAt = now
+	settings.UpdatedBy = user.ID
+	_, err := s.db.GetCollection("settings").ReplaceOne(ctx, bson.M{"_id": models.SettingsID}, settings, options.Replace().SetUpsert(true))
+	if err != nil {
+		return models.Settings{}, err
+	}
+	entry := models.SettingsAuditEntry{
+		ID:            primitive.NewObjectID(),
+		ChangedBy:     user.ID,
+		ChangedByName: user.Name,
+		Changes:       changes,
+		CreatedAt:     now,
+	}
+	if _, err := s.db.GetCollection("settings_audit").InsertOne(ctx, entry); err != nil {
+		log.Printf("Failed to record settings change by %s: %v", user.Email, err)
+	}
+
+	applySettings(s.cfg, settings)
+	for _, fn := range s.listeners {
+		fn(s.cfg)
+	}
+	log.Printf("Settings updated by %s: %d change(s)", user.Email, len(changes))
+	return maskSettings(settings), nil
+}
+
+// Audit returns the latest limit settings changes, newest first.
+func (s *SettingsService) Audit(ctx context.Context, limit int) ([]models.SettingsAuditEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := s.db.GetCollection("settings_audit").Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	entries := []models.SettingsAuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func settingsFromConfig(cfg *config.Config) models.Settings {
+	priorities := map[string]models.TicketPriority{}
+	for severity, priority := range cfg.AnomalyPriorityMap {
+		priorities[severity] = models.TicketPriority(priority)
+	}
+	return models.Settings{
+		AI: models.AISettings{
+			Provider:     cfg.AIProvider,
+			OpenAIModel:  cfg.OpenAIModel,
+			OpenAIAPIKey: cfg.OpenAIAPIKey,
+			LocalLLMURL:  cfg.LocalLLMURL,
+			Tone:         cfg.AITone,
+		},
+		Anomaly: models.AnomalySettings{
+			CreateTickets: cfg.AnomalyCreateTickets,
+			DefaultZScore: cfg.MonitorDefaultZScore,
+			SeverityThresholds: models.SeverityThresholds{
+				Medium:   cfg.AnomalyMediumZScore,
+				High:     cfg.AnomalyHighZScore,
+				Critical: cfg.AnomalyCriticalZScore,
+			},
+			PriorityMap:    priorities,
+			RecoveryPoints: cfg.AnomalyRecoveryPoints,
+			ResolveTickets: cfg.AnomalyResolveTickets,
+		},
+		Notifications: models.NotificationSettings{
+			WebhookURLs:      cfg.NotifyWebhookURLs,
+			SMTPHost:         cfg.SMTPHost,
+			SMTPPort:         cfg.SMTPPort,
+			SMTPUsername:     cfg.SMTPUsername,
+			SMTPPassword:     cfg.SMTPPassword,
+			SMTPFrom:         cfg.SMTPFrom,
+			DigestRecipients: cfg.DigestRecipients,
+		},
+		Organization: models.OrganizationSettings{
+			CompanyName:        cfg.OrgCompanyName,
+			SupportedOS:        cfg.OrgSupportedOS,
+			StandardTools:      cfg.OrgStandardTools,
+			EscalationContacts: cfg.OrgEscalationContacts,
+		},
+	}
+}
+
+func applySettings(cfg *config.Config, st models.Settings) {
+	cfg.AIProvider = st.AI.Provider
+	cfg.OpenAIModel = st.AI.OpenAIModel
+	cfg.OpenAIAPIKey = st.AI.OpenAIAPIKey
+	cfg.LocalLLMURL = st.AI.LocalLLMURL
+	cfg.AITone = st.AI.Tone
+
+	priorities := map[string]string{}
+	for severity, priority := range st.Anomaly.PriorityMap {
+		priorities[severity] = string(priority)
+	}
+	cfg.AnomalyCreateTickets = st.Anomaly.CreateTickets
+	cfg.MonitorDefaultZScore = st.Anomaly.DefaultZScore
+	cfg.AnomalyMediumZScore = st.Anomaly.SeverityThresholds.Medium
+	cfg.AnomalyHighZScore = st.Anomaly.SeverityThresholds.High
+	cfg.AnomalyCriticalZScore = st.Anomaly.SeverityThresholds.Critical
+	cfg.AnomalyPriorityMap = priorities
+	cfg.AnomalyRecoveryPoints = st.Anomaly.RecoveryPoints
+	cfg.AnomalyResolveTickets = st.Anomaly.ResolveTickets
+
+	cfg.NotifyWebhookURLs = st.Notifications.WebhookURLs
+	cfg.SMTPHost = st.Notifications.SMTPHost
+	cfg.SMTPPort = st.Notifications.SMTPPort
+	cfg.SMTPUsername = st.Notifications.SMTPUsername
+	cfg.SMTPPassword = st.Notifications.SMTPPassword
+	cfg.SMTPFrom = st.Notifications.SMTPFrom
+	cfg.DigestRecipients = st.Notifications.DigestRecipients
+
+	cfg.OrgCompanyName = st.Organization.CompanyName
+	cfg.OrgSupportedOS = st.Organization.SupportedOS
+	cfg.OrgStandardTools = st.Organization.StandardTools
+	cfg.OrgEscalationContacts = st.Organization.EscalationContacts
+}
+
+func maskSettings(st models.Settings) models.Settings {
+	st.AI.OpenAIAPIKeySet = st.AI.OpenAIAPIKey != ""
+	st.AI.OpenAIAPIKey = ""
+	st.Notifications.SMTPPasswordSet = st.Notifications.SMTPPassword != ""
+	st.Notifications.SMTPPassword = ""
+	return st
+}
+
+// settingValue is a setting as compared (value) and as shown in the audit
+// trail (display).
+type settingValue struct {
+	name, value, display string
+}
+
+func settingValues(st models.Settings) []settingValue {
+	plain := func(name, value string) settingValue { return settingValue{name, value, value} }
+	secret := func(name, value string) settingValue {
+		display := ""
+		if value != "" {
+			display = "********"
+		}
+		return settingValue{name, value, display}
+	}
+	float := func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+
+	severities := make([]string, 0, len(st.Anomaly.PriorityMap))
+	for severity := range st.Anomaly.PriorityMap {
+		severities = append(severities, severity)
+	}
+	sort.Strings(severities)
+	priorities := make([]string, len(severities))
+	for i, severity := range severities {
+		priorities[i] = severity + "=" + string(st.Anomaly.PriorityMap[severity])
+	}
+	hosts := make([]string, len(st.Notifications.WebhookURLs))
+	for i, u := range st.Notifications.WebhookURLs {
+		hosts[i] = "(invalid)"
+		if parsed, err := url.Parse(u); err == nil {
+			hosts[i] = parsed.Host
+		}
+	}
+
+	return []settingValue{
+		plain("ai.provider", st.AI.Provider),
+		plain("ai.openAIModel", st.AI.OpenAIModel),
+		secret("ai.openAIApiKey", st.AI.OpenAIAPIKey),
+		plain("ai.localLlmUrl", st.AI.LocalLLMURL),
+		plain("ai.tone", st.AI.Tone),
+		plain("anomaly.createTickets", strconv.FormatBool(st.Anomaly.CreateTickets)),
+		plain("anomaly.defaultZScore", float(st.Anomaly.DefaultZScore)),
+		plain("anomaly.severityThresholds.medium", float(st.Anomaly.SeverityThresholds.Medium)),
+		plain("anomaly.severityThresholds.high", float(st.Anomaly.SeverityThresholds.High)),
+		plain("anomaly.severityThresholds.critical", float(st.Anomaly.SeverityThresholds.Critical)),
+		plain("anomaly.priorityMap", strings.Join(priorities, ", ")),
+		plain("anomaly.recoveryPoints", strconv.Itoa(st.Anomaly.RecoveryPoints)),
+		plain("anomaly.resolveTickets", strconv.FormatBool(st.Anomaly.ResolveTickets)),
+		{"notifications.webhookUrls", strings.Join(st.Notifications.WebhookURLs, ", "), strings.Join(hosts, ", ")},
+		plain("notifications.smtpHost", st.Notifications.SMTPHost),
+		plain("notifications.smtpPort", strconv.Itoa(st.Notifications.SMTPPort)),
+		plain("notifications.smtpUsername", st.Notifications.SMTPUsername),
+		secret("notifications.smtpPassword", st.Notifications.SMTPPassword),
+		plain("notifications.smtpFrom", st.Notifications.SMTPFrom),
+		plain("notifications.digestRecipients", strings.Join(st.Notifications.DigestRecipients, ", ")),
+		plain("organization.companyName", st.Organization.CompanyName),
+		plain("organization.supportedOs", strings.Join(st.Organization.SupportedOS, ", ")),
+		plain("organization.standardTools", strings.Join(st.Organization.StandardTools, ", ")),
+		plain("organization.escalationContacts", strings.Join(st.Organization.EscalationContacts, ", ")),
+	}
+}
+
+func diffSettings(prev, next models.Settings) []models.SettingsChange {
+	changes := []models.SettingsChange{}
+	oldValues, newValues := settingValues(prev), settingValues(next)
+	for i := range oldValues {
+		if oldValues[i].value == newValues[i].value {
+			continue
+		}
+		change := models.SettingsChange{Setting: oldValues[i].name, Old: oldValues[i].display, New: newValues[i].display}
+		if change.Old == change.New {
+			change.New += " (changed)"
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
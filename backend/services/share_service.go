@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var (
+	ErrShareLinkInvalid = errors.New("invalid share link")
+	ErrShareLinkExpired = errors.New("share link has expired or was revoked")
+)
+
+// ShareService issues signed, time-limited read-only ticket links. A token
+// is "<linkID>.<expiry>.<signature>"; the link record is still checked on
+// every view so links can be revoked before they expire.
+type ShareService struct {
+	db         *database.MongoDB
+	comments   *CommentService
+	archive    *TicketArchiveService
+	secret     []byte
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+func NewShareService(db *database.MongoDB, comments *CommentService, archive *TicketArchiveService, cfg *config.Config) *ShareService {
+	return &ShareService{
+		db:         db,
+		comments:   comments,
+		archive:    archive,
+		secret:     []byte(cfg.JWTSecret),
+		defaultTTL: cfg.ShareLinkDefaultTTL,
+		maxTTL:     cfg.ShareLinkMaxTTL,
+	}
+}
+
+// ShareAccess identifies who performed an audited action.
+type ShareAccess struct {
+	UserID    *primitive.ObjectID
+	IP        string
+	UserAgent string
+}
+
+// ParseShareTTL returns the requested lifetime, or the default when empty.
+func (s *ShareService) ParseShareTTL(expiresIn string) (time.Duration, error) {
+	return parseLinkTTL(expiresIn, s.defaultTTL, s.maxTTL)
+}
+
+// parseLinkTTL parses the lifetime of a signed link, bounded by maxTTL.
+func parseLinkTTL(expiresIn string, defaultTTL, maxTTL time.Duration) (time.Duration, error) {
+	if expiresIn == "" {
+		return defaultTTL, nil
+	}
+	ttl, err := time.ParseDuration(expiresIn)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expiresIn: %v", err)
+	}
+	if ttl <= 0 || ttl > maxTTL {
+		return 0, fmt.Errorf("expiresIn must be between 0 and %s", maxTTL)
+	}
+	return ttl, nil
+}
+
+// Create stores a share link for a ticket and returns it with its token.
+func (s *ShareService) Create(ctx context.Context, ticketID primitive.ObjectID, ttl time.Duration, note string, access ShareAccess) (models.TicketShareLink, string, error) {
+	count, err := s.db.GetCollection("tickets").CountDocuments(ctx, bson.M{"_id": ticketID})
+	if err != nil {
+		return models.TicketShareLink{}, "", err
+	}
+	if count == 0 {
+		return models.TicketShareLink{}, "", mongo.ErrNoDocuments
+	}
+
+	now := time.Now()
+	link := models.TicketShareLink{
+		ID:        primitive.NewObjectID(),
+		TicketID:  ticketID,
+		Note:      note,
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+		CreatedBy: *access.UserID,
+		CreatedAt: now,
+	}
+	if _, err := s.db.GetCollection("ticket_share_links").InsertOne(ctx, link); err != nil {
+		return models.TicketShareLink{}, "", err
+	}
+	s.audit(ctx, link, models.ShareCreated, access)
+	return link, s.sign(link.ID, link.ExpiresAt), nil
+}
+
+func (s *ShareService) List(ctx context.Context, ticketID primitive.ObjectID) ([]models.TicketShareLink, error) {
+	cursor, err := s.db.GetCollection("ticket_share_links").Find(ctx, bson.M{"ticketId": ticketID},
+		options.Find().SetSort(bson.D{{"createdAt", -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	links := []models.TicketShareLink{}
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// Revoke disables a link immediately. Revoking twice is a no-op.
+func (s *ShareService) Revoke(ctx context.Context, ticketID, linkID primitive.ObjectID, access ShareAccess) (models.TicketShareLink, error) {
+	var link models.TicketShareLink
+	err := s.db.GetCollection("ticket_share_links").FindOne(ctx, bson.M{"_id": linkID, "ticketId": ticketID}).Decode(&link)
+	if err != nil {
+		return link, err
+	}
+	if link.RevokedAt != nil {
+		return link, nil
+	}
+
+	now := time.Now()
+	link.RevokedAt = &now
+	link.RevokedBy = access.UserID
+	if _, err := s.db.GetCollection("ticket_share_links").UpdateByID(ctx, linkID,
+		bson.M{"$set": bson.M{"revokedAt": now, "revokedBy": access.UserID}}); err != nil {
+		return link, err
+	}
+	s.audit(ctx, link, models.ShareRevoked, access)
+	return link, nil
+}
+
+// Audit returns the audit trail of a link, newest first.
+func (s *ShareService) Audit(ctx context.Context, ticketID, linkID primitive.ObjectID) ([]models.ShareAuditEntry, error) {
+	cursor, err := s.db.GetCollection("ticket_share_audit").Find(ctx, bson.M{"linkId": linkID, "ticketId": ticketID},
+		options.Find().SetSort(bson.D{{"createdAt", -1}}).SetLimit(500))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.ShareAuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// View verifies a token and returns the sanitized ticket it grants access to.
+func (s *ShareService) View(ctx context.Context, token string, access ShareAccess) (models.SharedTicket, error) {
+	linkID, expiresAt, err := s.verify(token)
+	if err != nil {
+		return models.SharedTicket{}, err
+	}
+
+	var link models.TicketShareLink
+	if err := s.db.GetCollection("ticket_share_links").FindOne(ctx, bson.M{"_id": linkID}).Decode(&link); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.SharedTicket{}, ErrShareLinkInvalid
+		}
+		return models.SharedTicket{}, err
+	}
+	if link.RevokedAt != nil || time.Now().After(expiresAt) || !link.ExpiresAt.Equal(expiresAt) {
+		s.audit(ctx, link, models.ShareDenied, access)
+		return models.SharedTicket{}, ErrShareLinkExpired
+	}
+
+	ticket, err := s.archive.Get(ctx, link.TicketID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.SharedTicket{}, ErrShareLinkInvalid
+		}
+		return models.SharedTicket{}, err
+	}
+
+	comments, err := s.comments.List(ctx, ticket.ID, false)
+	if err != nil {
+		return models.SharedTicket{}, err
+	}
+	shared := make([]models.SharedComment, 0, len(comments))
+	for _, comment := range comments {
+		shared = append(shared, models.SharedComment{AuthorName: comment.AuthorName, Body: comment.Body, CreatedAt: comment.CreatedAt})
+	}
+
+	now := time.Now()
+	if _, err := s.db.GetCollection("ticket_share_links").UpdateByID(ctx, link.ID,
+		bson.M{"$inc": bson.M{"viewCount": 1}, "$set": bson.M{"lastViewedAt": now}}); err != nil {
+		log.Printf("Failed to record share link view %s: %v", link.ID.Hex(), err)
+	}
+	s.audit(ctx, link, models.ShareViewed, access)
+
+	return models.SharedTicket{
+		Title:       ticket.Title,
+		Description: ticket.Description,
+		Category:    ticket.Category,
+		Priority:    ticket.Priority,
+		Status:      ticket.Status,
+		CreatedAt:   ticket.CreatedAt,
+		UpdatedAt:   ticket.UpdatedAt,
+		ResolvedAt:  ticket.ResolvedAt,
+		Comments:    shared,
+		ExpiresAt:   link.ExpiresAt,
+	}, nil
+}
+
+func (s *ShareService) audit(ctx context.Context, link models.TicketShareLink, action models.ShareAuditAction, access ShareAccess) {
+	entry := models.ShareAuditEntry{
+		ID:        primitive.NewObjectID(),
+		LinkID:    link.ID,
+		TicketID:  link.TicketID,
+		Action:    action,
+		UserID:    access.UserID,
+		IP:        access.IP,
+		UserAgent: access.UserAgent,
+		CreatedAt: time.Now(),
+	}
+	if _, err := s.db.GetCollection("ticket_share_audit").InsertOne(ctx, entry); err != nil {
+		log.Printf("Failed to write share audit entry for link %s: %v", link.ID.Hex(), err)
+	}
+}
+
+func (s *ShareService) sign(linkID primitive.ObjectID, expiresAt time.Time) string {
+	payload := linkID.Hex() + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *ShareService) verify(token string) (primitive.ObjectID, time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return primitive.NilObjectID, time.Time{}, ErrShareLinkInvalid
+	}
+	linkID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil {
+		return primitive.NilObjectID, time.Time{}, ErrShareLinkInvalid
+	}
+	unix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return primitive.NilObjectID, time.Time{}, ErrShareLinkInvalid
+	}
+	expiresAt := time.Unix(unix, 0)
+	if !hmac.Equal([]byte(s.sign(linkID, expiresAt)), []byte(token)) {
+		return primitive.NilObjectID, time.Time{}, ErrShareLinkInvalid
+	}
+	return linkID, expiresAt, nil
+}
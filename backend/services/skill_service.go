@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	skillHistoryDays = 180
+	// skillPriorWeight and skillPriorScore shrink scores of technicians with
+	// few resolutions towards a cautious default.
+	skillPriorWeight = 5.0
+	skillPriorScore  = 0.3
+)
+
+// SkillService infers technician skill profiles from resolution history.
+type SkillService struct {
+	db *database.MongoDB
+}
+
+func NewSkillService(db *database.MongoDB) *SkillService {
+	return &SkillService{db: db}
+}
+
+// Start recomputes profiles on the given interval.
+func (s *SkillService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if err := s.Recompute(ctx); err != nil {
+					log.Printf("skill inference error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Recompute rebuilds every technician's profile from the last 180 days of
+// assigned tickets, keeping admin overrides.
+func (s *SkillService) Recompute(ctx context.Context) error {
+	cursor, err := s.db.GetCollection("users").Find(ctx, bson.M{"role": models.RoleTechnician})
+	if err != nil {
+		return err
+	}
+	var technicians []models.User
+	err = cursor.All(ctx, &technicians)
+	cursor.Close(ctx)
+	if err != nil {
+		return err
+	}
+
+	since := time.Now().AddDate(0, 0, -skillHistoryDays)
+	cursor, err = s.db.GetCollection("tickets").Find(ctx,
+		bson.M{"assignedTo": bson.M{"$exists": true, "$ne": nil}, "createdAt": bson.M{"$gte": since}},
+		options.Find().SetProjection(bson.M{"assignedTo": 1, "category": 1, "status": 1, "createdAt": 1, "resolvedAt": 1}))
+	if err != nil {
+		return err
+	}
+	var tickets []models.Ticket
+	err = cursor.All(ctx, &tickets)
+	cursor.Close(ctx)
+	if err != nil {
+		return err
+	}
+
+	type stats struct {
+		assigned, resolved int64
+		hours              []float64
+	}
+	byTech := map[primitive.ObjectID]map[models.TicketCategory]*stats{}
+	categoryHours := map[models.TicketCategory][]float64{}
+	for _, t := range tickets {
+		if byTech[*t.AssignedTo] == nil {
+			byTech[*t.AssignedTo] = map[models.TicketCategory]*stats{}
+		}
+		st := byTech[*t.AssignedTo][t.Category]
+		if st == nil {
+			st = &stats{}
+			byTech[*t.AssignedTo][t.Category] = st
+		}
+		st.assigned++
+		if (t.Status == models.StatusResolved || t.Status == models.StatusClosed) && t.ResolvedAt != nil {
+			hours := t.ResolvedAt.Sub(t.CreatedAt).Hours()
+			st.resolved++
+			st.hours = append(st.hours, hours)
+			categoryHours[t.Category] = append(categoryHours[t.Category], hours)
+		}
+	}
+
+	for _, tech := range technicians {
+		var existing models.SkillProfile
+		err := s.db.GetCollection("technician_skills").FindOne(ctx, bson.M{"_id": tech.ID}).Decode(&existing)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+
+		var skills []models.CategorySkill
+		for category, st := range byTech[tech.ID] {
+			skill := models.CategorySkill{
+				Category:              category,
+				Assigned:              st.assigned,
+				Resolved:              st.resolved,
+				SuccessRate:           round2(float64(st.resolved) / float64(st.assigned)),
+				MedianResolutionHours: round2(median(st.hours)),
+			}
+			// Speed compares this technician's median with everyone's for the
+			// category: 0.5 is average, 1 is twice as fast or better.
+			speed := 0.0
+			if st.resolved > 0 {
+				speed = math.Min(median(categoryHours[category])/math.Max(median(st.hours), 0.1), 2) / 2
+			}
+			raw := 0.6*skill.SuccessRate + 0.4*speed
+			skill.InferredScore = math.Round((float64(st.resolved)*raw+skillPriorWeight*skillPriorScore)/(float64(st.resolved)+skillPriorWeight)*1000) / 1000
+			skills = append(skills, skill)
+		}
+
+		profile := models.SkillProfile{
+			TechnicianID:   tech.ID,
+			TechnicianName: tech.Name,
+			Skills:         skills,
+			Overrides:      existing.Overrides,
+			ComputedAt:     time.Now(),
+		}
+		applySkillOverrides(&profile)
+
+		_, err = s.db.GetCollection("technician_skills").ReplaceOne(ctx, bson.M{"_id": tech.ID}, profile, options.Replace().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applySkillOverrides sets each skill's effective score, adding entries for
+// categories that only exist as overrides, and sorts skills by score.
+func applySkillOverrides(profile *models.SkillProfile) {
+	if profile.Overrides == nil {
+		profile.Overrides = []models.SkillOverride{}
+	}
+	for i := range profile.Skills {
+		profile.Skills[i].Score = profile.Skills[i].InferredScore
+		profile.Skills[i].Overridden = false
+	}
+	for _, o := range profile.Overrides {
+		idx := -1
+		for i := range profile.Skills {
+			if profile.Skills[i].Category == o.Category {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			profile.Skills = append(profile.Skills, models.CategorySkill{Category: o.Category})
+			idx = len(profile.Skills) - 1
+		}
+		skill := &profile.Skills[idx]
+		skill.Overridden = true
+		switch {
+		case o.Excluded:
+			skill.Score = 0
+		case o.Score != nil:
+			skill.Score = *o.Score
+		}
+	}
+	if profile.Skills == nil {
+		profile.Skills = []models.CategorySkill{}
+	}
+	sort.Slice(profile.Skills, func(i, j int) bool { return profile.Skills[i].Score > profile.Skills[j].Score })
+}
+
+func (s *SkillService) List(ctx context.Context) ([]models.SkillProfile, error) {
+	cursor, err := s.db.GetCollection("technician_skills").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{"technicianName", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	profiles := []models.SkillProfile{}
+	if err := cursor.All(ctx, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func (s *SkillService) Get(ctx context.Context, technicianID primitive.ObjectID) (models.SkillProfile, error) {
+	var profile models.SkillProfile
+	err := s.db.GetCollection("technician_skills").FindOne(ctx, bson.M{"_id": technicianID}).Decode(&profile)
+	return profile, err
+}
+
+// SetOverride adds or replaces the admin override for one category.
+func (s *SkillService) SetOverride(ctx context.Context, technicianID primitive.ObjectID, req models.SetSkillOverrideRequest, setBy primitive.ObjectID) (models.SkillProfile, error) {
+	if req.Score == nil && !req.Excluded {
+		return models.SkillProfile{}, fmt.Errorf("set a score or exclude the category")
+	}
+	if req.Score != nil && (*req.Score < 0 || *req.Score > 1) {
+		return models.SkillProfile{}, fmt.Errorf("score must be between 0 and 1")
+	}
+
+	profile, err := s.profileFor(ctx, technicianID)
+	if err != nil {
+		return models.SkillProfile{}, err
+	}
+
+	override := models.SkillOverride{
+		Category: req.Category,
+		Score:    req.Score,
+		Excluded: req.Excluded,
+		Note:     req.Note,
+		SetBy:    setBy,
+		SetAt:    time.Now(),
+	}
+	replaced := false
+	for i := range profile.Overrides {
+		if profile.Overrides[i].Category == req.Category {
+			profile.Overrides[i] = override
+			replaced = true
+		}
+	}
+	if !replaced {
+		profile.Overrides = append(profile.Overrides, override)
+	}
+	return profile, s.save(ctx, profile)
+}
+
+// RemoveOverride drops the override for a category so the inferred score
+// applies again.
+func (s *SkillService) RemoveOverride(ctx context.Context, technicianID primitive.ObjectID, category models.TicketCategory) (models.SkillProfile, error) {
+	profile, err := s.profileFor(ctx, technicianID)
+	if err != nil {
+		return models.SkillProfile{}, err
+	}
+
+	overrides := []models.SkillOverride{}
+	for _, o := range profile.Overrides {
+		if o.Category != category {
+			overrides = append(overrides, o)
+		}
+	}
+	profile.Overrides = overrides
+
+	// Skills that only existed because of the override go away with it
+	skills := []models.CategorySkill{}
+	for _, skill := range profile.Skills {
+		if skill.Category != category || skill.Assigned > 0 {
+			skills = append(skills, skill)
+		}
+	}
+	profile.Skills = skills
+	return profile, s.save(ctx, profile)
+}
+
+// profileFor loads a technician's profile, starting an empty one for
+// technicians who have not been profiled yet.
+func (s *SkillService) profileFor(ctx context.Context, technicianID primitive.ObjectID) (models.SkillProfile, error) {
+	profile, err := s.Get(ctx, technicianID)
+	if err == nil {
+		return profile, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return models.SkillProfile{}, err
+	}
+
+	var tech models.User
+	if err := s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": technicianID, "role": models.RoleTechnician}).Decode(&tech); err != nil {
+		return models.SkillProfile{}, err
+	}
+	return models.SkillProfile{TechnicianID: tech.ID, TechnicianName: tech.Name, ComputedAt: time.Now()}, nil
+}
+
+func (s *SkillService) save(ctx context.Context, profile models.SkillProfile) error {
+	applySkillOverrides(&profile)
+	_, err := s.db.GetCollection("technician_skills").ReplaceOne(ctx, bson.M{"_id": profile.TechnicianID}, profile, options.Replace().SetUpsert(true))
+	return err
+}
+
+// RankTechnicians orders technicians by effective score for a category,
+// skipping excluded ones. Used by assignment to prefer skilled technicians.
+func (s *SkillService) RankTechnicians(ctx context.Context, category models.TicketCategory) ([]models.RankedTechnician, error) {
+	profiles, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := []models.RankedTechnician{}
+	for _, p := range profiles {
+		for _, skill := range p.Skills {
+			if skill.Category != category || skill.Score <= 0 {
+				continue
+			}
+			ranked = append(ranked, models.RankedTechnician{
+				TechnicianID:   p.TechnicianID,
+				TechnicianName: p.TechnicianName,
+				Score:          skill.Score,
+			})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+	return ranked, nil
+}
+
+func median(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, xs...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// snmpTrap is a decoded SNMP v1 or v2c trap (or inform).
+type snmpTrap struct {
+	Version   string
+	Community string
+	TrapOID   string
+	AgentAddr string
+	Varbinds  map[string]string
+}
+
+const oidSnmpTrapOID = "1.3.6.1.6.3.1.1.4.1.0"
+
+// BER tags used by SNMP PDUs.
+const (
+	tagTrapV1  = 4
+	tagTrapV2  = 7
+	tagInform  = 6
+	tagIPAddr  = 0
+	tagCounter = 1
+	tagGauge   = 2
+	tagTicks   = 3
+	tagCount64 = 6
+)
+
+// parseSNMPTrap decodes the trap PDU of an SNMP v1/v2c message.
+func parseSNMPTrap(packet []byte) (snmpTrap, error) {
+	var msg asn1.RawValue
+	if _, err := asn1.Unmarshal(packet, &msg); err != nil {
+		return snmpTrap{}, err
+	}
+	parts, err := berChildren(msg.Bytes)
+	if err != nil || len(parts) < 3 {
+		return snmpTrap{}, fmt.Errorf("not an SNMP message")
+	}
+
+	trap := snmpTrap{Varbinds: map[string]string{}}
+	switch berInt(parts[0].Bytes) {
+	case 0:
+		trap.Version = "v1"
+	case 1:
+		trap.Version = "v2c"
+	default:
+		return snmpTrap{}, fmt.Errorf("unsupported SNMP version")
+	}
+	trap.Community = string(parts[1].Bytes)
+
+	pdu := parts[2]
+	if pdu.Class != asn1.ClassContextSpecific {
+		return snmpTrap{}, fmt.Errorf("unexpected PDU")
+	}
+	fields, err := berChildren(pdu.Bytes)
+	if err != nil {
+		return snmpTrap{}, err
+	}
+
+	var varbinds asn1.RawValue
+	switch pdu.Tag {
+	case tagTrapV1:
+		// enterprise, agent-addr, generic-trap, specific-trap, time-stamp, varbinds
+		if len(fields) < 6 {
+			return snmpTrap{}, fmt.Errorf("short v1 trap")
+		}
+		enterprise := berOID(fields[0].Bytes)
+		trap.AgentAddr = net.IP(fields[1].Bytes).String()
+		generic, specific := berInt(fields[2].Bytes), berInt(fields[3].Bytes)
+		if generic < 6 {
+			trap.TrapOID = fmt.Sprintf("1.3.6.1.6.3.1.1.5.%d", generic+1)
+		} else {
+			trap.TrapOID = fmt.Sprintf("%s.0.%d", enterprise, specific)
+		}
+		varbinds = fields[5]
+	case tagTrapV2, tagInform:
+		// request-id, error-status, error-index, varbinds
+		if len(fields) < 4 {
+			return snmpTrap{}, fmt.Errorf("short v2 trap")
+		}
+		varbinds = fields[3]
+	default:
+		return snmpTrap{}, fmt.Errorf("PDU type %d is not a trap", pdu.Tag)
+	}
+
+	bindings, err := berChildren(varbinds.Bytes)
+	if err != nil {
+		return snmpTrap{}, err
+	}
+	for _, binding := range bindings {
+		pair, err := berChildren(binding.Bytes)
+		if err != nil || len(pair) != 2 {
+			continue
+		}
+		oid := berOID(pair[0].Bytes)
+		value := berValue(pair[1])
+		if oid == oidSnmpTrapOID {
+			trap.TrapOID = value
+			continue
+		}
+		trap.Varbinds[oid] = value
+	}
+	if trap.TrapOID == "" {
+		return snmpTrap{}, fmt.Errorf("trap has no snmpTrapOID")
+	}
+	return trap, nil
+}
+
+func berChildren(data []byte) ([]asn1.RawValue, error) {
+	children := []asn1.RawValue{}
+	for len(data) > 0 {
+		var child asn1.RawValue
+		rest, err := asn1.Unmarshal(data, &child)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+		data = rest
+	}
+	return children, nil
+}
+
+// berInt decodes a two's complement big-endian integer.
+func berInt(b []byte) int64 {
+	var n int64
+	for i, c := range b {
+		if i == 0 && c&0x80 != 0 {
+			n = -1
+		}
+		n = n<<8 | int64(c)
+	}
+	return n
+}
+
+func berUint(b []byte) uint64 {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n
+}
+
+func berOID(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	parts := []string{}
+	var n uint64
+	for _, c := range b {
+		n = n<<7 | uint64(c&0x7f)
+		if c&0x80 != 0 {
+			continue
+		}
+		if len(parts) == 0 {
+			first := n / 40
+			if first > 2 {
+				first = 2
+			}
+			parts = append(parts, strconv.FormatUint(first, 10), strconv.FormatUint(n-first*40, 10))
+		} else {
+			parts = append(parts, strconv.FormatUint(n, 10))
+		}
+		n = 0
+	}
+	return strings.Join(parts, ".")
+}
+
+// berValue renders a varbind value as text.
+func berValue(v asn1.RawValue) string {
+	switch v.Class {
+	case asn1.ClassUniversal:
+		switch v.Tag {
+		case asn1.TagInteger:
+			return strconv.FormatInt(berInt(v.Bytes), 10)
+		case asn1.TagOctetString:
+			if isPrintable(v.Bytes) {
+				return string(v.Bytes)
+			}
+			return "0x" + fmt.Sprintf("%x", v.Bytes)
+		case asn1.TagOID:
+			return berOID(v.Bytes)
+		case asn1.TagNull:
+			return ""
+		}
+	case asn1.ClassApplication:
+		switch v.Tag {
+		case tagIPAddr:
+			return net.IP(v.Bytes).String()
+		case tagCounter, tagGauge, tagTicks, tagCount64:
+			return strconv.FormatUint(berUint(v.Bytes), 10)
+		}
+	case asn1.ClassContextSpecific:
+		// noSuchObject, noSuchInstance, endOfMibView
+		return []string{"noSuchObject", "noSuchInstance", "endOfMibView"}[v.Tag%3]
+	}
+	return "0x" + fmt.Sprintf("%x", v.Bytes)
+}
+
+func isPrintable(b []byte) bool {
+	for _, r := range string(b) {
+		if r == unicode.ReplacementChar || (!unicode.IsPrint(r) && !unicode.IsSpace(r)) {
+			return false
+		}
+	}
+	return true
+}
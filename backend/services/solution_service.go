@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// SolutionService refines the solutions suggested for a ticket with
+// technician feedback, keeps every version of the solution set and tracks
+// which steps technicians have tried.
+type SolutionService struct {
+	db       *database.MongoDB
+	llm      *LLMService
+	vectors  *VectorService
+	searches *SearchAnalyticsService
+	queries  *QueryService
+}
+
+func NewSolutionService(db *database.MongoDB, llm *LLMService, vectors *VectorService, searches *SearchAnalyticsService, queries *QueryService) *SolutionService {
+	return &SolutionService{db: db, llm: llm, vectors: vectors, searches: searches, queries: queries}
+}
+
+// SearchConfidence is the mean relevance score of the documents solutions
+// were based on.
+func SearchConfidence(results []models.DocumentSearchResult) float32 {
+	if len(results) == 0 {
+		return 0.0
+	}
+
+	var total float32
+	for _, result := range results {
+		total += result.Score
+	}
+
+	return total / float32(len(results))
+}
+
+// search finds documentation for the ticket. Feedback is part of the query
+// so constraints such as another operating system pull in matching docs.
+// The returned log entry is recorded once the solutions citing the results
+// are known.
+func (s *SolutionService) search(ticket models.Ticket, feedback []string, userID primitive.ObjectID) ([]models.DocumentSearchResult, models.SearchLog, error) {
+	query := SolutionQuery(ticket) + " " + strings.Join(feedback, " ")
+	started := time.Now()
+	embedding, err := s.vectors.GenerateEmbedding(s.queries.Process(context.Background(), query).Query)
+	if err != nil {
+		return nil, models.SearchLog{}, err
+	}
+	results, err := s.vectors.SearchApproved(embedding, 5, 0.3)
+	if err != nil {
+		return nil, models.SearchLog{}, err
+	}
+	entry := NewSearchLog(models.SearchSourceSolutions, query, results, time.Since(started), userID)
+	entry.TicketID = &ticket.ID
+	return results, entry, nil
+}
+
+// recordSearch logs a solutions search with the documents the solutions
+// cite.
+func (s *SolutionService) recordSearch(ctx context.Context, entry models.SearchLog, results []models.DocumentSearchResult, solutions []models.SuggestedSolution) {
+	entry.CitedDocumentIDs = CitedDocuments(results, solutions)
+	s.searches.Record(ctx, entry)
+}
+
+func (s *SolutionService) collection() *mongo.Collection {
+	return s.db.GetCollection("solution_sets")
+}
+
+// Refine regenerates the solutions of a ticket with the technician's
+// feedback and stores them as a new version. When the ticket has no stored
+// solutions yet, the initial suggestion is generated and stored first as
+// version 1.
+func (s *SolutionService) Refine(ctx context.Context, ticket models.Ticket, req models.RefineSolutionsRequest, userID primitive.ObjectID) (models.SolutionSet, error) {
+	latest, err := s.latest(ctx, ticket.ID)
+	if err == mongo.ErrNoDocuments && req.Version <= 1 {
+		latest, err = s.initial(ctx, ticket, userID)
+	}
+	if err != nil {
+		return models.SolutionSet{}, err
+	}
+
+	base := latest
+	if req.Version > 0 && req.Version != latest.Version {
+		err := s.collection().FindOne(ctx, bson.M{"ticketId": ticket.ID, "version": req.Version}).Decode(&base)
+		if err != nil {
+			return models.SolutionSet{}, err
+		}
+	}
+
+	feedback := strings.TrimSpace(req.Feedback)
+	constraints := append(append([]string{}, base.Constraints...), feedback)
+	results, entry, err := s.search(ticket, constraints, userID)
+	if err != nil {
+		return models.SolutionSet{}, err
+	}
+	solutions, usage, err := s.llm.RefineSolutions(ctx, ticket, results, base.Solutions, constraints)
+	if err != nil {
+		return models.SolutionSet{}, err
+	}
+	s.recordSearch(ctx, entry, results, solutions)
+
+	set := newSolutionSet(ticket.ID, latest.Version+1, solutions, results, usage, userID)
+	set.Parent = base.Version
+	set.Feedback = feedback
+	set.Constraints = constraints
+	if _, err := s.collection().InsertOne(ctx, set); err != nil {
+		return models.SolutionSet{}, err
+	}
+	return set, nil
+}
+
+func (s *SolutionService) initial(ctx context.Context, ticket models.Ticket, userID primitive.ObjectID) (models.SolutionSet, error) {
+	results, entry, err := s.search(ticket, nil, userID)
+	if err != nil {
+		return models.SolutionSet{}, err
+	}
+	solutions, usage, err := s.llm.GenerateSolutions(ctx, ticket, results)
+	if err != nil {
+		return models.SolutionSet{}, err
+	}
+	s.recordSearch(ctx, entry, results, solutions)
+
+	set := newSolutionSet(ticket.ID, 1, solutions, results, usage, userID)
+	if _, err := s.collection().InsertOne(ctx, set); err != nil {
+		return models.SolutionSet{}, err
+	}
+	return set, nil
+}
+
+func newSolutionSet(ticketID primitive.ObjectID, version int, solutions []models.SuggestedSolution, results []models.DocumentSearchResult, usage models.ContextUsage, userID primitive.ObjectID) models.SolutionSet {
+	sources := []string{}
+	for _, result := range results {
+		sources = append(sources, result.Document.Title)
+	}
+	return models.SolutionSet{
+		ID:           primitive.NewObjectID(),
+		TicketID:     ticketID,
+		Version:      version,
+		Solutions:    solutions,
+		Sources:      sources,
+		Confidence:   SearchConfidence(results),
+		ContextUsage: usage,
+		CreatedBy:    userID,
+		CreatedAt:    time.Now(),
+	}
+}
+
+func (s *SolutionService) latest(ctx context.Context, ticketID primitive.ObjectID) (models.SolutionSet, error) {
+	var set models.SolutionSet
+	opts := options.FindOne().SetSort(bson.D{{"version", -1}})
+	err := s.collection().FindOne(ctx, bson.M{"ticketId": ticketID}, opts).Decode(&set)
+	return set, err
+}
+
+// History returns every solution set of a ticket, oldest version first.
+func (s *SolutionService) History(ctx context.Context, ticketID primitive.ObjectID) ([]models.SolutionSet, error) {
+	opts := options.Find().SetSort(bson.D{{"version", 1}})
+	cursor, err := s.collection().Find(ctx, bson.M{"ticketId": ticketID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sets := []models.SolutionSet{}
+	if err := cursor.All(ctx, &sets); err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+// ValidStepStatus reports whether status is a supported step status.
+func ValidStepStatus(status models.StepStatus) bool {
+	switch status {
+	case models.StepAttempted, models.StepSucceeded, models.StepFailed:
+		return true
+	}
+	return false
+}
+
+// RecordStep stores the technician's progress on a solution step, replacing
+// any earlier status of the same step, and returns the ticket's progress.
+func (s *SolutionService) RecordStep(ctx context.Context, ticketID primitive.ObjectID, req models.UpdateSolutionStepRequest, user models.User) ([]models.SolutionStepProgress, error) {
+	progress := models.SolutionStepProgress{
+		Solution:      strings.TrimSpace(req.Solution),
+		StepIndex:     req.StepIndex,
+		Step:          req.Step,
+		Status:        req.Status,
+		Note:          req.Note,
+		UpdatedBy:     user.ID,
+		UpdatedByName: user.Name,
+		UpdatedAt:     time.Now(),
+	}
+
+	return recordStepProgress(ctx, s.db, ticketID, progress)
+}
+
+// recordStepProgress replaces or appends the progress of a solution step on
+// a ticket and returns the ticket's progress.
+func recordStepProgress(ctx context.Context, db *database.MongoDB, ticketID primitive.ObjectID, progress models.SolutionStepProgress) ([]models.SolutionStepProgress, error) {
+	tickets := db.GetCollection("tickets")
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var ticket models.Ticket
+	err := tickets.FindOneAndUpdate(ctx, bson.M{
+		"_id":           ticketID,
+		"solutionSteps": bson.M{"$elemMatch": bson.M{"solution": progress.Solution, "stepIndex": progress.StepIndex}},
+	}, bson.M{
+		"$set": bson.M{"solutionSteps.$": progress, "updatedAt": progress.UpdatedAt},
+	}, opts).Decode(&ticket)
+	if err == mongo.ErrNoDocuments {
+		err = tickets.FindOneAndUpdate(ctx, bson.M{"_id": ticketID}, bson.M{
+			"$push": bson.M{"solutionSteps": progress},
+			"$set":  bson.M{"updatedAt": progress.UpdatedAt},
+		}, opts).Decode(&ticket)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ticket.SolutionSteps, nil
+}
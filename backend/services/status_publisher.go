@@ -0,0 +1,173 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"intelliops-ai-copilot/models"
+)
+
+// StatusPublisher mirrors incidents onto an external status page. Create
+// returns the provider's incident ID, which is passed to later updates.
+type StatusPublisher interface {
+	Name() string
+	Create(ctx context.Context, incident models.Incident, components []models.StatusComponent) (string, error)
+	Update(ctx context.Context, externalID string, incident models.Incident, components []models.StatusComponent) error
+}
+
+// componentStatus is the status an incident's impact puts its components in.
+func componentStatus(incident models.Incident) string {
+	if incident.Status == models.IncidentResolved {
+		return models.ComponentOperational
+	}
+	switch incident.Impact {
+	case models.ImpactCritical:
+		return models.ComponentMajor
+	case models.ImpactMajor:
+		return models.ComponentPartial
+	}
+	return models.ComponentDegraded
+}
+
+func latestUpdate(incident models.Incident) string {
+	if len(incident.Updates) == 0 {
+		return ""
+	}
+	return incident.Updates[len(incident.Updates)-1].Message
+}
+
+func sendStatusRequest(ctx context.Context, client *http.Client, method, url, auth string, body interface{}) ([]byte, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", auth)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	buf.ReadFrom(resp.Body)
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("status page API returned %d: %s", resp.StatusCode, strings.TrimSpace(buf.String()))
+	}
+	return buf.Bytes(), nil
+}
+
+// statuspagePublisher uses the Statuspage.io REST API.
+type statuspagePublisher struct {
+	apiKey string
+	pageID string
+	client *http.Client
+}
+
+func (p *statuspagePublisher) Name() string {
+	return "statuspage"
+}
+
+func (p *statuspagePublisher) payload(incident models.Incident, components []models.StatusComponent) map[string]interface{} {
+	ids := []string{}
+	statuses := map[string]string{}
+	for _, c := range components {
+		if c.StatuspageID != "" {
+			ids = append(ids, c.StatuspageID)
+			statuses[c.StatuspageID] = componentStatus(incident)
+		}
+	}
+	return map[string]interface{}{"incident": map[string]interface{}{
+		"name":            incident.Title,
+		"status":          string(incident.Status),
+		"body":            latestUpdate(incident),
+		"impact_override": string(incident.Impact),
+		"component_ids":   ids,
+		"components":      statuses,
+	}}
+}
+
+func (p *statuspagePublisher) Create(ctx context.Context, incident models.Incident, components []models.StatusComponent) (string, error) {
+	url := fmt.Sprintf("https://api.statuspage.io/v1/pages/%s/incidents", p.pageID)
+	body, err := sendStatusRequest(ctx, p.client, "POST", url, "OAuth "+p.apiKey, p.payload(incident, components))
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (p *statuspagePublisher) Update(ctx context.Context, externalID string, incident models.Incident, components []models.StatusComponent) error {
+	url := fmt.Sprintf("https://api.statuspage.io/v1/pages/%s/incidents/%s", p.pageID, externalID)
+	_, err := sendStatusRequest(ctx, p.client, "PATCH", url, "OAuth "+p.apiKey, p.payload(incident, components))
+	return err
+}
+
+// instatusPublisher uses the Instatus REST API, which spells statuses in
+// upper case without separators (MAJOROUTAGE, INVESTIGATING).
+type instatusPublisher struct {
+	apiKey string
+	pageID string
+	client *http.Client
+}
+
+func (p *instatusPublisher) Name() string {
+	return "instatus"
+}
+
+func (p *instatusPublisher) payload(incident models.Incident, components []models.StatusComponent) map[string]interface{} {
+	ids := []string{}
+	statuses := []map[string]string{}
+	status := strings.ToUpper(strings.ReplaceAll(componentStatus(incident), "_", ""))
+	for _, c := range components {
+		if c.InstatusID != "" {
+			ids = append(ids, c.InstatusID)
+			statuses = append(statuses, map[string]string{"id": c.InstatusID, "status": status})
+		}
+	}
+	return map[string]interface{}{
+		"name":       incident.Title,
+		"message":    latestUpdate(incident),
+		"components": ids,
+		"started":    incident.CreatedAt.UTC().Format(time.RFC3339),
+		"status":     strings.ToUpper(string(incident.Status)),
+		"notify":     true,
+		"statuses":   statuses,
+	}
+}
+
+func (p *instatusPublisher) Create(ctx context.Context, incident models.Incident, components []models.StatusComponent) (string, error) {
+	url := fmt.Sprintf("https://api.instatus.com/v1/%s/incidents", p.pageID)
+	body, err := sendStatusRequest(ctx, p.client, "POST", url, "Bearer "+p.apiKey, p.payload(incident, components))
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (p *instatusPublisher) Update(ctx context.Context, externalID string, incident models.Incident, components []models.StatusComponent) error {
+	url := fmt.Sprintf("https://api.instatus.com/v1/%s/incidents/%s/incident-updates", p.pageID, externalID)
+	_, err := sendStatusRequest(ctx, p.client, "POST", url, "Bearer "+p.apiKey, p.payload(incident, components))
+	return err
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const summarySystemPrompt = "You are an IT support lead writing concise ticket summaries. Always respond with valid JSON." + untrustedContentPolicy
+
+// SummaryService condenses a ticket thread into a technician handover and a
+// customer-facing update.
+type SummaryService struct {
+	db         *database.MongoDB
+	llm        *LLMService
+	guardrails *GuardrailService
+}
+
+func NewSummaryService(db *database.MongoDB, llm *LLMService, guardrails *GuardrailService) *SummaryService {
+	return &SummaryService{db: db, llm: llm, guardrails: guardrails}
+}
+
+// Summarize generates a summary of the ticket thread and stores it on the
+// ticket.
+func (s *SummaryService) Summarize(ctx context.Context, ticket models.Ticket) (models.ThreadSummary, error) {
+	summary, err := s.generate(ctx, ticket)
+	if err != nil {
+		if err != ErrLLMUnavailable {
+			log.Printf("Thread summary failed for ticket %s, using fallback: %v", ticket.ID.Hex(), err)
+		}
+		summary = mockThreadSummary(ticket)
+	}
+	summary.GeneratedAt = time.Now()
+
+	// updatedAt is left alone: a summary is not activity on the ticket.
+	_, err = s.db.GetCollection("tickets").UpdateByID(ctx, ticket.ID, bson.M{"$set": bson.M{"threadSummary": summary}})
+	return summary, err
+}
+
+// RefreshIfPresent regenerates the summary in the background after new
+// activity, but only for tickets that already have one.
+func (s *SummaryService) RefreshIfPresent(ticketID primitive.ObjectID) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		var ticket models.Ticket
+		if err := s.db.GetCollection("tickets").FindOne(ctx, bson.M{"_id": ticketID}).Decode(&ticket); err != nil {
+			return
+		}
+		if ticket.ThreadSummary == nil {
+			return
+		}
+		if _, err := s.Summarize(ctx, ticket); err != nil {
+			log.Printf("Failed to refresh thread summary for ticket %s: %v", ticketID.Hex(), err)
+		}
+	}()
+}
+
+func (s *SummaryService) generate(ctx context.Context, ticket models.Ticket) (models.ThreadSummary, error) {
+	prompt := fmt.Sprintf(`Summarize the following IT support ticket thread.
+
+%s
+
+Respond with a JSON object containing:
+- handover: 3-6 sentences for the next technician covering the problem, what has been tried or found, current state and open next steps
+- customer: 2-3 friendly sentences for the requester describing progress without internal details or jargon`, s.guardrails.Wrap(ctx, "ticket", ticket.ID.Hex(), "thread", TicketThread(ticket)))
+
+	content, err := s.llm.Complete(ctx, CompletionRequest{
+		System:   summarySystemPrompt,
+		Prompt:   prompt,
+		Endpoint: EndpointSummary,
+	})
+	if err != nil {
+		return models.ThreadSummary{}, err
+	}
+
+	var summary models.ThreadSummary
+	if err := json.Unmarshal([]byte(ExtractJSON(content)), &summary); err != nil {
+		return models.ThreadSummary{}, fmt.Errorf("failed to parse summary response: %v", err)
+	}
+	if strings.TrimSpace(summary.Handover) == "" || strings.TrimSpace(summary.Customer) == "" {
+		return models.ThreadSummary{}, fmt.Errorf("summary response missing handover or customer text")
+	}
+	return summary, nil
+}
+
+// TicketThread renders the description and all activity on a ticket in
+// chronological order as plain text.
+func TicketThread(ticket models.Ticket) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Title: %s\n", ticket.Title))
+	b.WriteString(fmt.Sprintf("Category: %s", ticket.Category))
+	if ticket.Subcategory != "" {
+		b.WriteString(" / " + ticket.Subcategory)
+	}
+	b.WriteString(fmt.Sprintf("\nPriority: %s\nStatus: %s\nOpened: %s\n", ticket.Priority, ticket.Status, ticket.CreatedAt.Format(time.RFC822)))
+	b.WriteString("\nDescription:\n" + ticket.Description + "\n")
+
+	for _, d := range ticket.Diagnostics {
+		b.WriteString(fmt.Sprintf("\n[%s] Diagnostic report:\n%s\n", d.CreatedAt.Format(time.RFC822), d.Report))
+	}
+	if info := LatestSystemInfo(ticket); info != nil {
+		b.WriteString("\nSystem information:\n" + TruncateToTokens(SystemInfoText(*info), systemInfoPromptTokens))
+	}
+	if len(ticket.SolutionSteps) > 0 {
+		b.WriteString("\nSolution steps tried:\n")
+		for _, step := range ticket.SolutionSteps {
+			b.WriteString(fmt.Sprintf("- [%s] %s, step %d: %s (%s by %s)", step.UpdatedAt.Format(time.RFC822), step.Solution, step.StepIndex+1, step.Step, step.Status, step.UpdatedByName))
+			if step.Note != "" {
+				b.WriteString(" - " + step.Note)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func mockThreadSummary(ticket models.Ticket) models.ThreadSummary {
+	description := strings.Join(strings.Fields(ticket.Description), " ")
+	if len(description) > 300 {
+		description = description[:300] + "..."
+	}
+
+	handover := fmt.Sprintf("%s ticket \"%s\" (%s priority) opened %s, currently %s. Reported issue: %s",
+		ticket.Category, ticket.Title, ticket.Priority, ticket.CreatedAt.Format("2006-01-02"),
+		strings.ReplaceAll(string(ticket.Status), "_", " "), description)
+	if n := len(ticket.Diagnostics); n > 0 {
+		latest := ticket.Diagnostics[n-1].Report
+		if i := strings.Index(latest, "\n"); i >= 0 {
+			latest = latest[:i]
+		}
+		handover += fmt.Sprintf(" %d diagnostic report(s) attached; latest: %s", n, latest)
+	}
+
+	var progress string
+	switch ticket.Status {
+	case models.StatusResolved, models.StatusClosed:
+		progress = "has been resolved. Please let us know if the problem comes back."
+	case models.StatusInProgress:
+		progress = "is being worked on by our support team. We will update you as soon as we have more information."
+	default:
+		progress = "has been received and is waiting for a technician. We will be in touch shortly."
+	}
+
+	return models.ThreadSummary{
+		Handover: handover,
+		Customer: fmt.Sprintf("Thank you for reporting \"%s\". Your request %s", ticket.Title, progress),
+		Fallback: true,
+	}
+}
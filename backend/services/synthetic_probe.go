@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"intelliops-ai-copilot/models"
+)
+
+// Largest response body read by HTTP checks; assertions see only this much.
+const maxCheckBodyBytes = 1 << 20
+
+var (
+	checkSources     = map[string]bool{"statusCode": true, "responseTime": true, "body": true, "header": true}
+	checkComparisons = map[string]bool{
+		"equals": true, "notEquals": true, "lessThan": true, "greaterThan": true,
+		"contains": true, "notContains": true, "matches": true,
+	}
+	pingTime = regexp.MustCompile(`time[=<]([0-9.]+) ?ms`)
+)
+
+// checkResponse is what a probe observed, for assertions to inspect.
+type checkResponse struct {
+	statusCode   int
+	responseTime float64
+	body         string
+	header       http.Header
+}
+
+// RunCheck probes the check's target once and evaluates its assertions.
+func RunCheck(ctx context.Context, check models.SyntheticCheck) models.CheckResult {
+	timeout := time.Duration(check.TimeoutSeconds) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := models.CheckResult{CheckID: check.ID, Region: check.Region, CheckedAt: time.Now()}
+	var resp checkResponse
+	var err error
+	switch check.Type {
+	case models.CheckHTTP:
+		resp, err = probeHTTP(ctx, check)
+	case models.CheckTCP:
+		resp, err = probeTCP(ctx, check.Target)
+	case models.CheckPing:
+		resp, err = probePing(ctx, check.Target, check.TimeoutSeconds)
+	default:
+		err = fmt.Errorf("unknown check type %q", check.Type)
+	}
+	result.ResponseTimeMs = resp.responseTime
+	result.StatusCode = resp.statusCode
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	assertions := check.Assertions
+	if check.Type == models.CheckHTTP && len(assertions) == 0 {
+		assertions = []models.CheckAssertion{{Source: "statusCode", Comparison: "lessThan", Target: "400"}}
+	}
+	for _, a := range assertions {
+		if !assertionHolds(a, resp) {
+			result.FailedAssertions = append(result.FailedAssertions, describeAssertion(a))
+		}
+	}
+	result.Success = len(result.FailedAssertions) == 0
+	return result
+}
+
+func probeHTTP(ctx context.Context, check models.SyntheticCheck) (checkResponse, error) {
+	method := check.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, check.Target, strings.NewReader(check.Body))
+	if err != nil {
+		return checkResponse{}, err
+	}
+	for k, v := range check.Headers {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return checkResponse{responseTime: elapsedMs(start)}, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxCheckBodyBytes))
+	resp := checkResponse{statusCode: res.StatusCode, responseTime: elapsedMs(start), body: string(body), header: res.Header}
+	return resp, err
+}
+
+func probeTCP(ctx context.Context, target string) (checkResponse, error) {
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	resp := checkResponse{responseTime: elapsedMs(start)}
+	if err != nil {
+		return resp, err
+	}
+	conn.Close()
+	return resp, nil
+}
+
+// probePing sends one ICMP echo with the system ping command, which unlike
+// raw sockets needs no extra privileges.
+func probePing(ctx context.Context, host string, timeoutSeconds int) (checkResponse, error) {
+	start := time.Now()
+	out, err := exec.CommandContext(ctx, "ping", "-c", "1", "-W", strconv.Itoa(timeoutSeconds), "--", host).CombinedOutput()
+	resp := checkResponse{responseTime: elapsedMs(start)}
+	if err != nil {
+		if line := lastLine(string(out)); line != "" {
+			return resp, fmt.Errorf("ping failed: %s", line)
+		}
+		return resp, fmt.Errorf("ping failed: %v", err)
+	}
+	if m := pingTime.FindStringSubmatch(string(out)); m != nil {
+		resp.responseTime, _ = strconv.ParseFloat(m[1], 64)
+	}
+	return resp, nil
+}
+
+func elapsedMs(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func assertionHolds(a models.CheckAssertion, resp checkResponse) bool {
+	var actual string
+	switch a.Source {
+	case "statusCode":
+		actual = strconv.Itoa(resp.statusCode)
+	case "responseTime":
+		actual = strconv.FormatFloat(resp.responseTime, 'f', -1, 64)
+	case "body":
+		actual = resp.body
+	case "header":
+		actual = resp.header.Get(a.Property)
+	}
+
+	switch a.Comparison {
+	case "equals", "notEquals":
+		equal := actual == a.Target
+		if x, err := strconv.ParseFloat(actual, 64); err == nil {
+			if y, err := strconv.ParseFloat(a.Target, 64); err == nil {
+				equal = x == y
+			}
+		}
+		return equal == (a.Comparison == "equals")
+	case "lessThan", "greaterThan":
+		x, err1 := strconv.ParseFloat(actual, 64)
+		y, err2 := strconv.ParseFloat(a.Target, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		if a.Comparison == "lessThan" {
+			return x < y
+		}
+		return x > y
+	case "contains":
+		return strings.Contains(actual, a.Target)
+	case "notContains":
+		return !strings.Contains(actual, a.Target)
+	case "matches":
+		re, err := regexp.Compile(a.Target)
+		return err == nil && re.MatchString(actual)
+	}
+	return false
+}
+
+func describeAssertion(a models.CheckAssertion) string {
+	source := a.Source
+	if a.Source == "header" {
+		source = "header " + a.Property
+	}
+	return fmt.Sprintf("%s %s %q", source, a.Comparison, a.Target)
+}
+
+// validateAssertions checks assertion sources, comparisons and targets.
+// TCP and ping checks can only assert on the response time.
+func validateAssertions(checkType models.SyntheticCheckType, assertions []models.CheckAssertion) error {
+	for i, a := range assertions {
+		if !checkSources[a.Source] {
+			return fmt.Errorf("assertions[%d]: source must be statusCode, responseTime, body or header", i)
+		}
+		if checkType != models.CheckHTTP && a.Source != "responseTime" {
+			return fmt.Errorf("assertions[%d]: %s checks can only assert on responseTime", i, checkType)
+		}
+		if a.Source == "header" && a.Property == "" {
+			return fmt.Errorf("assertions[%d]: header assertions need a property", i)
+		}
+		if !checkComparisons[a.Comparison] {
+			return fmt.Errorf("assertions[%d]: unknown comparison %q", i, a.Comparison)
+		}
+		switch a.Comparison {
+		case "lessThan", "greaterThan":
+			if _, err := strconv.ParseFloat(a.Target, 64); err != nil {
+				return fmt.Errorf("assertions[%d]: %s needs a numeric target", i, a.Comparison)
+			}
+		case "matches":
+			if _, err := regexp.Compile(a.Target); err != nil {
+				return fmt.Errorf("assertions[%d]: invalid regular expression: %v", i, err)
+			}
+		}
+	}
+	return nil
+}
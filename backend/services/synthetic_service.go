@@ -0,0 +1,511 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	anomalySourceSynthetic = "synthetic"
+	// syntheticConcurrency bounds the checks the backend runs at once.
+	syntheticConcurrency = 10
+	// syntheticHistoryLines is how many recent results a failure ticket lists.
+	syntheticHistoryLines = 10
+)
+
+// ErrCheckRegionMismatch is returned when a worker reports a result for a
+// check assigned to another region.
+var ErrCheckRegionMismatch = errors.New("check is not assigned to this region")
+
+// SyntheticService runs uptime checks and turns consecutive failures into
+// anomalies and tickets.
+type SyntheticService struct {
+	db          *database.MongoDB
+	cfg         *config.Config
+	taxonomy    *TaxonomyService
+	routes      *MonitorRouteService
+	maintenance *MaintenanceService
+	comments    *CommentService
+	policies    *AnomalyPolicies
+	flags       *FeatureFlagService
+
+	mu      sync.Mutex
+	running map[primitive.ObjectID]bool
+}
+
+func NewSyntheticService(db *database.MongoDB, cfg *config.Config, taxonomy *TaxonomyService, routes *MonitorRouteService, policies *AnomalyPolicies, flags *FeatureFlagService) *SyntheticService {
+	return &SyntheticService{
+		db:          db,
+		cfg:         cfg,
+		taxonomy:    taxonomy,
+		routes:      routes,
+		maintenance: NewMaintenanceService(db),
+		comments:    NewCommentService(db),
+		policies:    policies,
+		flags:       flags,
+		running:     map[primitive.ObjectID]bool{},
+	}
+}
+
+// ValidateSyntheticCheck checks the target for the check type and the
+// assertions, and fills in defaults for the interval, timeout, failure
+// threshold and severity.
+func ValidateSyntheticCheck(req *models.SyntheticCheckRequest) error {
+	switch req.Type {
+	case models.CheckHTTP:
+		if u, err := url.Parse(req.Target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("target must be an http(s) URL for http checks")
+		}
+	case models.CheckTCP:
+		if _, _, err := net.SplitHostPort(req.Target); err != nil {
+			return fmt.Errorf("target must be host:port for tcp checks")
+		}
+	case models.CheckPing:
+		if req.Target == "" || strings.HasPrefix(req.Target, "-") || strings.ContainsAny(req.Target, " /:") {
+			return fmt.Errorf("target must be a host name or IP address for ping checks")
+		}
+	default:
+		return fmt.Errorf("type must be http, tcp or ping")
+	}
+
+	if req.IntervalSeconds == 0 {
+		req.IntervalSeconds = 60
+	}
+	if req.TimeoutSeconds == 0 {
+		req.TimeoutSeconds = 10
+	}
+	if req.FailureThreshold == 0 {
+		req.FailureThreshold = 3
+	}
+	if req.Severity == "" {
+		req.Severity = "high"
+	}
+	if req.IntervalSeconds < 10 {
+		return fmt.Errorf("intervalSeconds must be at least 10")
+	}
+	if req.TimeoutSeconds < 1 || req.TimeoutSeconds >= req.IntervalSeconds {
+		return fmt.Errorf("timeoutSeconds must be positive and shorter than the interval")
+	}
+	if req.FailureThreshold < 1 {
+		return fmt.Errorf("failureThreshold must be positive")
+	}
+	if !isAnomalySeverity(req.Severity) {
+		return fmt.Errorf("severity must be critical, high, medium or low")
+	}
+	return validateAssertions(req.Type, req.Assertions)
+}
+
+func (s *SyntheticService) Create(ctx context.Context, req models.SyntheticCheckRequest, createdBy primitive.ObjectID) (models.SyntheticCheck, error) {
+	check := models.SyntheticCheck{
+		ID:               primitive.NewObjectID(),
+		Name:             req.Name,
+		Type:             req.Type,
+		Target:           req.Target,
+		Method:           strings.ToUpper(req.Method),
+		Headers:          req.Headers,
+		Body:             req.Body,
+		Assertions:       req.Assertions,
+		IntervalSeconds:  req.IntervalSeconds,
+		TimeoutSeconds:   req.TimeoutSeconds,
+		FailureThreshold: req.FailureThreshold,
+		Severity:         req.Severity,
+		Region:           req.Region,
+		ResourceID:       req.ResourceID,
+		Tags:             req.Tags,
+		Enabled:          req.Enabled == nil || *req.Enabled,
+		CreatedBy:        createdBy,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	if _, err := s.db.GetCollection("synthetic_checks").InsertOne(ctx, check); err != nil {
+		return models.SyntheticCheck{}, err
+	}
+	return check, nil
+}
+
+// List returns checks by name, filtered by tags.
+func (s *SyntheticService) List(ctx context.Context, tags map[string]string) ([]models.SyntheticCheck, error) {
+	cursor, err := s.db.GetCollection("synthetic_checks").Find(ctx, tagQuery("tags", tags), options.Find().SetSort(bson.D{{"name", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	checks := []models.SyntheticCheck{}
+	if err := cursor.All(ctx, &checks); err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+func (s *SyntheticService) Get(ctx context.Context, id primitive.ObjectID) (models.SyntheticCheck, error) {
+	var check models.SyntheticCheck
+	err := s.db.GetCollection("synthetic_checks").FindOne(ctx, bson.M{"_id": id}).Decode(&check)
+	return check, err
+}
+
+func (s *SyntheticService) Update(ctx context.Context, id primitive.ObjectID, req models.SyntheticCheckRequest) (models.SyntheticCheck, error) {
+	set := bson.M{
+		"name":             req.Name,
+		"type":             req.Type,
+		"target":           req.Target,
+		"method":           strings.ToUpper(req.Method),
+		"headers":          req.Headers,
+		"body":             req.Body,
+		"assertions":       req.Assertions,
+		"intervalSeconds":  req.IntervalSeconds,
+		"timeoutSeconds":   req.TimeoutSeconds,
+		"failureThreshold": req.FailureThreshold,
+		"severity":         req.Severity,
+		"region":           req.Region,
+		"resourceId":       req.ResourceID,
+		"tags":             req.Tags,
+		"updatedAt":        time.Now(),
+	}
+	if req.Enabled != nil {
+		set["enabled"] = *req.Enabled
+	}
+	var check models.SyntheticCheck
+	err := s.db.GetCollection("synthetic_checks").FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&check)
+	return check, err
+}
+
+// Delete removes a check and its results.
+func (s *SyntheticService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.db.GetCollection("synthetic_checks").DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	_, err = s.db.GetCollection("synthetic_results").DeleteMany(ctx, bson.M{"checkId": id})
+	return err
+}
+
+// History returns the latest limit results of a check with its uptime and
+// average and 95th percentile response times over them.
+func (s *SyntheticService) History(ctx context.Context, id primitive.ObjectID, limit int) (models.CheckHistory, error) {
+	history := models.CheckHistory{Results: []models.CheckResult{}}
+	cursor, err := s.db.GetCollection("synthetic_results").Find(ctx, bson.M{"checkId": id},
+		options.Find().SetSort(bson.D{{"checkedAt", -1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return history, err
+	}
+	defer cursor.Close(ctx)
+	if err := cursor.All(ctx, &history.Results); err != nil {
+		return history, err
+	}
+	if len(history.Results) == 0 {
+		return history, nil
+	}
+
+	up := 0
+	times := []float64{}
+	for _, r := range history.Results {
+		if r.Success {
+			up++
+			times = append(times, r.ResponseTimeMs)
+		}
+	}
+	history.UptimePercent = math.Round(float64(up)/float64(len(history.Results))*1000) / 10
+	if len(times) > 0 {
+		sort.Float64s(times)
+		sum := 0.0
+		for _, t := range times {
+			sum += t
+		}
+		history.AvgResponseTimeMs = math.Round(sum/float64(len(times))*10) / 10
+		history.P95ResponseTimeMs = times[int(math.Ceil(0.95*float64(len(times))))-1]
+	}
+	return history, nil
+}
+
+// Start runs the due backend checks, those without a region, every tick
+// until ctx is cancelled.
+func (s *SyntheticService) Start(ctx context.Context, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if err := s.runDue(ctx); err != nil {
+					log.Printf("synthetic check error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (s *SyntheticService) runDue(ctx context.Context) error {
+	checks, err := s.Due(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, syntheticConcurrency)
+	for _, check := range checks {
+		s.mu.Lock()
+		if s.running[check.ID] {
+			s.mu.Unlock()
+			continue
+		}
+		s.running[check.ID] = true
+		s.mu.Unlock()
+
+		sem <- struct{}{}
+		go func(check models.SyntheticCheck) {
+			defer func() {
+				<-sem
+				s.mu.Lock()
+				delete(s.running, check.ID)
+				s.mu.Unlock()
+			}()
+			if err := s.Record(ctx, check, RunCheck(ctx, check)); err != nil {
+				log.Printf("failed to record synthetic check %s: %v", check.Name, err)
+			}
+		}(check)
+	}
+	return nil
+}
+
+// Due returns the enabled checks of a region ("" for the backend) whose
+// interval has passed since their last run. No checks are due while the
+// synthetic_checks flag is off.
+func (s *SyntheticService) Due(ctx context.Context, region string) ([]models.SyntheticCheck, error) {
+	if !s.flags.Enabled(ctx, FlagSyntheticChecks) {
+		return []models.SyntheticCheck{}, nil
+	}
+	filter := bson.M{"enabled": true, "region": region}
+	if region == "" {
+		filter["region"] = bson.M{"$in": []interface{}{"", nil}}
+	}
+	cursor, err := s.db.GetCollection("synthetic_checks").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var checks []models.SyntheticCheck
+	err = cursor.All(ctx, &checks)
+	cursor.Close(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	due := []models.SyntheticCheck{}
+	for _, c := range checks {
+		if c.LastRunAt == nil || !c.LastRunAt.Add(time.Duration(c.IntervalSeconds)*time.Second).After(now) {
+			due = append(due, c)
+		}
+	}
+	return due, nil
+}
+
+// Report records results posted by a regional worker.
+func (s *SyntheticService) Report(ctx context.Context, report models.CheckResultReport) (int, error) {
+	recorded := 0
+	for _, result := range report.Results {
+		check, err := s.Get(ctx, result.CheckID)
+		if err != nil {
+			return recorded, err
+		}
+		if check.Region != report.Region {
+			return recorded, ErrCheckRegionMismatch
+		}
+		result.Region = report.Region
+		if result.CheckedAt.IsZero() {
+			result.CheckedAt = time.Now()
+		}
+		if err := s.Record(ctx, check, result); err != nil {
+			return recorded, err
+		}
+		recorded++
+	}
+	return recorded, nil
+}
+
+// Record stores a result and updates the check's state. Reaching the
+// failure threshold opens an anomaly and ticket; a success closes them.
+func (s *SyntheticService) Record(ctx context.Context, check models.SyntheticCheck, result models.CheckResult) error {
+	result.ID = primitive.NewObjectID()
+	result.CheckID = check.ID
+	if _, err := s.db.GetCollection("synthetic_results").InsertOne(ctx, result); err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"lastRunAt": result.CheckedAt, "lastSuccess": result.Success, "lastResponseTimeMs": result.ResponseTimeMs}}
+	if result.Success {
+		update["$set"].(bson.M)["consecutiveFailures"] = 0
+	} else {
+		update["$inc"] = bson.M{"consecutiveFailures": 1}
+	}
+	var updated models.SyntheticCheck
+	err := s.db.GetCollection("synthetic_checks").FindOneAndUpdate(ctx, bson.M{"_id": check.ID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+	if err != nil {
+		return err
+	}
+
+	if result.Success {
+		return s.closeAnomaly(ctx, updated, result)
+	}
+	if updated.ConsecutiveFailures >= updated.FailureThreshold {
+		return s.openAnomaly(ctx, updated, result)
+	}
+	return nil
+}
+
+func syntheticDedupKey(check models.SyntheticCheck) string {
+	return anomalySourceSynthetic + ":" + check.ID.Hex()
+}
+
+func (s *SyntheticService) openAnomaly(ctx context.Context, check models.SyntheticCheck, result models.CheckResult) error {
+	anomalies := s.db.GetCollection("mon_anomalies")
+	dedup := syntheticDedupKey(check)
+	count, err := anomalies.CountDocuments(ctx, bson.M{"dedupKey": dedup, "status": models.AnomalyOpen})
+	if err != nil || count > 0 {
+		return err
+	}
+
+	history, err := s.History(ctx, check.ID, syntheticHistoryLines)
+	if err != nil {
+		return err
+	}
+	labels := map[string]string{"check": check.Name, "type": string(check.Type), "target": check.Target}
+	if check.Region != "" {
+		labels["region"] = check.Region
+	}
+	for k, v := range check.Tags {
+		labels[k] = v
+	}
+	anomaly := models.AnomalyRecord{
+		ID:           primitive.NewObjectID(),
+		MetricName:   "Availability",
+		Timestamp:    result.CheckedAt,
+		Value:        result.ResponseTimeMs,
+		BaselineMean: history.AvgResponseTimeMs,
+		Severity:     check.Severity,
+		DedupKey:     dedup,
+		Status:       models.AnomalyOpen,
+		Source:       anomalySourceSynthetic,
+		AlertName:    check.Name,
+		Labels:       labels,
+		CreatedAt:    time.Now(),
+	}
+
+	var resource *models.MonitoredResource
+	if check.ResourceID != nil {
+		anomaly.ResourceID = *check.ResourceID
+		var r models.MonitoredResource
+		if err := s.db.GetCollection("mon_resources").FindOne(ctx, bson.M{"_id": *check.ResourceID}).Decode(&r); err == nil {
+			resource = &r
+		}
+	}
+
+	window, err := s.maintenance.Covering(ctx, resource, labels, result.CheckedAt)
+	if err != nil {
+		log.Printf("maintenance window lookup for check %s failed: %v", check.Name, err)
+	}
+	title := fmt.Sprintf("Check failing: %s (%s %s)", check.Name, strings.ToUpper(string(check.Type)), check.Target)
+	if window != nil {
+		anomaly.MaintenanceWindowID = &window.ID
+	} else {
+		if s.cfg.AnomalyCreateTickets {
+			ticketID, err := insertAnomalyTicket(ctx, s.db, title, describeCheckFailure(check, history), s.category(ctx), s.policies.Default().Priority(check.Severity), check.ResourceID)
+			if err != nil {
+				log.Printf("ticket creation for check %s failed: %v", check.Name, err)
+			} else {
+				anomaly.TicketID = ticketID
+			}
+		}
+		s.routes.Route(ctx, resource, &anomaly, title)
+	}
+
+	_, err = anomalies.InsertOne(ctx, anomaly)
+	return err
+}
+
+func (s *SyntheticService) closeAnomaly(ctx context.Context, check models.SyntheticCheck, result models.CheckResult) error {
+	var anomaly models.AnomalyRecord
+	err := s.db.GetCollection("mon_anomalies").FindOne(ctx, bson.M{"dedupKey": syntheticDedupKey(check), "status": models.AnomalyOpen}).Decode(&anomaly)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	note := fmt.Sprintf("Check %s passed again at %s in %.0f ms.", check.Name, result.CheckedAt.Format(time.RFC3339), result.ResponseTimeMs)
+	now := time.Now()
+	_, err = s.db.GetCollection("mon_anomalies").UpdateByID(ctx, anomaly.ID,
+		bson.M{"$set": bson.M{"status": models.AnomalyClosed, "closedAt": now, "recoveredAt": result.CheckedAt, "resolution": note}})
+	if err != nil || anomaly.TicketID == nil {
+		return err
+	}
+	if err := noteRecoveryOnTicket(ctx, s.db, s.comments, s.cfg.AnomalyResolveTickets, *anomaly.TicketID, note); err != nil {
+		log.Printf("failed to update ticket %s for recovered check: %v", anomaly.TicketID.Hex(), err)
+	}
+	return nil
+}
+
+// describeCheckFailure lists the latest results, newest first, so the
+// ticket shows the response-time history leading up to the failure.
+func describeCheckFailure(check models.SyntheticCheck, history models.CheckHistory) string {
+	var desc strings.Builder
+	desc.WriteString(fmt.Sprintf("Synthetic %s check %q against %s failed %d times in a row.\n",
+		check.Type, check.Name, check.Target, check.ConsecutiveFailures))
+	if check.Region != "" {
+		desc.WriteString(fmt.Sprintf("Region: %s\n", check.Region))
+	}
+	desc.WriteString(fmt.Sprintf("Uptime over the last %d runs: %.1f%%\n\nRecent results:\n", len(history.Results), history.UptimePercent))
+	for _, r := range history.Results {
+		status := "ok"
+		if !r.Success {
+			status = "FAIL"
+		}
+		line := fmt.Sprintf("  %s  %-4s  %8.1f ms", r.CheckedAt.Format(time.RFC3339), status, r.ResponseTimeMs)
+		if r.StatusCode != 0 {
+			line += fmt.Sprintf("  HTTP %d", r.StatusCode)
+		}
+		if r.Error != "" {
+			line += "  " + r.Error
+		}
+		if len(r.FailedAssertions) > 0 {
+			line += "  failed: " + strings.Join(r.FailedAssertions, "; ")
+		}
+		desc.WriteString(line + "\n")
+	}
+	return desc.String()
+}
+
+// category files check failures as network issues when the taxonomy has
+// them, under the taxonomy default otherwise.
+func (s *SyntheticService) category(ctx context.Context) models.TicketCategory {
+	taxonomy := s.taxonomy.Get(ctx)
+	if FindCategory(taxonomy, models.CategoryNetwork) != nil {
+		return models.CategoryNetwork
+	}
+	return DefaultCategory(taxonomy)
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+type TaxonomyService struct {
+	db *database.MongoDB
+}
+
+func NewTaxonomyService(db *database.MongoDB) *TaxonomyService {
+	return &TaxonomyService{db: db}
+}
+
+// DefaultTaxonomy mirrors the built-in category and priority constants and is
+// used until an admin saves a custom taxonomy.
+func DefaultTaxonomy() models.Taxonomy {
+	return models.Taxonomy{
+		ID: models.TaxonomyID,
+		Categories: []models.TaxonomyCategory{
+			{Name: models.CategoryNetwork, Description: "Connectivity, VPN, WiFi, DNS and other network problems",
+				Subcategories: []string{"VPN", "WiFi", "DNS", "Wired LAN", "Internet Access"}},
+			{Name: models.CategoryHardware, Description: "Faulty or missing devices and peripherals",
+				Subcategories: []string{"Laptop/Desktop", "Printer", "Monitor", "Peripheral", "Server"}},
+			{Name: models.CategorySoftware, Description: "Application errors, installs and updates",
+				Subcategories: []string{"Email", "Office Suite", "Installation", "Operating System", "Business Application", "Licensing"}},
+			{Name: models.CategorySecurity, Description: "Access, malware and other security concerns",
+				Subcategories: []string{"Account Access", "Malware", "Phishing", "Data Breach"}},
+			{Name: models.CategoryPerformance, Description: "Slowness, freezes and crashes",
+				Subcategories: []string{"Slowness", "Crash/Freeze", "Resource Exhaustion"}},
+			{Name: models.CategoryOther, Description: "Anything that does not fit another category"},
+		},
+		Priorities: []models.TaxonomyPriority{
+			{Name: models.PriorityLow, Description: "Minor inconvenience, workaround available", ResponseSLAMinutes: 1440, ResolutionSLAHours: 120},
+			{Name: models.PriorityMedium, Description: "Single user or non-critical function affected", ResponseSLAMinutes: 240, ResolutionSLAHours: 48},
+			{Name: models.PriorityHigh, Description: "Multiple users or an important function affected", ResponseSLAMinutes: 60, ResolutionSLAHours: 8},
+			{Name: models.PriorityCritical, Description: "Outage or security incident affecting the business", ResponseSLAMinutes: 15, ResolutionSLAHours: 4},
+		},
+	}
+}
+
+// Get returns the stored taxonomy, falling back to the default one when none
+// has been saved or the lookup fails.
+func (s *TaxonomyService) Get(ctx context.Context) models.Taxonomy {
+	var taxonomy models.Taxonomy
+	err := s.db.GetCollection("taxonomy").FindOne(ctx, bson.M{"_id": models.TaxonomyID}).Decode(&taxonomy)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Failed to load taxonomy, using defaults: %v", err)
+		}
+		return DefaultTaxonomy()
+	}
+	return taxonomy
+}
+
+// Save validates and stores a new taxonomy, replacing the previous one.
+func (s *TaxonomyService) Save(ctx context.Context, req models.UpdateTaxonomyRequest, updatedBy primitive.ObjectID) (models.Taxonomy, error) {
+	taxonomy := models.Taxonomy{
+		ID:         models.TaxonomyID,
+		Categories: req.Categories,
+		Priorities: req.Priorities,
+		UpdatedAt:  time.Now(),
+		UpdatedBy:  updatedBy,
+	}
+	if err := ValidateTaxonomy(taxonomy); err != nil {
+		return models.Taxonomy{}, err
+	}
+
+	_, err := s.db.GetCollection("taxonomy").ReplaceOne(ctx, bson.M{"_id": models.TaxonomyID}, taxonomy, options.Replace().SetUpsert(true))
+	if err != nil {
+		return models.Taxonomy{}, err
+	}
+	return taxonomy, nil
+}
+
+// ValidateTaxonomy checks that a taxonomy has at least one category and
+// priority, and that names are non-empty and unique.
+func ValidateTaxonomy(t models.Taxonomy) error {
+	if len(t.Categories) == 0 {
+		return fmt.Errorf("taxonomy must define at least one category")
+	}
+	if len(t.Priorities) == 0 {
+		return fmt.Errorf("taxonomy must define at least one priority")
+	}
+
+	categories := map[models.TicketCategory]bool{}
+	for _, category := range t.Categories {
+		if strings.TrimSpace(string(category.Name)) == "" {
+			return fmt.Errorf("category name is required")
+		}
+		if categories[category.Name] {
+			return fmt.Errorf("duplicate category: %s", category.Name)
+		}
+		categories[category.Name] = true
+
+		subcategories := map[string]bool{}
+		for _, sub := range category.Subcategories {
+			if strings.TrimSpace(sub) == "" {
+				return fmt.Errorf("subcategory name is required in %s", category.Name)
+			}
+			if subcategories[sub] {
+				return fmt.Errorf("duplicate subcategory %s in %s", sub, category.Name)
+			}
+			subcategories[sub] = true
+		}
+	}
+
+	priorities := map[models.TicketPriority]bool{}
+	for _, priority := range t.Priorities {
+		if strings.TrimSpace(string(priority.Name)) == "" {
+			return fmt.Errorf("priority name is required")
+		}
+		if priorities[priority.Name] {
+			return fmt.Errorf("duplicate priority: %s", priority.Name)
+		}
+		if priority.ResponseSLAMinutes < 0 || priority.ResolutionSLAHours < 0 {
+			return fmt.Errorf("SLA targets for %s must not be negative", priority.Name)
+		}
+		priorities[priority.Name] = true
+	}
+	return nil
+}
+
+// ValidateTicketFields checks category, subcategory and priority values against
+// the current taxonomy. Empty values are accepted so callers can apply their
+// own defaults; a subcategory must belong to the given primary category.
+func (s *TaxonomyService) ValidateTicketFields(ctx context.Context, category models.TicketCategory, subcategory string, secondary []models.TicketCategory, priority models.TicketPriority) error {
+	taxonomy := s.Get(ctx)
+	for _, c := range append([]models.TicketCategory{category}, secondary...) {
+		if c != "" && FindCategory(taxonomy, c) == nil {
+			return fmt.Errorf("unknown category: %s", c)
+		}
+	}
+	if subcategory != "" && !HasSubcategory(taxonomy, category, subcategory) {
+		return fmt.Errorf("unknown subcategory %s for category %s", subcategory, category)
+	}
+	if priority != "" && FindPriority(taxonomy, priority) == nil {
+		return fmt.Errorf("unknown priority: %s", priority)
+	}
+	return nil
+}
+
+// DefaultCategory is applied to tickets created without a category: "Other"
+// when the taxonomy has it, otherwise the last (catch-all) category.
+func DefaultCategory(t models.Taxonomy) models.TicketCategory {
+	if FindCategory(t, models.CategoryOther) != nil || len(t.Categories) == 0 {
+		return models.CategoryOther
+	}
+	return t.Categories[len(t.Categories)-1].Name
+}
+
+// DefaultPriority is applied to tickets created without a priority: "medium"
+// when the taxonomy has it, otherwise the middle priority.
+func DefaultPriority(t models.Taxonomy) models.TicketPriority {
+	if FindPriority(t, models.PriorityMedium) != nil || len(t.Priorities) == 0 {
+		return models.PriorityMedium
+	}
+	return t.Priorities[len(t.Priorities)/2].Name
+}
+
+func FindCategory(t models.Taxonomy, name models.TicketCategory) *models.TaxonomyCategory {
+	for i := range t.Categories {
+		if t.Categories[i].Name == name {
+			return &t.Categories[i]
+		}
+	}
+	return nil
+}
+
+// HasSubcategory reports whether subcategory is defined under category.
+func HasSubcategory(t models.Taxonomy, category models.TicketCategory, subcategory string) bool {
+	c := FindCategory(t, category)
+	if c == nil {
+		return false
+	}
+	for _, sub := range c.Subcategories {
+		if sub == subcategory {
+			return true
+		}
+	}
+	return false
+}
+
+func FindPriority(t models.Taxonomy, name models.TicketPriority) *models.TaxonomyPriority {
+	for i := range t.Priorities {
+		if t.Priorities[i].Name == name {
+			return &t.Priorities[i]
+		}
+	}
+	return nil
+}
+
+// TaxonomyPrompt renders the taxonomy as a prompt section so the LLM picks
+// categories and priorities from the organization's own lists.
+func TaxonomyPrompt(t models.Taxonomy) string {
+	var b strings.Builder
+	b.WriteString("Allowed categories:\n")
+	for _, category := range t.Categories {
+		b.WriteString(fmt.Sprintf("- %q", category.Name))
+		if category.Description != "" {
+			b.WriteString(": " + category.Description)
+		}
+		if len(category.Subcategories) > 0 {
+			b.WriteString(fmt.Sprintf(" (subcategories: %s)", strings.Join(category.Subcategories, ", ")))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nAllowed priorities:\n")
+	for _, priority := range t.Priorities {
+		b.WriteString(fmt.Sprintf("- %q", priority.Name))
+		if priority.Description != "" {
+			b.WriteString(": " + priority.Description)
+		}
+		if priority.ResolutionSLAHours > 0 {
+			b.WriteString(fmt.Sprintf(" (resolve within %dh)", priority.ResolutionSLAHours))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	technicianCandidates = 3
+	// Scores weigh resolution success, satisfaction and whether triage
+	// named the technician.
+	candidateSuccessWeight = 0.5
+	candidateRatingWeight  = 0.4
+	candidateMatchWeight   = 0.1
+	// candidatePriorRating is the satisfaction assumed for technicians with
+	// few ratings, about 3.4 of 5; skillPriorWeight sets how many tickets
+	// it counts as.
+	candidatePriorRating = 0.6
+)
+
+// candidateHistory is one technician's record with a category.
+type candidateHistory struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Assigned  int64              `bson:"assigned"`
+	Succeeded int64              `bson:"succeeded"`
+	Ratings   int64              `bson:"ratings"`
+	RatingSum float64            `bson:"ratingSum"`
+}
+
+// suggestTechnicians ranks technicians for the triaged category by their
+// history with it over the last 180 days, archived tickets included, and
+// keeps the best three. Technicians excluded from the category by a skill
+// override, out of office or off shift are left out; when that leaves no
+// one, the on-call technician is the only candidate. When there are
+// candidates the first becomes the suggested technician.
+func (s *TriageService) suggestTechnicians(ctx context.Context, response *models.TriageResponse) error {
+	cursor, err := s.db.GetCollection("users").Find(ctx, bson.M{"role": models.RoleTechnician},
+		options.Find().SetProjection(bson.M{"name": 1}))
+	if err != nil {
+		return err
+	}
+	var technicians []models.User
+	if err := cursor.All(ctx, &technicians); err != nil {
+		return err
+	}
+	if len(technicians) == 0 {
+		return nil
+	}
+	ids := make([]primitive.ObjectID, len(technicians))
+	for i, t := range technicians {
+		ids[i] = t.ID
+	}
+	now := time.Now()
+	unavailable, err := s.availability.Unavailable(ctx, ids, now)
+	if err != nil {
+		return err
+	}
+
+	excluded := map[primitive.ObjectID]bool{}
+	cursor, err = s.db.GetCollection("technician_skills").Find(ctx, bson.M{
+		"overrides": bson.M{"$elemMatch": bson.M{"category": response.Category, "excluded": true}},
+	}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	var profiles []models.SkillProfile
+	if err := cursor.All(ctx, &profiles); err != nil {
+		return err
+	}
+	for _, p := range profiles {
+		excluded[p.TechnicianID] = true
+	}
+
+	match := bson.M{
+		"category":   response.Category,
+		"assignedTo": bson.M{"$exists": true, "$ne": nil},
+		"createdAt":  bson.M{"$gte": now.AddDate(0, 0, -skillHistoryDays)},
+		// Duplicates were closed by the merge, not resolved
+		"duplicateOf": bson.M{"$exists": false},
+	}
+	cursor, err = s.db.GetCollection("tickets").Aggregate(ctx, bson.A{
+		bson.M{"$match": match},
+		bson.M{"$unionWith": bson.M{"coll": "tickets_archive", "pipeline": bson.A{bson.M{"$match": match}}}},
+		bson.M{"$group": bson.M{
+			"_id":      "$assignedTo",
+			"assigned": bson.M{"$sum": 1},
+			"succeeded": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{
+					bson.M{"$in": bson.A{"$status", bson.A{models.StatusResolved, models.StatusClosed}}},
+					bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$reopenCount", 0}}, 0}},
+				}},
+				1, 0,
+			}}},
+			"ratings":   bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$rating.score", nil}}, 1, 0}}},
+			"ratingSum": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$rating.score", 0}}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	var history []candidateHistory
+	if err := cursor.All(ctx, &history); err != nil {
+		return err
+	}
+	byTechnician := map[primitive.ObjectID]candidateHistory{}
+	for _, h := range history {
+		byTechnician[h.ID] = h
+	}
+
+	named := strings.TrimSpace(response.SuggestedTechnician)
+	candidates := []models.TechnicianCandidate{}
+	namedAway := false
+	for _, t := range technicians {
+		matched := named != "" && strings.EqualFold(t.Name, named)
+		if unavailable[t.ID] != "" {
+			namedAway = namedAway || matched
+			continue
+		}
+		if excluded[t.ID] {
+			continue
+		}
+		candidates = append(candidates, scoreCandidate(t, byTechnician[t.ID], matched))
+	}
+	if len(candidates) == 0 {
+		shift, err := s.availability.OnCall(ctx, now)
+		if err != nil {
+			return err
+		}
+		if shift == nil {
+			if namedAway {
+				response.SuggestedTechnician = ""
+			}
+			return nil
+		}
+		candidate := scoreCandidate(models.User{ID: shift.UserID, Name: shift.Name}, byTechnician[shift.UserID],
+			named != "" && strings.EqualFold(shift.Name, named))
+		candidate.OnCall = true
+		candidates = append(candidates, candidate)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Assigned > candidates[j].Assigned
+	})
+	if len(candidates) > technicianCandidates {
+		candidates = candidates[:technicianCandidates]
+	}
+	if len(candidates) > 0 {
+		response.TechnicianCandidates = candidates
+		response.SuggestedTechnician = candidates[0].TechnicianName
+	}
+	return nil
+}
+
+// scoreCandidate blends a technician's success rate and satisfaction, each
+// shrunk towards a cautious default while there is little history, so one
+// good rating does not outrank a long record.
+func scoreCandidate(technician models.User, h candidateHistory, matched bool) models.TechnicianCandidate {
+	candidate := models.TechnicianCandidate{
+		TechnicianID:   technician.ID,
+		TechnicianName: technician.Name,
+		Assigned:       h.Assigned,
+		Ratings:        h.Ratings,
+		Matched:        matched,
+	}
+	if h.Assigned > 0 {
+		candidate.SuccessRate = round2(float64(h.Succeeded) / float64(h.Assigned))
+	}
+	if h.Ratings > 0 {
+		average := round2(h.RatingSum / float64(h.Ratings))
+		candidate.AverageRating = &average
+	}
+
+	success := (float64(h.Succeeded) + skillPriorWeight*skillPriorScore) / (float64(h.Assigned) + skillPriorWeight)
+	// Ratings run from 1 to 5
+	satisfaction := (h.RatingSum - float64(h.Ratings) + 4*skillPriorWeight*candidatePriorRating) / (4 * (float64(h.Ratings) + skillPriorWeight))
+	score := candidateSuccessWeight*success + candidateRatingWeight*satisfaction
+	if matched {
+		score += candidateMatchWeight
+	}
+	candidate.Score = math.Round(score*1000) / 1000
+	return candidate
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// Tickets are moved to the archive in batches of this size.
+const ticketArchiveBatchSize = 500
+
+// TicketArchiveService moves old resolved and closed tickets out of the
+// tickets collection into tickets_archive, keeping the collection every
+// list and dashboard query reads small. Archived tickets keep their _id and
+// their comments, and are read through Get. Deleted tickets are moved to
+// tickets_deleted the same way, so an admin can restore them.
+type TicketArchiveService struct {
+	db    *database.MongoDB
+	after time.Duration
+}
+
+func NewTicketArchiveService(db *database.MongoDB, cfg *config.Config) *TicketArchiveService {
+	return &TicketArchiveService{db: db, after: cfg.TicketArchiveAfter}
+}
+
+func (s *TicketArchiveService) tickets() *mongo.Collection {
+	return s.db.GetCollection("tickets")
+}
+
+func (s *TicketArchiveService) archive() *mongo.Collection {
+	return s.db.GetCollection("tickets_archive")
+}
+
+func (s *TicketArchiveService) deleted() *mongo.Collection {
+	return s.db.GetCollection("tickets_deleted")
+}
+
+// Start archives tickets every interval.
+func (s *TicketArchiveService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if _, err := s.Archive(ctx); err != nil {
+					log.Printf("ticket archive error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func archivableStatuses() bson.A {
+	return bson.A{models.StatusResolved, models.StatusClosed}
+}
+
+// Archive moves resolved and closed tickets that were resolved, or last
+// updated when the resolution time is unknown, before the archive threshold.
+// It returns the number of tickets moved.
+func (s *TicketArchiveService) Archive(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.after)
+	filter := bson.M{
+		"status": bson.M{"$in": archivableStatuses()},
+		"$or": bson.A{
+			bson.M{"resolvedAt": bson.M{"$lt": cutoff}},
+			bson.M{"resolvedAt": nil, "updatedAt": bson.M{"$lt": cutoff}},
+		},
+	}
+
+	moved := 0
+	for {
+		cursor, err := s.tickets().Find(ctx, filter, options.Find().SetLimit(ticketArchiveBatchSize))
+		if err != nil {
+			return moved, err
+		}
+		var batch []bson.M
+		if err := cursor.All(ctx, &batch); err != nil {
+			return moved, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		n, err := s.moveBatch(ctx, batch)
+		moved += n
+		if err != nil {
+			return moved, err
+		}
+		if len(batch) < ticketArchiveBatchSize {
+			break
+		}
+	}
+	if moved > 0 {
+		log.Printf("Archived %d ticket(s) resolved before %s", moved, cutoff.Format("2006-01-02"))
+	}
+	return moved, nil
+}
+
+// moveBatch copies the tickets to the archive before removing them, so a
+// failure in between leaves a ticket in both collections rather than in
+// neither. Copies are upserts, which makes the next run finish the move.
+func (s *TicketArchiveService) moveBatch(ctx context.Context, batch []bson.M) (int, error) {
+	now := time.Now()
+	writes := make([]mongo.WriteModel, 0, len(batch))
+	ids := make(bson.A, 0, len(batch))
+	for _, doc := range batch {
+		doc["archivedAt"] = now
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": doc["_id"]}).
+			SetReplacement(doc).
+			SetUpsert(true))
+		ids = append(ids, doc["_id"])
+	}
+	if _, err := s.archive().BulkWrite(ctx, writes); err != nil {
+		return 0, err
+	}
+
+	// A ticket reopened since it was read stays in the hot collection, and
+	// its stale copy is dropped from the archive.
+	res, err := s.tickets().DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "status": bson.M{"$in": archivableStatuses()}})
+	if err != nil {
+		return 0, err
+	}
+	if int(res.DeletedCount) < len(ids) {
+		var reopened []struct {
+			ID interface{} `bson:"_id"`
+		}
+		cursor, err := s.tickets().Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+		if err != nil {
+			return int(res.DeletedCount), err
+		}
+		if err := cursor.All(ctx, &reopened); err != nil {
+			return int(res.DeletedCount), err
+		}
+		stale := make(bson.A, 0, len(reopened))
+		for _, r := range reopened {
+			stale = append(stale, r.ID)
+		}
+		if len(stale) > 0 {
+			if _, err := s.archive().DeleteMany(ctx, bson.M{"_id": bson.M{"$in": stale}}); err != nil {
+				return int(res.DeletedCount), err
+			}
+		}
+	}
+	return int(res.DeletedCount), nil
+}
+
+// Get returns the ticket with the id from the tickets collection, or from
+// the archive when it has been archived.
+func (s *TicketArchiveService) Get(ctx context.Context, id primitive.ObjectID) (models.Ticket, error) {
+	var ticket models.Ticket
+	err := s.tickets().FindOne(ctx, bson.M{"_id": id}).Decode(&ticket)
+	if err != mongo.ErrNoDocuments {
+		return ticket, err
+	}
+	err = s.archive().FindOne(ctx, bson.M{"_id": id}).Decode(&ticket)
+	return ticket, err
+}
+
+// List returns a page of archived tickets matching filter, newest first,
+// and the number of matches.
+func (s *TicketArchiveService) List(ctx context.Context, filter bson.M, skip, limit int64) ([]models.Ticket, int64, error) {
+	opts := options.Find().
+		SetSkip(skip).
+		SetLimit(limit).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := s.archive().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	tickets := []models.Ticket{}
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return nil, 0, err
+	}
+	total, err := s.archive().CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tickets, total, nil
+}
+
+// Delete moves a ticket to tickets_deleted, recording when and by whom.
+// Its comments, worklogs and attachments are kept for Restore. Like the
+// archive, the copy is written before the ticket is removed.
+func (s *TicketArchiveService) Delete(ctx context.Context, id, deletedBy primitive.ObjectID) error {
+	var doc bson.M
+	if err := s.tickets().FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+		return err
+	}
+	doc["deletedAt"] = time.Now()
+	doc["deletedBy"] = deletedBy
+	if _, err := s.deleted().ReplaceOne(ctx, bson.M{"_id": id}, doc, options.Replace().SetUpsert(true)); err != nil {
+		return err
+	}
+	res, err := s.tickets().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Restore moves a deleted ticket back to the tickets collection.
+func (s *TicketArchiveService) Restore(ctx context.Context, id primitive.ObjectID) (models.Ticket, error) {
+	var doc bson.M
+	if err := s.deleted().FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+		return models.Ticket{}, err
+	}
+	delete(doc, "deletedAt")
+	delete(doc, "deletedBy")
+	doc["updatedAt"] = time.Now()
+	if _, err := s.tickets().ReplaceOne(ctx, bson.M{"_id": id}, doc, options.Replace().SetUpsert(true)); err != nil {
+		return models.Ticket{}, err
+	}
+	if _, err := s.deleted().DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return models.Ticket{}, err
+	}
+	var ticket models.Ticket
+	err := s.tickets().FindOne(ctx, bson.M{"_id": id}).Decode(&ticket)
+	return ticket, err
+}
+
+// ListDeleted returns a page of deleted tickets, most recently deleted
+// first, and the number of deleted tickets.
+func (s *TicketArchiveService) ListDeleted(ctx context.Context, skip, limit int64) ([]models.Ticket, int64, error) {
+	opts := options.Find().
+		SetSkip(skip).
+		SetLimit(limit).
+		SetSort(bson.D{{Key: "deletedAt", Value: -1}})
+	cursor, err := s.deleted().Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	tickets := []models.Ticket{}
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return nil, 0, err
+	}
+	total, err := s.deleted().CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+	return tickets, total, nil
+}
+
+// Purge removes a deleted ticket for good. The caller removes its
+// attachments.
+func (s *TicketArchiveService) Purge(ctx context.Context, id primitive.ObjectID) error {
+	res, err := s.deleted().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
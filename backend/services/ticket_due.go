@@ -0,0 +1,48 @@
+package services
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"intelliops-ai-copilot/models"
+)
+
+// liveStatuses are the statuses a ticket can be overdue in.
+func liveStatuses() bson.A {
+	return bson.A{models.StatusOpen, models.StatusInProgress}
+}
+
+// FillDueDate sets the due date of a ticket without one to its priority's
+// resolution target from creation, marking it as derived, and flags open
+// and in-progress tickets past due at now.
+func FillDueDate(taxonomy models.Taxonomy, ticket *models.Ticket, now time.Time) {
+	if ticket.DueAt == nil {
+		for _, p := range taxonomy.Priorities {
+			if p.Name == ticket.Priority && p.ResolutionSLAHours > 0 {
+				due := ticket.CreatedAt.Add(time.Duration(p.ResolutionSLAHours) * time.Hour)
+				ticket.DueAt, ticket.DueFromSLA = &due, true
+				break
+			}
+		}
+	}
+	live := ticket.Status == models.StatusOpen || ticket.Status == models.StatusInProgress
+	ticket.Overdue = live && ticket.DueAt != nil && ticket.DueAt.Before(now)
+}
+
+// OverdueFilter matches open and in-progress tickets past due at now:
+// those with a due date before now, and those without one created longer
+// ago than their priority's resolution target.
+func OverdueFilter(taxonomy models.Taxonomy, now time.Time) bson.M {
+	due := bson.A{bson.M{"dueAt": bson.M{"$lt": now}}}
+	for _, p := range taxonomy.Priorities {
+		if p.ResolutionSLAHours > 0 {
+			due = append(due, bson.M{
+				"dueAt":     nil,
+				"priority":  p.Name,
+				"createdAt": bson.M{"$lt": now.Add(-time.Duration(p.ResolutionSLAHours) * time.Hour)},
+			})
+		}
+	}
+	return bson.M{"status": bson.M{"$in": liveStatuses()}, "$or": due}
+}
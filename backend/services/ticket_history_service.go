@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// Text fields longer than this are cut short in the history.
+const ticketHistoryTextLimit = 200
+
+// TicketHistoryService keeps the audit trail of ticket field changes.
+type TicketHistoryService struct {
+	db *database.MongoDB
+}
+
+func NewTicketHistoryService(db *database.MongoDB) *TicketHistoryService {
+	return &TicketHistoryService{db: db}
+}
+
+func (s *TicketHistoryService) collection() *mongo.Collection {
+	return s.db.GetCollection("ticket_history")
+}
+
+// DiffTicket lists the tracked fields that differ between two versions of
+// a ticket.
+func DiffTicket(before, after models.Ticket) []models.TicketFieldChange {
+	changes := []models.TicketFieldChange{}
+	add := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, models.TicketFieldChange{Field: field, Old: old, New: new})
+		}
+	}
+	add("title", before.Title, after.Title)
+	add("description", truncateWords(before.Description, ticketHistoryTextLimit), truncateWords(after.Description, ticketHistoryTextLimit))
+	add("category", string(before.Category), string(after.Category))
+	add("subcategory", before.Subcategory, after.Subcategory)
+	add("secondaryCategories", joinCategories(before.SecondaryCategories), joinCategories(after.SecondaryCategories))
+	add("priority", string(before.Priority), string(after.Priority))
+	add("status", string(before.Status), string(after.Status))
+	add("assignedTo", objectIDString(before.AssignedTo), objectIDString(after.AssignedTo))
+	add("department", before.Department, after.Department)
+	add("location", before.Location, after.Location)
+	add("team", before.Team, after.Team)
+	add("tags", strings.Join(before.Tags, ", "), strings.Join(after.Tags, ", "))
+	add("duplicateOf", objectIDString(before.DuplicateOf), objectIDString(after.DuplicateOf))
+	keys := []string{}
+	for k := range before.CustomFields {
+		keys = append(keys, k)
+	}
+	for k := range after.CustomFields {
+		if _, ok := before.CustomFields[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		add("customFields."+k, customFieldString(before.CustomFields[k]), customFieldString(after.CustomFields[k]))
+	}
+	return changes
+}
+
+func joinCategories(categories []models.TicketCategory) string {
+	names := make([]string, len(categories))
+	for i, c := range categories {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
+
+func objectIDString(id *primitive.ObjectID) string {
+	if id == nil {
+		return ""
+	}
+	return id.Hex()
+}
+
+// Record stores the changes user made to a ticket. Updates that changed no
+// tracked field are not recorded.
+func (s *TicketHistoryService) Record(ctx context.Context, ticketID primitive.ObjectID, user models.User, changes []models.TicketFieldChange) error {
+	return s.RecordOnBehalf(ctx, ticketID, user, nil, changes)
+}
+
+// RecordOnBehalf stores changes user made standing in for onBehalfOf,
+// which may be nil when they acted for themselves.
+func (s *TicketHistoryService) RecordOnBehalf(ctx context.Context, ticketID primitive.ObjectID, user models.User, onBehalfOf *models.User, changes []models.TicketFieldChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	entry := models.TicketHistoryEntry{
+		ID:            primitive.NewObjectID(),
+		TicketID:      ticketID,
+		ChangedBy:     user.ID,
+		ChangedByName: user.Name,
+		Changes:       changes,
+		CreatedAt:     time.Now(),
+	}
+	if onBehalfOf != nil {
+		entry.OnBehalfOf, entry.OnBehalfOfName = &onBehalfOf.ID, onBehalfOf.Name
+	}
+	_, err := s.collection().InsertOne(ctx, entry)
+	return err
+}
+
+// List returns one page of a ticket's history, newest first, with the
+// number of entries.
+func (s *TicketHistoryService) List(ctx context.Context, ticketID primitive.ObjectID, skip, limit int64) ([]models.TicketHistoryEntry, int64, error) {
+	filter := bson.M{"ticketId": ticketID}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).SetSkip(skip).SetLimit(limit)
+	cursor, err := s.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	entries := []models.TicketHistoryEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+	total, err := s.collection().CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,414 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// MaxTicketImportRows is the most data rows one CSV import may hold.
+const MaxTicketImportRows = 5000
+
+// ErrTicketImportFile is returned for CSV files that cannot be imported at
+// all, as opposed to single rows that fail.
+var ErrTicketImportFile = errors.New("invalid ticket import file")
+
+// ticketImportColumns maps normalized CSV header names, as exported by
+// common helpdesks, to the ticket field they fill.
+var ticketImportColumns = map[string]string{
+	"title": "title", "subject": "title", "summary": "title",
+	"description": "description", "body": "description", "details": "description",
+	"category": "category", "type": "category", "subcategory": "subcategory",
+	"priority": "priority", "urgency": "priority",
+	"status": "status", "state": "status",
+	"tags": "tags", "labels": "tags",
+	"assignedto": "assignedTo", "assignee": "assignedTo", "assigneeemail": "assignedTo", "agent": "assignedTo",
+	"createdby": "createdBy", "requester": "createdBy", "requesteremail": "createdBy", "reporter": "createdBy",
+	"createdat": "createdAt", "created": "createdAt", "opened": "createdAt", "openedat": "createdAt",
+	"updatedat": "updatedAt", "updated": "updatedAt",
+	"resolvedat": "resolvedAt", "resolved": "resolvedAt", "closedat": "resolvedAt", "closed": "resolvedAt",
+	"department": "department", "location": "location", "team": "team", "group": "team",
+	"externalid": "externalId", "id": "externalId", "ticketid": "externalId", "number": "externalId", "ticketnumber": "externalId",
+}
+
+// ticketImportStatuses maps status names used by other helpdesks to ours.
+var ticketImportStatuses = map[string]models.TicketStatus{
+	"open": models.StatusOpen, "new": models.StatusOpen, "pending": models.StatusOpen,
+	"inprogress": models.StatusInProgress, "active": models.StatusInProgress, "assigned": models.StatusInProgress,
+	"resolved": models.StatusResolved, "solved": models.StatusResolved, "done": models.StatusResolved,
+	"closed": models.StatusClosed, "cancelled": models.StatusClosed, "canceled": models.StatusClosed,
+}
+
+// ticketImportTimeLayouts are the timestamp formats accepted in CSV files.
+var ticketImportTimeLayouts = []string{
+	time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02", "01/02/2006 15:04", "01/02/2006",
+}
+
+// normalizeImportKey lowercases s and drops spaces, dashes and underscores,
+// so "Assigned To", "assigned_to" and "assignedTo" match.
+func normalizeImportKey(s string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// TicketImportService creates tickets in bulk from CSV exports of another
+// helpdesk.
+type TicketImportService struct {
+	db       *database.MongoDB
+	taxonomy *TaxonomyService
+	org      *OrgService
+}
+
+func NewTicketImportService(db *database.MongoDB, taxonomy *TaxonomyService, org *OrgService) *TicketImportService {
+	return &TicketImportService{db: db, taxonomy: taxonomy, org: org}
+}
+
+// ticketImport holds what is needed to turn rows into tickets.
+type ticketImport struct {
+	taxonomy models.Taxonomy
+	columns  map[int]string
+	users    map[string]models.User
+	seen     map[string]bool
+	importer models.User
+	router   *TicketRouter
+	now      time.Time
+}
+
+// Import reads a CSV file with a header row and creates a ticket per valid
+// row, reporting the rows it could not import. Rows whose external ID was
+// imported before are skipped, so an import can be retried. With dryRun
+// nothing is stored.
+func (s *TicketImportService) Import(ctx context.Context, r io.Reader, importer models.User, dryRun bool) (models.TicketImportResult, error) {
+	result := models.TicketImportResult{
+		DryRun:         dryRun,
+		TicketIDs:      []primitive.ObjectID{},
+		Errors:         []models.TicketImportError{},
+		Columns:        map[string]string{},
+		IgnoredColumns: []string{},
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return result, fmt.Errorf("%w: the CSV file is empty", ErrTicketImportFile)
+	}
+	if err != nil {
+		return result, fmt.Errorf("%w: bad CSV header: %v", ErrTicketImportFile, err)
+	}
+
+	imp := ticketImport{
+		taxonomy: s.taxonomy.Get(ctx),
+		columns:  map[int]string{},
+		seen:     map[string]bool{},
+		importer: importer,
+		now:      time.Now(),
+	}
+	mapped := map[string]bool{}
+	for i, name := range header {
+		// Excel prefixes UTF-8 files with a byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		field, ok := ticketImportColumns[normalizeImportKey(name)]
+		if !ok || mapped[field] {
+			result.IgnoredColumns = append(result.IgnoredColumns, name)
+			continue
+		}
+		mapped[field] = true
+		imp.columns[i] = field
+		result.Columns[name] = field
+	}
+	if !mapped["title"] {
+		return result, fmt.Errorf("%w: a title (or subject) column is required", ErrTicketImportFile)
+	}
+	if imp.users, err = s.userIndex(ctx); err != nil {
+		return result, err
+	}
+	if imp.router, err = s.org.Router(ctx); err != nil {
+		return result, err
+	}
+
+	var tickets []models.Ticket
+	rows := map[primitive.ObjectID]int{}
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if result.Rows == MaxTicketImportRows {
+			return result, fmt.Errorf("%w: more than %d rows; split it into smaller files", ErrTicketImportFile, MaxTicketImportRows)
+		}
+		result.Rows++
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, models.TicketImportError{Row: row, Message: err.Error()})
+			continue
+		}
+
+		ticket, err := s.ticket(ctx, &imp, record)
+		var rowErr *importRowError
+		switch {
+		case err == errTicketAlreadyImported:
+			result.Skipped++
+		case errors.As(err, &rowErr):
+			result.Failed++
+			result.Errors = append(result.Errors, models.TicketImportError{Row: row, Column: rowErr.column, Message: rowErr.message})
+		case err != nil:
+			return result, err
+		default:
+			rows[ticket.ID] = row
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	if !dryRun && len(tickets) > 0 {
+		docs := make([]interface{}, len(tickets))
+		for i := range tickets {
+			docs[i] = tickets[i]
+		}
+		_, err := s.db.GetCollection("tickets").InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+		// Unordered inserts store every ticket they can; report the others
+		failed := map[int]bool{}
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) {
+			for _, e := range bulkErr.WriteErrors {
+				failed[e.Index] = true
+				result.Failed++
+				result.Errors = append(result.Errors, models.TicketImportError{Row: rows[tickets[e.Index].ID], Message: "failed to store ticket"})
+			}
+		} else if err != nil {
+			return result, err
+		}
+		for i := range tickets {
+			if !failed[i] {
+				result.TicketIDs = append(result.TicketIDs, tickets[i].ID)
+			}
+		}
+		result.Created = len(result.TicketIDs)
+	} else {
+		result.Created = len(tickets)
+	}
+	return result, nil
+}
+
+// errTicketAlreadyImported marks rows whose external ID is already on a
+// ticket.
+var errTicketAlreadyImported = errors.New("ticket already imported")
+
+// importRowError is a problem with one CSV row, reported back per row.
+type importRowError struct {
+	column  string
+	message string
+}
+
+func (e *importRowError) Error() string {
+	return e.column + ": " + e.message
+}
+
+// userIndex maps the lowercased email and hex ID of every user to the
+// user, for the assignee and requester columns.
+func (s *TicketImportService) userIndex(ctx context.Context) (map[string]models.User, error) {
+	cursor, err := s.db.GetCollection("users").Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"email": 1, "department": 1, "location": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	index := map[string]models.User{}
+	for _, u := range users {
+		index[strings.ToLower(u.Email)] = u
+		index[u.ID.Hex()] = u
+	}
+	return index, nil
+}
+
+// ticket builds the ticket for one CSV record, or explains why it cannot.
+func (s *TicketImportService) ticket(ctx context.Context, imp *ticketImport, record []string) (models.Ticket, error) {
+	values := map[string]string{}
+	for i, value := range record {
+		if field, ok := imp.columns[i]; ok {
+			values[field] = strings.TrimSpace(value)
+		}
+	}
+	fail := func(column, format string, args ...interface{}) (models.Ticket, error) {
+		return models.Ticket{}, &importRowError{column: column, message: fmt.Sprintf(format, args...)}
+	}
+
+	ticket := models.Ticket{
+		ID:          primitive.NewObjectID(),
+		Title:       values["title"],
+		Description: values["description"],
+		Status:      models.StatusOpen,
+		Department:  values["department"],
+		Location:    values["location"],
+		Team:        values["team"],
+		ExternalID:  values["externalId"],
+		CreatedBy:   imp.importer.ID,
+		CreatedAt:   imp.now,
+	}
+	if ticket.Title == "" {
+		return fail("title", "title is required")
+	}
+	if ticket.Description == "" {
+		ticket.Description = ticket.Title
+	}
+
+	if ticket.ExternalID != "" {
+		if imp.seen[ticket.ExternalID] {
+			return fail("externalId", "external ID %s appears more than once in the file", ticket.ExternalID)
+		}
+		imp.seen[ticket.ExternalID] = true
+		count, err := s.db.GetCollection("tickets").CountDocuments(ctx, bson.M{"externalId": ticket.ExternalID})
+		if err != nil {
+			return models.Ticket{}, err
+		}
+		if count > 0 {
+			return models.Ticket{}, errTicketAlreadyImported
+		}
+	}
+
+	// Categories and priorities match the taxonomy regardless of case
+	if v := values["category"]; v != "" {
+		category := importCategory(imp.taxonomy, v)
+		if category == nil {
+			return fail("category", "unknown category: %s", v)
+		}
+		ticket.Category = category.Name
+		if sub := values["subcategory"]; sub != "" {
+			for _, name := range category.Subcategories {
+				if strings.EqualFold(name, sub) {
+					ticket.Subcategory = name
+				}
+			}
+			if ticket.Subcategory == "" {
+				return fail("subcategory", "unknown subcategory %s for category %s", sub, category.Name)
+			}
+		}
+	} else if values["subcategory"] != "" {
+		return fail("subcategory", "category is required when subcategory is set")
+	} else {
+		ticket.Category = DefaultCategory(imp.taxonomy)
+	}
+	if v := values["priority"]; v != "" {
+		for _, p := range imp.taxonomy.Priorities {
+			if strings.EqualFold(string(p.Name), v) {
+				ticket.Priority = p.Name
+			}
+		}
+		if ticket.Priority == "" {
+			return fail("priority", "unknown priority: %s", v)
+		}
+	} else {
+		ticket.Priority = DefaultPriority(imp.taxonomy)
+	}
+	if v := values["status"]; v != "" {
+		status, ok := ticketImportStatuses[normalizeImportKey(v)]
+		if !ok {
+			return fail("status", "unknown status: %s", v)
+		}
+		ticket.Status = status
+	}
+
+	if v := values["tags"]; v != "" {
+		tags, err := MergeTicketTags(nil, strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ';' || r == '|' }))
+		if err != nil {
+			return fail("tags", "%v", err)
+		}
+		ticket.Tags = tags
+	}
+
+	requester := imp.importer
+	for _, column := range []string{"assignedTo", "createdBy"} {
+		v := values[column]
+		if v == "" {
+			continue
+		}
+		user, ok := imp.users[strings.ToLower(v)]
+		if !ok {
+			return fail(column, "no user with email or ID %s", v)
+		}
+		if column == "assignedTo" {
+			id := user.ID
+			ticket.AssignedTo = &id
+		} else {
+			ticket.CreatedBy = user.ID
+			requester = user
+		}
+	}
+
+	times := map[string]*time.Time{}
+	for _, column := range []string{"createdAt", "updatedAt", "resolvedAt"} {
+		v := values[column]
+		if v == "" {
+			continue
+		}
+		t, err := parseImportTime(v)
+		if err != nil {
+			return fail(column, "invalid time %q; use RFC 3339 or YYYY-MM-DD HH:MM:SS", v)
+		}
+		times[column] = &t
+	}
+	if t := times["createdAt"]; t != nil {
+		if t.After(imp.now) {
+			return fail("createdAt", "createdAt is in the future")
+		}
+		ticket.CreatedAt = *t
+	}
+	ticket.UpdatedAt = ticket.CreatedAt
+	if t := times["updatedAt"]; t != nil {
+		ticket.UpdatedAt = *t
+	}
+	if ticket.Status == models.StatusResolved || ticket.Status == models.StatusClosed {
+		resolvedAt := ticket.UpdatedAt
+		if t := times["resolvedAt"]; t != nil {
+			resolvedAt = *t
+		}
+		if resolvedAt.Before(ticket.CreatedAt) {
+			return fail("resolvedAt", "resolvedAt is before createdAt")
+		}
+		ticket.ResolvedAt = &resolvedAt
+		if ticket.UpdatedAt.Before(resolvedAt) {
+			ticket.UpdatedAt = resolvedAt
+		}
+	}
+
+	// Route like a new ticket, keeping a team given in the file
+	team := ticket.Team
+	imp.router.Route(&ticket, requester)
+	if team != "" {
+		ticket.Team = team
+	}
+	return ticket, nil
+}
+
+func importCategory(t models.Taxonomy, name string) *models.TaxonomyCategory {
+	for i := range t.Categories {
+		if strings.EqualFold(string(t.Categories[i].Name), name) {
+			return &t.Categories[i]
+		}
+	}
+	return nil
+}
+
+// parseImportTime reads a CSV timestamp; times without a zone are UTC.
+func parseImportTime(v string) (time.Time, error) {
+	for _, layout := range ticketImportTimeLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", v)
+}
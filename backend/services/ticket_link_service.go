@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// maxTicketLinks caps the links on one ticket, so an incident can fan out
+// into sub-tasks without the document growing without bound.
+const maxTicketLinks = 200
+
+// maxTicketAncestors bounds the walk up the parent chain when checking for
+// cycles.
+const maxTicketAncestors = 50
+
+var (
+	// ErrTicketLinkInvalid is returned for links that are not allowed, e.g.
+	// to the ticket itself or a second parent.
+	ErrTicketLinkInvalid = errors.New("invalid ticket link")
+	// ErrTicketLinkExists is returned when the tickets are already linked
+	// that way.
+	ErrTicketLinkExists = errors.New("tickets are already linked that way")
+	// ErrLinkedTicketNotFound is returned when the ticket to link to does
+	// not exist.
+	ErrLinkedTicketNotFound = errors.New("linked ticket not found")
+)
+
+// TicketLinkService keeps the relationships between tickets. Both tickets
+// of a link carry it, so either side lists it without a lookup.
+type TicketLinkService struct {
+	db         *database.MongoDB
+	history    *TicketHistoryService
+	assignment *AssignmentService
+	taxonomy   *TaxonomyService
+}
+
+func NewTicketLinkService(db *database.MongoDB, history *TicketHistoryService, assignment *AssignmentService, taxonomy *TaxonomyService) *TicketLinkService {
+	return &TicketLinkService{db: db, history: history, assignment: assignment, taxonomy: taxonomy}
+}
+
+func (s *TicketLinkService) tickets() *mongo.Collection {
+	return s.db.GetCollection("tickets")
+}
+
+func (s *TicketLinkService) get(ctx context.Context, id primitive.ObjectID) (models.Ticket, error) {
+	var ticket models.Ticket
+	err := s.tickets().FindOne(ctx, bson.M{"_id": id}).Decode(&ticket)
+	return ticket, err
+}
+
+func hasTicketLink(ticket models.Ticket, linkType models.TicketLinkType, other primitive.ObjectID) bool {
+	for _, l := range ticket.Links {
+		if l.TicketID == other && (linkType == "" || l.Type == linkType) {
+			return true
+		}
+	}
+	return false
+}
+
+func ticketParent(ticket models.Ticket) *primitive.ObjectID {
+	for _, l := range ticket.Links {
+		if l.Type == models.TicketLinkParent {
+			id := l.TicketID
+			return &id
+		}
+	}
+	return nil
+}
+
+// Link relates ticketID to req.TicketID and stores the inverse link on the
+// other ticket.
+func (s *TicketLinkService) Link(ctx context.Context, ticketID primitive.ObjectID, req models.CreateTicketLinkRequest, user models.User) (models.TicketLink, error) {
+	inverse, ok := models.TicketLinkInverse[req.Type]
+	if !ok {
+		return models.TicketLink{}, fmt.Errorf("%w: unknown link type %q", ErrTicketLinkInvalid, req.Type)
+	}
+	otherID, err := primitive.ObjectIDFromHex(req.TicketID)
+	if err != nil {
+		return models.TicketLink{}, fmt.Errorf("%w: invalid linked ticket ID", ErrTicketLinkInvalid)
+	}
+	if otherID == ticketID {
+		return models.TicketLink{}, fmt.Errorf("%w: a ticket cannot be linked to itself", ErrTicketLinkInvalid)
+	}
+	ticket, err := s.get(ctx, ticketID)
+	if err != nil {
+		return models.TicketLink{}, err
+	}
+	other, err := s.get(ctx, otherID)
+	if err == mongo.ErrNoDocuments {
+		return models.TicketLink{}, ErrLinkedTicketNotFound
+	}
+	if err != nil {
+		return models.TicketLink{}, err
+	}
+	if hasTicketLink(ticket, req.Type, otherID) {
+		return models.TicketLink{}, ErrTicketLinkExists
+	}
+	if (req.Type == models.TicketLinkAfter || req.Type == models.TicketLinkBefore) &&
+		(ticket.Change == nil || other.Change == nil) {
+		return models.TicketLink{}, fmt.Errorf("%w: only changes can be scheduled after or before each other", ErrTicketLinkInvalid)
+	}
+	if len(ticket.Links) >= maxTicketLinks || len(other.Links) >= maxTicketLinks {
+		return models.TicketLink{}, fmt.Errorf("%w: a ticket can have at most %d links", ErrTicketLinkInvalid, maxTicketLinks)
+	}
+
+	// A ticket has one parent, and no ticket may end up its own ancestor
+	child, parent := ticket, other
+	if req.Type == models.TicketLinkChild {
+		child, parent = other, ticket
+	}
+	if req.Type == models.TicketLinkParent || req.Type == models.TicketLinkChild {
+		if ticketParent(child) != nil {
+			return models.TicketLink{}, fmt.Errorf("%w: ticket %s already has a parent", ErrTicketLinkInvalid, child.ID.Hex())
+		}
+		if err := s.checkAncestors(ctx, parent, child.ID); err != nil {
+			return models.TicketLink{}, err
+		}
+	}
+	// Changes must not end up scheduled after themselves, which could never
+	// be approved
+	if req.Type == models.TicketLinkAfter || req.Type == models.TicketLinkBefore {
+		first, then := ticket, other
+		if req.Type == models.TicketLinkAfter {
+			first, then = other, ticket
+		}
+		if err := s.checkOrder(ctx, then, first.ID); err != nil {
+			return models.TicketLink{}, err
+		}
+	}
+
+	now := time.Now()
+	link := models.TicketLink{Type: req.Type, TicketID: otherID, CreatedBy: user.ID, CreatedAt: now}
+	back := models.TicketLink{Type: inverse, TicketID: ticketID, CreatedBy: user.ID, CreatedAt: now}
+	if _, err := s.tickets().UpdateByID(ctx, ticketID, bson.M{"$push": bson.M{"links": link}, "$set": bson.M{"updatedAt": now}}); err != nil {
+		return models.TicketLink{}, err
+	}
+	if _, err := s.tickets().UpdateByID(ctx, otherID, bson.M{"$push": bson.M{"links": back}, "$set": bson.M{"updatedAt": now}}); err != nil {
+		return models.TicketLink{}, err
+	}
+
+	s.record(ctx, ticketID, user, models.TicketFieldChange{Field: "links", New: string(req.Type) + " " + otherID.Hex()})
+	s.record(ctx, otherID, user, models.TicketFieldChange{Field: "links", New: string(inverse) + " " + ticketID.Hex()})
+	return link, nil
+}
+
+// checkAncestors fails when descendant is parent or one of its ancestors.
+func (s *TicketLinkService) checkAncestors(ctx context.Context, parent models.Ticket, descendant primitive.ObjectID) error {
+	current := parent
+	for i := 0; i < maxTicketAncestors; i++ {
+		if current.ID == descendant {
+			return fmt.Errorf("%w: the link would make a ticket its own ancestor", ErrTicketLinkInvalid)
+		}
+		up := ticketParent(current)
+		if up == nil {
+			return nil
+		}
+		next, err := s.get(ctx, *up)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		current = next
+	}
+	return fmt.Errorf("%w: tickets can be nested at most %d levels deep", ErrTicketLinkInvalid, maxTicketAncestors)
+}
+
+// checkOrder fails when earlier is scheduled, directly or through other
+// changes, after later, so that linking later after earlier would close a
+// cycle. Changes scheduled after one another form chains, so the walk
+// follows before links out from later.
+func (s *TicketLinkService) checkOrder(ctx context.Context, later models.Ticket, earlier primitive.ObjectID) error {
+	seen := map[primitive.ObjectID]bool{later.ID: true}
+	level := []models.Ticket{later}
+	for depth := 0; len(level) > 0; depth++ {
+		if depth >= maxTicketAncestors {
+			return fmt.Errorf("%w: changes can be chained at most %d deep", ErrTicketLinkInvalid, maxTicketAncestors)
+		}
+		var next []primitive.ObjectID
+		for _, t := range level {
+			for _, l := range t.Links {
+				if l.Type != models.TicketLinkBefore {
+					continue
+				}
+				if l.TicketID == earlier {
+					return fmt.Errorf("%w: %s is already scheduled before %s, so the link would make a change come after itself",
+						ErrTicketLinkInvalid, later.ID.Hex(), earlier.Hex())
+				}
+				if !seen[l.TicketID] {
+					seen[l.TicketID] = true
+					next = append(next, l.TicketID)
+				}
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		cursor, err := s.tickets().Find(ctx, bson.M{"_id": bson.M{"$in": next}}, options.Find().SetProjection(bson.M{"links": 1}))
+		if err != nil {
+			return err
+		}
+		level = level[:0]
+		if err := cursor.All(ctx, &level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unlink removes the links between ticketID and otherID, of linkType only
+// when it is set, from both tickets.
+func (s *TicketLinkService) Unlink(ctx context.Context, ticketID, otherID primitive.ObjectID, linkType models.TicketLinkType, user models.User) error {
+	ticket, err := s.get(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+	if !hasTicketLink(ticket, linkType, otherID) {
+		return mongo.ErrNoDocuments
+	}
+
+	pull := bson.M{"ticketId": otherID}
+	back := bson.M{"ticketId": ticketID}
+	if linkType != "" {
+		pull["type"] = linkType
+		back["type"] = models.TicketLinkInverse[linkType]
+	}
+	now := time.Now()
+	if _, err := s.tickets().UpdateByID(ctx, ticketID, bson.M{"$pull": bson.M{"links": pull}, "$set": bson.M{"updatedAt": now}}); err != nil {
+		return err
+	}
+	// The other ticket may have been deleted since
+	if _, err := s.tickets().UpdateByID(ctx, otherID, bson.M{"$pull": bson.M{"links": back}, "$set": bson.M{"updatedAt": now}}); err != nil {
+		return err
+	}
+
+	for _, l := range ticket.Links {
+		if l.TicketID == otherID && (linkType == "" || l.Type == linkType) {
+			s.record(ctx, ticketID, user, models.TicketFieldChange{Field: "links", Old: string(l.Type) + " " + otherID.Hex()})
+			s.record(ctx, otherID, user, models.TicketFieldChange{Field: "links", Old: string(models.TicketLinkInverse[l.Type]) + " " + ticketID.Hex()})
+		}
+	}
+	return nil
+}
+
+// List returns the ticket's links with the title, status and assignee of
+// each linked ticket. Links to deleted tickets are left out.
+func (s *TicketLinkService) List(ctx context.Context, ticket models.Ticket) ([]models.TicketLinkView, error) {
+	views := []models.TicketLinkView{}
+	if len(ticket.Links) == 0 {
+		return views, nil
+	}
+	ids := make([]primitive.ObjectID, len(ticket.Links))
+	for i, l := range ticket.Links {
+		ids[i] = l.TicketID
+	}
+	cursor, err := s.tickets().Find(ctx, bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"title": 1, "status": 1, "assignedTo": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var linked []models.Ticket
+	if err := cursor.All(ctx, &linked); err != nil {
+		return nil, err
+	}
+	byID := map[primitive.ObjectID]models.Ticket{}
+	for _, t := range linked {
+		byID[t.ID] = t
+	}
+	for _, l := range ticket.Links {
+		t, ok := byID[l.TicketID]
+		if !ok {
+			continue
+		}
+		views = append(views, models.TicketLinkView{TicketLink: l, Title: t.Title, Status: t.Status, AssignedTo: t.AssignedTo})
+	}
+	return views, nil
+}
+
+func (s *TicketLinkService) record(ctx context.Context, ticketID primitive.ObjectID, user models.User, change models.TicketFieldChange) {
+	if err := s.history.Record(ctx, ticketID, user, []models.TicketFieldChange{change}); err != nil {
+		log.Printf("Failed to record history of ticket %s: %v", ticketID.Hex(), err)
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// ErrMergeInvalid is returned when a ticket cannot be merged into the
+// ticket given, e.g. itself or a ticket that is already a duplicate.
+var ErrMergeInvalid = errors.New("invalid merge")
+
+// TicketMergeService folds duplicate tickets into the ticket they repeat.
+type TicketMergeService struct {
+	db       *database.MongoDB
+	history  *TicketHistoryService
+	comments *CommentService
+}
+
+func NewTicketMergeService(db *database.MongoDB, history *TicketHistoryService, comments *CommentService) *TicketMergeService {
+	return &TicketMergeService{db: db, history: history, comments: comments}
+}
+
+// Merge moves the comments, attachments and logged time of source over to
+// target, closes source as a duplicate of target and returns the updated
+// target. Tickets merged into source earlier now point at target.
+func (s *TicketMergeService) Merge(ctx context.Context, source models.Ticket, targetID primitive.ObjectID, user models.User) (models.Ticket, error) {
+	tickets := s.db.GetCollection("tickets")
+	if source.ID == targetID {
+		return models.Ticket{}, fmt.Errorf("%w: a ticket cannot be merged into itself", ErrMergeInvalid)
+	}
+	if source.DuplicateOf != nil {
+		return models.Ticket{}, fmt.Errorf("%w: ticket is already a duplicate of %s", ErrMergeInvalid, source.DuplicateOf.Hex())
+	}
+	var target models.Ticket
+	if err := tickets.FindOne(ctx, bson.M{"_id": targetID}).Decode(&target); err != nil {
+		return models.Ticket{}, err
+	}
+	if target.DuplicateOf != nil {
+		return models.Ticket{}, fmt.Errorf("%w: target is a duplicate of %s, merge into that ticket instead", ErrMergeInvalid, target.DuplicateOf.Hex())
+	}
+	// Closing the duplicate must not leave its sub-tasks behind
+	if err := CheckSubtasksDone(ctx, s.db, source, models.StatusClosed); err != nil {
+		if errors.Is(err, ErrInvalidStatusTransition) {
+			return models.Ticket{}, fmt.Errorf("%w: the duplicate's sub-tasks must be closed first", ErrMergeInvalid)
+		}
+		return models.Ticket{}, err
+	}
+
+	now := time.Now()
+	if _, err := s.db.GetCollection("ticket_comments").UpdateMany(ctx, bson.M{"ticketId": source.ID}, bson.M{"$set": bson.M{"ticketId": target.ID}}); err != nil {
+		return models.Ticket{}, err
+	}
+	if _, err := s.db.GetCollection("ticket_attachments").UpdateMany(ctx, bson.M{"ticketId": source.ID}, bson.M{"$set": bson.M{"ticketId": target.ID}}); err != nil {
+		return models.Ticket{}, err
+	}
+	if _, err := s.db.GetCollection("ticket_worklogs").UpdateMany(ctx, bson.M{"ticketId": source.ID}, bson.M{"$set": bson.M{"ticketId": target.ID}}); err != nil {
+		return models.Ticket{}, err
+	}
+	if _, err := tickets.UpdateMany(ctx, bson.M{"duplicateOf": source.ID}, bson.M{"$set": bson.M{"duplicateOf": target.ID}}); err != nil {
+		return models.Ticket{}, err
+	}
+
+	var closed models.Ticket
+	update := bson.M{
+		"$set":   bson.M{"duplicateOf": target.ID, "updatedAt": now},
+		"$unset": bson.M{"mergedFrom": "", "timeSpentMinutes": ""},
+	}
+	// Duplicates are closed from any status, outside the usual workflow,
+	// since any work on them carries on in the target
+	ApplyStatusTransition(update, source, models.StatusClosed, now)
+	err := tickets.FindOneAndUpdate(ctx, bson.M{"_id": source.ID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&closed)
+	if err != nil {
+		return models.Ticket{}, err
+	}
+	if err := s.history.Record(ctx, source.ID, user, DiffTicket(source, closed)); err != nil {
+		return models.Ticket{}, err
+	}
+
+	merged := append([]primitive.ObjectID{source.ID}, source.MergedFrom...)
+	if err := tickets.FindOneAndUpdate(ctx, bson.M{"_id": target.ID}, bson.M{
+		"$addToSet": bson.M{"mergedFrom": bson.M{"$each": merged}},
+		"$inc":      bson.M{"timeSpentMinutes": source.TimeSpentMinutes},
+		"$set":      bson.M{"updatedAt": now},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&target); err != nil {
+		return models.Ticket{}, err
+	}
+
+	note := fmt.Sprintf("Merged duplicate ticket %s: %s", source.ID.Hex(), source.Title)
+	if _, err := s.comments.Add(ctx, target.ID, user, note, false); err != nil {
+		return models.Ticket{}, err
+	}
+	return target, nil
+}
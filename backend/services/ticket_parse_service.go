@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const ticketParseSystemPrompt = "You are an IT service desk agent turning user messages into well-formed support tickets. Always respond with valid JSON." + untrustedContentPolicy
+
+const (
+	// maxParseTicketText bounds the text sent to the LLM.
+	maxParseTicketText = 20000
+	maxParsedTitle     = 120
+)
+
+// transcriptSpeaker matches the speaker prefix of a chat transcript line,
+// such as "[10:32] Priya:" or "John Smith:".
+var transcriptSpeaker = regexp.MustCompile(`^(\[[^\]]{1,30}\]\s*)?[\p{L}][\p{L} .'-]{0,40}:\s+`)
+
+// leadingGreeting matches an opening such as "Hi," or "Hello team!".
+var leadingGreeting = regexp.MustCompile(`(?i)^(hi|hello|hey|good (morning|afternoon|evening))\b[\s\p{L}]{0,20}?[,.!]+\s*`)
+
+// TicketParseService drafts tickets from free-form text, such as a message
+// or a chat transcript from a chat integration.
+type TicketParseService struct {
+	db         *database.MongoDB
+	llm        *LLMService
+	guardrails *GuardrailService
+	taxonomy   *TaxonomyService
+}
+
+func NewTicketParseService(db *database.MongoDB, llm *LLMService, guardrails *GuardrailService, taxonomy *TaxonomyService) *TicketParseService {
+	return &TicketParseService{db: db, llm: llm, guardrails: guardrails, taxonomy: taxonomy}
+}
+
+// Parse drafts a ticket from text, falling back to keyword triage when the
+// LLM is unavailable, and matches the affected asset to an endpoint agent.
+func (s *TicketParseService) Parse(ctx context.Context, text string) (models.ParsedTicket, error) {
+	text = truncateBytes(strings.TrimSpace(text), maxParseTicketText)
+
+	parsed, err := s.generate(ctx, text)
+	if err != nil {
+		if err != ErrLLMUnavailable {
+			log.Printf("Ticket parsing failed, using fallback: %v", err)
+		}
+		parsed = mockParsedTicket(text)
+	}
+	s.applyTaxonomy(ctx, &parsed.Ticket)
+
+	agent, err := s.matchAgent(ctx, parsed.AffectedAsset, text)
+	if err != nil {
+		return models.ParsedTicket{}, err
+	}
+	if agent != nil {
+		parsed.EndpointAgent = &models.ParsedTicketAgent{
+			ID:       agent.ID.Hex(),
+			Name:     agent.Name,
+			AssetTag: agent.AssetTag,
+			Hostname: agent.Inventory.Hostname,
+		}
+		parsed.Ticket.EndpointAgentID = agent.ID.Hex()
+		if parsed.AffectedAsset == "" {
+			parsed.AffectedAsset = agent.Name
+		}
+	}
+	return parsed, nil
+}
+
+func (s *TicketParseService) generate(ctx context.Context, text string) (models.ParsedTicket, error) {
+	taxonomy := s.taxonomy.Get(ctx)
+	var categories strings.Builder
+	for _, c := range taxonomy.Categories {
+		categories.WriteString(fmt.Sprintf("- %s", c.Name))
+		if len(c.Subcategories) > 0 {
+			categories.WriteString(" (subcategories: " + strings.Join(c.Subcategories, ", ") + ")")
+		}
+		categories.WriteString("\n")
+	}
+	priorities := []string{}
+	for _, p := range taxonomy.Priorities {
+		priorities = append(priorities, fmt.Sprintf("%s (%s)", p.Name, p.Description))
+	}
+
+	prompt := fmt.Sprintf(`Turn the following message or chat transcript from an employee into an IT support ticket.
+
+%s
+
+Categories:
+%s
+Priorities: %s
+
+Respond with a JSON object containing:
+- title: a specific one-line summary of the problem, at most 100 characters
+- description: the problem in the requester's terms with the details a technician needs (symptoms, error messages, what was tried, when it started); leave out greetings and small talk
+- category: one of the categories
+- subcategory: one of its subcategories, or empty
+- priority: one of the priorities
+- affectedAsset: the hostname, asset tag, device or system affected as written in the text, or empty`,
+		s.guardrails.Wrap(ctx, "parse_ticket", "", "text", text), categories.String(), strings.Join(priorities, ", "))
+
+	content, err := s.llm.Complete(ctx, CompletionRequest{
+		System:   ticketParseSystemPrompt,
+		Prompt:   prompt,
+		Endpoint: EndpointParse,
+	})
+	if err != nil {
+		return models.ParsedTicket{}, err
+	}
+
+	var result struct {
+		Title         string                `json:"title"`
+		Description   string                `json:"description"`
+		Category      models.TicketCategory `json:"category"`
+		Subcategory   string                `json:"subcategory"`
+		Priority      models.TicketPriority `json:"priority"`
+		AffectedAsset string                `json:"affectedAsset"`
+	}
+	if err := json.Unmarshal([]byte(ExtractJSON(content)), &result); err != nil {
+		return models.ParsedTicket{}, fmt.Errorf("failed to parse ticket response: %v", err)
+	}
+	title, description := strings.TrimSpace(result.Title), strings.TrimSpace(result.Description)
+	if title == "" || description == "" {
+		return models.ParsedTicket{}, fmt.Errorf("ticket response missing title or description")
+	}
+	return models.ParsedTicket{
+		Ticket: models.CreateTicketRequest{
+			Title:       truncateWords(title, maxParsedTitle),
+			Description: description,
+			Category:    result.Category,
+			Subcategory: strings.TrimSpace(result.Subcategory),
+			Priority:    result.Priority,
+		},
+		AffectedAsset: strings.TrimSpace(result.AffectedAsset),
+	}, nil
+}
+
+// applyTaxonomy drops fields outside the taxonomy, leaving them to the
+// defaults and automatic triage of ticket creation.
+func (s *TicketParseService) applyTaxonomy(ctx context.Context, req *models.CreateTicketRequest) {
+	taxonomy := s.taxonomy.Get(ctx)
+	if FindCategory(taxonomy, req.Category) == nil {
+		req.Category = ""
+	}
+	if req.Subcategory != "" && (req.Category == "" || !HasSubcategory(taxonomy, req.Category, req.Subcategory)) {
+		req.Subcategory = ""
+	}
+	if FindPriority(taxonomy, req.Priority) == nil {
+		req.Priority = ""
+	}
+}
+
+// matchAgent finds the endpoint agent whose name, asset tag, hostname or
+// serial number is asset, or failing that is mentioned in text.
+func (s *TicketParseService) matchAgent(ctx context.Context, asset, text string) (*models.EndpointAgent, error) {
+	cursor, err := s.db.GetCollection("endpoint_agents").Find(ctx, bson.M{"enabled": true},
+		options.Find().SetProjection(bson.M{"name": 1, "assetTag": 1, "inventory.hostname": 1, "inventory.serialNumber": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var agents []models.EndpointAgent
+	if err := cursor.All(ctx, &agents); err != nil {
+		return nil, err
+	}
+
+	identifiers := func(a models.EndpointAgent) []string {
+		return []string{a.AssetTag, a.Inventory.Hostname, a.Inventory.SerialNumber, a.Name}
+	}
+	if asset != "" {
+		for i, a := range agents {
+			for _, id := range identifiers(a) {
+				if id != "" && strings.EqualFold(id, asset) {
+					return &agents[i], nil
+				}
+			}
+		}
+	}
+	for i, a := range agents {
+		for _, id := range identifiers(a) {
+			if mentions(text, id) {
+				return &agents[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// mockParsedTicket drafts a ticket without the LLM: the first sentence the
+// requester wrote becomes the title, the text the description, and keyword
+// triage picks the category and priority.
+func mockParsedTicket(text string) models.ParsedTicket {
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(transcriptSpeaker.ReplaceAllString(strings.TrimSpace(line), ""))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	title := "Support request"
+	for _, line := range lines {
+		line = leadingGreeting.ReplaceAllString(line, "")
+		if i := strings.IndexAny(line, ".?!"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			title = truncateWords(line, maxParsedTitle)
+			break
+		}
+	}
+	description := strings.Join(lines, "\n")
+	if description == "" {
+		description = title
+	}
+
+	triage := GenerateMockTriage(models.TriageRequest{Title: title, Description: description})
+	return models.ParsedTicket{
+		Ticket: models.CreateTicketRequest{
+			Title:       title,
+			Description: description,
+			Category:    triage.Category,
+			Subcategory: triage.Subcategory,
+			Priority:    triage.Priority,
+		},
+		Fallback: true,
+	}
+}
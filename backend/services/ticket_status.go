@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+var (
+	// ErrInvalidStatus is returned for statuses that do not exist.
+	ErrInvalidStatus = errors.New("invalid status")
+	// ErrInvalidStatusTransition is returned for moves the ticket workflow
+	// does not allow, such as resolving a ticket nobody worked on.
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+)
+
+// ticketTransitions are the statuses a ticket can move to from each status:
+// open → in_progress → resolved → closed. Work can be handed back to the
+// queue, resolved tickets reopened, and open tickets closed without work
+// when they are withdrawn or rejected. Closed tickets are final.
+var ticketTransitions = map[models.TicketStatus][]models.TicketStatus{
+	models.StatusOpen:       {models.StatusInProgress, models.StatusClosed},
+	models.StatusInProgress: {models.StatusOpen, models.StatusResolved},
+	models.StatusResolved:   {models.StatusClosed, models.StatusOpen},
+	models.StatusClosed:     {},
+}
+
+func ValidTicketStatus(status models.TicketStatus) bool {
+	_, ok := ticketTransitions[status]
+	return ok
+}
+
+// CheckStatusTransition returns nil when a ticket can move from one status
+// to another. Staying in the same status is always allowed, and tickets
+// stored with an unknown status can move to any status to repair them.
+func CheckStatusTransition(from, to models.TicketStatus) error {
+	if !ValidTicketStatus(to) {
+		return fmt.Errorf("%w: %q; must be open, in_progress, resolved or closed", ErrInvalidStatus, to)
+	}
+	allowed, known := ticketTransitions[from]
+	if from == to || !known {
+		return nil
+	}
+	for _, s := range allowed {
+		if s == to {
+			return nil
+		}
+	}
+	if len(allowed) == 0 {
+		return fmt.Errorf("%w: %s tickets cannot change status", ErrInvalidStatusTransition, from)
+	}
+	names := make([]string, len(allowed))
+	for i, s := range allowed {
+		names[i] = string(s)
+	}
+	return fmt.Errorf("%w: cannot move a ticket from %s to %s; it can move to %s",
+		ErrInvalidStatusTransition, from, to, strings.Join(names, " or "))
+}
+
+// ApplyStatusTransition adds the move of ticket to status to an update
+// document, stamping when it happened: startedAt the first time work
+// starts, resolvedAt and closedAt, and reopenedAt with a count when a
+// resolved ticket is reopened, which clears its resolution. Tickets closed
+// without being resolved get resolvedAt too, as before.
+func ApplyStatusTransition(update bson.M, ticket models.Ticket, to models.TicketStatus, now time.Time) {
+	if ticket.Status == to {
+		return
+	}
+	field := func(op string) bson.M {
+		if _, ok := update[op]; !ok {
+			update[op] = bson.M{}
+		}
+		return update[op].(bson.M)
+	}
+	set := field("$set")
+	set["status"] = to
+	set["statusChangedAt"] = now
+	switch to {
+	case models.StatusInProgress:
+		if ticket.StartedAt == nil {
+			set["startedAt"] = now
+		}
+	case models.StatusResolved:
+		set["resolvedAt"] = now
+	case models.StatusClosed:
+		set["closedAt"] = now
+		if ticket.ResolvedAt == nil {
+			set["resolvedAt"] = now
+		}
+	case models.StatusOpen:
+		if ticket.Status == models.StatusResolved {
+			set["reopenedAt"] = now
+			field("$inc")["reopenCount"] = 1
+			unset := field("$unset")
+			unset["resolvedAt"] = ""
+			unset["closedAt"] = ""
+		}
+	}
+}
+
+// MoveTicketStatus checks that ticket can move to status, including that
+// its sub-tasks are done before it is resolved or closed, and adds the
+// move to an update document.
+func MoveTicketStatus(ctx context.Context, db *database.MongoDB, update bson.M, ticket models.Ticket, to models.TicketStatus, now time.Time) error {
+	if err := CheckStatusTransition(ticket.Status, to); err != nil {
+		return err
+	}
+	if err := CheckSubtasksDone(ctx, db, ticket, to); err != nil {
+		return err
+	}
+	ApplyStatusTransition(update, ticket, to, now)
+	return nil
+}
+
+// ResolveTicketStatus adds resolving ticket to an update document for work
+// finished outside the service desk, such as a linked issue closed in its
+// tracker or an anomaly that recovered. Open tickets are started and
+// resolved at once, since the work happened even though nobody picked them
+// up here.
+func ResolveTicketStatus(ctx context.Context, db *database.MongoDB, update bson.M, ticket models.Ticket, now time.Time) error {
+	if ticket.Status == models.StatusOpen {
+		ApplyStatusTransition(update, ticket, models.StatusInProgress, now)
+		ticket.Status = models.StatusInProgress
+	}
+	return MoveTicketStatus(ctx, db, update, ticket, models.StatusResolved, now)
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// childrenOf matches the tickets whose parent is one of ids.
+func childrenOf(ids ...primitive.ObjectID) bson.M {
+	return bson.M{"links": bson.M{"$elemMatch": bson.M{"type": models.TicketLinkParent, "ticketId": bson.M{"$in": ids}}}}
+}
+
+// CheckSubtasksDone fails with ErrInvalidStatusTransition when a ticket
+// with sub-tasks still being worked on is resolved, or one with sub-tasks
+// not yet closed is closed. Sub-tasks of sub-tasks are held back the same
+// way, so only direct children are checked.
+func CheckSubtasksDone(ctx context.Context, db *database.MongoDB, ticket models.Ticket, to models.TicketStatus) error {
+	var pending bson.A
+	switch to {
+	case models.StatusResolved:
+		pending = liveStatuses()
+	case models.StatusClosed:
+		pending = bson.A{models.StatusOpen, models.StatusInProgress, models.StatusResolved}
+	default:
+		return nil
+	}
+	if !hasChildLink(ticket) {
+		return nil
+	}
+	filter := childrenOf(ticket.ID)
+	filter["status"] = bson.M{"$in": pending}
+	n, err := db.GetCollection("tickets").CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("%w: %d sub-task(s) must be %s first", ErrInvalidStatusTransition, n, map[models.TicketStatus]string{
+			models.StatusResolved: "resolved or closed",
+			models.StatusClosed:   "closed",
+		}[to])
+	}
+	return nil
+}
+
+func hasChildLink(ticket models.Ticket) bool {
+	for _, l := range ticket.Links {
+		if l.Type == models.TicketLinkChild {
+			return true
+		}
+	}
+	return false
+}
+
+// Split creates a sub-task for each request, linked to the parent both
+// ways. Only open and in-progress tickets can be split, and service
+// requests and changes only once approved, since sub-tasks can be worked
+// on straight away.
+func (s *TicketLinkService) Split(ctx context.Context, parentID primitive.ObjectID, req models.SplitTicketRequest, user models.User) ([]models.Ticket, error) {
+	parent, err := s.get(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Status != models.StatusOpen && parent.Status != models.StatusInProgress {
+		return nil, fmt.Errorf("%w: only open and in-progress tickets can be split", ErrTicketLinkInvalid)
+	}
+	if err := ServiceRequestGate(parent); err != nil {
+		return nil, err
+	}
+	if err := ChangeGate(parent); err != nil {
+		return nil, err
+	}
+	if len(parent.Links)+len(req.Subtasks) > maxTicketLinks {
+		return nil, fmt.Errorf("%w: a ticket can have at most %d links", ErrTicketLinkInvalid, maxTicketLinks)
+	}
+	// Sub-tasks are one level below the parent, within the nesting limit
+	if err := s.checkAncestors(ctx, parent, primitive.NilObjectID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	subtasks := make([]models.Ticket, len(req.Subtasks))
+	for i, r := range req.Subtasks {
+		title := strings.TrimSpace(r.Title)
+		if title == "" {
+			return nil, fmt.Errorf("%w: subtasks[%d].title is empty", ErrTicketLinkInvalid, i)
+		}
+		description := strings.TrimSpace(r.Description)
+		if description == "" {
+			description = "Sub-task of \"" + parent.Title + "\"."
+		}
+		subtask := models.Ticket{
+			ID:                  primitive.NewObjectID(),
+			Title:               title,
+			Description:         description,
+			Category:            parent.Category,
+			Subcategory:         parent.Subcategory,
+			SecondaryCategories: parent.SecondaryCategories,
+			Priority:            parent.Priority,
+			Status:              models.StatusOpen,
+			Type:                parent.Type,
+			Tags:                parent.Tags,
+			Department:          parent.Department,
+			Location:            parent.Location,
+			Team:                parent.Team,
+			EndpointAgentID:     parent.EndpointAgentID,
+			DueAt:               r.DueAt,
+			CreatedBy:           user.ID,
+			CreatedAt:           now,
+			UpdatedAt:           now,
+			Links:               []models.TicketLink{{Type: models.TicketLinkParent, TicketID: parent.ID, CreatedBy: user.ID, CreatedAt: now}},
+		}
+		if subtask.DueAt == nil {
+			subtask.DueAt = parent.DueAt
+		}
+		if r.AssignedTo != "" {
+			id, err := primitive.ObjectIDFromHex(r.AssignedTo)
+			if err != nil {
+				return nil, fmt.Errorf("%w: subtasks[%d].assignedTo is not a valid ID", ErrTicketLinkInvalid, i)
+			}
+			n, err := s.db.GetCollection("users").CountDocuments(ctx, bson.M{"_id": id, "role": bson.M{"$in": bson.A{models.RoleTechnician, models.RoleAdmin}}})
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				return nil, fmt.Errorf("%w: subtasks[%d].assignedTo is not a technician", ErrTicketLinkInvalid, i)
+			}
+			subtask.AssignedTo = &id
+		}
+		subtasks[i] = subtask
+	}
+
+	docs := make([]interface{}, len(subtasks))
+	links := make([]models.TicketLink, len(subtasks))
+	for i := range subtasks {
+		if subtasks[i].AssignedTo == nil {
+			if err := s.assignment.Assign(ctx, &subtasks[i]); err != nil {
+				return nil, err
+			}
+		}
+		docs[i] = subtasks[i]
+		links[i] = models.TicketLink{Type: models.TicketLinkChild, TicketID: subtasks[i].ID, CreatedBy: user.ID, CreatedAt: now}
+	}
+	if _, err := s.tickets().InsertMany(ctx, docs); err != nil {
+		return nil, err
+	}
+	if _, err := s.tickets().UpdateByID(ctx, parent.ID, bson.M{
+		"$push": bson.M{"links": bson.M{"$each": links}},
+		"$set":  bson.M{"updatedAt": now},
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, subtask := range subtasks {
+		s.record(ctx, parent.ID, user, models.TicketFieldChange{Field: "links", New: string(models.TicketLinkChild) + " " + subtask.ID.Hex()})
+	}
+	return subtasks, nil
+}
+
+// Subtasks returns the ticket's sub-tasks at every level, up to the
+// nesting limit, with their roll-up. Sub-tasks without a due date are due
+// by their priority's resolution target, as in ticket responses.
+func (s *TicketLinkService) Subtasks(ctx context.Context, ticket models.Ticket) (models.SubtaskTree, error) {
+	tree := models.SubtaskTree{TicketID: ticket.ID, Subtasks: []models.SubtaskNode{}}
+
+	// Read one level at a time, then hang each level under its parents
+	byParent := map[primitive.ObjectID][]models.Ticket{}
+	level := []primitive.ObjectID{ticket.ID}
+	started := false
+	for depth := 0; depth < maxTicketAncestors && len(level) > 0; depth++ {
+		cursor, err := s.tickets().Find(ctx, childrenOf(level...), options.Find().
+			SetProjection(bson.M{"title": 1, "status": 1, "priority": 1, "assignedTo": 1, "dueAt": 1, "createdAt": 1, "links": 1}).
+			SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+		if err != nil {
+			return tree, err
+		}
+		var children []models.Ticket
+		if err := cursor.All(ctx, &children); err != nil {
+			return tree, err
+		}
+		level = level[:0]
+		for _, child := range children {
+			if parent := ticketParent(child); parent != nil {
+				byParent[*parent] = append(byParent[*parent], child)
+			}
+			level = append(level, child.ID)
+			tree.Total++
+			switch child.Status {
+			case models.StatusResolved, models.StatusClosed:
+				tree.Done++
+				started = true
+			case models.StatusInProgress:
+				started = true
+			}
+		}
+	}
+
+	taxonomy := s.taxonomy.Get(ctx)
+	now := time.Now()
+	var build func(id primitive.ObjectID) []models.SubtaskNode
+	build = func(id primitive.ObjectID) []models.SubtaskNode {
+		nodes := []models.SubtaskNode{}
+		for _, t := range byParent[id] {
+			FillDueDate(taxonomy, &t, now)
+			nodes = append(nodes, models.SubtaskNode{
+				ID:         t.ID,
+				Title:      t.Title,
+				Status:     t.Status,
+				Priority:   t.Priority,
+				AssignedTo: t.AssignedTo,
+				DueAt:      t.DueAt,
+				DueFromSLA: t.DueFromSLA,
+				Overdue:    t.Overdue,
+				Subtasks:   build(t.ID),
+			})
+		}
+		return nodes
+	}
+	tree.Subtasks = build(ticket.ID)
+
+	switch {
+	case tree.Total == 0:
+	case tree.Done == tree.Total:
+		tree.Status = models.StatusResolved
+	case started:
+		tree.Status = models.StatusInProgress
+	default:
+		tree.Status = models.StatusOpen
+	}
+	return tree, nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// A ticket carries at most this many tags.
+const maxTicketTags = 20
+
+// Tags are short lowercase slugs such as vpn or exchange-migration.
+var ticketTagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,49}$`)
+
+// NormalizeTicketTags lowercases and trims tags and drops empty and
+// duplicate ones, keeping their order. It rejects tags that are not short
+// slugs.
+func NormalizeTicketTags(tags []string) ([]string, error) {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if !ticketTagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q: use up to 50 letters, digits, '.', '_' or '-'", tag)
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result, nil
+}
+
+// MergeTicketTags adds tags to a ticket's existing ones, keeping within the
+// per-ticket limit.
+func MergeTicketTags(existing, added []string) ([]string, error) {
+	merged, err := NormalizeTicketTags(append(append([]string{}, existing...), added...))
+	if err != nil {
+		return nil, err
+	}
+	if len(merged) > maxTicketTags {
+		return nil, fmt.Errorf("a ticket can have at most %d tags", maxTicketTags)
+	}
+	return merged, nil
+}
+
+// ParseTicketTagFilter reads the tags of repeated or comma-separated ?tag=
+// query parameters.
+func ParseTicketTagFilter(values []string) []string {
+	tags := []string{}
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// TicketTagCounts returns every tag in use on live tickets with the number
+// of tickets, and of open tickets, carrying it, most used first.
+func TicketTagCounts(ctx context.Context, db *database.MongoDB) ([]models.TicketTagCount, error) {
+	cursor, err := db.GetCollection("tickets").Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"tags.0": bson.M{"$exists": true}}},
+		bson.M{"$unwind": "$tags"},
+		bson.M{"$group": bson.M{
+			"_id":   "$tags",
+			"count": bson.M{"$sum": 1},
+			"open": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$in": bson.A{"$status", bson.A{models.StatusOpen, models.StatusInProgress}}}, 1, 0,
+			}}},
+		}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	counts := []models.TicketTagCount{}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
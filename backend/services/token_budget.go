@@ -0,0 +1,194 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+
+	"intelliops-ai-copilot/models"
+)
+
+// EstimateTokens approximates how many tokens a BPE tokenizer such as
+// OpenAI's cl100k produces for text. It splits text the way the tokenizer's
+// pre-tokenizer does (letter runs with a leading space, digit groups of up
+// to three, individual symbols) and charges long words one token per four
+// characters. It errs slightly high so budgets stay within the limit.
+func EstimateTokens(text string) int {
+	tokens := 0
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsLetter(r):
+			j := i
+			for j < len(runes) && unicode.IsLetter(runes[j]) {
+				j++
+			}
+			tokens += (j - i + 3) / 4
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			tokens += (j - i + 2) / 3
+			i = j
+		case r == '\n':
+			// Runs of newlines encode as one token
+			for i < len(runes) && runes[i] == '\n' {
+				i++
+			}
+			tokens++
+		case unicode.IsSpace(r):
+			// A single space merges into the following word
+			j := i
+			for j < len(runes) && unicode.IsSpace(runes[j]) && runes[j] != '\n' {
+				j++
+			}
+			if j-i > 1 {
+				tokens++
+			}
+			i = j
+		default:
+			if r > unicode.MaxASCII {
+				tokens += 2
+			} else {
+				tokens++
+			}
+			i++
+		}
+	}
+	return tokens
+}
+
+// TruncateToTokens cuts text to at most maxTokens estimated tokens, ending
+// at a sentence or word boundary where possible.
+func TruncateToTokens(text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	if EstimateTokens(text) <= maxTokens {
+		return text
+	}
+
+	// Binary search the longest prefix (in words) that fits
+	words := strings.Fields(text)
+	lo, hi := 0, len(words)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if EstimateTokens(strings.Join(words[:mid], " ")) <= maxTokens {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	cut := strings.Join(words[:lo], " ")
+	if i := strings.LastIndexAny(cut, ".!?"); i > len(cut)/2 {
+		cut = cut[:i+1]
+	}
+	return cut
+}
+
+// splitByTokens breaks text into pieces of at most maxTokens estimated
+// tokens at word boundaries.
+func splitByTokens(text string, maxTokens int) []string {
+	if EstimateTokens(text) <= maxTokens {
+		return []string{text}
+	}
+	var pieces, current []string
+	tokens := 0
+	for _, word := range strings.Fields(text) {
+		wordTokens := EstimateTokens(" " + word)
+		if tokens+wordTokens > maxTokens && len(current) > 0 {
+			pieces = append(pieces, strings.Join(current, " "))
+			current, tokens = nil, 0
+		}
+		current = append(current, word)
+		tokens += wordTokens
+	}
+	if len(current) > 0 {
+		pieces = append(pieces, strings.Join(current, " "))
+	}
+	return pieces
+}
+
+// modelContextTokens are the context windows of known OpenAI chat models.
+// Prefixes are matched longest first.
+var modelContextTokens = map[string]int{
+	"gpt-3.5-turbo": 16385,
+	"gpt-4":         8192,
+	"gpt-4-32k":     32768,
+	"gpt-4-turbo":   128000,
+	"gpt-4o":        128000,
+	"gpt-4.1":       1047576,
+	"o1":            200000,
+	"o3":            200000,
+	"o4":            200000,
+}
+
+// defaultContextTokens is assumed for unknown and local models.
+const defaultContextTokens = 4096
+
+// ContextLimit returns the context window for a model; override wins when
+// positive.
+func ContextLimit(model string, override int) int {
+	if override > 0 {
+		return override
+	}
+	best, limit := "", defaultContextTokens
+	for prefix, tokens := range modelContextTokens {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best, limit = prefix, tokens
+		}
+	}
+	return limit
+}
+
+// minTrimmedChunkTokens is the smallest remainder worth filling with a
+// trimmed chunk.
+const minTrimmedChunkTokens = 64
+
+// SelectContext picks search results for a prompt in score order until the
+// token budget is spent. The first chunk that does not fit whole is trimmed
+// when enough room is left, which fills the budget. render returns the text
+// a result adds to the prompt so its framing counts towards the budget.
+func SelectContext(results []models.DocumentSearchResult, budget int, render func(models.DocumentSearchResult) string) ([]models.DocumentSearchResult, models.ContextUsage) {
+	usage := models.ContextUsage{BudgetTokens: budget, ChunksAvailable: len(results)}
+
+	ordered := make([]models.DocumentSearchResult, len(results))
+	copy(ordered, results)
+	for i := 1; i < len(ordered); i++ {
+		for j := i; j > 0 && ordered[j].Score > ordered[j-1].Score; j-- {
+			ordered[j], ordered[j-1] = ordered[j-1], ordered[j]
+		}
+	}
+
+	var selected []models.DocumentSearchResult
+	remaining := budget
+	for _, result := range ordered {
+		cost := EstimateTokens(render(result))
+		if cost <= remaining {
+			selected = append(selected, result)
+			remaining -= cost
+			continue
+		}
+
+		overhead := cost - EstimateTokens(result.Chunk.Content)
+		room := remaining - overhead
+		if room < minTrimmedChunkTokens {
+			// Too little room to be useful; a shorter chunk may still fit
+			continue
+		}
+		result.Chunk.Content = TruncateToTokens(result.Chunk.Content, room)
+		selected = append(selected, result)
+		remaining -= EstimateTokens(render(result))
+		usage.ChunksTrimmed++
+		break
+	}
+
+	usage.ChunksUsed = len(selected)
+	usage.ContextTokens = budget - remaining
+	if usage.ContextTokens > budget {
+		usage.ContextTokens = budget
+	}
+	return selected, usage
+}
@@ -0,0 +1,476 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const triageSystemPrompt = "You are an expert IT support triage specialist. Analyze tickets and provide structured triage information." + untrustedContentPolicy
+
+// builtInTriagePrompts are always available. v1 is the original fixed-list
+// prompt, v2 feeds the organization's taxonomy to the model.
+var builtInTriagePrompts = []models.PromptTemplate{
+	{
+		Name:        "triage",
+		Version:     "v1",
+		Description: "Original prompt with the built-in category list",
+		BuiltIn:     true,
+		Content: `
+Analyze the following IT support ticket and provide triage information:
+
+Title: {{.Title}}
+Description: {{.Description}}
+
+Please respond with a JSON object containing:
+- category: One of "Network Issue", "Hardware Issue", "Software Issue", "Security Issue", "Performance Issue", or "Other"
+- summary: A brief 1-2 sentence summary of the issue
+- priority: One of "low", "medium", "high", or "critical"
+- suggestedTechnician: A suggested technician name (use Indian names like "Ravi Kumar", "Priya Sharma", "Amit Patel", "Sneha Singh")
+- confidence: A number between 0.0 and 1.0 indicating confidence in the analysis
+- reasoning: Brief explanation of the categorization
+
+Respond only with valid JSON, no additional text.
+`,
+	},
+	{
+		Name:        "triage",
+		Version:     "v2",
+		Description: "Taxonomy-aware prompt with subcategories and secondary categories",
+		BuiltIn:     true,
+		Content: `
+Analyze the following IT support ticket and provide triage information:
+
+Title: {{.Title}}
+Description: {{.Description}}
+
+{{.Taxonomy}}
+Please respond with a JSON object containing:
+- category: The primary category, exactly one of the allowed category names
+- subcategory: One of the subcategories listed for the chosen category, or an empty string if none fits
+- secondaryCategories: Any additional allowed categories that also apply (may be an empty array)
+- summary: A brief 1-2 sentence summary of the issue
+- priority: Exactly one of the allowed priority names
+- suggestedTechnician: A suggested technician name (use Indian names like "Ravi Kumar", "Priya Sharma", "Amit Patel", "Sneha Singh")
+- confidence: A number between 0.0 and 1.0 indicating confidence in the analysis
+- reasoning: Brief explanation of the categorization
+
+Respond only with valid JSON, no additional text.
+`,
+	},
+}
+
+type TriageService struct {
+	db                   *database.MongoDB
+	llm                  *LLMService
+	taxonomy             *TaxonomyService
+	deployments          *DeploymentService
+	availability         *AvailabilityService
+	defaultPromptVersion string
+}
+
+func NewTriageService(db *database.MongoDB, llm *LLMService, taxonomy *TaxonomyService, deployments *DeploymentService, availability *AvailabilityService, defaultPromptVersion string) *TriageService {
+	return &TriageService{
+		db:                   db,
+		llm:                  llm,
+		taxonomy:             taxonomy,
+		deployments:          deployments,
+		availability:         availability,
+		defaultPromptVersion: defaultPromptVersion,
+	}
+}
+
+// Triage categorizes a ticket with the selected provider, model and prompt
+// version, falling back to keyword matching when the LLM is unavailable.
+func (s *TriageService) Triage(ctx context.Context, req models.TriageRequest, opts models.TriageOptions) models.TriageRun {
+	if opts.Provider == "" {
+		opts.Provider = s.llm.Provider()
+	}
+	if opts.Model == "" {
+		opts.Model = s.llm.GenerationParams(ctx, EndpointTriage, opts.Provider).Model
+	}
+	if opts.PromptVersion == "" {
+		opts.PromptVersion = s.defaultPromptVersion
+	}
+
+	run := models.TriageRun{Options: opts, RanAt: time.Now()}
+	start := time.Now()
+
+	response, err := s.callLLM(ctx, req, opts, &run)
+	if err != nil {
+		if err != ErrLLMUnavailable {
+			run.Error = err.Error()
+		}
+		response = GenerateMockTriage(req)
+		run.Fallback = true
+	}
+
+	s.applyTaxonomy(ctx, response)
+	if err := s.suggestTechnicians(ctx, response); err != nil {
+		log.Printf("Failed to rank technicians for triage: %v", err)
+	}
+	s.addRecentDeployments(ctx, req, response)
+	run.Result = response
+	run.LatencyMs = time.Since(start).Milliseconds()
+	return run
+}
+
+// addRecentDeployments points out recent deployments to the services the
+// ticket mentions, the likely cause of a new incident.
+func (s *TriageService) addRecentDeployments(ctx context.Context, req models.TriageRequest, response *models.TriageResponse) {
+	if s.deployments == nil {
+		return
+	}
+	recent, err := s.deployments.Mentioned(ctx, req.Title+"\n"+req.Description)
+	if err != nil || len(recent) == 0 {
+		return
+	}
+	summaries := make([]string, len(recent))
+	for i, r := range recent {
+		summaries[i] = r.Summary
+	}
+	response.RecentDeployments = recent
+	response.Reasoning = strings.TrimSpace(response.Reasoning + " Recent changes: " + strings.Join(summaries, "; ") + ".")
+}
+
+// ApplyToTicket triages a new ticket and fills in its category and
+// priority, unless keepCategory or keepPriority say they were given, and
+// assigns it to the best technician candidate when they have worked on the
+// category or triage named them, or else to a technician with the
+// suggested name. The result is recorded on the ticket.
+func (s *TriageService) ApplyToTicket(ctx context.Context, ticket *models.Ticket, keepCategory, keepPriority bool) error {
+	run := s.Triage(ctx, models.TriageRequest{Title: ticket.Title, Description: ticket.Description}, models.TriageOptions{})
+	result := run.Result
+	if !keepCategory {
+		ticket.Category = result.Category
+		ticket.Subcategory = result.Subcategory
+		ticket.SecondaryCategories = NormalizeSecondaryCategories(result.Category, append(ticket.SecondaryCategories, result.SecondaryCategories...))
+	}
+	if !keepPriority {
+		ticket.Priority = result.Priority
+	}
+	ticket.AutoTriage = &models.TicketTriage{
+		Category:            result.Category,
+		Subcategory:         result.Subcategory,
+		Priority:            result.Priority,
+		SuggestedTechnician: result.SuggestedTechnician,
+		Confidence:          result.Confidence,
+		Reasoning:           result.Reasoning,
+		Fallback:            run.Fallback,
+		TriagedAt:           run.RanAt,
+	}
+
+	if ticket.AssignedTo != nil || result.SuggestedTechnician == "" {
+		return nil
+	}
+	if len(result.TechnicianCandidates) > 0 {
+		if best := result.TechnicianCandidates[0]; best.Assigned > 0 || best.Matched || best.OnCall {
+			ticket.AssignedTo = &best.TechnicianID
+		}
+		return nil
+	}
+	var technician models.User
+	err := s.db.GetCollection("users").FindOne(ctx, bson.M{
+		"role": models.RoleTechnician,
+		"name": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(strings.TrimSpace(result.SuggestedTechnician)) + "$", Options: "i"},
+	}).Decode(&technician)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	unavailable, err := s.availability.Unavailable(ctx, []primitive.ObjectID{technician.ID}, time.Now())
+	if err != nil || unavailable[technician.ID] != "" {
+		return err
+	}
+	ticket.AssignedTo = &technician.ID
+	return nil
+}
+
+func (s *TriageService) callLLM(ctx context.Context, req models.TriageRequest, opts models.TriageOptions, run *models.TriageRun) (*models.TriageResponse, error) {
+	tmpl, err := s.GetPromptTemplate(ctx, "triage", opts.PromptVersion)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := s.renderPrompt(ctx, tmpl, req)
+	if err != nil {
+		return nil, err
+	}
+	if glossary := s.llm.GlossaryPrompt(ctx, req.Title+"\n"+req.Description); glossary != "" {
+		prompt += "\n\n" + glossary
+	}
+	prompt = s.llm.Profile() + prompt
+
+	content, err := s.llm.Complete(ctx, CompletionRequest{
+		System:   triageSystemPrompt,
+		Prompt:   prompt,
+		Endpoint: EndpointTriage,
+		Provider: opts.Provider,
+		Model:    opts.Model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	run.PromptTokens = EstimateTokens(triageSystemPrompt + prompt)
+	run.CompletionTokens = EstimateTokens(content)
+	run.CostUSD = EstimateCostUSD(opts.Model, run.PromptTokens, run.CompletionTokens)
+
+	var response models.TriageResponse
+	if err := json.Unmarshal([]byte(ExtractJSON(content)), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse triage response: %v", err)
+	}
+	if err := ValidateTriageResponse(&response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (s *TriageService) renderPrompt(ctx context.Context, tmpl models.PromptTemplate, req models.TriageRequest) (string, error) {
+	t, err := template.New(tmpl.Name + "@" + tmpl.Version).Parse(tmpl.Content)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template %s@%s: %v", tmpl.Name, tmpl.Version, err)
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, struct {
+		Title       string
+		Description string
+		Taxonomy    string
+	}{
+		s.llm.guardrails.Wrap(ctx, "ticket", "", "title", req.Title),
+		s.llm.guardrails.Wrap(ctx, "ticket", "", "description", req.Description),
+		TaxonomyPrompt(s.taxonomy.Get(ctx)),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s@%s: %v", tmpl.Name, tmpl.Version, err)
+	}
+	return buf.String(), nil
+}
+
+// applyTaxonomy coerces triage output onto the current taxonomy so values the
+// LLM invented never reach the client.
+func (s *TriageService) applyTaxonomy(ctx context.Context, response *models.TriageResponse) {
+	taxonomy := s.taxonomy.Get(ctx)
+	if FindCategory(taxonomy, response.Category) == nil {
+		response.Category = DefaultCategory(taxonomy)
+	}
+	if response.Subcategory != "" && !HasSubcategory(taxonomy, response.Category, response.Subcategory) {
+		response.Subcategory = ""
+	}
+	if FindPriority(taxonomy, response.Priority) == nil {
+		response.Priority = DefaultPriority(taxonomy)
+	}
+
+	var secondary []models.TicketCategory
+	for _, category := range response.SecondaryCategories {
+		if FindCategory(taxonomy, category) != nil {
+			secondary = append(secondary, category)
+		}
+	}
+	response.SecondaryCategories = NormalizeSecondaryCategories(response.Category, secondary)
+}
+
+// GetPromptTemplate looks up a built-in or admin-stored template by version.
+func (s *TriageService) GetPromptTemplate(ctx context.Context, name, version string) (models.PromptTemplate, error) {
+	for _, tmpl := range builtInTriagePrompts {
+		if tmpl.Name == name && tmpl.Version == version {
+			return tmpl, nil
+		}
+	}
+
+	var tmpl models.PromptTemplate
+	err := s.db.GetCollection("prompt_templates").FindOne(ctx, bson.M{"name": name, "version": version}).Decode(&tmpl)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.PromptTemplate{}, fmt.Errorf("unknown prompt template %s@%s", name, version)
+		}
+		return models.PromptTemplate{}, err
+	}
+	return tmpl, nil
+}
+
+// ListPromptTemplates returns built-in templates followed by stored ones.
+func (s *TriageService) ListPromptTemplates(ctx context.Context) ([]models.PromptTemplate, error) {
+	templates := append([]models.PromptTemplate{}, builtInTriagePrompts...)
+
+	cursor, err := s.db.GetCollection("prompt_templates").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{"name", 1}, {"createdAt", 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stored []models.PromptTemplate
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+	return append(templates, stored...), nil
+}
+
+// CreatePromptTemplate stores a new template version. Versions are immutable
+// so experiments and evaluations stay reproducible.
+func (s *TriageService) CreatePromptTemplate(ctx context.Context, req models.CreatePromptTemplateRequest, createdBy primitive.ObjectID) (models.PromptTemplate, error) {
+	if _, err := template.New(req.Name).Parse(req.Content); err != nil {
+		return models.PromptTemplate{}, fmt.Errorf("invalid template: %v", err)
+	}
+	if _, err := s.GetPromptTemplate(ctx, req.Name, req.Version); err == nil {
+		return models.PromptTemplate{}, fmt.Errorf("prompt template %s@%s already exists", req.Name, req.Version)
+	}
+
+	tmpl := models.PromptTemplate{
+		ID:          primitive.NewObjectID(),
+		Name:        req.Name,
+		Version:     req.Version,
+		Description: req.Description,
+		Content:     req.Content,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+	}
+	if _, err := s.db.GetCollection("prompt_templates").InsertOne(ctx, tmpl); err != nil {
+		return models.PromptTemplate{}, err
+	}
+	return tmpl, nil
+}
+
+// NormalizeSecondaryCategories drops empty and duplicate labels as well as the
+// primary category, so the secondary list only ever carries extra labels.
+func NormalizeSecondaryCategories(primary models.TicketCategory, secondary []models.TicketCategory) []models.TicketCategory {
+	seen := map[models.TicketCategory]bool{primary: true}
+	result := []models.TicketCategory{}
+	for _, category := range secondary {
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		result = append(result, category)
+	}
+	return result
+}
+
+// GenerateMockTriage is the keyword-based triage used when no LLM is available.
+func GenerateMockTriage(req models.TriageRequest) *models.TriageResponse {
+	combined := req.Title + " " + req.Description
+
+	var category models.TicketCategory
+	var subcategory string
+	var secondaryCategories []models.TicketCategory
+	var priority models.TicketPriority
+	var suggestedTechnician string
+
+	// Determine categories based on keywords. The first match becomes the
+	// primary category, any further matches are reported as secondary.
+	for _, rule := range mockCategoryRules {
+		if !containsAny(combined, rule.keywords) {
+			continue
+		}
+		if category == "" {
+			category = rule.category
+			suggestedTechnician = rule.technician
+			for _, sub := range rule.subcategories {
+				if containsAny(combined, sub.keywords) {
+					subcategory = sub.name
+					if sub.technician != "" {
+						suggestedTechnician = sub.technician
+					}
+					break
+				}
+			}
+		} else {
+			secondaryCategories = append(secondaryCategories, rule.category)
+		}
+	}
+	if category == "" {
+		category = models.CategoryOther
+		suggestedTechnician = "General Support"
+	}
+
+	// Determine priority based on keywords
+	if containsAny(combined, []string{"urgent", "critical", "down", "emergency", "outage"}) {
+		priority = models.PriorityCritical
+	} else if containsAny(combined, []string{"high", "important", "asap", "immediately"}) {
+		priority = models.PriorityHigh
+	} else if containsAny(combined, []string{"low", "minor", "when possible"}) {
+		priority = models.PriorityLow
+	} else {
+		priority = models.PriorityMedium
+	}
+
+	return &models.TriageResponse{
+		Category:            category,
+		Subcategory:         subcategory,
+		SecondaryCategories: secondaryCategories,
+		Summary:             fmt.Sprintf("Issue categorized as %s based on ticket content analysis", category),
+		Priority:            priority,
+		SuggestedTechnician: suggestedTechnician,
+		Confidence:          0.75,
+		Reasoning:           "Analysis based on keyword matching and ticket content patterns",
+	}
+}
+
+// mockSubcategoryRule picks a subcategory (and optionally a more specialised
+// technician) within a category when any of its keywords match.
+type mockSubcategoryRule struct {
+	name       string
+	keywords   []string
+	technician string
+}
+
+var mockCategoryRules = []struct {
+	category      models.TicketCategory
+	keywords      []string
+	technician    string
+	subcategories []mockSubcategoryRule
+}{
+	{models.CategoryNetwork, []string{"network", "wifi", "internet", "connection", "router", "switch", "vpn"}, "Ravi Kumar", []mockSubcategoryRule{
+		{"VPN", []string{"vpn", "tunnel"}, "Ravi Kumar"},
+		{"WiFi", []string{"wifi", "wi-fi", "wireless"}, ""},
+		{"DNS", []string{"dns", "resolve", "nslookup"}, ""},
+		{"Internet Access", []string{"internet"}, ""},
+	}},
+	{models.CategoryHardware, []string{"hardware", "computer", "laptop", "desktop", "printer", "monitor"}, "Amit Patel", []mockSubcategoryRule{
+		{"Printer", []string{"printer", "print"}, ""},
+		{"Monitor", []string{"monitor", "display", "screen"}, ""},
+		{"Laptop/Desktop", []string{"laptop", "desktop", "computer"}, ""},
+	}},
+	{models.CategorySoftware, []string{"software", "application", "program", "install", "update", "license", "licence"}, "Priya Sharma", []mockSubcategoryRule{
+		{"Licensing", []string{"license", "licence", "activation", "subscription expired"}, ""},
+		{"Email", []string{"email", "outlook", "mail"}, ""},
+		{"Installation", []string{"install"}, ""},
+		{"Operating System", []string{"windows", "macos", "linux", "os update"}, ""},
+	}},
+	{models.CategorySecurity, []string{"security", "virus", "malware", "breach", "access"}, "Sneha Singh", []mockSubcategoryRule{
+		{"Malware", []string{"virus", "malware", "ransomware"}, ""},
+		{"Phishing", []string{"phishing", "suspicious email"}, ""},
+		{"Account Access", []string{"access", "password", "locked"}, ""},
+		{"Data Breach", []string{"breach", "leak"}, ""},
+	}},
+	{models.CategoryPerformance, []string{"slow", "performance", "lag", "freeze", "crash"}, "Rajesh Kumar", []mockSubcategoryRule{
+		{"Crash/Freeze", []string{"crash", "freeze"}, ""},
+		{"Slowness", []string{"slow", "lag"}, ""},
+	}},
+}
+
+func containsAny(text string, keywords []string) bool {
+	text = strings.ToLower(text)
+	for _, keyword := range keywords {
+		if strings.Contains(text, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
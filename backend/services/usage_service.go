@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+// Quotas on expensive endpoints. Quotas not stored yet use the defaults in
+// builtinUsageQuotas.
+const (
+	QuotaAITriage           = "ai_triage"
+	QuotaSolutionGeneration = "solution_generation"
+)
+
+var builtinUsageQuotas = []models.UsageQuota{
+	{Key: QuotaAITriage, Description: "AI triage of tickets, including the sandbox"},
+	{Key: QuotaSolutionGeneration, Description: "Generating and refining ticket solutions"},
+}
+
+var usageQuotaKey = regexp.MustCompile(`^[a-z][a-z0-9_]{1,63}$`)
+
+// usageQuotaTTL is how long quotas are cached before being read again, so
+// changes made on another replica apply within it.
+const usageQuotaTTL = 30 * time.Second
+
+const usageDayFormat = "2006-01-02"
+
+type usageKey struct {
+	day    string
+	userID primitive.ObjectID
+	group  string
+}
+
+// UsageService counts API requests per user and route group, and enforces
+// daily quotas on expensive endpoints. Request counts are buffered in
+// memory and flushed periodically; quota counters are written at once so
+// every replica sees them.
+type UsageService struct {
+	db *database.MongoDB
+
+	pendingMu sync.Mutex
+	pending   map[usageKey]int64
+
+	mu       sync.RWMutex
+	quotas   map[string]models.UsageQuota
+	loadedAt time.Time
+}
+
+func NewUsageService(db *database.MongoDB) *UsageService {
+	return &UsageService{db: db, pending: map[usageKey]int64{}}
+}
+
+func (s *UsageService) usage() *mongo.Collection {
+	return s.db.GetCollection("api_usage")
+}
+
+func (s *UsageService) quotaCollection() *mongo.Collection {
+	return s.db.GetCollection("usage_quotas")
+}
+
+func (s *UsageService) quotaCounters() *mongo.Collection {
+	return s.db.GetCollection("usage_quota_counters")
+}
+
+// usageGroup maps a route such as "/api/tickets/:id" to its group,
+// "tickets".
+func usageGroup(route string) string {
+	route = strings.TrimPrefix(strings.TrimPrefix(route, "/"), "api/")
+	if i := strings.Index(route, "/"); i >= 0 {
+		route = route[:i]
+	}
+	if route == "" {
+		return "root"
+	}
+	return route
+}
+
+// Record counts a request by the user to route, the matched route pattern.
+func (s *UsageService) Record(userID primitive.ObjectID, route string) {
+	key := usageKey{day: time.Now().UTC().Format(usageDayFormat), userID: userID, group: usageGroup(route)}
+	s.pendingMu.Lock()
+	s.pending[key]++
+	s.pendingMu.Unlock()
+}
+
+// Start flushes the buffered request counts every interval.
+func (s *UsageService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+				if err := s.Flush(ctx); err != nil {
+					log.Printf("usage flush error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Flush adds the buffered request counts to the stored ones. On failure the
+// counts are kept for the next flush.
+func (s *UsageService) Flush(ctx context.Context) error {
+	s.pendingMu.Lock()
+	pending := s.pending
+	s.pending = map[usageKey]int64{}
+	s.pendingMu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(pending))
+	for key, n := range pending {
+		id := key.day + ":" + key.userID.Hex() + ":" + key.group
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetUpdate(bson.M{
+				"$inc":         bson.M{"count": n},
+				"$setOnInsert": bson.M{"day": key.day, "userId": key.userID, "group": key.group},
+			}).
+			SetUpsert(true))
+	}
+	if _, err := s.usage().BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		s.pendingMu.Lock()
+		for key, n := range pending {
+			s.pending[key] += n
+		}
+		s.pendingMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Report returns each user's requests per day and route group over the
+// days from and to (inclusive, "2006-01-02"), busiest users first. userID
+// limits the report to one user when not nil.
+func (s *UsageService) Report(ctx context.Context, from, to string, userID *primitive.ObjectID) (models.UsageReport, error) {
+	filter := bson.M{"day": bson.M{"$gte": from, "$lte": to}}
+	if userID != nil {
+		filter["userId"] = *userID
+	}
+	cursor, err := s.usage().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "day", Value: 1}}))
+	if err != nil {
+		return models.UsageReport{}, err
+	}
+	var records []models.APIUsage
+	if err := cursor.All(ctx, &records); err != nil {
+		return models.UsageReport{}, err
+	}
+
+	users := map[primitive.ObjectID]*models.UserUsage{}
+	ids := []primitive.ObjectID{}
+	for _, r := range records {
+		u, ok := users[r.UserID]
+		if !ok {
+			u = &models.UserUsage{UserID: r.UserID, Days: []models.UsageDay{}}
+			users[r.UserID] = u
+			ids = append(ids, r.UserID)
+		}
+		// Records are sorted by day, so a user's current day is the last
+		if len(u.Days) == 0 || u.Days[len(u.Days)-1].Day != r.Day {
+			u.Days = append(u.Days, models.UsageDay{Day: r.Day, Groups: map[string]int64{}})
+		}
+		day := &u.Days[len(u.Days)-1]
+		day.Groups[r.Group] += r.Count
+		day.Total += r.Count
+		u.Total += r.Count
+	}
+
+	if len(ids) > 0 {
+		cursor, err := s.db.GetCollection("users").Find(ctx, bson.M{"_id": bson.M{"$in": ids}},
+			options.Find().SetProjection(bson.M{"name": 1, "email": 1}))
+		if err != nil {
+			return models.UsageReport{}, err
+		}
+		var found []models.User
+		if err := cursor.All(ctx, &found); err != nil {
+			return models.UsageReport{}, err
+		}
+		for _, user := range found {
+			users[user.ID].UserName, users[user.ID].Email = user.Name, user.Email
+		}
+	}
+
+	report := models.UsageReport{From: from, To: to, Users: make([]models.UserUsage, 0, len(users))}
+	for _, u := range users {
+		report.Users = append(report.Users, *u)
+	}
+	sort.Slice(report.Users, func(i, j int) bool { return report.Users[i].Total > report.Users[j].Total })
+	return report, nil
+}
+
+// ValidateUsageQuota checks a quota update and returns the quota it sets.
+func ValidateUsageQuota(key string, req models.UsageQuotaRequest) (models.UsageQuota, error) {
+	if !usageQuotaKey.MatchString(key) {
+		return models.UsageQuota{}, errors.New("key must be 2-64 lowercase letters, digits or underscores, starting with a letter")
+	}
+	if *req.DailyLimit < 0 {
+		return models.UsageQuota{}, errors.New("dailyLimit must not be negative")
+	}
+	return models.UsageQuota{Key: key, Description: req.Description, DailyLimit: *req.DailyLimit}, nil
+}
+
+// SaveQuota creates or replaces a quota.
+func (s *UsageService) SaveQuota(ctx context.Context, quota models.UsageQuota, updatedBy primitive.ObjectID) (models.UsageQuota, error) {
+	quota.UpdatedAt = time.Now()
+	quota.UpdatedBy = &updatedBy
+	_, err := s.quotaCollection().ReplaceOne(ctx, bson.M{"_id": quota.Key}, quota, options.Replace().SetUpsert(true))
+	if err != nil {
+		return models.UsageQuota{}, err
+	}
+	s.invalidate()
+	return quota, nil
+}
+
+// DeleteQuota removes a stored quota; built-in quotas return to no limit.
+func (s *UsageService) DeleteQuota(ctx context.Context, key string) error {
+	res, err := s.quotaCollection().DeleteOne(ctx, bson.M{"_id": key})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	s.invalidate()
+	return nil
+}
+
+// ListQuotas returns the stored quotas and the built-in quotas not stored
+// yet, by key.
+func (s *UsageService) ListQuotas(ctx context.Context) ([]models.UsageQuota, error) {
+	quotas, err := s.loadQuotas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]models.UsageQuota, 0, len(quotas))
+	for _, quota := range quotas {
+		list = append(list, quota)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list, nil
+}
+
+// Consume counts one call by user against the quota and reports whether it
+// is within the daily limit. Calls over the limit are not counted. Unknown
+// quotas and quotas without a limit always allow the call.
+func (s *UsageService) Consume(ctx context.Context, key string, user models.User) (bool, error) {
+	quotas, err := s.loadQuotas(ctx)
+	if err != nil {
+		log.Printf("Failed to load usage quotas: %v", err)
+	}
+	quota, ok := quotas[key]
+	if !ok || quota.DailyLimit == 0 {
+		return true, nil
+	}
+
+	day := time.Now().UTC().Format(usageDayFormat)
+	id := day + ":" + key + ":" + user.ID.Hex()
+	var counter struct {
+		Count int `bson:"count"`
+	}
+	err = s.quotaCounters().FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$inc":         bson.M{"count": 1},
+			"$setOnInsert": bson.M{"day": day, "quota": key, "userId": user.ID},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return false, err
+	}
+	if counter.Count <= quota.DailyLimit {
+		return true, nil
+	}
+	if _, err := s.quotaCounters().UpdateByID(ctx, id, bson.M{"$inc": bson.M{"count": -1}}); err != nil {
+		log.Printf("Failed to release quota %s call of %s: %v", key, user.ID.Hex(), err)
+	}
+	return false, nil
+}
+
+// loadQuotas returns the cached quotas, reading them again once the cache
+// is older than usageQuotaTTL. On a read error the previous quotas, or the
+// built-in defaults, are returned with the error.
+func (s *UsageService) loadQuotas(ctx context.Context) (map[string]models.UsageQuota, error) {
+	s.mu.RLock()
+	quotas, loadedAt := s.quotas, s.loadedAt
+	s.mu.RUnlock()
+	if quotas != nil && time.Since(loadedAt) < usageQuotaTTL {
+		return quotas, nil
+	}
+
+	loaded := map[string]models.UsageQuota{}
+	for _, quota := range builtinUsageQuotas {
+		quota.Builtin = true
+		loaded[quota.Key] = quota
+	}
+	cursor, err := s.quotaCollection().Find(ctx, bson.M{})
+	if err == nil {
+		var stored []models.UsageQuota
+		if err = cursor.All(ctx, &stored); err == nil {
+			for _, quota := range stored {
+				loaded[quota.Key] = quota
+			}
+		}
+	}
+	if err != nil {
+		if quotas != nil {
+			return quotas, err
+		}
+		return loaded, err
+	}
+
+	s.mu.Lock()
+	s.quotas, s.loadedAt = loaded, time.Now()
+	s.mu.Unlock()
+	return loaded, nil
+}
+
+func (s *UsageService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
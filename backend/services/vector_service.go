@@ -2,32 +2,211 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"math"
 	"net/http"
+	"sync"
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/config"
+	"intelliops-ai-copilot/database"
 	"intelliops-ai-copilot/models"
 )
 
+// VectorService embeds text and searches indexed documents. Documents are
+// stored in MongoDB so every replica shares one index; each replica keeps
+// an in-memory copy for search that follows the collection through a
+// change stream, or by polling when change streams are unavailable.
 type VectorService struct {
+	db           *database.MongoDB
 	openAIAPIKey string
 	localLLMURL  string
 	provider     string
-	// In-memory storage for demo (replace with actual vector DB)
-	documents []models.Document
+	// Batched embedding: inputs per request, concurrent requests and the
+	// price used to estimate cost
+	batchSize       int
+	concurrency     int
+	costPer1KTokens float64
+	// Cosine similarity from which chunks may be duplicates; 0 disables
+	// near-duplicate detection
+	duplicateSimilarity float64
+
+	mu          sync.RWMutex
+	documents   map[primitive.ObjectID]models.Document
+	fingerprint string
 }
 
-func NewVectorService(openAIAPIKey, localLLMURL, provider string) *VectorService {
+// openAIEmbeddingModel is the OpenAI model used for all embeddings.
+const openAIEmbeddingModel = "text-embedding-3-small"
+
+// openAIMaxBatch is the most inputs OpenAI accepts in one embeddings request.
+const openAIMaxBatch = 2048
+
+func NewVectorService(db *database.MongoDB, cfg *config.Config) *VectorService {
+	batchSize := cfg.EmbeddingBatchSize
+	if batchSize < 1 || batchSize > openAIMaxBatch {
+		batchSize = openAIMaxBatch
+	}
+	concurrency := cfg.EmbeddingConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
 	return &VectorService{
-		openAIAPIKey: openAIAPIKey,
-		localLLMURL:  localLLMURL,
-		provider:     provider,
-		documents:    []models.Document{},
+		db:                  db,
+		openAIAPIKey:        cfg.OpenAIAPIKey,
+		localLLMURL:         cfg.LocalLLMURL,
+		provider:            cfg.AIProvider,
+		batchSize:           batchSize,
+		concurrency:         concurrency,
+		costPer1KTokens:     cfg.EmbeddingCostPer1KTokens,
+		duplicateSimilarity: cfg.DuplicateSimilarity,
+		documents:           map[primitive.ObjectID]models.Document{},
+	}
+}
+
+func (v *VectorService) collection() *mongo.Collection {
+	return v.db.GetCollection("documents")
+}
+
+// Start loads the shared index and keeps the local copy in sync with
+// changes made through other replicas.
+func (v *VectorService) Start(ctx context.Context, pollInterval time.Duration) {
+	if err := v.Reload(ctx); err != nil {
+		log.Printf("Failed to load document index: %v", err)
+	}
+	go v.watch(ctx, pollInterval)
+}
+
+// Reload replaces the local copy with the documents collection.
+func (v *VectorService) Reload(ctx context.Context) error {
+	fingerprint, err := v.collectionFingerprint(ctx)
+	if err != nil {
+		return err
+	}
+	cursor, err := v.collection().Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var docs []models.Document
+	if err := cursor.All(ctx, &docs); err != nil {
+		return err
+	}
+
+	documents := make(map[primitive.ObjectID]models.Document, len(docs))
+	for _, doc := range docs {
+		documents[doc.ID] = doc
+	}
+	v.mu.Lock()
+	v.documents = documents
+	v.fingerprint = fingerprint
+	v.mu.Unlock()
+	return nil
+}
+
+// collectionFingerprint changes whenever a document is added, updated or
+// removed, so polling replicas only reload when needed.
+func (v *VectorService) collectionFingerprint(ctx context.Context) (string, error) {
+	cursor, err := v.collection().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": nil, "count": bson.M{"$sum": 1}, "latest": bson.M{"$max": "$updatedAt"}}}},
+	})
+	if err != nil {
+		return "", err
+	}
+	var rows []struct {
+		Count  int       `bson:"count"`
+		Latest time.Time `bson:"latest"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return "", err
+	}
+	if len(rows) == 0 {
+		return "0", nil
+	}
+	return fmt.Sprintf("%d-%d", rows[0].Count, rows[0].Latest.UnixNano()), nil
+}
+
+func (v *VectorService) watch(ctx context.Context, pollInterval time.Duration) {
+	stream, err := v.collection().Watch(ctx, mongo.Pipeline{}, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		log.Printf("Document change stream unavailable (%v), polling every %s", err, pollInterval)
+		v.poll(ctx, pollInterval)
+		return
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var event struct {
+			OperationType string `bson:"operationType"`
+			DocumentKey   struct {
+				ID primitive.ObjectID `bson:"_id"`
+			} `bson:"documentKey"`
+			FullDocument *models.Document `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&event); err != nil {
+			log.Printf("Failed to decode document change: %v", err)
+			continue
+		}
+
+		switch {
+		case event.OperationType == "delete":
+			v.mu.Lock()
+			delete(v.documents, event.DocumentKey.ID)
+			v.mu.Unlock()
+		case event.FullDocument != nil:
+			v.cache(*event.FullDocument)
+		default:
+			// drop, rename or invalidate: start over from the collection
+			if err := v.Reload(ctx); err != nil {
+				log.Printf("Failed to reload document index: %v", err)
+			}
+		}
+	}
+	if ctx.Err() == nil {
+		log.Printf("Document change stream closed (%v), polling every %s", stream.Err(), pollInterval)
+		v.poll(ctx, pollInterval)
+	}
+}
+
+func (v *VectorService) poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fingerprint, err := v.collectionFingerprint(ctx)
+			if err != nil {
+				log.Printf("Failed to check document index: %v", err)
+				continue
+			}
+			v.mu.RLock()
+			changed := fingerprint != v.fingerprint
+			v.mu.RUnlock()
+			if changed {
+				if err := v.Reload(ctx); err != nil {
+					log.Printf("Failed to reload document index: %v", err)
+				}
+			}
+		}
 	}
 }
 
+func (v *VectorService) cache(doc models.Document) {
+	v.mu.Lock()
+	v.documents[doc.ID] = doc
+	v.mu.Unlock()
+}
+
 // GenerateEmbedding generates vector embedding for text
 func (v *VectorService) GenerateEmbedding(text string) ([]float32, error) {
 	apiKeyPreview := "empty"
@@ -63,12 +242,144 @@ func (v *VectorService) GenerateEmbedding(text string) ([]float32, error) {
 	return v.generateSimpleEmbedding(text), nil
 }
 
+// GenerateEmbeddings embeds many texts, batching them per request where the
+// provider supports it and running up to the configured number of requests
+// at once. A failed batch falls back to simple embeddings, like
+// GenerateEmbedding.
+func (v *VectorService) GenerateEmbeddings(texts []string) ([][]float32, models.EmbeddingStats) {
+	start := time.Now()
+	embeddings := make([][]float32, len(texts))
+	stats := models.EmbeddingStats{Provider: "simple", Model: "hash", Chunks: len(texts)}
+
+	var embed func(batch []string) ([][]float32, int, error)
+	batchSize := 1
+	switch {
+	case v.provider == "openai" && v.openAIAPIKey != "":
+		stats.Provider, stats.Model = "openai", openAIEmbeddingModel
+		embed = v.generateOpenAIEmbeddings
+		batchSize = v.batchSize
+	case v.provider == "local" && v.localLLMURL != "":
+		// The local endpoint takes one input per request
+		stats.Provider, stats.Model = "local", "local-model"
+		embed = func(batch []string) ([][]float32, int, error) {
+			embedding, err := v.generateLocalEmbedding(batch[0])
+			return [][]float32{embedding}, 0, err
+		}
+	default:
+		for i, text := range texts {
+			embeddings[i] = v.generateSimpleEmbedding(text)
+		}
+		stats.DurationMs = time.Since(start).Milliseconds()
+		return embeddings, stats
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, v.concurrency)
+	for from := 0; from < len(texts); from += batchSize {
+		to := from + batchSize
+		if to > len(texts) {
+			to = len(texts)
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(from, to int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			batch, tokens, err := embed(texts[from:to])
+			if err == nil && len(batch) != to-from {
+				err = fmt.Errorf("expected %d embeddings, got %d", to-from, len(batch))
+			}
+			if err != nil {
+				log.Printf("Embedding batch of %d failed, using simple embeddings: %v", to-from, err)
+				batch = make([][]float32, to-from)
+				for i := range batch {
+					batch[i] = v.generateSimpleEmbedding(texts[from+i])
+				}
+			}
+			copy(embeddings[from:to], batch)
+
+			mu.Lock()
+			stats.Requests++
+			stats.Tokens += tokens
+			if err != nil {
+				stats.FailedRequests++
+			}
+			mu.Unlock()
+		}(from, to)
+	}
+	wg.Wait()
+
+	stats.DurationMs = time.Since(start).Milliseconds()
+	if stats.DurationMs > 0 {
+		stats.ChunksPerSecond = float64(stats.Chunks) / (float64(stats.DurationMs) / 1000)
+	}
+	stats.EstimatedCostUSD = float64(stats.Tokens) / 1000 * v.costPer1KTokens
+	return embeddings, stats
+}
+
+// generateOpenAIEmbeddings embeds a batch in one request and returns the
+// embeddings in input order with the tokens billed.
+func (v *VectorService) generateOpenAIEmbeddings(texts []string) ([][]float32, int, error) {
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"input": texts,
+		"model": openAIEmbeddingModel,
+	})
+
+	req, err := http.NewRequest("POST", "https://api.openai.com/v1/embeddings", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+v.openAIAPIKey)
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != 200 {
+		return nil, 0, fmt.Errorf("OpenAI API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, 0, err
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, 0, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+	for i, e := range embeddings {
+		if e == nil {
+			return nil, 0, fmt.Errorf("no embedding returned for input %d", i)
+		}
+	}
+	return embeddings, result.Usage.TotalTokens, nil
+}
+
 func (v *VectorService) generateOpenAIEmbedding(text string) ([]float32, error) {
 	url := "https://api.openai.com/v1/embeddings"
 
 	payload := map[string]interface{}{
 		"input": text,
-		"model": "text-embedding-3-small",
+		"model": openAIEmbeddingModel,
 	}
 
 	jsonData, _ := json.Marshal(payload)
@@ -169,17 +480,104 @@ func (v *VectorService) generateSimpleEmbedding(text string) []float32 {
 	return embedding
 }
 
-// StoreDocument stores document for later retrieval
-func (v *VectorService) StoreDocument(doc models.Document) {
-	v.documents = append(v.documents, doc)
+// StoreDocument saves a document to the shared index, replacing an earlier
+// version indexed from the same file.
+func (v *VectorService) StoreDocument(ctx context.Context, doc models.Document) (models.Document, error) {
+	var existing models.Document
+	err := v.collection().FindOne(ctx, bson.M{"filePath": doc.FilePath}).Decode(&existing)
+	switch {
+	case err == nil:
+		doc.ID = existing.ID
+		if doc.DuplicatePaths == nil {
+			doc.DuplicatePaths = existing.DuplicatePaths
+		}
+		if doc.OwnerID == nil {
+			doc.OwnerID = existing.OwnerID
+		}
+		if doc.LastReviewedAt == nil {
+			doc.LastReviewedAt, doc.ReviewedBy = existing.LastReviewedAt, existing.ReviewedBy
+		}
+		if doc.ReviewTicketID == nil {
+			doc.ReviewTicketID = existing.ReviewTicketID
+		}
+		// Re-indexing keeps the approval; uploads set their own
+		if doc.ApprovalStatus == "" {
+			doc.ApprovalStatus, doc.SubmittedAt = existing.ApprovalStatus, existing.SubmittedAt
+			doc.DecidedBy, doc.DecidedAt, doc.ApprovalNote = existing.DecidedBy, existing.DecidedAt, existing.ApprovalNote
+		}
+		if doc.UploadedBy == nil {
+			doc.UploadedBy = existing.UploadedBy
+		}
+		if doc.ReviewerID == nil {
+			doc.ReviewerID = existing.ReviewerID
+		}
+	case err != mongo.ErrNoDocuments:
+		return doc, err
+	case doc.ID.IsZero():
+		doc.ID = primitive.NewObjectID()
+	}
+	if doc.OwnerID == nil {
+		doc.OwnerID = doc.UploadedBy
+	}
+	if doc.ContentChangedAt == nil {
+		if existing.ContentChangedAt != nil && existing.ContentHash == doc.ContentHash {
+			doc.ContentChangedAt = existing.ContentChangedAt
+		} else {
+			changed := time.Now()
+			doc.ContentChangedAt = &changed
+		}
+	}
+	if doc.IndexedAt.IsZero() {
+		doc.IndexedAt = time.Now()
+	}
+	doc.UpdatedAt = time.Now()
+
+	if _, err := v.collection().ReplaceOne(ctx, bson.M{"_id": doc.ID}, doc, options.Replace().SetUpsert(true)); err != nil {
+		return doc, err
+	}
+	// Visible here at once; other replicas pick it up from the change stream
+	v.cache(doc)
+	return doc, nil
+}
+
+// DeleteDocument removes the document indexed from filePath, if any.
+func (v *VectorService) DeleteDocument(ctx context.Context, filePath string) error {
+	var existing models.Document
+	err := v.collection().FindOneAndDelete(ctx, bson.M{"filePath": filePath}).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	v.mu.Lock()
+	delete(v.documents, existing.ID)
+	v.mu.Unlock()
+	return nil
 }
 
 // Search finds similar documents using cosine similarity
 func (v *VectorService) Search(queryEmbedding []float32, topK int, minScore float32) ([]models.DocumentSearchResult, error) {
+	return v.search(queryEmbedding, topK, minScore, false)
+}
+
+// SearchApproved searches like Search but only in documents that may be
+// cited in solutions, skipping uploads not yet approved.
+func (v *VectorService) SearchApproved(queryEmbedding []float32, topK int, minScore float32) ([]models.DocumentSearchResult, error) {
+	return v.search(queryEmbedding, topK, minScore, true)
+}
+
+func (v *VectorService) search(queryEmbedding []float32, topK int, minScore float32, approvedOnly bool) ([]models.DocumentSearchResult, error) {
 	var results []models.DocumentSearchResult
 
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
 	// Search through all stored documents
 	for _, doc := range v.documents {
+		if approvedOnly && !doc.Approved() {
+			continue
+		}
 		for _, chunk := range doc.Chunks {
 			if len(chunk.Embedding) == 0 {
 				continue
@@ -246,6 +644,20 @@ func CosineSimilarity(a, b []float32) float32 {
 
 // GetDocumentCount returns the number of indexed documents
 func (v *VectorService) GetDocumentCount() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	return len(v.documents)
 }
 
+
+// Documents returns the indexed documents. They share their content and
+// chunks with the index and must not be modified.
+func (v *VectorService) Documents() []models.Document {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	docs := make([]models.Document, 0, len(v.documents))
+	for _, doc := range v.documents {
+		docs = append(docs, doc)
+	}
+	return docs
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"intelliops-ai-copilot/models"
+)
+
+// WarRoomProvider creates a chat channel for an incident and posts the
+// incident timeline to it.
+type WarRoomProvider interface {
+	Name() string
+	CreateChannel(ctx context.Context, name, topic string) (models.WarRoom, error)
+	Post(ctx context.Context, room models.WarRoom, text string) error
+}
+
+var channelNameInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// warRoomChannelName builds e.g. "inc-20240131-vpn-outage-in-emea".
+func warRoomChannelName(incident models.Incident) string {
+	slug := strings.Trim(channelNameInvalid.ReplaceAllString(strings.ToLower(incident.Title), "-"), "-")
+	name := "inc-" + incident.CreatedAt.Format("20060102") + "-" + slug
+	if len(name) > 50 {
+		name = strings.TrimRight(name[:50], "-")
+	}
+	return name
+}
+
+// slackWarRoom uses the Slack Web API with a bot token.
+type slackWarRoom struct {
+	token  string
+	client *http.Client
+}
+
+func (s *slackWarRoom) Name() string {
+	return "slack"
+}
+
+func (s *slackWarRoom) call(ctx context.Context, method string, body map[string]interface{}, out interface{}) error {
+	data, err := sendStatusRequest(ctx, s.client, "POST", "https://slack.com/api/"+method, "Bearer "+s.token, body)
+	if err != nil {
+		return err
+	}
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return err
+	}
+	if !status.OK {
+		return fmt.Errorf("slack %s failed: %s", method, status.Error)
+	}
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+func (s *slackWarRoom) CreateChannel(ctx context.Context, name, topic string) (models.WarRoom, error) {
+	var created struct {
+		Channel struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"channel"`
+	}
+	if err := s.call(ctx, "conversations.create", map[string]interface{}{"name": name}, &created); err != nil {
+		return models.WarRoom{}, err
+	}
+	room := models.WarRoom{
+		Provider:  s.Name(),
+		ChannelID: created.Channel.ID,
+		Name:      created.Channel.Name,
+		URL:       "https://slack.com/app_redirect?channel=" + created.Channel.ID,
+	}
+	if len(topic) > 250 {
+		topic = topic[:250]
+	}
+	if err := s.call(ctx, "conversations.setTopic", map[string]interface{}{"channel": room.ChannelID, "topic": topic}, nil); err != nil {
+		log.Printf("Failed to set topic of war room %s: %v", room.Name, err)
+	}
+	return room, nil
+}
+
+func (s *slackWarRoom) Post(ctx context.Context, room models.WarRoom, text string) error {
+	return s.call(ctx, "chat.postMessage", map[string]interface{}{"channel": room.ChannelID, "text": text}, nil)
+}
+
+// teamsWarRoom creates channels through Microsoft Graph with an app-only
+// token. Graph does not let applications post channel messages, so the
+// timeline reaches Teams through the notification webhooks instead.
+type teamsWarRoom struct {
+	graph  *graphCredentials
+	teamID string
+	client *http.Client
+}
+
+func (t *teamsWarRoom) Name() string {
+	return "teams"
+}
+
+func (t *teamsWarRoom) CreateChannel(ctx context.Context, name, topic string) (models.WarRoom, error) {
+	token, err := t.graph.accessToken(ctx)
+	if err != nil {
+		return models.WarRoom{}, err
+	}
+	if len(topic) > 1024 {
+		topic = topic[:1024]
+	}
+	endpoint := fmt.Sprintf("https://graph.microsoft.com/v1.0/teams/%s/channels", t.teamID)
+	data, err := sendStatusRequest(ctx, t.client, "POST", endpoint, "Bearer "+token, map[string]interface{}{
+		"displayName":    name,
+		"description":    topic,
+		"membershipType": "standard",
+	})
+	if err != nil {
+		return models.WarRoom{}, err
+	}
+	var created struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"displayName"`
+		WebURL      string `json:"webUrl"`
+	}
+	if err := json.Unmarshal(data, &created); err != nil {
+		return models.WarRoom{}, err
+	}
+	return models.WarRoom{Provider: t.Name(), ChannelID: created.ID, Name: created.DisplayName, URL: created.WebURL}, nil
+}
+
+func (t *teamsWarRoom) Post(context.Context, models.WarRoom, string) error {
+	return nil
+}
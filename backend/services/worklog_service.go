@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"intelliops-ai-copilot/database"
+	"intelliops-ai-copilot/models"
+)
+
+const (
+	// maxWorklogMinutes bounds one entry to a day's work.
+	maxWorklogMinutes = 24 * 60
+	maxWorklogNote    = 1000
+	// worklogTopTickets is how many tickets Stats lists by time spent.
+	worklogTopTickets = 10
+)
+
+// ErrInvalidWorklog is returned for worklog entries that do not validate.
+var ErrInvalidWorklog = errors.New("invalid worklog entry")
+
+func invalidWorklog(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidWorklog, fmt.Sprintf(format, args...))
+}
+
+// WorklogService records the time technicians spend on tickets. Each entry
+// is kept in ticket_worklogs and its minutes added to the ticket's
+// timeSpentMinutes.
+type WorklogService struct {
+	db *database.MongoDB
+}
+
+func NewWorklogService(db *database.MongoDB) *WorklogService {
+	return &WorklogService{db: db}
+}
+
+func (s *WorklogService) collection() *mongo.Collection {
+	return s.db.GetCollection("ticket_worklogs")
+}
+
+func validateWorklog(minutes int, note string, workedAt time.Time) error {
+	if minutes < 1 || minutes > maxWorklogMinutes {
+		return invalidWorklog("minutes must be between 1 and %d", maxWorklogMinutes)
+	}
+	if len(note) > maxWorklogNote {
+		return invalidWorklog("note must be at most %d characters", maxWorklogNote)
+	}
+	if workedAt.After(time.Now().Add(time.Hour)) {
+		return invalidWorklog("workedAt cannot be in the future")
+	}
+	return nil
+}
+
+// Add logs time on a live ticket; archived tickets are read-only and give
+// mongo.ErrNoDocuments.
+func (s *WorklogService) Add(ctx context.Context, ticketID primitive.ObjectID, user models.User, req models.CreateWorklogRequest) (models.WorklogEntry, error) {
+	now := time.Now()
+	entry := models.WorklogEntry{
+		ID:        primitive.NewObjectID(),
+		TicketID:  ticketID,
+		UserID:    user.ID,
+		UserName:  user.Name,
+		Minutes:   req.Minutes,
+		Note:      strings.TrimSpace(req.Note),
+		WorkedAt:  now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if req.WorkedAt != nil {
+		entry.WorkedAt = *req.WorkedAt
+	}
+	if err := validateWorklog(entry.Minutes, entry.Note, entry.WorkedAt); err != nil {
+		return models.WorklogEntry{}, err
+	}
+
+	if err := s.addToTicket(ctx, ticketID, entry.Minutes, now); err != nil {
+		return models.WorklogEntry{}, err
+	}
+	if _, err := s.collection().InsertOne(ctx, entry); err != nil {
+		return models.WorklogEntry{}, err
+	}
+	return entry, nil
+}
+
+// Update changes an entry, keeping the ticket's total in step.
+func (s *WorklogService) Update(ctx context.Context, ticketID, id primitive.ObjectID, req models.UpdateWorklogRequest) (models.WorklogEntry, error) {
+	entry, err := s.Get(ctx, ticketID, id)
+	if err != nil {
+		return models.WorklogEntry{}, err
+	}
+	before := entry.Minutes
+	if req.Minutes != nil {
+		entry.Minutes = *req.Minutes
+	}
+	if req.Note != nil {
+		entry.Note = strings.TrimSpace(*req.Note)
+	}
+	if req.WorkedAt != nil {
+		entry.WorkedAt = *req.WorkedAt
+	}
+	if err := validateWorklog(entry.Minutes, entry.Note, entry.WorkedAt); err != nil {
+		return models.WorklogEntry{}, err
+	}
+	entry.UpdatedAt = time.Now()
+
+	if entry.Minutes != before {
+		if err := s.addToTicket(ctx, ticketID, entry.Minutes-before, entry.UpdatedAt); err != nil {
+			return models.WorklogEntry{}, err
+		}
+	}
+	_, err = s.collection().ReplaceOne(ctx, bson.M{"_id": id}, entry)
+	return entry, err
+}
+
+// Delete removes an entry and takes its minutes off the ticket's total.
+func (s *WorklogService) Delete(ctx context.Context, ticketID, id primitive.ObjectID) error {
+	var entry models.WorklogEntry
+	if err := s.collection().FindOneAndDelete(ctx, bson.M{"_id": id, "ticketId": ticketID}).Decode(&entry); err != nil {
+		return err
+	}
+	// The ticket may have been archived since
+	if err := s.addToTicket(ctx, ticketID, -entry.Minutes, time.Now()); err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	return nil
+}
+
+func (s *WorklogService) Get(ctx context.Context, ticketID, id primitive.ObjectID) (models.WorklogEntry, error) {
+	var entry models.WorklogEntry
+	err := s.collection().FindOne(ctx, bson.M{"_id": id, "ticketId": ticketID}).Decode(&entry)
+	return entry, err
+}
+
+func (s *WorklogService) addToTicket(ctx context.Context, ticketID primitive.ObjectID, minutes int, at time.Time) error {
+	result, err := s.db.GetCollection("tickets").UpdateByID(ctx, ticketID, bson.M{
+		"$inc": bson.M{"timeSpentMinutes": minutes},
+		"$set": bson.M{"updatedAt": at},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// List returns a ticket's entries, most recent work first, with the total
+// and each technician's share.
+func (s *WorklogService) List(ctx context.Context, ticketID primitive.ObjectID) (models.TicketWorklogs, error) {
+	cursor, err := s.collection().Find(ctx, bson.M{"ticketId": ticketID}, options.Find().SetSort(bson.D{{"workedAt", -1}}))
+	if err != nil {
+		return models.TicketWorklogs{}, err
+	}
+	defer cursor.Close(ctx)
+
+	worklogs := models.TicketWorklogs{Entries: []models.WorklogEntry{}}
+	if err := cursor.All(ctx, &worklogs.Entries); err != nil {
+		return models.TicketWorklogs{}, err
+	}
+	totals := map[primitive.ObjectID]*models.WorklogTotal{}
+	order := []primitive.ObjectID{}
+	for _, e := range worklogs.Entries {
+		worklogs.TotalMinutes += e.Minutes
+		t, ok := totals[e.UserID]
+		if !ok {
+			t = &models.WorklogTotal{UserID: e.UserID, UserName: e.UserName}
+			totals[e.UserID] = t
+			order = append(order, e.UserID)
+		}
+		t.Minutes += e.Minutes
+		t.Entries++
+	}
+	worklogs.ByTechnician = []models.WorklogTotal{}
+	for _, id := range order {
+		worklogs.ByTechnician = append(worklogs.ByTechnician, *totals[id])
+	}
+	return worklogs, nil
+}
+
+// Stats totals the time logged on all tickets, per technician and for the
+// tickets that took longest.
+func (s *WorklogService) Stats(ctx context.Context) (models.WorklogStats, error) {
+	stats := models.WorklogStats{ByTechnician: []models.WorklogTotal{}, TopTickets: []models.TicketTimeSpent{}}
+
+	cursor, err := s.collection().Aggregate(ctx, bson.A{
+		bson.M{"$group": bson.M{
+			"_id":      "$userId",
+			"userName": bson.M{"$last": "$userName"},
+			"minutes":  bson.M{"$sum": "$minutes"},
+			"entries":  bson.M{"$sum": 1},
+		}},
+		bson.M{"$sort": bson.D{{"minutes", -1}, {"_id", 1}}},
+	})
+	if err != nil {
+		return stats, err
+	}
+	if err := cursor.All(ctx, &stats.ByTechnician); err != nil {
+		return stats, err
+	}
+	for _, t := range stats.ByTechnician {
+		stats.TotalMinutes += t.Minutes
+	}
+
+	// Archived tickets keep their totals
+	cursor, err = s.db.GetCollection("tickets").Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"timeSpentMinutes": bson.M{"$gt": 0}}},
+		bson.M{"$unionWith": bson.M{"coll": "tickets_archive", "pipeline": bson.A{
+			bson.M{"$match": bson.M{"timeSpentMinutes": bson.M{"$gt": 0}}},
+		}}},
+		bson.M{"$sort": bson.D{{"timeSpentMinutes", -1}, {"_id", 1}}},
+		bson.M{"$limit": worklogTopTickets},
+		bson.M{"$project": bson.M{"title": 1, "minutes": "$timeSpentMinutes"}},
+	})
+	if err != nil {
+		return stats, err
+	}
+	err = cursor.All(ctx, &stats.TopTickets)
+	return stats, err
+}
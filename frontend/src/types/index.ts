@@ -1,4 +1,4 @@
-export type UserRole = 'admin' | 'technician';
+export type UserRole = 'admin' | 'technician' | 'requester';
 
 export type TicketStatus = 'open' | 'in_progress' | 'resolved' | 'closed';
 export type TicketPriority = 'low' | 'medium' | 'high' | 'critical';
@@ -18,6 +18,8 @@ export interface Ticket {
     title: string;
     description: string;
     category: TicketCategory;
+    subcategory?: string;
+    secondaryCategories?: TicketCategory[];
     priority: TicketPriority;
     status: TicketStatus;
     assignedTo?: string;
@@ -25,6 +27,24 @@ export interface Ticket {
     createdAt: string;
     updatedAt: string;
     resolvedAt?: string;
+    linkedResourceIds?: string[];
+    problemId?: string;
+    diagnostics?: DiagnosticReport[];
+    threadSummary?: ThreadSummary;
+}
+
+export interface ThreadSummary {
+    handover: string;
+    customer: string;
+    fallback: boolean;
+    generatedAt: string;
+}
+
+export interface DiagnosticReport {
+    runId: string;
+    report: string;
+    createdBy: string;
+    createdAt: string;
 }
 
 export interface TicketWithUser extends Ticket {
@@ -53,6 +73,8 @@ export interface CreateTicketRequest {
     title: string;
     description: string;
     category?: TicketCategory;
+    subcategory?: string;
+    secondaryCategories?: TicketCategory[];
     priority?: TicketPriority;
 }
 
@@ -60,6 +82,8 @@ export interface UpdateTicketRequest {
     title?: string;
     description?: string;
     category?: TicketCategory;
+    subcategory?: string;
+    secondaryCategories?: TicketCategory[];
     priority?: TicketPriority;
     status?: TicketStatus;
     assignedTo?: string;
@@ -72,6 +96,8 @@ export interface TriageRequest {
 
 export interface TriageResponse {
     category: TicketCategory;
+    subcategory?: string;
+    secondaryCategories?: TicketCategory[];
     summary: string;
     priority: TicketPriority;
     suggestedTechnician: string;
@@ -0,0 +1,42 @@
+#!/bin/bash
+#
+# Compares the per-endpoint p95 latency of a k6 summary with a baseline and
+# fails when any endpoint is slower by more than the tolerance.
+#
+#   loadtest/compare.sh SUMMARY BASELINE [TOLERANCE_PERCENT]
+
+set -uo pipefail
+
+SUMMARY=$1
+BASELINE=$2
+TOLERANCE=${3:-20}
+
+if [ ! -f "$BASELINE" ]; then
+    echo "❌ No baseline at $BASELINE. Record one with: make loadtest-baseline"
+    exit 1
+fi
+
+p95() {
+    jq -r --arg m "http_req_duration{endpoint:$2}" '.metrics[$m]["p(95)"] // empty' "$1"
+}
+
+REGRESSIONS=0
+printf "%-10s %12s %12s %9s\n" endpoint "baseline p95" "current p95" change
+for endpoint in tickets triage search; do
+    base=$(p95 "$BASELINE" "$endpoint")
+    current=$(p95 "$SUMMARY" "$endpoint")
+    if [ -z "$base" ] || [ -z "$current" ]; then
+        echo "❌ $endpoint: missing from summary or baseline"
+        REGRESSIONS=$((REGRESSIONS + 1))
+        continue
+    fi
+    change=$(awk -v b="$base" -v c="$current" 'BEGIN { printf "%.1f", (c - b) / b * 100 }')
+    printf "%-10s %10.1fms %10.1fms %8s%%\n" "$endpoint" "$base" "$current" "$change"
+    if awk -v ch="$change" -v t="$TOLERANCE" 'BEGIN { exit !(ch > t) }'; then
+        echo "❌ $endpoint p95 regressed by $change% (tolerance $TOLERANCE%)"
+        REGRESSIONS=$((REGRESSIONS + 1))
+    fi
+done
+
+[ "$REGRESSIONS" -eq 0 ] || exit 1
+echo "✅ No p95 regressions above $TOLERANCE%"
@@ -0,0 +1,91 @@
+// k6 load profile for the backend hot paths: ticket listing, AI triage and
+// document vector search.
+//
+//   k6 run -e BASE_URL=http://localhost:8080 loadtest/hot-paths.js
+//
+// Run the backend with DEMO_MODE=true so triage measures the API and
+// database path rather than the latency of an external LLM.
+import http from 'k6/http';
+import { check } from 'k6';
+
+const BASE_URL = __ENV.BASE_URL || 'http://localhost:8080';
+const EMAIL = __ENV.LOADTEST_EMAIL || 'admin@intelliops.com';
+const PASSWORD = __ENV.LOADTEST_PASSWORD || 'password';
+const RATE = parseInt(__ENV.RATE || '20', 10);
+const DURATION = __ENV.DURATION || '1m';
+
+function scenario(exec, rate) {
+  return {
+    executor: 'constant-arrival-rate',
+    exec,
+    rate,
+    timeUnit: '1s',
+    duration: DURATION,
+    preAllocatedVUs: Math.max(5, rate),
+    maxVUs: rate * 4,
+  };
+}
+
+export const options = {
+  scenarios: {
+    tickets: scenario('listTickets', RATE),
+    triage: scenario('triage', Math.max(1, Math.floor(RATE / 4))),
+    search: scenario('searchDocs', Math.max(1, Math.floor(RATE / 2))),
+  },
+  // Per-endpoint thresholds also make k6 export each endpoint's p95 in the
+  // summary, which loadtest/compare.sh checks against the baseline.
+  thresholds: {
+    'http_req_failed': ['rate<0.01'],
+    'http_req_duration{endpoint:tickets}': ['p(95)<300'],
+    'http_req_duration{endpoint:triage}': ['p(95)<1000'],
+    'http_req_duration{endpoint:search}': ['p(95)<500'],
+  },
+};
+
+const titles = [
+  ['VPN disconnects every few minutes', 'Remote users lose the VPN tunnel several times an hour.'],
+  ['Outlook not syncing', 'Mailbox stopped syncing after the password change this morning.'],
+  ['Disk almost full on db-2', 'The data volume on db-2 is at 95% and growing.'],
+  ['Cannot print from laptop', 'Print jobs stay queued on the floor 3 printer.'],
+];
+const queries = ['vpn disconnects', 'reset password', 'disk space cleanup', 'printer queue stuck'];
+
+export function setup() {
+  const res = http.post(`${BASE_URL}/api/auth/login`, JSON.stringify({ email: EMAIL, password: PASSWORD }), {
+    headers: { 'Content-Type': 'application/json' },
+  });
+  check(res, { 'login succeeded': (r) => r.status === 200 });
+  const token = res.json('token');
+  const params = { headers: { 'Content-Type': 'application/json', Authorization: `Bearer ${token}` } };
+
+  // Give the ticket list and vector search something to work on
+  http.post(`${BASE_URL}/api/docs/index`, '{}', params);
+  for (const [title, description] of titles) {
+    http.post(`${BASE_URL}/api/tickets`, JSON.stringify({ title, description }), params);
+  }
+  return { token };
+}
+
+function params(data, endpoint) {
+  return {
+    headers: { 'Content-Type': 'application/json', Authorization: `Bearer ${data.token}` },
+    tags: { endpoint },
+  };
+}
+
+export function listTickets(data) {
+  const res = http.get(`${BASE_URL}/api/tickets?page=1&limit=10`, params(data, 'tickets'));
+  check(res, { 'tickets 200': (r) => r.status === 200 });
+}
+
+export function triage(data) {
+  const [title, description] = titles[Math.floor(Math.random() * titles.length)];
+  const res = http.post(`${BASE_URL}/api/ai/triage`, JSON.stringify({ title, description }), params(data, 'triage'));
+  check(res, { 'triage 200': (r) => r.status === 200 });
+}
+
+export function searchDocs(data) {
+  const query = queries[Math.floor(Math.random() * queries.length)];
+  const res = http.post(`${BASE_URL}/api/docs/search`, JSON.stringify({ query, topK: 5 }), params(data, 'search'));
+  check(res, { 'search 200': (r) => r.status === 200 });
+}